@@ -0,0 +1,65 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *handler) CreateAlias(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAliasRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+
+	resp, err := h.linkService.CreateAlias(r.Context(), chi.URLParam(r, "path"), req)
+	if err != nil {
+		writeAliasError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *handler) ListAliases(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.linkService.ListAliases(r.Context(), r.URL.Query().Get("host"), chi.URLParam(r, "path"))
+	if err != nil {
+		writeAliasError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *handler) DeleteAlias(w http.ResponseWriter, r *http.Request) {
+	if err := h.linkService.DeleteAlias(r.Context(), r.URL.Query().Get("host"), chi.URLParam(r, "alias")); err != nil {
+		writeAliasError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeAliasError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost),
+		errors.Is(err, apperrors.ErrInvalidPath),
+		errors.Is(err, apperrors.ErrReservedPath):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrLinkNotFound):
+		WriteError(w, err, http.StatusNotFound, "Link not found", models.StatusNotFound)
+	case errors.Is(err, apperrors.ErrPathTaken):
+		WriteError(w, err, http.StatusConflict, "Path is already in use", models.StatusAlreadyExists)
+	default:
+		requestLog(w).Error().Err(err).Msg("Failed to manage aliases")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to manage aliases", models.StatusInternal)
+	}
+}
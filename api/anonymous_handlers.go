@@ -0,0 +1,49 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/service"
+)
+
+type AnonymousHandler interface {
+	CreateLink(w http.ResponseWriter, r *http.Request)
+}
+
+type anonymousHandler struct {
+	anonymousService service.AnonymousService
+}
+
+func NewAnonymousHandler(anonymousService service.AnonymousService) AnonymousHandler {
+	return &anonymousHandler{
+		anonymousService: anonymousService,
+	}
+}
+
+// CreateLink serves "POST /v1/anonymous/shortLinks" for public shortener front-ends.
+func (h *anonymousHandler) CreateLink(w http.ResponseWriter, r *http.Request) {
+	var req models.AnonymousLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+
+	resp, err := h.anonymousService.CreateLink(r.Context(), req, clientIP(r))
+	switch {
+	case errors.Is(err, apperrors.ErrRateLimited):
+		w.Header().Set("Retry-After", "60")
+		WriteError(w, err, http.StatusTooManyRequests, "Too many links created, retry later", models.StatusResourceExhausted)
+	case errors.Is(err, apperrors.ErrMissingLink),
+		errors.Is(err, apperrors.ErrInvalidFormat):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case err != nil:
+		writeCreateError(w, r, err)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
@@ -7,13 +7,115 @@ var (
 	ErrHostInvalid       = errors.New("host is invalid")
 	ErrInvalidAppStoreID = errors.New("app store id should contain numbers only")
 
-	ErrDomainLinkNotAllowed = errors.New("domain link not in allow list")
-	ErrInvalidPathFormat    = errors.New("path must contain exactly one segment")
-	ErrInvalidRequestedLink = errors.New("invalid requested link")
+	ErrDomainLinkNotAllowed  = errors.New("domain link not in allow list")
+	ErrFallbackNotAllowed    = errors.New("fallback URL host is not in the fallback allow list")
+	ErrImageNotAllowed       = errors.New("social image host is not in the image allow list")
+	ErrInvalidPathFormat     = errors.New("path must contain exactly one segment")
+	ErrInvalidRequestedLink  = errors.New("invalid requested link")
+	ErrPIIDetected           = errors.New("destination contains personal data")
+	ErrUnsafeURL             = errors.New("destination is flagged as unsafe")
+	ErrReputationUnavailable = errors.New("URL reputation service is unavailable")
+	ErrWarningAsError        = errors.New("link rejected for a warning configured as an error")
+	ErrInvalidGate           = errors.New("gate must be one of: age, terms")
+	ErrInvalidDeepLink       = errors.New("deep link route may only contain letters, digits and '/', '-', '_', '.', '~'")
+	ErrInvalidPlatform       = errors.New("platform must be one of: web, android, ios")
+	ErrSMSBudgetExceeded     = errors.New("short link would exceed the SMS length budget")
+	ErrInvalidPlatformLink   = errors.New("platform link must be an absolute URL with a safe scheme")
+	ErrInvalidGeoDestination = errors.New("geoDestinations must list at most 50 links, each for a different ISO 3166-1 alpha-2 country code")
+	ErrInvalidDeviceRule     = errors.New("invalid device rule")
+	ErrInvalidVariant        = errors.New("invalid variant")
+	ErrInvalidMaxClicks      = errors.New("maxClicks must not be negative")
+	ErrInvalidExpiredLink    = errors.New("expiredLink must be an absolute http(s) URL")
+	ErrInvalidActiveWindow   = errors.New("activeUntil must be after activeFrom")
+	ErrInvalidTTL            = errors.New("ttlSeconds must not be negative")
+	ErrSignedLinksDisabled   = errors.New("signed links are not configured")
 
 	ErrInvalidFormat = errors.New("invalid request format")
 	ErrMissingHost   = errors.New("missing host")
 	ErrMissingLink   = errors.New("missing link")
+	ErrMissingQuery  = errors.New("missing search query")
+	ErrInvalidSort   = errors.New("sort must be one of: relevance, newest, oldest")
 
-	ErrLinkNotFound = errors.New("link not found")
+	ErrInvalidListSort          = errors.New("sort must be one of: createdAt, clickCount, lastClickedAt, expiresAt, optionally prefixed with '-'")
+	ErrInvalidPageToken         = errors.New("invalid page token")
+	ErrInvalidListSuffix        = errors.New("suffix must be one of: SHORT, UNGUESSABLE")
+	ErrInvalidDateRange         = errors.New("createdAfter must be before createdBefore")
+	ErrInvalidGranularity       = errors.New("granularity must be one of: hour, day, week, month")
+	ErrInvalidStatsRange        = errors.New("invalid stats time range")
+	ErrInvalidDestinationDomain = errors.New("destinationDomain must be a domain name")
+
+	ErrInvalidRewriteRule = errors.New("invalid rewrite rule")
+	ErrSupersedeSelf      = errors.New("successor is the same link")
+	ErrLinkChanged        = errors.New("link was changed since it was read")
+	ErrInvalidLinkParam   = errors.New("invalid link param")
+	ErrInvalidMetadata    = errors.New("invalid link metadata")
+
+	ErrBatchTooLarge = errors.New("too many links in batch")
+	ErrEmptyBatch    = errors.New("batch holds no links")
+
+	ErrInvalidImportFile = errors.New("invalid import file")
+	ErrInvalidImportRow  = errors.New("invalid import row")
+	ErrImportTooLarge    = errors.New("too many rows in import file")
+
+	ErrLinkNotFound   = errors.New("link not found")
+	ErrLinkDeleted    = errors.New("link was deleted")
+	ErrLinkReserved   = errors.New("link has no destination yet")
+	ErrLinkExpired    = errors.New("link has expired")
+	ErrLinkNotActive  = errors.New("link is not active yet")
+	ErrPathTaken      = errors.New("path is already in use")
+	ErrCodeTaken      = errors.New("code is already in use")
+	ErrInvalidPath    = errors.New("path may only contain letters, digits, '-' and '_'")
+	ErrReservedPath   = errors.New("path is reserved")
+	ErrInvalidSuffix  = errors.New("suffix.customPath requires suffix.option CUSTOM")
+	ErrCodeNotAllowed = errors.New("suffix.numericCode requires suffix.option SHORT or CUSTOM")
+	ErrDomainNotFound = errors.New("domain not found")
+
+	ErrSavedSearchNotFound = errors.New("saved search not found")
+	ErrSavedSearchExists   = errors.New("a saved search with this name already exists")
+	ErrInvalidSearchName   = errors.New("saved search name must be 1 to 100 characters")
+
+	ErrInvalidPackageName  = errors.New("packageName must be an Android application ID, e.g. com.example.app")
+	ErrInvalidFingerprint  = errors.New("sha256CertFingerprint must be 32 hex bytes, e.g. 14:6D:E9:...")
+	ErrFingerprintExists   = errors.New("fingerprint is already registered for this app")
+	ErrFingerprintNotFound = errors.New("fingerprint not found")
+
+	ErrDomainExists          = errors.New("domain is already configured")
+	ErrDomainUnverified      = errors.New("domain has not published its verification TXT record")
+	ErrMissingAllowedDomains = errors.New("allowedDomains must list at least one destination domain")
+	ErrInvalidAllowedDomain  = errors.New("allowedDomains may only hold domain names")
+
+	ErrWebhookNotFound        = errors.New("webhook not found")
+	ErrInvalidWebhookURL      = errors.New("webhook url must be an absolute http or https URL")
+	ErrInvalidWebhookEvent    = errors.New("events may only hold link.created, link.updated, link.deleted, link.expired")
+	ErrInvalidClickThresholds = errors.New("clickThresholds must be positive click counts")
+
+	ErrCampaignNotFound     = errors.New("campaign not found")
+	ErrCampaignExists       = errors.New("a campaign with this id already exists")
+	ErrInvalidCampaignID    = errors.New("campaign id must be 1 to 64 lowercase letters, digits, '-' or '_', starting with a letter or digit")
+	ErrInvalidCampaignName  = errors.New("campaign name must be at most 255 characters")
+	ErrInvalidCampaignLinks = errors.New("paths must list 1 to 1000 short link paths")
+
+	ErrUTMPresetNotFound    = errors.New("UTM preset not found")
+	ErrUTMPresetExists      = errors.New("a UTM preset with this name already exists")
+	ErrInvalidUTMPresetName = errors.New("UTM preset name must be 1 to 64 letters, digits, '-', '_' or '.', starting with a letter or digit")
+	ErrInvalidUTMPreset     = errors.New("UTM preset must set at least one of utmSource, utmMedium, utmCampaign, utmTerm or utmContent, each at most 255 characters")
+
+	ErrAPIKeyNotFound      = errors.New("API key not found")
+	ErrInvalidAPIKeyName   = errors.New("API key name must be 1 to 255 characters")
+	ErrSubjectRoleNotFound = errors.New("subject has no role")
+	ErrInvalidSubject      = errors.New("subject must be 1 to 255 characters")
+	ErrInvalidRole         = errors.New("role must be admin, editor or viewer")
+
+	ErrInvalidAuditRange = errors.New("from must be before to")
+
+	ErrInvalidIdempotencyKey = errors.New("Idempotency-Key must be 1 to 255 characters")
+	ErrIdempotencyKeyReused  = errors.New("Idempotency-Key was already used for a different request")
+	ErrIdempotencyKeyInUse   = errors.New("a request with this Idempotency-Key is still in progress, retry later")
+
+	ErrTenantLimited = errors.New("too many link changes for this domain, retry later")
+	ErrRateLimited   = errors.New("too many requests, retry later")
+	ErrPolicyDenied  = errors.New("denied by policy")
+	ErrNoFreePath    = errors.New("no free path for the link, retry later")
+
+	ErrProjectNotAllowed = errors.New("not allowed to create links on this host")
 )
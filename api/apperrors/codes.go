@@ -0,0 +1,135 @@
+package apperrors
+
+// codes are the machine readable codes of the errors, sent as the reason of error responses.
+// Clients switch on them, so a code is never renamed or given to another error.
+var codes = map[error]string{
+	ErrInvalidURLFormat:         "INVALID_URL_FORMAT",
+	ErrHostInvalid:              "HOST_INVALID",
+	ErrInvalidAppStoreID:        "INVALID_APP_STORE_ID",
+	ErrDomainLinkNotAllowed:     "DOMAIN_LINK_NOT_ALLOWED",
+	ErrFallbackNotAllowed:       "FALLBACK_DOMAIN_NOT_ALLOWED",
+	ErrImageNotAllowed:          "IMAGE_DOMAIN_NOT_ALLOWED",
+	ErrInvalidPathFormat:        "INVALID_PATH_FORMAT",
+	ErrInvalidRequestedLink:     "INVALID_REQUESTED_LINK",
+	ErrPIIDetected:              "PII_DETECTED",
+	ErrUnsafeURL:                "UNSAFE_URL",
+	ErrReputationUnavailable:    "REPUTATION_UNAVAILABLE",
+	ErrWarningAsError:           "WARNING_AS_ERROR",
+	ErrInvalidGate:              "INVALID_GATE",
+	ErrInvalidDeepLink:          "INVALID_DEEP_LINK",
+	ErrInvalidPlatform:          "INVALID_PLATFORM",
+	ErrSMSBudgetExceeded:        "SMS_BUDGET_EXCEEDED",
+	ErrInvalidPlatformLink:      "INVALID_PLATFORM_LINK",
+	ErrInvalidGeoDestination:    "INVALID_GEO_DESTINATION",
+	ErrInvalidDeviceRule:        "INVALID_DEVICE_RULE",
+	ErrInvalidVariant:           "INVALID_VARIANT",
+	ErrInvalidMaxClicks:         "INVALID_MAX_CLICKS",
+	ErrInvalidExpiredLink:       "INVALID_EXPIRED_LINK",
+	ErrInvalidActiveWindow:      "INVALID_ACTIVE_WINDOW",
+	ErrInvalidTTL:               "INVALID_TTL",
+	ErrSignedLinksDisabled:      "SIGNED_LINKS_DISABLED",
+	ErrInvalidFormat:            "INVALID_FORMAT",
+	ErrMissingHost:              "MISSING_HOST",
+	ErrMissingLink:              "MISSING_LINK",
+	ErrMissingQuery:             "MISSING_QUERY",
+	ErrInvalidSort:              "INVALID_SORT",
+	ErrInvalidListSort:          "INVALID_LIST_SORT",
+	ErrInvalidPageToken:         "INVALID_PAGE_TOKEN",
+	ErrInvalidListSuffix:        "INVALID_LIST_SUFFIX",
+	ErrInvalidDateRange:         "INVALID_DATE_RANGE",
+	ErrInvalidGranularity:       "INVALID_GRANULARITY",
+	ErrInvalidStatsRange:        "INVALID_STATS_RANGE",
+	ErrInvalidDestinationDomain: "INVALID_DESTINATION_DOMAIN",
+	ErrInvalidRewriteRule:       "INVALID_REWRITE_RULE",
+	ErrSupersedeSelf:            "SUPERSEDE_SELF",
+	ErrLinkChanged:              "LINK_CHANGED",
+	ErrInvalidLinkParam:         "INVALID_LINK_PARAM",
+	ErrInvalidMetadata:          "INVALID_METADATA",
+	ErrBatchTooLarge:            "BATCH_TOO_LARGE",
+	ErrEmptyBatch:               "EMPTY_BATCH",
+	ErrInvalidImportFile:        "INVALID_IMPORT_FILE",
+	ErrInvalidImportRow:         "INVALID_IMPORT_ROW",
+	ErrImportTooLarge:           "IMPORT_TOO_LARGE",
+	ErrLinkNotFound:             "LINK_NOT_FOUND",
+	ErrLinkDeleted:              "LINK_DELETED",
+	ErrLinkReserved:             "LINK_RESERVED",
+	ErrLinkExpired:              "LINK_EXPIRED",
+	ErrLinkNotActive:            "LINK_NOT_ACTIVE",
+	ErrPathTaken:                "PATH_TAKEN",
+	ErrCodeTaken:                "CODE_TAKEN",
+	ErrInvalidPath:              "INVALID_PATH",
+	ErrReservedPath:             "RESERVED_PATH",
+	ErrInvalidSuffix:            "INVALID_SUFFIX",
+	ErrCodeNotAllowed:           "NUMERIC_CODE_NOT_ALLOWED",
+	ErrDomainNotFound:           "DOMAIN_NOT_FOUND",
+	ErrSavedSearchNotFound:      "SAVED_SEARCH_NOT_FOUND",
+	ErrSavedSearchExists:        "SAVED_SEARCH_EXISTS",
+	ErrInvalidSearchName:        "INVALID_SEARCH_NAME",
+	ErrInvalidPackageName:       "INVALID_PACKAGE_NAME",
+	ErrInvalidFingerprint:       "INVALID_FINGERPRINT",
+	ErrFingerprintExists:        "FINGERPRINT_EXISTS",
+	ErrFingerprintNotFound:      "FINGERPRINT_NOT_FOUND",
+	ErrDomainExists:             "DOMAIN_EXISTS",
+	ErrDomainUnverified:         "DOMAIN_UNVERIFIED",
+	ErrMissingAllowedDomains:    "MISSING_ALLOWED_DOMAINS",
+	ErrInvalidAllowedDomain:     "INVALID_ALLOWED_DOMAIN",
+	ErrWebhookNotFound:          "WEBHOOK_NOT_FOUND",
+	ErrInvalidWebhookURL:        "INVALID_WEBHOOK_URL",
+	ErrInvalidWebhookEvent:      "INVALID_WEBHOOK_EVENT",
+	ErrInvalidClickThresholds:   "INVALID_CLICK_THRESHOLDS",
+	ErrCampaignNotFound:         "CAMPAIGN_NOT_FOUND",
+	ErrCampaignExists:           "CAMPAIGN_EXISTS",
+	ErrInvalidCampaignID:        "INVALID_CAMPAIGN_ID",
+	ErrInvalidCampaignName:      "INVALID_CAMPAIGN_NAME",
+	ErrInvalidCampaignLinks:     "INVALID_CAMPAIGN_LINKS",
+	ErrUTMPresetNotFound:        "UTM_PRESET_NOT_FOUND",
+	ErrUTMPresetExists:          "UTM_PRESET_EXISTS",
+	ErrInvalidUTMPresetName:     "INVALID_UTM_PRESET_NAME",
+	ErrInvalidUTMPreset:         "INVALID_UTM_PRESET",
+	ErrAPIKeyNotFound:           "API_KEY_NOT_FOUND",
+	ErrInvalidAPIKeyName:        "INVALID_API_KEY_NAME",
+	ErrSubjectRoleNotFound:      "SUBJECT_ROLE_NOT_FOUND",
+	ErrInvalidSubject:           "INVALID_SUBJECT",
+	ErrInvalidRole:              "INVALID_ROLE",
+	ErrInvalidAuditRange:        "INVALID_AUDIT_RANGE",
+	ErrInvalidIdempotencyKey:    "INVALID_IDEMPOTENCY_KEY",
+	ErrIdempotencyKeyReused:     "IDEMPOTENCY_KEY_REUSED",
+	ErrIdempotencyKeyInUse:      "IDEMPOTENCY_KEY_IN_USE",
+	ErrTenantLimited:            "TENANT_LIMITED",
+	ErrRateLimited:              "RATE_LIMITED",
+	ErrPolicyDenied:             "POLICY_DENIED",
+	ErrNoFreePath:               "NO_FREE_PATH",
+	ErrProjectNotAllowed:        "PROJECT_NOT_ALLOWED",
+}
+
+// Code returns the code of the first error of this package found in err's tree, outermost
+// first, or "" if there is none.
+func Code(err error) string {
+	if err == nil {
+		return ""
+	}
+	if code, ok := codes[err]; ok {
+		return code
+	}
+	switch wrapped := err.(type) {
+	case interface{ Unwrap() error }:
+		return Code(wrapped.Unwrap())
+	case interface{ Unwrap() []error }:
+		for _, e := range wrapped.Unwrap() {
+			if code := Code(e); code != "" {
+				return code
+			}
+		}
+	}
+	return ""
+}
+
+// FromCode returns the error with the given code, or nil if there is none.
+func FromCode(code string) error {
+	for err, c := range codes {
+		if c == code {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodes(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "apperrors.go", nil, 0)
+	if err != nil {
+		t.Fatalf("failed to parse apperrors.go: %s", err)
+	}
+	declared := 0
+	ast.Inspect(file, func(n ast.Node) bool {
+		if spec, ok := n.(*ast.ValueSpec); ok {
+			declared += len(spec.Names)
+		}
+		return true
+	})
+	assert.Len(t, codes, declared, "every error has a code")
+
+	seen := map[string]bool{}
+	for err, code := range codes {
+		assert.False(t, seen[code], "code %s is given to more than one error", code)
+		seen[code] = true
+		assert.Equal(t, err, FromCode(code))
+	}
+}
+
+func TestCode(t *testing.T) {
+	assert.Equal(t, "LINK_NOT_FOUND", Code(ErrLinkNotFound))
+	assert.Equal(t, "LINK_NOT_FOUND", Code(fmt.Errorf("database error: %w", ErrLinkNotFound)))
+	assert.Equal(t, "INVALID_LINK_PARAM", Code(fmt.Errorf("%w 'afl': %w", ErrInvalidLinkParam, ErrDomainLinkNotAllowed)), "the outer error wins")
+	assert.Equal(t, "", Code(errors.New("boom")))
+	assert.Equal(t, "", Code(nil))
+	assert.Nil(t, FromCode("NOPE"))
+}
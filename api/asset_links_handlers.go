@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type AssetLinksHandler interface {
+	AssetLinks(w http.ResponseWriter, r *http.Request)
+	AddFingerprint(w http.ResponseWriter, r *http.Request)
+	ListFingerprints(w http.ResponseWriter, r *http.Request)
+	DeleteFingerprint(w http.ResponseWriter, r *http.Request)
+}
+
+type assetLinksHandler struct {
+	assetLinksService service.AssetLinksService
+}
+
+func NewAssetLinksHandler(assetLinksService service.AssetLinksService) AssetLinksHandler {
+	return &assetLinksHandler{
+		assetLinksService: assetLinksService,
+	}
+}
+
+// AssetLinks serves the host's /.well-known/assetlinks.json for Android App Links verification.
+func (h *assetLinksHandler) AssetLinks(w http.ResponseWriter, r *http.Request) {
+	statements, err := h.assetLinksService.AssetLinks(r.Context(), r.Host)
+	if err != nil {
+		writeAssetLinksError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	json.NewEncoder(w).Encode(statements)
+}
+
+func (h *assetLinksHandler) AddFingerprint(w http.ResponseWriter, r *http.Request) {
+	var fingerprint models.AndroidAppFingerprint
+	if err := json.NewDecoder(r.Body).Decode(&fingerprint); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+
+	added, err := h.assetLinksService.AddFingerprint(r.Context(), chi.URLParam(r, "domain"), fingerprint)
+	if err != nil {
+		writeAssetLinksError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(added)
+}
+
+func (h *assetLinksHandler) ListFingerprints(w http.ResponseWriter, r *http.Request) {
+	fingerprints, err := h.assetLinksService.ListFingerprints(r.Context(), chi.URLParam(r, "domain"))
+	if err != nil {
+		writeAssetLinksError(w, err)
+		return
+	}
+
+	writeProjectedJSON(w, r, models.AndroidAppFingerprintsResponse{Fingerprints: fingerprints}, "fingerprints")
+}
+
+func (h *assetLinksHandler) DeleteFingerprint(w http.ResponseWriter, r *http.Request) {
+	err := h.assetLinksService.DeleteFingerprint(r.Context(),
+		chi.URLParam(r, "domain"), chi.URLParam(r, "packageName"), chi.URLParam(r, "fingerprint"))
+	if err != nil {
+		writeAssetLinksError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeAssetLinksError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost),
+		errors.Is(err, apperrors.ErrInvalidPackageName),
+		errors.Is(err, apperrors.ErrInvalidFingerprint):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrDomainNotFound):
+		WriteError(w, err, http.StatusNotFound, "Domain not found", models.StatusNotFound)
+	case errors.Is(err, apperrors.ErrFingerprintNotFound):
+		WriteError(w, err, http.StatusNotFound, err.Error(), models.StatusNotFound)
+	case errors.Is(err, apperrors.ErrFingerprintExists):
+		WriteError(w, err, http.StatusConflict, err.Error(), models.StatusAlreadyExists)
+	default:
+		requestLog(w).Error().Err(err).Msg("Asset links request failed")
+		WriteError(w, err, http.StatusInternalServerError, "Asset links request failed", models.StatusInternal)
+	}
+}
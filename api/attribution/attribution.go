@@ -0,0 +1,173 @@
+package attribution
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fingerprint is what both a click and a freshly installed app can tell about a device without
+// an identifier. Empty fields are unknown.
+type Fingerprint struct {
+	IP string
+	// OS is "android" or "ios".
+	OS string
+	// OSVersion is the major version, e.g. "17".
+	OSVersion string
+	// Language is the primary language subtag of the preferred locale, e.g. "en".
+	Language string
+}
+
+// Click is a redirect to a link with an app, kept until an install claims it or it expires.
+type Click struct {
+	Fingerprint
+	Host     string
+	Path     string
+	DeepLink string
+	// Variant is the A/B test variant the click was sent to, if the link has variants.
+	Variant string
+	At      time.Time
+}
+
+// Recorder takes note of clicks that an install may later claim.
+type Recorder interface {
+	Record(click Click)
+}
+
+// Nop drops every click.
+type Nop struct{}
+
+func (Nop) Record(Click) {}
+
+// Store keeps recent clicks for installs to claim.
+type Store interface {
+	Recorder
+	// Claim removes and returns the click choose picks among the unexpired clicks from ip,
+	// newest first. choose returns -1 to claim none.
+	Claim(ip string, choose func(candidates []Click) int) (Click, bool)
+}
+
+// maxClicksPerIP bounds the clicks kept for one address, the oldest being dropped first. Installs
+// claim a recent click, so more than a handful from one address are of no use.
+const maxClicksPerIP = 16
+
+// MemoryStore keeps clicks in memory for ttl, from at most maxIPs addresses. Clicks are only
+// claimable on the instance that served the redirect.
+type MemoryStore struct {
+	ttl    time.Duration
+	maxIPs int
+	now    func() time.Time
+
+	mu        sync.Mutex
+	byIP      map[string][]Click
+	lastPrune time.Time
+}
+
+func NewMemoryStore(ttl time.Duration, maxIPs int) *MemoryStore {
+	return &MemoryStore{
+		ttl:    ttl,
+		maxIPs: maxIPs,
+		now:    time.Now,
+		byIP:   map[string][]Click{},
+	}
+}
+
+func (s *MemoryStore) Record(click Click) {
+	if click.IP == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked()
+	clicks, known := s.byIP[click.IP]
+	if !known && s.maxIPs > 0 && len(s.byIP) >= s.maxIPs {
+		// Making room for a new address drops an arbitrary one, which is cheap and can't be
+		// steered by whoever fills the store.
+		for ip := range s.byIP {
+			delete(s.byIP, ip)
+			break
+		}
+	}
+	if len(clicks) >= maxClicksPerIP {
+		clicks = clicks[len(clicks)-maxClicksPerIP+1:]
+	}
+	s.byIP[click.IP] = append(clicks, click)
+}
+
+func (s *MemoryStore) Claim(ip string, choose func(candidates []Click) int) (Click, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := s.now().Add(-s.ttl)
+	var candidates []Click
+	for i := len(s.byIP[ip]) - 1; i >= 0; i-- {
+		if c := s.byIP[ip][i]; c.At.After(cutoff) {
+			candidates = append(candidates, c)
+		}
+	}
+	i := choose(candidates)
+	if i < 0 || i >= len(candidates) {
+		return Click{}, false
+	}
+
+	claimed := candidates[i]
+	remaining := s.byIP[ip][:0]
+	for _, c := range s.byIP[ip] {
+		if c != claimed && c.At.After(cutoff) {
+			remaining = append(remaining, c)
+		}
+	}
+	if len(remaining) == 0 {
+		delete(s.byIP, ip)
+	} else {
+		s.byIP[ip] = remaining
+	}
+	return claimed, true
+}
+
+// pruneLocked drops expired clicks, at most once a minute so recording stays cheap.
+func (s *MemoryStore) pruneLocked() {
+	now := s.now()
+	if now.Sub(s.lastPrune) < time.Minute {
+		return
+	}
+	s.lastPrune = now
+
+	cutoff := now.Add(-s.ttl)
+	for ip, clicks := range s.byIP {
+		// Clicks are appended in time order, so the expired ones come first.
+		n := 0
+		for n < len(clicks) && !clicks[n].At.After(cutoff) {
+			n++
+		}
+		if n == len(clicks) {
+			delete(s.byIP, ip)
+		} else if n > 0 {
+			s.byIP[ip] = clicks[n:]
+		}
+	}
+}
+
+var osVersionPattern = regexp.MustCompile(`(?:android|(?:iphone |cpu )?os) (\d+)`)
+
+// OSVersion returns the major Android or iOS version named in a user agent, or "". Chrome's
+// reduced user agent always claims Android 10, so Android versions are only a weak signal.
+func OSVersion(ua string) string {
+	m := osVersionPattern.FindStringSubmatch(strings.ToLower(ua))
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// Language returns the primary language subtag of a locale or of the first entry of an
+// Accept-Language header, e.g. "en" for "en-US,en;q=0.9".
+func Language(locale string) string {
+	locale, _, _ = strings.Cut(locale, ",")
+	locale, _, _ = strings.Cut(locale, ";")
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	lang, _, _ := strings.Cut(locale, "-")
+	return strings.ToLower(lang)
+}
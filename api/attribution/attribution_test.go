@@ -0,0 +1,71 @@
+package attribution
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s := NewMemoryStore(time.Hour, 0)
+	s.now = func() time.Time { return now }
+
+	s.Record(Click{Fingerprint: Fingerprint{IP: "203.0.113.7"}, Path: "old", At: now.Add(-2 * time.Hour)})
+	s.Record(Click{Fingerprint: Fingerprint{IP: "203.0.113.7"}, Path: "a", At: now.Add(-30 * time.Minute)})
+	s.Record(Click{Fingerprint: Fingerprint{IP: "203.0.113.7"}, Path: "b", At: now.Add(-time.Minute)})
+	s.Record(Click{Path: "no ip", At: now})
+
+	var seen []string
+	click, ok := s.Claim("203.0.113.7", func(candidates []Click) int {
+		for _, c := range candidates {
+			seen = append(seen, c.Path)
+		}
+		return 1
+	})
+	assert.True(t, ok)
+	assert.Equal(t, "a", click.Path)
+	assert.Equal(t, []string{"b", "a"}, seen, "newest first, expired left out")
+
+	click, ok = s.Claim("203.0.113.7", func([]Click) int { return 0 })
+	assert.True(t, ok)
+	assert.Equal(t, "b", click.Path)
+
+	_, ok = s.Claim("203.0.113.7", func([]Click) int { return 0 })
+	assert.False(t, ok, "claimed clicks are gone")
+	_, ok = s.Claim("198.51.100.1", func([]Click) int { return -1 })
+	assert.False(t, ok)
+}
+
+func TestMemoryStore_Bounds(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s := NewMemoryStore(time.Hour, 2)
+	s.now = func() time.Time { return now }
+
+	for i := range maxClicksPerIP + 4 {
+		s.Record(Click{Fingerprint: Fingerprint{IP: "203.0.113.7"}, Path: fmt.Sprint(i), At: now})
+	}
+	assert.Len(t, s.byIP["203.0.113.7"], maxClicksPerIP)
+	assert.Equal(t, "4", s.byIP["203.0.113.7"][0].Path, "the oldest clicks are dropped")
+
+	s.Record(Click{Fingerprint: Fingerprint{IP: "198.51.100.1"}, At: now})
+	s.Record(Click{Fingerprint: Fingerprint{IP: "198.51.100.2"}, At: now})
+	assert.Len(t, s.byIP, 2)
+	assert.Contains(t, s.byIP, "198.51.100.2", "the newest address is kept")
+}
+
+func TestOSVersion(t *testing.T) {
+	assert.Equal(t, "17", OSVersion("Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15"))
+	assert.Equal(t, "16", OSVersion("Mozilla/5.0 (iPad; CPU OS 16_2 like Mac OS X) AppleWebKit/605.1.15"))
+	assert.Equal(t, "14", OSVersion("Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36"))
+	assert.Equal(t, "", OSVersion("Mozilla/5.0 (Windows NT 10.0; Win64; x64)"))
+}
+
+func TestLanguage(t *testing.T) {
+	assert.Equal(t, "en", Language("en-US,en;q=0.9"))
+	assert.Equal(t, "pt", Language("pt_BR"))
+	assert.Equal(t, "de", Language("de"))
+	assert.Equal(t, "", Language(""))
+}
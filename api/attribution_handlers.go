@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+type AttributionHandler interface {
+	InstallAttribution(w http.ResponseWriter, r *http.Request)
+}
+
+type attributionHandler struct {
+	attributionService service.AttributionService
+}
+
+func NewAttributionHandler(attributionService service.AttributionService) AttributionHandler {
+	return &attributionHandler{
+		attributionService: attributionService,
+	}
+}
+
+// InstallAttribution serves "/installAttribution", called by an app on first launch to find the
+// link that was clicked before it was installed.
+func (h *attributionHandler) InstallAttribution(w http.ResponseWriter, r *http.Request) {
+	var req models.InstallAttributionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+	req.IP = clientIP(r)
+
+	resp, err := h.attributionService.Attribute(r.Context(), req)
+	switch {
+	case errors.Is(err, apperrors.ErrInvalidPlatform):
+		WriteError(w, err, http.StatusBadRequest, "platform must be one of: android, ios", models.StatusInvalidArgument)
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to attribute install")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to attribute install", models.StatusInternal)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
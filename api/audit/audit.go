@@ -0,0 +1,29 @@
+// Package audit carries who is making a change through a request's context, so the repositories
+// recording link and domain mutations in the audit log can tell who made them.
+package audit
+
+import "context"
+
+// Actor is who made a change. Changes made without one, by background jobs, have the zero Actor.
+type Actor struct {
+	// Key is the fingerprint of the caller's API key or token subject, empty for anonymous
+	// callers.
+	Key string
+	// Subject is the subject of the caller's token, empty for API keys.
+	Subject   string
+	Role      string
+	ClientIP  string
+	RequestID string
+}
+
+type actorKey struct{}
+
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor of ctx, or the zero Actor if there is none.
+func ActorFrom(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	return actor
+}
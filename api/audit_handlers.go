@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/service"
+)
+
+type AuditHandler interface {
+	List(w http.ResponseWriter, r *http.Request)
+}
+
+type auditHandler struct {
+	auditService service.AuditService
+}
+
+func NewAuditHandler(auditService service.AuditService) AuditHandler {
+	return &auditHandler{
+		auditService: auditService,
+	}
+}
+
+// List pages through the audit log, filtered by host, resource (a link's path or a domain's
+// host), action, actor (a key fingerprint or token subject) and from/to.
+func (h *auditHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := models.AuditLogRequest{
+		Host:      query.Get("host"),
+		Resource:  query.Get("resource"),
+		Action:    query.Get("action"),
+		Actor:     query.Get("actor"),
+		PageToken: query.Get("pageToken"),
+	}
+	if raw := query.Get("pageSize"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size < 1 {
+			WriteErrorResponse(w, http.StatusBadRequest, "pageSize must be a positive integer", models.StatusInvalidArgument)
+			return
+		}
+		req.PageSize = size
+	}
+	bounds := []struct {
+		param string
+		dst   *time.Time
+	}{
+		{"from", &req.From},
+		{"to", &req.To},
+	}
+	for _, bound := range bounds {
+		if raw := query.Get(bound.param); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				WriteErrorResponse(w, http.StatusBadRequest, bound.param+" must be an RFC 3339 timestamp", models.StatusInvalidArgument)
+				return
+			}
+			*bound.dst = t
+		}
+	}
+
+	resp, err := h.auditService.List(r.Context(), req)
+	switch {
+	case errors.Is(err, apperrors.ErrInvalidAuditRange),
+		errors.Is(err, apperrors.ErrInvalidPageToken):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case err != nil:
+		requestLog(w).Error().Err(err).Msg("Failed to list the audit log")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to list the audit log", models.StatusInternal)
+	default:
+		writeProjectedJSON(w, r, resp, "entries")
+	}
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/authz"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/oidc"
+	"durable-links-generator/api/service"
+	"durable-links-generator/config"
+)
+
+var (
+	errMissingCredential = errors.New("missing API key")
+	errKeyNotAllowed     = errors.New("API key is not allowed")
+	errTokenInvalid      = errors.New("token is invalid")
+	errNoRole            = errors.New("token has no known role")
+	errProviderDown      = errors.New("identity provider is unavailable")
+)
+
+// principal is who a request was authenticated as.
+type principal struct {
+	// Subject is the subject of a token, and empty for API keys.
+	Subject string
+	// Fingerprint identifies the caller to rate limits, idempotency keys, saved searches and OPA:
+	// the fingerprint of an API key, or of a token's issuer and subject, so that it outlives the
+	// token.
+	Fingerprint string
+	// Role is one of models.Roles.
+	Role string
+	// Projects are the hosts a token may work on; nil allows all of them.
+	Projects []string
+}
+
+// mayAccess reports whether p may work on project.
+func (p *principal) mayAccess(project string) bool {
+	return p.Projects == nil || slices.Contains(p.Projects, strings.ToLower(project))
+}
+
+// hasRole reports whether p's role is role or a more privileged one.
+func (p *principal) hasRole(role string) bool {
+	have := slices.Index(models.Roles, p.Role)
+	return have >= 0 && have <= slices.Index(models.Roles, role)
+}
+
+// authenticator tells who sent a credential: one of ADMIN_API_KEYS, a key created through
+// /v1/apiKeys or, with OIDC_ISSUER set, a token of the issuer.
+type authenticator struct {
+	cfg   *config.Config
+	roles service.RoleService
+	// verifier is nil without OIDC_ISSUER.
+	verifier *oidc.Verifier
+}
+
+func newAuthenticator(cfg *config.Config, roles service.RoleService) (*authenticator, error) {
+	a := &authenticator{cfg: cfg, roles: roles}
+	if cfg.Server.OIDCIssuer == "" {
+		return a, nil
+	}
+	// Without an audience, any token of the issuer would do, including those it issues to other
+	// applications.
+	if cfg.Server.OIDCAudience == "" {
+		return nil, errors.New("OIDC_AUDIENCE must be set with OIDC_ISSUER")
+	}
+	verifier, err := oidc.New(oidc.Options{
+		Issuer:   cfg.Server.OIDCIssuer,
+		Audience: cfg.Server.OIDCAudience,
+		JWKSURL:  cfg.Server.OIDCJWKSURL,
+		CacheTTL: cfg.Server.OIDCJWKSCacheTTL,
+	})
+	if err != nil {
+		return nil, err
+	}
+	a.verifier = verifier
+	return a, nil
+}
+
+// enabled reports whether any caller can authenticate at all. Keys can only be created by an
+// admin, so it takes ADMIN_API_KEYS or an issuer.
+func (a *authenticator) enabled() bool {
+	return len(a.cfg.Server.AdminAPIKeys) > 0 || a.verifier != nil
+}
+
+// authenticate returns who sent credential, an API key or token. ADMIN_API_KEYS are admins,
+// created keys have the role they were created with, and tokens the role set for their subject
+// or else the one of their role claim; tokens without a role fail with errNoRole.
+func (a *authenticator) authenticate(ctx context.Context, credential string) (*principal, error) {
+	if credential == "" {
+		return nil, errMissingCredential
+	}
+	if a.verifier == nil || !oidc.LooksLikeToken(credential) {
+		if isAdminKey(a.cfg, credential) {
+			return &principal{Fingerprint: authz.Fingerprint(credential), Role: models.RoleAdmin}, nil
+		}
+		role, err := a.roles.KeyRole(ctx, credential)
+		switch {
+		case errors.Is(err, apperrors.ErrAPIKeyNotFound):
+			return nil, errKeyNotAllowed
+		case err != nil:
+			return nil, err
+		}
+		return &principal{Fingerprint: authz.Fingerprint(credential), Role: role}, nil
+	}
+
+	claims, err := a.verifier.Verify(ctx, credential)
+	switch {
+	case errors.Is(err, oidc.ErrInvalidToken), errors.Is(err, oidc.ErrExpiredToken):
+		return nil, fmt.Errorf("%w: %w", errTokenInvalid, err)
+	case err != nil:
+		return nil, fmt.Errorf("%w: %w", errProviderDown, err)
+	}
+	p := &principal{
+		Subject:     claims.Subject(),
+		Fingerprint: authz.Fingerprint("oidc:" + a.cfg.Server.OIDCIssuer + "\x00" + claims.Subject()),
+	}
+	p.Role, err = a.roles.SubjectRole(ctx, p.Subject)
+	switch {
+	case errors.Is(err, apperrors.ErrSubjectRoleNotFound):
+		p.Role = a.tokenRole(claims)
+	case err != nil:
+		return nil, err
+	}
+	if claim := a.cfg.Server.OIDCProjectClaim; claim != "" {
+		p.Projects = []string{}
+		for _, project := range claims.Strings(claim) {
+			if project == "*" {
+				p.Projects = nil
+				break
+			}
+			p.Projects = append(p.Projects, strings.ToLower(project))
+		}
+	}
+	if p.Role == "" {
+		return nil, errNoRole
+	}
+	return p, nil
+}
+
+// tokenRole maps the values of the role claim through OIDC_ROLE_MAP and returns the most
+// privileged known role among them, or "" for none. With a map, values it doesn't list grant
+// nothing: the claim may hold the issuer's own role names, which mustn't match ours by chance.
+func (a *authenticator) tokenRole(claims oidc.Claims) string {
+	roleMap := a.cfg.Server.OIDCRoleMap
+	granted := map[string]bool{}
+	for _, value := range claims.Strings(a.cfg.Server.OIDCRoleClaim) {
+		if len(roleMap) > 0 {
+			role, ok := roleMap[strings.ToLower(value)]
+			if !ok {
+				continue
+			}
+			value = role
+		}
+		granted[strings.ToLower(value)] = true
+	}
+	for _, role := range models.Roles {
+		if granted[role] {
+			return role
+		}
+	}
+	return ""
+}
+
+type principalKey struct{}
+
+func withPrincipal(ctx context.Context, p *principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// principalFrom returns who the request of ctx was authenticated as, or nil on routes without
+// authentication.
+func principalFrom(ctx context.Context) *principal {
+	p, _ := ctx.Value(principalKey{}).(*principal)
+	return p
+}
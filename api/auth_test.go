@@ -0,0 +1,40 @@
+package api
+
+import (
+	"testing"
+
+	"durable-links-generator/api/oidc"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		roleMap map[string]string
+		roles   []any
+		want    string
+	}{
+		{name: "taken as they are", roles: []any{"Viewer", "editor"}, want: "editor"},
+		{name: "unknown", roles: []any{"superuser"}, want: ""},
+		{name: "mapped", roleMap: map[string]string{"link-admins": "admin"}, roles: []any{"Link-Admins"}, want: "admin"},
+		{name: "unmapped ignored", roleMap: map[string]string{"link-viewers": "viewer"}, roles: []any{"admin", "link-viewers"}, want: "viewer"},
+		{name: "nothing mapped", roleMap: map[string]string{"link-viewers": "viewer"}, roles: []any{"admin"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &authenticator{cfg: &config.Config{Server: &config.ServerConfig{OIDCRoleClaim: "roles", OIDCRoleMap: tt.roleMap}}}
+			assert.Equal(t, tt.want, a.tokenRole(oidc.Claims{"roles": tt.roles}))
+		})
+	}
+}
+
+func TestNewAuthenticator_RequiresAudience(t *testing.T) {
+	_, err := newAuthenticator(&config.Config{Server: &config.ServerConfig{OIDCIssuer: "https://issuer.example.com"}}, nil)
+	assert.ErrorContains(t, err, "OIDC_AUDIENCE")
+
+	a, err := newAuthenticator(&config.Config{Server: &config.ServerConfig{}}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, a.verifier)
+}
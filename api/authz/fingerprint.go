@@ -0,0 +1,16 @@
+package authz
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Fingerprint returns a stable identifier for an API key that is safe to hand to policy engines
+// and logs: the first 16 hex characters of its SHA-256.
+func Fingerprint(key string) string {
+	if key == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
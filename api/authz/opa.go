@@ -0,0 +1,97 @@
+package authz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Input is the request context sent to OPA as input.
+type Input struct {
+	// KeyFingerprint identifies the API key without sending it, see Fingerprint.
+	KeyFingerprint string `json:"key"`
+	Project        string `json:"project"`
+	Action         string `json:"action"`
+	Domain         string `json:"domain"`
+	Method         string `json:"method"`
+	Path           string `json:"path"`
+	// Subject and Role are the caller's token subject and role, set on admin routes; Subject is
+	// empty for API keys.
+	Subject string `json:"subject,omitempty"`
+	Role    string `json:"role,omitempty"`
+}
+
+type Authorizer interface {
+	Authorize(ctx context.Context, input Input) (bool, error)
+}
+
+// OPA asks an OPA server, usually a sidecar, for a decision through its data API. The policy
+// must evaluate to a boolean, or to an object with a boolean "allow" field.
+type OPA struct {
+	url    string
+	client *http.Client
+}
+
+// NewOPA returns a client querying the document at policyPath, e.g. "durablelinks/allow".
+func NewOPA(baseURL, policyPath string, timeout time.Duration) *OPA {
+	return &OPA{
+		url:    strings.TrimRight(baseURL, "/") + "/v1/data/" + strings.Trim(policyPath, "/"),
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (o *OPA) Authorize(ctx context.Context, input Input) (bool, error) {
+	body, err := json.Marshal(map[string]any{"input": input})
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("opa request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("opa returned status %d", resp.StatusCode)
+	}
+
+	var decision struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decision); err != nil {
+		return false, fmt.Errorf("invalid opa response: %w", err)
+	}
+	return parseResult(decision.Result)
+}
+
+// parseResult reads a decision. An undefined result, which OPA returns when no rule matched,
+// denies.
+func parseResult(raw json.RawMessage) (bool, error) {
+	if len(raw) == 0 {
+		return false, nil
+	}
+
+	var allowed bool
+	if err := json.Unmarshal(raw, &allowed); err == nil {
+		return allowed, nil
+	}
+
+	var object struct {
+		Allow bool `json:"allow"`
+	}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return false, fmt.Errorf("unexpected opa result %s", raw)
+	}
+	return object.Allow, nil
+}
@@ -0,0 +1,61 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOPAAuthorize(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    bool
+		wantErr bool
+	}{
+		{name: "Boolean allow", status: http.StatusOK, body: `{"result": true}`, want: true},
+		{name: "Boolean deny", status: http.StatusOK, body: `{"result": false}`, want: false},
+		{name: "Object allow", status: http.StatusOK, body: `{"result": {"allow": true, "reason": "owner"}}`, want: true},
+		{name: "Undefined denies", status: http.StatusOK, body: `{}`, want: false},
+		{name: "Unexpected result", status: http.StatusOK, body: `{"result": "yes"}`, wantErr: true},
+		{name: "Server error", status: http.StatusInternalServerError, body: `{}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received map[string]Input
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1/data/durablelinks/allow", r.URL.Path)
+				json.NewDecoder(r.Body).Decode(&received)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			opa := NewOPA(server.URL+"/", "/durablelinks/allow", time.Second)
+			input := Input{KeyFingerprint: Fingerprint("key"), Project: "example.page.link", Action: "POST /shortLinks"}
+			got, err := opa.Authorize(context.Background(), input)
+
+			assert.Equal(t, input, received["input"])
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Equal(t, "", Fingerprint(""))
+	assert.Len(t, Fingerprint("secret"), 16)
+	assert.Equal(t, Fingerprint("secret"), Fingerprint("secret"))
+	assert.NotEqual(t, Fingerprint("secret"), Fingerprint("other"))
+}
@@ -0,0 +1,30 @@
+package authz
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"durable-links-generator/utils"
+)
+
+type projectsKey struct{}
+
+// WithProjects limits the work done for the request of ctx to the short link hosts projects;
+// nil allows all of them. Services check it where the hosts a request works on are only known
+// once its body is read, like the rows of an import.
+func WithProjects(ctx context.Context, projects []string) context.Context {
+	return context.WithValue(ctx, projectsKey{}, projects)
+}
+
+// MayAccess reports whether the request of ctx may work on host.
+func MayAccess(ctx context.Context, host string) bool {
+	projects, _ := ctx.Value(projectsKey{}).([]string)
+	if projects == nil {
+		return true
+	}
+	if cleaned, err := utils.CleanHost(host); err == nil {
+		host = cleaned
+	}
+	return slices.Contains(projects, strings.ToLower(host))
+}
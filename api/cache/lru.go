@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type lruEntry struct {
+	host, key, value string
+	expiresAt        time.Time
+}
+
+// LRU is an in-process link cache of up to size entries, each expiring ttl after it was cached.
+// It serves single instance installs: other instances never see its invalidations, so with more
+// than one instance changes only show everywhere once the entries expire.
+type LRU struct {
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu sync.Mutex
+	// order has the most recently used entry in front.
+	order   *list.List
+	entries map[string]map[string]*list.Element // host -> key -> element of order
+	counters
+	evictions int64
+}
+
+func NewLRU(size int, ttl time.Duration) *LRU {
+	return &LRU{
+		size:    max(size, 1),
+		ttl:     ttl,
+		now:     time.Now,
+		order:   list.New(),
+		entries: map[string]map[string]*list.Element{},
+	}
+}
+
+func (c *LRU) Get(_ context.Context, host, key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[host][key]
+	if ok && c.now().After(elem.Value.(*lruEntry).expiresAt) {
+		c.remove(elem)
+		ok = false
+	}
+	c.record(ok)
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry).value, true
+}
+
+func (c *LRU) Set(_ context.Context, host string, entries map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	for key, value := range entries {
+		if elem, ok := c.entries[host][key]; ok {
+			entry := elem.Value.(*lruEntry)
+			entry.value, entry.expiresAt = value, expiresAt
+			c.order.MoveToFront(elem)
+			continue
+		}
+		if c.entries[host] == nil {
+			c.entries[host] = map[string]*list.Element{}
+		}
+		c.entries[host][key] = c.order.PushFront(&lruEntry{host: host, key: key, value: value, expiresAt: expiresAt})
+		if c.order.Len() > c.size {
+			c.remove(c.order.Back())
+			c.evictions++
+		}
+	}
+}
+
+func (c *LRU) Invalidate(_ context.Context, hosts ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, host := range hosts {
+		for _, elem := range c.entries[host] {
+			c.order.Remove(elem)
+		}
+		delete(c.entries, host)
+	}
+}
+
+func (c *LRU) remove(elem *list.Element) {
+	entry := c.order.Remove(elem).(*lruEntry)
+	delete(c.entries[entry.host], entry.key)
+	if len(c.entries[entry.host]) == 0 {
+		delete(c.entries, entry.host)
+	}
+}
+
+func (c *LRU) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.counters.stats("memory")
+	stats.Entries = c.order.Len()
+	stats.Evictions = c.evictions
+	return stats
+}
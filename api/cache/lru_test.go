@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	c := NewLRU(3, time.Minute)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	c.Set(ctx, "acme.link", map[string]string{"path:a": "1", "path:b": "2"})
+	c.Set(ctx, "other.link", map[string]string{"path:a": "3"})
+	value, ok := c.Get(ctx, "acme.link", "path:a")
+	assert.True(t, ok)
+	assert.Equal(t, "1", value)
+
+	// acme.link/path:b is the least recently used entry.
+	c.Set(ctx, "other.link", map[string]string{"path:b": "4"})
+	_, ok = c.Get(ctx, "acme.link", "path:b")
+	assert.False(t, ok)
+	_, ok = c.Get(ctx, "acme.link", "path:a")
+	assert.True(t, ok)
+
+	c.Invalidate(ctx, "other.link")
+	_, ok = c.Get(ctx, "other.link", "path:a")
+	assert.False(t, ok)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = c.Get(ctx, "acme.link", "path:a")
+	assert.False(t, ok, "expired")
+
+	stats := c.Stats()
+	assert.Equal(t, Stats{Backend: "memory", Hits: 2, Misses: 3, Entries: 0, Evictions: 1}, stats)
+	assert.Equal(t, 0.4, stats.HitRate())
+	assert.Empty(t, c.entries)
+}
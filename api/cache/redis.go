@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+const keyPrefix = "durablelinks:links:"
+
+// Redis is a link cache keeping the entries of each host in one Redis hash. Failing Redis calls
+// are logged and treated as misses, so links keep resolving from the database while Redis is
+// down.
+type Redis struct {
+	client *redis.Client
+	ttl    time.Duration
+	counters
+}
+
+// Dial connects to the Redis server at url, e.g. redis://:password@localhost:6379/0, for the link
+// cache and rate limits. Redis 7 or later is needed.
+func Dial(url string) (*redis.Client, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	return client, nil
+}
+
+// NewRedis returns a link cache on client. The entries of a host expire ttl after the first of
+// them was cached.
+func NewRedis(client *redis.Client, ttl time.Duration) *Redis {
+	return &Redis{client: client, ttl: ttl}
+}
+
+func (c *Redis) Get(ctx context.Context, host, key string) (string, bool) {
+	value, err := c.client.HGet(ctx, keyPrefix+host, key).Result()
+	c.record(err == nil)
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Ctx(ctx).Warn().Err(err).Str("host", host).Msg("Failed to read link cache")
+		}
+		return "", false
+	}
+	return value, true
+}
+
+// Set caches entries on host. The expiry of the host's hash is only set when the hash is
+// created, so entries of hot hosts can't outlive ttl by being refreshed forever.
+func (c *Redis) Set(ctx context.Context, host string, entries map[string]string) {
+	if len(entries) == 0 {
+		return
+	}
+	key := keyPrefix + host
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, entries)
+		pipe.ExpireNX(ctx, key, c.ttl)
+		return nil
+	})
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("host", host).Msg("Failed to write link cache")
+	}
+}
+
+func (c *Redis) Invalidate(ctx context.Context, hosts ...string) {
+	keys := make([]string, len(hosts))
+	for i, host := range hosts {
+		keys[i] = keyPrefix + host
+	}
+	if err := c.client.Del(ctx, keys...).Err(); err != nil {
+		log.Ctx(ctx).Error().Err(err).Strs("hosts", hosts).Msg("Failed to invalidate link cache, entries stay until they expire")
+	}
+}
+
+func (c *Redis) Stats() Stats {
+	return c.counters.stats("redis")
+}
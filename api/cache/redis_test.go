@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedis(t *testing.T) {
+	server := miniredis.RunT(t)
+	client, err := Dial("redis://" + server.Addr())
+	assert.NoError(t, err)
+	defer client.Close()
+	c := NewRedis(client, time.Minute)
+	ctx := context.Background()
+
+	_, ok := c.Get(ctx, "acme.link", "path:abc")
+	assert.False(t, ok)
+
+	c.Set(ctx, "acme.link", map[string]string{"path:abc": "link=x", "query:link=x": "abc"})
+	c.Set(ctx, "other.link", map[string]string{"path:abc": "link=y"})
+	value, ok := c.Get(ctx, "acme.link", "path:abc")
+	assert.True(t, ok)
+	assert.Equal(t, "link=x", value)
+	assert.Equal(t, time.Minute, server.TTL(keyPrefix+"acme.link"))
+
+	// Later entries don't push the expiry of the host back.
+	server.FastForward(30 * time.Second)
+	c.Set(ctx, "acme.link", map[string]string{"path:def": "link=z"})
+	assert.Equal(t, 30*time.Second, server.TTL(keyPrefix+"acme.link"))
+
+	c.Invalidate(ctx, "acme.link")
+	_, ok = c.Get(ctx, "acme.link", "path:abc")
+	assert.False(t, ok)
+	_, ok = c.Get(ctx, "other.link", "path:abc")
+	assert.True(t, ok)
+
+	server.FastForward(time.Minute)
+	_, ok = c.Get(ctx, "other.link", "path:abc")
+	assert.False(t, ok, "expired")
+
+	// A failing Redis is a miss.
+	server.Close()
+	_, ok = c.Get(ctx, "other.link", "path:abc")
+	assert.False(t, ok)
+	assert.Equal(t, Stats{Backend: "redis", Hits: 2, Misses: 4}, c.Stats())
+}
+
+func TestDialErrors(t *testing.T) {
+	_, err := Dial("http://localhost")
+	assert.Error(t, err)
+}
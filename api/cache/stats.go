@@ -0,0 +1,43 @@
+package cache
+
+import "sync/atomic"
+
+// Stats tells how well a link cache is doing since the instance started.
+type Stats struct {
+	// Backend is "redis" or "memory".
+	Backend string
+	Hits    int64
+	Misses  int64
+	// Entries and Evictions are only known for the in-process cache.
+	Entries   int
+	Evictions int64
+}
+
+// HitRate is the share of lookups answered from the cache, 0 before the first lookup.
+func (s Stats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// StatsReporter is a cache keeping Stats.
+type StatsReporter interface {
+	Stats() Stats
+}
+
+type counters struct {
+	hits, misses atomic.Int64
+}
+
+func (c *counters) record(hit bool) {
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+func (c *counters) stats(backend string) Stats {
+	return Stats{Backend: backend, Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
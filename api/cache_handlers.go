@@ -0,0 +1,43 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"durable-links-generator/api/cache"
+	"durable-links-generator/api/models"
+)
+
+type CacheHandler interface {
+	CacheStats(w http.ResponseWriter, r *http.Request)
+}
+
+type cacheHandler struct {
+	// reporter is nil without a link cache.
+	reporter cache.StatsReporter
+}
+
+func NewCacheHandler(reporter cache.StatsReporter) CacheHandler {
+	return &cacheHandler{
+		reporter: reporter,
+	}
+}
+
+func (h *cacheHandler) CacheStats(w http.ResponseWriter, r *http.Request) {
+	resp := models.CacheStatsResponse{Backend: "none"}
+	if h.reporter != nil {
+		stats := h.reporter.Stats()
+		resp = models.CacheStatsResponse{
+			Backend:   stats.Backend,
+			Hits:      stats.Hits,
+			Misses:    stats.Misses,
+			HitRate:   stats.HitRate(),
+			Entries:   stats.Entries,
+			Evictions: stats.Evictions,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CampaignHandler interface {
+	Create(w http.ResponseWriter, r *http.Request)
+	List(w http.ResponseWriter, r *http.Request)
+	Get(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+	AddLinks(w http.ResponseWriter, r *http.Request)
+	ListLinks(w http.ResponseWriter, r *http.Request)
+	RemoveLink(w http.ResponseWriter, r *http.Request)
+	Stats(w http.ResponseWriter, r *http.Request)
+}
+
+type campaignHandler struct {
+	campaignService service.CampaignService
+}
+
+func NewCampaignHandler(campaignService service.CampaignService) CampaignHandler {
+	return &campaignHandler{
+		campaignService: campaignService,
+	}
+}
+
+func (h *campaignHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var campaign models.Campaign
+	if err := json.NewDecoder(r.Body).Decode(&campaign); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+
+	created, err := h.campaignService.Create(r.Context(), chi.URLParam(r, "domain"), campaign)
+	if err != nil {
+		writeCampaignError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (h *campaignHandler) List(w http.ResponseWriter, r *http.Request) {
+	campaigns, err := h.campaignService.List(r.Context(), chi.URLParam(r, "domain"))
+	if err != nil {
+		writeCampaignError(w, err)
+		return
+	}
+
+	writeProjectedJSON(w, r, models.CampaignListResponse{Campaigns: campaigns}, "campaigns")
+}
+
+func (h *campaignHandler) Get(w http.ResponseWriter, r *http.Request) {
+	campaign, err := h.campaignService.Get(r.Context(), chi.URLParam(r, "domain"), chi.URLParam(r, "id"))
+	if err != nil {
+		writeCampaignError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(campaign)
+}
+
+// Delete deletes the campaign, leaving its links as they are.
+func (h *campaignHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.campaignService.Delete(r.Context(), chi.URLParam(r, "domain"), chi.URLParam(r, "id")); err != nil {
+		writeCampaignError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddLinks puts links in the campaign and answers with the campaign.
+func (h *campaignHandler) AddLinks(w http.ResponseWriter, r *http.Request) {
+	var req models.CampaignLinksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+
+	campaign, err := h.campaignService.AddLinks(r.Context(), chi.URLParam(r, "domain"), chi.URLParam(r, "id"), req.Paths)
+	if err != nil {
+		writeCampaignError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(campaign)
+}
+
+func (h *campaignHandler) ListLinks(w http.ResponseWriter, r *http.Request) {
+	links, err := h.campaignService.ListLinks(r.Context(), chi.URLParam(r, "domain"), chi.URLParam(r, "id"))
+	if err != nil {
+		writeCampaignError(w, err)
+		return
+	}
+
+	writeProjectedJSON(w, r, models.CampaignLinkListResponse{Links: links}, "links")
+}
+
+func (h *campaignHandler) RemoveLink(w http.ResponseWriter, r *http.Request) {
+	err := h.campaignService.RemoveLink(r.Context(), chi.URLParam(r, "domain"), chi.URLParam(r, "id"), chi.URLParam(r, "path"))
+	if err != nil {
+		writeCampaignError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Stats reports the clicks on the links of the campaign, taking the query params of
+// GET /shortLinks/{path}/stats.
+func (h *campaignHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	req, ok := statsRequest(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := h.campaignService.Stats(r.Context(), chi.URLParam(r, "domain"), chi.URLParam(r, "id"), req)
+	if err != nil {
+		writeCampaignError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func writeCampaignError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost),
+		errors.Is(err, apperrors.ErrInvalidCampaignID),
+		errors.Is(err, apperrors.ErrInvalidCampaignName),
+		errors.Is(err, apperrors.ErrInvalidCampaignLinks),
+		errors.Is(err, apperrors.ErrInvalidGranularity),
+		errors.Is(err, apperrors.ErrInvalidStatsRange):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrCampaignNotFound),
+		errors.Is(err, apperrors.ErrLinkNotFound):
+		WriteError(w, err, http.StatusNotFound, err.Error(), models.StatusNotFound)
+	case errors.Is(err, apperrors.ErrCampaignExists):
+		WriteError(w, err, http.StatusConflict, err.Error(), models.StatusAlreadyExists)
+	default:
+		requestLog(w).Error().Err(err).Msg("Campaign request failed")
+		WriteError(w, err, http.StatusInternalServerError, "Campaign request failed", models.StatusInternal)
+	}
+}
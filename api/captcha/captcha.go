@@ -0,0 +1,74 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrFailed is returned for missing, invalid or expired solutions.
+var ErrFailed = errors.New("captcha verification failed")
+
+// Verifier checks the CAPTCHA or proof-of-work solution a client sent with a request. It is the
+// hook anonymous endpoints put in front of themselves.
+type Verifier interface {
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// Challenger is implemented by verifiers that hand out the puzzle themselves instead of relying on
+// a third-party widget.
+type Challenger interface {
+	Challenge() (Challenge, error)
+}
+
+// Challenge is a puzzle a client has to solve before calling a protected endpoint.
+type Challenge struct {
+	Challenge  string    `json:"challenge"`
+	Difficulty int       `json:"difficulty"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// Nop accepts every request, for deployments that rely on rate limits alone.
+type Nop struct{}
+
+func (Nop) Verify(context.Context, string, string) error { return nil }
+
+const (
+	ProviderNone      = "none"
+	ProviderTurnstile = "turnstile"
+	ProviderHCaptcha  = "hcaptcha"
+	ProviderPoW       = "pow"
+)
+
+// Options selects and configures a provider.
+type Options struct {
+	Provider string
+	// Secret is the provider's secret key, or the HMAC key challenges are signed with for PoW.
+	Secret     string
+	Timeout    time.Duration
+	Difficulty int
+	TTL        time.Duration
+}
+
+// New returns the verifier for opts.Provider. An empty provider is the same as "none".
+func New(opts Options) (Verifier, error) {
+	switch opts.Provider {
+	case "", ProviderNone:
+		return Nop{}, nil
+	case ProviderTurnstile:
+		if opts.Secret == "" {
+			return nil, errors.New("turnstile needs a secret key")
+		}
+		return NewSiteVerify(TurnstileURL, opts.Secret, opts.Timeout), nil
+	case ProviderHCaptcha:
+		if opts.Secret == "" {
+			return nil, errors.New("hcaptcha needs a secret key")
+		}
+		return NewSiteVerify(HCaptchaURL, opts.Secret, opts.Timeout), nil
+	case ProviderPoW:
+		return NewPoW([]byte(opts.Secret), opts.Difficulty, opts.TTL)
+	default:
+		return nil, fmt.Errorf("unknown captcha provider %q", opts.Provider)
+	}
+}
@@ -0,0 +1,109 @@
+package captcha
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSiteVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		status  int
+		body    string
+		wantErr error
+	}{
+		{name: "Accepted", token: "tok", status: http.StatusOK, body: `{"success": true}`},
+		{name: "Rejected", token: "tok", status: http.StatusOK, body: `{"success": false, "error-codes": ["invalid-input-response"]}`, wantErr: ErrFailed},
+		{name: "Missing token", token: "", status: http.StatusOK, body: `{"success": true}`, wantErr: ErrFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				assert.Equal(t, "secret", r.PostForm.Get("secret"))
+				assert.Equal(t, tt.token, r.PostForm.Get("response"))
+				assert.Equal(t, "10.0.0.1", r.PostForm.Get("remoteip"))
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			err := NewSiteVerify(server.URL, "secret", time.Second).Verify(context.Background(), tt.token, "10.0.0.1")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	err := NewSiteVerify(server.URL, "secret", time.Second).Verify(context.Background(), "tok", "")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrFailed, "provider outages are not the client's fault")
+}
+
+func TestPoW(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	pow, err := NewPoW([]byte("key"), 8, time.Minute)
+	assert.NoError(t, err)
+	pow.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	challenge, err := pow.Challenge()
+	assert.NoError(t, err)
+	assert.Equal(t, 8, challenge.Difficulty)
+
+	token := solve(challenge)
+	assert.ErrorIs(t, pow.Verify(ctx, challenge.Challenge+":x", ""), ErrFailed, "wrong nonce")
+	assert.NoError(t, pow.Verify(ctx, token, ""))
+	assert.ErrorIs(t, pow.Verify(ctx, token, ""), ErrFailed, "challenges are single use")
+
+	other, _ := NewPoW([]byte("other"), 8, time.Minute)
+	other.now = pow.now
+	assert.ErrorIs(t, other.Verify(ctx, solve(challenge), ""), ErrFailed, "signed with another key")
+
+	expiring, err := pow.Challenge()
+	assert.NoError(t, err)
+	now = now.Add(2 * time.Minute)
+	assert.ErrorIs(t, pow.Verify(ctx, solve(expiring), ""), ErrFailed, "expired")
+
+	_, err = NewPoW(nil, 8, time.Minute)
+	assert.Error(t, err)
+}
+
+func TestNew(t *testing.T) {
+	v, err := New(Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, Nop{}, v)
+
+	_, err = New(Options{Provider: ProviderTurnstile})
+	assert.Error(t, err)
+
+	v, err = New(Options{Provider: ProviderPoW, Secret: "key", Difficulty: 16, TTL: time.Minute})
+	assert.NoError(t, err)
+	assert.Implements(t, (*Challenger)(nil), v)
+
+	_, err = New(Options{Provider: "recaptcha"})
+	assert.Error(t, err)
+}
+
+func solve(c Challenge) string {
+	for nonce := 0; ; nonce++ {
+		token := c.Challenge + ":" + strconv.Itoa(nonce)
+		if solves(token, c.Difficulty) {
+			return token
+		}
+	}
+}
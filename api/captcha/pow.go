@@ -0,0 +1,120 @@
+package captcha
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PoW is a self-hosted proof-of-work check. Clients fetch a signed challenge and look for a
+// nonce such that SHA-256("<challenge>:<nonce>") starts with Difficulty zero bits, then send
+// "<challenge>:<nonce>" as the token. Each challenge is accepted once until it expires.
+type PoW struct {
+	key        []byte
+	difficulty int
+	ttl        time.Duration
+	now        func() time.Time
+
+	mu   sync.Mutex
+	used map[string]time.Time
+}
+
+// NewPoW returns a verifier signing challenges with key. Instances behind a load balancer must
+// share the key.
+func NewPoW(key []byte, difficulty int, ttl time.Duration) (*PoW, error) {
+	if len(key) == 0 {
+		return nil, errors.New("proof-of-work needs a signing key")
+	}
+	if difficulty < 1 || difficulty > 32 {
+		return nil, errors.New("proof-of-work difficulty must be between 1 and 32")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("proof-of-work challenge TTL must be positive")
+	}
+	return &PoW{
+		key:        key,
+		difficulty: difficulty,
+		ttl:        ttl,
+		now:        time.Now,
+		used:       map[string]time.Time{},
+	}, nil
+}
+
+func (p *PoW) Challenge() (Challenge, error) {
+	expiresAt := p.now().Add(p.ttl).Truncate(time.Second)
+
+	payload := make([]byte, 8+16)
+	binary.BigEndian.PutUint64(payload, uint64(expiresAt.Unix()))
+	if _, err := rand.Read(payload[8:]); err != nil {
+		return Challenge{}, err
+	}
+
+	challenge := base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(p.sign(payload))
+	return Challenge{Challenge: challenge, Difficulty: p.difficulty, ExpiresAt: expiresAt}, nil
+}
+
+func (p *PoW) Verify(_ context.Context, token, _ string) error {
+	challenge, nonce, ok := strings.Cut(token, ":")
+	if !ok || nonce == "" {
+		return ErrFailed
+	}
+
+	expiresAt, ok := p.open(challenge)
+	if !ok || !p.now().Before(expiresAt) {
+		return ErrFailed
+	}
+	if !solves(token, p.difficulty) {
+		return ErrFailed
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, seen := p.used[challenge]; seen {
+		return ErrFailed
+	}
+	now := p.now()
+	for c, exp := range p.used {
+		if !now.Before(exp) {
+			delete(p.used, c)
+		}
+	}
+	p.used[challenge] = expiresAt
+	return nil
+}
+
+func (p *PoW) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write(payload)
+	return mac.Sum(nil)[:16]
+}
+
+// open checks the signature on a challenge and returns its expiry.
+func (p *PoW) open(challenge string) (time.Time, bool) {
+	encoded, sig, ok := strings.Cut(challenge, ".")
+	if !ok {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(payload) != 24 {
+		return time.Time{}, false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, p.sign(payload)) {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(binary.BigEndian.Uint64(payload)), 0), true
+}
+
+// solves reports whether the SHA-256 of token starts with difficulty zero bits.
+func solves(token string, difficulty int) bool {
+	sum := sha256.Sum256([]byte(token))
+	return bits.LeadingZeros32(binary.BigEndian.Uint32(sum[:4])) >= difficulty
+}
@@ -0,0 +1,71 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	TurnstileURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+	HCaptchaURL  = "https://api.hcaptcha.com/siteverify"
+)
+
+// SiteVerify checks widget tokens against a siteverify API. Turnstile and hCaptcha share the
+// same request and response shape.
+type SiteVerify struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewSiteVerify(verifyURL, secret string, timeout time.Duration) *SiteVerify {
+	return &SiteVerify{
+		url:    verifyURL,
+		secret: secret,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *SiteVerify) Verify(ctx context.Context, token, remoteIP string) error {
+	if token == "" {
+		return ErrFailed
+	}
+
+	form := url.Values{"secret": {s.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("siteverify request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("siteverify returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("invalid siteverify response: %w", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("%w: %s", ErrFailed, strings.Join(result.ErrorCodes, ","))
+	}
+	return nil
+}
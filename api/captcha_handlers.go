@@ -0,0 +1,39 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"durable-links-generator/api/captcha"
+	"durable-links-generator/api/models"
+
+	"github.com/rs/zerolog/log"
+)
+
+type CaptchaHandler interface {
+	Challenge(w http.ResponseWriter, r *http.Request)
+}
+
+type captchaHandler struct {
+	challenger captcha.Challenger
+}
+
+func NewCaptchaHandler(challenger captcha.Challenger) CaptchaHandler {
+	return &captchaHandler{
+		challenger: challenger,
+	}
+}
+
+// Challenge hands out a proof-of-work puzzle to solve before calling a protected endpoint.
+func (h *captchaHandler) Challenge(w http.ResponseWriter, r *http.Request) {
+	challenge, err := h.challenger.Challenge()
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to create CAPTCHA challenge")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to create challenge", models.StatusInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(challenge)
+}
@@ -0,0 +1,146 @@
+package card
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Width and Height are the Open Graph recommended image size.
+const (
+	Width  = 1200
+	Height = 630
+	margin = 80
+)
+
+// Card is the text shown on a social card.
+type Card struct {
+	Title       string
+	Description string
+	Domain      string
+}
+
+// Style is the deployment's branding.
+type Style struct {
+	Background color.Color
+	Text       color.Color
+	Accent     color.Color
+	// Brand is printed in the footer next to the domain, if set.
+	Brand string
+}
+
+// ParseColor reads a "#rrggbb" hex color.
+func ParseColor(hex string) (color.Color, error) {
+	hex = strings.TrimPrefix(strings.TrimSpace(hex), "#")
+	if len(hex) != 6 {
+		return nil, fmt.Errorf("invalid color %q, want #rrggbb", hex)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color %q, want #rrggbb", hex)
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, nil
+}
+
+// Renderer draws cards with the Go fonts, which are compiled in so rendering needs no system
+// fonts.
+type Renderer struct {
+	style       Style
+	title       font.Face
+	description font.Face
+	footer      font.Face
+}
+
+func NewRenderer(style Style) (*Renderer, error) {
+	bold, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return nil, err
+	}
+	regular, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Renderer{style: style}
+	if r.title, err = face(bold, 64); err != nil {
+		return nil, err
+	}
+	if r.description, err = face(regular, 36); err != nil {
+		return nil, err
+	}
+	if r.footer, err = face(bold, 30); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func face(f *opentype.Font, size float64) (font.Face, error) {
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+// Render writes c as a PNG.
+func (r *Renderer) Render(w io.Writer, c Card) error {
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(r.style.Background), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, Width, 16), image.NewUniform(r.style.Accent), image.Point{}, draw.Src)
+
+	y := margin + 64
+	for _, line := range wrap(r.title, c.Title, Width-2*margin, 3) {
+		r.drawText(img, r.title, r.style.Text, line, y)
+		y += 78
+	}
+	y += 12
+	for _, line := range wrap(r.description, c.Description, Width-2*margin, 3) {
+		r.drawText(img, r.description, r.style.Text, line, y)
+		y += 48
+	}
+
+	footer := c.Domain
+	if r.style.Brand != "" {
+		footer = r.style.Brand + " · " + footer
+	}
+	r.drawText(img, r.footer, r.style.Accent, footer, Height-margin)
+
+	return png.Encode(w, img)
+}
+
+func (r *Renderer) drawText(img draw.Image, f font.Face, c color.Color, text string, y int) {
+	d := font.Drawer{Dst: img, Src: image.NewUniform(c), Face: f, Dot: fixed.P(margin, y)}
+	d.DrawString(text)
+}
+
+// wrap breaks text into at most maxLines lines no wider than width, ending the last one with an
+// ellipsis when the text doesn't fit. A single word wider than width overflows and is clipped.
+func wrap(f font.Face, text string, width, maxLines int) []string {
+	limit := fixed.I(width)
+	words := strings.Fields(text)
+	var lines []string
+	for len(words) > 0 && len(lines) < maxLines {
+		n := 1
+		for n < len(words) && font.MeasureString(f, strings.Join(words[:n+1], " ")) <= limit {
+			n++
+		}
+		lines = append(lines, strings.Join(words[:n], " "))
+		words = words[n:]
+	}
+
+	if len(words) > 0 {
+		last := lines[len(lines)-1]
+		for font.MeasureString(f, last+"…") > limit && strings.Contains(last, " ") {
+			last = last[:strings.LastIndex(last, " ")]
+		}
+		lines[len(lines)-1] = last + "…"
+	}
+	return lines
+}
@@ -0,0 +1,50 @@
+package card
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseColor(t *testing.T) {
+	c, err := ParseColor("#e94560")
+	assert.NoError(t, err)
+	assert.Equal(t, color.RGBA{R: 0xe9, G: 0x45, B: 0x60, A: 0xff}, c)
+
+	_, err = ParseColor("red")
+	assert.Error(t, err)
+}
+
+func TestRender(t *testing.T) {
+	r, err := NewRenderer(Style{Background: color.Black, Text: color.White, Accent: color.White, Brand: "Acme"})
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = r.Render(&buf, Card{
+		Title:       "Spring sale",
+		Description: strings.Repeat("Everything must go. ", 40),
+		Domain:      "example.com",
+	})
+	assert.NoError(t, err)
+
+	img, err := png.Decode(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, Width, img.Bounds().Dx())
+	assert.Equal(t, Height, img.Bounds().Dy())
+}
+
+func TestWrap(t *testing.T) {
+	r, err := NewRenderer(Style{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"short title"}, wrap(r.title, "short title", 1000, 3))
+	assert.Empty(t, wrap(r.title, "", 1000, 3))
+
+	lines := wrap(r.description, strings.Repeat("word ", 200), 1000, 3)
+	assert.Len(t, lines, 3)
+	assert.True(t, strings.HasSuffix(lines[2], "…"))
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/card"
+	"durable-links-generator/api/service"
+	"durable-links-generator/config"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+type CardHandler interface {
+	Card(w http.ResponseWriter, r *http.Request)
+}
+
+type cardHandler struct {
+	linkService service.LinkService
+	renderer    *card.Renderer
+}
+
+func NewCardHandler(linkService service.LinkService, renderer *card.Renderer) CardHandler {
+	return &cardHandler{
+		linkService: linkService,
+		renderer:    renderer,
+	}
+}
+
+// newCardRenderer builds the card renderer from the CARD_* branding config.
+func newCardRenderer(cfg *config.Config) (*card.Renderer, error) {
+	style := card.Style{Brand: cfg.App.CardBrandName}
+	var err error
+	if style.Background, err = card.ParseColor(cfg.App.CardBackgroundColor); err != nil {
+		return nil, err
+	}
+	if style.Text, err = card.ParseColor(cfg.App.CardTextColor); err != nil {
+		return nil, err
+	}
+	if style.Accent, err = card.ParseColor(cfg.App.CardAccentColor); err != nil {
+		return nil, err
+	}
+	return card.NewRenderer(style)
+}
+
+// Card serves "/v1/links/{path}/card.png", a branded social card for links whose destination
+// has no good image of its own. The link's host comes from the host query param, defaulting to
+// the request host, so the URL can be used as the link's "si".
+func (h *cardHandler) Card(w http.ResponseWriter, r *http.Request) {
+	host := r.URL.Query().Get("host")
+	if host == "" {
+		host = r.Host
+	}
+
+	c, err := h.linkService.GetLinkCard(r.Context(), host, chi.URLParam(r, "path"))
+	switch {
+	case errors.Is(err, apperrors.ErrLinkNotFound):
+		http.NotFound(w, r)
+		return
+	case errors.Is(err, apperrors.ErrPolicyDenied):
+		http.Error(w, "This link is not available", http.StatusForbidden)
+		return
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to load link card")
+		http.Error(w, "Failed to render card", http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.renderer.Render(&buf, card.Card{Title: c.Title, Description: c.Description, Domain: c.Domain}); err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to render link card")
+		http.Error(w, "Failed to render card", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write(buf.Bytes())
+}
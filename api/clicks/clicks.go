@@ -0,0 +1,120 @@
+package clicks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Recorder takes note of clicks on short links.
+type Recorder interface {
+	Record(host, path string, at time.Time)
+}
+
+// Nop drops every click.
+type Nop struct{}
+
+func (Nop) Record(string, string, time.Time) {}
+
+// Count is what happened to one link on one UTC day, the day of LastClickedAt, since the last
+// flush.
+type Count struct {
+	Host          string
+	Path          string
+	Clicks        int64
+	LastClickedAt time.Time
+}
+
+// Store persists click counts, adding them to the link's totals.
+type Store interface {
+	AddClicks(ctx context.Context, counts []Count) error
+}
+
+type click struct {
+	host, path string
+	at         time.Time
+}
+
+// Aggregator queues clicks and writes them from a background goroutine, one row update per link
+// and flush interval, so redirects never wait on the database. Clicks are dropped when the queue
+// is full.
+type Aggregator struct {
+	store    Store
+	queue    chan click
+	interval time.Duration
+	done     chan struct{}
+	once     sync.Once
+}
+
+func NewAggregator(store Store, interval time.Duration, queueSize int) *Aggregator {
+	a := &Aggregator{
+		store:    store,
+		queue:    make(chan click, queueSize),
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+func (a *Aggregator) Record(host, path string, at time.Time) {
+	select {
+	case a.queue <- click{host: host, path: path, at: at}:
+	default:
+		log.Warn().Str("host", host).Str("path", path).Msg("Click queue full, dropping click")
+	}
+}
+
+func (a *Aggregator) run() {
+	defer close(a.done)
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	pending := map[[3]string]*Count{}
+	for {
+		select {
+		case c, ok := <-a.queue:
+			if !ok {
+				a.flush(pending)
+				return
+			}
+			key := [3]string{c.host, c.path, c.at.UTC().Format(time.DateOnly)}
+			count := pending[key]
+			if count == nil {
+				count = &Count{Host: c.host, Path: c.path}
+				pending[key] = count
+			}
+			count.Clicks++
+			if c.at.After(count.LastClickedAt) {
+				count.LastClickedAt = c.at
+			}
+		case <-ticker.C:
+			a.flush(pending)
+			clear(pending)
+		}
+	}
+}
+
+func (a *Aggregator) flush(pending map[[3]string]*Count) {
+	if len(pending) == 0 {
+		return
+	}
+	counts := make([]Count, 0, len(pending))
+	for _, count := range pending {
+		counts = append(counts, *count)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := a.store.AddClicks(ctx, counts); err != nil {
+		log.Error().Err(err).Int("links", len(counts)).Msg("Failed to write click counts")
+	}
+}
+
+// Close writes the queued clicks and stops the aggregator.
+func (a *Aggregator) Close() {
+	a.once.Do(func() { close(a.queue) })
+	<-a.done
+}
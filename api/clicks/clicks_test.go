@@ -0,0 +1,61 @@
+package clicks
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStore struct {
+	mu      sync.Mutex
+	flushes [][]Count
+}
+
+func (f *fakeStore) AddClicks(_ context.Context, counts []Count) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flushes = append(f.flushes, counts)
+	return nil
+}
+
+func TestAggregator(t *testing.T) {
+	store := &fakeStore{}
+	a := NewAggregator(store, time.Hour, 16)
+
+	t0 := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	a.Record("a.page.link", "abc", t0.Add(time.Minute))
+	a.Record("a.page.link", "abc", t0)
+	a.Record("a.page.link", "def", t0)
+	a.Record("a.page.link", "def", t0.AddDate(0, 0, 1))
+	a.Close()
+	a.Close()
+
+	if assert.Len(t, store.flushes, 1, "clicks are written in one batch on close") {
+		counts := map[string]Count{}
+		for _, c := range store.flushes[0] {
+			counts[c.Path+" "+c.LastClickedAt.Format(time.DateOnly)] = c
+		}
+		assert.Len(t, counts, 3, "counts are split by day")
+		assert.Equal(t, int64(2), counts["abc 2024-05-01"].Clicks)
+		assert.Equal(t, t0.Add(time.Minute), counts["abc 2024-05-01"].LastClickedAt)
+		assert.Equal(t, int64(1), counts["def 2024-05-01"].Clicks)
+		assert.Equal(t, int64(1), counts["def 2024-05-02"].Clicks)
+	}
+}
+
+func TestAggregatorDropsWhenFull(t *testing.T) {
+	store := &fakeStore{}
+	a := &Aggregator{store: store, queue: make(chan click, 1), interval: time.Hour, done: make(chan struct{})}
+
+	a.Record("a.page.link", "abc", time.Now())
+	a.Record("a.page.link", "abc", time.Now())
+	go a.run()
+	a.Close()
+
+	if assert.Len(t, store.flushes, 1) {
+		assert.Equal(t, int64(1), store.flushes[0][0].Clicks)
+	}
+}
@@ -0,0 +1,144 @@
+package clicks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"durable-links-generator/api/geoip"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Sources of click events.
+const (
+	SourceRedirect = "redirect"
+	SourceExchange = "exchange"
+)
+
+// Event is one resolution of a short link: a redirect, or an exchange of the short link for its
+// long form through the API.
+type Event struct {
+	Host string
+	// Path is the path as requested, before following aliases and successors.
+	Path   string
+	At     time.Time
+	Source string
+	// Platform is the detected platform, see device.Platform*, or the platform asked for on
+	// exchanges.
+	Platform string
+	// Country is the ISO 3166-1 alpha-2 code of the country the click came from, if known. It is
+	// filled in from IP before the event is stored.
+	Country   string
+	Referrer  string
+	UserAgent string
+	// Visitor tells clicks by the same visitor in the same salt rotation period apart from others,
+	// for unique click counts. It is filled in from IP and UserAgent by a VisitorHasher before the
+	// event is stored, and is empty for clicks without an IP.
+	Visitor string
+	// Variant is the A/B test variant the click was sent to, if the link has variants.
+	Variant string
+	// Consented tells whether the visitor consented to analytics. Without consent Referrer,
+	// UserAgent and IP are left empty, and so are Country and Visitor.
+	Consented bool
+	// IP is only used to look up Country and Visitor and is never stored.
+	IP string
+}
+
+// EventRecorder takes note of every resolution of a short link.
+type EventRecorder interface {
+	RecordEvent(event Event)
+}
+
+func (Nop) RecordEvent(Event) {}
+
+// EventStore persists click events. The events slice is reused once AddClickEvents returns.
+type EventStore interface {
+	AddClickEvents(ctx context.Context, events []Event) error
+}
+
+// Writer queues click events and writes them from a background goroutine in batches of up to
+// batchSize, at least once per flush interval, so redirects never wait on the database or the
+// GeoIP lookup. Events are dropped when the queue is full.
+type Writer struct {
+	store     EventStore
+	locator   geoip.Locator
+	visitors  *VisitorHasher
+	queue     chan Event
+	interval  time.Duration
+	batchSize int
+	done      chan struct{}
+	once      sync.Once
+}
+
+func NewWriter(store EventStore, locator geoip.Locator, visitors *VisitorHasher, interval time.Duration, queueSize, batchSize int) *Writer {
+	w := &Writer{
+		store:     store,
+		locator:   locator,
+		visitors:  visitors,
+		queue:     make(chan Event, queueSize),
+		interval:  interval,
+		batchSize: max(batchSize, 1),
+		done:      make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *Writer) RecordEvent(event Event) {
+	select {
+	case w.queue <- event:
+	default:
+		log.Warn().Str("host", event.Host).Str("path", event.Path).Msg("Click event queue full, dropping event")
+	}
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, w.batchSize)
+	for {
+		select {
+		case event, ok := <-w.queue:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			if event.Country == "" {
+				event.Country = w.locator.Country(event.IP)
+			}
+			if event.Visitor == "" && event.IP != "" {
+				event.Visitor = w.visitors.Hash(event.At, event.IP, event.UserAgent)
+			}
+			event.IP = ""
+			batch = append(batch, event)
+			if len(batch) >= w.batchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			w.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (w *Writer) flush(batch []Event) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := w.store.AddClickEvents(ctx, batch); err != nil {
+		log.Error().Err(err).Int("events", len(batch)).Msg("Failed to write click events")
+	}
+}
+
+// Close writes the queued events and stops the writer.
+func (w *Writer) Close() {
+	w.once.Do(func() { close(w.queue) })
+	<-w.done
+}
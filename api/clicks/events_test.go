@@ -0,0 +1,53 @@
+package clicks
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeEventStore struct {
+	mu      sync.Mutex
+	batches [][]Event
+}
+
+func (f *fakeEventStore) AddClickEvents(_ context.Context, events []Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches = append(f.batches, append([]Event(nil), events...))
+	return nil
+}
+
+type fakeLocator map[string]string
+
+func (f fakeLocator) Country(ip string) string { return f[ip] }
+
+func TestWriter(t *testing.T) {
+	store := &fakeEventStore{}
+	w := NewWriter(store, fakeLocator{"1.2.3.4": "AU"}, newTestVisitorHasher(t, "", 0), time.Hour, 16, 2)
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	w.RecordEvent(Event{Host: "a.page.link", Path: "abc", At: at, Source: SourceRedirect, IP: "1.2.3.4"})
+	w.RecordEvent(Event{Host: "a.page.link", Path: "abc", At: at, Source: SourceRedirect, IP: "5.6.7.8"})
+	w.RecordEvent(Event{Host: "a.page.link", Path: "def", At: at, Source: SourceExchange, Country: "NZ"})
+	w.Close()
+	w.Close()
+
+	if assert.Len(t, store.batches, 2, "full batches are written at once, the rest on close") {
+		assert.Len(t, store.batches[0], 2)
+		assert.Equal(t, "AU", store.batches[0][0].Country)
+		assert.Equal(t, "", store.batches[0][1].Country)
+		assert.Equal(t, "NZ", store.batches[1][0].Country)
+		assert.NotEmpty(t, store.batches[0][0].Visitor)
+		assert.NotEqual(t, store.batches[0][0].Visitor, store.batches[0][1].Visitor)
+		assert.Empty(t, store.batches[1][0].Visitor, "clicks without an IP have no visitor")
+		for _, batch := range store.batches {
+			for _, event := range batch {
+				assert.Empty(t, event.IP, "IPs are never stored")
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package clicks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// VisitorHasher derives the Visitor of click events from their IP and user agent, for unique
+// click counts without keeping IPs. Hashes are keyed with a salt that rotates every period, so a
+// visitor's clicks of different periods can't be linked. The salts are derived from a secret:
+// instances sharing it count the same visitors once, and nothing needs storing between periods.
+type VisitorHasher struct {
+	secret   []byte
+	rotation time.Duration
+}
+
+// NewVisitorHasher returns a hasher rotating its salt every rotation, aligned on UTC midnight
+// for whole days, and every UTC day when rotation isn't positive. Without a secret a random one
+// is used, so hashes only match within the process.
+func NewVisitorHasher(secret string, rotation time.Duration) (*VisitorHasher, error) {
+	key := []byte(secret)
+	if secret == "" {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("generate visitor secret: %w", err)
+		}
+	}
+	if rotation <= 0 {
+		rotation = 24 * time.Hour
+	}
+	return &VisitorHasher{secret: key, rotation: rotation}, nil
+}
+
+// Hash returns the visitor of a click from ip with userAgent at time at.
+func (h *VisitorHasher) Hash(at time.Time, ip, userAgent string) string {
+	mac := hmac.New(sha256.New, h.salt(at))
+	mac.Write([]byte(ip))
+	mac.Write([]byte{0})
+	mac.Write([]byte(userAgent))
+	return hex.EncodeToString(mac.Sum(nil)[:12])
+}
+
+// salt returns the salt of the rotation period at falls in.
+func (h *VisitorHasher) salt(at time.Time) []byte {
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write([]byte(at.UTC().Truncate(h.rotation).Format(time.RFC3339)))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,39 @@
+package clicks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestVisitorHasher(t *testing.T, secret string, rotation time.Duration) *VisitorHasher {
+	t.Helper()
+	h, err := NewVisitorHasher(secret, rotation)
+	if err != nil {
+		t.Fatalf("NewVisitorHasher: %v", err)
+	}
+	return h
+}
+
+func TestVisitorHasher(t *testing.T) {
+	h := newTestVisitorHasher(t, "secret", 0)
+	day := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	visitor := h.Hash(day, "1.2.3.4", "Safari")
+
+	assert.Len(t, visitor, 24)
+	assert.NotContains(t, visitor, "1.2.3.4")
+	assert.Equal(t, visitor, h.Hash(day.Add(12*time.Hour), "1.2.3.4", "Safari"), "same visitor on the same day")
+	assert.Equal(t, visitor, h.Hash(day.In(time.FixedZone("AEST", 10*3600)), "1.2.3.4", "Safari"), "days are UTC days")
+	assert.NotEqual(t, visitor, h.Hash(day.AddDate(0, 0, 1), "1.2.3.4", "Safari"), "salts rotate daily")
+	assert.NotEqual(t, visitor, h.Hash(day, "1.2.3.4", "Chrome"))
+	assert.NotEqual(t, visitor, h.Hash(day, "1.2.3.5", "Safari"))
+
+	assert.Equal(t, visitor, newTestVisitorHasher(t, "secret", 24*time.Hour).Hash(day, "1.2.3.4", "Safari"), "instances sharing the secret agree")
+	assert.NotEqual(t, visitor, newTestVisitorHasher(t, "other", 0).Hash(day, "1.2.3.4", "Safari"))
+	assert.NotEqual(t, visitor, newTestVisitorHasher(t, "", 0).Hash(day, "1.2.3.4", "Safari"), "without a secret, a random one is used")
+
+	hourly := newTestVisitorHasher(t, "secret", time.Hour)
+	assert.Equal(t, hourly.Hash(day, "1.2.3.4", "Safari"), hourly.Hash(day.Add(59*time.Minute), "1.2.3.4", "Safari"))
+	assert.NotEqual(t, hourly.Hash(day, "1.2.3.4", "Safari"), hourly.Hash(day.Add(time.Hour), "1.2.3.4", "Safari"))
+}
@@ -0,0 +1,96 @@
+// Package clientip finds the address of the client behind the proxies in front of the server.
+// Forwarding headers are only believed when they were set by one of the trusted proxies, as
+// anyone can send them.
+package clientip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Resolver finds client addresses behind the trusted proxies.
+type Resolver struct {
+	trusted []netip.Prefix
+}
+
+// New returns a Resolver trusting the proxies at the given addresses or CIDR ranges. With none,
+// forwarding headers are ignored and the client is the peer of the connection.
+func New(trustedProxies []string) (*Resolver, error) {
+	res := &Resolver{}
+	for _, proxy := range trustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(proxy)
+			if addrErr != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		res.trusted = append(res.trusted, prefix.Masked())
+	}
+	return res, nil
+}
+
+// Middleware sets the RemoteAddr of requests to the client address, like chi's RealIP but only
+// believing the trusted proxies.
+func (res *Resolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.RemoteAddr = res.ClientIP(r)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ClientIP returns the address of the client that sent r. When the peer is a trusted proxy, it is
+// the last address of X-Forwarded-For that isn't a trusted proxy itself, else X-Real-IP.
+func (res *Resolver) ClientIP(r *http.Request) string {
+	peer := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(peer); err == nil {
+		peer = host
+	}
+	if !res.isTrusted(peer) {
+		return peer
+	}
+
+	var forwarded []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, addr := range strings.Split(header, ",") {
+			forwarded = append(forwarded, strings.TrimSpace(addr))
+		}
+	}
+	// Each proxy appends the address it got the request from, so the entries from the right are
+	// the ones the trusted proxies vouch for.
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(forwarded[i])
+		if err != nil {
+			break
+		}
+		if !res.isTrusted(addr.String()) || i == 0 {
+			return addr.Unmap().String()
+		}
+	}
+	if addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil && len(forwarded) == 0 {
+		return addr.Unmap().String()
+	}
+	return peer
+}
+
+func (res *Resolver) isTrusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range res.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package clientip
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIP(t *testing.T) {
+	res, err := New([]string{"10.0.0.0/8", "192.0.2.1"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		realIP     string
+		want       string
+	}{
+		{name: "direct client", remoteAddr: "203.0.113.7:1234", want: "203.0.113.7"},
+		{name: "untrusted peer's headers are ignored", remoteAddr: "203.0.113.7:1234", forwarded: []string{"198.51.100.1"}, realIP: "198.51.100.2", want: "203.0.113.7"},
+		{name: "trusted proxy", remoteAddr: "10.0.0.5:1234", forwarded: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "spoofed entries left of the client", remoteAddr: "10.0.0.5:1234", forwarded: []string{"1.2.3.4, 198.51.100.1"}, want: "198.51.100.1"},
+		{name: "chain of trusted proxies", remoteAddr: "192.0.2.1:1234", forwarded: []string{"198.51.100.1", "10.1.1.1"}, want: "198.51.100.1"},
+		{name: "only proxies", remoteAddr: "10.0.0.5:1234", forwarded: []string{"10.1.1.1, 10.2.2.2"}, want: "10.1.1.1"},
+		{name: "X-Real-IP from a trusted proxy", remoteAddr: "10.0.0.5:1234", realIP: "198.51.100.2", want: "198.51.100.2"},
+		{name: "garbage", remoteAddr: "10.0.0.5:1234", forwarded: []string{"not-an-ip"}, want: "10.0.0.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, f := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", f)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			assert.Equal(t, tt.want, res.ClientIP(r))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, err := New([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+	_, err = New([]string{"proxy.internal"})
+	assert.Error(t, err)
+}
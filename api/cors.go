@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+
+	"durable-links-generator/config"
+
+	"github.com/go-chi/cors"
+)
+
+// publicCORS lets SDKs on any configured origin call the link creation and resolution endpoints.
+// They authenticate without cookies, so credentials are never allowed.
+func publicCORS(cfg *config.Config) func(http.Handler) http.Handler {
+	return cors.Handler(cors.Options{
+		AllowedOrigins: cfg.Server.PublicCORSOrigins,
+		AllowedMethods: []string{"GET", "POST", "OPTIONS"},
+		AllowedHeaders: []string{"Accept", "Content-Type", "Authorization", "X-API-Key", "Idempotency-Key", "X-Captcha-Token"},
+		ExposedHeaders: []string{"Idempotent-Replayed"},
+		MaxAge:         300,
+	})
+}
+
+// adminCORS only answers browsers on the configured admin origins. With none configured the
+// admin API can't be called from a browser at all.
+func adminCORS(cfg *config.Config) func(http.Handler) http.Handler {
+	return cors.Handler(cors.Options{
+		AllowedOrigins:   cfg.Server.AdminCORSOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Content-Type", "Authorization", "X-API-Key", "If-Match", "Idempotency-Key"},
+		ExposedHeaders:   []string{"ETag", "Idempotent-Replayed"},
+		AllowCredentials: true,
+		MaxAge:           300,
+	})
+}
+
+// preflight answers OPTIONS requests the CORS middleware didn't handle, i.e. ones without
+// preflight headers. Routing OPTIONS explicitly makes the route group's CORS middleware run;
+// chi would answer 405 before reaching it otherwise.
+func preflight(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DashboardHandler serves the read-only admin dashboard API under /admin/api, counting across all
+// hosts so an internal dashboard needs no database access.
+type DashboardHandler interface {
+	Overview(w http.ResponseWriter, r *http.Request)
+	LinksCreated(w http.ResponseWriter, r *http.Request)
+	TopDestinations(w http.ResponseWriter, r *http.Request)
+	TopHosts(w http.ResponseWriter, r *http.Request)
+	Storage(w http.ResponseWriter, r *http.Request)
+}
+
+type dashboardHandler struct {
+	dashboardService service.DashboardService
+}
+
+func NewDashboardHandler(dashboardService service.DashboardService) DashboardHandler {
+	return &dashboardHandler{
+		dashboardService: dashboardService,
+	}
+}
+
+func (h *dashboardHandler) Overview(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.dashboardService.Overview(r.Context())
+	h.write(w, r, resp, err, "")
+}
+
+func (h *dashboardHandler) LinksCreated(w http.ResponseWriter, r *http.Request) {
+	days, ok := positiveIntParam(w, r.URL.Query().Get("days"), "days")
+	if !ok {
+		return
+	}
+	resp, err := h.dashboardService.LinksCreated(r.Context(), days)
+	h.write(w, r, resp, err, "days")
+}
+
+func (h *dashboardHandler) TopDestinations(w http.ResponseWriter, r *http.Request) {
+	limit, ok := positiveIntParam(w, r.URL.Query().Get("limit"), "limit")
+	if !ok {
+		return
+	}
+	resp, err := h.dashboardService.TopDestinations(r.Context(), limit)
+	h.write(w, r, resp, err, "destinations")
+}
+
+func (h *dashboardHandler) TopHosts(w http.ResponseWriter, r *http.Request) {
+	limit, ok := positiveIntParam(w, r.URL.Query().Get("limit"), "limit")
+	if !ok {
+		return
+	}
+	resp, err := h.dashboardService.TopHosts(r.Context(), limit)
+	h.write(w, r, resp, err, "hosts")
+}
+
+func (h *dashboardHandler) Storage(w http.ResponseWriter, r *http.Request) {
+	days, ok := positiveIntParam(w, r.URL.Query().Get("days"), "days")
+	if !ok {
+		return
+	}
+	resp, err := h.dashboardService.Storage(r.Context(), days)
+	h.write(w, r, resp, err, "growth")
+}
+
+func (h *dashboardHandler) write(w http.ResponseWriter, r *http.Request, resp any, err error, listKey string) {
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("path", r.URL.Path).Msg("Failed to read dashboard counts")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to read dashboard counts", models.StatusInternal)
+		return
+	}
+	writeProjectedJSON(w, r, resp, listKey)
+}
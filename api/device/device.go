@@ -0,0 +1,204 @@
+package device
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Platforms links can have a destination for.
+const (
+	PlatformWeb     = "web"
+	PlatformAndroid = "android"
+	PlatformIOS     = "ios"
+)
+
+// Form factors that can't open mobile apps or type in a browser comfortably.
+const (
+	FormFactorTV      = "tv"
+	FormFactorConsole = "console"
+	FormFactorWatch   = "watch"
+)
+
+// Hints is what the visitor sent about their device.
+type Hints struct {
+	UserAgent string
+	// Platform and Model are the Sec-CH-UA-Platform and Sec-CH-UA-Model client hints, quoted or not.
+	Platform string
+	Model    string
+}
+
+// Device is what a detector could tell about the visitor.
+type Device struct {
+	// Platform is PlatformWeb, PlatformAndroid or PlatformIOS.
+	Platform string
+	// OS is the operating system in lower case, e.g. "android", "ios", "windows", "macos",
+	// "linux", "chromeos", or "" when unknown.
+	OS string
+	// OSVersion is the version of OS the user agent gives, e.g. "14" or "17.1", for Android and
+	// iOS only. Reduced user agents freeze it, so it can be older than the real one.
+	OSVersion string
+	// Tablet is set for iPads and Android devices whose user agent leaves out "Mobile". iPads
+	// asking for the desktop site pass for Macs.
+	Tablet bool
+	Model  string
+	// HMS is set for Huawei devices running Huawei Mobile Services instead of Google Play, where
+	// Play Store links don't work.
+	HMS bool
+	// FormFactor is FormFactorTV, FormFactorConsole, FormFactorWatch, or "" for phones, tablets
+	// and computers.
+	FormFactor string
+}
+
+// Detector classifies visitors. Redirect handling only depends on this interface, so detection
+// rules can change, or be replaced by a UA parsing library, without touching it.
+type Detector interface {
+	Detect(hints Hints) Device
+}
+
+// Default is the built-in detector.
+var Default Detector = UserAgentDetector{}
+
+// UserAgentDetector reads the client hints when present and the user agent otherwise. The
+// Sec-CH-UA-Platform hint wins: reduced user agents no longer say much beyond the browser, while
+// the hint names the OS reliably. Safari sends no client hints, so iOS is still told apart by
+// user agent.
+type UserAgentDetector struct{}
+
+func (UserAgentDetector) Detect(hints Hints) Device {
+	d := Device{Model: unquote(hints.Model)}
+	ua := strings.ToLower(hints.UserAgent)
+	d.FormFactor = formFactor(ua)
+
+	d.OS = osFromHint(unquote(hints.Platform))
+	if d.OS == "" {
+		d.OS = osFromUserAgent(ua)
+	}
+
+	switch d.OS {
+	case "android":
+		d.Platform = PlatformAndroid
+		d.HMS = isHMS(ua)
+		d.OSVersion = androidVersion(ua)
+		d.Tablet = d.FormFactor == "" && strings.Contains(ua, "android") && !strings.Contains(ua, "mobile")
+	case "ios":
+		d.Platform = PlatformIOS
+		d.OSVersion = iosVersion(ua)
+		d.Tablet = strings.Contains(ua, "ipad")
+	default:
+		d.Platform = PlatformWeb
+	}
+	return d
+}
+
+// Desktop reports whether the device is a computer rather than a phone, tablet, TV, console or
+// watch.
+func (d Device) Desktop() bool {
+	if d.Platform != PlatformWeb || d.FormFactor != "" {
+		return false
+	}
+	switch d.OS {
+	case "windows", "macos", "linux", "chromeos":
+		return true
+	}
+	return false
+}
+
+func unquote(hint string) string {
+	return strings.Trim(hint, `" `)
+}
+
+func osFromHint(platform string) string {
+	switch strings.ToLower(platform) {
+	case "":
+		return ""
+	case "android":
+		return "android"
+	case "ios":
+		return "ios"
+	case "windows":
+		return "windows"
+	case "macos":
+		return "macos"
+	case "linux":
+		return "linux"
+	case "chrome os", "chromeos":
+		return "chromeos"
+	}
+	return "unknown"
+}
+
+// User agent fragments of each exotic form factor. TVs are matched last as some consoles mention
+// their TV app platform too.
+var formFactorUserAgents = []struct {
+	formFactor string
+	fragments  []string
+}{
+	{FormFactorConsole, []string{"playstation", "xbox", "nintendo"}},
+	{FormFactorWatch, []string{"watchos", "watch os", "wearos", "wear os", "tizen wearable"}},
+	{FormFactorTV, []string{"smart-tv", "smarttv", "hbbtv", "googletv", "google tv", "android tv",
+		"appletv", "apple tv", "crkey", "roku", "bravia", "web0s", "webos.tv", "aftb", "aftm", "afts", "aftt", "tizen tv"}},
+}
+
+func formFactor(ua string) string {
+	for _, f := range formFactorUserAgents {
+		for _, fragment := range f.fragments {
+			if strings.Contains(ua, fragment) {
+				return f.formFactor
+			}
+		}
+	}
+	return ""
+}
+
+// User agent fragments of Huawei's own browser and of HarmonyOS, both only found on devices
+// without Google Play.
+var hmsUserAgents = []string{"hmscore", "huaweibrowser", "harmonyos", "openharmony"}
+
+func isHMS(ua string) bool {
+	for _, s := range hmsUserAgents {
+		if strings.Contains(ua, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// osFromUserAgent checks the fragments in order: Android user agents mention Linux, and iPads
+// asking for the desktop site claim to be a Mac.
+func osFromUserAgent(ua string) string {
+	switch {
+	case strings.Contains(ua, "android"), strings.Contains(ua, "harmonyos"):
+		return "android"
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "ipod"):
+		return "ios"
+	case strings.Contains(ua, "windows"):
+		return "windows"
+	case strings.Contains(ua, "cros"):
+		return "chromeos"
+	case strings.Contains(ua, "macintosh"), strings.Contains(ua, "mac os x"):
+		return "macos"
+	case strings.Contains(ua, "linux"):
+		return "linux"
+	}
+	return ""
+}
+
+var (
+	androidVersionPattern = regexp.MustCompile(`android (\d+(?:\.\d+)*)`)
+	iosVersionPattern     = regexp.MustCompile(`os (\d+(?:_\d+)*) like mac os x`)
+)
+
+func androidVersion(ua string) string {
+	if m := androidVersionPattern.FindStringSubmatch(ua); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// iosVersion reads the version of "CPU iPhone OS 17_1 like Mac OS X" as "17.1".
+func iosVersion(ua string) string {
+	if m := iosVersionPattern.FindStringSubmatch(ua); m != nil {
+		return strings.ReplaceAll(m[1], "_", ".")
+	}
+	return ""
+}
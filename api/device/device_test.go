@@ -0,0 +1,49 @@
+package device
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// corpusCase is an entry of testdata/corpus.json. Add a case there whenever detection gets a
+// user agent wrong.
+type corpusCase struct {
+	Name         string `json:"name"`
+	UserAgent    string `json:"userAgent"`
+	PlatformHint string `json:"platformHint"`
+	ModelHint    string `json:"modelHint"`
+	Want         struct {
+		Platform   string `json:"platform"`
+		OS         string `json:"os"`
+		OSVersion  string `json:"osVersion"`
+		Tablet     bool   `json:"tablet"`
+		Model      string `json:"model"`
+		HMS        bool   `json:"hms"`
+		FormFactor string `json:"formFactor"`
+	} `json:"want"`
+}
+
+func TestUserAgentDetectorCorpus(t *testing.T) {
+	data, err := os.ReadFile("testdata/corpus.json")
+	if !assert.NoError(t, err) {
+		return
+	}
+	var corpus []corpusCase
+	if !assert.NoError(t, json.Unmarshal(data, &corpus)) {
+		return
+	}
+
+	for _, tt := range corpus {
+		t.Run(tt.Name, func(t *testing.T) {
+			got := UserAgentDetector{}.Detect(Hints{
+				UserAgent: tt.UserAgent,
+				Platform:  tt.PlatformHint,
+				Model:     tt.ModelHint,
+			})
+			assert.Equal(t, Device{Platform: tt.Want.Platform, OS: tt.Want.OS, OSVersion: tt.Want.OSVersion, Tablet: tt.Want.Tablet, Model: tt.Want.Model, HMS: tt.Want.HMS, FormFactor: tt.Want.FormFactor}, got)
+		})
+	}
+}
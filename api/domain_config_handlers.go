@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type DomainConfigHandler interface {
+	Create(w http.ResponseWriter, r *http.Request)
+	List(w http.ResponseWriter, r *http.Request)
+	Get(w http.ResponseWriter, r *http.Request)
+	Update(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+}
+
+type domainConfigHandler struct {
+	domainConfigService service.DomainConfigService
+}
+
+func NewDomainConfigHandler(domainConfigService service.DomainConfigService) DomainConfigHandler {
+	return &domainConfigHandler{
+		domainConfigService: domainConfigService,
+	}
+}
+
+func (h *domainConfigHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var domain models.DomainConfig
+	if err := json.NewDecoder(r.Body).Decode(&domain); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+
+	created, err := h.domainConfigService.Create(r.Context(), domain)
+	if err != nil {
+		writeDomainConfigError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (h *domainConfigHandler) List(w http.ResponseWriter, r *http.Request) {
+	domains, err := h.domainConfigService.List(r.Context())
+	if err != nil {
+		writeDomainConfigError(w, err)
+		return
+	}
+
+	writeProjectedJSON(w, r, models.DomainConfigListResponse{Domains: domains}, "domains")
+}
+
+func (h *domainConfigHandler) Get(w http.ResponseWriter, r *http.Request) {
+	domain, err := h.domainConfigService.Get(r.Context(), chi.URLParam(r, "domain"))
+	if err != nil {
+		writeDomainConfigError(w, err)
+		return
+	}
+
+	writeProjectedJSON(w, r, domain, "")
+}
+
+// Update replaces the settings of the domain with the body. The host in the body, if any, is
+// ignored in favor of the one in the path.
+func (h *domainConfigHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var domain models.DomainConfig
+	if err := json.NewDecoder(r.Body).Decode(&domain); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+
+	updated, err := h.domainConfigService.Update(r.Context(), chi.URLParam(r, "domain"), domain)
+	if err != nil {
+		writeDomainConfigError(w, err)
+		return
+	}
+
+	writeProjectedJSON(w, r, updated, "")
+}
+
+func (h *domainConfigHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.domainConfigService.Delete(r.Context(), chi.URLParam(r, "domain")); err != nil {
+		writeDomainConfigError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeDomainConfigError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost),
+		errors.Is(err, apperrors.ErrHostInvalid),
+		errors.Is(err, apperrors.ErrMissingAllowedDomains),
+		errors.Is(err, apperrors.ErrInvalidAllowedDomain),
+		errors.Is(err, apperrors.ErrInvalidPackageName),
+		errors.Is(err, apperrors.ErrInvalidAppStoreID):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrDomainNotFound):
+		WriteError(w, err, http.StatusNotFound, err.Error(), models.StatusNotFound)
+	case errors.Is(err, apperrors.ErrDomainExists):
+		WriteError(w, err, http.StatusConflict, err.Error(), models.StatusAlreadyExists)
+	case errors.Is(err, apperrors.ErrDomainUnverified):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusFailedPrecondition)
+	default:
+		requestLog(w).Error().Err(err).Msg("Domain settings request failed")
+		WriteError(w, err, http.StatusInternalServerError, "Domain settings request failed", models.StatusInternal)
+	}
+}
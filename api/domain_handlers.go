@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+type DomainHandler interface {
+	ListVerifications(w http.ResponseWriter, r *http.Request)
+	VerifyDomain(w http.ResponseWriter, r *http.Request)
+	LinkPolicy(w http.ResponseWriter, r *http.Request)
+	AppSiteAssociation(w http.ResponseWriter, r *http.Request)
+}
+
+type domainHandler struct {
+	domainService service.DomainService
+}
+
+func NewDomainHandler(domainService service.DomainService) DomainHandler {
+	return &domainHandler{
+		domainService: domainService,
+	}
+}
+
+func (h *domainHandler) ListVerifications(w http.ResponseWriter, r *http.Request) {
+	writeProjectedJSON(w, r, models.DomainVerificationListResponse{
+		Domains: h.domainService.VerifyAllDomains(r.Context()),
+	}, "domains")
+}
+
+func (h *domainHandler) VerifyDomain(w http.ResponseWriter, r *http.Request) {
+	result, err := h.domainService.VerifyDomain(r.Context(), chi.URLParam(r, "domain"))
+	if errors.Is(err, apperrors.ErrDomainNotFound) {
+		WriteError(w, err, http.StatusNotFound, "Domain not found", models.StatusNotFound)
+		return
+	}
+
+	writeProjectedJSON(w, r, result, "")
+}
+
+func (h *domainHandler) LinkPolicy(w http.ResponseWriter, r *http.Request) {
+	policy, err := h.domainService.LinkPolicy(r.Context(), r.Host)
+	if errors.Is(err, apperrors.ErrDomainNotFound) {
+		WriteError(w, err, http.StatusNotFound, "Domain not found", models.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("host", r.Host).Msg("Failed to build link policy")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to build link policy", models.StatusInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	json.NewEncoder(w).Encode(policy)
+}
+
+// AppSiteAssociation serves the host's apple-app-site-association document. Apple's CDN fetches
+// it without following redirects, so it is served directly at both of its locations.
+func (h *domainHandler) AppSiteAssociation(w http.ResponseWriter, r *http.Request) {
+	aasa, err := h.domainService.AppSiteAssociation(r.Host)
+	if err != nil {
+		WriteError(w, err, http.StatusNotFound, "Domain not found", models.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	json.NewEncoder(w).Encode(aasa)
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+type FixtureHandler interface {
+	ListFixtures(w http.ResponseWriter, r *http.Request)
+}
+
+type fixtureHandler struct {
+	fixtureService service.FixtureService
+}
+
+func NewFixtureHandler(fixtureService service.FixtureService) FixtureHandler {
+	return &fixtureHandler{
+		fixtureService: fixtureService,
+	}
+}
+
+func (h *fixtureHandler) ListFixtures(w http.ResponseWriter, r *http.Request) {
+	fixtures, err := h.fixtureService.Fixtures()
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to derive fixtures")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to derive fixtures", models.StatusInternal)
+		return
+	}
+
+	writeProjectedJSON(w, r, models.FixturesResponse{Fixtures: fixtures}, "fixtures")
+}
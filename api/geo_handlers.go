@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GeoDestinations serves "GET /v1/links/{path}/geoDestinations".
+func (h *handler) GeoDestinations(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.linkService.GeoDestinations(r.Context(), r.URL.Query().Get("host"), chi.URLParam(r, "path"))
+	if err != nil {
+		writeGeoDestinationsError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// SetGeoDestinations serves "PUT /v1/links/{path}/geoDestinations", replacing all geo
+// destinations of the link.
+func (h *handler) SetGeoDestinations(w http.ResponseWriter, r *http.Request) {
+	var req models.SetGeoDestinationsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+
+	resp, err := h.linkService.SetGeoDestinations(r.Context(), chi.URLParam(r, "path"), req)
+	if err != nil {
+		writeGeoDestinationsError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func writeGeoDestinationsError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost),
+		errors.Is(err, apperrors.ErrInvalidGeoDestination):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrDomainLinkNotAllowed):
+		WriteError(w, err, http.StatusBadRequest, "Link domain is not in the allow list", models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrLinkNotFound):
+		WriteError(w, err, http.StatusNotFound, "Link not found", models.StatusNotFound)
+	default:
+		requestLog(w).Error().Err(err).Msg("Failed to manage geo destinations")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to manage geo destinations", models.StatusInternal)
+	}
+}
@@ -0,0 +1,115 @@
+package geoip
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Locator finds the country an IP address is in.
+type Locator interface {
+	// Country returns the ISO 3166-1 alpha-2 code of the country ip is in, or "" if unknown.
+	Country(ip string) string
+}
+
+// Nop knows no country.
+type Nop struct{}
+
+func (Nop) Country(string) string { return "" }
+
+// Open loads the country database at path, or returns Nop if path is empty.
+func Open(path string) (Locator, error) {
+	if path == "" {
+		return Nop{}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ranges, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return ranges, nil
+}
+
+type ipRange struct {
+	first, last netip.Addr
+	country     string
+}
+
+// Ranges is a country database of IP ranges, searched in memory.
+type Ranges struct {
+	ranges []ipRange
+}
+
+// Parse reads a country database in the CSV layout of the free DB-IP and IP2Location country
+// databases: one "first IP,last IP,country code" line per range, IPv4 and IPv6 mixed. Extra
+// columns are ignored, as are ranges without a country ("-" or "ZZ").
+func Parse(r io.Reader) (*Ranges, error) {
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	var ranges []ipRange
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("line %d: want first IP, last IP and country code", line)
+		}
+		first, err := netip.ParseAddr(strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		last, err := netip.ParseAddr(strings.TrimSpace(record[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		first, last = first.Unmap(), last.Unmap()
+		if first.Is4() != last.Is4() || last.Less(first) {
+			return nil, fmt.Errorf("line %d: invalid range %s-%s", line, first, last)
+		}
+		country := strings.ToUpper(strings.TrimSpace(record[2]))
+		if country == "" || country == "-" || country == "ZZ" {
+			continue
+		}
+		ranges = append(ranges, ipRange{first: first, last: last, country: country})
+	}
+
+	slices.SortFunc(ranges, func(a, b ipRange) int { return a.first.Compare(b.first) })
+	return &Ranges{ranges: ranges}, nil
+}
+
+func (r *Ranges) Country(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+
+	// The last range starting at or before addr is the only one that can hold it.
+	i, found := slices.BinarySearchFunc(r.ranges, addr, func(rng ipRange, addr netip.Addr) int {
+		return rng.first.Compare(addr)
+	})
+	if !found {
+		i--
+	}
+	if i < 0 || r.ranges[i].last.Less(addr) || r.ranges[i].first.Is4() != addr.Is4() {
+		return ""
+	}
+	return r.ranges[i].country
+}
@@ -0,0 +1,52 @@
+package geoip
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testDatabase = `1.0.0.0,1.0.0.255,AU
+"2001:200::","2001:200:ffff:ffff:ffff:ffff:ffff:ffff","JP"
+8.8.8.0,8.8.8.255,us
+1.0.4.0,1.0.7.255,AU
+10.0.0.0,10.255.255.255,ZZ
+`
+
+func TestRanges(t *testing.T) {
+	ranges, err := Parse(strings.NewReader(testDatabase))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{ip: "1.0.0.1", want: "AU"},
+		{ip: "1.0.0.255", want: "AU"},
+		{ip: "1.0.1.0", want: ""},
+		{ip: "1.0.5.9", want: "AU"},
+		{ip: "8.8.8.8", want: "US"},
+		{ip: "::ffff:8.8.8.8", want: "US"},
+		{ip: "2001:200::1", want: "JP"},
+		{ip: "2001:201::1", want: ""},
+		{ip: "10.1.2.3", want: ""},
+		{ip: "0.0.0.1", want: ""},
+		{ip: "not an ip", want: ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ranges.Country(tt.ip), tt.ip)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, db := range []string{
+		"1.0.0.0,1.0.0.255\n",
+		"1.0.0.0,bad,AU\n",
+		"1.0.0.255,1.0.0.0,AU\n",
+		"1.0.0.0,::1,AU\n",
+	} {
+		_, err := Parse(strings.NewReader(db))
+		assert.Error(t, err, db)
+	}
+}
@@ -0,0 +1,278 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/audit"
+	"durable-links-generator/api/linkspb"
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+	"durable-links-generator/db"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// NewGRPCServer returns the gRPC server of the Links service defined in linkspb/links.proto,
+// backed by the same services as the HTTP API. Every call needs an API key or token with the
+// method's role; OPA policies and rate limits only apply to the HTTP API.
+func NewGRPCServer(services *Services, cfg *config.Config) *grpc.Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		requestIDUnary,
+		recoverUnary,
+		authenticateUnary(services.authenticator),
+	))
+	linkspb.RegisterLinksServer(server, &linksServer{services: services})
+	reflection.Register(server)
+	return server
+}
+
+// requestIDUnary is RequestID for gRPC calls, reading and returning the ID in the "x-request-id"
+// metadata.
+func requestIDUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	id := ""
+	if ids := md.Get(requestIDHeader); len(ids) > 0 {
+		id = ids[0]
+	}
+	if !validRequestID(id) {
+		id = newRequestID()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, id))
+
+	logger := log.With().Str("request_id", id).Logger()
+	return handler(logger.WithContext(ctx), req)
+}
+
+// recoverUnary turns a panicking call into an Internal error, as middleware.Recoverer does for
+// HTTP requests.
+func recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Ctx(ctx).Error().Interface("panic", p).Str("method", info.FullMethod).Msg("gRPC call panicked")
+			err = status.Error(codes.Internal, "Internal error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// methodRoles are the roles the methods of the Links service need, as RequireRole does for the
+// matching HTTP endpoints.
+var methodRoles = map[string]string{
+	linkspb.Links_CreateDurableLink_FullMethodName: models.RoleEditor,
+	linkspb.Links_ExchangeShortLink_FullMethodName: models.RoleViewer,
+	linkspb.Links_GetLink_FullMethodName:           models.RoleViewer,
+	linkspb.Links_DeleteLink_FullMethodName:        models.RoleAdmin,
+}
+
+// authenticateUnary is Authenticate and RequireRole for gRPC calls, reading the key or token from
+// the "x-api-key" or "authorization: Bearer" metadata. Calls don't say which project they work on
+// up front, so tokens limited to some projects are rejected.
+func authenticateUnary(auth *authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		p, err := auth.authenticate(ctx, apiKeyFromMetadata(ctx))
+		switch {
+		case errors.Is(err, errMissingCredential):
+			return nil, status.Error(codes.Unauthenticated, "Missing API key")
+		case errors.Is(err, errTokenInvalid):
+			return nil, status.Error(codes.Unauthenticated, "Invalid or expired token")
+		case errors.Is(err, errProviderDown):
+			log.Ctx(ctx).Error().Err(err).Msg("Failed to verify token")
+			return nil, status.Error(codes.Unavailable, "Identity provider unavailable")
+		case errors.Is(err, errNoRole):
+			return nil, status.Error(codes.PermissionDenied, "Token has no role allowed to call this method")
+		case errors.Is(err, errKeyNotAllowed):
+			return nil, status.Error(codes.PermissionDenied, "API key is not allowed to call this method")
+		case err != nil:
+			log.Ctx(ctx).Error().Err(err).Msg("Failed to authenticate")
+			return nil, status.Error(codes.Internal, "Failed to authenticate")
+		}
+		if p.Projects != nil {
+			return nil, status.Error(codes.PermissionDenied, "Tokens limited to some projects can't call this method")
+		}
+		// Methods added without a role are admin only until given one.
+		role, ok := methodRoles[info.FullMethod]
+		if !ok {
+			role = models.RoleAdmin
+		}
+		if !p.hasRole(role) {
+			return nil, status.Error(codes.PermissionDenied, "This method needs the "+role+" role")
+		}
+		actor := audit.Actor{Key: p.Fingerprint, Subject: p.Subject, Role: p.Role, ClientIP: grpcClickContext(ctx).IP}
+		return handler(audit.WithActor(withPrincipal(ctx, p), actor), req)
+	}
+}
+
+func apiKeyFromMetadata(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if keys := md.Get("x-api-key"); len(keys) > 0 && keys[0] != "" {
+		return keys[0]
+	}
+	for _, value := range md.Get("authorization") {
+		if token, ok := strings.CutPrefix(value, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	return ""
+}
+
+type linksServer struct {
+	linkspb.UnimplementedLinksServer
+	services *Services
+}
+
+func (s *linksServer) CreateDurableLink(ctx context.Context, req *linkspb.CreateDurableLinkRequest) (*linkspb.CreateDurableLinkResponse, error) {
+	ctx = db.WithLane(ctx, db.LaneWrite)
+
+	// The request is the REST API's JSON body in proto form, so it is prepared by the same code.
+	body, err := protojson.Marshal(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid request")
+	}
+	var rawReq map[string]any
+	if err := json.Unmarshal(body, &rawReq); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid request")
+	}
+	createReq, err := s.services.linkService.PrepareDurableLinkRequest(ctx, rawReq)
+	if err != nil {
+		return nil, grpcError(prepareErrorDetails(err))
+	}
+
+	resp, err := s.services.linkService.CreateDurableLink(ctx, createReq)
+	if err != nil {
+		return nil, grpcError(createErrorDetails(ctx, err))
+	}
+	return &linkspb.CreateDurableLinkResponse{
+		ShortLink: resp.ShortLink,
+		Code:      resp.Code,
+		Warnings:  pbWarnings(resp.Warnings),
+	}, nil
+}
+
+func (s *linksServer) ExchangeShortLink(ctx context.Context, req *linkspb.ExchangeShortLinkRequest) (*linkspb.ExchangeShortLinkResponse, error) {
+	ctx = db.WithLane(ctx, db.LaneRead)
+	if req.RequestedLink == "" {
+		return nil, status.Error(codes.InvalidArgument, "Invalid or missing requestedLink")
+	}
+
+	link, err := s.services.linkService.ResolveShortPath(ctx, req.RequestedLink, req.Platform, grpcClickContext(ctx))
+	switch {
+	case errors.Is(err, apperrors.ErrLinkDeleted):
+		return nil, status.Error(codes.NotFound, "Link was deleted")
+	case errors.Is(err, apperrors.ErrLinkNotFound):
+		return nil, status.Error(codes.NotFound, "Link not found")
+	case errors.Is(err, apperrors.ErrLinkReserved):
+		return nil, status.Error(codes.NotFound, "Link has no destination yet")
+	case errors.Is(err, apperrors.ErrLinkNotActive):
+		return nil, status.Error(codes.NotFound, "Link is not active yet")
+	case errors.Is(err, apperrors.ErrLinkExpired):
+		return nil, status.Error(codes.NotFound, "Link has expired")
+	case errors.Is(err, apperrors.ErrInvalidPlatform):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, apperrors.ErrInvalidRequestedLink):
+		return nil, status.Error(codes.InvalidArgument, "Invalid requested link")
+	case errors.Is(err, apperrors.ErrPolicyDenied):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
+		log.Ctx(ctx).Error().Err(err).Msg("Failed to resolve short link")
+		return nil, status.Error(codes.Internal, "Failed to resolve link")
+	}
+	return &linkspb.ExchangeShortLinkResponse{LongLink: link.LongLink, Link: link.Link}, nil
+}
+
+func (s *linksServer) GetLink(ctx context.Context, req *linkspb.GetLinkRequest) (*linkspb.Link, error) {
+	ctx = db.WithLane(ctx, db.LaneRead)
+
+	resp, err := s.services.linkService.GetLink(ctx, req.Host, req.Path)
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, apperrors.ErrLinkNotFound):
+		return nil, status.Error(codes.NotFound, "Link not found")
+	case err != nil:
+		log.Ctx(ctx).Error().Err(err).Msg("Failed to get link")
+		return nil, status.Error(codes.Internal, "Failed to get link")
+	}
+	return &linkspb.Link{
+		ShortLink: resp.ShortLink,
+		LongLink:  resp.LongLink,
+		Etag:      resp.ETag,
+		Warnings:  pbWarnings(resp.Warnings),
+	}, nil
+}
+
+func (s *linksServer) DeleteLink(ctx context.Context, req *linkspb.DeleteLinkRequest) (*linkspb.DeleteLinkResponse, error) {
+	ctx = db.WithLane(ctx, db.LaneWrite)
+
+	err := s.services.linkService.DeleteLink(ctx, req.Host, req.Path, req.Hard)
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, apperrors.ErrLinkNotFound):
+		return nil, status.Error(codes.NotFound, "Link not found")
+	case err != nil:
+		log.Ctx(ctx).Error().Err(err).Msg("Failed to delete link")
+		return nil, status.Error(codes.Internal, "Failed to delete link")
+	}
+	return &linkspb.DeleteLinkResponse{}, nil
+}
+
+// grpcCodes maps the statuses of REST error responses, named after the google.rpc codes, to
+// those codes.
+var grpcCodes = map[models.ErrorStatus]codes.Code{
+	models.StatusInvalidArgument:    codes.InvalidArgument,
+	models.StatusFailedPrecondition: codes.FailedPrecondition,
+	models.StatusNotFound:           codes.NotFound,
+	models.StatusAlreadyExists:      codes.AlreadyExists,
+	models.StatusAborted:            codes.Aborted,
+	models.StatusUnauthenticated:    codes.Unauthenticated,
+	models.StatusPermissionDenied:   codes.PermissionDenied,
+	models.StatusResourceExhausted:  codes.ResourceExhausted,
+	models.StatusUnavailable:        codes.Unavailable,
+	models.StatusInternal:           codes.Internal,
+}
+
+// grpcError returns the gRPC error of the REST error response details.
+func grpcError(details models.ErrorDetails) error {
+	code, ok := grpcCodes[details.Status]
+	if !ok {
+		code = codes.Unknown
+	}
+	return status.Error(code, details.Message)
+}
+
+// grpcClickContext is clickContext for gRPC calls, which only carry the caller's address and
+// user agent.
+func grpcClickContext(ctx context.Context) models.ClickContext {
+	var click models.ClickContext
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			click.IP = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if userAgent := md.Get("user-agent"); len(userAgent) > 0 {
+			click.UserAgent = userAgent[0]
+		}
+	}
+	return click
+}
+
+func pbWarnings(warnings []models.DurableLinkCreationWarning) []*linkspb.Warning {
+	pbWarnings := make([]*linkspb.Warning, len(warnings))
+	for i, w := range warnings {
+		pbWarnings[i] = &linkspb.Warning{WarningCode: w.WarningCode, WarningMessage: w.WarningMessage}
+	}
+	return pbWarnings
+}
@@ -1,103 +1,803 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"html/template"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"durable-links-generator/api/apperrors"
 	"durable-links-generator/api/models"
 	"durable-links-generator/api/service"
+	"durable-links-generator/config"
+	"durable-links-generator/utils"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog/log"
 )
 
 type Handler interface {
 	CreateLink(w http.ResponseWriter, r *http.Request)
+	CreateLinks(w http.ResponseWriter, r *http.Request)
 	ExchangeShortLink(w http.ResponseWriter, r *http.Request)
+	Redirect(w http.ResponseWriter, r *http.Request)
+	RedirectCode(w http.ResponseWriter, r *http.Request)
+	ResolveText(w http.ResponseWriter, r *http.Request)
+	RewriteLinks(w http.ResponseWriter, r *http.Request)
+	ListRevisions(w http.ResponseWriter, r *http.Request)
+	SearchLinks(w http.ResponseWriter, r *http.Request)
+	ListLinks(w http.ResponseWriter, r *http.Request)
+	CreateAlias(w http.ResponseWriter, r *http.Request)
+	ListAliases(w http.ResponseWriter, r *http.Request)
+	DeleteAlias(w http.ResponseWriter, r *http.Request)
+	GeoDestinations(w http.ResponseWriter, r *http.Request)
+	SetGeoDestinations(w http.ResponseWriter, r *http.Request)
+	DeleteLink(w http.ResponseWriter, r *http.Request)
+	RestoreLink(w http.ResponseWriter, r *http.Request)
+	GetLink(w http.ResponseWriter, r *http.Request)
+	UpdateLink(w http.ResponseWriter, r *http.Request)
+	ReserveLink(w http.ResponseWriter, r *http.Request)
+	MergeLinks(w http.ResponseWriter, r *http.Request)
+	SupersedeLink(w http.ResponseWriter, r *http.Request)
+	LinkStats(w http.ResponseWriter, r *http.Request)
+	CreateSignedLink(w http.ResponseWriter, r *http.Request)
+	RedirectSigned(w http.ResponseWriter, r *http.Request)
 }
 
 type handler struct {
 	linkService service.LinkService
+	cfg         *config.Config
+	// previewTemplates replace the default preview page for the hosts that have one.
+	previewTemplates map[string]*template.Template
 }
 
-func NewHandler(linkService service.LinkService) Handler {
+func NewHandler(linkService service.LinkService, cfg *config.Config, previewTemplates map[string]*template.Template) Handler {
 	return &handler{
-		linkService: linkService,
+		linkService:      linkService,
+		cfg:              cfg,
+		previewTemplates: previewTemplates,
 	}
 }
 
+// CreateLink serves "POST /shortLinks". With validateDestination=true the destination is requested
+// first, and the response warns if that fails.
 func (h *handler) CreateLink(w http.ResponseWriter, r *http.Request) {
+	validateDestination := false
+	if raw := r.URL.Query().Get("validateDestination"); raw != "" {
+		var err error
+		if validateDestination, err = strconv.ParseBool(raw); err != nil {
+			WriteErrorResponse(w, http.StatusBadRequest, "validateDestination must be true or false", models.StatusInvalidArgument)
+			return
+		}
+	}
+
 	var rawReq map[string]any
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
 	if err := json.NewDecoder(r.Body).Decode(&rawReq); err != nil {
-		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", "INVALID_ARGUMENT")
+		writeBodyError(w, err)
 		return
 	}
 
-	createReq, err := h.linkService.PrepareDurableLinkRequest(rawReq)
+	createReq, err := h.linkService.PrepareDurableLinkRequest(r.Context(), rawReq)
 	if err != nil {
-		switch {
-		case errors.Is(err, apperrors.ErrInvalidURLFormat):
-			WriteErrorResponse(w, http.StatusBadRequest, "longDurableLink is not parsable", "INVALID_ARGUMENT")
-		case errors.Is(err, apperrors.ErrHostInvalid):
-			WriteErrorResponse(w, http.StatusBadRequest, "Host is invalid", "INVALID_ARGUMENT")
-		case errors.Is(err, apperrors.ErrInvalidFormat),
-			errors.Is(err, apperrors.ErrMissingHost),
-			errors.Is(err, apperrors.ErrMissingLink):
-			WriteErrorResponse(w, http.StatusBadRequest, err.Error(), "INVALID_ARGUMENT")
-		default:
-			WriteErrorResponse(w, http.StatusBadRequest, "Invalid request format", "INVALID_ARGUMENT")
-		}
+		writePrepareError(w, err)
 		return
 	}
+	createReq.ValidateDestination = validateDestination
 
 	shortLinkResp, err := h.linkService.CreateDurableLink(r.Context(), createReq)
-	if errors.Is(err, apperrors.ErrDomainLinkNotAllowed) {
-		WriteErrorResponse(w, http.StatusBadRequest, "'link' parameter contains a host that is not in the allow list", "INVALID_ARGUMENT")
+	if err != nil {
+		writeCreateError(w, r, err)
 		return
-	} else if errors.Is(err, apperrors.ErrInvalidAppStoreID) {
-		WriteErrorResponse(w, http.StatusBadRequest, "'isbn' parameter contains a non-numeric value", "INVALID_ARGUMENT")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shortLinkResp)
+}
+
+// CreateLinks serves "POST /shortLinks:batch". Requests that fail don't fail the call, the error
+// is reported in their result instead.
+func (h *handler) CreateLinks(w http.ResponseWriter, r *http.Request) {
+	var req models.BatchCreateLinksRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBodyError(w, err)
 		return
-	} else if err != nil {
-		log.Error().Err(err).Msg("Failed to create durable link")
-		WriteErrorResponse(w, http.StatusInternalServerError, "Failed to create link", "INTERNAL")
+	}
+	if len(req.Requests) == 0 || len(req.Requests) > h.cfg.App.BatchMaxLinks {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("requests must hold 1 to %d links", h.cfg.App.BatchMaxLinks), models.StatusInvalidArgument)
 		return
 	}
 
+	results := make([]models.BatchLinkResult, len(req.Requests))
+	createReqs := make([]models.CreateDurableLinkRequest, 0, len(req.Requests))
+	// indexes maps the position in createReqs to the position in the batch.
+	indexes := make([]int, 0, len(req.Requests))
+	for i, rawReq := range req.Requests {
+		createReq, err := h.linkService.PrepareDurableLinkRequest(r.Context(), rawReq)
+		if err != nil {
+			details := prepareErrorDetails(err)
+			results[i].Error = &details
+			continue
+		}
+		createReqs = append(createReqs, createReq)
+		indexes = append(indexes, i)
+	}
+
+	if len(createReqs) > 0 {
+		created, err := h.linkService.CreateDurableLinks(r.Context(), createReqs)
+		if err != nil {
+			writeCreateError(w, r, err)
+			return
+		}
+		for i, result := range created {
+			if result.Err != nil {
+				details := createErrorDetails(r.Context(), result.Err)
+				results[indexes[i]].Error = &details
+				continue
+			}
+			results[indexes[i]].ShortLinkResponse = result.Link
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(shortLinkResp)
+	json.NewEncoder(w).Encode(models.BatchCreateLinksResponse{Results: results})
+}
+
+func (h *handler) SupersedeLink(w http.ResponseWriter, r *http.Request) {
+	var rawReq map[string]any
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+	if err := json.NewDecoder(r.Body).Decode(&rawReq); err != nil {
+		writeBodyError(w, err)
+		return
+	}
+	redirect, _ := rawReq["redirectOldLink"].(bool)
+	delete(rawReq, "redirectOldLink")
+
+	createReq, err := h.linkService.PrepareDurableLinkRequest(r.Context(), rawReq)
+	if err != nil {
+		writePrepareError(w, err)
+		return
+	}
+
+	resp, err := h.linkService.SupersedeLink(r.Context(), chi.URLParam(r, "path"), createReq, redirect)
+	switch {
+	case errors.Is(err, apperrors.ErrLinkNotFound):
+		WriteError(w, err, http.StatusNotFound, "Link not found", models.StatusNotFound)
+	case errors.Is(err, apperrors.ErrSupersedeSelf):
+		WriteError(w, err, http.StatusBadRequest, "New link is identical to the superseded link", models.StatusInvalidArgument)
+	case err != nil:
+		writeCreateError(w, r, err)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func writePrepareError(w http.ResponseWriter, err error) {
+	writeErrorDetails(w, prepareErrorDetails(err))
+}
+
+func prepareErrorDetails(err error) models.ErrorDetails {
+	switch {
+	case errors.Is(err, apperrors.ErrInvalidURLFormat):
+		return errorDetails(err, http.StatusBadRequest, "longDurableLink is not parsable", models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrHostInvalid):
+		return errorDetails(err, http.StatusBadRequest, "Host is invalid", models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrInvalidFormat),
+		errors.Is(err, apperrors.ErrMissingHost),
+		errors.Is(err, apperrors.ErrMissingLink),
+		errors.Is(err, apperrors.ErrInvalidDeepLink):
+		return errorDetails(err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	default:
+		return errorDetails(err, http.StatusBadRequest, "Invalid request format", models.StatusInvalidArgument)
+	}
+}
+
+func writeCreateError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, apperrors.ErrTenantLimited) {
+		writeTenantLimited(w)
+		return
+	}
+	details := createErrorDetails(r.Context(), err)
+	WriteError(w, err, details.Code, details.Message, details.Status)
+}
+
+func createErrorDetails(ctx context.Context, err error) models.ErrorDetails {
+	switch {
+	case errors.Is(err, apperrors.ErrDomainLinkNotAllowed):
+		return errorDetails(err, http.StatusBadRequest, "'link' parameter contains a host that is not in the allow list", models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrFallbackNotAllowed),
+		errors.Is(err, apperrors.ErrImageNotAllowed):
+		return errorDetails(err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrPIIDetected):
+		return errorDetails(err, http.StatusBadRequest, "Destination URL appears to contain personal data", models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrUnsafeURL):
+		return errorDetails(err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrReputationUnavailable):
+		log.Ctx(ctx).Error().Err(err).Msg("URL reputation check failed")
+		return errorDetails(err, http.StatusServiceUnavailable, "Destination URLs couldn't be checked, retry later", models.StatusUnavailable)
+	case errors.Is(err, apperrors.ErrInvalidGate):
+		return errorDetails(err, http.StatusBadRequest, "'gate' parameter must be one of: age, terms", models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrInvalidDeepLink),
+		errors.Is(err, apperrors.ErrInvalidPlatformLink),
+		errors.Is(err, apperrors.ErrWarningAsError),
+		errors.Is(err, apperrors.ErrSMSBudgetExceeded),
+		errors.Is(err, apperrors.ErrInvalidSuffix),
+		errors.Is(err, apperrors.ErrCodeNotAllowed),
+		errors.Is(err, apperrors.ErrInvalidMetadata),
+		errors.Is(err, apperrors.ErrUTMPresetNotFound),
+		errors.Is(err, apperrors.ErrInvalidGeoDestination),
+		errors.Is(err, apperrors.ErrInvalidDeviceRule),
+		errors.Is(err, apperrors.ErrInvalidVariant),
+		errors.Is(err, apperrors.ErrInvalidMaxClicks),
+		errors.Is(err, apperrors.ErrInvalidExpiredLink),
+		errors.Is(err, apperrors.ErrInvalidActiveWindow),
+		errors.Is(err, apperrors.ErrInvalidPath),
+		errors.Is(err, apperrors.ErrReservedPath),
+		errors.Is(err, apperrors.ErrEmptyBatch),
+		errors.Is(err, apperrors.ErrBatchTooLarge),
+		errors.Is(err, apperrors.ErrHostInvalid):
+		return errorDetails(err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrPathTaken):
+		return errorDetails(err, http.StatusConflict, "suffix.customPath is already in use on this host", models.StatusAlreadyExists)
+	case errors.Is(err, apperrors.ErrInvalidAppStoreID):
+		return errorDetails(err, http.StatusBadRequest, "'isbn' parameter contains a non-numeric value", models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrTenantLimited):
+		return errorDetails(err, http.StatusTooManyRequests, "Too many link changes for this domain, retry later", models.StatusResourceExhausted)
+	case errors.Is(err, apperrors.ErrNoFreePath):
+		return errorDetails(err, http.StatusServiceUnavailable, "No free path for the link, retry later", models.StatusUnavailable)
+	case errors.Is(err, apperrors.ErrPolicyDenied):
+		return errorDetails(err, http.StatusBadRequest, err.Error(), models.StatusFailedPrecondition)
+	default:
+		log.Ctx(ctx).Error().Err(err).Msg("Failed to create durable link")
+		return errorDetails(err, http.StatusInternalServerError, "Failed to create link", models.StatusInternal)
+	}
+}
+
+func writeTenantLimited(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "1")
+	WriteError(w, apperrors.ErrTenantLimited, http.StatusTooManyRequests, "Too many link changes for this domain, retry later", models.StatusResourceExhausted)
 }
 
 func (h *handler) ExchangeShortLink(w http.ResponseWriter, r *http.Request) {
 	var req models.ExchangeShortLinkRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RequestedLink == "" {
-		WriteErrorResponse(w, http.StatusBadRequest, "Invalid or missing requestedLink", "INVALID_ARGUMENT")
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid or missing requestedLink", models.StatusInvalidArgument)
 		return
 	}
 
-	link, err := h.linkService.ResolveShortPath(r.Context(), req.RequestedLink)
+	link, err := h.linkService.ResolveShortPath(r.Context(), req.RequestedLink, req.Platform, h.clickContext(r))
 	switch {
+	case errors.Is(err, apperrors.ErrLinkDeleted):
+		WriteError(w, err, http.StatusGone, "Link was deleted", models.StatusNotFound)
 	case errors.Is(err, apperrors.ErrLinkNotFound):
-		WriteErrorResponse(w, http.StatusNotFound, "Link not found", "NOT_FOUND")
+		WriteError(w, err, http.StatusNotFound, "Link not found", models.StatusNotFound)
+	case errors.Is(err, apperrors.ErrLinkReserved):
+		WriteError(w, err, http.StatusNotFound, "Link has no destination yet", models.StatusNotFound)
+	case errors.Is(err, apperrors.ErrLinkNotActive):
+		WriteError(w, err, http.StatusNotFound, "Link is not active yet", models.StatusNotFound)
+	case errors.Is(err, apperrors.ErrLinkExpired):
+		WriteError(w, err, http.StatusGone, "Link has expired", models.StatusNotFound)
+	case errors.Is(err, apperrors.ErrInvalidPlatform):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
 	case errors.Is(err, apperrors.ErrInvalidRequestedLink):
-		WriteErrorResponse(w, http.StatusBadRequest, "Invalid requested link", "INVALID_ARGUMENT")
+		WriteError(w, err, http.StatusBadRequest, "Invalid requested link", models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrPolicyDenied):
+		WriteError(w, err, http.StatusForbidden, err.Error(), models.StatusPermissionDenied)
 	case err != nil:
-		log.Error().Err(err).Msg("Failed to resolve short link")
-		WriteErrorResponse(w, http.StatusInternalServerError, "Failed to resolve link", "INTERNAL")
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to resolve short link")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to resolve link", models.StatusInternal)
 	default:
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(link)
 	}
 }
 
-func WriteErrorResponse(w http.ResponseWriter, code int, message string, status string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(models.ErrorResponse{
-		Error: models.ErrorDetails{
-			Code:    code,
-			Message: message,
-			Status:  status,
-		},
+func (h *handler) Redirect(w http.ResponseWriter, r *http.Request) {
+	path := chi.URLParam(r, "shortPath")
+	if infoPath, ok := strings.CutSuffix(path, "+"); ok {
+		h.linkInfo(w, r, infoPath)
+		return
+	}
+	h.redirect(w, r, path)
+}
+
+// RedirectCode serves "/c/{code}", following a link by its numeric code.
+func (h *handler) RedirectCode(w http.ResponseWriter, r *http.Request) {
+	path, err := h.linkService.ResolveCode(r.Context(), r.Host, chi.URLParam(r, "code"))
+	switch {
+	case errors.Is(err, apperrors.ErrLinkNotFound):
+		http.NotFound(w, r)
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to resolve code")
+		http.Error(w, "Failed to resolve link", http.StatusInternalServerError)
+	default:
+		h.redirect(w, r, path)
+	}
+}
+
+func (h *handler) redirect(w http.ResponseWriter, r *http.Request, path string) {
+	// Ask for the device model on later visits; the platform hint is sent by default. The
+	// response depends on both, so caches must key on them.
+	w.Header().Set("Accept-CH", "Sec-CH-UA-Platform, Sec-CH-UA-Model")
+	w.Header().Add("Vary", "User-Agent, Sec-CH-UA-Platform")
+
+	target, err := h.linkService.ResolveRedirect(r.Context(), r.Host, path, h.clickContext(r))
+	if err == nil && target.Variant != "" {
+		// Visitors of an A/B tested link are split per visitor; a shared cache would send them
+		// all to one variant.
+		w.Header().Set("Cache-Control", "private, no-store")
+		if target.VisitorID != "" {
+			http.SetCookie(w, &http.Cookie{
+				Name:     visitorCookie,
+				Value:    target.VisitorID,
+				Path:     "/",
+				MaxAge:   365 * 24 * 60 * 60,
+				HttpOnly: true,
+				Secure:   r.TLS != nil,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+	}
+	switch {
+	case errors.Is(err, apperrors.ErrLinkDeleted):
+		http.Error(w, "This link has been deleted", http.StatusGone)
+	case errors.Is(err, apperrors.ErrLinkNotFound):
+		http.NotFound(w, r)
+	case errors.Is(err, apperrors.ErrLinkReserved):
+		h.serveReservedLink(w, r)
+	case errors.Is(err, apperrors.ErrLinkNotActive):
+		serveLinkBehavior(w, r, h.cfg.App.PrelaunchLinkBehavior, "coming_soon.html", http.StatusOK)
+	case errors.Is(err, apperrors.ErrLinkExpired):
+		serveLinkBehavior(w, r, h.cfg.App.ExpiredLinkBehavior, "expired.html", http.StatusGone)
+	case errors.Is(err, apperrors.ErrPolicyDenied):
+		http.Error(w, "This link is not available", http.StatusForbidden)
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Str("path", path).Msg("Failed to resolve redirect")
+		http.Error(w, "Failed to resolve link", http.StatusInternalServerError)
+	// The gate comes before the previews, which show the destination.
+	case target.Gate != "" && !h.acknowledged(w, r, target.Gate):
+		h.renderInterstitial(w, r, target)
+	case target.SocialPreview:
+		h.renderSocialPreview(w, r, target)
+	case target.ScannerPreview:
+		renderPage(w, http.StatusOK, "scanner_preview.html", scannerPreviewPage{
+			Destination:     target.Destination,
+			DestinationHost: hostOf(target.Destination),
+		})
+	case target.AppPreview != nil:
+		h.renderPreview(w, target)
+	case target.QRCode != "":
+		h.renderQRCode(w, target)
+	case target.DesktopApp != nil:
+		renderPage(w, http.StatusOK, "desktop_app.html", desktopAppPage{
+			ProtocolLink:    template.URL(target.DesktopApp.ProtocolLink),
+			StoreLink:       template.URL(target.DesktopApp.StoreLink),
+			StoreName:       target.DesktopApp.StoreName,
+			Destination:     target.Destination,
+			DestinationHost: hostOf(target.Destination),
+		})
+	default:
+		http.Redirect(w, r, target.Destination, http.StatusFound)
+	}
+}
+
+// renderInterstitial serves the page of target's gate, which the visitor acknowledges to go on to
+// the destination.
+func (h *handler) renderInterstitial(w http.ResponseWriter, r *http.Request, target *models.RedirectTarget) {
+	renderPage(w, http.StatusOK, pageVariant(r, "interstitial.html"), interstitialPage{
+		Gate:            target.Gate,
+		MinAge:          h.cfg.App.InterstitialMinAge,
+		TermsURL:        h.cfg.App.InterstitialTermsURL,
+		DestinationHost: hostOf(target.Destination),
+		Action:          r.URL.Path,
+		NoJSURL:         noJSURL(r),
+	})
+}
+
+// renderQRCode serves the page showing the short link as a QR code to continue on a phone.
+func (h *handler) renderQRCode(w http.ResponseWriter, target *models.RedirectTarget) {
+	image, err := qrCodeImage(target.QRCode)
+	if err != nil {
+		requestLog(w).Error().Err(err).Str("short_link", target.QRCode).Msg("Failed to encode QR code")
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+		return
+	}
+	renderPage(w, http.StatusOK, "qr_code.html", qrCodePage{
+		ShortLink:       target.QRCode,
+		QRCode:          image,
+		DestinationHost: hostOf(target.Destination),
+		Title:           target.Preview.SocialTitle,
+		Description:     target.Preview.SocialDescription,
+		ImageURL:        target.Preview.SocialImageLink,
+	})
+}
+
+// renderPreview serves the preview page of preview hosts, with the host's own template if it has
+// one. Visitors only get here past the gate, if any.
+func (h *handler) renderPreview(w http.ResponseWriter, target *models.RedirectTarget) {
+	page := previewPage{
+		ShortLink:          target.AppPreview.ShortLink,
+		Destination:        target.Destination,
+		DestinationHost:    hostOf(target.Destination),
+		Title:              target.Preview.SocialTitle,
+		Description:        target.Preview.SocialDescription,
+		ImageURL:           target.Preview.SocialImageLink,
+		PlayStoreLink:      target.AppPreview.PlayStoreLink,
+		AppStoreLink:       target.AppPreview.AppStoreLink,
+		AndroidPackageName: target.AppPreview.AndroidPackageName,
+	}
+	if t, ok := h.previewTemplates[strings.ToLower(target.AppPreview.Host)]; ok {
+		renderTemplate(w, http.StatusOK, t, page)
+		return
+	}
+	renderPage(w, http.StatusOK, "preview.html", page)
+}
+
+// renderSocialPreview serves crawlers the link's social tags, so shared links get the title,
+// description and image their creator chose rather than whatever the destination has.
+func (h *handler) renderSocialPreview(w http.ResponseWriter, r *http.Request, target *models.RedirectTarget) {
+	renderPage(w, http.StatusOK, "social_preview.html", socialPreviewPage{
+		ShortLink:       h.cfg.App.URLScheme + "://" + r.Host + r.URL.Path,
+		Destination:     target.Destination,
+		DestinationHost: hostOf(target.Destination),
+		Title:           target.Preview.SocialTitle,
+		Description:     target.Preview.SocialDescription,
+		ImageURL:        target.Preview.SocialImageLink,
 	})
 }
+
+// linkInfo serves the "/{path}+" page telling visitors where a link leads before they follow it.
+// Continuing follows the short link itself, so that its gate still applies.
+func (h *handler) linkInfo(w http.ResponseWriter, r *http.Request, path string) {
+	info, err := h.linkService.GetLinkInfo(r.Context(), r.Host, path)
+	switch {
+	case errors.Is(err, apperrors.ErrLinkNotFound), errors.Is(err, apperrors.ErrLinkReserved):
+		http.NotFound(w, r)
+	case errors.Is(err, apperrors.ErrPolicyDenied):
+		http.Error(w, "This link is not available", http.StatusForbidden)
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Str("path", path).Msg("Failed to load link info")
+		http.Error(w, "Failed to load link info", http.StatusInternalServerError)
+	default:
+		renderPage(w, http.StatusOK, "link_info.html", info)
+	}
+}
+
+// ResolveText returns just the destination of a short link as plain text, for curl scripts and
+// other clients that can't follow redirects or parse JSON.
+func (h *handler) ResolveText(w http.ResponseWriter, r *http.Request) {
+	u, err := url.Parse(r.URL.Query().Get("link"))
+	if err != nil || u.Host == "" {
+		http.Error(w, "Invalid or missing link", http.StatusBadRequest)
+		return
+	}
+
+	path := strings.Trim(u.Path, "/")
+	if path == "" || strings.Contains(path, "/") {
+		http.Error(w, "Invalid requested link", http.StatusBadRequest)
+		return
+	}
+
+	// Gates can't be acknowledged here, so resolving a gated link isn't a click.
+	click := h.clickContext(r)
+	click.Acknowledged = nil
+	target, err := h.linkService.ResolveRedirect(r.Context(), u.Host, path, click)
+	switch {
+	case errors.Is(err, apperrors.ErrLinkDeleted):
+		http.Error(w, "This link has been deleted", http.StatusGone)
+	case errors.Is(err, apperrors.ErrLinkNotFound):
+		http.Error(w, "Link not found", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrLinkReserved):
+		http.Error(w, "Link has no destination yet", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrLinkNotActive):
+		serveTextLinkBehavior(w, h.cfg.App.PrelaunchLinkBehavior, "Link is not active yet", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrLinkExpired):
+		serveTextLinkBehavior(w, h.cfg.App.ExpiredLinkBehavior, "Link has expired", http.StatusGone)
+	case errors.Is(err, apperrors.ErrPolicyDenied):
+		http.Error(w, "This link is not available", http.StatusForbidden)
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Str("link", u.String()).Msg("Failed to resolve link")
+		http.Error(w, "Failed to resolve link", http.StatusInternalServerError)
+	case target.Gate != "":
+		http.Error(w, "Link requires acknowledgment in a browser", http.StatusForbidden)
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, target.Destination)
+	}
+}
+
+func (h *handler) RewriteLinks(w http.ResponseWriter, r *http.Request) {
+	var req models.RewriteLinksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+
+	resp, err := h.linkService.RewriteDestinations(r.Context(), req)
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost),
+		errors.Is(err, apperrors.ErrHostInvalid),
+		errors.Is(err, apperrors.ErrInvalidRewriteRule):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrTenantLimited):
+		writeTenantLimited(w)
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to rewrite links")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to rewrite links", models.StatusInternal)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func (h *handler) MergeLinks(w http.ResponseWriter, r *http.Request) {
+	var req models.MergeLinksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+
+	resp, err := h.linkService.MergeDuplicates(r.Context(), req)
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost),
+		errors.Is(err, apperrors.ErrHostInvalid),
+		errors.Is(err, apperrors.ErrInvalidFormat):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrTenantLimited):
+		writeTenantLimited(w)
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to merge links")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to merge links", models.StatusInternal)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func (h *handler) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	revisions, err := h.linkService.ListRevisions(r.Context(), query.Get("host"), query.Get("path"))
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to list revisions")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to list revisions", models.StatusInternal)
+	default:
+		writeProjectedJSON(w, r, models.LinkRevisionsResponse{Revisions: revisions}, "revisions")
+	}
+}
+
+func (h *handler) SearchLinks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := models.SearchRequest{Query: query.Get("q"), Host: query.Get("host"), Sort: query.Get("sort")}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			WriteErrorResponse(w, http.StatusBadRequest, "limit must be a positive integer", models.StatusInvalidArgument)
+			return
+		}
+		req.Limit = limit
+	}
+
+	resp, err := h.linkService.SearchLinks(r.Context(), req)
+	switch {
+	case errors.Is(err, apperrors.ErrMissingQuery),
+		errors.Is(err, apperrors.ErrMissingHost),
+		errors.Is(err, apperrors.ErrInvalidSort):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to search links")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to search links", models.StatusInternal)
+	default:
+		writeProjectedJSON(w, r, resp, "results")
+	}
+}
+
+func (h *handler) ListLinks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := models.ListLinksRequest{
+		Host:              query.Get("host"),
+		Sort:              query.Get("sort"),
+		PageToken:         query.Get("pageToken"),
+		Suffix:            query.Get("suffix"),
+		DestinationDomain: query.Get("destinationDomain"),
+		Tags:              query["tag"],
+	}
+	if raw := query.Get("pageSize"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size < 1 {
+			WriteErrorResponse(w, http.StatusBadRequest, "pageSize must be a positive integer", models.StatusInvalidArgument)
+			return
+		}
+		req.PageSize = size
+	}
+	bounds := []struct {
+		param string
+		dst   *time.Time
+	}{
+		{"createdAfter", &req.CreatedAfter},
+		{"createdBefore", &req.CreatedBefore},
+	}
+	for _, bound := range bounds {
+		if raw := query.Get(bound.param); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				WriteErrorResponse(w, http.StatusBadRequest, bound.param+" must be an RFC 3339 timestamp", models.StatusInvalidArgument)
+				return
+			}
+			*bound.dst = t
+		}
+	}
+
+	resp, err := h.linkService.ListLinks(r.Context(), req)
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost),
+		errors.Is(err, apperrors.ErrInvalidListSort),
+		errors.Is(err, apperrors.ErrInvalidPageToken),
+		errors.Is(err, apperrors.ErrInvalidDateRange),
+		errors.Is(err, apperrors.ErrInvalidListSuffix),
+		errors.Is(err, apperrors.ErrInvalidDestinationDomain),
+		errors.Is(err, apperrors.ErrInvalidMetadata):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to list links")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to list links", models.StatusInternal)
+	default:
+		writeProjectedJSON(w, r, resp, "links")
+	}
+}
+
+// ackCookiePrefix names the cookies remembering the gates a visitor has passed.
+const ackCookiePrefix = "dl_ack_"
+
+// acknowledgedGates returns the gates the visitor has passed, either by submitting the
+// interstitial form now or earlier, as remembered by a cookie on the short link host.
+func acknowledgedGates(r *http.Request) []string {
+	var gates []string
+	for _, c := range r.Cookies() {
+		if gate, ok := strings.CutPrefix(c.Name, ackCookiePrefix); ok && c.Value == "1" {
+			gates = append(gates, gate)
+		}
+	}
+	if gate := r.URL.Query().Get("ack"); gate != "" {
+		gates = append(gates, gate)
+	}
+	return gates
+}
+
+// acknowledged reports whether the visitor has passed the gate, remembering it in a cookie when
+// they just did.
+func (h *handler) acknowledged(w http.ResponseWriter, r *http.Request, gate string) bool {
+	cookieName := ackCookiePrefix + gate
+	if c, err := r.Cookie(cookieName); err == nil && c.Value == "1" {
+		return true
+	}
+	if r.URL.Query().Get("ack") != gate {
+		return false
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     cookieName,
+		Value:    "1",
+		Path:     "/",
+		MaxAge:   30 * 24 * 60 * 60,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return true
+}
+
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// visitorCookie keeps a visitor on the same A/B test variant of links across visits.
+const visitorCookie = "dl_visitor"
+
+// clickContext collects the consent signals sent with a click: a TCF string from the standard
+// gdpr_consent param or euconsent-v2 cookie, and the configured consent param or cookie.
+func (h *handler) clickContext(r *http.Request) models.ClickContext {
+	click := models.ClickContext{
+		TCString:       r.URL.Query().Get("gdpr_consent"),
+		ConsentValue:   r.URL.Query().Get(h.cfg.App.ConsentParam),
+		UserAgent:      r.UserAgent(),
+		PlatformHint:   r.Header.Get("Sec-CH-UA-Platform"),
+		ModelHint:      strings.Trim(r.Header.Get("Sec-CH-UA-Model"), `"`),
+		Head:           r.Method == http.MethodHead,
+		IP:             clientIP(r),
+		AcceptLanguage: r.Header.Get("Accept-Language"),
+		Referrer:       r.Referer(),
+		Acknowledged:   acknowledgedGates(r),
+	}
+	if click.TCString == "" {
+		if c, err := r.Cookie("euconsent-v2"); err == nil {
+			click.TCString = c.Value
+		}
+	}
+	if click.ConsentValue == "" && h.cfg.App.ConsentCookie != "" {
+		if c, err := r.Cookie(h.cfg.App.ConsentCookie); err == nil {
+			click.ConsentValue = c.Value
+		}
+	}
+	if c, err := r.Cookie(visitorCookie); err == nil && len(c.Value) <= 64 {
+		click.VisitorID = c.Value
+	}
+	return click
+}
+
+// errorDomain is the domain of the ErrorInfo of error responses.
+const errorDomain = "durable-links-generator"
+
+// WriteErrorResponse writes an error response for a failure without an apperrors error behind
+// it. Its reason is the status.
+func WriteErrorResponse(w http.ResponseWriter, code int, message string, status models.ErrorStatus) {
+	WriteError(w, nil, code, message, status)
+}
+
+// WriteError writes an error response for err, with the code of err as the reason.
+func WriteError(w http.ResponseWriter, err error, code int, message string, status models.ErrorStatus) {
+	writeErrorDetails(w, errorDetails(err, code, message, status))
+}
+
+// writeBodyError answers a request whose body, limited by http.MaxBytesReader, couldn't be read
+// or decoded.
+func writeBodyError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		WriteErrorResponse(w, http.StatusRequestEntityTooLarge, "Request body must be at most 1 MiB", models.StatusInvalidArgument)
+		return
+	}
+	WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+}
+
+func writeErrorDetails(w http.ResponseWriter, details models.ErrorDetails) {
+	details.RequestID = w.Header().Get(requestIDHeader)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(details.Code)
+	json.NewEncoder(w).Encode(models.ErrorResponse{Error: details})
+}
+
+func errorDetails(err error, code int, message string, status models.ErrorStatus) models.ErrorDetails {
+	reason := apperrors.Code(err)
+	if reason == "" {
+		reason = string(status)
+	}
+	return models.ErrorDetails{
+		Code:      code,
+		Message:   message,
+		Status:    status,
+		Retryable: status.Retryable(),
+		Details:   []models.ErrorInfo{{Type: models.ErrorInfoType, Reason: reason, Domain: errorDomain}},
+	}
+}
+
+// writeProjectedJSON writes v as JSON, trimmed to the fields named in the fields query param.
+// listKey names the list whose items the fields select, or is empty to select v's own fields.
+func writeProjectedJSON(w http.ResponseWriter, r *http.Request, v any, listKey string) {
+	data, err := json.Marshal(v)
+	if err == nil {
+		data, err = utils.ProjectFields(data, listKey, utils.ParseFields(r.URL.Query().Get("fields")))
+	}
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to encode response")
+		WriteErrorResponse(w, http.StatusInternalServerError, "Failed to encode response", models.StatusInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(data, '\n'))
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/service"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeLinkService resolves every link to target or err. Methods a test doesn't need fall through
+// to the embedded nil interface and panic.
+type fakeLinkService struct {
+	service.LinkService
+	target *models.RedirectTarget
+	err    error
+	clicks []models.ClickContext
+}
+
+func (f *fakeLinkService) ResolveRedirect(_ context.Context, _, _ string, click models.ClickContext) (*models.RedirectTarget, error) {
+	f.clicks = append(f.clicks, click)
+	return f.target, f.err
+}
+
+func (f *fakeLinkService) ResolveSignedLink(_ context.Context, _, _ string, click models.ClickContext) (*models.RedirectTarget, error) {
+	f.clicks = append(f.clicks, click)
+	return f.target, f.err
+}
+
+func TestResolveText(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   *models.RedirectTarget
+		err      error
+		behavior string
+		wantCode int
+		wantBody string
+	}{
+		{name: "destination", target: &models.RedirectTarget{Destination: "https://example.com"}, wantCode: http.StatusOK, wantBody: "https://example.com\n"},
+		{name: "expired", err: apperrors.ErrLinkExpired, wantCode: http.StatusGone, wantBody: "Link has expired\n"},
+		{name: "expired elsewhere", err: apperrors.ErrLinkExpired, behavior: "https://example.com/over", wantCode: http.StatusOK, wantBody: "https://example.com/over\n"},
+		{name: "not active", err: apperrors.ErrLinkNotActive, wantCode: http.StatusNotFound, wantBody: "Link is not active yet\n"},
+		{name: "deleted", err: apperrors.ErrLinkDeleted, wantCode: http.StatusGone, wantBody: "This link has been deleted\n"},
+		{name: "gated", target: &models.RedirectTarget{Destination: "https://example.com", Gate: "age"}, wantCode: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links := &fakeLinkService{target: tt.target, err: tt.err}
+			cfg := &config.Config{App: &config.AppConfig{ExpiredLinkBehavior: tt.behavior}}
+			h := NewHandler(links, cfg, nil)
+
+			r := httptest.NewRequest(http.MethodGet, "/v1/resolve.txt?link=https://acme.link/abc&ack=age", nil)
+			r.AddCookie(&http.Cookie{Name: "dl_ack_age", Value: "1"})
+			w := httptest.NewRecorder()
+			h.ResolveText(w, r)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+			if assert.Len(t, links.clicks, 1) {
+				assert.Empty(t, links.clicks[0].Acknowledged, "gates can't be passed here")
+			}
+		})
+	}
+}
+
+func TestRedirectSigned(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		cookie       bool
+		wantCode     int
+		wantLocation string
+	}{
+		{name: "gate shown", wantCode: http.StatusOK},
+		{name: "gate acknowledged", query: "?ack=age", wantCode: http.StatusFound, wantLocation: "https://example.com"},
+		{name: "gate passed before", cookie: true, wantCode: http.StatusFound, wantLocation: "https://example.com"},
+		{name: "other gate acknowledged", query: "?ack=terms", wantCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links := &fakeLinkService{target: &models.RedirectTarget{Destination: "https://example.com", Gate: "age"}}
+			h := NewHandler(links, &config.Config{App: &config.AppConfig{InterstitialMinAge: 18}}, nil)
+
+			r := httptest.NewRequest(http.MethodGet, "/s/token"+tt.query, nil)
+			if tt.cookie {
+				r.AddCookie(&http.Cookie{Name: "dl_ack_age", Value: "1"})
+			}
+			w := httptest.NewRecorder()
+			h.RedirectSigned(w, r)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, tt.wantLocation, w.Header().Get("Location"))
+			if tt.wantLocation == "" {
+				assert.Contains(t, w.Body.String(), "example.com")
+			}
+		})
+	}
+}
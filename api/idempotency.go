@@ -0,0 +1,125 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotentReplayedHeader marks a response replayed from an earlier request.
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// Idempotent replays the stored response of a request sent again with the same Idempotency-Key
+// by the same caller, per API key or per client IP without one, for ttl after the first one, so
+// a client retrying a request whose response it missed doesn't create a second link. Sending the
+// key with a different request is rejected, as is sending it while the first request is still
+// running. Responses that are worth retrying, 429s and 5xx, aren't stored. When the store fails,
+// requests go through.
+func Idempotent(store repository.IdempotencyRepository, ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				WriteError(w, apperrors.ErrInvalidIdempotencyKey, http.StatusBadRequest, apperrors.ErrInvalidIdempotencyKey.Error(), models.StatusInvalidArgument)
+				return
+			}
+
+			// The body is held in memory to be hashed, so it gets the limit of the handlers.
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBody))
+			if err != nil {
+				writeBodyError(w, err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			caller := "ip:" + clientIP(r)
+			if fingerprint := callerFingerprint(r); fingerprint != "" {
+				caller = "key:" + fingerprint
+			}
+			hash := requestHash(r, body)
+
+			existing, err := store.ClaimIdempotencyKey(r.Context(), caller, key, hash, time.Now().Add(-ttl))
+			switch {
+			case err != nil:
+				log.Ctx(r.Context()).Error().Err(err).Msg("Idempotency key check failed, letting the request through")
+				next.ServeHTTP(w, r)
+				return
+			case existing == nil:
+				serveIdempotent(store, caller, key, next, w, r)
+				return
+			case existing.RequestHash != hash:
+				WriteError(w, apperrors.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, apperrors.ErrIdempotencyKeyReused.Error(), models.StatusInvalidArgument)
+			case existing.StatusCode == 0:
+				w.Header().Set("Retry-After", "1")
+				WriteError(w, apperrors.ErrIdempotencyKeyInUse, http.StatusConflict, apperrors.ErrIdempotencyKeyInUse.Error(), models.StatusAborted)
+			default:
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set(idempotentReplayedHeader, "true")
+				w.WriteHeader(existing.StatusCode)
+				io.WriteString(w, existing.Body)
+			}
+		})
+	}
+}
+
+// serveIdempotent runs the request that claimed key and stores its response, or releases the
+// key when the response isn't to be replayed, e.g. the handler panicked.
+func serveIdempotent(store repository.IdempotencyRepository, caller, key string, next http.Handler, w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+	ww.Tee(&buf)
+
+	// The response is stored even when the client went away, that's when it retries.
+	ctx := context.WithoutCancel(r.Context())
+	saved := false
+	defer func() {
+		if saved {
+			return
+		}
+		if err := store.ReleaseIdempotencyKey(ctx, caller, key); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Failed to release idempotency key")
+		}
+	}()
+
+	next.ServeHTTP(ww, r)
+
+	status := ww.Status()
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if status == http.StatusTooManyRequests || status >= 500 {
+		return
+	}
+	if err := store.SaveIdempotentResponse(ctx, caller, key, status, buf.String()); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Failed to store idempotent response")
+		return
+	}
+	saved = true
+}
+
+// requestHash identifies a request by its method, URL and body.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
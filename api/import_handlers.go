@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/service"
+	"durable-links-generator/config"
+
+	"github.com/rs/zerolog/log"
+)
+
+type ImportHandler interface {
+	ImportFirebase(w http.ResponseWriter, r *http.Request)
+}
+
+type importHandler struct {
+	importService service.FirebaseImportService
+	cfg           *config.Config
+}
+
+func NewImportHandler(importService service.FirebaseImportService, cfg *config.Config) ImportHandler {
+	return &importHandler{
+		importService: importService,
+		cfg:           cfg,
+	}
+}
+
+// ImportFirebase serves "POST /import/firebase", importing the Firebase Dynamic Links CSV export
+// sent as the request body. The host param creates the links on another domain than the one of
+// their Firebase short link.
+func (h *importHandler) ImportFirebase(w http.ResponseWriter, r *http.Request) {
+	results, err := h.importService.ImportFirebase(r.Context(), r.Body, r.URL.Query().Get("host"), h.cfg.App.ImportMaxRows)
+	switch {
+	case errors.Is(err, apperrors.ErrInvalidImportFile),
+		errors.Is(err, apperrors.ErrImportTooLarge):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+		return
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to import Firebase export")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to import links", models.StatusInternal)
+		return
+	}
+
+	resp := models.FirebaseImportResponse{Results: make([]models.FirebaseImportRow, len(results))}
+	for i, result := range results {
+		row := models.FirebaseImportRow{Row: result.Row, SourceLink: result.SourceLink}
+		switch {
+		case result.Err == nil:
+			resp.Imported++
+			row.ShortLinkResponse = result.Link
+		case errors.Is(result.Err, apperrors.ErrPathTaken):
+			resp.Skipped++
+			details := errorDetails(result.Err, http.StatusConflict, "Path is already in use on this host", models.StatusAlreadyExists)
+			row.Error = &details
+		default:
+			resp.Failed++
+			details := importErrorDetails(r.Context(), result.Err)
+			row.Error = &details
+		}
+		resp.Results[i] = row
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func importErrorDetails(ctx context.Context, err error) models.ErrorDetails {
+	switch {
+	case errors.Is(err, apperrors.ErrInvalidImportRow):
+		return errorDetails(err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrProjectNotAllowed):
+		return errorDetails(err, http.StatusForbidden, "Token is not allowed to access this project", models.StatusPermissionDenied)
+	case errors.Is(err, apperrors.ErrInvalidURLFormat),
+		errors.Is(err, apperrors.ErrHostInvalid),
+		errors.Is(err, apperrors.ErrInvalidFormat),
+		errors.Is(err, apperrors.ErrMissingHost),
+		errors.Is(err, apperrors.ErrMissingLink):
+		return prepareErrorDetails(err)
+	default:
+		return createErrorDetails(ctx, err)
+	}
+}
@@ -0,0 +1,68 @@
+package limiter
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenBucket allows rate events per second on average with bursts of up to burst events.
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	b := &TokenBucket{
+		rate:  rate,
+		burst: float64(max(burst, 1)),
+		now:   time.Now,
+	}
+	b.tokens = b.burst
+	b.last = b.now()
+	return b
+}
+
+// Allow takes a token if one is available.
+func (b *TokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Take takes a token if one is available, or returns how long until one is.
+func (b *TokenBucket) Take() (ok bool, wait time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// Full reports whether the bucket has refilled completely, i.e. it hasn't been used lately.
+func (b *TokenBucket) Full() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	return b.tokens >= b.burst
+}
+
+func (b *TokenBucket) refill() {
+	now := b.now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
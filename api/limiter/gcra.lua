@@ -0,0 +1,23 @@
+-- KEYS[1]: the quota's key
+-- ARGV[1]: microseconds between events at the quota's rate
+-- ARGV[2]: burst
+-- Returns {1, 0} when the event is allowed, {0, microseconds to wait} when it isn't.
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+  tat = now
+end
+
+local next_tat = tat + interval
+local wait = next_tat - now - burst * interval
+if wait > 0 then
+  return {0, wait}
+end
+
+redis.call('SET', KEYS[1], string.format('%.0f', next_tat), 'PX', math.ceil((next_tat - now) / 1000))
+return {1, 0}
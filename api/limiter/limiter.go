@@ -0,0 +1,107 @@
+package limiter
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter is an adaptive concurrency limit using AIMD: every request finishing within the
+// latency target grows the limit by 1/limit, a slower one shrinks it by the backoff factor. Under
+// overload the limit falls towards what the database can serve at the target latency and excess
+// requests are rejected instead of queueing.
+type Limiter struct {
+	mu       sync.Mutex
+	limit    float64
+	inflight int
+
+	minLimit      float64
+	maxLimit      float64
+	latencyTarget time.Duration
+	backoff       float64
+	// lastDecrease throttles decreases to one per latency target, so a burst of slow responses
+	// started under the same conditions only counts once.
+	lastDecrease time.Time
+	now          func() time.Time
+}
+
+type Options struct {
+	InitialLimit  int
+	MinLimit      int
+	MaxLimit      int
+	LatencyTarget time.Duration
+	// Backoff is the factor the limit is multiplied with after a slow request, e.g. 0.9.
+	Backoff float64
+}
+
+func New(opts Options) *Limiter {
+	if opts.MinLimit < 1 {
+		opts.MinLimit = 1
+	}
+	if opts.MaxLimit < opts.MinLimit {
+		opts.MaxLimit = opts.MinLimit
+	}
+	if opts.Backoff <= 0 || opts.Backoff >= 1 {
+		opts.Backoff = 0.9
+	}
+
+	l := &Limiter{
+		minLimit:      float64(opts.MinLimit),
+		maxLimit:      float64(opts.MaxLimit),
+		latencyTarget: opts.LatencyTarget,
+		backoff:       opts.Backoff,
+		now:           time.Now,
+	}
+	l.limit = l.clamp(float64(opts.InitialLimit))
+	return l
+}
+
+// Acquire reserves a slot. It returns false when the limit is reached; otherwise the caller must
+// call the returned function once the request finished.
+func (l *Limiter) Acquire() (release func(), ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if float64(l.inflight) >= l.limit {
+		return nil, false
+	}
+	l.inflight++
+
+	start := l.now()
+	var once sync.Once
+	return func() {
+		once.Do(func() { l.release(l.now().Sub(start)) })
+	}, true
+}
+
+func (l *Limiter) release(latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inflight--
+	if latency > l.latencyTarget {
+		if now := l.now(); now.Sub(l.lastDecrease) >= l.latencyTarget {
+			l.limit = l.clamp(l.limit * l.backoff)
+			l.lastDecrease = now
+		}
+		return
+	}
+	l.limit = l.clamp(l.limit + 1/l.limit)
+}
+
+// Limit returns the current concurrency limit.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// Inflight returns the number of requests holding a slot.
+func (l *Limiter) Inflight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inflight
+}
+
+func (l *Limiter) clamp(limit float64) float64 {
+	return max(l.minLimit, min(l.maxLimit, limit))
+}
@@ -0,0 +1,221 @@
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock advances by step on every read, so each request takes exactly step.
+type fakeClock struct {
+	t    time.Time
+	step time.Duration
+}
+
+func (c *fakeClock) now() time.Time {
+	c.t = c.t.Add(c.step)
+	return c.t
+}
+
+func newTestLimiter(step time.Duration) *Limiter {
+	l := New(Options{InitialLimit: 10, MinLimit: 2, MaxLimit: 20, LatencyTarget: 100 * time.Millisecond, Backoff: 0.5})
+	l.now = (&fakeClock{t: time.Unix(0, 0), step: step}).now
+	return l
+}
+
+func TestAcquireRejectsAtLimit(t *testing.T) {
+	l := newTestLimiter(time.Millisecond)
+
+	var releases []func()
+	for range 10 {
+		release, ok := l.Acquire()
+		assert.True(t, ok)
+		releases = append(releases, release)
+	}
+
+	_, ok := l.Acquire()
+	assert.False(t, ok)
+	assert.Equal(t, 10, l.Inflight())
+
+	releases[0]()
+	releases[0]()
+	assert.Equal(t, 9, l.Inflight(), "releasing twice must not free two slots")
+
+	_, ok = l.Acquire()
+	assert.True(t, ok)
+}
+
+func TestLimitAdapts(t *testing.T) {
+	tests := []struct {
+		name     string
+		latency  time.Duration
+		requests int
+		want     int
+	}{
+		{name: "Fast requests grow the limit", latency: 10 * time.Millisecond, requests: 50, want: 14},
+		{name: "Slow requests shrink the limit", latency: 200 * time.Millisecond, requests: 1, want: 5},
+		{name: "Limit never drops below the minimum", latency: 200 * time.Millisecond, requests: 50, want: 2},
+		{name: "Limit never grows above the maximum", latency: time.Millisecond, requests: 10000, want: 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLimiter(tt.latency)
+			for range tt.requests {
+				release, ok := l.Acquire()
+				assert.True(t, ok)
+				release()
+			}
+			assert.Equal(t, tt.want, l.Limit())
+		})
+	}
+}
+
+func TestQueue(t *testing.T) {
+	q := NewQueue(1, 1, 20*time.Millisecond)
+
+	release, ok := q.Acquire(context.Background())
+	assert.True(t, ok)
+
+	// The single wait slot is taken by a request that gets the slot once it's released.
+	acquired := make(chan bool)
+	go func() {
+		release, ok := q.Acquire(context.Background())
+		if ok {
+			defer release()
+		}
+		acquired <- ok
+	}()
+	assert.Eventually(t, func() bool { return q.waiting.Load() == 1 }, time.Second, time.Millisecond)
+
+	_, ok = q.Acquire(context.Background())
+	assert.False(t, ok, "wait list is full")
+
+	release()
+	assert.True(t, <-acquired)
+
+	release, ok = q.Acquire(context.Background())
+	assert.True(t, ok)
+	defer release()
+
+	_, ok = q.Acquire(context.Background())
+	assert.False(t, ok, "timed out waiting for a slot")
+}
+
+func TestTokenBucket(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	b := NewTokenBucket(2, 2)
+	b.now = clock.now
+	b.last = clock.t
+
+	assert.True(t, b.Allow())
+	assert.True(t, b.Allow())
+	assert.False(t, b.Allow(), "burst used up")
+
+	clock.step = 500 * time.Millisecond
+	assert.True(t, b.Allow(), "one token refilled after half a second")
+	clock.step = 0
+	assert.False(t, b.Allow())
+}
+
+func TestTenants(t *testing.T) {
+	tenants := NewTenants(
+		TenantLimits{Concurrency: 1},
+		map[string]TenantLimits{"big.page.link": {Concurrency: 2}},
+	)
+
+	release, ok := tenants.Acquire("a.page.link")
+	assert.True(t, ok)
+	_, ok = tenants.Acquire("a.page.link")
+	assert.False(t, ok, "default cap reached")
+
+	_, ok = tenants.Acquire("b.page.link")
+	assert.True(t, ok, "other tenants are not affected")
+
+	_, ok = tenants.Acquire("big.page.link")
+	assert.True(t, ok)
+	_, ok = tenants.Acquire("big.page.link")
+	assert.True(t, ok, "override allows two")
+
+	release()
+	_, ok = tenants.Acquire("a.page.link")
+	assert.True(t, ok)
+}
+
+func TestTenantsPruneIdle(t *testing.T) {
+	tenants := NewTenants(TenantLimits{Concurrency: 1}, nil)
+	release, _ := tenants.Acquire("busy")
+	for i := range maxTenants {
+		r, ok := tenants.Acquire(fmt.Sprintf("idle-%d", i))
+		assert.True(t, ok)
+		r()
+	}
+
+	assert.LessOrEqual(t, len(tenants.tenants), maxTenants)
+	_, ok := tenants.Acquire("busy")
+	assert.False(t, ok, "busy tenants keep their state")
+	release()
+}
+
+func TestTenantsFull(t *testing.T) {
+	tenants := NewTenants(TenantLimits{Concurrency: 1}, nil)
+	for i := range maxTenants {
+		_, ok := tenants.Acquire(fmt.Sprintf("busy-%d", i))
+		assert.True(t, ok)
+	}
+
+	_, ok := tenants.Acquire("new")
+	assert.False(t, ok, "new tenants are turned away while all are busy")
+	assert.Len(t, tenants.tenants, maxTenants)
+}
+
+func TestMemoryRateLimiter(t *testing.T) {
+	m := NewMemory()
+	ctx := context.Background()
+	quota := Quota{PerMinute: 60, Burst: 2}
+
+	for range 2 {
+		ok, _, err := m.Allow(ctx, "a", quota)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+	ok, wait, _ := m.Allow(ctx, "a", quota)
+	assert.False(t, ok)
+	assert.InDelta(t, time.Second, wait, float64(50*time.Millisecond))
+
+	ok, _, _ = m.Allow(ctx, "b", quota)
+	assert.True(t, ok, "keys have their own buckets")
+	ok, _, _ = m.Allow(ctx, "a", Quota{})
+	assert.True(t, ok, "zero quota is unlimited")
+}
+
+func TestRedisRateLimiter(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	r := NewRedis(client)
+	ctx := context.Background()
+	quota := Quota{PerMinute: 60, Burst: 2}
+
+	for range 2 {
+		ok, _, err := r.Allow(ctx, "a", quota)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+	ok, wait, err := r.Allow(ctx, "a", quota)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.InDelta(t, time.Second, wait, float64(50*time.Millisecond))
+
+	ok, _, _ = r.Allow(ctx, "b", quota)
+	assert.True(t, ok)
+
+	server.Close()
+	_, _, err = r.Allow(ctx, "a", quota)
+	assert.Error(t, err)
+}
@@ -0,0 +1,56 @@
+package limiter
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Queue caps concurrency at a fixed number of slots with a bounded wait list in front of them.
+// Requests beyond the wait list, or waiting longer than the timeout, are rejected.
+type Queue struct {
+	slots    chan struct{}
+	waiting  atomic.Int64
+	maxQueue int64
+	timeout  time.Duration
+}
+
+func NewQueue(concurrency, queueLength int, timeout time.Duration) *Queue {
+	return &Queue{
+		slots:    make(chan struct{}, max(concurrency, 1)),
+		maxQueue: int64(max(queueLength, 0)),
+		timeout:  timeout,
+	}
+}
+
+// Acquire waits for a free slot. It returns false when the wait list is full, the timeout passed
+// or ctx was cancelled; otherwise the caller must call the returned function when done.
+func (q *Queue) Acquire(ctx context.Context) (release func(), ok bool) {
+	select {
+	case q.slots <- struct{}{}:
+		return q.release, true
+	default:
+	}
+
+	if q.waiting.Add(1) > q.maxQueue {
+		q.waiting.Add(-1)
+		return nil, false
+	}
+	defer q.waiting.Add(-1)
+
+	timer := time.NewTimer(q.timeout)
+	defer timer.Stop()
+
+	select {
+	case q.slots <- struct{}{}:
+		return q.release, true
+	case <-timer.C:
+		return nil, false
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+func (q *Queue) release() {
+	<-q.slots
+}
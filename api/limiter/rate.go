@@ -0,0 +1,98 @@
+package limiter
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Quota is a rate of events per minute with bursts of up to Burst events. A zero PerMinute is
+// unlimited.
+type Quota struct {
+	PerMinute int
+	Burst     int
+}
+
+func (q Quota) burst() int {
+	if q.Burst > 0 {
+		return q.Burst
+	}
+	return max(q.PerMinute/60, 1)
+}
+
+// RateLimiter admits events per key within a quota.
+type RateLimiter interface {
+	// Allow takes one event of key's quota, or returns how long until the next one is allowed.
+	Allow(ctx context.Context, key string, quota Quota) (ok bool, retryAfter time.Duration, err error)
+}
+
+// Memory keeps a token bucket per key in this instance, so every instance allows the full quota.
+type Memory struct {
+	mu      sync.Mutex
+	buckets map[string]*TokenBucket
+}
+
+func NewMemory() *Memory {
+	return &Memory{buckets: map[string]*TokenBucket{}}
+}
+
+func (m *Memory) Allow(_ context.Context, key string, quota Quota) (bool, time.Duration, error) {
+	if quota.PerMinute <= 0 {
+		return true, 0, nil
+	}
+	ok, wait := m.bucket(key, quota).Take()
+	return ok, wait, nil
+}
+
+func (m *Memory) bucket(key string, quota Quota) *TokenBucket {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if bucket, ok := m.buckets[key]; ok {
+		return bucket
+	}
+	// Keys can come from clients, so idle buckets are dropped like idle tenants.
+	if len(m.buckets) >= maxTenants {
+		for key, bucket := range m.buckets {
+			if bucket.Full() {
+				delete(m.buckets, key)
+			}
+		}
+	}
+	bucket := NewTokenBucket(float64(quota.PerMinute)/60, quota.burst())
+	m.buckets[key] = bucket
+	return bucket
+}
+
+// gcraScript is the generic cell rate algorithm, the token bucket keeping a single timestamp per
+// key: the theoretical arrival time of the next event.
+//
+//go:embed gcra.lua
+var gcraScript string
+
+var gcra = redis.NewScript(gcraScript)
+
+// Redis keeps the quotas in Redis, shared by all instances.
+type Redis struct {
+	client *redis.Client
+}
+
+func NewRedis(client *redis.Client) *Redis {
+	return &Redis{client: client}
+}
+
+func (r *Redis) Allow(ctx context.Context, key string, quota Quota) (bool, time.Duration, error) {
+	if quota.PerMinute <= 0 {
+		return true, 0, nil
+	}
+	interval := time.Minute / time.Duration(quota.PerMinute)
+	result, err := gcra.Run(ctx, r.client, []string{"durablelinks:rate:" + key}, interval.Microseconds(), quota.burst()).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("rate limit: %w", err)
+	}
+	return result[0] == 1, time.Duration(result[1]) * time.Microsecond, nil
+}
@@ -0,0 +1,107 @@
+package limiter
+
+import (
+	"sync"
+)
+
+// TenantLimits caps one tenant. Zero values mean unlimited.
+type TenantLimits struct {
+	QPS         float64
+	Burst       int
+	Concurrency int
+}
+
+// Tenants tracked before idle ones are dropped. Keys can come from clients, e.g. IP addresses, so
+// the map must not grow without bound: new tenants are turned away while it is full of busy ones.
+const maxTenants = 10000
+
+// Tenants applies TenantLimits separately to every tenant, so a single tenant running into its
+// caps doesn't affect the others.
+type Tenants struct {
+	mu        sync.Mutex
+	defaults  TenantLimits
+	overrides map[string]TenantLimits
+	tenants   map[string]*tenantState
+}
+
+type tenantState struct {
+	limits   TenantLimits
+	bucket   *TokenBucket
+	inflight int
+}
+
+func NewTenants(defaults TenantLimits, overrides map[string]TenantLimits) *Tenants {
+	return &Tenants{
+		defaults:  defaults,
+		overrides: overrides,
+		tenants:   map[string]*tenantState{},
+	}
+}
+
+// Acquire admits one operation for tenant. It returns false when the tenant is over its rate or
+// concurrency cap, or is new while maxTenants busy tenants are tracked; otherwise the caller must
+// call the returned function when done.
+func (t *Tenants) Acquire(tenant string) (release func(), ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := t.state(tenant)
+	if state == nil {
+		return nil, false
+	}
+	if state.limits.Concurrency > 0 && state.inflight >= state.limits.Concurrency {
+		return nil, false
+	}
+	if state.bucket != nil && !state.bucket.Allow() {
+		return nil, false
+	}
+	state.inflight++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			state.inflight--
+		})
+	}, true
+}
+
+// state returns the state of tenant, tracking it if it is new, or nil when there is no room for it.
+func (t *Tenants) state(tenant string) *tenantState {
+	if state, ok := t.tenants[tenant]; ok {
+		return state
+	}
+
+	if len(t.tenants) >= maxTenants {
+		t.pruneIdle()
+		if len(t.tenants) >= maxTenants {
+			return nil
+		}
+	}
+
+	limits, ok := t.overrides[tenant]
+	if !ok {
+		limits = t.defaults
+	}
+	state := &tenantState{limits: limits}
+	if limits.QPS > 0 {
+		burst := limits.Burst
+		if burst <= 0 {
+			burst = int(limits.QPS)
+		}
+		state.bucket = NewTokenBucket(limits.QPS, burst)
+	}
+	t.tenants[tenant] = state
+	return state
+}
+
+// pruneIdle forgets tenants with nothing in flight and a full bucket. Their next request starts
+// from fresh state, which is exactly what they would have had anyway.
+func (t *Tenants) pruneIdle() {
+	for tenant, state := range t.tenants {
+		if state.inflight == 0 && (state.bucket == nil || state.bucket.Full()) {
+			delete(t.tenants, tenant)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DeleteLink serves "DELETE /shortLinks/{path}". The link is soft deleted unless hard=true asks
+// for it to be purged.
+func (h *handler) DeleteLink(w http.ResponseWriter, r *http.Request) {
+	hard := false
+	if raw := r.URL.Query().Get("hard"); raw != "" {
+		var err error
+		if hard, err = strconv.ParseBool(raw); err != nil {
+			WriteErrorResponse(w, http.StatusBadRequest, "hard must be true or false", models.StatusInvalidArgument)
+			return
+		}
+	}
+
+	err := h.linkService.DeleteLink(r.Context(), r.URL.Query().Get("host"), chi.URLParam(r, "path"), hard)
+	if err != nil {
+		writeDeleteError(w, err, "Failed to delete link")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreLink serves "POST /shortLinks/{path}:restore" for soft deleted links.
+func (h *handler) RestoreLink(w http.ResponseWriter, r *http.Request) {
+	err := h.linkService.RestoreLink(r.Context(), r.URL.Query().Get("host"), chi.URLParam(r, "path"))
+	if err != nil {
+		writeDeleteError(w, err, "Failed to restore link")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeDeleteError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrLinkNotFound):
+		WriteError(w, err, http.StatusNotFound, "Link not found", models.StatusNotFound)
+	default:
+		requestLog(w).Error().Err(err).Msg(message)
+		WriteError(w, err, http.StatusInternalServerError, message, models.StatusInternal)
+	}
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// What RESERVED_LINK_BEHAVIOR, PRELAUNCH_LINK_BEHAVIOR and EXPIRED_LINK_BEHAVIOR can do with
+// visitors of a link that doesn't redirect: show a page, answer 404, or, given an absolute URL,
+// redirect there.
+const (
+	linkBehaviorPage     = "page"
+	linkBehaviorNotFound = "notfound"
+)
+
+// validateLinkBehavior checks a *_LINK_BEHAVIOR config.
+func validateLinkBehavior(behavior string) error {
+	if behavior == linkBehaviorPage || behavior == linkBehaviorNotFound {
+		return nil
+	}
+	u, err := url.Parse(behavior)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not %s, %s or an absolute http(s) URL", behavior, linkBehaviorPage, linkBehaviorNotFound)
+	}
+	return nil
+}
+
+// ReserveLink serves "POST /shortLinks/{path}:reserve", taking the path for a link whose
+// destination is set later with PATCH /shortLinks/{path}.
+func (h *handler) ReserveLink(w http.ResponseWriter, r *http.Request) {
+	var req models.ReserveLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+
+	resp, err := h.linkService.ReserveLink(r.Context(), req.Host, chi.URLParam(r, "path"))
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost),
+		errors.Is(err, apperrors.ErrHostInvalid),
+		errors.Is(err, apperrors.ErrInvalidPath),
+		errors.Is(err, apperrors.ErrReservedPath):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrPathTaken):
+		WriteError(w, err, http.StatusConflict, "Path is already in use", models.StatusAlreadyExists)
+	case errors.Is(err, apperrors.ErrTenantLimited):
+		writeTenantLimited(w)
+	case err != nil:
+		requestLog(w).Error().Err(err).Msg("Failed to reserve link")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to reserve link", models.StatusInternal)
+	default:
+		w.Header().Set("ETag", resp.ETag)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// serveReservedLink answers a visit to a reserved link as RESERVED_LINK_BEHAVIOR says.
+func (h *handler) serveReservedLink(w http.ResponseWriter, r *http.Request) {
+	serveLinkBehavior(w, r, h.cfg.App.ReservedLinkBehavior, "coming_soon.html", http.StatusOK)
+}
+
+// serveLinkBehavior answers a visit to a link that doesn't redirect as behavior says, with the
+// page template and status for "page". The response must not be cached: the link may redirect
+// later.
+func serveLinkBehavior(w http.ResponseWriter, r *http.Request, behavior, page string, status int) {
+	w.Header().Set("Cache-Control", "no-store")
+	switch behavior {
+	case linkBehaviorNotFound:
+		http.NotFound(w, r)
+	case linkBehaviorPage, "":
+		renderPage(w, status, page, nil)
+	default:
+		http.Redirect(w, r, behavior, http.StatusFound)
+	}
+}
+
+// serveTextLinkBehavior is serveLinkBehavior for GET /v1/resolve.txt: the URL of a redirecting
+// behavior is answered as the destination, otherwise msg is answered with status.
+func serveTextLinkBehavior(w http.ResponseWriter, behavior, msg string, status int) {
+	w.Header().Set("Cache-Control", "no-store")
+	switch behavior {
+	case linkBehaviorNotFound:
+		http.Error(w, "Link not found", http.StatusNotFound)
+	case linkBehaviorPage, "":
+		http.Error(w, msg, status)
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, behavior)
+	}
+}
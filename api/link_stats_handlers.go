@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+func (h *handler) LinkStats(w http.ResponseWriter, r *http.Request) {
+	req, ok := statsRequest(w, r)
+	if !ok {
+		return
+	}
+	req.Host = r.URL.Query().Get("host")
+	req.Path = chi.URLParam(r, "path")
+
+	resp, err := h.linkService.LinkStats(r.Context(), req)
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost),
+		errors.Is(err, apperrors.ErrInvalidGranularity),
+		errors.Is(err, apperrors.ErrInvalidStatsRange):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrLinkNotFound):
+		WriteError(w, err, http.StatusNotFound, "Link not found", models.StatusNotFound)
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to get link stats")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to get link stats", models.StatusInternal)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// statsRequest reads the time range and granularity of a stats request from its query, answering
+// 400 when they can't be parsed.
+func statsRequest(w http.ResponseWriter, r *http.Request) (models.LinkStatsRequest, bool) {
+	query := r.URL.Query()
+	req := models.LinkStatsRequest{
+		Granularity: query.Get("granularity"),
+	}
+	days, ok := positiveIntParam(w, query.Get("durationDays"), "durationDays")
+	if !ok {
+		return req, false
+	}
+	req.DurationDays = days
+	bounds := []struct {
+		param string
+		dst   *time.Time
+	}{
+		{"from", &req.From},
+		{"to", &req.To},
+	}
+	for _, bound := range bounds {
+		if raw := query.Get(bound.param); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				WriteErrorResponse(w, http.StatusBadRequest, bound.param+" must be an RFC 3339 timestamp", models.StatusInvalidArgument)
+				return req, false
+			}
+			*bound.dst = t
+		}
+	}
+	return req, true
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+type LinkTransferHandler interface {
+	ImportLinks(w http.ResponseWriter, r *http.Request)
+	ExportLinks(w http.ResponseWriter, r *http.Request)
+}
+
+type linkTransferHandler struct {
+	transferService service.LinkTransferService
+}
+
+func NewLinkTransferHandler(transferService service.LinkTransferService) LinkTransferHandler {
+	return &linkTransferHandler{transferService: transferService}
+}
+
+// importFormats maps the content types of an import to its format.
+var importFormats = map[string]string{
+	"text/csv":             service.ImportCSV,
+	"application/x-ndjson": service.ImportJSONL,
+	"application/jsonl":    service.ImportJSONL,
+	"application/x-jsonl":  service.ImportJSONL,
+}
+
+// ImportLinks serves "POST /shortLinks/import", creating the links of the CSV or JSONL body as it
+// is read. The format param, or else the Content-Type, tells the format, CSV by default.
+func (h *linkTransferHandler) ImportLinks(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if format = importFormats[mediaType]; format == "" {
+			format = service.ImportCSV
+		}
+	}
+
+	resp := models.LinkImportResponse{Errors: []models.LinkImportError{}}
+	err := h.transferService.ImportLinks(r.Context(), r.Body, format, func(result service.ImportResult) {
+		if result.Err == nil {
+			resp.Imported++
+			return
+		}
+		resp.Failed++
+		resp.Errors = append(resp.Errors, models.LinkImportError{Row: result.Row, Error: importErrorDetails(r.Context(), result.Err)})
+	})
+	switch {
+	case errors.Is(err, apperrors.ErrInvalidImportFile):
+		// The rows before the unreadable one are imported, tell how many.
+		msg := fmt.Sprintf("%s (%d links imported before)", err.Error(), resp.Imported)
+		WriteError(w, err, http.StatusBadRequest, msg, models.StatusInvalidArgument)
+		return
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Int("imported", resp.Imported).Msg("Failed to import links")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to import links", models.StatusInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// ExportLinks serves "GET /shortLinks/export", streaming the host's links as a CSV download that
+// POST /shortLinks/import reads back.
+func (h *linkTransferHandler) ExportLinks(w http.ResponseWriter, r *http.Request) {
+	host := r.URL.Query().Get("host")
+	out := &exportWriter{w: w, filename: host + "-links.csv"}
+	err := h.transferService.ExportLinks(r.Context(), host, out)
+	switch {
+	case err == nil:
+		out.start()
+	case out.started:
+		// The status is already sent, the client sees a truncated file.
+		log.Ctx(r.Context()).Error().Err(err).Str("host", host).Msg("Failed to finish links export")
+	case errors.Is(err, apperrors.ErrMissingHost):
+		WriteError(w, err, http.StatusBadRequest, "Missing or invalid host", models.StatusInvalidArgument)
+	default:
+		log.Ctx(r.Context()).Error().Err(err).Str("host", host).Msg("Failed to export links")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to export links", models.StatusInternal)
+	}
+}
+
+// exportWriter sends the headers of the CSV download on its first write, so that an export failing
+// before leaves the response free for an error.
+type exportWriter struct {
+	w        http.ResponseWriter
+	filename string
+	started  bool
+}
+
+func (e *exportWriter) start() {
+	if e.started {
+		return
+	}
+	e.started = true
+	e.w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	e.w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": e.filename}))
+}
+
+func (e *exportWriter) Write(p []byte) (int, error) {
+	e.start()
+	return e.w.Write(p)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetLink serves "GET /shortLinks/{path}", returning the link along with the ETag to update it
+// with.
+func (h *handler) GetLink(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.linkService.GetLink(r.Context(), r.URL.Query().Get("host"), chi.URLParam(r, "path"))
+	if err != nil {
+		writeUpdateError(w, err)
+		return
+	}
+	writeStoredLink(w, resp)
+}
+
+// UpdateLink serves "PATCH /shortLinks/{path}". An If-Match header makes the update conditional
+// on the link's ETag.
+func (h *handler) UpdateLink(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+
+	resp, err := h.linkService.UpdateLink(r.Context(), r.URL.Query().Get("host"), chi.URLParam(r, "path"), r.Header.Get("If-Match"), req)
+	if err != nil {
+		writeUpdateError(w, err)
+		return
+	}
+	writeStoredLink(w, resp)
+}
+
+func writeStoredLink(w http.ResponseWriter, resp *models.StoredLinkResponse) {
+	w.Header().Set("ETag", resp.ETag)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func writeUpdateError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost),
+		errors.Is(err, apperrors.ErrHostInvalid),
+		errors.Is(err, apperrors.ErrMissingLink),
+		errors.Is(err, apperrors.ErrInvalidLinkParam),
+		errors.Is(err, apperrors.ErrInvalidMetadata),
+		errors.Is(err, apperrors.ErrPIIDetected),
+		errors.Is(err, apperrors.ErrUnsafeURL):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrReputationUnavailable):
+		requestLog(w).Error().Err(err).Msg("URL reputation check failed")
+		WriteError(w, err, http.StatusServiceUnavailable, "Destination URLs couldn't be checked, retry later", models.StatusUnavailable)
+	case errors.Is(err, apperrors.ErrLinkNotFound):
+		WriteError(w, err, http.StatusNotFound, "Link not found", models.StatusNotFound)
+	case errors.Is(err, apperrors.ErrLinkChanged):
+		WriteError(w, err, http.StatusPreconditionFailed, "Link was changed since it was read, fetch it again for the current ETag", models.StatusFailedPrecondition)
+	case errors.Is(err, apperrors.ErrTenantLimited):
+		writeTenantLimited(w)
+	default:
+		requestLog(w).Error().Err(err).Msg("Failed to update link")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to update link", models.StatusInternal)
+	}
+}
@@ -0,0 +1,4 @@
+// Package linkspb holds the gRPC API's messages and service stubs, generated from links.proto.
+package linkspb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative links.proto
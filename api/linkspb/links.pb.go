@@ -0,0 +1,1654 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.4
+// 	protoc        (unknown)
+// source: links.proto
+
+package linkspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// CreateDurableLinkRequest takes either a long link to parse or the link's parameters. Field
+// names and values are those of the REST API's JSON body.
+type CreateDurableLinkRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	LongDurableLink string                 `protobuf:"bytes,1,opt,name=long_durable_link,json=longDurableLink,proto3" json:"long_durable_link,omitempty"`
+	DurableLinkInfo *DurableLinkInfo       `protobuf:"bytes,2,opt,name=durable_link_info,json=durableLinkInfo,proto3" json:"durable_link_info,omitempty"`
+	Suffix          *Suffix                `protobuf:"bytes,3,opt,name=suffix,proto3" json:"suffix,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CreateDurableLinkRequest) Reset() {
+	*x = CreateDurableLinkRequest{}
+	mi := &file_links_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDurableLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDurableLinkRequest) ProtoMessage() {}
+
+func (x *CreateDurableLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDurableLinkRequest.ProtoReflect.Descriptor instead.
+func (*CreateDurableLinkRequest) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateDurableLinkRequest) GetLongDurableLink() string {
+	if x != nil {
+		return x.LongDurableLink
+	}
+	return ""
+}
+
+func (x *CreateDurableLinkRequest) GetDurableLinkInfo() *DurableLinkInfo {
+	if x != nil {
+		return x.DurableLinkInfo
+	}
+	return nil
+}
+
+func (x *CreateDurableLinkRequest) GetSuffix() *Suffix {
+	if x != nil {
+		return x.Suffix
+	}
+	return nil
+}
+
+type DurableLinkInfo struct {
+	state                   protoimpl.MessageState   `protogen:"open.v1"`
+	Host                    string                   `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	Link                    string                   `protobuf:"bytes,2,opt,name=link,proto3" json:"link,omitempty"`
+	AndroidParameters       *AndroidParameters       `protobuf:"bytes,3,opt,name=android_parameters,json=androidParameters,proto3" json:"android_parameters,omitempty"`
+	IosParameters           *IosParameters           `protobuf:"bytes,4,opt,name=ios_parameters,json=iosParameters,proto3" json:"ios_parameters,omitempty"`
+	OtherPlatformParameters *OtherPlatformParameters `protobuf:"bytes,5,opt,name=other_platform_parameters,json=otherPlatformParameters,proto3" json:"other_platform_parameters,omitempty"`
+	DesktopParameters       *DesktopParameters       `protobuf:"bytes,6,opt,name=desktop_parameters,json=desktopParameters,proto3" json:"desktop_parameters,omitempty"`
+	AnalyticsInfo           *AnalyticsInfo           `protobuf:"bytes,7,opt,name=analytics_info,json=analyticsInfo,proto3" json:"analytics_info,omitempty"`
+	SocialMetaTagInfo       *SocialMetaTagInfo       `protobuf:"bytes,8,opt,name=social_meta_tag_info,json=socialMetaTagInfo,proto3" json:"social_meta_tag_info,omitempty"`
+	// gate is "age" or "terms".
+	Gate          string         `protobuf:"bytes,9,opt,name=gate,proto3" json:"gate,omitempty"`
+	DeepLink      *DeepLink      `protobuf:"bytes,10,opt,name=deep_link,json=deepLink,proto3" json:"deep_link,omitempty"`
+	PlatformLinks *PlatformLinks `protobuf:"bytes,11,opt,name=platform_links,json=platformLinks,proto3" json:"platform_links,omitempty"`
+	QrFirst       bool           `protobuf:"varint,12,opt,name=qr_first,json=qrFirst,proto3" json:"qr_first,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DurableLinkInfo) Reset() {
+	*x = DurableLinkInfo{}
+	mi := &file_links_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DurableLinkInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DurableLinkInfo) ProtoMessage() {}
+
+func (x *DurableLinkInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DurableLinkInfo.ProtoReflect.Descriptor instead.
+func (*DurableLinkInfo) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DurableLinkInfo) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *DurableLinkInfo) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+func (x *DurableLinkInfo) GetAndroidParameters() *AndroidParameters {
+	if x != nil {
+		return x.AndroidParameters
+	}
+	return nil
+}
+
+func (x *DurableLinkInfo) GetIosParameters() *IosParameters {
+	if x != nil {
+		return x.IosParameters
+	}
+	return nil
+}
+
+func (x *DurableLinkInfo) GetOtherPlatformParameters() *OtherPlatformParameters {
+	if x != nil {
+		return x.OtherPlatformParameters
+	}
+	return nil
+}
+
+func (x *DurableLinkInfo) GetDesktopParameters() *DesktopParameters {
+	if x != nil {
+		return x.DesktopParameters
+	}
+	return nil
+}
+
+func (x *DurableLinkInfo) GetAnalyticsInfo() *AnalyticsInfo {
+	if x != nil {
+		return x.AnalyticsInfo
+	}
+	return nil
+}
+
+func (x *DurableLinkInfo) GetSocialMetaTagInfo() *SocialMetaTagInfo {
+	if x != nil {
+		return x.SocialMetaTagInfo
+	}
+	return nil
+}
+
+func (x *DurableLinkInfo) GetGate() string {
+	if x != nil {
+		return x.Gate
+	}
+	return ""
+}
+
+func (x *DurableLinkInfo) GetDeepLink() *DeepLink {
+	if x != nil {
+		return x.DeepLink
+	}
+	return nil
+}
+
+func (x *DurableLinkInfo) GetPlatformLinks() *PlatformLinks {
+	if x != nil {
+		return x.PlatformLinks
+	}
+	return nil
+}
+
+func (x *DurableLinkInfo) GetQrFirst() bool {
+	if x != nil {
+		return x.QrFirst
+	}
+	return false
+}
+
+type AndroidParameters struct {
+	state                        protoimpl.MessageState `protogen:"open.v1"`
+	AndroidPackageName           string                 `protobuf:"bytes,1,opt,name=android_package_name,json=androidPackageName,proto3" json:"android_package_name,omitempty"`
+	AndroidFallbackLink          string                 `protobuf:"bytes,2,opt,name=android_fallback_link,json=androidFallbackLink,proto3" json:"android_fallback_link,omitempty"`
+	AndroidMinPackageVersionCode string                 `protobuf:"bytes,3,opt,name=android_min_package_version_code,json=androidMinPackageVersionCode,proto3" json:"android_min_package_version_code,omitempty"`
+	AndroidAppGalleryLink        string                 `protobuf:"bytes,4,opt,name=android_app_gallery_link,json=androidAppGalleryLink,proto3" json:"android_app_gallery_link,omitempty"`
+	unknownFields                protoimpl.UnknownFields
+	sizeCache                    protoimpl.SizeCache
+}
+
+func (x *AndroidParameters) Reset() {
+	*x = AndroidParameters{}
+	mi := &file_links_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AndroidParameters) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AndroidParameters) ProtoMessage() {}
+
+func (x *AndroidParameters) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AndroidParameters.ProtoReflect.Descriptor instead.
+func (*AndroidParameters) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AndroidParameters) GetAndroidPackageName() string {
+	if x != nil {
+		return x.AndroidPackageName
+	}
+	return ""
+}
+
+func (x *AndroidParameters) GetAndroidFallbackLink() string {
+	if x != nil {
+		return x.AndroidFallbackLink
+	}
+	return ""
+}
+
+func (x *AndroidParameters) GetAndroidMinPackageVersionCode() string {
+	if x != nil {
+		return x.AndroidMinPackageVersionCode
+	}
+	return ""
+}
+
+func (x *AndroidParameters) GetAndroidAppGalleryLink() string {
+	if x != nil {
+		return x.AndroidAppGalleryLink
+	}
+	return ""
+}
+
+type IosParameters struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	IosFallbackLink     string                 `protobuf:"bytes,1,opt,name=ios_fallback_link,json=iosFallbackLink,proto3" json:"ios_fallback_link,omitempty"`
+	IosIpadFallbackLink string                 `protobuf:"bytes,2,opt,name=ios_ipad_fallback_link,json=iosIpadFallbackLink,proto3" json:"ios_ipad_fallback_link,omitempty"`
+	IosAppStoreId       string                 `protobuf:"bytes,3,opt,name=ios_app_store_id,json=iosAppStoreId,proto3" json:"ios_app_store_id,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *IosParameters) Reset() {
+	*x = IosParameters{}
+	mi := &file_links_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IosParameters) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IosParameters) ProtoMessage() {}
+
+func (x *IosParameters) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IosParameters.ProtoReflect.Descriptor instead.
+func (*IosParameters) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *IosParameters) GetIosFallbackLink() string {
+	if x != nil {
+		return x.IosFallbackLink
+	}
+	return ""
+}
+
+func (x *IosParameters) GetIosIpadFallbackLink() string {
+	if x != nil {
+		return x.IosIpadFallbackLink
+	}
+	return ""
+}
+
+func (x *IosParameters) GetIosAppStoreId() string {
+	if x != nil {
+		return x.IosAppStoreId
+	}
+	return ""
+}
+
+type OtherPlatformParameters struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FallbackUrl   string                 `protobuf:"bytes,1,opt,name=fallback_url,json=ofl,proto3" json:"fallback_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OtherPlatformParameters) Reset() {
+	*x = OtherPlatformParameters{}
+	mi := &file_links_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OtherPlatformParameters) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OtherPlatformParameters) ProtoMessage() {}
+
+func (x *OtherPlatformParameters) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OtherPlatformParameters.ProtoReflect.Descriptor instead.
+func (*OtherPlatformParameters) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *OtherPlatformParameters) GetFallbackUrl() string {
+	if x != nil {
+		return x.FallbackUrl
+	}
+	return ""
+}
+
+type DesktopParameters struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	DesktopProtocolLink string                 `protobuf:"bytes,1,opt,name=desktop_protocol_link,json=desktopProtocolLink,proto3" json:"desktop_protocol_link,omitempty"`
+	MicrosoftStoreLink  string                 `protobuf:"bytes,2,opt,name=microsoft_store_link,json=microsoftStoreLink,proto3" json:"microsoft_store_link,omitempty"`
+	MacAppStoreLink     string                 `protobuf:"bytes,3,opt,name=mac_app_store_link,json=macAppStoreLink,proto3" json:"mac_app_store_link,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *DesktopParameters) Reset() {
+	*x = DesktopParameters{}
+	mi := &file_links_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DesktopParameters) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DesktopParameters) ProtoMessage() {}
+
+func (x *DesktopParameters) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DesktopParameters.ProtoReflect.Descriptor instead.
+func (*DesktopParameters) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DesktopParameters) GetDesktopProtocolLink() string {
+	if x != nil {
+		return x.DesktopProtocolLink
+	}
+	return ""
+}
+
+func (x *DesktopParameters) GetMicrosoftStoreLink() string {
+	if x != nil {
+		return x.MicrosoftStoreLink
+	}
+	return ""
+}
+
+func (x *DesktopParameters) GetMacAppStoreLink() string {
+	if x != nil {
+		return x.MacAppStoreLink
+	}
+	return ""
+}
+
+type AnalyticsInfo struct {
+	state                  protoimpl.MessageState  `protogen:"open.v1"`
+	MarketingParameters    *MarketingParameters    `protobuf:"bytes,1,opt,name=marketing_parameters,json=marketingParameters,proto3" json:"marketing_parameters,omitempty"`
+	ItunesConnectAnalytics *ItunesConnectAnalytics `protobuf:"bytes,2,opt,name=itunes_connect_analytics,json=itunesConnectAnalytics,proto3" json:"itunes_connect_analytics,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *AnalyticsInfo) Reset() {
+	*x = AnalyticsInfo{}
+	mi := &file_links_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyticsInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyticsInfo) ProtoMessage() {}
+
+func (x *AnalyticsInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyticsInfo.ProtoReflect.Descriptor instead.
+func (*AnalyticsInfo) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AnalyticsInfo) GetMarketingParameters() *MarketingParameters {
+	if x != nil {
+		return x.MarketingParameters
+	}
+	return nil
+}
+
+func (x *AnalyticsInfo) GetItunesConnectAnalytics() *ItunesConnectAnalytics {
+	if x != nil {
+		return x.ItunesConnectAnalytics
+	}
+	return nil
+}
+
+type MarketingParameters struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UtmSource     string                 `protobuf:"bytes,1,opt,name=utm_source,json=utmSource,proto3" json:"utm_source,omitempty"`
+	UtmMedium     string                 `protobuf:"bytes,2,opt,name=utm_medium,json=utmMedium,proto3" json:"utm_medium,omitempty"`
+	UtmCampaign   string                 `protobuf:"bytes,3,opt,name=utm_campaign,json=utmCampaign,proto3" json:"utm_campaign,omitempty"`
+	UtmTerm       string                 `protobuf:"bytes,4,opt,name=utm_term,json=utmTerm,proto3" json:"utm_term,omitempty"`
+	UtmContent    string                 `protobuf:"bytes,5,opt,name=utm_content,json=utmContent,proto3" json:"utm_content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MarketingParameters) Reset() {
+	*x = MarketingParameters{}
+	mi := &file_links_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarketingParameters) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarketingParameters) ProtoMessage() {}
+
+func (x *MarketingParameters) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarketingParameters.ProtoReflect.Descriptor instead.
+func (*MarketingParameters) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MarketingParameters) GetUtmSource() string {
+	if x != nil {
+		return x.UtmSource
+	}
+	return ""
+}
+
+func (x *MarketingParameters) GetUtmMedium() string {
+	if x != nil {
+		return x.UtmMedium
+	}
+	return ""
+}
+
+func (x *MarketingParameters) GetUtmCampaign() string {
+	if x != nil {
+		return x.UtmCampaign
+	}
+	return ""
+}
+
+func (x *MarketingParameters) GetUtmTerm() string {
+	if x != nil {
+		return x.UtmTerm
+	}
+	return ""
+}
+
+func (x *MarketingParameters) GetUtmContent() string {
+	if x != nil {
+		return x.UtmContent
+	}
+	return ""
+}
+
+type ItunesConnectAnalytics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	At            string                 `protobuf:"bytes,1,opt,name=at,proto3" json:"at,omitempty"`
+	Ct            string                 `protobuf:"bytes,2,opt,name=ct,proto3" json:"ct,omitempty"`
+	Mt            string                 `protobuf:"bytes,3,opt,name=mt,proto3" json:"mt,omitempty"`
+	Pt            string                 `protobuf:"bytes,4,opt,name=pt,proto3" json:"pt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ItunesConnectAnalytics) Reset() {
+	*x = ItunesConnectAnalytics{}
+	mi := &file_links_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ItunesConnectAnalytics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ItunesConnectAnalytics) ProtoMessage() {}
+
+func (x *ItunesConnectAnalytics) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ItunesConnectAnalytics.ProtoReflect.Descriptor instead.
+func (*ItunesConnectAnalytics) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ItunesConnectAnalytics) GetAt() string {
+	if x != nil {
+		return x.At
+	}
+	return ""
+}
+
+func (x *ItunesConnectAnalytics) GetCt() string {
+	if x != nil {
+		return x.Ct
+	}
+	return ""
+}
+
+func (x *ItunesConnectAnalytics) GetMt() string {
+	if x != nil {
+		return x.Mt
+	}
+	return ""
+}
+
+func (x *ItunesConnectAnalytics) GetPt() string {
+	if x != nil {
+		return x.Pt
+	}
+	return ""
+}
+
+type SocialMetaTagInfo struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	SocialTitle       string                 `protobuf:"bytes,1,opt,name=social_title,json=socialTitle,proto3" json:"social_title,omitempty"`
+	SocialDescription string                 `protobuf:"bytes,2,opt,name=social_description,json=socialDescription,proto3" json:"social_description,omitempty"`
+	SocialImageLink   string                 `protobuf:"bytes,3,opt,name=social_image_link,json=socialImageLink,proto3" json:"social_image_link,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *SocialMetaTagInfo) Reset() {
+	*x = SocialMetaTagInfo{}
+	mi := &file_links_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SocialMetaTagInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SocialMetaTagInfo) ProtoMessage() {}
+
+func (x *SocialMetaTagInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SocialMetaTagInfo.ProtoReflect.Descriptor instead.
+func (*SocialMetaTagInfo) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SocialMetaTagInfo) GetSocialTitle() string {
+	if x != nil {
+		return x.SocialTitle
+	}
+	return ""
+}
+
+func (x *SocialMetaTagInfo) GetSocialDescription() string {
+	if x != nil {
+		return x.SocialDescription
+	}
+	return ""
+}
+
+func (x *SocialMetaTagInfo) GetSocialImageLink() string {
+	if x != nil {
+		return x.SocialImageLink
+	}
+	return ""
+}
+
+type DeepLink struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Route         string                 `protobuf:"bytes,1,opt,name=route,proto3" json:"route,omitempty"`
+	Params        map[string]string      `protobuf:"bytes,2,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeepLink) Reset() {
+	*x = DeepLink{}
+	mi := &file_links_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeepLink) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeepLink) ProtoMessage() {}
+
+func (x *DeepLink) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeepLink.ProtoReflect.Descriptor instead.
+func (*DeepLink) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeepLink) GetRoute() string {
+	if x != nil {
+		return x.Route
+	}
+	return ""
+}
+
+func (x *DeepLink) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+type PlatformLinks struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WebLink       string                 `protobuf:"bytes,1,opt,name=web_link,json=webLink,proto3" json:"web_link,omitempty"`
+	AndroidLink   string                 `protobuf:"bytes,2,opt,name=android_link,json=androidLink,proto3" json:"android_link,omitempty"`
+	IosLink       string                 `protobuf:"bytes,3,opt,name=ios_link,json=iosLink,proto3" json:"ios_link,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlatformLinks) Reset() {
+	*x = PlatformLinks{}
+	mi := &file_links_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlatformLinks) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlatformLinks) ProtoMessage() {}
+
+func (x *PlatformLinks) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlatformLinks.ProtoReflect.Descriptor instead.
+func (*PlatformLinks) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PlatformLinks) GetWebLink() string {
+	if x != nil {
+		return x.WebLink
+	}
+	return ""
+}
+
+func (x *PlatformLinks) GetAndroidLink() string {
+	if x != nil {
+		return x.AndroidLink
+	}
+	return ""
+}
+
+func (x *PlatformLinks) GetIosLink() string {
+	if x != nil {
+		return x.IosLink
+	}
+	return ""
+}
+
+type Suffix struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// option is "SHORT", "UNGUESSABLE" or "CUSTOM".
+	Option        string `protobuf:"bytes,1,opt,name=option,proto3" json:"option,omitempty"`
+	CustomPath    string `protobuf:"bytes,2,opt,name=custom_path,json=customPath,proto3" json:"custom_path,omitempty"`
+	NumericCode   bool   `protobuf:"varint,3,opt,name=numeric_code,json=numericCode,proto3" json:"numeric_code,omitempty"`
+	SmsSafe       bool   `protobuf:"varint,4,opt,name=sms_safe,json=smsSafe,proto3" json:"sms_safe,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Suffix) Reset() {
+	*x = Suffix{}
+	mi := &file_links_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Suffix) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Suffix) ProtoMessage() {}
+
+func (x *Suffix) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Suffix.ProtoReflect.Descriptor instead.
+func (*Suffix) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Suffix) GetOption() string {
+	if x != nil {
+		return x.Option
+	}
+	return ""
+}
+
+func (x *Suffix) GetCustomPath() string {
+	if x != nil {
+		return x.CustomPath
+	}
+	return ""
+}
+
+func (x *Suffix) GetNumericCode() bool {
+	if x != nil {
+		return x.NumericCode
+	}
+	return false
+}
+
+func (x *Suffix) GetSmsSafe() bool {
+	if x != nil {
+		return x.SmsSafe
+	}
+	return false
+}
+
+type CreateDurableLinkResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ShortLink string                 `protobuf:"bytes,1,opt,name=short_link,json=shortLink,proto3" json:"short_link,omitempty"`
+	// code is the link's numeric code, when one was requested.
+	Code          string     `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Warnings      []*Warning `protobuf:"bytes,3,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDurableLinkResponse) Reset() {
+	*x = CreateDurableLinkResponse{}
+	mi := &file_links_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDurableLinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDurableLinkResponse) ProtoMessage() {}
+
+func (x *CreateDurableLinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDurableLinkResponse.ProtoReflect.Descriptor instead.
+func (*CreateDurableLinkResponse) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CreateDurableLinkResponse) GetShortLink() string {
+	if x != nil {
+		return x.ShortLink
+	}
+	return ""
+}
+
+func (x *CreateDurableLinkResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *CreateDurableLinkResponse) GetWarnings() []*Warning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+type Warning struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	WarningCode    string                 `protobuf:"bytes,1,opt,name=warning_code,json=warningCode,proto3" json:"warning_code,omitempty"`
+	WarningMessage string                 `protobuf:"bytes,2,opt,name=warning_message,json=warningMessage,proto3" json:"warning_message,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Warning) Reset() {
+	*x = Warning{}
+	mi := &file_links_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Warning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Warning) ProtoMessage() {}
+
+func (x *Warning) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Warning.ProtoReflect.Descriptor instead.
+func (*Warning) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Warning) GetWarningCode() string {
+	if x != nil {
+		return x.WarningCode
+	}
+	return ""
+}
+
+func (x *Warning) GetWarningMessage() string {
+	if x != nil {
+		return x.WarningMessage
+	}
+	return ""
+}
+
+type ExchangeShortLinkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestedLink string                 `protobuf:"bytes,1,opt,name=requested_link,json=requestedLink,proto3" json:"requested_link,omitempty"`
+	// platform picks the destination returned in link: "web", "android" or "ios".
+	Platform      string `protobuf:"bytes,2,opt,name=platform,proto3" json:"platform,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExchangeShortLinkRequest) Reset() {
+	*x = ExchangeShortLinkRequest{}
+	mi := &file_links_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExchangeShortLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExchangeShortLinkRequest) ProtoMessage() {}
+
+func (x *ExchangeShortLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExchangeShortLinkRequest.ProtoReflect.Descriptor instead.
+func (*ExchangeShortLinkRequest) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ExchangeShortLinkRequest) GetRequestedLink() string {
+	if x != nil {
+		return x.RequestedLink
+	}
+	return ""
+}
+
+func (x *ExchangeShortLinkRequest) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+type ExchangeShortLinkResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	LongLink string                 `protobuf:"bytes,1,opt,name=long_link,json=longLink,proto3" json:"long_link,omitempty"`
+	// link is the destination for the requested platform, only set when a platform was given.
+	Link          string `protobuf:"bytes,2,opt,name=link,proto3" json:"link,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExchangeShortLinkResponse) Reset() {
+	*x = ExchangeShortLinkResponse{}
+	mi := &file_links_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExchangeShortLinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExchangeShortLinkResponse) ProtoMessage() {}
+
+func (x *ExchangeShortLinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExchangeShortLinkResponse.ProtoReflect.Descriptor instead.
+func (*ExchangeShortLinkResponse) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ExchangeShortLinkResponse) GetLongLink() string {
+	if x != nil {
+		return x.LongLink
+	}
+	return ""
+}
+
+func (x *ExchangeShortLinkResponse) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+type GetLinkRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Host  string                 `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	// path is the path of the short link, or of an alias of it.
+	Path          string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLinkRequest) Reset() {
+	*x = GetLinkRequest{}
+	mi := &file_links_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLinkRequest) ProtoMessage() {}
+
+func (x *GetLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLinkRequest.ProtoReflect.Descriptor instead.
+func (*GetLinkRequest) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetLinkRequest) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *GetLinkRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type Link struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShortLink     string                 `protobuf:"bytes,1,opt,name=short_link,json=shortLink,proto3" json:"short_link,omitempty"`
+	LongLink      string                 `protobuf:"bytes,2,opt,name=long_link,json=longLink,proto3" json:"long_link,omitempty"`
+	Etag          string                 `protobuf:"bytes,3,opt,name=etag,proto3" json:"etag,omitempty"`
+	Warnings      []*Warning             `protobuf:"bytes,4,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Link) Reset() {
+	*x = Link{}
+	mi := &file_links_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Link) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Link) ProtoMessage() {}
+
+func (x *Link) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Link.ProtoReflect.Descriptor instead.
+func (*Link) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Link) GetShortLink() string {
+	if x != nil {
+		return x.ShortLink
+	}
+	return ""
+}
+
+func (x *Link) GetLongLink() string {
+	if x != nil {
+		return x.LongLink
+	}
+	return ""
+}
+
+func (x *Link) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+func (x *Link) GetWarnings() []*Warning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+type DeleteLinkRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Host  string                 `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	Path  string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	// hard purges the link instead of soft deleting it.
+	Hard          bool `protobuf:"varint,3,opt,name=hard,proto3" json:"hard,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteLinkRequest) Reset() {
+	*x = DeleteLinkRequest{}
+	mi := &file_links_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteLinkRequest) ProtoMessage() {}
+
+func (x *DeleteLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteLinkRequest.ProtoReflect.Descriptor instead.
+func (*DeleteLinkRequest) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DeleteLinkRequest) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *DeleteLinkRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DeleteLinkRequest) GetHard() bool {
+	if x != nil {
+		return x.Hard
+	}
+	return false
+}
+
+type DeleteLinkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteLinkResponse) Reset() {
+	*x = DeleteLinkResponse{}
+	mi := &file_links_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteLinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteLinkResponse) ProtoMessage() {}
+
+func (x *DeleteLinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_links_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteLinkResponse.ProtoReflect.Descriptor instead.
+func (*DeleteLinkResponse) Descriptor() ([]byte, []int) {
+	return file_links_proto_rawDescGZIP(), []int{20}
+}
+
+var File_links_proto protoreflect.FileDescriptor
+
+var file_links_proto_rawDesc = string([]byte{
+	0x0a, 0x0b, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0f, 0x64,
+	0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x22, 0xc5,
+	0x01, 0x0a, 0x18, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65,
+	0x4c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2a, 0x0a, 0x11, 0x6c,
+	0x6f, 0x6e, 0x67, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x6c, 0x69, 0x6e, 0x6b,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x6c, 0x6f, 0x6e, 0x67, 0x44, 0x75, 0x72, 0x61,
+	0x62, 0x6c, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x4c, 0x0a, 0x11, 0x64, 0x75, 0x72, 0x61, 0x62,
+	0x6c, 0x65, 0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x5f, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x20, 0x2e, 0x64, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x6c, 0x69, 0x6e, 0x6b,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x4c, 0x69, 0x6e, 0x6b,
+	0x49, 0x6e, 0x66, 0x6f, 0x52, 0x0f, 0x64, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x4c, 0x69, 0x6e,
+	0x6b, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x2f, 0x0a, 0x06, 0x73, 0x75, 0x66, 0x66, 0x69, 0x78, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x64, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x6c,
+	0x69, 0x6e, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x75, 0x66, 0x66, 0x69, 0x78, 0x52, 0x06,
+	0x73, 0x75, 0x66, 0x66, 0x69, 0x78, 0x22, 0xd6, 0x05, 0x0a, 0x0f, 0x44, 0x75, 0x72, 0x61, 0x62,
+	0x6c, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f,
+	0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6c, 0x69,
+	0x6e, 0x6b, 0x12, 0x51, 0x0a, 0x12, 0x61, 0x6e, 0x64, 0x72, 0x6f, 0x69, 0x64, 0x5f, 0x70, 0x61,
+	0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22,
+	0x2e, 0x64, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x41, 0x6e, 0x64, 0x72, 0x6f, 0x69, 0x64, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65,
+	0x72, 0x73, 0x52, 0x11, 0x61, 0x6e, 0x64, 0x72, 0x6f, 0x69, 0x64, 0x50, 0x61, 0x72, 0x61, 0x6d,
+	0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x45, 0x0a, 0x0e, 0x69, 0x6f, 0x73, 0x5f, 0x70, 0x61, 0x72,
+	0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e,
+	0x64, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x49, 0x6f, 0x73, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x52, 0x0d, 0x69,
+	0x6f, 0x73, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x64, 0x0a, 0x19,
+	0x6f, 0x74, 0x68, 0x65, 0x72, 0x5f, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x70,
+	0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x28, 0x2e, 0x64, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x4f, 0x74, 0x68, 0x65, 0x72, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x50,
+	0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x52, 0x17, 0x6f, 0x74, 0x68, 0x65, 0x72,
+	0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65,
+	0x72, 0x73, 0x12, 0x51, 0x0a, 0x12, 0x64, 0x65, 0x73, 0x6b, 0x74, 0x6f, 0x70, 0x5f, 0x70, 0x61,
+	0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22,
+	0x2e, 0x64, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x44, 0x65, 0x73, 0x6b, 0x74, 0x6f, 0x70, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65,
+	0x72, 0x73, 0x52, 0x11, 0x64, 0x65, 0x73, 0x6b, 0x74, 0x6f, 0x70, 0x50, 0x61, 0x72, 0x61, 0x6d,
+	0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x45, 0x0a, 0x0e, 0x61, 0x6e, 0x61, 0x6c, 0x79, 0x74, 0x69,
+	0x63, 0x73, 0x5f, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e,
+	0x64, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x41, 0x6e, 0x61, 0x6c, 0x79, 0x74, 0x69, 0x63, 0x73, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x0d, 0x61,
+	0x6e, 0x61, 0x6c, 0x79, 0x74, 0x69, 0x63, 0x73, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x53, 0x0a, 0x14,
+	0x73, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x5f, 0x6d, 0x65, 0x74, 0x61, 0x5f, 0x74, 0x61, 0x67, 0x5f,
+	0x69, 0x6e, 0x66, 0x6f, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x64, 0x75, 0x72,
+	0x61, 0x62, 0x6c, 0x65, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x6f, 0x63,
+	0x69, 0x61, 0x6c, 0x4d, 0x65, 0x74, 0x61, 0x54, 0x61, 0x67, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x11,
+	0x73, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x4d, 0x65, 0x74, 0x61, 0x54, 0x61, 0x67, 0x49, 0x6e, 0x66,
+	0x6f, 0x12, 0x12, 0x0a, 0x04, 0x67, 0x61, 0x74, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x67, 0x61, 0x74, 0x65, 0x12, 0x36, 0x0a, 0x09, 0x64, 0x65, 0x65, 0x70, 0x5f, 0x6c, 0x69,
+	0x6e, 0x6b, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x64, 0x75, 0x72, 0x61, 0x62,
+	0x6c, 0x65, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x65, 0x70, 0x4c,
+	0x69, 0x6e, 0x6b, 0x52, 0x08, 0x64, 0x65, 0x65, 0x70, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x45, 0x0a,
+	0x0e, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x64, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x6c,
+	0x69, 0x6e, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d,
+	0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x52, 0x0d, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x4c,
+	0x69, 0x6e, 0x6b, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x71, 0x72, 0x5f, 0x66, 0x69, 0x72, 0x73, 0x74,
+	0x18, 0x0c, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x71, 0x72, 0x46, 0x69, 0x72, 0x73, 0x74, 0x22,
+	0xfa, 0x01, 0x0a, 0x11, 0x41, 0x6e, 0x64, 0x72, 0x6f, 0x69, 0x64, 0x50, 0x61, 0x72, 0x61, 0x6d,
+	0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x30, 0x0a, 0x14, 0x61, 0x6e, 0x64, 0x72, 0x6f, 0x69, 0x64,
+	0x5f, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x12, 0x61, 0x6e, 0x64, 0x72, 0x6f, 0x69, 0x64, 0x50, 0x61, 0x63, 0x6b,
+	0x61, 0x67, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x32, 0x0a, 0x15, 0x61, 0x6e, 0x64, 0x72, 0x6f,
+	0x69, 0x64, 0x5f, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x5f, 0x6c, 0x69, 0x6e, 0x6b,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x13, 0x61, 0x6e, 0x64, 0x72, 0x6f, 0x69, 0x64, 0x46,
+	0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x46, 0x0a, 0x20, 0x61,
+	0x6e, 0x64, 0x72, 0x6f, 0x69, 0x64, 0x5f, 0x6d, 0x69, 0x6e, 0x5f, 0x70, 0x61, 0x63, 0x6b, 0x61,
+	0x67, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x1c, 0x61, 0x6e, 0x64, 0x72, 0x6f, 0x69, 0x64, 0x4d, 0x69,
+	0x6e, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x43,
+	0x6f, 0x64, 0x65, 0x12, 0x37, 0x0a, 0x18, 0x61, 0x6e, 0x64, 0x72, 0x6f, 0x69, 0x64, 0x5f, 0x61,
+	0x70, 0x70, 0x5f, 0x67, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x79, 0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x15, 0x61, 0x6e, 0x64, 0x72, 0x6f, 0x69, 0x64, 0x41, 0x70,
+	0x70, 0x47, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x79, 0x4c, 0x69, 0x6e, 0x6b, 0x22, 0x99, 0x01, 0x0a,
+	0x0d, 0x49, 0x6f, 0x73, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x2a,
+	0x0a, 0x11, 0x69, 0x6f, 0x73, 0x5f, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x5f, 0x6c,
+	0x69, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x69, 0x6f, 0x73, 0x46, 0x61,
+	0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x33, 0x0a, 0x16, 0x69, 0x6f,
+	0x73, 0x5f, 0x69, 0x70, 0x61, 0x64, 0x5f, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x5f,
+	0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x13, 0x69, 0x6f, 0x73, 0x49,
+	0x70, 0x61, 0x64, 0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x4c, 0x69, 0x6e, 0x6b, 0x12,
+	0x27, 0x0a, 0x10, 0x69, 0x6f, 0x73, 0x5f, 0x61, 0x70, 0x70, 0x5f, 0x73, 0x74, 0x6f, 0x72, 0x65,
+	0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x69, 0x6f, 0x73, 0x41, 0x70,
+	0x70, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x49, 0x64, 0x22, 0x34, 0x0a, 0x17, 0x4f, 0x74, 0x68, 0x65,
+	0x72, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74,
+	0x65, 0x72, 0x73, 0x12, 0x19, 0x0a, 0x0c, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x5f,
+	0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6f, 0x66, 0x6c, 0x22, 0xa6,
+	0x01, 0x0a, 0x11, 0x44, 0x65, 0x73, 0x6b, 0x74, 0x6f, 0x70, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65,
+	0x74, 0x65, 0x72, 0x73, 0x12, 0x32, 0x0a, 0x15, 0x64, 0x65, 0x73, 0x6b, 0x74, 0x6f, 0x70, 0x5f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x13, 0x64, 0x65, 0x73, 0x6b, 0x74, 0x6f, 0x70, 0x50, 0x72, 0x6f, 0x74,
+	0x6f, 0x63, 0x6f, 0x6c, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x30, 0x0a, 0x14, 0x6d, 0x69, 0x63, 0x72,
+	0x6f, 0x73, 0x6f, 0x66, 0x74, 0x5f, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x5f, 0x6c, 0x69, 0x6e, 0x6b,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x6d, 0x69, 0x63, 0x72, 0x6f, 0x73, 0x6f, 0x66,
+	0x74, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x2b, 0x0a, 0x12, 0x6d, 0x61,
+	0x63, 0x5f, 0x61, 0x70, 0x70, 0x5f, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x5f, 0x6c, 0x69, 0x6e, 0x6b,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x6d, 0x61, 0x63, 0x41, 0x70, 0x70, 0x53, 0x74,
+	0x6f, 0x72, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x22, 0xcb, 0x01, 0x0a, 0x0d, 0x41, 0x6e, 0x61, 0x6c,
+	0x79, 0x74, 0x69, 0x63, 0x73, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x57, 0x0a, 0x14, 0x6d, 0x61, 0x72,
+	0x6b, 0x65, 0x74, 0x69, 0x6e, 0x67, 0x5f, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x64, 0x75, 0x72, 0x61, 0x62, 0x6c,
+	0x65, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x61, 0x72, 0x6b, 0x65, 0x74,
+	0x69, 0x6e, 0x67, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x52, 0x13, 0x6d,
+	0x61, 0x72, 0x6b, 0x65, 0x74, 0x69, 0x6e, 0x67, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65,
+	0x72, 0x73, 0x12, 0x61, 0x0a, 0x18, 0x69, 0x74, 0x75, 0x6e, 0x65, 0x73, 0x5f, 0x63, 0x6f, 0x6e,
+	0x6e, 0x65, 0x63, 0x74, 0x5f, 0x61, 0x6e, 0x61, 0x6c, 0x79, 0x74, 0x69, 0x63, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x64, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x6c, 0x69,
+	0x6e, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x74, 0x75, 0x6e, 0x65, 0x73, 0x43, 0x6f, 0x6e,
+	0x6e, 0x65, 0x63, 0x74, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x74, 0x69, 0x63, 0x73, 0x52, 0x16, 0x69,
+	0x74, 0x75, 0x6e, 0x65, 0x73, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x41, 0x6e, 0x61, 0x6c,
+	0x79, 0x74, 0x69, 0x63, 0x73, 0x22, 0xb2, 0x01, 0x0a, 0x13, 0x4d, 0x61, 0x72, 0x6b, 0x65, 0x74,
+	0x69, 0x6e, 0x67, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x1d, 0x0a,
+	0x0a, 0x75, 0x74, 0x6d, 0x5f, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x75, 0x74, 0x6d, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x1d, 0x0a, 0x0a,
+	0x75, 0x74, 0x6d, 0x5f, 0x6d, 0x65, 0x64, 0x69, 0x75, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x75, 0x74, 0x6d, 0x4d, 0x65, 0x64, 0x69, 0x75, 0x6d, 0x12, 0x21, 0x0a, 0x0c, 0x75,
+	0x74, 0x6d, 0x5f, 0x63, 0x61, 0x6d, 0x70, 0x61, 0x69, 0x67, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x75, 0x74, 0x6d, 0x43, 0x61, 0x6d, 0x70, 0x61, 0x69, 0x67, 0x6e, 0x12, 0x19,
+	0x0a, 0x08, 0x75, 0x74, 0x6d, 0x5f, 0x74, 0x65, 0x72, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x75, 0x74, 0x6d, 0x54, 0x65, 0x72, 0x6d, 0x12, 0x1f, 0x0a, 0x0b, 0x75, 0x74, 0x6d,
+	0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x75, 0x74, 0x6d, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0x58, 0x0a, 0x16, 0x49, 0x74,
+	0x75, 0x6e, 0x65, 0x73, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x41, 0x6e, 0x61, 0x6c, 0x79,
+	0x74, 0x69, 0x63, 0x73, 0x12, 0x0e, 0x0a, 0x02, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x61, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x63, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x63, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x6d, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x6d, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x70, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x70, 0x74, 0x22, 0x91, 0x01, 0x0a, 0x11, 0x53, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x4d,
+	0x65, 0x74, 0x61, 0x54, 0x61, 0x67, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x6f,
+	0x63, 0x69, 0x61, 0x6c, 0x5f, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x54, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x2d, 0x0a,
+	0x12, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x5f, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x73, 0x6f, 0x63, 0x69, 0x61,
+	0x6c, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2a, 0x0a, 0x11,
+	0x73, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x5f, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x5f, 0x6c, 0x69, 0x6e,
+	0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x6c, 0x49,
+	0x6d, 0x61, 0x67, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x22, 0x9a, 0x01, 0x0a, 0x08, 0x44, 0x65, 0x65,
+	0x70, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x12, 0x3d, 0x0a, 0x06, 0x70,
+	0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x64, 0x75,
+	0x72, 0x61, 0x62, 0x6c, 0x65, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65,
+	0x65, 0x70, 0x4c, 0x69, 0x6e, 0x6b, 0x2e, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x1a, 0x39, 0x0a, 0x0b, 0x50, 0x61,
+	0x72, 0x61, 0x6d, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x68, 0x0a, 0x0d, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72,
+	0x6d, 0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x77, 0x65, 0x62, 0x5f, 0x6c, 0x69,
+	0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x77, 0x65, 0x62, 0x4c, 0x69, 0x6e,
+	0x6b, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x6e, 0x64, 0x72, 0x6f, 0x69, 0x64, 0x5f, 0x6c, 0x69, 0x6e,
+	0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x6e, 0x64, 0x72, 0x6f, 0x69, 0x64,
+	0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x19, 0x0a, 0x08, 0x69, 0x6f, 0x73, 0x5f, 0x6c, 0x69, 0x6e, 0x6b,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x69, 0x6f, 0x73, 0x4c, 0x69, 0x6e, 0x6b, 0x22,
+	0x7f, 0x0a, 0x06, 0x53, 0x75, 0x66, 0x66, 0x69, 0x78, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6f, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x5f, 0x70, 0x61, 0x74, 0x68,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x50, 0x61,
+	0x74, 0x68, 0x12, 0x21, 0x0a, 0x0c, 0x6e, 0x75, 0x6d, 0x65, 0x72, 0x69, 0x63, 0x5f, 0x63, 0x6f,
+	0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x6e, 0x75, 0x6d, 0x65, 0x72, 0x69,
+	0x63, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x73, 0x6d, 0x73, 0x5f, 0x73, 0x61, 0x66,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x6d, 0x73, 0x53, 0x61, 0x66, 0x65,
+	0x22, 0x84, 0x01, 0x0a, 0x19, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x75, 0x72, 0x61, 0x62,
+	0x6c, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d,
+	0x0a, 0x0a, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x12, 0x0a,
+	0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f, 0x64,
+	0x65, 0x12, 0x34, 0x0a, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x64, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x6c, 0x69, 0x6e,
+	0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x77,
+	0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73, 0x22, 0x55, 0x0a, 0x07, 0x57, 0x61, 0x72, 0x6e, 0x69,
+	0x6e, 0x67, 0x12, 0x21, 0x0a, 0x0c, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x63, 0x6f,
+	0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e,
+	0x67, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67,
+	0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e,
+	0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x5d,
+	0x0a, 0x18, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x4c,
+	0x69, 0x6e, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0d, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x4c, 0x69, 0x6e,
+	0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x22, 0x4c, 0x0a,
+	0x19, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x4c, 0x69,
+	0x6e, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x6c, 0x6f,
+	0x6e, 0x67, 0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c,
+	0x6f, 0x6e, 0x67, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x6b, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6c, 0x69, 0x6e, 0x6b, 0x22, 0x38, 0x0a, 0x0e, 0x47,
+	0x65, 0x74, 0x4c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x68, 0x6f, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x6f, 0x73,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x70, 0x61, 0x74, 0x68, 0x22, 0x8c, 0x01, 0x0a, 0x04, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x1d,
+	0x0a, 0x0a, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x1b, 0x0a,
+	0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x5f, 0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x6c, 0x6f, 0x6e, 0x67, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x65, 0x74,
+	0x61, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x65, 0x74, 0x61, 0x67, 0x12, 0x34,
+	0x0a, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x18, 0x2e, 0x64, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x57, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x77, 0x61, 0x72, 0x6e,
+	0x69, 0x6e, 0x67, 0x73, 0x22, 0x4f, 0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x69,
+	0x6e, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f, 0x73,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74,
+	0x68, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x72, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x04, 0x68, 0x61, 0x72, 0x64, 0x22, 0x14, 0x0a, 0x12, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c,
+	0x69, 0x6e, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0xf9, 0x02, 0x0a, 0x05,
+	0x4c, 0x69, 0x6e, 0x6b, 0x73, 0x12, 0x6a, 0x0a, 0x11, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44,
+	0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x29, 0x2e, 0x64, 0x75, 0x72,
+	0x61, 0x62, 0x6c, 0x65, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x44, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2a, 0x2e, 0x64, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x6c,
+	0x69, 0x6e, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x75,
+	0x72, 0x61, 0x62, 0x6c, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x6a, 0x0a, 0x11, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x53, 0x68, 0x6f,
+	0x72, 0x74, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x29, 0x2e, 0x64, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65,
+	0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67,
+	0x65, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x4c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x2a, 0x2e, 0x64, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x6c, 0x69, 0x6e, 0x6b, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x53, 0x68, 0x6f, 0x72,
+	0x74, 0x4c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x41, 0x0a,
+	0x07, 0x47, 0x65, 0x74, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x1f, 0x2e, 0x64, 0x75, 0x72, 0x61, 0x62,
+	0x6c, 0x65, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x69,
+	0x6e, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x64, 0x75, 0x72, 0x61,
+	0x62, 0x6c, 0x65, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x6e, 0x6b,
+	0x12, 0x55, 0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x12, 0x22,
+	0x2e, 0x64, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x23, 0x2e, 0x64, 0x75, 0x72, 0x61, 0x62, 0x6c, 0x65, 0x6c, 0x69, 0x6e, 0x6b,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4c, 0x69, 0x6e, 0x6b, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x25, 0x5a, 0x23, 0x64, 0x75, 0x72, 0x61, 0x62,
+	0x6c, 0x65, 0x2d, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x2d, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74,
+	0x6f, 0x72, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x6c, 0x69, 0x6e, 0x6b, 0x73, 0x70, 0x62, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_links_proto_rawDescOnce sync.Once
+	file_links_proto_rawDescData []byte
+)
+
+func file_links_proto_rawDescGZIP() []byte {
+	file_links_proto_rawDescOnce.Do(func() {
+		file_links_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_links_proto_rawDesc), len(file_links_proto_rawDesc)))
+	})
+	return file_links_proto_rawDescData
+}
+
+var file_links_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_links_proto_goTypes = []any{
+	(*CreateDurableLinkRequest)(nil),  // 0: durablelinks.v1.CreateDurableLinkRequest
+	(*DurableLinkInfo)(nil),           // 1: durablelinks.v1.DurableLinkInfo
+	(*AndroidParameters)(nil),         // 2: durablelinks.v1.AndroidParameters
+	(*IosParameters)(nil),             // 3: durablelinks.v1.IosParameters
+	(*OtherPlatformParameters)(nil),   // 4: durablelinks.v1.OtherPlatformParameters
+	(*DesktopParameters)(nil),         // 5: durablelinks.v1.DesktopParameters
+	(*AnalyticsInfo)(nil),             // 6: durablelinks.v1.AnalyticsInfo
+	(*MarketingParameters)(nil),       // 7: durablelinks.v1.MarketingParameters
+	(*ItunesConnectAnalytics)(nil),    // 8: durablelinks.v1.ItunesConnectAnalytics
+	(*SocialMetaTagInfo)(nil),         // 9: durablelinks.v1.SocialMetaTagInfo
+	(*DeepLink)(nil),                  // 10: durablelinks.v1.DeepLink
+	(*PlatformLinks)(nil),             // 11: durablelinks.v1.PlatformLinks
+	(*Suffix)(nil),                    // 12: durablelinks.v1.Suffix
+	(*CreateDurableLinkResponse)(nil), // 13: durablelinks.v1.CreateDurableLinkResponse
+	(*Warning)(nil),                   // 14: durablelinks.v1.Warning
+	(*ExchangeShortLinkRequest)(nil),  // 15: durablelinks.v1.ExchangeShortLinkRequest
+	(*ExchangeShortLinkResponse)(nil), // 16: durablelinks.v1.ExchangeShortLinkResponse
+	(*GetLinkRequest)(nil),            // 17: durablelinks.v1.GetLinkRequest
+	(*Link)(nil),                      // 18: durablelinks.v1.Link
+	(*DeleteLinkRequest)(nil),         // 19: durablelinks.v1.DeleteLinkRequest
+	(*DeleteLinkResponse)(nil),        // 20: durablelinks.v1.DeleteLinkResponse
+	nil,                               // 21: durablelinks.v1.DeepLink.ParamsEntry
+}
+var file_links_proto_depIdxs = []int32{
+	1,  // 0: durablelinks.v1.CreateDurableLinkRequest.durable_link_info:type_name -> durablelinks.v1.DurableLinkInfo
+	12, // 1: durablelinks.v1.CreateDurableLinkRequest.suffix:type_name -> durablelinks.v1.Suffix
+	2,  // 2: durablelinks.v1.DurableLinkInfo.android_parameters:type_name -> durablelinks.v1.AndroidParameters
+	3,  // 3: durablelinks.v1.DurableLinkInfo.ios_parameters:type_name -> durablelinks.v1.IosParameters
+	4,  // 4: durablelinks.v1.DurableLinkInfo.other_platform_parameters:type_name -> durablelinks.v1.OtherPlatformParameters
+	5,  // 5: durablelinks.v1.DurableLinkInfo.desktop_parameters:type_name -> durablelinks.v1.DesktopParameters
+	6,  // 6: durablelinks.v1.DurableLinkInfo.analytics_info:type_name -> durablelinks.v1.AnalyticsInfo
+	9,  // 7: durablelinks.v1.DurableLinkInfo.social_meta_tag_info:type_name -> durablelinks.v1.SocialMetaTagInfo
+	10, // 8: durablelinks.v1.DurableLinkInfo.deep_link:type_name -> durablelinks.v1.DeepLink
+	11, // 9: durablelinks.v1.DurableLinkInfo.platform_links:type_name -> durablelinks.v1.PlatformLinks
+	7,  // 10: durablelinks.v1.AnalyticsInfo.marketing_parameters:type_name -> durablelinks.v1.MarketingParameters
+	8,  // 11: durablelinks.v1.AnalyticsInfo.itunes_connect_analytics:type_name -> durablelinks.v1.ItunesConnectAnalytics
+	21, // 12: durablelinks.v1.DeepLink.params:type_name -> durablelinks.v1.DeepLink.ParamsEntry
+	14, // 13: durablelinks.v1.CreateDurableLinkResponse.warnings:type_name -> durablelinks.v1.Warning
+	14, // 14: durablelinks.v1.Link.warnings:type_name -> durablelinks.v1.Warning
+	0,  // 15: durablelinks.v1.Links.CreateDurableLink:input_type -> durablelinks.v1.CreateDurableLinkRequest
+	15, // 16: durablelinks.v1.Links.ExchangeShortLink:input_type -> durablelinks.v1.ExchangeShortLinkRequest
+	17, // 17: durablelinks.v1.Links.GetLink:input_type -> durablelinks.v1.GetLinkRequest
+	19, // 18: durablelinks.v1.Links.DeleteLink:input_type -> durablelinks.v1.DeleteLinkRequest
+	13, // 19: durablelinks.v1.Links.CreateDurableLink:output_type -> durablelinks.v1.CreateDurableLinkResponse
+	16, // 20: durablelinks.v1.Links.ExchangeShortLink:output_type -> durablelinks.v1.ExchangeShortLinkResponse
+	18, // 21: durablelinks.v1.Links.GetLink:output_type -> durablelinks.v1.Link
+	20, // 22: durablelinks.v1.Links.DeleteLink:output_type -> durablelinks.v1.DeleteLinkResponse
+	19, // [19:23] is the sub-list for method output_type
+	15, // [15:19] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_links_proto_init() }
+func file_links_proto_init() {
+	if File_links_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_links_proto_rawDesc), len(file_links_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   22,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_links_proto_goTypes,
+		DependencyIndexes: file_links_proto_depIdxs,
+		MessageInfos:      file_links_proto_msgTypes,
+	}.Build()
+	File_links_proto = out.File
+	file_links_proto_goTypes = nil
+	file_links_proto_depIdxs = nil
+}
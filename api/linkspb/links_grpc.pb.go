@@ -0,0 +1,253 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: links.proto
+
+package linkspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Links_CreateDurableLink_FullMethodName = "/durablelinks.v1.Links/CreateDurableLink"
+	Links_ExchangeShortLink_FullMethodName = "/durablelinks.v1.Links/ExchangeShortLink"
+	Links_GetLink_FullMethodName           = "/durablelinks.v1.Links/GetLink"
+	Links_DeleteLink_FullMethodName        = "/durablelinks.v1.Links/DeleteLink"
+)
+
+// LinksClient is the client API for Links service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Links creates and resolves durable links for internal services, on GRPC_PORT. Every call needs
+// one of ADMIN_API_KEYS, sent as "x-api-key" or "authorization: Bearer <key>" metadata. Errors
+// carry the status the REST API would return, e.g. INVALID_ARGUMENT or NOT_FOUND.
+type LinksClient interface {
+	// CreateDurableLink creates a short link, or returns the existing one for the same parameters,
+	// like "POST /shortLinks".
+	CreateDurableLink(ctx context.Context, in *CreateDurableLinkRequest, opts ...grpc.CallOption) (*CreateDurableLinkResponse, error)
+	// ExchangeShortLink returns the long link of a short link, like "POST /exchangeShortLink".
+	ExchangeShortLink(ctx context.Context, in *ExchangeShortLinkRequest, opts ...grpc.CallOption) (*ExchangeShortLinkResponse, error)
+	// GetLink returns a stored link, like "GET /shortLinks/{path}".
+	GetLink(ctx context.Context, in *GetLinkRequest, opts ...grpc.CallOption) (*Link, error)
+	// DeleteLink deletes a link, like "DELETE /shortLinks/{path}".
+	DeleteLink(ctx context.Context, in *DeleteLinkRequest, opts ...grpc.CallOption) (*DeleteLinkResponse, error)
+}
+
+type linksClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLinksClient(cc grpc.ClientConnInterface) LinksClient {
+	return &linksClient{cc}
+}
+
+func (c *linksClient) CreateDurableLink(ctx context.Context, in *CreateDurableLinkRequest, opts ...grpc.CallOption) (*CreateDurableLinkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateDurableLinkResponse)
+	err := c.cc.Invoke(ctx, Links_CreateDurableLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *linksClient) ExchangeShortLink(ctx context.Context, in *ExchangeShortLinkRequest, opts ...grpc.CallOption) (*ExchangeShortLinkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExchangeShortLinkResponse)
+	err := c.cc.Invoke(ctx, Links_ExchangeShortLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *linksClient) GetLink(ctx context.Context, in *GetLinkRequest, opts ...grpc.CallOption) (*Link, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Link)
+	err := c.cc.Invoke(ctx, Links_GetLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *linksClient) DeleteLink(ctx context.Context, in *DeleteLinkRequest, opts ...grpc.CallOption) (*DeleteLinkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteLinkResponse)
+	err := c.cc.Invoke(ctx, Links_DeleteLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LinksServer is the server API for Links service.
+// All implementations must embed UnimplementedLinksServer
+// for forward compatibility.
+//
+// Links creates and resolves durable links for internal services, on GRPC_PORT. Every call needs
+// one of ADMIN_API_KEYS, sent as "x-api-key" or "authorization: Bearer <key>" metadata. Errors
+// carry the status the REST API would return, e.g. INVALID_ARGUMENT or NOT_FOUND.
+type LinksServer interface {
+	// CreateDurableLink creates a short link, or returns the existing one for the same parameters,
+	// like "POST /shortLinks".
+	CreateDurableLink(context.Context, *CreateDurableLinkRequest) (*CreateDurableLinkResponse, error)
+	// ExchangeShortLink returns the long link of a short link, like "POST /exchangeShortLink".
+	ExchangeShortLink(context.Context, *ExchangeShortLinkRequest) (*ExchangeShortLinkResponse, error)
+	// GetLink returns a stored link, like "GET /shortLinks/{path}".
+	GetLink(context.Context, *GetLinkRequest) (*Link, error)
+	// DeleteLink deletes a link, like "DELETE /shortLinks/{path}".
+	DeleteLink(context.Context, *DeleteLinkRequest) (*DeleteLinkResponse, error)
+	mustEmbedUnimplementedLinksServer()
+}
+
+// UnimplementedLinksServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedLinksServer struct{}
+
+func (UnimplementedLinksServer) CreateDurableLink(context.Context, *CreateDurableLinkRequest) (*CreateDurableLinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateDurableLink not implemented")
+}
+func (UnimplementedLinksServer) ExchangeShortLink(context.Context, *ExchangeShortLinkRequest) (*ExchangeShortLinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExchangeShortLink not implemented")
+}
+func (UnimplementedLinksServer) GetLink(context.Context, *GetLinkRequest) (*Link, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLink not implemented")
+}
+func (UnimplementedLinksServer) DeleteLink(context.Context, *DeleteLinkRequest) (*DeleteLinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteLink not implemented")
+}
+func (UnimplementedLinksServer) mustEmbedUnimplementedLinksServer() {}
+func (UnimplementedLinksServer) testEmbeddedByValue()               {}
+
+// UnsafeLinksServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LinksServer will
+// result in compilation errors.
+type UnsafeLinksServer interface {
+	mustEmbedUnimplementedLinksServer()
+}
+
+func RegisterLinksServer(s grpc.ServiceRegistrar, srv LinksServer) {
+	// If the following call pancis, it indicates UnimplementedLinksServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Links_ServiceDesc, srv)
+}
+
+func _Links_CreateDurableLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDurableLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LinksServer).CreateDurableLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Links_CreateDurableLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LinksServer).CreateDurableLink(ctx, req.(*CreateDurableLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Links_ExchangeShortLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExchangeShortLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LinksServer).ExchangeShortLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Links_ExchangeShortLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LinksServer).ExchangeShortLink(ctx, req.(*ExchangeShortLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Links_GetLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LinksServer).GetLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Links_GetLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LinksServer).GetLink(ctx, req.(*GetLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Links_DeleteLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LinksServer).DeleteLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Links_DeleteLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LinksServer).DeleteLink(ctx, req.(*DeleteLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Links_ServiceDesc is the grpc.ServiceDesc for Links service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Links_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "durablelinks.v1.Links",
+	HandlerType: (*LinksServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateDurableLink",
+			Handler:    _Links_CreateDurableLink_Handler,
+		},
+		{
+			MethodName: "ExchangeShortLink",
+			Handler:    _Links_ExchangeShortLink_Handler,
+		},
+		{
+			MethodName: "GetLink",
+			Handler:    _Links_GetLink_Handler,
+		},
+		{
+			MethodName: "DeleteLink",
+			Handler:    _Links_DeleteLink_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "links.proto",
+}
@@ -0,0 +1,539 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/audit"
+	"durable-links-generator/api/authz"
+	"durable-links-generator/api/captcha"
+	"durable-links-generator/api/limiter"
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+	"durable-links-generator/db"
+	"durable-links-generator/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// requestIDHeader carries the ID of a request, on the request when the caller or a proxy in front
+// of us set one, and on its response.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the request IDs taken from callers.
+const maxRequestIDLength = 128
+
+// RequestID gives every request an ID, returned in the X-Request-ID header and quoted in error
+// responses. A valid ID sent by the caller is kept, so logs can be followed across services.
+// The request's context carries a logger tagging every line with the ID, which handlers,
+// services and repositories get with log.Ctx.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		ctx := context.WithValue(r.Context(), middleware.RequestIDKey, id)
+		logger := log.With().Str("request_id", id).Logger()
+		next.ServeHTTP(w, r.WithContext(logger.WithContext(ctx)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID reports whether id can be used as a request ID: short and made of letters,
+// digits and -._:, so it can't forge log lines or headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// requestLog returns a logger tagged with the ID of the request answered by w, for code that
+// has the response but not the request.
+func requestLog(w http.ResponseWriter) *zerolog.Logger {
+	logger := log.With().Str("request_id", w.Header().Get(requestIDHeader)).Logger()
+	return &logger
+}
+
+// Authenticate rejects requests that don't carry an API key, either one of ADMIN_API_KEYS or
+// one created through /v1/apiKeys, or, with OIDC_ISSUER set, a token of a subject with a role,
+// either as a bearer token or in the X-API-Key header. Tokens limited to some projects are also
+// rejected on requests for other projects, and on requests whose body can't be read for the
+// projects it names; imports check each row instead. What the caller may do is up to
+// RequireRole. Without keys or an issuer the admin API is disabled.
+func Authenticate(auth *authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, err := auth.authenticate(r.Context(), apiKeyFromRequest(r))
+			switch {
+			case errors.Is(err, errMissingCredential):
+				WriteErrorResponse(w, http.StatusUnauthorized, "Missing API key", models.StatusUnauthenticated)
+				return
+			case errors.Is(err, errTokenInvalid):
+				log.Ctx(r.Context()).Debug().Err(err).Msg("Rejected token")
+				WriteErrorResponse(w, http.StatusUnauthorized, "Invalid or expired token", models.StatusUnauthenticated)
+				return
+			case errors.Is(err, errProviderDown):
+				log.Ctx(r.Context()).Error().Err(err).Msg("Failed to verify token")
+				WriteErrorResponse(w, http.StatusServiceUnavailable, "Identity provider unavailable", models.StatusUnavailable)
+				return
+			case errors.Is(err, errNoRole):
+				WriteErrorResponse(w, http.StatusForbidden, "Token has no role allowed to access this endpoint", models.StatusPermissionDenied)
+				return
+			case errors.Is(err, errKeyNotAllowed):
+				WriteErrorResponse(w, http.StatusForbidden, "API key is not allowed to access this endpoint", models.StatusPermissionDenied)
+				return
+			case err != nil:
+				log.Ctx(r.Context()).Error().Err(err).Msg("Failed to authenticate")
+				WriteErrorResponse(w, http.StatusInternalServerError, "Failed to authenticate", models.StatusInternal)
+				return
+			}
+			if r.Method != http.MethodOptions {
+				projects, complete := requestProjects(r)
+				if !complete && p.Projects != nil {
+					WriteErrorResponse(w, http.StatusForbidden, "Token limited to projects needs a JSON request body of at most 1 MiB", models.StatusPermissionDenied)
+					return
+				}
+				if !mayAccessProjects(p, projects) {
+					WriteErrorResponse(w, http.StatusForbidden, "Token is not allowed to access this project", models.StatusPermissionDenied)
+					return
+				}
+			}
+			ctx := authz.WithProjects(withPrincipal(r.Context(), p), p.Projects)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireRole rejects requests whose caller, authenticated by Authenticate, has a less
+// privileged role than role.
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if p := principalFrom(r.Context()); p == nil || !p.hasRole(role) {
+				WriteErrorResponse(w, http.StatusForbidden, "This endpoint needs the "+role+" role", models.StatusPermissionDenied)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RecordActor puts who is calling into the request's context, for the audit log entries of the
+// changes the request makes: its principal once Authenticate ran, its API key and address either
+// way.
+func RecordActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := audit.Actor{
+			Key:       callerFingerprint(r),
+			ClientIP:  clientIP(r),
+			RequestID: middleware.GetReqID(r.Context()),
+		}
+		if p := principalFrom(r.Context()); p != nil {
+			actor.Subject, actor.Role = p.Subject, p.Role
+		}
+		next.ServeHTTP(w, r.WithContext(audit.WithActor(r.Context(), actor)))
+	})
+}
+
+// isAdminKey reports whether key is one of ADMIN_API_KEYS.
+func isAdminKey(cfg *config.Config, key string) bool {
+	for _, adminKey := range cfg.Server.AdminAPIKeys {
+		if adminKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(adminKey)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// callerFingerprint identifies the caller of r: by its principal once authenticated, otherwise
+// by the fingerprint of the API key it sent, or "" without one.
+func callerFingerprint(r *http.Request) string {
+	if p := principalFrom(r.Context()); p != nil {
+		return p.Fingerprint
+	}
+	return authz.Fingerprint(apiKeyFromRequest(r))
+}
+
+func apiKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// RateLimit caps how often each caller may call a route: per API key, by its fingerprint, or per
+// client IP without one. Callers over their quota get a 429 with a Retry-After. When the limiter
+// fails, e.g. Redis is down, requests go through.
+func RateLimit(l limiter.RateLimiter, quotas *rateQuotas) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if quotas == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			caller := "ip:" + clientIP(r)
+			fingerprint := callerFingerprint(r)
+			if fingerprint != "" {
+				caller = "key:" + fingerprint
+			}
+			key := r.Method + " " + chi.RouteContext(r.Context()).RoutePattern() + " " + caller
+
+			ok, retryAfter, err := l.Allow(r.Context(), key, quotas.forKey(fingerprint))
+			if err != nil {
+				log.Ctx(r.Context()).Error().Err(err).Msg("Rate limit check failed, letting the request through")
+				ok = true
+			}
+			if !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				WriteError(w, apperrors.ErrRateLimited, http.StatusTooManyRequests, "Rate limit exceeded, retry later", models.StatusResourceExhausted)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateQuotas are the RATE_LIMIT_* quotas, by API key fingerprint.
+type rateQuotas struct {
+	defaults  limiter.Quota
+	overrides map[string]limiter.Quota
+}
+
+// newRateQuotas reads the RATE_LIMIT_* config, returning nil when rate limiting is off.
+func newRateQuotas(cfg *config.Config) *rateQuotas {
+	quotas := &rateQuotas{
+		defaults:  limiter.Quota{PerMinute: cfg.Server.RateLimitPerMinute, Burst: cfg.Server.RateLimitBurst},
+		overrides: map[string]limiter.Quota{},
+	}
+	for fingerprint, value := range cfg.Server.RateLimitOverrides {
+		perMinute, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || perMinute < 0 {
+			log.Warn().Str("fingerprint", fingerprint).Str("value", value).Msg("Ignoring invalid rate limit override")
+			continue
+		}
+		quota := quotas.defaults
+		quota.PerMinute = perMinute
+		quotas.overrides[fingerprint] = quota
+	}
+
+	if quotas.defaults.PerMinute <= 0 && len(quotas.overrides) == 0 {
+		return nil
+	}
+	return quotas
+}
+
+func (q *rateQuotas) forKey(fingerprint string) limiter.Quota {
+	if quota, ok := q.overrides[fingerprint]; ok {
+		return quota
+	}
+	return q.defaults
+}
+
+// LimitConcurrency sheds requests with a 503 once the adaptive limit is reached, so a slow
+// database makes some requests fail fast rather than all of them slow.
+func LimitConcurrency(l *limiter.Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			release, ok := l.Acquire()
+			if !ok {
+				w.Header().Set("Retry-After", "1")
+				WriteErrorResponse(w, http.StatusServiceUnavailable, "Server is overloaded, retry later", models.StatusUnavailable)
+				return
+			}
+			defer release()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// UseLane assigns requests to a traffic lane, picking the connection pool their queries run on.
+// With a queue, requests also wait for one of the lane's slots and get a 503 once its wait list
+// is full or they waited too long.
+func UseLane(lane db.Lane, queue *limiter.Queue) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if queue != nil {
+				release, ok := queue.Acquire(r.Context())
+				if !ok {
+					w.Header().Set("Retry-After", "1")
+					WriteErrorResponse(w, http.StatusServiceUnavailable, "Too many "+lane.String()+" requests, retry later", models.StatusUnavailable)
+					return
+				}
+				defer release()
+			}
+			next.ServeHTTP(w, r.WithContext(db.WithLane(r.Context(), lane)))
+		})
+	}
+}
+
+// laneQueue returns the queue for a lane limited to limit concurrent requests, or nil when the
+// lane is unlimited.
+func laneQueue(cfg *config.Config, limit, queueLength int) *limiter.Queue {
+	if limit <= 0 {
+		return nil
+	}
+	return limiter.NewQueue(limit, queueLength, cfg.Server.LaneQueueTimeout)
+}
+
+// Authorize asks the external authorizer whether the caller may perform the matched route on the
+// project it targets. When the authorizer can't be reached the request is denied, unless
+// failOpen is set, and so are requests whose body can't be read for the projects it names.
+func Authorize(authorizer authz.Authorizer, failOpen bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// The policy is asked about every project the request names, as the handler may act
+			// on any of them.
+			projects, complete := requestProjects(r)
+			if !complete {
+				WriteErrorResponse(w, http.StatusBadRequest, "Request body must be a single JSON value of at most 1 MiB", models.StatusInvalidArgument)
+				return
+			}
+			if len(projects) == 0 {
+				projects = []string{""}
+			}
+			for _, project := range projects {
+				input := authz.Input{
+					KeyFingerprint: callerFingerprint(r),
+					Project:        project,
+					Action:         r.Method + " " + chi.RouteContext(r.Context()).RoutePattern(),
+					Domain:         r.Host,
+					Method:         r.Method,
+					Path:           r.URL.Path,
+				}
+				if p := principalFrom(r.Context()); p != nil {
+					input.Subject = p.Subject
+					input.Role = p.Role
+				}
+
+				allowed, err := authorizer.Authorize(r.Context(), input)
+				if err != nil {
+					log.Ctx(r.Context()).Error().Err(err).Str("action", input.Action).Msg("Authorization check failed")
+					if !failOpen {
+						WriteErrorResponse(w, http.StatusServiceUnavailable, "Authorization service unavailable", models.StatusUnavailable)
+						return
+					}
+					allowed = true
+				}
+				if !allowed {
+					WriteErrorResponse(w, http.StatusForbidden, "Not allowed by policy", models.StatusPermissionDenied)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireCaptcha lets requests to the given paths through once verifier accepts the solution
+// sent in the X-Captcha-Token header. Other paths pass untouched.
+func RequireCaptcha(verifier captcha.Verifier, paths []string) func(http.Handler) http.Handler {
+	protected := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		protected[path] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions || !protected[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			err := verifier.Verify(r.Context(), r.Header.Get("X-Captcha-Token"), clientIP(r))
+			switch {
+			case errors.Is(err, captcha.ErrFailed):
+				WriteError(w, err, http.StatusForbidden, "CAPTCHA verification failed", models.StatusPermissionDenied)
+			case err != nil:
+				log.Ctx(r.Context()).Error().Err(err).Msg("CAPTCHA verification unavailable")
+				WriteError(w, err, http.StatusServiceUnavailable, "CAPTCHA verification unavailable", models.StatusUnavailable)
+			default:
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// clientIP returns the caller's address without the port. Behind TRUSTED_PROXIES it relies on
+// the clientip middleware having put the forwarded address in RemoteAddr.
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// maxRequestBody bounds the JSON request bodies of the API. Handlers reading a whole body limit
+// it with http.MaxBytesReader and answer 413 past it.
+const maxRequestBody = 1 << 20
+
+// rowScopedRoutes take files whose rows each name their host, which the import services check
+// against the caller's projects row by row, so their bodies aren't read up front.
+var rowScopedRoutes = map[string]bool{
+	"/import/firebase":   true,
+	"/shortLinks/import": true,
+}
+
+// requestProjects finds the short link hosts a request works on: the host query param, the
+// domain URL param and every host in its JSON body, including those of batched requests. A
+// handler may act on any of them, so a caller has to be allowed all of them. complete is false
+// when the body isn't a single JSON value of at most maxRequestBody, whatever its Content-Type,
+// as handlers decode it regardless: the hosts in it are then unknown.
+//
+// The body is decoded once and the handler gets that decoded body, re-encoded, instead of the
+// bytes sent, so nothing past the first value reaches it. As decoders match
+// keys case-insensitively and pick among duplicates by their order, every key that any of them
+// could read a host from counts.
+func requestProjects(r *http.Request) (projects []string, complete bool) {
+	add := func(host string) {
+		if host == "" {
+			return
+		}
+		if cleaned, err := utils.CleanHost(host); err == nil {
+			host = cleaned
+		}
+		host = strings.ToLower(host)
+		if !slices.Contains(projects, host) {
+			projects = append(projects, host)
+		}
+	}
+	add(r.URL.Query().Get("host"))
+	add(chi.URLParam(r, "domain"))
+
+	if r.Body == nil || r.Body == http.NoBody || rowScopedRoutes[chi.RouteContext(r.Context()).RoutePattern()] {
+		return projects, true
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBody+1))
+	r.Body.Close()
+	if err != nil || len(body) > maxRequestBody {
+		// The handler's own limit rejects it.
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		return projects, false
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		r.Body = io.NopCloser(bytes.NewReader(nil))
+		return projects, true
+	}
+
+	value, err := decodeSingleJSON(body)
+	if err != nil {
+		// The handler rejects it the same way.
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		return projects, false
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		return projects, false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(canonical))
+
+	object, _ := value.(map[string]any)
+	addBodyProjects(object, add)
+	for key, requests := range object {
+		if !strings.EqualFold(key, "requests") {
+			continue
+		}
+		items, _ := requests.([]any)
+		for _, item := range items {
+			request, _ := item.(map[string]any)
+			addBodyProjects(request, add)
+		}
+	}
+	return projects, true
+}
+
+// decodeSingleJSON decodes body, which must hold a single JSON value.
+func decodeSingleJSON(body []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	// Numbers are kept as sent, as the handler may read them into integers.
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("more than one JSON value")
+	}
+	return value, nil
+}
+
+// addBodyProjects adds the hosts of a link request object: its host, the host of its
+// durableLinkInfo and the one of its longDurableLink, under keys of any case.
+func addBodyProjects(object map[string]any, add func(string)) {
+	for key, value := range object {
+		switch {
+		case strings.EqualFold(key, "host"):
+			host, _ := value.(string)
+			add(host)
+		case strings.EqualFold(key, "longDurableLink"):
+			if longLink, _ := value.(string); longLink != "" {
+				if u, err := url.Parse(longLink); err == nil {
+					add(u.Host)
+				}
+			}
+		case strings.EqualFold(key, "durableLinkInfo"):
+			info, _ := value.(map[string]any)
+			for key, value := range info {
+				if strings.EqualFold(key, "host") {
+					host, _ := value.(string)
+					add(host)
+				}
+			}
+		}
+	}
+}
+
+// mayAccessProjects reports whether p may work on every one of projects. Requests naming no
+// project work across all of them.
+func mayAccessProjects(p *principal, projects []string) bool {
+	if len(projects) == 0 {
+		return p.mayAccess("")
+	}
+	for _, project := range projects {
+		if !p.mayAccess(project) {
+			return false
+		}
+	}
+	return true
+}
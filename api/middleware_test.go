@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"durable-links-generator/api/authz"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+	os.Exit(m.Run())
+}
+
+func TestRequestProjects(t *testing.T) {
+	tests := []struct {
+		name         string
+		target       string
+		pattern      string
+		body         string
+		wantProjects []string
+		wantComplete bool
+		wantBody     string
+	}{
+		{
+			name:         "no body",
+			target:       "/shortLinks?host=Acme.link",
+			wantProjects: []string{"acme.link"},
+			wantComplete: true,
+		},
+		{
+			name:         "keys differing in case",
+			body:         `{"durableLinkInfo":{"host":"victim.com","link":"https://example.com"},"DurableLinkInfo":{"host":"allowed.com"}}`,
+			wantProjects: []string{"allowed.com", "victim.com"},
+			wantComplete: true,
+		},
+		{
+			name:         "duplicate keys",
+			body:         `{"host":"victim.com","host":"allowed.com"}`,
+			wantProjects: []string{"allowed.com"},
+			wantComplete: true,
+			wantBody:     `{"host":"allowed.com"}`,
+		},
+		{
+			name:         "long links and batches",
+			body:         `{"LongDurableLink":"https://a.link/?link=x","Requests":[{"longDurableLink":"https://b.link/?link=x"},{"durableLinkInfo":{"HOST":"c.link"}}]}`,
+			wantProjects: []string{"a.link", "b.link", "c.link"},
+			wantComplete: true,
+		},
+		{
+			name:         "numbers kept",
+			body:         ` {"host": "a.link", "ttlSeconds": 9007199254740993} `,
+			wantProjects: []string{"a.link"},
+			wantComplete: true,
+			wantBody:     `{"host":"a.link","ttlSeconds":9007199254740993}`,
+		},
+		{
+			name:         "more than one value",
+			target:       "/shortLinks?host=a.link",
+			body:         `{"host":"a.link"} {"host":"b.link"}`,
+			wantProjects: []string{"a.link"},
+			wantBody:     `{"host":"a.link"} {"host":"b.link"}`,
+		},
+		{
+			name:         "too large",
+			body:         `{"host":"a.link"` + strings.Repeat(" ", maxRequestBody) + `}`,
+			wantProjects: nil,
+			wantBody:     (`{"host":"a.link"` + strings.Repeat(" ", maxRequestBody))[:maxRequestBody+1],
+		},
+		{
+			name:         "not JSON",
+			body:         "host,link\n",
+			wantProjects: nil,
+			wantBody:     "host,link\n",
+		},
+		{
+			name:         "import rows are checked by the service",
+			target:       "/shortLinks/import?host=a.link",
+			pattern:      "/shortLinks/import",
+			body:         "Path,Long Durable Link\nx,https://b.link/?link=x\n",
+			wantProjects: []string{"a.link"},
+			wantComplete: true,
+			wantBody:     "Path,Long Durable Link\nx,https://b.link/?link=x\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := tt.target
+			if target == "" {
+				target = "/shortLinks"
+			}
+			var body io.Reader = http.NoBody
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			r := httptest.NewRequest(http.MethodPost, target, body)
+			if tt.pattern != "" {
+				rctx := chi.NewRouteContext()
+				rctx.RoutePatterns = []string{tt.pattern}
+				r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+			}
+
+			projects, complete := requestProjects(r)
+			assert.ElementsMatch(t, tt.wantProjects, projects)
+			assert.Equal(t, tt.wantComplete, complete)
+			if tt.wantBody != "" || !tt.wantComplete {
+				handed, _ := io.ReadAll(r.Body)
+				assert.Equal(t, tt.wantBody, string(handed))
+			}
+		})
+	}
+}
+
+// fakeAuthorizer allows what allow says, recording what it was asked.
+type fakeAuthorizer struct {
+	allow  func(authz.Input) bool
+	inputs []authz.Input
+}
+
+func (f *fakeAuthorizer) Authorize(_ context.Context, input authz.Input) (bool, error) {
+	f.inputs = append(f.inputs, input)
+	return f.allow(input), nil
+}
+
+func TestAuthorize(t *testing.T) {
+	authorizer := &fakeAuthorizer{allow: func(input authz.Input) bool { return input.Project != "victim.link" }}
+	var handed string
+	handler := Authorize(authorizer, false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		handed = string(body)
+	}))
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{name: "allowed", body: `{"host":"allowed.link"}`, wantCode: http.StatusOK},
+		{name: "denied", body: `{"Host":"victim.link"}`, wantCode: http.StatusForbidden},
+		{name: "denied in batch", body: `{"requests":[{"host":"allowed.link"},{"durableLinkInfo":{"host":"victim.link"}}]}`, wantCode: http.StatusForbidden},
+		{name: "not JSON", body: "host,link\n", wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handed = ""
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/shortLinks", strings.NewReader(tt.body)))
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, tt.body, handed)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package models
+
+type CreateAliasRequest struct {
+	Host  string `json:"host"`
+	Alias string `json:"alias"`
+}
+
+type AliasesResponse struct {
+	Path    string   `json:"path"`
+	Aliases []string `json:"aliases"`
+}
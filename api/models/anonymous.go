@@ -0,0 +1,7 @@
+package models
+
+// AnonymousLinkRequest is the body of the anonymous shortener endpoint. Only the destination can
+// be chosen; everything else uses the deployment's defaults.
+type AnonymousLinkRequest struct {
+	Link string `json:"link"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// AppSiteAssociation is the apple-app-site-association document that lets iOS apps open a
+// domain's short links as universal links.
+type AppSiteAssociation struct {
+	AppLinks AppLinks `json:"applinks"`
+}
+
+type AppLinks struct {
+	// Apps must be present and empty for iOS versions before 13.
+	Apps    []string        `json:"apps"`
+	Details []AppLinkDetail `json:"details"`
+}
+
+type AppLinkDetail struct {
+	AppIDs     []string           `json:"appIDs"`
+	Components []AppLinkComponent `json:"components"`
+}
+
+// AppLinkComponent matches URL paths; the first component matching a path decides.
+type AppLinkComponent struct {
+	Path    string `json:"/"`
+	Exclude bool   `json:"exclude,omitempty"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// AssetStatement is one statement of a Digital Asset Links assetlinks.json document.
+type AssetStatement struct {
+	Relation []string    `json:"relation"`
+	Target   AssetTarget `json:"target"`
+}
+
+type AssetTarget struct {
+	Namespace              string   `json:"namespace"`
+	PackageName            string   `json:"package_name"`
+	SHA256CertFingerprints []string `json:"sha256_cert_fingerprints"`
+}
+
+// AndroidAppFingerprint allows the app signed with the certificate to open a host's links.
+type AndroidAppFingerprint struct {
+	PackageName           string    `json:"packageName"`
+	SHA256CertFingerprint string    `json:"sha256CertFingerprint"`
+	CreatedAt             time.Time `json:"createdAt"`
+}
+
+type AndroidAppFingerprintsResponse struct {
+	Fingerprints []AndroidAppFingerprint `json:"fingerprints"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Actions of audit log entries.
+const (
+	AuditLinkCreate          = "link.create"
+	AuditLinkUpdate          = "link.update"
+	AuditLinkDelete          = "link.delete"
+	AuditLinkRestore         = "link.restore"
+	AuditLinkUnarchive       = "link.unarchive"
+	AuditLinkMerge           = "link.merge"
+	AuditLinkSupersede       = "link.supersede"
+	AuditLinkGeoDestinations = "link.geoDestinations.set"
+	AuditLinkDeviceRules     = "link.deviceRules.set"
+	AuditLinkVariants        = "link.variants.set"
+	AuditAliasCreate         = "alias.create"
+	AuditAliasDelete         = "alias.delete"
+	AuditDomainCreate        = "domain.create"
+	AuditDomainUpdate        = "domain.update"
+	AuditDomainDelete        = "domain.delete"
+)
+
+// AuditEntry records a change of a link or domain config, listed by GET /auditlog. Before and
+// After are snapshots of what changed, null when it didn't exist: the link (a LinkSnapshot), its
+// geo destinations, device rules or variants, an alias, or the domain config.
+type AuditEntry struct {
+	ID   int64     `json:"id"`
+	Time time.Time `json:"time"`
+	// Actor is the fingerprint of the API key or token subject that made the change, empty for
+	// anonymous callers and background jobs.
+	Actor     string `json:"actor,omitempty"`
+	Subject   string `json:"subject,omitempty"`
+	Role      string `json:"role,omitempty"`
+	ClientIP  string `json:"clientIp,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+	Action    string `json:"action"`
+	Host      string `json:"host"`
+	// Resource is the path of the link, or the host of the domain config.
+	Resource string `json:"resource"`
+	// Reason is why a link's destination changed, as in its revisions, e.g. "rewrite".
+	Reason string          `json:"reason,omitempty"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// LinkSnapshot is a link as stored, the Before and After of link audit entries.
+type LinkSnapshot struct {
+	Path         string       `json:"path"`
+	QueryParams  string       `json:"queryParams"`
+	Unguessable  bool         `json:"unguessable"`
+	Metadata     LinkMetadata `json:"metadata"`
+	SupersededBy string       `json:"supersededBy,omitempty"`
+	Archived     bool         `json:"archived,omitempty"`
+	Deleted      bool         `json:"deleted,omitempty"`
+}
+
+// AuditFilter selects audit log entries; zero fields match everything.
+type AuditFilter struct {
+	Host     string
+	Resource string
+	Action   string
+	// Actor matches the fingerprint or the token subject of who made the change.
+	Actor string
+	From  time.Time
+	To    time.Time
+	// BeforeID only matches entries older than the one with this ID, for paging.
+	BeforeID int64
+	Limit    int
+}
+
+type AuditLogRequest struct {
+	Host      string
+	Resource  string
+	Action    string
+	Actor     string
+	From      time.Time
+	To        time.Time
+	PageSize  int
+	PageToken string
+}
+
+type AuditLogResponse struct {
+	Entries       []AuditEntry `json:"entries"`
+	NextPageToken string       `json:"nextPageToken,omitempty"`
+}
@@ -0,0 +1,18 @@
+package models
+
+// BatchCreateLinksRequest is the body of POST /shortLinks:batch. Every request takes the same form
+// as a POST /shortLinks body.
+type BatchCreateLinksRequest struct {
+	Requests []map[string]any `json:"requests"`
+}
+
+type BatchCreateLinksResponse struct {
+	Results []BatchLinkResult `json:"results"`
+}
+
+// BatchLinkResult is the outcome of one request of a batch, in request order: the link as POST
+// /shortLinks returns it, or the error it was rejected with.
+type BatchLinkResult struct {
+	*ShortLinkResponse
+	Error *ErrorDetails `json:"error,omitempty"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// CacheStatsResponse is the body of GET /v1/admin/cacheStats. Backend is "redis", "memory" or
+// "none" when no link cache is configured.
+type CacheStatsResponse struct {
+	Backend   string  `json:"backend"`
+	Hits      int64   `json:"hits"`
+	Misses    int64   `json:"misses"`
+	HitRate   float64 `json:"hitRate"`
+	Entries   int     `json:"entries,omitempty"`
+	Evictions int64   `json:"evictions,omitempty"`
+}
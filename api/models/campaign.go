@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// Campaign groups short links of a domain, e.g. those shared for one UTM campaign, so their
+// clicks are reported together. It is managed through /v1/domains/{domain}/campaigns.
+type Campaign struct {
+	// ID is chosen on creation, e.g. the utm_campaign of its links.
+	ID   string `json:"id"`
+	Host string `json:"host"`
+	Name string `json:"name"`
+	// LinkCount is how many links are in the campaign.
+	LinkCount int64     `json:"linkCount"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type CampaignListResponse struct {
+	Campaigns []Campaign `json:"campaigns"`
+}
+
+// CampaignLinksRequest is the body of POST /v1/domains/{domain}/campaigns/{id}/links. Links in
+// another campaign are moved to this one.
+type CampaignLinksRequest struct {
+	Paths []string `json:"paths"`
+}
+
+// CampaignLink is a link of a campaign. AddedAt is when it joined the campaign.
+type CampaignLink struct {
+	Path      string    `json:"path"`
+	ShortLink string    `json:"shortLink"`
+	AddedAt   time.Time `json:"addedAt"`
+}
+
+type CampaignLinkListResponse struct {
+	Links []CampaignLink `json:"links"`
+}
+
+// LinkClicks counts the clicks on one link of a campaign, aliases included.
+type LinkClicks struct {
+	Path         string `json:"path"`
+	ShortLink    string `json:"shortLink"`
+	Clicks       int64  `json:"clicks"`
+	UniqueClicks int64  `json:"uniqueClicks"`
+}
+
+// CampaignStatsResponse is the body of GET /v1/domains/{domain}/campaigns/{id}/stats: the
+// clicks on all links of the campaign, as GET /shortLinks/{path}/stats counts them for one, and
+// the clicks per link, most clicked first.
+type CampaignStatsResponse struct {
+	Campaign        string           `json:"campaign"`
+	From            time.Time        `json:"from"`
+	To              time.Time        `json:"to"`
+	Granularity     string           `json:"granularity"`
+	Clicks          int64            `json:"clicks"`
+	UniqueClicks    int64            `json:"uniqueClicks"`
+	ConsentedClicks int64            `json:"consentedClicks"`
+	Buckets         []ClickBucket    `json:"buckets"`
+	Platforms       []PlatformClicks `json:"platforms"`
+	TopReferrers    []ReferrerClicks `json:"topReferrers"`
+	Links           []LinkClicks     `json:"links"`
+}
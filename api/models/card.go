@@ -0,0 +1,8 @@
+package models
+
+// LinkCard is the text of a link's social card image.
+type LinkCard struct {
+	Title       string
+	Description string
+	Domain      string
+}
@@ -0,0 +1,112 @@
+package models
+
+import "time"
+
+// ClickContext carries what is known about the visitor when a short link is opened.
+type ClickContext struct {
+	// TCString is the IAB TCF consent string sent with the click, if any.
+	TCString string
+	// ConsentValue is the value of the configured consent cookie or query param, if any.
+	ConsentValue string
+	UserAgent    string
+	// PlatformHint and ModelHint are the Sec-CH-UA-Platform and Sec-CH-UA-Model client hints.
+	PlatformHint string
+	ModelHint    string
+	// Head is set for HEAD requests, which probe a link rather than follow it.
+	Head bool
+	// IP and AcceptLanguage fingerprint the click for install attribution.
+	IP             string
+	AcceptLanguage string
+	Referrer       string
+	// VisitorID is the value of the visitor cookie that keeps A/B test visitors on one variant,
+	// if any.
+	VisitorID string
+	// Acknowledged are the interstitial gates the visitor has passed. Resolving a link behind
+	// another gate only shows the interstitial, which isn't a click.
+	Acknowledged []string
+}
+
+// RedirectTarget is where a click should be sent, and what the visitor must see first.
+type RedirectTarget struct {
+	Destination string
+	// Gate is the interstitial the visitor has to acknowledge before redirecting, if any.
+	Gate string
+	// ScannerPreview asks for a plain 200 preview instead of a redirect, served to mail security
+	// scanners and unknown clients on domains that opted in.
+	ScannerPreview bool
+	// SocialPreview asks for a page of OpenGraph and Twitter Card tags built from Preview instead
+	// of a redirect, served to the crawlers of social networks and chat apps.
+	SocialPreview bool
+	// QRCode asks for a page showing this short link as a QR code instead of a redirect, for
+	// devices the visitor is better off continuing on a phone from.
+	QRCode string
+	// Preview is what the QR code and social preview pages show of the destination.
+	Preview SocialMetaTagInfo
+	// DesktopApp asks for the desktop app page instead of a redirect, if set.
+	DesktopApp *DesktopApp
+	// AppPreview asks for the preview page of a preview host instead of a redirect, if set.
+	AppPreview *AppPreview
+	// Variant is the A/B test variant the visitor was sent to, if the link has variants.
+	Variant string
+	// VisitorID is to be set as the visitor cookie, keeping the visitor on Variant when their
+	// address or browser changes. It is empty when the click sent one or didn't consent.
+	VisitorID string
+}
+
+// AppPreview is what the preview page shows before the visitor continues to the short link on its
+// main host, where the app can claim it.
+type AppPreview struct {
+	Host      string
+	ShortLink string
+	// PlayStoreLink and AppStoreLink are the app's store pages, if the link names the app.
+	PlayStoreLink      string
+	AndroidPackageName string
+	AppStoreLink       string
+}
+
+// DesktopApp is the desktop client a visitor on Windows or macOS is offered.
+type DesktopApp struct {
+	ProtocolLink string
+	// StoreLink is the Microsoft Store or Mac App Store page for the visitor's OS, if any.
+	StoreLink string
+	StoreName string
+}
+
+// LinkInfo describes where a short link leads, for the public link info page.
+type LinkInfo struct {
+	ShortLink         string
+	Destination       string
+	DestinationDomain string
+	CreatedAt         time.Time
+}
+
+// InstallAttributionRequest is what a freshly installed app knows about its device on first
+// launch. Platform is "android" or "ios".
+type InstallAttributionRequest struct {
+	Platform  string `json:"platform"`
+	OSVersion string `json:"osVersion,omitempty"`
+	Locale    string `json:"locale,omitempty"`
+	// IP is filled in from the request.
+	IP string `json:"-"`
+}
+
+// InstallAttributionResponse is the link clicked before the install, if one matched.
+type InstallAttributionResponse struct {
+	// MatchType is "NONE", "WEAK" for a guess among several candidates or with nothing but the
+	// network in common, or "DEFAULT" for the only candidate matching more than the network.
+	MatchType string `json:"matchType"`
+	DeepLink  string `json:"deepLink,omitempty"`
+	ShortLink string `json:"shortLink,omitempty"`
+	// Variant is the A/B test variant of the link the click was sent to, for the app to report
+	// conversions by variant.
+	Variant string `json:"variant,omitempty"`
+}
+
+// ClickEventFilter narrows the click events of a host. Zero fields don't filter; From is
+// inclusive and To exclusive.
+type ClickEventFilter struct {
+	Path  string
+	From  time.Time
+	To    time.Time
+	Limit int
+}
@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// DashboardOverview counts the links and clicks of all hosts. Links counts every stored link:
+// the active ones, the archived ones and those deleted but not purged yet.
+type DashboardOverview struct {
+	Links         int64 `json:"links"`
+	ActiveLinks   int64 `json:"activeLinks"`
+	ArchivedLinks int64 `json:"archivedLinks"`
+	DeletedLinks  int64 `json:"deletedLinks"`
+	Hosts         int64 `json:"hosts"`
+	// Clicks is the total of the click counters of all links, aliases' clicks included.
+	Clicks int64 `json:"clicks"`
+}
+
+// DailyCount is a count for one UTC day, written YYYY-MM-DD.
+type DailyCount struct {
+	Day   string `json:"day"`
+	Count int64  `json:"count"`
+}
+
+// LinksCreatedResponse is the body of GET /admin/api/linksCreated: the links created on each UTC
+// day from From to To, both inclusive. Days without new links are left out.
+type LinksCreatedResponse struct {
+	From  string       `json:"from"`
+	To    string       `json:"to"`
+	Total int64        `json:"total"`
+	Days  []DailyCount `json:"days"`
+}
+
+// DestinationCount counts the links leading to one destination domain, by the host of their
+// "link" param, and their clicks.
+type DestinationCount struct {
+	Domain string `json:"domain"`
+	Links  int64  `json:"links"`
+	Clicks int64  `json:"clicks"`
+}
+
+type TopDestinationsResponse struct {
+	Destinations []DestinationCount `json:"destinations"`
+}
+
+// HostCount counts the links of one host and their clicks.
+type HostCount struct {
+	Host   string `json:"host"`
+	Links  int64  `json:"links"`
+	Clicks int64  `json:"clicks"`
+}
+
+type TopHostsResponse struct {
+	Hosts []HostCount `json:"hosts"`
+}
+
+// TableSize is the size of one table. Bytes is what the database reports the table and its
+// indexes take, and is nil on SQLite, which doesn't tell.
+type TableSize struct {
+	Table string `json:"table"`
+	Rows  int64  `json:"rows"`
+	Bytes *int64 `json:"bytes,omitempty"`
+}
+
+// StorageDay counts the rows added to the tables that grow with traffic on one UTC day.
+type StorageDay struct {
+	Day         string `json:"day"`
+	Links       int64  `json:"links"`
+	Revisions   int64  `json:"revisions"`
+	ClickEvents int64  `json:"clickEvents"`
+}
+
+// StorageResponse is the body of GET /admin/api/storage: the size of the tables now, and the rows
+// added per UTC day since From. Days without new rows are left out.
+type StorageResponse struct {
+	Tables []TableSize  `json:"tables"`
+	From   string       `json:"from"`
+	Growth []StorageDay `json:"growth"`
+}
+
+// DashboardOverviewResponse is the body of GET /admin/api/overview.
+type DashboardOverviewResponse struct {
+	DashboardOverview
+	GeneratedAt time.Time `json:"generatedAt"`
+}
@@ -0,0 +1,14 @@
+package models
+
+// DeviceRule matches visitors on OS, "android", "ios", "ipados", "windows", "macos", "linux" or
+// "chromeos", optionally only below or from some version of it, e.g. "10" or "16.4". "ios"
+// matches iPads too, "ipados" only them; versions are only known on Android and iOS. A matching
+// visitor goes to Link, or to Param, the destination of the link's own param such as "ipfl" or
+// "ofl".
+type DeviceRule struct {
+	OS             string `json:"os"`
+	VersionBelow   string `json:"versionBelow,omitempty"`
+	VersionAtLeast string `json:"versionAtLeast,omitempty"`
+	Link           string `json:"link,omitempty"`
+	Param          string `json:"param,omitempty"`
+}
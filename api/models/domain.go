@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// DomainVerification is the DNS verification state of a short link domain.
+type DomainVerification struct {
+	Domain    string    `json:"domain"`
+	Record    string    `json:"record"`
+	Verified  bool      `json:"verified"`
+	CheckedAt time.Time `json:"checkedAt"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type DomainVerificationListResponse struct {
+	Domains []DomainVerification `json:"domains"`
+}
+
+// DomainConfig is the link settings of a short link domain, managed through /v1/domains. Hosts
+// without one use the ALLOWED_DOMAINS, DEFAULT_ANDROID_PACKAGE_NAME and DEFAULT_IOS_STORE_ID
+// settings.
+type DomainConfig struct {
+	Host                      string    `json:"host"`
+	AllowedDomains            []string  `json:"allowedDomains"`
+	DefaultAndroidPackageName string    `json:"defaultAndroidPackageName,omitempty"`
+	DefaultIosStoreId         string    `json:"defaultIosStoreId,omitempty"`
+	CreatedAt                 time.Time `json:"createdAt"`
+	UpdatedAt                 time.Time `json:"updatedAt"`
+}
+
+type DomainConfigListResponse struct {
+	Domains []DomainConfig `json:"domains"`
+}
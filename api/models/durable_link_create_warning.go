@@ -1,7 +1,18 @@
 package models
 
+// Severities of link creation warnings.
+const (
+	WarningSeverityInfo    = "INFO"
+	WarningSeverityWarning = "WARNING"
+	// WarningSeverityError marks warnings promoted to errors by WARNINGS_AS_ERRORS, which reject
+	// the request rather than being returned, and destinations flagged by the URL reputation check
+	// under URL_REPUTATION_POLICY=warn.
+	WarningSeverityError = "ERROR"
+)
+
 // Represents a warning when creating a durable link. For ex. you pass ItunesConnectAnalytics paramters but don't pass an iOS app store id.
 type DurableLinkCreationWarning struct {
 	WarningCode    string `json:"warningCode"`
 	WarningMessage string `json:"warningMessage"`
+	Severity       string `json:"severity,omitempty"`
 }
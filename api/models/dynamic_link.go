@@ -1,19 +1,65 @@
 package models
 
+import "time"
+
 type DurableLinkInfo struct {
 	Host                    string                  `json:"host"`
 	Link                    string                  `json:"link"`
 	AndroidParameters       AndroidParameters       `json:"androidParameters,omitempty"`
 	IosParameters           IosParameters           `json:"iosParameters,omitempty"`
 	OtherPlatformParameters OtherPlatformParameters `json:"otherPlatformParameters,omitempty"`
+	DesktopParameters       DesktopParameters       `json:"desktopParameters,omitempty"`
 	AnalyticsInfo           AnalyticsInfo           `json:"analyticsInfo,omitempty"`
 	SocialMetaTagInfo       SocialMetaTagInfo       `json:"socialMetaTagInfo,omitempty"`
+	// Gate requires visitors to acknowledge an interstitial before being redirected: "age" or "terms".
+	Gate string `json:"gate,omitempty"`
+	// DeepLink describes the in-app destination as a route and params. Without a link the web
+	// destination is composed from it.
+	DeepLink *DeepLink `json:"deepLink,omitempty"`
+	// PlatformLinks overrides the destination per platform. Unlike the fallback links these are
+	// where the link leads, not where it leads when the app isn't installed.
+	PlatformLinks PlatformLinks `json:"platformLinks,omitempty"`
+	// GeoDestinations overrides link by the visitor's country on redirects. Platform links still
+	// take precedence on their platform.
+	GeoDestinations []GeoDestination `json:"geoDestinations,omitempty"`
+	// DeviceRules send visitors on some OS, or some versions of it, elsewhere on redirects. The
+	// first matching rule wins over the platform and fallback links.
+	DeviceRules []DeviceRule `json:"deviceRules,omitempty"`
+	// Variants split visitors between weighted destinations replacing link, for A/B tests. Geo
+	// destinations take precedence, and visitors they send elsewhere aren't in the test.
+	Variants []LinkVariant `json:"variants,omitempty"`
+	// QRFirst shows desktop visitors a QR code of the short link and a preview of the destination
+	// instead of redirecting, to continue on a phone where the app can open.
+	QRFirst bool `json:"qrFirst,omitempty"`
+	// MaxClicks expires the link once it was followed that many times; 0 means no limit. Only
+	// redirects count, not previews or exchanges by the app.
+	MaxClicks int64 `json:"maxClicks,omitempty"`
+	// ActiveFrom and ActiveUntil limit when the link redirects, e.g. to create it ahead of a
+	// launch. Visitors outside the window get the pre-launch or expired behavior of the server.
+	ActiveFrom  *time.Time `json:"activeFrom,omitempty"`
+	ActiveUntil *time.Time `json:"activeUntil,omitempty"`
+	// ExpiredLink is where visitors of an expired link are sent instead of the expired page.
+	ExpiredLink string `json:"expiredLink,omitempty"`
+}
+
+type PlatformLinks struct {
+	WebLink     string `json:"webLink,omitempty"`
+	AndroidLink string `json:"androidLink,omitempty"`
+	IosLink     string `json:"iosLink,omitempty"`
+}
+
+type DeepLink struct {
+	Route  string            `json:"route"`
+	Params map[string]string `json:"params,omitempty"`
 }
 
 type AndroidParameters struct {
 	AndroidPackageName           string `json:"androidPackageName,omitempty"`
 	AndroidFallbackLink          string `json:"androidFallbackLink,omitempty"`
 	AndroidMinPackageVersionCode string `json:"androidMinPackageVersionCode,omitempty"`
+	// AndroidAppGalleryLink replaces a Play Store destination for Huawei devices without Google
+	// Play, typically the app's AppGallery page.
+	AndroidAppGalleryLink string `json:"androidAppGalleryLink,omitempty"`
 }
 
 type IosParameters struct {
@@ -27,7 +73,19 @@ type OtherPlatformParameters struct {
 	FallbackURL string `json:"ofl,omitempty"`
 }
 
+// DesktopParameters open a desktop client from Windows and macOS browsers. Visitors get a page that
+// launches the protocol link and offers the store as the fallback.
+type DesktopParameters struct {
+	// DesktopProtocolLink uses the app's custom protocol, e.g. "myapp://open/42".
+	DesktopProtocolLink string `json:"desktopProtocolLink,omitempty"`
+	MicrosoftStoreLink  string `json:"microsoftStoreLink,omitempty"`
+	MacAppStoreLink     string `json:"macAppStoreLink,omitempty"`
+}
+
 type AnalyticsInfo struct {
+	// PresetName names a UTM preset of the link's host whose params are used for the
+	// marketingParameters left empty.
+	PresetName             string                 `json:"presetName,omitempty"`
 	MarketingParameters    MarketingParameters    `json:"marketingParameters,omitempty"`
 	ItunesConnectAnalytics ItunesConnectAnalytics `json:"itunesConnectAnalytics,omitempty"`
 }
@@ -55,5 +113,13 @@ type SocialMetaTagInfo struct {
 }
 
 type Suffix struct {
-	Option string `json:"option,omitempty"` // "SHORT" or "UNGUESSABLE"
+	Option string `json:"option,omitempty"` // "SHORT", "UNGUESSABLE" or "CUSTOM"
+	// CustomPath is the path of a CUSTOM link, e.g. "summer-sale".
+	CustomPath string `json:"customPath,omitempty"`
+	// NumericCode also assigns a numeric code resolving to the link through /c/{code}, for voice,
+	// SMS keywords and NFC tags. UNGUESSABLE links can't have one.
+	NumericCode bool `json:"numericCode,omitempty"`
+	// SMSSafe restricts the path to characters SMS gateways don't mangle and rejects the link when
+	// it would exceed SMS_LINK_MAX_LENGTH. The constraint is stored with the link as "sms=1".
+	SMSSafe bool `json:"smsSafe,omitempty"`
 }
@@ -5,7 +5,55 @@ type ErrorResponse struct {
 }
 
 type ErrorDetails struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Status  string `json:"status"`
+	Code      int         `json:"code"`
+	Message   string      `json:"message"`
+	Status    ErrorStatus `json:"status"`
+	Retryable bool        `json:"retryable"`
+	// Details holds an ErrorInfo whose reason tells the causes of errors with the same status
+	// apart.
+	Details []ErrorInfo `json:"details"`
+	// RequestID identifies the request in the server logs, for reporting the error.
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// ErrorInfoType is the type of ErrorInfo details.
+const ErrorInfoType = "type.googleapis.com/google.rpc.ErrorInfo"
+
+// ErrorInfo is the cause of an error, shaped like the google.rpc.ErrorInfo the Firebase API sent.
+// Reason is the code of the apperrors error behind it, see apperrors.Code, or the status for
+// failures without one. Like statuses, reasons are never renamed or given a new meaning.
+type ErrorInfo struct {
+	Type   string `json:"@type"`
+	Reason string `json:"reason"`
+	Domain string `json:"domain"`
+}
+
+// ErrorStatus is the machine readable status of an error response, named after the google.rpc
+// codes the Firebase API used. Client SDKs switch on it, so statuses are only ever added, never
+// renamed or given a new meaning.
+type ErrorStatus string
+
+const (
+	StatusInvalidArgument    ErrorStatus = "INVALID_ARGUMENT"
+	StatusFailedPrecondition ErrorStatus = "FAILED_PRECONDITION"
+	StatusNotFound           ErrorStatus = "NOT_FOUND"
+	StatusAlreadyExists      ErrorStatus = "ALREADY_EXISTS"
+	StatusAborted            ErrorStatus = "ABORTED"
+	StatusUnauthenticated    ErrorStatus = "UNAUTHENTICATED"
+	StatusPermissionDenied   ErrorStatus = "PERMISSION_DENIED"
+	StatusResourceExhausted  ErrorStatus = "RESOURCE_EXHAUSTED"
+	StatusUnavailable        ErrorStatus = "UNAVAILABLE"
+	StatusInternal           ErrorStatus = "INTERNAL"
+)
+
+// Retryable reports whether a request that failed with s may succeed when sent again unchanged,
+// after the Retry-After delay if the response has one. INTERNAL is not retryable: creation isn't
+// idempotent, and a retry could leave a second link behind.
+func (s ErrorStatus) Retryable() bool {
+	switch s {
+	case StatusResourceExhausted, StatusUnavailable, StatusAborted:
+		return true
+	default:
+		return false
+	}
 }
@@ -0,0 +1,11 @@
+package models
+
+type Fixture struct {
+	Name        string `json:"name"`
+	ShortLink   string `json:"shortLink"`
+	Destination string `json:"destination"`
+}
+
+type FixturesResponse struct {
+	Fixtures []Fixture `json:"fixtures"`
+}
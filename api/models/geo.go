@@ -0,0 +1,22 @@
+package models
+
+// GeoDestination sends visitors GeoIP places in Country, an ISO 3166-1 alpha-2 code, to Link
+// instead of the link's own destination.
+type GeoDestination struct {
+	Country string `json:"country"`
+	Link    string `json:"link"`
+}
+
+// SetGeoDestinationsRequest is the body of PUT /v1/links/{path}/geoDestinations. It replaces the
+// link's geo destinations; an empty list removes them.
+type SetGeoDestinationsRequest struct {
+	Host            string           `json:"host"`
+	GeoDestinations []GeoDestination `json:"geoDestinations"`
+}
+
+type GeoDestinationsResponse struct {
+	Path            string           `json:"path"`
+	GeoDestinations []GeoDestination `json:"geoDestinations"`
+	// Warnings are set by PUT, for destinations flagged by the URL reputation check.
+	Warnings []DurableLinkCreationWarning `json:"warnings,omitempty"`
+}
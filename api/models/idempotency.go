@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// IdempotentResponse is the response stored for a request sent with an Idempotency-Key, replayed
+// when the same caller sends the key again.
+type IdempotentResponse struct {
+	Caller string
+	Key    string
+	// RequestHash identifies the request the key was first sent with.
+	RequestHash string
+	// StatusCode is 0 while the first request is still running.
+	StatusCode int
+	Body       string
+	CreatedAt  time.Time
+}
@@ -0,0 +1,37 @@
+package models
+
+// FirebaseImportResponse is the outcome of POST /import/firebase. Rows whose path is already in
+// use are skipped rather than failed, so an export can be imported again after fixing the rows
+// that failed.
+type FirebaseImportResponse struct {
+	Imported int                 `json:"imported"`
+	Skipped  int                 `json:"skipped"`
+	Failed   int                 `json:"failed"`
+	Results  []FirebaseImportRow `json:"results"`
+}
+
+// FirebaseImportRow is the outcome of one row of the export, in file order: the link as POST
+// /shortLinks returns it, or the error it was rejected with.
+type FirebaseImportRow struct {
+	// Row is the row's line in the file, the header being line 1.
+	Row int `json:"row"`
+	// SourceLink is the Firebase short link of the row.
+	SourceLink string `json:"sourceLink"`
+	*ShortLinkResponse
+	Error *ErrorDetails `json:"error,omitempty"`
+}
+
+// LinkImportResponse is the outcome of POST /shortLinks/import. Only the rows that failed are
+// listed, so that the response of a large import stays small.
+type LinkImportResponse struct {
+	Imported int               `json:"imported"`
+	Failed   int               `json:"failed"`
+	Errors   []LinkImportError `json:"errors"`
+}
+
+// LinkImportError is a row of the import that failed, in file order.
+type LinkImportError struct {
+	// Row is the row's line in the file, the CSV header being line 1.
+	Row   int          `json:"row"`
+	Error ErrorDetails `json:"error"`
+}
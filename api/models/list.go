@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+type ListLinksRequest struct {
+	Host string
+	// Sort is a sort field, prefixed with "-" for descending order. Defaults to "-createdAt".
+	Sort      string
+	PageSize  int
+	PageToken string
+	// CreatedAfter and CreatedBefore bound the creation time, inclusive and exclusive. Zero
+	// values leave the range open.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// Suffix is "SHORT" or "UNGUESSABLE", or empty for both. CUSTOM links count as SHORT.
+	Suffix string
+	// DestinationDomain selects links pointing to this domain or one of its subdomains.
+	DestinationDomain string
+	// Tags selects links carrying all of these tags.
+	Tags []string
+}
+
+// LinkPage selects one page of a host's links in a stable order. The page starts after the link
+// with ID AfterID whose sort column has the value AfterValue, unless AfterID is 0.
+type LinkPage struct {
+	// Sort is a sort field such as "createdAt" or "clickCount".
+	Sort       string
+	Desc       bool
+	AfterValue string
+	AfterID    int64
+	Limit      int
+	Filter     LinkFilter
+}
+
+// LinkFilter narrows the links a LinkPage selects from. Zero values don't filter.
+type LinkFilter struct {
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	// Unguessable selects links by the kind of path they got, when set.
+	Unguessable *bool
+	// DestinationDomain is lowercase.
+	DestinationDomain string
+	// Tags are normalized like the tags of LinkMetadata.
+	Tags []string
+}
+
+type LinkSummary struct {
+	ShortLink     string     `json:"shortLink"`
+	Path          string     `json:"path"`
+	Link          string     `json:"link"`
+	Title         string     `json:"title,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	TotalClicks   int64      `json:"totalClicks"`
+	LastClickedAt *time.Time `json:"lastClickedAt,omitempty"`
+	LinkMetadata
+}
+
+type ListLinksResponse struct {
+	Links         []LinkSummary `json:"links"`
+	NextPageToken string        `json:"nextPageToken,omitempty"`
+}
+
+type StaleLinksResponse struct {
+	Host string `json:"host"`
+	// Days is how long the links have gone without a click.
+	Days  int           `json:"days"`
+	Links []LinkSummary `json:"links"`
+}
@@ -0,0 +1,19 @@
+package models
+
+type MergeLinksRequest struct {
+	Host string `json:"host"`
+	// Strategy picks the link that survives a merge: "oldest" (default) or "shortest" path.
+	Strategy string `json:"strategy,omitempty"`
+	DryRun   bool   `json:"dryRun"`
+}
+
+type MergeGroup struct {
+	Canonical string   `json:"canonical"`
+	Merged    []string `json:"merged"`
+}
+
+type MergeLinksResponse struct {
+	DryRun bool         `json:"dryRun"`
+	Merged int          `json:"merged"`
+	Groups []MergeGroup `json:"groups"`
+}
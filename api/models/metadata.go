@@ -0,0 +1,16 @@
+package models
+
+// LinkMetadata labels a link for the people managing it. It doesn't change where the link
+// leads.
+type LinkMetadata struct {
+	// Name is the display name of the link.
+	Name  string `json:"name,omitempty"`
+	Notes string `json:"notes,omitempty"`
+	// Tags are lowercase, sorted and free of repeats.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// IsZero reports whether m holds no metadata.
+func (m LinkMetadata) IsZero() bool {
+	return m.Name == "" && m.Notes == "" && len(m.Tags) == 0
+}
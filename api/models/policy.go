@@ -0,0 +1,33 @@
+package models
+
+// LinkPolicy is the machine-readable description of how a short link domain behaves, served at
+// /.well-known/link-policy for mail gateways and URL scanners.
+type LinkPolicy struct {
+	Domain         string               `json:"domain"`
+	Redirects      RedirectPolicy       `json:"redirects"`
+	DataCollection DataCollectionPolicy `json:"dataCollection"`
+	Retention      RetentionPolicy      `json:"retention"`
+	Contact        string               `json:"contact,omitempty"`
+}
+
+type RedirectPolicy struct {
+	StatusCode int `json:"statusCode"`
+	// ScannerStatusCode is set when scanners and unknown clients get a 200 preview instead.
+	ScannerStatusCode   int      `json:"scannerStatusCode,omitempty"`
+	AllowedDestinations []string `json:"allowedDestinations"`
+	Interstitial        string   `json:"interstitial,omitempty"`
+	LinkInfoPage        string   `json:"linkInfoPage,omitempty"`
+	PreviewHosts        []string `json:"previewHosts"`
+}
+
+type DataCollectionPolicy struct {
+	Fields                  []string `json:"fields"`
+	ConsentRequired         bool     `json:"consentRequired"`
+	StripsMarketingParams   bool     `json:"stripsMarketingParamsWithoutConsent"`
+	PersonalDataScanOnLinks string   `json:"personalDataScanOnLinks"`
+}
+
+type RetentionPolicy struct {
+	// ClickDataDays is how long click data is kept; 0 means it isn't stored.
+	ClickDataDays int `json:"clickDataDays"`
+}
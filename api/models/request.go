@@ -2,13 +2,21 @@ package models
 
 type ShortenLinkRequest struct {
 	LongDurableLink string `json:"longDurableLink"`
+	LinkMetadata
 }
 
 type ExchangeShortLinkRequest struct {
 	RequestedLink string `json:"requestedLink"`
+	// Platform picks the destination returned in the response: "web", "android" or "ios".
+	Platform string `json:"platform,omitempty"`
 }
 
 type CreateDurableLinkRequest struct {
 	DurableLinkInfo DurableLinkInfo `json:"durableLinkInfo"`
 	Suffix          Suffix          `json:"suffix,omitempty"`
+	// The link's name, notes and tags, next to durableLinkInfo in the body.
+	LinkMetadata
+	// ValidateDestination asks for the destination to be requested before the link is created,
+	// with a warning if it fails. It is the validateDestination query param of POST /shortLinks.
+	ValidateDestination bool `json:"-"`
 }
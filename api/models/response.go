@@ -1,15 +1,24 @@
 package models
 
 type ShortLinkResponse struct {
-	ShortLink string                       `json:"shortLink"`
-	Warnings  []DurableLinkCreationWarning `json:"warnings"`
+	ShortLink string `json:"shortLink"`
+	// Code is the link's numeric code, when one was requested.
+	Code     string                       `json:"code,omitempty"`
+	Warnings []DurableLinkCreationWarning `json:"warnings"`
 }
 
 type LongLinkResponse struct {
 	LongLink string `json:"longLink"`
+	// Link is the destination for the requested platform, only set when a platform was given.
+	Link string `json:"link,omitempty"`
 }
 
 type LinkResponse struct {
 	ShortLink   string `json:"shortLink"`
 	PreviewLink string `json:"previewLink,omitempty"`
 }
+
+type SupersedeLinkResponse struct {
+	ShortLinkResponse
+	Supersedes string `json:"supersedes"`
+}
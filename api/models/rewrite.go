@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// StoredLink is a link row as kept in the database.
+type StoredLink struct {
+	// ID is only set by queries that page through links.
+	ID          int64
+	Host        string
+	Path        string
+	QueryParams string
+	Unguessable bool
+	CreatedAt   time.Time
+	// TotalClicks, LastClickedAt and ActiveUntil are only set by queries that page through links.
+	TotalClicks   int64
+	LastClickedAt *time.Time
+	ActiveUntil   *time.Time
+	// Metadata is only set by queries that page through links, GetLink and CreateShortLinks.
+	Metadata LinkMetadata
+}
+
+type RewriteLinksRequest struct {
+	Host    string `json:"host"`
+	Match   string `json:"match"`
+	Replace string `json:"replace"`
+	// Params lists the URL params the rule is applied to. Defaults to the destination "link" only.
+	Params []string `json:"params,omitempty"`
+	DryRun bool     `json:"dryRun"`
+}
+
+type LinkRewrite struct {
+	Path   string `json:"path"`
+	Param  string `json:"param"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+	Error  string `json:"error,omitempty"`
+	// Warning tells why an applied change may need a second look, e.g. a destination flagged by
+	// the URL reputation service under URL_REPUTATION_POLICY=warn.
+	Warning string `json:"warning,omitempty"`
+}
+
+type RewriteLinksResponse struct {
+	DryRun    bool          `json:"dryRun"`
+	Matched   int           `json:"matched"`
+	Rewritten int           `json:"rewritten"`
+	Changes   []LinkRewrite `json:"changes"`
+}
+
+type LinkRevision struct {
+	QueryParams string    `json:"queryParams"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type LinkRevisionsResponse struct {
+	Revisions []LinkRevision `json:"revisions"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Roles of API keys and token subjects. Viewers can list and read links, editors can also create
+// and update them, and admins can do anything, including deleting links and managing domains and
+// API keys.
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// Roles are the known roles, most privileged first.
+var Roles = []string{RoleAdmin, RoleEditor, RoleViewer}
+
+// APIKey is a key created through /v1/apiKeys. Its ID is the key's fingerprint; the key itself
+// is only returned when it is created.
+type APIKey struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// CreatedAPIKey is the response of creating an API key, the only one holding the key.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type APIKeyListResponse struct {
+	APIKeys []APIKey `json:"apiKeys"`
+}
+
+// SubjectRole is the role of the subject of OIDC tokens, set through /v1/subjectRoles/{subject}.
+// It takes precedence over the role claim of the tokens.
+type SubjectRole struct {
+	Subject   string    `json:"subject"`
+	Role      string    `json:"role"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type SetSubjectRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type SubjectRoleListResponse struct {
+	SubjectRoles []SubjectRole `json:"subjectRoles"`
+}
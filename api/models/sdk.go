@@ -0,0 +1,15 @@
+package models
+
+// WebSDKConfig is what the web SDK needs to talk to this deployment.
+type WebSDKConfig struct {
+	APIBase   string   `json:"apiBase"`
+	Domains   []string `json:"domains"`
+	PublicKey string   `json:"publicKey,omitempty"`
+}
+
+// SignedWebSDKConfig carries the config together with an Ed25519 signature over its JSON
+// encoding, so the SDK can check it against a pinned public key.
+type SignedWebSDKConfig struct {
+	Config    WebSDKConfig `json:"config"`
+	Signature string       `json:"signature,omitempty"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Sort orders of search results.
+const (
+	SortRelevance = "relevance"
+	SortNewest    = "newest"
+	SortOldest    = "oldest"
+)
+
+type SearchRequest struct {
+	Query string
+	// Host limits the search to one domain. Empty searches all of them.
+	Host string
+	// Sort is one of SortRelevance, the default, SortNewest or SortOldest.
+	Sort  string
+	Limit int
+}
+
+type SearchResult struct {
+	ShortLink   string    `json:"shortLink"`
+	Host        string    `json:"host"`
+	Path        string    `json:"path"`
+	Link        string    `json:"link"`
+	Title       string    `json:"title,omitempty"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type SearchResponse struct {
+	Results []SearchResult `json:"results"`
+}
+
+// SavedSearch is a named search an API key can rerun, e.g. as a dashboard quick view.
+type SavedSearch struct {
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	Host      string    `json:"host,omitempty"`
+	Sort      string    `json:"sort"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type SavedSearchesResponse struct {
+	SavedSearches []SavedSearch `json:"savedSearches"`
+}
@@ -0,0 +1,19 @@
+package models
+
+type SelfTestRequest struct {
+	Host string `json:"host,omitempty"`
+}
+
+type SelfTestStep struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"` // "pass", "fail" or "skip"
+	DurationMs int64  `json:"durationMs"`
+	Detail     string `json:"detail,omitempty"`
+}
+
+type SelfTestReport struct {
+	Passed    bool           `json:"passed"`
+	Host      string         `json:"host"`
+	ShortLink string         `json:"shortLink,omitempty"`
+	Steps     []SelfTestStep `json:"steps"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// CreateSignedLinkRequest asks for a signed link on Host leading to Link. It expires after
+// TTLSeconds, or never when 0.
+type CreateSignedLinkRequest struct {
+	Host       string `json:"host"`
+	Link       string `json:"link"`
+	TTLSeconds int64  `json:"ttlSeconds,omitempty"`
+}
+
+type SignedLinkResponse struct {
+	ShortLink string                       `json:"shortLink"`
+	ExpiresAt *time.Time                   `json:"expiresAt,omitempty"`
+	Warnings  []DurableLinkCreationWarning `json:"warnings,omitempty"`
+}
@@ -0,0 +1,102 @@
+package models
+
+import "time"
+
+// LinkStatsRequest is the query of GET /shortLinks/{path}/stats. The range is From to To, or the
+// DurationDays days before To, like the durationDays param of Firebase Dynamic Links. To defaults
+// to now and DurationDays to 7.
+type LinkStatsRequest struct {
+	Host         string
+	Path         string
+	From         time.Time
+	To           time.Time
+	DurationDays int
+	// Granularity is "hour", "day", "week" or "month", and defaults to "day".
+	Granularity string
+}
+
+// ClickStatsQuery selects the clicks GET /shortLinks/{path}/stats reports on: those on the link
+// or its aliases from From, inclusive, to To, exclusive. With Campaign set, Path is ignored and
+// the clicks on the links of the campaign and their aliases are counted instead.
+type ClickStatsQuery struct {
+	Path     string
+	Campaign string
+	From     time.Time
+	To       time.Time
+	// Granularity is "hour", "day", "week" or "month".
+	Granularity  string
+	TopReferrers int
+}
+
+// ClickStats is what the repository counts for a ClickStatsQuery. Clicks are redirects; unique
+// clicks count each visitor once per VISITOR_SALT_ROTATION period, a UTC day by default, and
+// clicks without a known visitor once each.
+type ClickStats struct {
+	Clicks       int64
+	UniqueClicks int64
+	// ConsentedClicks counts the redirects of visitors who consented to analytics. Only those
+	// count towards TopReferrers.
+	ConsentedClicks int64
+	Buckets         []ClickBucket
+	// Sources counts all resolutions by source and platform, exchanges included.
+	Sources      []SourceClicks
+	TopReferrers []ReferrerClicks
+	// Links counts the redirects per link of a campaign, most clicked first. It is nil unless
+	// the query is for a campaign.
+	Links []LinkClicks
+	// Variants counts the redirects per A/B test variant, by variant name.
+	Variants []VariantClicks
+}
+
+type SourceClicks struct {
+	Source       string
+	Platform     string
+	Clicks       int64
+	UniqueClicks int64
+}
+
+type ClickBucket struct {
+	Start        time.Time `json:"start"`
+	Clicks       int64     `json:"clicks"`
+	UniqueClicks int64     `json:"uniqueClicks"`
+}
+
+type PlatformClicks struct {
+	Platform     string `json:"platform"`
+	Clicks       int64  `json:"clicks"`
+	UniqueClicks int64  `json:"uniqueClicks"`
+}
+
+// ReferrerClicks counts the clicks referred by one site, by the host of the Referer header.
+type ReferrerClicks struct {
+	Referrer string `json:"referrer"`
+	Clicks   int64  `json:"clicks"`
+}
+
+// LinkEventStat is one entry of the Firebase Dynamic Links linkStats response: the count of one
+// event on one platform. Event is "CLICK" for redirects and "APP_RE_OPEN" for exchanges of the
+// link by an app; Platform is "ANDROID", "IOS", "DESKTOP" or "OTHER".
+type LinkEventStat struct {
+	Platform string `json:"platform"`
+	Count    int64  `json:"count,string"`
+	Event    string `json:"event"`
+}
+
+// LinkStatsResponse is the body of GET /shortLinks/{path}/stats. LinkEventStats has the layout
+// of the Firebase Dynamic Links linkStats response so existing dashboards keep working; the other
+// fields go beyond it. ConsentedClicks counts the clicks of visitors who consented to analytics;
+// the others are counted without their referrer, country or visitor.
+type LinkStatsResponse struct {
+	LinkEventStats  []LinkEventStat  `json:"linkEventStats"`
+	ShortLink       string           `json:"shortLink"`
+	From            time.Time        `json:"from"`
+	To              time.Time        `json:"to"`
+	Granularity     string           `json:"granularity"`
+	Clicks          int64            `json:"clicks"`
+	UniqueClicks    int64            `json:"uniqueClicks"`
+	ConsentedClicks int64            `json:"consentedClicks"`
+	Buckets         []ClickBucket    `json:"buckets"`
+	Platforms       []PlatformClicks `json:"platforms"`
+	TopReferrers    []ReferrerClicks `json:"topReferrers"`
+	Variants        []VariantClicks  `json:"variants,omitempty"`
+}
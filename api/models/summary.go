@@ -0,0 +1,30 @@
+package models
+
+type ClickTotals struct {
+	Today      int64 `json:"today"`
+	Last7Days  int64 `json:"last7Days"`
+	Last30Days int64 `json:"last30Days"`
+}
+
+type TopLink struct {
+	ShortLink string `json:"shortLink"`
+	Path      string `json:"path"`
+	Link      string `json:"link"`
+	Clicks    int64  `json:"clicks"`
+}
+
+type TopDomain struct {
+	Domain string `json:"domain"`
+	Clicks int64  `json:"clicks"`
+	Links  int    `json:"links"`
+}
+
+// SummaryResponse is a project's dashboard overview. Top links and domains are ranked by clicks
+// over the last 30 days.
+type SummaryResponse struct {
+	Host        string      `json:"host"`
+	ActiveLinks int64       `json:"activeLinks"`
+	Clicks      ClickTotals `json:"clicks"`
+	TopLinks    []TopLink   `json:"topLinks"`
+	TopDomains  []TopDomain `json:"topDomains"`
+}
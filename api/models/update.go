@@ -0,0 +1,36 @@
+package models
+
+// UpdateLinkRequest is the body of PATCH /shortLinks/{path}. Only the fields present change, and
+// an empty string removes a fallback or social param. The destination link can't be removed.
+type UpdateLinkRequest struct {
+	Link                *string `json:"link"`
+	AndroidFallbackLink *string `json:"androidFallbackLink"`
+	IosFallbackLink     *string `json:"iosFallbackLink"`
+	IosIpadFallbackLink *string `json:"iosIpadFallbackLink"`
+	FallbackURL         *string `json:"ofl"`
+	SocialTitle         *string `json:"socialTitle"`
+	SocialDescription   *string `json:"socialDescription"`
+	SocialImageLink     *string `json:"socialImageLink"`
+	// Name, Notes and Tags change the link's metadata. An empty string or tags array removes it.
+	Name  *string   `json:"name"`
+	Notes *string   `json:"notes"`
+	Tags  *[]string `json:"tags"`
+}
+
+// StoredLinkResponse is a link as GET and PATCH /shortLinks/{path} return it. ETag is sent in
+// the ETag header too, for the If-Match header of the next PATCH.
+type StoredLinkResponse struct {
+	ShortLink string `json:"shortLink"`
+	// LongLink is empty while the link is reserved.
+	LongLink string `json:"longLink"`
+	ETag     string `json:"etag"`
+	// Reserved is set on links reserved without a destination, until a PATCH sets their link.
+	Reserved bool `json:"reserved,omitempty"`
+	LinkMetadata
+	Warnings []DurableLinkCreationWarning `json:"warnings,omitempty"`
+}
+
+// ReserveLinkRequest is the body of POST /shortLinks/{path}:reserve.
+type ReserveLinkRequest struct {
+	Host string `json:"host"`
+}
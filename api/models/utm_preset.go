@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// UTMPreset is a named set of UTM params of a short link domain, managed through
+// /v1/domains/{domain}/utmPresets. Links created with its name as analyticsInfo.presetName get
+// the params they don't set themselves from it.
+type UTMPreset struct {
+	Name                string              `json:"name"`
+	Host                string              `json:"host"`
+	MarketingParameters MarketingParameters `json:"marketingParameters"`
+	CreatedAt           time.Time           `json:"createdAt"`
+}
+
+type UTMPresetListResponse struct {
+	Presets []UTMPreset `json:"presets"`
+}
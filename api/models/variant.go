@@ -0,0 +1,16 @@
+package models
+
+// LinkVariant is one destination of a link's A/B test. Each visitor is sent to one variant, with
+// a chance of Weight out of the weights of all variants, and stays on it.
+type LinkVariant struct {
+	Name   string `json:"name"`
+	Link   string `json:"link"`
+	Weight int    `json:"weight"`
+}
+
+// VariantClicks counts the redirects to one A/B test variant.
+type VariantClicks struct {
+	Variant      string `json:"variant"`
+	Clicks       int64  `json:"clicks"`
+	UniqueClicks int64  `json:"uniqueClicks"`
+}
@@ -0,0 +1,10 @@
+package models
+
+type VersionResponse struct {
+	Version          string   `json:"version"`
+	Commit           string   `json:"commit,omitempty"`
+	BuildDate        string   `json:"buildDate,omitempty"`
+	GoVersion        string   `json:"goVersion"`
+	MigrationVersion int      `json:"migrationVersion"`
+	Features         []string `json:"features"`
+}
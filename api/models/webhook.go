@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Webhook is an endpoint notified of the link events of a short link domain, managed through
+// /v1/domains/{domain}/webhooks.
+type Webhook struct {
+	ID   int64  `json:"id"`
+	Host string `json:"host"`
+	URL  string `json:"url"`
+	// Secret signs the payloads. It is generated when not given, and only returned on creation.
+	Secret string `json:"secret,omitempty"`
+	// Events lists the event types sent to the endpoint, every link event when empty.
+	Events []string `json:"events"`
+	// ClickThresholds are the total click counts of a link that send it a link.click_threshold
+	// event as they are reached.
+	ClickThresholds []int64   `json:"clickThresholds"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+type WebhookListResponse struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// WebhookDelivery is one attempt to deliver an event to a webhook.
+type WebhookDelivery struct {
+	ID        int64  `json:"id"`
+	WebhookID int64  `json:"webhookId"`
+	EventID   string `json:"eventId"`
+	EventType string `json:"eventType"`
+	Payload   string `json:"payload"`
+	Attempt   int    `json:"attempt"`
+	// StatusCode is the status the endpoint answered with, 0 when it didn't answer.
+	StatusCode  int       `json:"statusCode"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  int64     `json:"durationMs"`
+	DeliveredAt time.Time `json:"deliveredAt"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
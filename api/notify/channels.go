@@ -0,0 +1,130 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// Slack posts events to a Slack incoming webhook.
+type Slack struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+func (s *Slack) Notify(ctx context.Context, event Event) error {
+	var text strings.Builder
+	fmt.Fprintf(&text, "*[%s] %s*", strings.ToUpper(event.Severity.String()), event.Title)
+	if event.Project != "" {
+		fmt.Fprintf(&text, " (%s)", event.Project)
+	}
+	if event.Message != "" {
+		text.WriteString("\n" + event.Message)
+	}
+	keys := make([]string, 0, len(event.Fields))
+	for k := range event.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&text, "\n• %s: %s", k, event.Fields[k])
+	}
+
+	return postJSON(ctx, s.Client, s.WebhookURL, map[string]string{"text": text.String()}, nil)
+}
+
+// PagerDuty triggers incidents through the Events API v2. Events of the same kind and project
+// share a dedup key, so repeats update the open incident instead of paging again.
+type PagerDuty struct {
+	RoutingKey string
+	// URL overrides the Events API endpoint, for tests and EU accounts.
+	URL    string
+	Client *http.Client
+}
+
+func (p *PagerDuty) Notify(ctx context.Context, event Event) error {
+	url := p.URL
+	if url == "" {
+		url = pagerDutyEventsURL
+	}
+
+	severity := event.Severity.String()
+	summary := event.Title
+	if event.Project != "" {
+		summary += " (" + event.Project + ")"
+	}
+
+	return postJSON(ctx, p.Client, url, map[string]any{
+		"routing_key":  p.RoutingKey,
+		"event_action": "trigger",
+		"dedup_key":    event.Kind + ":" + event.Project,
+		"payload": map[string]any{
+			"summary":        summary,
+			"source":         "durable-links-generator",
+			"severity":       severity,
+			"timestamp":      event.Time,
+			"custom_details": event,
+		},
+	}, nil)
+}
+
+// Webhook posts the event as JSON. With a secret the body is signed with HMAC-SHA256 in the
+// X-Signature header as "sha256=<hex>".
+type Webhook struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+func (h *Webhook) Notify(ctx context.Context, event Event) error {
+	var sign func([]byte) map[string]string
+	if h.Secret != "" {
+		sign = func(body []byte) map[string]string {
+			mac := hmac.New(sha256.New, []byte(h.Secret))
+			mac.Write(body)
+			return map[string]string{"X-Signature": "sha256=" + hex.EncodeToString(mac.Sum(nil))}
+		}
+	}
+	return postJSON(ctx, h.Client, h.URL, event, sign)
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, payload any, headers func([]byte) map[string]string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if headers != nil {
+		for k, v := range headers(body) {
+			req.Header.Set(k, v)
+		}
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d", req.URL.Host, resp.StatusCode)
+	}
+	return nil
+}
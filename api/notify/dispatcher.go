@@ -0,0 +1,193 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"durable-links-generator/config"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Route sends matching events to a channel. Empty Projects or Kinds match everything.
+type Route struct {
+	Notifier    Notifier
+	Projects    []string
+	Kinds       []string
+	MinSeverity Severity
+}
+
+func (r Route) matches(event Event) bool {
+	if event.Severity < r.MinSeverity {
+		return false
+	}
+	if len(r.Kinds) > 0 && !slices.Contains(r.Kinds, event.Kind) {
+		return false
+	}
+	if len(r.Projects) > 0 && !slices.ContainsFunc(r.Projects, func(p string) bool {
+		return p == "*" || strings.EqualFold(p, event.Project)
+	}) {
+		return false
+	}
+	return true
+}
+
+// Dispatcher queues events and delivers them to the matching routes from a background
+// goroutine, so raising an alert never slows down a request. The same kind of event for the same
+// project is sent at most once per dedup window.
+type Dispatcher struct {
+	routes      []Route
+	queue       chan Event
+	dedupWindow time.Duration
+
+	mu       sync.Mutex
+	lastSent map[string]time.Time
+	now      func() time.Time
+
+	done chan struct{}
+}
+
+func NewDispatcher(routes []Route, dedupWindow time.Duration) *Dispatcher {
+	d := &Dispatcher{
+		routes:      routes,
+		queue:       make(chan Event, 256),
+		dedupWindow: dedupWindow,
+		lastSent:    map[string]time.Time{},
+		now:         time.Now,
+		done:        make(chan struct{}),
+	}
+	go d.run()
+	return d
+}
+
+// Dedup keys a dispatcher remembers, at most.
+const maxDedupKeys = 10000
+
+// Notify queues event. It is dropped when it repeats a recent event or the queue is full; a
+// dropped event doesn't hold back the next one.
+func (d *Dispatcher) Notify(_ context.Context, event Event) error {
+	if event.Time.IsZero() {
+		event.Time = d.now()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := event.Kind + "\x00" + strings.ToLower(event.Project)
+	if last, ok := d.lastSent[key]; ok && event.Time.Sub(last) < d.dedupWindow {
+		return nil
+	}
+
+	select {
+	case d.queue <- event:
+	default:
+		log.Warn().Str("kind", event.Kind).Msg("Notification queue full, dropping event")
+		return fmt.Errorf("notification queue full")
+	}
+	d.rememberLocked(key, event.Time)
+	return nil
+}
+
+// rememberLocked records when the event with key was queued. When the dedup keys are full, the
+// ones past the dedup window are dropped first, then an arbitrary one.
+func (d *Dispatcher) rememberLocked(key string, sent time.Time) {
+	if _, known := d.lastSent[key]; !known && len(d.lastSent) >= maxDedupKeys {
+		for k, last := range d.lastSent {
+			if sent.Sub(last) >= d.dedupWindow {
+				delete(d.lastSent, k)
+			}
+		}
+		if len(d.lastSent) >= maxDedupKeys {
+			for k := range d.lastSent {
+				delete(d.lastSent, k)
+				break
+			}
+		}
+	}
+	d.lastSent[key] = sent
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+	for event := range d.queue {
+		for _, route := range d.routes {
+			if !route.matches(event) {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := route.Notifier.Notify(ctx, event); err != nil {
+				log.Error().Err(err).Str("kind", event.Kind).Msg("Failed to send notification")
+			}
+			cancel()
+		}
+	}
+}
+
+// Close delivers the queued events and stops the dispatcher.
+func (d *Dispatcher) Close() {
+	close(d.queue)
+	<-d.done
+}
+
+type channelConfig struct {
+	Type        string   `json:"type"` // "slack", "pagerduty" or "webhook"
+	URL         string   `json:"url"`
+	RoutingKey  string   `json:"routingKey"`
+	Secret      string   `json:"secret"`
+	Projects    []string `json:"projects"`
+	Kinds       []string `json:"kinds"`
+	MinSeverity Severity `json:"minSeverity"`
+}
+
+// New builds the dispatcher for the channels in NOTIFICATIONS_FILE. Without a file events are
+// dropped.
+func New(cfg *config.Config) (Notifier, error) {
+	if cfg.App.NotificationsFile == "" {
+		return Nop{}, nil
+	}
+
+	data, err := os.ReadFile(cfg.App.NotificationsFile)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Channels []channelConfig `json:"channels"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid NOTIFICATIONS_FILE: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	routes := make([]Route, 0, len(file.Channels))
+	for i, c := range file.Channels {
+		var notifier Notifier
+		switch strings.ToLower(c.Type) {
+		case "slack":
+			notifier = &Slack{WebhookURL: c.URL, Client: client}
+		case "pagerduty":
+			notifier = &PagerDuty{RoutingKey: c.RoutingKey, URL: c.URL, Client: client}
+		case "webhook":
+			notifier = &Webhook{URL: c.URL, Secret: c.Secret, Client: client}
+		default:
+			return nil, fmt.Errorf("channel %d: unknown type %q", i+1, c.Type)
+		}
+		if c.URL == "" && c.RoutingKey == "" {
+			return nil, fmt.Errorf("channel %d: missing url or routingKey", i+1)
+		}
+		routes = append(routes, Route{
+			Notifier:    notifier,
+			Projects:    c.Projects,
+			Kinds:       c.Kinds,
+			MinSeverity: c.MinSeverity,
+		})
+	}
+
+	return NewDispatcher(routes, cfg.App.NotificationsDedupWindow), nil
+}
@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "info"
+	}
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	parsed, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "info":
+		return SeverityInfo, nil
+	case "warning", "warn":
+		return SeverityWarning, nil
+	case "critical", "error":
+		return SeverityCritical, nil
+	}
+	return SeverityInfo, fmt.Errorf("unknown severity %q", s)
+}
+
+// Kinds of events raised by the service.
+const (
+	KindQuotaExhausted   = "quota_exhausted"
+	KindDomainUnverified = "domain_unverified"
+	KindSelfTestFailed   = "selftest_failed"
+	KindLinksArchived    = "links_archived"
+)
+
+// Event is an operational alert. Project is the short link host it concerns, empty for
+// deployment wide events.
+type Event struct {
+	Kind     string            `json:"kind"`
+	Project  string            `json:"project,omitempty"`
+	Severity Severity          `json:"severity"`
+	Title    string            `json:"title"`
+	Message  string            `json:"message,omitempty"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	Time     time.Time         `json:"time"`
+}
+
+// Notifier delivers events to an external channel.
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// Nop drops every event. It is used when no channels are configured.
+type Nop struct{}
+
+func (Nop) Notify(context.Context, Event) error {
+	return nil
+}
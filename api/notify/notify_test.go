@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"durable-links-generator/config"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+	os.Exit(m.Run())
+}
+
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recorder) Notify(_ context.Context, event Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestDispatcherRouting(t *testing.T) {
+	all := &recorder{}
+	critical := &recorder{}
+	shop := &recorder{}
+
+	d := NewDispatcher([]Route{
+		{Notifier: all},
+		{Notifier: critical, MinSeverity: SeverityCritical},
+		{Notifier: shop, Projects: []string{"shop.page.link"}, Kinds: []string{KindQuotaExhausted}},
+	}, time.Minute)
+
+	d.Notify(context.Background(), Event{Kind: KindQuotaExhausted, Project: "Shop.page.link", Severity: SeverityWarning})
+	d.Notify(context.Background(), Event{Kind: KindQuotaExhausted, Project: "shop.page.link", Severity: SeverityWarning})
+	d.Notify(context.Background(), Event{Kind: KindSelfTestFailed, Project: "shop.page.link", Severity: SeverityCritical})
+	d.Notify(context.Background(), Event{Kind: KindQuotaExhausted, Project: "other.page.link", Severity: SeverityWarning})
+	d.Close()
+
+	assert.Len(t, all.events, 3, "the repeated quota event is deduplicated")
+	assert.Len(t, critical.events, 1)
+	assert.Len(t, shop.events, 1)
+}
+
+func TestDispatcherDedup(t *testing.T) {
+	// Without a running loop, the queue only holds one event.
+	d := &Dispatcher{queue: make(chan Event, 1), dedupWindow: time.Minute, lastSent: map[string]time.Time{}, now: time.Now}
+	ctx := context.Background()
+
+	assert.NoError(t, d.Notify(ctx, Event{Kind: KindQuotaExhausted, Project: "a"}))
+	assert.Error(t, d.Notify(ctx, Event{Kind: KindSelfTestFailed, Project: "a"}), "the queue is full")
+	<-d.queue
+	assert.NoError(t, d.Notify(ctx, Event{Kind: KindSelfTestFailed, Project: "a"}), "the dropped event isn't deduplicated")
+	<-d.queue
+	assert.NoError(t, d.Notify(ctx, Event{Kind: KindSelfTestFailed, Project: "a"}))
+	assert.Empty(t, d.queue, "the queued event is deduplicated")
+
+	start := time.Now()
+	for i := range maxDedupKeys + 10 {
+		d.rememberLocked(strconv.Itoa(i), start.Add(time.Duration(i)*time.Second))
+	}
+	assert.LessOrEqual(t, len(d.lastSent), maxDedupKeys)
+	assert.Contains(t, d.lastSent, strconv.Itoa(maxDedupKeys+9))
+}
+
+func TestChannels(t *testing.T) {
+	var body []byte
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get("X-Signature")
+	}))
+	defer server.Close()
+
+	event := Event{
+		Kind:     KindDomainUnverified,
+		Project:  "shop.page.link",
+		Severity: SeverityWarning,
+		Title:    "Domain not verified",
+		Fields:   map[string]string{"txt_record": "abc"},
+	}
+	ctx := context.Background()
+
+	assert.NoError(t, (&Slack{WebhookURL: server.URL}).Notify(ctx, event))
+	var slack map[string]string
+	assert.NoError(t, json.Unmarshal(body, &slack))
+	assert.Equal(t, "*[WARNING] Domain not verified* (shop.page.link)\n• txt_record: abc", slack["text"])
+
+	assert.NoError(t, (&PagerDuty{RoutingKey: "rk", URL: server.URL}).Notify(ctx, event))
+	var pd map[string]any
+	assert.NoError(t, json.Unmarshal(body, &pd))
+	assert.Equal(t, "rk", pd["routing_key"])
+	assert.Equal(t, "domain_unverified:shop.page.link", pd["dedup_key"])
+	assert.Equal(t, "warning", pd["payload"].(map[string]any)["severity"])
+
+	assert.NoError(t, (&Webhook{URL: server.URL, Secret: "s3cret"}).Notify(ctx, event))
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), signature)
+	var received Event
+	assert.NoError(t, json.Unmarshal(body, &received))
+	assert.Equal(t, SeverityWarning, received.Severity)
+}
+
+func TestNew(t *testing.T) {
+	notifier, err := New(&config.Config{App: &config.AppConfig{}})
+	assert.NoError(t, err)
+	assert.Equal(t, Nop{}, notifier)
+
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "notifications.json")
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	path := write(`{"channels":[{"type":"slack","url":"https://hooks.slack.com/x","minSeverity":"critical"},{"type":"pagerduty","routingKey":"rk"}]}`)
+	notifier, err = New(&config.Config{App: &config.AppConfig{NotificationsFile: path}})
+	assert.NoError(t, err)
+	dispatcher := notifier.(*Dispatcher)
+	assert.Len(t, dispatcher.routes, 2)
+	assert.Equal(t, SeverityCritical, dispatcher.routes[0].MinSeverity)
+	dispatcher.Close()
+
+	for _, content := range []string{
+		`{"channels":[{"type":"email","url":"x"}]}`,
+		`{"channels":[{"type":"slack"}]}`,
+		`{"channels":[{"type":"slack","url":"x","minSeverity":"loud"}]}`,
+	} {
+		_, err = New(&config.Config{App: &config.AppConfig{NotificationsFile: write(content)}})
+		assert.Error(t, err, content)
+	}
+}
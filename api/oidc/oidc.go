@@ -0,0 +1,416 @@
+// Package oidc verifies JWTs issued by an OpenID Connect provider, so callers can authenticate
+// with the tokens of an existing SSO instead of API keys. The provider's signing keys are read
+// from its JWKS, found through discovery unless configured, and cached: they are fetched again
+// when the cache expires, and early when a token names a key the cache doesn't hold, so the
+// provider can rotate keys.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("token is invalid")
+	ErrExpiredToken = errors.New("token has expired")
+)
+
+const (
+	// leeway allows for clock skew between the provider and this server.
+	leeway = time.Minute
+	// minRefreshInterval bounds how often tokens naming unknown keys refetch the JWKS.
+	minRefreshInterval = 30 * time.Second
+	// maxDocumentSize bounds the discovery document and JWKS read.
+	maxDocumentSize = 1 << 20
+)
+
+type Options struct {
+	// Issuer is the provider's issuer URL, which tokens' "iss" claim must equal.
+	Issuer string
+	// Audience, when set, must be among tokens' "aud" claim.
+	Audience string
+	// JWKSURL is where the signing keys are read; empty discovers it from the issuer.
+	JWKSURL string
+	// CacheTTL is how long fetched keys are used before fetching them again.
+	CacheTTL time.Duration
+	// Timeout bounds each request to the provider.
+	Timeout time.Duration
+}
+
+// Verifier verifies the tokens of one issuer.
+type Verifier struct {
+	opts   Options
+	client *http.Client
+	now    func() time.Time
+
+	mu sync.Mutex
+	// jwksURL is Options.JWKSURL, or the discovered one once discovery succeeded.
+	jwksURL   string
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	triedAt   time.Time
+	fetchErr  error
+}
+
+// New returns a verifier for the tokens of opts.Issuer. Nothing is fetched until the first token
+// is verified, so a provider that is down doesn't keep the server from starting.
+func New(opts Options) (*Verifier, error) {
+	if !isHTTPURL(opts.Issuer) {
+		return nil, fmt.Errorf("issuer %q must be an absolute http(s) URL", opts.Issuer)
+	}
+	if opts.JWKSURL != "" && !isHTTPURL(opts.JWKSURL) {
+		return nil, fmt.Errorf("JWKS URL %q must be an absolute http(s) URL", opts.JWKSURL)
+	}
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = time.Hour
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	return &Verifier{
+		opts:    opts,
+		client:  &http.Client{Timeout: opts.Timeout},
+		now:     time.Now,
+		jwksURL: opts.JWKSURL,
+	}, nil
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
+
+// LooksLikeToken reports whether credential has the shape of a JWT, telling tokens apart from
+// API keys sent the same way.
+func LooksLikeToken(credential string) bool {
+	return strings.HasPrefix(credential, "eyJ") && strings.Count(credential, ".") == 2
+}
+
+// Claims are the claims of a verified token.
+type Claims map[string]any
+
+// Subject returns the "sub" claim.
+func (c Claims) Subject() string {
+	sub, _ := c["sub"].(string)
+	return sub
+}
+
+// Strings returns the claim at path as a list: a string claim is a list of one, and a list claim
+// keeps its strings. Dots in path walk into nested objects, e.g. "realm_access.roles".
+func (c Claims) Strings(path string) []string {
+	var value any = map[string]any(c)
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+	switch value := value.(type) {
+	case string:
+		return []string{value}
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// Verify checks token's signature, issuer, audience and validity period, and returns its
+// claims. Tokens that fail the checks are ErrInvalidToken or ErrExpiredToken; other errors mean
+// the signing keys couldn't be fetched.
+func (v *Verifier) Verify(ctx context.Context, token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+	alg, ok := algorithms[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("%w: algorithm %q is not accepted", ErrInvalidToken, header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if !alg.verify(key, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *Verifier) checkClaims(claims Claims) error {
+	if iss, _ := claims["iss"].(string); iss != v.opts.Issuer {
+		return fmt.Errorf("%w: issuer %q is not accepted", ErrInvalidToken, iss)
+	}
+	if v.opts.Audience != "" {
+		found := false
+		for _, aud := range claims.Strings("aud") {
+			found = found || aud == v.opts.Audience
+		}
+		if !found {
+			return fmt.Errorf("%w: audience is not accepted", ErrInvalidToken)
+		}
+	}
+	now := v.now()
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("%w: missing expiry", ErrInvalidToken)
+	}
+	if !now.Before(time.Unix(int64(exp), 0).Add(leeway)) {
+		return ErrExpiredToken
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(leeway).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+	if claims.Subject() == "" {
+		return fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+	return nil
+}
+
+// key returns the signing key named kid, fetching the keys when the cache expired or doesn't hold
+// it. A token without kid can only be verified while the JWKS holds a single key.
+func (v *Verifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := v.now()
+	expired := now.Sub(v.fetchedAt) >= v.opts.CacheTTL
+	key, known := v.lookup(kid)
+	// Failed fetches are retried no sooner than minRefreshInterval either, so a provider that is
+	// down isn't asked on every request.
+	if (expired || !known) && now.Sub(v.triedAt) >= minRefreshInterval {
+		v.triedAt = now
+		keys, err := v.fetchKeys(ctx)
+		if err == nil {
+			v.keys, v.fetchedAt = keys, now
+		}
+		// A failed refresh keeps the keys fetched before.
+		v.fetchErr = err
+		key, known = v.lookup(kid)
+	}
+	if !known {
+		if v.keys == nil {
+			return nil, v.fetchErr
+		}
+		return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+func (v *Verifier) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[kid]
+	return key, ok && kid != ""
+}
+
+func (v *Verifier) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	if v.jwksURL == "" {
+		var discovery struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := v.get(ctx, strings.TrimRight(v.opts.Issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+			return nil, fmt.Errorf("oidc discovery failed: %w", err)
+		}
+		if discovery.Issuer != v.opts.Issuer || discovery.JWKSURI == "" {
+			return nil, fmt.Errorf("oidc discovery document of %q is for issuer %q", v.opts.Issuer, discovery.Issuer)
+		}
+		v.jwksURL = discovery.JWKSURI
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := v.get(ctx, v.jwksURL, &set); err != nil {
+		return nil, fmt.Errorf("jwks request failed: %w", err)
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		// Keys of unsupported types are skipped rather than failing the whole set.
+		if key, err := k.publicKey(); err == nil {
+			keys[k.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+func (v *Verifier) get(ctx context.Context, url string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxDocumentSize)).Decode(dst)
+}
+
+func decodeSegment(segment string, dst any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// jwk is a public key of a JWKS, RFC 7517. Only RSA and EC keys are used.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+var curves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(k.E)
+		if err != nil || !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curve, ok := curves[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		if !key.Curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(data) == 0 {
+		return nil, errors.New("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// algorithm verifies the signatures of one JWS algorithm. HMAC and "none" are deliberately
+// missing: a provider's tokens are only ever verified with its public keys.
+type algorithm struct {
+	hash func() hash.Hash
+	id   crypto.Hash
+	kind string // "RS", "PS" or "ES"
+	// curve is the curve of the keys of an ES algorithm.
+	curve string
+}
+
+var algorithms = map[string]algorithm{
+	"RS256": {sha256.New, crypto.SHA256, "RS", ""},
+	"RS384": {sha512.New384, crypto.SHA384, "RS", ""},
+	"RS512": {sha512.New, crypto.SHA512, "RS", ""},
+	"PS256": {sha256.New, crypto.SHA256, "PS", ""},
+	"PS384": {sha512.New384, crypto.SHA384, "PS", ""},
+	"PS512": {sha512.New, crypto.SHA512, "PS", ""},
+	"ES256": {sha256.New, crypto.SHA256, "ES", "P-256"},
+	"ES384": {sha512.New384, crypto.SHA384, "ES", "P-384"},
+	"ES512": {sha512.New, crypto.SHA512, "ES", "P-521"},
+}
+
+func (a algorithm) verify(key crypto.PublicKey, signed, signature []byte) bool {
+	h := a.hash()
+	h.Write(signed)
+	digest := h.Sum(nil)
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		switch a.kind {
+		case "RS":
+			return rsa.VerifyPKCS1v15(key, a.id, digest, signature) == nil
+		case "PS":
+			return rsa.VerifyPSS(key, a.id, digest, signature, nil) == nil
+		}
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if a.kind != "ES" || key.Curve.Params().Name != a.curve || len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(key, digest, r, s)
+	}
+	return false
+}
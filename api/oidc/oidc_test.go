@@ -0,0 +1,179 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type provider struct {
+	server   *httptest.Server
+	keys     []map[string]string
+	requests atomic.Int32
+}
+
+func newProvider(t *testing.T) *provider {
+	p := &provider{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": p.server.URL, "jwks_uri": p.server.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		p.requests.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"keys": p.keys})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func (p *provider) addRSA(t *testing.T, kid string) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	p.keys = append(p.keys, map[string]string{
+		"kty": "RSA", "kid": kid, "use": "sig",
+		"n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes()),
+	})
+	return key
+}
+
+func (p *provider) addEC(t *testing.T, kid string) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	p.keys = append(p.keys, map[string]string{
+		"kty": "EC", "kid": kid, "crv": "P-256",
+		"x": b64(key.X.FillBytes(make([]byte, 32))), "y": b64(key.Y.FillBytes(make([]byte, 32))),
+	})
+	return key
+}
+
+func sign(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+	var signature []byte
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		assert.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		assert.NoError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + b64(signature)
+}
+
+func TestVerify(t *testing.T) {
+	p := newProvider(t)
+	rsaKey := p.addRSA(t, "r1")
+	ecKey := p.addEC(t, "e1")
+	v, err := New(Options{Issuer: p.server.URL, Audience: "links"})
+	assert.NoError(t, err)
+	now := time.Now()
+	ctx := context.Background()
+	claims := func(overrides map[string]any) map[string]any {
+		c := map[string]any{"iss": p.server.URL, "aud": []string{"other", "links"}, "sub": "alice", "exp": now.Add(time.Hour).Unix(), "role": "admin"}
+		for name, value := range overrides {
+			c[name] = value
+		}
+		return c
+	}
+
+	verified, err := v.Verify(ctx, sign(t, "RS256", "r1", rsaKey, claims(nil)))
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", verified.Subject())
+	assert.Equal(t, []string{"admin"}, verified.Strings("role"))
+	_, err = v.Verify(ctx, sign(t, "ES256", "e1", ecKey, claims(nil)))
+	assert.NoError(t, err)
+
+	for name, token := range map[string]string{
+		"wrong issuer":   sign(t, "RS256", "r1", rsaKey, claims(map[string]any{"iss": "https://evil.test"})),
+		"wrong audience": sign(t, "RS256", "r1", rsaKey, claims(map[string]any{"aud": "other"})),
+		"no subject":     sign(t, "RS256", "r1", rsaKey, claims(map[string]any{"sub": ""})),
+		"not yet valid":  sign(t, "RS256", "r1", rsaKey, claims(map[string]any{"nbf": now.Add(time.Hour).Unix()})),
+		"wrong key":      sign(t, "RS256", "e1", rsaKey, claims(nil)),
+		"HMAC":           sign(t, "HS256", "r1", rsaKey, claims(nil)),
+		"none":           sign(t, "none", "r1", rsaKey, claims(nil)),
+		"malformed":      "eyJhbGciOiJSUzI1NiJ9.e30",
+	} {
+		_, err := v.Verify(ctx, token)
+		assert.ErrorIs(t, err, ErrInvalidToken, name)
+	}
+	_, err = v.Verify(ctx, sign(t, "RS256", "r1", rsaKey, claims(map[string]any{"exp": now.Add(-2 * time.Minute).Unix()})))
+	assert.ErrorIs(t, err, ErrExpiredToken)
+
+	token := sign(t, "RS256", "r1", rsaKey, claims(nil))
+	tampered := token[:len(token)-4] + "AAAA"
+	_, err = v.Verify(ctx, tampered)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestKeyRotation(t *testing.T) {
+	p := newProvider(t)
+	old := p.addRSA(t, "r1")
+	v, err := New(Options{Issuer: p.server.URL})
+	assert.NoError(t, err)
+	now := time.Now()
+	v.now = func() time.Time { return now }
+	ctx := context.Background()
+	claims := map[string]any{"iss": p.server.URL, "sub": "alice", "exp": now.Add(time.Hour).Unix()}
+
+	_, err = v.Verify(ctx, sign(t, "RS256", "r1", old, claims))
+	assert.NoError(t, err)
+	_, err = v.Verify(ctx, sign(t, "RS256", "r1", old, claims))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), p.requests.Load(), "keys are cached")
+
+	rotated := p.addRSA(t, "r2")
+	_, err = v.Verify(ctx, sign(t, "RS256", "r2", rotated, claims))
+	assert.ErrorIs(t, err, ErrInvalidToken, "refetches are rate limited")
+	now = now.Add(minRefreshInterval)
+	_, err = v.Verify(ctx, sign(t, "RS256", "r2", rotated, claims))
+	assert.NoError(t, err, "an unknown key refetches the JWKS")
+	assert.Equal(t, int32(2), p.requests.Load())
+}
+
+func TestProviderDown(t *testing.T) {
+	p := newProvider(t)
+	key := p.addRSA(t, "r1")
+	v, err := New(Options{Issuer: p.server.URL, JWKSURL: p.server.URL + "/missing"})
+	assert.NoError(t, err)
+
+	_, err = v.Verify(context.Background(), sign(t, "RS256", "r1", key, map[string]any{"iss": p.server.URL, "sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}))
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidToken, "an unavailable provider isn't the token's fault")
+}
+
+func TestNewValidatesURLs(t *testing.T) {
+	_, err := New(Options{Issuer: "accounts.example.com"})
+	assert.Error(t, err)
+	_, err = New(Options{Issuer: "https://accounts.example.com", JWKSURL: "/keys"})
+	assert.Error(t, err)
+}
+
+func TestLooksLikeToken(t *testing.T) {
+	assert.True(t, LooksLikeToken("eyJhbGciOiJSUzI1NiJ9.e30.c2ln"))
+	assert.False(t, LooksLikeToken("secret-api-key"))
+	assert.False(t, LooksLikeToken("eyJ.only-one-dot"))
+}
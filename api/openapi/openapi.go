@@ -0,0 +1,257 @@
+// Package openapi builds OpenAPI 3 documents, deriving the schemas of request and response
+// bodies from the Go models they are encoded from.
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+const Version = "3.0.3"
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem maps lowercase HTTP methods to the operation serving them.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	// Security lists the alternative ways to authenticate, empty for public operations.
+	Security []SecurityRequirement `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // "path", "query" or "header"
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme,omitempty"`
+	In     string `json:"in,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+// SecurityRequirement maps the names of security schemes to their scopes.
+type SecurityRequirement map[string][]string
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+}
+
+// Ref returns a schema referring to the component schema name.
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// JSON returns the content of a JSON body of schema.
+func JSON(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
+
+// Builder collects operations and the component schemas of the models they use.
+type Builder struct {
+	doc Document
+}
+
+func NewBuilder(info Info) *Builder {
+	return &Builder{doc: Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas:         map[string]*Schema{},
+			SecuritySchemes: map[string]SecurityScheme{},
+		},
+	}}
+}
+
+// SecurityScheme adds a security scheme operations can list in their Security.
+func (b *Builder) SecurityScheme(name string, scheme SecurityScheme) {
+	b.doc.Components.SecuritySchemes[name] = scheme
+}
+
+// Add adds op as the operation serving method on path, a chi route pattern such as
+// "/shortLinks/{path}".
+func (b *Builder) Add(method, path string, op *Operation) {
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = PathItem{}
+		b.doc.Paths[path] = item
+	}
+	item[strings.ToLower(method)] = op
+}
+
+// Document returns the document built so far.
+func (b *Builder) Document() *Document {
+	return &b.doc
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Schema returns the schema of the JSON encoding of v's type. Named struct types become
+// component schemas, referred to by their Go name; fields are named and made optional as their
+// json tags say, and embedded structs are inlined like encoding/json does.
+func (b *Builder) Schema(v any) *Schema {
+	return b.schemaOf(reflect.TypeOf(v))
+}
+
+func (b *Builder) schemaOf(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		if _, ok := b.doc.Components.Schemas[t.Name()]; !ok {
+			// Registered before the fields so recursive types refer to themselves.
+			b.doc.Components.Schemas[t.Name()] = &Schema{}
+			*b.doc.Components.Schemas[t.Name()] = *b.structSchema(t)
+		}
+		return Ref(t.Name())
+	}
+	// Interfaces: any JSON value.
+	return &Schema{}
+}
+
+func (b *Builder) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	b.addFields(schema, t, false)
+	return schema
+}
+
+// addFields adds the fields of struct type t to schema. With optional, as for fields of an
+// embedded pointer that may be nil, none is required.
+func (b *Builder) addFields(schema *Schema, t reflect.Type, optional bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			b.addFields(schema, fieldType, optional || field.Type.Kind() == reflect.Pointer)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldSchema := b.schemaOf(field.Type)
+		if hasOption(opts, "string") {
+			fieldSchema = &Schema{Type: "string", Format: fieldSchema.Format}
+		}
+		schema.Properties[name] = fieldSchema
+		if !optional && !hasOption(opts, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+func hasOption(opts, option string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testBase struct {
+	ID int64 `json:"id,string"`
+}
+
+type testExtra struct {
+	Note string `json:"note"`
+}
+
+type testLink struct {
+	testBase
+	*testExtra
+	Link      string            `json:"link"`
+	Title     string            `json:"title,omitempty"`
+	Expires   *time.Time        `json:"expires"`
+	Created   time.Time         `json:"created"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Aliases   []testAlias       `json:"aliases"`
+	Parent    *testLink         `json:"parent,omitempty"`
+	Internal  string            `json:"-"`
+	unexposed string
+}
+
+type testAlias struct {
+	Path string `json:"path"`
+}
+
+func TestSchema(t *testing.T) {
+	b := NewBuilder(Info{Title: "test", Version: "1"})
+
+	assert.Equal(t, Ref("testLink"), b.Schema(testLink{}))
+	assert.Equal(t, Ref("testLink"), b.Schema(&testLink{}))
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Type: "string"}}, b.Schema([]string{}))
+
+	schemas := b.Document().Components.Schemas
+	assert.Equal(t, &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"id":      {Type: "string", Format: "int64"},
+			"note":    {Type: "string"},
+			"link":    {Type: "string"},
+			"title":   {Type: "string"},
+			"expires": {Type: "string", Format: "date-time"},
+			"created": {Type: "string", Format: "date-time"},
+			"labels":  {Type: "object", AdditionalProperties: &Schema{Type: "string"}},
+			"aliases": {Type: "array", Items: Ref("testAlias")},
+			"parent":  Ref("testLink"),
+		},
+		Required: []string{"id", "link", "created", "aliases"},
+	}, schemas["testLink"])
+	assert.Equal(t, &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{"path": {Type: "string"}},
+		Required:   []string{"path"},
+	}, schemas["testAlias"])
+	assert.Len(t, schemas, 2)
+}
+
+func TestAdd(t *testing.T) {
+	b := NewBuilder(Info{Title: "test", Version: "1"})
+	get := &Operation{OperationID: "getLink"}
+	del := &Operation{OperationID: "deleteLink"}
+	b.Add(http.MethodGet, "/links/{path}", get)
+	b.Add(http.MethodDelete, "/links/{path}", del)
+
+	doc := b.Document()
+	assert.Equal(t, Version, doc.OpenAPI)
+	assert.Equal(t, PathItem{"get": get, "delete": del}, doc.Paths["/links/{path}"])
+}
@@ -0,0 +1,295 @@
+package api
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/openapi"
+	"durable-links-generator/buildinfo"
+	"durable-links-generator/config"
+
+	"github.com/rs/zerolog/log"
+)
+
+type OpenAPIHandler interface {
+	Spec(w http.ResponseWriter, r *http.Request)
+	SwaggerUI(w http.ResponseWriter, r *http.Request)
+}
+
+type openAPIHandler struct {
+	// spec is the encoded document, built once as it only changes with the build.
+	spec []byte
+}
+
+func NewOpenAPIHandler(cfg *config.Config) (OpenAPIHandler, error) {
+	spec, err := json.Marshal(newOpenAPIDocument(cfg))
+	if err != nil {
+		return nil, err
+	}
+	return &openAPIHandler{spec: spec}, nil
+}
+
+// Spec serves "GET /openapi.json".
+func (h *openAPIHandler) Spec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(h.spec)
+}
+
+// SwaggerUI serves "GET /v1/docs", a Swagger UI page of the spec. The page loads Swagger UI
+// itself from swaggerUIAssetsURL.
+func (h *openAPIHandler) SwaggerUI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := pageTemplates.ExecuteTemplate(w, "swagger_ui.html", swaggerUIPage{
+		AssetsURL: template.URL(swaggerUIAssetsURL),
+		SpecURL:   "/openapi.json",
+	}); err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to render Swagger UI")
+	}
+}
+
+// The Swagger UI release the docs page loads.
+const swaggerUIAssetsURL = "https://unpkg.com/swagger-ui-dist@5.17.14"
+
+type swaggerUIPage struct {
+	AssetsURL template.URL
+	SpecURL   string
+}
+
+var (
+	errorResponses = map[string]string{
+		"400": "The request is invalid.",
+		"401": "The API key is missing.",
+		"403": "The API key or policy doesn't allow the request.",
+		"404": "The link doesn't exist.",
+		"409": "A request with the same Idempotency-Key is still running, retry after the Retry-After delay.",
+		"410": "The link was deleted or has expired.",
+		"413": "The request body is larger than 1 MiB.",
+		"422": "The Idempotency-Key was already sent with a different request.",
+		"429": "Too many requests, retry after the Retry-After delay.",
+		"500": "The request failed.",
+	}
+	hostParam = openapi.Parameter{
+		Name:        "host",
+		In:          "query",
+		Description: "Domain of the link.",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	pathParam = openapi.Parameter{
+		Name:        "path",
+		In:          "path",
+		Description: "Path of the short link, or of an alias of it.",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	idempotencyKeyParam = openapi.Parameter{
+		Name:        "Idempotency-Key",
+		In:          "header",
+		Description: "Unique key of the request, up to 255 characters. Sending it again replays the first response instead of creating another link.",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	fieldsParam = openapi.Parameter{
+		Name:        "fields",
+		In:          "query",
+		Description: "Comma separated fields to return of each item, all of them by default.",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	adminKey = []openapi.SecurityRequirement{{"apiKey": {}}, {"bearer": {}}}
+)
+
+// responses returns the responses of an operation: ok as the 200 response, or as 204 without a
+// body when ok is nil, and the error responses with the given codes.
+func responses(b *openapi.Builder, ok any, errorCodes ...string) map[string]openapi.Response {
+	resps := map[string]openapi.Response{}
+	if ok == nil {
+		resps["204"] = openapi.Response{Description: "Done."}
+	} else {
+		resps["200"] = openapi.Response{Description: "OK.", Content: openapi.JSON(b.Schema(ok))}
+	}
+	for _, code := range errorCodes {
+		resps[code] = openapi.Response{
+			Description: errorResponses[code],
+			Content:     openapi.JSON(b.Schema(models.ErrorResponse{})),
+		}
+	}
+	return resps
+}
+
+func jsonBody(schema *openapi.Schema) *openapi.RequestBody {
+	return &openapi.RequestBody{Required: true, Content: openapi.JSON(schema)}
+}
+
+func queryParam(name, description string, schema *openapi.Schema) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+// newOpenAPIDocument describes the link API clients are generated for: creating, exchanging,
+// listing and managing links. Redirects, pages and the domain admin API are left out.
+func newOpenAPIDocument(cfg *config.Config) *openapi.Document {
+	version, _, _ := buildinfo.Info()
+	b := openapi.NewBuilder(openapi.Info{
+		Title:       "Durable Links API",
+		Description: "Creates and resolves durable short links, compatible with the Firebase Dynamic Links REST API.",
+		Version:     version,
+	})
+	b.SecurityScheme("apiKey", openapi.SecurityScheme{Type: "apiKey", In: "header", Name: "X-API-Key"})
+	b.SecurityScheme("bearer", openapi.SecurityScheme{Type: "http", Scheme: "bearer"})
+
+	str := &openapi.Schema{Type: "string"}
+	dateTime := &openapi.Schema{Type: "string", Format: "date-time"}
+	positive := &openapi.Schema{Type: "integer", Format: "int32"}
+
+	// Creating links takes a key unless PUBLIC_LINK_CREATION is set.
+	createSecurity, createErrors := adminKey, []string{"400", "401", "403", "409", "413", "422", "429", "500"}
+	if cfg.App.PublicLinkCreation {
+		createSecurity, createErrors = nil, []string{"400", "403", "409", "413", "422", "429", "500"}
+	}
+
+	// POST /shortLinks takes either form of the Firebase request body.
+	createBody := &openapi.Schema{OneOf: []*openapi.Schema{
+		b.Schema(models.CreateDurableLinkRequest{}),
+		b.Schema(models.ShortenLinkRequest{}),
+	}}
+	b.Add(http.MethodPost, "/shortLinks", &openapi.Operation{
+		OperationID: "createShortLink",
+		Summary:     "Create a short link",
+		Tags:        []string{"links"},
+		Parameters: []openapi.Parameter{
+			idempotencyKeyParam,
+			queryParam("validateDestination", "Request the destination first, and warn if it fails, answers with an error or redirects off the allow list.", &openapi.Schema{Type: "boolean"}),
+		},
+		RequestBody: jsonBody(createBody),
+		Responses:   responses(b, models.ShortLinkResponse{}, createErrors...),
+		Security:    createSecurity,
+	})
+	b.Add(http.MethodPost, "/shortLinks:batch", &openapi.Operation{
+		OperationID: "createShortLinks",
+		Summary:     "Create short links in one call",
+		Description: "Requests that fail don't fail the call, their result holds the error instead.",
+		Tags:        []string{"links"},
+		Parameters:  []openapi.Parameter{idempotencyKeyParam},
+		RequestBody: jsonBody(&openapi.Schema{
+			Type:       "object",
+			Properties: map[string]*openapi.Schema{"requests": {Type: "array", Items: createBody}},
+			Required:   []string{"requests"},
+		}),
+		Responses: responses(b, models.BatchCreateLinksResponse{}, createErrors...),
+		Security:  createSecurity,
+	})
+	b.Add(http.MethodPost, "/exchangeShortLink", &openapi.Operation{
+		OperationID: "exchangeShortLink",
+		Summary:     "Exchange a short link for its long link",
+		Tags:        []string{"links"},
+		RequestBody: jsonBody(b.Schema(models.ExchangeShortLinkRequest{})),
+		Responses:   responses(b, models.LongLinkResponse{}, "400", "403", "404", "410", "429", "500"),
+	})
+
+	b.Add(http.MethodGet, "/shortLinks", &openapi.Operation{
+		OperationID: "listShortLinks",
+		Summary:     "List the links of a domain",
+		Tags:        []string{"admin"},
+		Parameters: []openapi.Parameter{
+			hostParam,
+			queryParam("sort", `Sort field, "createdAt", "clickCount", "lastClickedAt" or "expiresAt", prefixed with "-" for descending order. Links that never expire sort last. Defaults to "-createdAt".`, str),
+			queryParam("pageSize", "Links per page.", positive),
+			queryParam("pageToken", "nextPageToken of the previous page.", str),
+			queryParam("createdAfter", "Inclusive lower bound of the creation time.", dateTime),
+			queryParam("createdBefore", "Exclusive upper bound of the creation time.", dateTime),
+			queryParam("suffix", "Kind of path: SHORT or UNGUESSABLE.", &openapi.Schema{Type: "string", Enum: []string{"SHORT", "UNGUESSABLE"}}),
+			queryParam("destinationDomain", "Only links to this domain or its subdomains.", str),
+			queryParam("tag", "Only links with this tag. Repeat it for links with all of the tags.", &openapi.Schema{Type: "array", Items: str}),
+			fieldsParam,
+		},
+		Responses: responses(b, models.ListLinksResponse{}, "400", "401", "403", "500"),
+		Security:  adminKey,
+	})
+	b.Add(http.MethodGet, "/v1/search", &openapi.Operation{
+		OperationID: "searchLinks",
+		Summary:     "Search links by destination, social tags, deep link route or path",
+		Tags:        []string{"admin"},
+		Parameters: []openapi.Parameter{
+			{Name: "q", In: "query", Description: "Search query.", Required: true, Schema: str},
+			queryParam("host", "Domain to search, all of them by default.", str),
+			queryParam("sort", "Result order.", &openapi.Schema{Type: "string", Enum: []string{models.SortRelevance, models.SortNewest, models.SortOldest}}),
+			queryParam("limit", "Maximum number of results.", positive),
+			fieldsParam,
+		},
+		Responses: responses(b, models.SearchResponse{}, "400", "401", "403", "500"),
+		Security:  adminKey,
+	})
+
+	etag := map[string]openapi.Header{"ETag": {Description: "Version of the link, for If-Match.", Schema: str}}
+	getLink := responses(b, models.StoredLinkResponse{}, "400", "401", "403", "404", "500")
+	getLink["200"] = openapi.Response{Description: "OK.", Headers: etag, Content: getLink["200"].Content}
+	b.Add(http.MethodGet, "/shortLinks/{path}", &openapi.Operation{
+		OperationID: "getShortLink",
+		Summary:     "Get a link",
+		Tags:        []string{"admin"},
+		Parameters:  []openapi.Parameter{pathParam, hostParam},
+		Responses:   getLink,
+		Security:    adminKey,
+	})
+	updateLink := responses(b, models.StoredLinkResponse{}, "400", "401", "403", "404", "429", "500")
+	updateLink["200"] = getLink["200"]
+	updateLink["412"] = openapi.Response{
+		Description: "The link changed since the If-Match ETag was read.",
+		Content:     openapi.JSON(b.Schema(models.ErrorResponse{})),
+	}
+	b.Add(http.MethodPatch, "/shortLinks/{path}", &openapi.Operation{
+		OperationID: "updateShortLink",
+		Summary:     "Update a link's destination, fallbacks, social tags or metadata",
+		Description: "Only the fields present change, and an empty string removes a fallback, social tag or metadata field.",
+		Tags:        []string{"admin"},
+		Parameters: []openapi.Parameter{
+			pathParam,
+			hostParam,
+			{Name: "If-Match", In: "header", Description: "ETag the update is conditional on.", Schema: str},
+		},
+		RequestBody: jsonBody(b.Schema(models.UpdateLinkRequest{})),
+		Responses:   updateLink,
+		Security:    adminKey,
+	})
+	b.Add(http.MethodDelete, "/shortLinks/{path}", &openapi.Operation{
+		OperationID: "deleteShortLink",
+		Summary:     "Delete a link",
+		Description: "The link is soft deleted, and can be restored, unless hard is true.",
+		Tags:        []string{"admin"},
+		Parameters: []openapi.Parameter{
+			pathParam,
+			hostParam,
+			queryParam("hard", "Purge the link instead.", &openapi.Schema{Type: "boolean"}),
+		},
+		Responses: responses(b, nil, "400", "401", "403", "404", "500"),
+		Security:  adminKey,
+	})
+	b.Add(http.MethodGet, "/shortLinks/{path}/stats", &openapi.Operation{
+		OperationID: "getShortLinkStats",
+		Summary:     "Get a link's click statistics",
+		Tags:        []string{"admin"},
+		Parameters: []openapi.Parameter{
+			pathParam,
+			hostParam,
+			queryParam("from", "Start of the range.", dateTime),
+			queryParam("to", "End of the range, now by default.", dateTime),
+			queryParam("durationDays", "Days before to, without from. Defaults to 7.", positive),
+			queryParam("granularity", "Bucket size, day by default.", &openapi.Schema{Type: "string", Enum: []string{"hour", "day", "week", "month"}}),
+		},
+		Responses: responses(b, models.LinkStatsResponse{}, "400", "401", "403", "404", "500"),
+		Security:  adminKey,
+	})
+
+	b.Add(http.MethodGet, "/v1/version", &openapi.Operation{
+		OperationID: "getVersion",
+		Summary:     "Get the server's version and enabled features",
+		Tags:        []string{"meta"},
+		Responses:   responses(b, models.VersionResponse{}),
+	})
+
+	doc := b.Document()
+	for _, domain := range cfg.App.Domains {
+		doc.Servers = append(doc.Servers, openapi.Server{URL: "https://" + domain})
+	}
+	return doc
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var pageTemplates = template.Must(template.ParseFS(templateFS, "templates/*.html"))
+
+type interstitialPage struct {
+	Gate            string
+	MinAge          int
+	TermsURL        string
+	DestinationHost string
+	Action          string
+	NoJSURL         string
+}
+
+// desktopAppPage links use template.URL because custom protocols would otherwise be replaced
+// by html/template; their schemes were checked when the link was created.
+type desktopAppPage struct {
+	ProtocolLink    template.URL
+	StoreLink       template.URL
+	StoreName       string
+	Destination     string
+	DestinationHost string
+}
+
+type qrCodePage struct {
+	ShortLink       string
+	QRCode          template.URL
+	DestinationHost string
+	Title           string
+	Description     string
+	ImageURL        string
+}
+
+type socialPreviewPage struct {
+	ShortLink       string
+	Destination     string
+	DestinationHost string
+	Title           string
+	Description     string
+	ImageURL        string
+}
+
+// previewPage is also what the templates configured with PREVIEW_PAGE_TEMPLATES are executed with.
+// ShortLink is the link on its main host, which the Continue button should lead to.
+type previewPage struct {
+	ShortLink          string
+	Destination        string
+	DestinationHost    string
+	Title              string
+	Description        string
+	ImageURL           string
+	PlayStoreLink      string
+	AppStoreLink       string
+	AndroidPackageName string
+}
+
+type scannerPreviewPage struct {
+	Destination     string
+	DestinationHost string
+}
+
+// Browsers without JavaScript support. Everyone else is switched to the no-JS variant by a
+// meta refresh inside <noscript>.
+var textBrowsers = []string{"lynx", "w3m", "links", "elinks", "browsh"}
+
+// pageVariant returns the accessible no-JS variant of a page when the client asked for it or is
+// known not to run scripts.
+func pageVariant(r *http.Request, name string) string {
+	if r.URL.Query().Get("nojs") == "1" {
+		return strings.TrimSuffix(name, ".html") + "_nojs.html"
+	}
+
+	ua := strings.ToLower(r.UserAgent())
+	for _, b := range textBrowsers {
+		if strings.HasPrefix(ua, b) {
+			return strings.TrimSuffix(name, ".html") + "_nojs.html"
+		}
+	}
+	return name
+}
+
+// noJSURL returns the current URL with nojs=1 added.
+func noJSURL(r *http.Request) string {
+	query := r.URL.Query()
+	query.Set("nojs", "1")
+	return r.URL.Path + "?" + query.Encode()
+}
+
+// loadPreviewTemplates parses the preview page template of each host that has its own.
+func loadPreviewTemplates(files map[string]string) (map[string]*template.Template, error) {
+	templates := make(map[string]*template.Template, len(files))
+	for host, file := range files {
+		t, err := template.ParseFiles(file)
+		if err != nil {
+			return nil, fmt.Errorf("preview page template for %s: %w", host, err)
+		}
+		templates[host] = t
+	}
+	return templates, nil
+}
+
+// qrCodeImage encodes content as a PNG QR code data URI for an <img> tag.
+func qrCodeImage(content string) (template.URL, error) {
+	png, err := qrcode.Encode(content, qrcode.Medium, 320)
+	if err != nil {
+		return "", err
+	}
+	return template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png)), nil
+}
+
+// renderPage executes the named template into a buffer first so a template error never leaves a
+// half-written page behind.
+func renderPage(w http.ResponseWriter, status int, name string, data any) {
+	renderTemplate(w, status, pageTemplates.Lookup(name), data)
+}
+
+// renderTemplate is renderPage for a template outside the embedded set.
+func renderTemplate(w http.ResponseWriter, status int, t *template.Template, data any) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		requestLog(w).Error().Err(err).Str("template", t.Name()).Msg("Failed to render page")
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
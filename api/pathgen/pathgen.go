@@ -0,0 +1,99 @@
+package pathgen
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"durable-links-generator/config"
+)
+
+// PathGenerator picks the path a new link is stored under.
+type PathGenerator interface {
+	Generate(ctx context.Context, req Request) (string, error)
+}
+
+type Request struct {
+	Host        string
+	QueryParams string
+	// Length is the configured path length for the kind of link requested.
+	Length      int
+	Unguessable bool
+	// Attempt counts from 0 and goes up when a generated path was already taken, so
+	// deterministic strategies can derive a different path.
+	Attempt int
+}
+
+// Sequence hands out increasing numbers shared by all instances.
+type Sequence interface {
+	NextPathSequence(ctx context.Context) (int64, error)
+}
+
+// Factory builds a generator from the config. seq is the database sequence, for strategies that
+// need one.
+type Factory func(cfg *config.Config, seq Sequence) (PathGenerator, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{}
+)
+
+// Register makes a strategy selectable through PATH_GENERATOR. Custom strategies register
+// themselves from an init function in a package imported by the build.
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[strings.ToLower(name)] = factory
+}
+
+// Names returns the registered strategies.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// New builds the strategy named by PATH_GENERATOR, "random" when it's not set.
+func New(cfg *config.Config, seq Sequence) (PathGenerator, error) {
+	name := strings.ToLower(strings.TrimSpace(cfg.App.PathGenerator))
+	if name == "" {
+		name = "random"
+	}
+
+	mu.RLock()
+	factory, ok := factories[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown path generator %q, available: %s", name, strings.Join(Names(), ", "))
+	}
+	return factory(cfg, seq)
+}
+
+func init() {
+	Register("random", func(*config.Config, Sequence) (PathGenerator, error) {
+		return Random{}, nil
+	})
+	Register("sequence", func(_ *config.Config, seq Sequence) (PathGenerator, error) {
+		if seq == nil {
+			return nil, fmt.Errorf("sequence path generator needs a database sequence")
+		}
+		return &SequenceGenerator{seq: seq}, nil
+	})
+	Register("hash", func(*config.Config, Sequence) (PathGenerator, error) {
+		return Hash{}, nil
+	})
+	Register("hmac", func(cfg *config.Config, _ Sequence) (PathGenerator, error) {
+		if cfg.App.PathGeneratorSecret == "" {
+			return nil, fmt.Errorf("hmac path generator needs PATH_GENERATOR_SECRET")
+		}
+		return Hash{Key: cfg.App.PathGeneratorSecret}, nil
+	})
+}
@@ -0,0 +1,95 @@
+package pathgen
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"durable-links-generator/config"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+	os.Exit(m.Run())
+}
+
+type fakeSequence struct {
+	next int64
+}
+
+func (f *fakeSequence) NextPathSequence(context.Context) (int64, error) {
+	f.next++
+	return f.next, nil
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		generator string
+		secret    string
+		want      PathGenerator
+		wantErr   bool
+	}{
+		{name: "Default is random", want: Random{}},
+		{name: "Hash", generator: "hash", want: Hash{}},
+		{name: "HMAC", generator: "HMAC", secret: "s3cret", want: Hash{Key: "s3cret"}},
+		{name: "HMAC without secret", generator: "hmac", wantErr: true},
+		{name: "Unknown", generator: "uuid", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{App: &config.AppConfig{PathGenerator: tt.generator, PathGeneratorSecret: tt.secret}}
+			got, err := New(cfg, &fakeSequence{})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSequenceGenerator(t *testing.T) {
+	g := &SequenceGenerator{seq: &fakeSequence{next: 60}}
+	ctx := context.Background()
+
+	path, err := g.Generate(ctx, Request{Length: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, "000Z", path)
+
+	path, err = g.Generate(ctx, Request{Length: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "10", path)
+
+	path, err = g.Generate(ctx, Request{Length: 10, Unguessable: true})
+	assert.NoError(t, err)
+	assert.Len(t, path, 10)
+}
+
+func TestHash(t *testing.T) {
+	ctx := context.Background()
+	req := Request{Host: "example.page.link", QueryParams: "link=https%3A%2F%2Fexample.com", Length: 6}
+
+	first, _ := Hash{}.Generate(ctx, req)
+	again, _ := Hash{}.Generate(ctx, req)
+	assert.Len(t, first, 6)
+	assert.Equal(t, first, again)
+
+	keyed, _ := Hash{Key: "s3cret"}.Generate(ctx, req)
+	assert.NotEqual(t, first, keyed)
+
+	req.Attempt = 1
+	retry, _ := Hash{}.Generate(ctx, req)
+	assert.NotEqual(t, first, retry)
+}
+
+func TestSMSSafe(t *testing.T) {
+	path, err := SMSSafe{}.Generate(context.Background(), Request{Length: 8})
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[2-9a-hjkmnp-z]{8}$`, path)
+}
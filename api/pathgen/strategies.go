@@ -0,0 +1,98 @@
+package pathgen
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"durable-links-generator/utils"
+)
+
+const base62 = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// Random draws every character from crypto/rand. This is the default and the only strategy
+// used for unguessable links.
+type Random struct{}
+
+func (Random) Generate(_ context.Context, req Request) (string, error) {
+	return utils.GenerateRandomAlphanumericString(req.Length), nil
+}
+
+// SMSSafe draws random characters that survive SMS gateways: lower case letters and digits only,
+// so case folding can't break the link, without the look-alikes 0, o, 1, i and l for people
+// retyping it. Used for links created with the SMS-safe option whatever the configured strategy.
+type SMSSafe struct{}
+
+const smsSafeAlphabet = "23456789abcdefghjkmnpqrstuvwxyz"
+
+func (SMSSafe) Generate(_ context.Context, req Request) (string, error) {
+	b := make([]byte, req.Length)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(smsSafeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		b[i] = smsSafeAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
+
+// SequenceGenerator encodes the next value of a database sequence in base62, zero padded to the
+// configured length. Paths are short and never collide but reveal how many links exist, so
+// unguessable links still get random paths.
+type SequenceGenerator struct {
+	seq Sequence
+}
+
+func (g *SequenceGenerator) Generate(ctx context.Context, req Request) (string, error) {
+	if req.Unguessable {
+		return Random{}.Generate(ctx, req)
+	}
+
+	n, err := g.seq.NextPathSequence(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	path := encodeBase62(uint64(n))
+	if pad := req.Length - len(path); pad > 0 {
+		path = strings.Repeat("0", pad) + path
+	}
+	return path, nil
+}
+
+func encodeBase62(n uint64) string {
+	if n == 0 {
+		return "0"
+	}
+	var b []byte
+	for n > 0 {
+		b = append(b, base62[n%62])
+		n /= 62
+	}
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
+
+// Hash derives the path from the host and query params, so recreating a link on another
+// instance or after a restore yields the same path. With a Key it is an HMAC and paths can't be
+// predicted without the secret. Unguessable links get random paths.
+type Hash struct {
+	Key string
+}
+
+func (h Hash) Generate(ctx context.Context, req Request) (string, error) {
+	if req.Unguessable {
+		return Random{}.Generate(ctx, req)
+	}
+
+	message := req.Host + "\n" + req.QueryParams
+	if req.Attempt > 0 {
+		message += "\n" + strconv.Itoa(req.Attempt)
+	}
+	return utils.DeterministicAlphanumericString(h.Key, message, req.Length), nil
+}
@@ -0,0 +1,104 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"durable-links-generator/config"
+)
+
+func init() {
+	Register("utm_naming", newUTMNaming)
+	Register("takedown", newTakedown)
+}
+
+// utmNaming lowercases UTM values so reports don't split "Email" and "email", and warns about
+// campaign names not matching POLICY_UTM_CAMPAIGN_PATTERN.
+type utmNaming struct {
+	campaignPattern *regexp.Regexp
+}
+
+func newUTMNaming(cfg *config.Config) (Hook, error) {
+	h := &utmNaming{}
+	if cfg.App.PolicyUTMCampaignPattern != "" {
+		re, err := regexp.Compile(cfg.App.PolicyUTMCampaignPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid POLICY_UTM_CAMPAIGN_PATTERN: %w", err)
+		}
+		h.campaignPattern = re
+	}
+	return h, nil
+}
+
+func (h *utmNaming) BeforeCreate(_ context.Context, link *Link) Decision {
+	for key, values := range link.Params {
+		if !strings.HasPrefix(key, "utm_") {
+			continue
+		}
+		for i, v := range values {
+			values[i] = strings.ToLower(v)
+		}
+	}
+
+	campaign := link.Params.Get("utm_campaign")
+	if h.campaignPattern != nil && campaign != "" && !h.campaignPattern.MatchString(campaign) {
+		return Warn(fmt.Sprintf("utm_campaign %q doesn't follow the naming convention", campaign))
+	}
+	return Allow()
+}
+
+func (h *utmNaming) BeforeResolve(context.Context, *Link) Decision {
+	return Allow()
+}
+
+// takedown stops links listed in POLICY_TAKEDOWN_LINKS ("host/path") from resolving, and
+// destinations on POLICY_TAKEDOWN_DESTINATIONS hosts from being created or resolved.
+type takedown struct {
+	links        map[string]bool
+	destinations map[string]bool
+}
+
+func newTakedown(cfg *config.Config) (Hook, error) {
+	h := &takedown{links: map[string]bool{}, destinations: map[string]bool{}}
+	for _, l := range cfg.App.PolicyTakedownLinks {
+		h.links[strings.ToLower(strings.TrimSpace(l))] = true
+	}
+	for _, d := range cfg.App.PolicyTakedownDestinations {
+		h.destinations[strings.ToLower(strings.TrimSpace(d))] = true
+	}
+	return h, nil
+}
+
+func (h *takedown) BeforeCreate(_ context.Context, link *Link) Decision {
+	if h.destinationTakenDown(link) {
+		return Deny("destination has been taken down")
+	}
+	return Allow()
+}
+
+func (h *takedown) BeforeResolve(_ context.Context, link *Link) Decision {
+	if h.links[strings.ToLower(link.Host)+"/"+link.Path] || h.destinationTakenDown(link) {
+		return Deny("link has been taken down")
+	}
+	return Allow()
+}
+
+func (h *takedown) destinationTakenDown(link *Link) bool {
+	for _, key := range []string{"link", "afl", "ifl", "ipfl", "ofl"} {
+		if host := hostOf(link.Params.Get(key)); host != "" && h.destinations[host] {
+			return true
+		}
+	}
+	return false
+}
+
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
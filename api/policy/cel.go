@@ -0,0 +1,185 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"durable-links-generator/config"
+
+	"github.com/google/cel-go/cel"
+	"github.com/rs/zerolog/log"
+)
+
+// Limits keeping a misbehaving expression from slowing down or blowing up link creation.
+const (
+	celMaxExpressionLength  = 2048
+	celMaxDestinationLength = 4096
+	celCostLimit            = 10000
+	celEvalTimeout          = 10 * time.Millisecond
+)
+
+func init() {
+	Register("cel", newCELHook)
+}
+
+// CELRule is one rule from POLICY_CEL_FILE. Reject is a bool expression refusing the link when
+// true, Rewrite a string expression whose result replaces the destination.
+type CELRule struct {
+	Name    string `json:"name"`
+	Reject  string `json:"reject,omitempty"`
+	Rewrite string `json:"rewrite,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// CELFile maps a project's short link host to its rules; "*" applies to every project.
+type CELFile struct {
+	Projects map[string][]CELRule `json:"projects"`
+}
+
+type celRule struct {
+	CELRule
+	reject  cel.Program
+	rewrite cel.Program
+}
+
+// celHook runs per project CEL expressions before links are created. Expressions see host,
+// link and params (the link's query params as a map of strings).
+type celHook struct {
+	projects map[string][]celRule
+}
+
+func newCELHook(cfg *config.Config) (Hook, error) {
+	if cfg.App.PolicyCELFile == "" {
+		return nil, errors.New("POLICY_CEL_FILE is not set")
+	}
+	data, err := os.ReadFile(cfg.App.PolicyCELFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var file CELFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid POLICY_CEL_FILE: %w", err)
+	}
+	return compileCEL(file)
+}
+
+func compileCEL(file CELFile) (*celHook, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("host", cel.StringType),
+		cel.Variable("link", cel.StringType),
+		cel.Variable("params", cel.MapType(cel.StringType, cel.StringType)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	compile := func(expr string, want *cel.Type) (cel.Program, error) {
+		if len(expr) > celMaxExpressionLength {
+			return nil, fmt.Errorf("expression longer than %d characters", celMaxExpressionLength)
+		}
+		ast, issues := env.Compile(expr)
+		if issues != nil && issues.Err() != nil {
+			return nil, issues.Err()
+		}
+		if !ast.OutputType().IsExactType(want) {
+			return nil, fmt.Errorf("expression must return %s, got %s", want, ast.OutputType())
+		}
+		return env.Program(ast,
+			cel.CostLimit(celCostLimit),
+			cel.InterruptCheckFrequency(100),
+		)
+	}
+
+	hook := &celHook{projects: map[string][]celRule{}}
+	for project, rules := range file.Projects {
+		for i, rule := range rules {
+			if rule.Name == "" {
+				rule.Name = fmt.Sprintf("%s#%d", project, i+1)
+			}
+			compiled := celRule{CELRule: rule}
+			if rule.Reject != "" {
+				if compiled.reject, err = compile(rule.Reject, cel.BoolType); err != nil {
+					return nil, fmt.Errorf("rule %s: reject: %w", rule.Name, err)
+				}
+			}
+			if rule.Rewrite != "" {
+				if compiled.rewrite, err = compile(rule.Rewrite, cel.StringType); err != nil {
+					return nil, fmt.Errorf("rule %s: rewrite: %w", rule.Name, err)
+				}
+			}
+			hook.projects[strings.ToLower(project)] = append(hook.projects[strings.ToLower(project)], compiled)
+		}
+	}
+	return hook, nil
+}
+
+func (h *celHook) BeforeCreate(ctx context.Context, link *Link) Decision {
+	rules := slices.Concat(h.projects["*"], h.projects[strings.ToLower(link.Host)])
+
+	for _, rule := range rules {
+		if rule.reject != nil {
+			out, err := h.eval(ctx, rule.reject, link)
+			if err != nil {
+				return h.failed(rule, err)
+			}
+			if out == true {
+				message := rule.Message
+				if message == "" {
+					message = "rejected by rule " + rule.Name
+				}
+				return Deny(message)
+			}
+		}
+
+		if rule.rewrite != nil {
+			out, err := h.eval(ctx, rule.rewrite, link)
+			if err != nil {
+				return h.failed(rule, err)
+			}
+			destination, _ := out.(string)
+			if destination == "" || len(destination) > celMaxDestinationLength {
+				return h.failed(rule, fmt.Errorf("rewrite returned an empty or oversized destination"))
+			}
+			link.Params.Set("link", destination)
+		}
+	}
+	return Allow()
+}
+
+func (h *celHook) BeforeResolve(context.Context, *Link) Decision {
+	return Allow()
+}
+
+func (h *celHook) eval(ctx context.Context, prg cel.Program, link *Link) (any, error) {
+	params := make(map[string]string, len(link.Params))
+	for key := range link.Params {
+		params[key] = link.Params.Get(key)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, celEvalTimeout)
+	defer cancel()
+
+	out, _, err := prg.ContextEval(ctx, map[string]any{
+		"host":   link.Host,
+		"link":   link.Params.Get("link"),
+		"params": params,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Value(), nil
+}
+
+// failed denies the link when a rule can't be evaluated: skipping it could let through exactly
+// what the rule was written to stop.
+func (h *celHook) failed(rule celRule, err error) Decision {
+	log.Error().Err(err).Str("rule", rule.Name).Msg("CEL policy rule failed")
+	return Deny("policy rule " + rule.Name + " could not be evaluated")
+}
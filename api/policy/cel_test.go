@@ -0,0 +1,116 @@
+package policy
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCELHook(t *testing.T) {
+	hook, err := compileCEL(CELFile{Projects: map[string][]CELRule{
+		"*": {
+			{Name: "https-only", Reject: `!link.startsWith("https://")`, Message: "destinations must use https"},
+		},
+		"shop.page.link": {
+			{Name: "campaign-required", Reject: `!("utm_campaign" in params)`},
+			{Name: "tag-source", Rewrite: `link + (link.contains("?") ? "&" : "?") + "src=" + host`},
+		},
+	}})
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		host     string
+		params   url.Values
+		wantDeny string
+		wantLink string
+	}{
+		{
+			name:     "Global rule rejects",
+			host:     "other.page.link",
+			params:   url.Values{"link": {"http://example.com"}},
+			wantDeny: "destinations must use https",
+		},
+		{
+			name:     "Other projects are untouched",
+			host:     "other.page.link",
+			params:   url.Values{"link": {"https://example.com"}},
+			wantLink: "https://example.com",
+		},
+		{
+			name:     "Project rule rejects with default message",
+			host:     "shop.page.link",
+			params:   url.Values{"link": {"https://example.com"}},
+			wantDeny: "rejected by rule campaign-required",
+		},
+		{
+			name:     "Project rule rewrites",
+			host:     "Shop.page.link",
+			params:   url.Values{"link": {"https://example.com/?a=1"}, "utm_campaign": {"spring"}},
+			wantLink: "https://example.com/?a=1&src=Shop.page.link",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link := &Link{Host: tt.host, Params: tt.params}
+			decision := hook.BeforeCreate(ctx, link)
+			if tt.wantDeny != "" {
+				assert.True(t, decision.Deny)
+				assert.Equal(t, tt.wantDeny, decision.Reason)
+				return
+			}
+			assert.False(t, decision.Deny)
+			assert.Equal(t, tt.wantLink, link.Params.Get("link"))
+		})
+	}
+}
+
+func TestCELHookCompileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule CELRule
+	}{
+		{name: "Syntax error", rule: CELRule{Reject: `link ==`}},
+		{name: "Reject must be bool", rule: CELRule{Reject: `link`}},
+		{name: "Rewrite must be string", rule: CELRule{Rewrite: `true`}},
+		{name: "Expression too long", rule: CELRule{Reject: strings.Repeat("true && ", 300) + "true"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileCEL(CELFile{Projects: map[string][]CELRule{"*": {tt.rule}}})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCELHookRuntimeErrorDenies(t *testing.T) {
+	hook, err := compileCEL(CELFile{Projects: map[string][]CELRule{
+		"*": {{Name: "lookup", Reject: `params["missing"] == "x"`}},
+	}})
+	assert.NoError(t, err)
+
+	decision := hook.BeforeCreate(context.Background(), &Link{Params: url.Values{"link": {"https://example.com"}}})
+	assert.True(t, decision.Deny)
+}
+
+func TestNewCELHookFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"projects":{"*":[{"reject":"host == \"blocked.page.link\""}]}}`), 0o600))
+
+	hook, err := newCELHook(&config.Config{App: &config.AppConfig{PolicyCELFile: path}})
+	assert.NoError(t, err)
+	assert.True(t, hook.BeforeCreate(context.Background(), &Link{Host: "blocked.page.link", Params: url.Values{}}).Deny)
+
+	_, err = newCELHook(&config.Config{App: &config.AppConfig{}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,133 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"durable-links-generator/config"
+)
+
+// Link is what a hook sees. Params are the link's query params; a BeforeCreate hook may change
+// them to alter the link that gets stored.
+type Link struct {
+	Host   string
+	Path   string
+	Params url.Values
+}
+
+// Decision is a hook's verdict. The zero value allows the link.
+type Decision struct {
+	Deny     bool
+	Reason   string
+	Warnings []string
+}
+
+func Allow() Decision {
+	return Decision{}
+}
+
+func Deny(reason string) Decision {
+	return Decision{Deny: true, Reason: reason}
+}
+
+func Warn(warnings ...string) Decision {
+	return Decision{Warnings: warnings}
+}
+
+// Hook enforces a custom rule before links are created and before they are resolved.
+type Hook interface {
+	BeforeCreate(ctx context.Context, link *Link) Decision
+	BeforeResolve(ctx context.Context, link *Link) Decision
+}
+
+type Factory func(cfg *config.Config) (Hook, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{}
+)
+
+// Register makes a policy module selectable through POLICY_HOOKS. Custom modules register
+// themselves from an init function in a package imported by the build.
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[strings.ToLower(name)] = factory
+}
+
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Chain runs hooks in the configured order. The first deny wins; warnings are collected from
+// every hook. A nil Chain allows everything.
+type Chain struct {
+	hooks []Hook
+}
+
+// New builds the chain of modules listed in POLICY_HOOKS.
+func New(cfg *config.Config) (*Chain, error) {
+	chain := &Chain{}
+	for _, name := range cfg.App.PolicyHooks {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		mu.RLock()
+		factory, ok := factories[name]
+		mu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("unknown policy hook %q, available: %s", name, strings.Join(Names(), ", "))
+		}
+
+		hook, err := factory(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("policy hook %s: %w", name, err)
+		}
+		chain.hooks = append(chain.hooks, hook)
+	}
+	return chain, nil
+}
+
+func NewChain(hooks ...Hook) *Chain {
+	return &Chain{hooks: hooks}
+}
+
+func (c *Chain) BeforeCreate(ctx context.Context, link *Link) Decision {
+	return c.run(func(h Hook) Decision { return h.BeforeCreate(ctx, link) })
+}
+
+func (c *Chain) BeforeResolve(ctx context.Context, link *Link) Decision {
+	return c.run(func(h Hook) Decision { return h.BeforeResolve(ctx, link) })
+}
+
+func (c *Chain) run(call func(Hook) Decision) Decision {
+	var result Decision
+	if c == nil {
+		return result
+	}
+
+	for _, hook := range c.hooks {
+		decision := call(hook)
+		result.Warnings = append(result.Warnings, decision.Warnings...)
+		if decision.Deny {
+			result.Deny = true
+			result.Reason = decision.Reason
+			return result
+		}
+	}
+	return result
+}
@@ -0,0 +1,116 @@
+package policy
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"testing"
+
+	"durable-links-generator/config"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+	os.Exit(m.Run())
+}
+
+type staticHook struct {
+	create  Decision
+	resolve Decision
+	calls   int
+}
+
+func (h *staticHook) BeforeCreate(context.Context, *Link) Decision {
+	h.calls++
+	return h.create
+}
+
+func (h *staticHook) BeforeResolve(context.Context, *Link) Decision {
+	h.calls++
+	return h.resolve
+}
+
+func TestChain(t *testing.T) {
+	warn := &staticHook{create: Warn("first")}
+	deny := &staticHook{create: Deny("nope")}
+	after := &staticHook{}
+
+	decision := NewChain(warn, deny, after).BeforeCreate(context.Background(), &Link{})
+	assert.True(t, decision.Deny)
+	assert.Equal(t, "nope", decision.Reason)
+	assert.Equal(t, []string{"first"}, decision.Warnings)
+	assert.Zero(t, after.calls, "hooks after a deny don't run")
+
+	var nilChain *Chain
+	assert.False(t, nilChain.BeforeResolve(context.Background(), &Link{}).Deny)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(&config.Config{App: &config.AppConfig{PolicyHooks: []string{"takedown", "unknown"}}})
+	assert.Error(t, err)
+
+	_, err = New(&config.Config{App: &config.AppConfig{PolicyHooks: []string{"utm_naming"}, PolicyUTMCampaignPattern: "("}})
+	assert.Error(t, err)
+
+	chain, err := New(&config.Config{App: &config.AppConfig{PolicyHooks: []string{"UTM_Naming", " takedown"}}})
+	assert.NoError(t, err)
+	assert.Len(t, chain.hooks, 2)
+}
+
+func TestUTMNaming(t *testing.T) {
+	hook, err := newUTMNaming(&config.Config{App: &config.AppConfig{PolicyUTMCampaignPattern: `^[a-z0-9]+(-[a-z0-9]+)*$`}})
+	assert.NoError(t, err)
+
+	link := &Link{Params: url.Values{"utm_source": {"Newsletter"}, "utm_campaign": {"Spring Sale"}, "link": {"https://Example.com"}}}
+	decision := hook.BeforeCreate(context.Background(), link)
+
+	assert.False(t, decision.Deny)
+	assert.Len(t, decision.Warnings, 1)
+	assert.Equal(t, "newsletter", link.Params.Get("utm_source"))
+	assert.Equal(t, "https://Example.com", link.Params.Get("link"), "only UTM values are changed")
+
+	link = &Link{Params: url.Values{"utm_campaign": {"spring-sale"}}}
+	assert.Empty(t, hook.BeforeCreate(context.Background(), link).Warnings)
+}
+
+func TestTakedown(t *testing.T) {
+	hook, err := newTakedown(&config.Config{App: &config.AppConfig{
+		PolicyTakedownLinks:        []string{"Example.page.link/abc"},
+		PolicyTakedownDestinations: []string{"bad.example.com"},
+	}})
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		link        Link
+		denyCreate  bool
+		denyResolve bool
+	}{
+		{
+			name: "Clean link",
+			link: Link{Host: "example.page.link", Path: "xyz", Params: url.Values{"link": {"https://good.example.com"}}},
+		},
+		{
+			name:        "Taken down path",
+			link:        Link{Host: "example.page.link", Path: "abc", Params: url.Values{"link": {"https://good.example.com"}}},
+			denyResolve: true,
+		},
+		{
+			name:        "Taken down fallback destination",
+			link:        Link{Host: "example.page.link", Path: "xyz", Params: url.Values{"link": {"https://good.example.com"}, "afl": {"https://BAD.example.com/app"}}},
+			denyCreate:  true,
+			denyResolve: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.denyCreate, hook.BeforeCreate(ctx, &tt.link).Deny)
+			assert.Equal(t, tt.denyResolve, hook.BeforeResolve(ctx, &tt.link).Deny)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package qr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/image/draw"
+)
+
+// Level is the error correction level of a QR code: the share of the code that can be damaged,
+// or covered by a logo, and still be read.
+type Level int
+
+const (
+	LevelLow      Level = iota // L, 7%
+	LevelMedium                // M, 15%
+	LevelQuartile              // Q, 25%
+	LevelHigh                  // H, 30%
+)
+
+// ParseLevel reads a level by its letter, L, M, Q or H.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "L":
+		return LevelLow, nil
+	case "M":
+		return LevelMedium, nil
+	case "Q":
+		return LevelQuartile, nil
+	case "H":
+		return LevelHigh, nil
+	}
+	return 0, fmt.Errorf("invalid error correction level %q, want L, M, Q or H", s)
+}
+
+// Logo is an image shown in the middle of QR codes.
+type Logo struct {
+	img image.Image
+	// png is img encoded for SVG codes.
+	png []byte
+}
+
+// LoadLogo reads a PNG or JPEG logo, or returns nil if path is empty.
+func LoadLogo(path string) (*Logo, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return NewLogo(img)
+}
+
+func NewLogo(img image.Image) (*Logo, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return &Logo{img: img, png: buf.Bytes()}, nil
+}
+
+// Options of a rendered QR code.
+type Options struct {
+	// Size is the width and height of the code in pixels. Codes with more modules than Size are
+	// drawn larger.
+	Size  int
+	Level Level
+	// Logo, if set, is drawn on a white square in the middle, covering a fifth of the width. The
+	// level is raised to at least LevelQuartile so the code stays readable.
+	Logo *Logo
+}
+
+func (o Options) encode(content string) (*qrcode.QRCode, error) {
+	level := o.Level
+	if o.Logo != nil {
+		level = max(level, LevelQuartile)
+	}
+	// The levels are in the same order as go-qrcode's Low, Medium, High and Highest.
+	return qrcode.New(content, qrcode.RecoveryLevel(level))
+}
+
+// logoBox is where the logo goes in a code of size units, with a white margin around it.
+func logoBox(size float64) (box, logo [4]float64) {
+	side := size / 5
+	margin := side / 10
+	x := (size - side) / 2
+	return [4]float64{x - margin, x - margin, side + 2*margin, side + 2*margin}, [4]float64{x, x, side, side}
+}
+
+// PNG writes content as a QR code PNG.
+func PNG(w io.Writer, content string, opts Options) error {
+	code, err := opts.encode(content)
+	if err != nil {
+		return err
+	}
+	qrImage := code.Image(opts.Size)
+	if opts.Logo == nil {
+		return png.Encode(w, qrImage)
+	}
+
+	bounds := qrImage.Bounds()
+	img := image.NewRGBA(bounds)
+	draw.Draw(img, bounds, qrImage, image.Point{}, draw.Src)
+	box, logo := logoBox(float64(bounds.Dx()))
+	draw.Draw(img, rect(box), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(img, rect(logo), opts.Logo.img, opts.Logo.img.Bounds(), draw.Over, nil)
+	return png.Encode(w, img)
+}
+
+func rect(box [4]float64) image.Rectangle {
+	x, y := int(box[0]), int(box[1])
+	return image.Rect(x, y, x+int(box[2]), y+int(box[3]))
+}
+
+// SVG writes content as a QR code SVG, one path of dark modules over a white background.
+func SVG(w io.Writer, content string, opts Options) error {
+	code, err := opts.encode(content)
+	if err != nil {
+		return err
+	}
+	bitmap := code.Bitmap()
+	n := len(bitmap)
+	size := max(opts.Size, n)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, n, n)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#ffffff"/><path fill="#000000" d="`, n, n)
+	for y, row := range bitmap {
+		// Runs of dark modules are drawn as one rectangle.
+		for x := 0; x < n; x++ {
+			if !row[x] {
+				continue
+			}
+			run := 1
+			for x+run < n && row[x+run] {
+				run++
+			}
+			fmt.Fprintf(&b, "M%d %dh%dv1h-%dz", x, y, run, run)
+			x += run
+		}
+	}
+	b.WriteString(`"/>`)
+	if opts.Logo != nil {
+		box, logo := logoBox(float64(n))
+		fmt.Fprintf(&b, `<rect x="%g" y="%g" width="%g" height="%g" fill="#ffffff"/>`, box[0], box[1], box[2], box[3])
+		fmt.Fprintf(&b, `<image x="%g" y="%g" width="%g" height="%g" href="data:image/png;base64,%s"/>`,
+			logo[0], logo[1], logo[2], logo[3], base64.StdEncoding.EncodeToString(opts.Logo.png))
+	}
+	b.WriteString("</svg>")
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
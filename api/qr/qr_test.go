@@ -0,0 +1,59 @@
+package qr
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testLink = "https://acme.link/abc"
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("q")
+	assert.NoError(t, err)
+	assert.Equal(t, LevelQuartile, level)
+
+	_, err = ParseLevel("X")
+	assert.Error(t, err)
+}
+
+func TestPNG(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, PNG(&buf, testLink, Options{Size: 256, Level: LevelMedium}))
+	img, err := png.Decode(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 256, 256), img.Bounds())
+
+	red := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for i := 0; i < len(red.Pix); i += 4 {
+		red.Pix[i], red.Pix[i+3] = 0xff, 0xff
+	}
+	logo, err := NewLogo(red)
+	assert.NoError(t, err)
+	buf.Reset()
+	assert.NoError(t, PNG(&buf, testLink, Options{Size: 256, Logo: logo}))
+	img, err = png.Decode(&buf)
+	assert.NoError(t, err)
+	r, g, _, _ := img.At(128, 128).RGBA()
+	assert.Equal(t, [2]uint32{0xffff, 0}, [2]uint32{r, g}, "logo in the middle")
+}
+
+func TestSVG(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, SVG(&buf, testLink, Options{Size: 300}))
+	svg := buf.String()
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="300" height="300" viewBox="0 0 `))
+	assert.True(t, strings.HasSuffix(svg, `"/></svg>`))
+	assert.Contains(t, svg, `<path fill="#000000" d="M4 4h7v1h-7z`, "finder pattern after the quiet zone")
+	assert.NotContains(t, svg, "<image")
+
+	logo, err := NewLogo(image.NewRGBA(image.Rect(0, 0, 2, 2)))
+	assert.NoError(t, err)
+	buf.Reset()
+	assert.NoError(t, SVG(&buf, testLink, Options{Size: 300, Logo: logo}))
+	assert.Contains(t, buf.String(), `href="data:image/png;base64,`)
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/qr"
+	"durable-links-generator/api/service"
+	"durable-links-generator/config"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// Bounds of the size param of QR codes, in pixels.
+const (
+	defaultQRSize = 512
+	minQRSize     = 64
+	maxQRSize     = 2048
+)
+
+type QRCodeHandler interface {
+	QRCode(w http.ResponseWriter, r *http.Request)
+}
+
+type qrCodeHandler struct {
+	linkService service.LinkService
+	level       qr.Level
+	// logo is nil without QR_LOGO.
+	logo *qr.Logo
+}
+
+// NewQRCodeHandler builds the QR code handler from the QR_* config.
+func NewQRCodeHandler(linkService service.LinkService, cfg *config.Config) (QRCodeHandler, error) {
+	level, err := qr.ParseLevel(cfg.App.QRErrorCorrection)
+	if err != nil {
+		return nil, err
+	}
+	logo, err := qr.LoadLogo(cfg.App.QRLogo)
+	if err != nil {
+		return nil, err
+	}
+	return &qrCodeHandler{
+		linkService: linkService,
+		level:       level,
+		logo:        logo,
+	}, nil
+}
+
+// QRCode serves "/shortLinks/{path}/qr", the short link as a PNG or SVG QR code. The size,
+// format ("png" or "svg") and ecc (error correction level) params override the defaults; the
+// configured logo is embedded unless logo=false. The link's host comes from the host query
+// param, defaulting to the request host.
+func (h *qrCodeHandler) QRCode(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	host := query.Get("host")
+	if host == "" {
+		host = r.Host
+	}
+
+	opts := qr.Options{Size: defaultQRSize, Level: h.level, Logo: h.logo}
+	if raw := query.Get("size"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size < minQRSize || size > maxQRSize {
+			WriteErrorResponse(w, http.StatusBadRequest, "size must be an integer from 64 to 2048", models.StatusInvalidArgument)
+			return
+		}
+		opts.Size = size
+	}
+	if raw := query.Get("ecc"); raw != "" {
+		level, err := qr.ParseLevel(raw)
+		if err != nil {
+			WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+			return
+		}
+		opts.Level = level
+	}
+	if raw := query.Get("logo"); raw != "" {
+		withLogo, err := strconv.ParseBool(raw)
+		if err != nil {
+			WriteErrorResponse(w, http.StatusBadRequest, "logo must be true or false", models.StatusInvalidArgument)
+			return
+		}
+		if !withLogo {
+			opts.Logo = nil
+		} else if h.logo == nil {
+			WriteErrorResponse(w, http.StatusBadRequest, "No QR code logo is configured", models.StatusInvalidArgument)
+			return
+		}
+	}
+	render, contentType := qr.PNG, "image/png"
+	switch query.Get("format") {
+	case "", "png":
+	case "svg":
+		render, contentType = qr.SVG, "image/svg+xml"
+	default:
+		WriteErrorResponse(w, http.StatusBadRequest, "format must be png or svg", models.StatusInvalidArgument)
+		return
+	}
+
+	shortLink, err := h.linkService.QRCodeLink(r.Context(), host, chi.URLParam(r, "path"))
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+		return
+	case errors.Is(err, apperrors.ErrLinkNotFound):
+		WriteError(w, err, http.StatusNotFound, "Link not found", models.StatusNotFound)
+		return
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to load link for QR code")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to render QR code", models.StatusInternal)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := render(&buf, shortLink, opts); err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Str("short_link", shortLink).Msg("Failed to render QR code")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to render QR code", models.StatusInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write(buf.Bytes())
+}
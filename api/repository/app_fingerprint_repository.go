@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/db"
+)
+
+type AppFingerprintRepository interface {
+	AddAppFingerprint(ctx context.Context, host string, fingerprint models.AndroidAppFingerprint) (*models.AndroidAppFingerprint, error)
+	ListAppFingerprints(ctx context.Context, host string) ([]models.AndroidAppFingerprint, error)
+	DeleteAppFingerprint(ctx context.Context, host, packageName, fingerprint string) error
+}
+
+type appFingerprintRepository struct {
+	db *sql.DB
+}
+
+func NewAppFingerprintRepository(db *sql.DB) AppFingerprintRepository {
+	return &appFingerprintRepository{
+		db: db,
+	}
+}
+
+// NewAppFingerprintRepositoryForDB returns the repository for the dialect of database, running
+// queries on its write pool.
+func NewAppFingerprintRepositoryForDB(database *db.DB) AppFingerprintRepository {
+	if database.Dialect == db.MySQL {
+		return NewMySQLAppFingerprintRepository(database.Write)
+	}
+	return NewAppFingerprintRepository(database.Write)
+}
+
+func (r *appFingerprintRepository) AddAppFingerprint(ctx context.Context, host string, fingerprint models.AndroidAppFingerprint) (*models.AndroidAppFingerprint, error) {
+	const stmt = `
+    INSERT INTO android_app_fingerprints
+      (host, package_name, sha256_fingerprint)
+    VALUES ($1, $2, $3)
+    RETURNING created_at`
+	err := r.db.QueryRowContext(ctx, stmt, host, fingerprint.PackageName, fingerprint.SHA256CertFingerprint).
+		Scan(&fingerprint.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, apperrors.ErrFingerprintExists
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &fingerprint, nil
+}
+
+func (r *appFingerprintRepository) ListAppFingerprints(ctx context.Context, host string) ([]models.AndroidAppFingerprint, error) {
+	const q = `
+    SELECT package_name, sha256_fingerprint, created_at
+      FROM android_app_fingerprints
+     WHERE host = $1
+     ORDER BY package_name, created_at`
+	rows, err := r.db.QueryContext(ctx, q, host)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	fingerprints := []models.AndroidAppFingerprint{}
+	for rows.Next() {
+		var fp models.AndroidAppFingerprint
+		if err := rows.Scan(&fp.PackageName, &fp.SHA256CertFingerprint, &fp.CreatedAt); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		fingerprints = append(fingerprints, fp)
+	}
+	return fingerprints, rows.Err()
+}
+
+func (r *appFingerprintRepository) DeleteAppFingerprint(ctx context.Context, host, packageName, fingerprint string) error {
+	const stmt = `
+    DELETE FROM android_app_fingerprints
+     WHERE host = $1 AND package_name = $2 AND sha256_fingerprint = $3`
+	res, err := r.db.ExecContext(ctx, stmt, host, packageName, fingerprint)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return apperrors.ErrFingerprintNotFound
+	}
+	return nil
+}
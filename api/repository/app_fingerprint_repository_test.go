@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+const testFingerprint = "14:6D:E9:83:C5:73:06:50:D8:EE:B9:95:2F:34:FC:64:16:A0:83:42:E6:1D:BE:A8:8A:04:96:B2:3F:CF:44:E5"
+
+func TestAddAppFingerprint(t *testing.T) {
+	db, mock, _ := setupMockDB(t)
+	defer db.Close()
+	repo := NewAppFingerprintRepository(db)
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`INSERT INTO android_app_fingerprints .* RETURNING created_at`).
+		WithArgs("acme.link", "com.acme.app", testFingerprint).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(createdAt))
+	mock.ExpectQuery(`INSERT INTO android_app_fingerprints`).
+		WithArgs("acme.link", "com.acme.app", testFingerprint).
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	fp := models.AndroidAppFingerprint{PackageName: "com.acme.app", SHA256CertFingerprint: testFingerprint}
+	added, err := repo.AddAppFingerprint(context.Background(), "acme.link", fp)
+	assert.NoError(t, err)
+	assert.Equal(t, createdAt, added.CreatedAt)
+
+	_, err = repo.AddAppFingerprint(context.Background(), "acme.link", fp)
+	assert.ErrorIs(t, err, apperrors.ErrFingerprintExists)
+}
+
+func TestDeleteAppFingerprint_NotFound(t *testing.T) {
+	db, mock, _ := setupMockDB(t)
+	defer db.Close()
+	repo := NewAppFingerprintRepository(db)
+
+	mock.ExpectExec(`DELETE FROM android_app_fingerprints`).
+		WithArgs("acme.link", "com.acme.app", testFingerprint).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.DeleteAppFingerprint(context.Background(), "acme.link", "com.acme.app", testFingerprint)
+	assert.ErrorIs(t, err, apperrors.ErrFingerprintNotFound)
+}
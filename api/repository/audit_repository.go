@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"durable-links-generator/api/models"
+)
+
+// AuditRepository appends to the audit log and reads it back. Entries are never changed or
+// deleted; the audit_log table refuses it.
+type AuditRepository interface {
+	AppendAuditEntry(ctx context.Context, entry models.AuditEntry) error
+	// ListAuditEntries returns the entries matching filter, newest first.
+	ListAuditEntries(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error)
+}
+
+type auditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository returns the audit repository for any dialect: its queries are the same in
+// Postgres, SQLite and MySQL.
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{
+		db: db,
+	}
+}
+
+func (r *auditRepository) AppendAuditEntry(ctx context.Context, entry models.AuditEntry) error {
+	const stmt = `
+    INSERT INTO audit_log
+      (actor, subject, role, client_ip, request_id, action, host, resource, reason, before_state, after_state)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	_, err := r.db.ExecContext(ctx, stmt, entry.Actor, entry.Subject, entry.Role, entry.ClientIP, entry.RequestID,
+		entry.Action, entry.Host, entry.Resource, entry.Reason, nullableJSON(entry.Before), nullableJSON(entry.After))
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+func (r *auditRepository) ListAuditEntries(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	var conds []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, strings.ReplaceAll(cond, "$?", fmt.Sprintf("$%d", len(args))))
+	}
+	if filter.Host != "" {
+		add("host = $?", filter.Host)
+	}
+	if filter.Resource != "" {
+		add("resource = $?", filter.Resource)
+	}
+	if filter.Action != "" {
+		add("action = $?", filter.Action)
+	}
+	if filter.Actor != "" {
+		add("(actor = $? OR subject = $?)", filter.Actor)
+	}
+	if !filter.From.IsZero() {
+		add("created_at >= $?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("created_at < $?", filter.To)
+	}
+	if filter.BeforeID > 0 {
+		add("id < $?", filter.BeforeID)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, filter.Limit)
+	q := fmt.Sprintf(`
+    SELECT id, created_at, actor, subject, role, client_ip, request_id, action, host, resource, reason,
+           before_state, after_state
+      FROM audit_log
+     %s
+     ORDER BY id DESC
+     LIMIT $%d`, where, len(args))
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var entry models.AuditEntry
+		var before, after sql.NullString
+		err := rows.Scan(&entry.ID, &entry.Time, &entry.Actor, &entry.Subject, &entry.Role, &entry.ClientIP, &entry.RequestID,
+			&entry.Action, &entry.Host, &entry.Resource, &entry.Reason, &before, &after)
+		if err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		entry.Before, entry.After = rawJSON(before), rawJSON(after)
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// nullableJSON stores a missing snapshot as NULL.
+func nullableJSON(raw json.RawMessage) any {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	return string(raw)
+}
+
+func rawJSON(value sql.NullString) json.RawMessage {
+	if !value.Valid {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(value.String)
+}
@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/audit"
+	"durable-links-generator/api/models"
+
+	"github.com/rs/zerolog/log"
+)
+
+// auditedLinkRepository records the links created, changed and deleted through it in the audit
+// log, with snapshots from before and after each change and the actor of the change's context.
+// Entries are appended once a change succeeded; failing to append one is logged, as the change
+// can't be taken back by then. Clicks and the archiving of stale links aren't recorded.
+type auditedLinkRepository struct {
+	LinkRepository
+	audit AuditRepository
+}
+
+// NewAuditedLinkRepository records the changes made through repo in audit.
+func NewAuditedLinkRepository(repo LinkRepository, audit AuditRepository) LinkRepository {
+	return &auditedLinkRepository{LinkRepository: repo, audit: audit}
+}
+
+// aliasSnapshot is the Before or After of alias entries.
+type aliasSnapshot struct {
+	Alias string `json:"alias"`
+	Path  string `json:"path"`
+}
+
+// snapshot returns the link at path, or nil if there is none.
+func (r *auditedLinkRepository) snapshot(ctx context.Context, host, path string) *models.LinkSnapshot {
+	link, err := r.LinkRepository.GetLinkSnapshot(ctx, host, path)
+	if err != nil {
+		if !errors.Is(err, apperrors.ErrLinkNotFound) {
+			log.Ctx(ctx).Warn().Err(err).Str("host", host).Str("path", path).Msg("Failed to snapshot link for the audit log")
+		}
+		return nil
+	}
+	return link
+}
+
+// change applies a change of the link at path and records it.
+func (r *auditedLinkRepository) change(ctx context.Context, action, host, path, reason string, apply func() error) error {
+	before := r.snapshot(ctx, host, path)
+	if err := apply(); err != nil {
+		return err
+	}
+	// An alias is recorded as the path of its link.
+	resource := path
+	if before != nil {
+		resource = before.Path
+	}
+	appendAuditEntry(ctx, r.audit, action, host, resource, reason, before, r.snapshot(ctx, host, resource))
+	return nil
+}
+
+func (r *auditedLinkRepository) CreateShortLink(ctx context.Context, host, path, rawQS string, unguessable bool) error {
+	if err := r.LinkRepository.CreateShortLink(ctx, host, path, rawQS, unguessable); err != nil {
+		return err
+	}
+	appendAuditEntry(ctx, r.audit, models.AuditLinkCreate, host, path, "", nil, &models.LinkSnapshot{Path: path, QueryParams: rawQS, Unguessable: unguessable})
+	return nil
+}
+
+func (r *auditedLinkRepository) CreateShortLinks(ctx context.Context, links []models.StoredLink) error {
+	if err := r.LinkRepository.CreateShortLinks(ctx, links); err != nil {
+		return err
+	}
+	for _, link := range links {
+		appendAuditEntry(ctx, r.audit, models.AuditLinkCreate, link.Host, link.Path, "", nil, &models.LinkSnapshot{
+			Path:        link.Path,
+			QueryParams: link.QueryParams,
+			Unguessable: link.Unguessable,
+			Metadata:    link.Metadata,
+		})
+	}
+	return nil
+}
+
+func (r *auditedLinkRepository) UpdateQueryParams(ctx context.Context, links []models.StoredLink, reason string) error {
+	before := make([]*models.LinkSnapshot, len(links))
+	for i, link := range links {
+		before[i] = r.snapshot(ctx, link.Host, link.Path)
+	}
+	if err := r.LinkRepository.UpdateQueryParams(ctx, links, reason); err != nil {
+		return err
+	}
+	for i, link := range links {
+		appendAuditEntry(ctx, r.audit, models.AuditLinkUpdate, link.Host, link.Path, reason, before[i], r.snapshot(ctx, link.Host, link.Path))
+	}
+	return nil
+}
+
+func (r *auditedLinkRepository) ReplaceQueryParams(ctx context.Context, link models.StoredLink, previous, reason string) error {
+	return r.change(ctx, models.AuditLinkUpdate, link.Host, link.Path, reason, func() error {
+		return r.LinkRepository.ReplaceQueryParams(ctx, link, previous, reason)
+	})
+}
+
+func (r *auditedLinkRepository) SetLinkMetadata(ctx context.Context, host, path string, metadata models.LinkMetadata) error {
+	return r.change(ctx, models.AuditLinkUpdate, host, path, "", func() error {
+		return r.LinkRepository.SetLinkMetadata(ctx, host, path, metadata)
+	})
+}
+
+func (r *auditedLinkRepository) SetGeoDestinations(ctx context.Context, host, path string, destinations []models.GeoDestination) error {
+	before, _ := r.LinkRepository.ListGeoDestinations(ctx, host, path)
+	if err := r.LinkRepository.SetGeoDestinations(ctx, host, path, destinations); err != nil {
+		return err
+	}
+	appendAuditEntry(ctx, r.audit, models.AuditLinkGeoDestinations, host, path, "", before, destinations)
+	return nil
+}
+
+func (r *auditedLinkRepository) SetDeviceRules(ctx context.Context, host, path string, rules []models.DeviceRule) error {
+	before, _ := r.LinkRepository.ResolveDeviceRules(ctx, host, path)
+	if err := r.LinkRepository.SetDeviceRules(ctx, host, path, rules); err != nil {
+		return err
+	}
+	appendAuditEntry(ctx, r.audit, models.AuditLinkDeviceRules, host, path, "", before, rules)
+	return nil
+}
+
+func (r *auditedLinkRepository) SetLinkVariants(ctx context.Context, host, path string, variants []models.LinkVariant) error {
+	before, _ := r.LinkRepository.ResolveLinkVariants(ctx, host, path)
+	if err := r.LinkRepository.SetLinkVariants(ctx, host, path, variants); err != nil {
+		return err
+	}
+	appendAuditEntry(ctx, r.audit, models.AuditLinkVariants, host, path, "", before, variants)
+	return nil
+}
+
+func (r *auditedLinkRepository) CreateAlias(ctx context.Context, host, aliasPath, path string) error {
+	if err := r.LinkRepository.CreateAlias(ctx, host, aliasPath, path); err != nil {
+		return err
+	}
+	appendAuditEntry(ctx, r.audit, models.AuditAliasCreate, host, aliasPath, "", nil, aliasSnapshot{Alias: aliasPath, Path: path})
+	return nil
+}
+
+func (r *auditedLinkRepository) DeleteAlias(ctx context.Context, host, aliasPath string) error {
+	var before any
+	if path, err := r.LinkRepository.GetCanonicalPath(ctx, host, aliasPath); err == nil {
+		before = aliasSnapshot{Alias: aliasPath, Path: path}
+	}
+	if err := r.LinkRepository.DeleteAlias(ctx, host, aliasPath); err != nil {
+		return err
+	}
+	appendAuditEntry(ctx, r.audit, models.AuditAliasDelete, host, aliasPath, "", before, nil)
+	return nil
+}
+
+// MergeLinks records each duplicate as turned into an alias of canonical.
+func (r *auditedLinkRepository) MergeLinks(ctx context.Context, host, canonical string, duplicates []string) error {
+	before := make([]*models.LinkSnapshot, len(duplicates))
+	for i, path := range duplicates {
+		before[i] = r.snapshot(ctx, host, path)
+	}
+	if err := r.LinkRepository.MergeLinks(ctx, host, canonical, duplicates); err != nil {
+		return err
+	}
+	for i, path := range duplicates {
+		appendAuditEntry(ctx, r.audit, models.AuditLinkMerge, host, path, "merged into "+canonical, before[i], aliasSnapshot{Alias: path, Path: canonical})
+	}
+	return nil
+}
+
+func (r *auditedLinkRepository) MarkSuperseded(ctx context.Context, host, path, successor string, redirect bool) error {
+	return r.change(ctx, models.AuditLinkSupersede, host, path, "", func() error {
+		return r.LinkRepository.MarkSuperseded(ctx, host, path, successor, redirect)
+	})
+}
+
+func (r *auditedLinkRepository) DeleteLink(ctx context.Context, host, path string) error {
+	return r.change(ctx, models.AuditLinkDelete, host, path, "", func() error {
+		return r.LinkRepository.DeleteLink(ctx, host, path)
+	})
+}
+
+func (r *auditedLinkRepository) SoftDeleteLink(ctx context.Context, host, path string) error {
+	return r.change(ctx, models.AuditLinkDelete, host, path, "", func() error {
+		return r.LinkRepository.SoftDeleteLink(ctx, host, path)
+	})
+}
+
+func (r *auditedLinkRepository) RestoreLink(ctx context.Context, host, path string) error {
+	return r.change(ctx, models.AuditLinkRestore, host, path, "", func() error {
+		return r.LinkRepository.RestoreLink(ctx, host, path)
+	})
+}
+
+func (r *auditedLinkRepository) UnarchiveLink(ctx context.Context, host, path string) error {
+	return r.change(ctx, models.AuditLinkUnarchive, host, path, "", func() error {
+		return r.LinkRepository.UnarchiveLink(ctx, host, path)
+	})
+}
+
+// auditedDomainRepository records the domain configs created, updated and deleted through it in
+// the audit log, as auditedLinkRepository does for links.
+type auditedDomainRepository struct {
+	DomainRepository
+	audit AuditRepository
+}
+
+// NewAuditedDomainRepository records the changes made through repo in audit.
+func NewAuditedDomainRepository(repo DomainRepository, audit AuditRepository) DomainRepository {
+	return &auditedDomainRepository{DomainRepository: repo, audit: audit}
+}
+
+func (r *auditedDomainRepository) CreateDomain(ctx context.Context, domain models.DomainConfig) (*models.DomainConfig, error) {
+	created, err := r.DomainRepository.CreateDomain(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	appendAuditEntry(ctx, r.audit, models.AuditDomainCreate, created.Host, created.Host, "", nil, created)
+	return created, nil
+}
+
+func (r *auditedDomainRepository) UpdateDomain(ctx context.Context, domain models.DomainConfig) (*models.DomainConfig, error) {
+	before, _ := r.DomainRepository.GetDomain(ctx, domain.Host)
+	updated, err := r.DomainRepository.UpdateDomain(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	appendAuditEntry(ctx, r.audit, models.AuditDomainUpdate, updated.Host, updated.Host, "", before, updated)
+	return updated, nil
+}
+
+func (r *auditedDomainRepository) DeleteDomain(ctx context.Context, host string) error {
+	before, _ := r.DomainRepository.GetDomain(ctx, host)
+	if err := r.DomainRepository.DeleteDomain(ctx, host); err != nil {
+		return err
+	}
+	appendAuditEntry(ctx, r.audit, models.AuditDomainDelete, host, host, "", before, nil)
+	return nil
+}
+
+// appendAuditEntry appends an entry made by the actor of ctx, with before and after encoded as
+// JSON.
+func appendAuditEntry(ctx context.Context, repo AuditRepository, action, host, resource, reason string, before, after any) {
+	actor := audit.ActorFrom(ctx)
+	entry := models.AuditEntry{
+		Actor:     actor.Key,
+		Subject:   actor.Subject,
+		Role:      actor.Role,
+		ClientIP:  actor.ClientIP,
+		RequestID: actor.RequestID,
+		Action:    action,
+		Host:      host,
+		Resource:  resource,
+		Reason:    reason,
+		Before:    snapshotJSON(before),
+		After:     snapshotJSON(after),
+	}
+	if err := repo.AppendAuditEntry(ctx, entry); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("action", action).Str("host", host).Str("resource", resource).Msg("Failed to append to the audit log")
+	}
+}
+
+// snapshotJSON encodes a snapshot; nil ones, typed or not, become null.
+func snapshotJSON(snapshot any) json.RawMessage {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil
+	}
+	return data
+}
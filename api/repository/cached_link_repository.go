@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"durable-links-generator/api/models"
+)
+
+// LinkCache holds the results of link lookups by host, so that all entries of a host can be
+// dropped at once when any of its links changes.
+type LinkCache interface {
+	// Get returns the cached value of key on host. Lookup failures are misses.
+	Get(ctx context.Context, host, key string) (string, bool)
+	Set(ctx context.Context, host string, entries map[string]string)
+	Invalidate(ctx context.Context, hosts ...string)
+}
+
+// Keys of the cached lookups: the query params a path resolves to, the path of the guessable
+// link with some query params, and the geo destinations, device rules and variants of the link a
+// path resolves to.
+func pathKey(path string) string     { return "path:" + path }
+func queryKey(rawQS string) string   { return "query:" + rawQS }
+func geoKey(path string) string      { return "geo:" + path }
+func rulesKey(path string) string    { return "rules:" + path }
+func variantsKey(path string) string { return "variants:" + path }
+
+// cachedLinkRepository serves link lookups from a LinkCache, writing new links through to it and
+// invalidating the host of any link that is changed, aliased away, superseded or removed. Only
+// hits are cached, so lookups of links that don't resolve always reach the database; geo
+// destinations, device rules and variants, looked up for links that resolved, are cached even
+// when there are none.
+type cachedLinkRepository struct {
+	LinkRepository
+	cache LinkCache
+}
+
+// NewCachedLinkRepository puts cache in front of repo's link lookups, or returns repo if cache is
+// nil.
+func NewCachedLinkRepository(repo LinkRepository, cache LinkCache) LinkRepository {
+	if cache == nil {
+		return repo
+	}
+	return &cachedLinkRepository{LinkRepository: repo, cache: cache}
+}
+
+func (r *cachedLinkRepository) GetQueryParamsByHostAndPath(ctx context.Context, host, path string) (string, error) {
+	if rawQS, ok := r.cache.Get(ctx, host, pathKey(path)); ok {
+		return rawQS, nil
+	}
+	rawQS, err := r.LinkRepository.GetQueryParamsByHostAndPath(ctx, host, path)
+	if err != nil {
+		return "", err
+	}
+	r.cache.Set(ctx, host, map[string]string{pathKey(path): rawQS})
+	return rawQS, nil
+}
+
+func (r *cachedLinkRepository) ResolveGeoDestinations(ctx context.Context, host, path string) ([]models.GeoDestination, error) {
+	if cached, ok := r.cache.Get(ctx, host, geoKey(path)); ok {
+		var destinations []models.GeoDestination
+		if json.Unmarshal([]byte(cached), &destinations) == nil {
+			return destinations, nil
+		}
+	}
+	destinations, err := r.LinkRepository.ResolveGeoDestinations(ctx, host, path)
+	if err != nil {
+		return nil, err
+	}
+	if encoded, err := json.Marshal(destinations); err == nil {
+		r.cache.Set(ctx, host, map[string]string{geoKey(path): string(encoded)})
+	}
+	return destinations, nil
+}
+
+func (r *cachedLinkRepository) ResolveDeviceRules(ctx context.Context, host, path string) ([]models.DeviceRule, error) {
+	if cached, ok := r.cache.Get(ctx, host, rulesKey(path)); ok {
+		var rules []models.DeviceRule
+		if json.Unmarshal([]byte(cached), &rules) == nil {
+			return rules, nil
+		}
+	}
+	rules, err := r.LinkRepository.ResolveDeviceRules(ctx, host, path)
+	if err != nil {
+		return nil, err
+	}
+	if encoded, err := json.Marshal(rules); err == nil {
+		r.cache.Set(ctx, host, map[string]string{rulesKey(path): string(encoded)})
+	}
+	return rules, nil
+}
+
+func (r *cachedLinkRepository) ResolveLinkVariants(ctx context.Context, host, path string) ([]models.LinkVariant, error) {
+	if cached, ok := r.cache.Get(ctx, host, variantsKey(path)); ok {
+		var variants []models.LinkVariant
+		if json.Unmarshal([]byte(cached), &variants) == nil {
+			return variants, nil
+		}
+	}
+	variants, err := r.LinkRepository.ResolveLinkVariants(ctx, host, path)
+	if err != nil {
+		return nil, err
+	}
+	if encoded, err := json.Marshal(variants); err == nil {
+		r.cache.Set(ctx, host, map[string]string{variantsKey(path): string(encoded)})
+	}
+	return variants, nil
+}
+
+func (r *cachedLinkRepository) FindExistingShortLink(ctx context.Context, host, rawQS string) (string, error) {
+	if path, ok := r.cache.Get(ctx, host, queryKey(rawQS)); ok {
+		return path, nil
+	}
+	path, err := r.LinkRepository.FindExistingShortLink(ctx, host, rawQS)
+	if err != nil {
+		return "", err
+	}
+	r.cache.Set(ctx, host, map[string]string{queryKey(rawQS): path})
+	return path, nil
+}
+
+func (r *cachedLinkRepository) CreateShortLink(ctx context.Context, host, path, rawQS string, unguessable bool) error {
+	if err := r.LinkRepository.CreateShortLink(ctx, host, path, rawQS, unguessable); err != nil {
+		return err
+	}
+	r.cache.Set(ctx, host, linkEntries(map[string]string{}, path, rawQS, unguessable))
+	return nil
+}
+
+func (r *cachedLinkRepository) CreateShortLinks(ctx context.Context, links []models.StoredLink) error {
+	if err := r.LinkRepository.CreateShortLinks(ctx, links); err != nil {
+		return err
+	}
+	entries := map[string]map[string]string{}
+	for _, link := range links {
+		if entries[link.Host] == nil {
+			entries[link.Host] = map[string]string{}
+		}
+		linkEntries(entries[link.Host], link.Path, link.QueryParams, link.Unguessable)
+	}
+	for host, hostEntries := range entries {
+		r.cache.Set(ctx, host, hostEntries)
+	}
+	return nil
+}
+
+// linkEntries adds the lookups a new link answers to entries. Unguessable links are never
+// returned for their query params.
+func linkEntries(entries map[string]string, path, rawQS string, unguessable bool) map[string]string {
+	entries[pathKey(path)] = rawQS
+	if !unguessable {
+		entries[queryKey(rawQS)] = path
+	}
+	return entries
+}
+
+func (r *cachedLinkRepository) UpdateQueryParams(ctx context.Context, links []models.StoredLink, reason string) error {
+	err := r.LinkRepository.UpdateQueryParams(ctx, links, reason)
+	r.invalidateLinks(ctx, links)
+	return err
+}
+
+func (r *cachedLinkRepository) ReplaceQueryParams(ctx context.Context, link models.StoredLink, previous, reason string) error {
+	err := r.LinkRepository.ReplaceQueryParams(ctx, link, previous, reason)
+	r.invalidate(ctx, link.Host)
+	return err
+}
+
+func (r *cachedLinkRepository) SetGeoDestinations(ctx context.Context, host, path string, destinations []models.GeoDestination) error {
+	err := r.LinkRepository.SetGeoDestinations(ctx, host, path, destinations)
+	r.invalidate(ctx, host)
+	return err
+}
+
+func (r *cachedLinkRepository) SetDeviceRules(ctx context.Context, host, path string, rules []models.DeviceRule) error {
+	err := r.LinkRepository.SetDeviceRules(ctx, host, path, rules)
+	r.invalidate(ctx, host)
+	return err
+}
+
+func (r *cachedLinkRepository) SetLinkVariants(ctx context.Context, host, path string, variants []models.LinkVariant) error {
+	err := r.LinkRepository.SetLinkVariants(ctx, host, path, variants)
+	r.invalidate(ctx, host)
+	return err
+}
+
+func (r *cachedLinkRepository) DeleteAlias(ctx context.Context, host, aliasPath string) error {
+	err := r.LinkRepository.DeleteAlias(ctx, host, aliasPath)
+	r.invalidate(ctx, host)
+	return err
+}
+
+func (r *cachedLinkRepository) MergeLinks(ctx context.Context, host, canonical string, duplicates []string) error {
+	err := r.LinkRepository.MergeLinks(ctx, host, canonical, duplicates)
+	r.invalidate(ctx, host)
+	return err
+}
+
+func (r *cachedLinkRepository) MarkSuperseded(ctx context.Context, host, path, successor string, redirect bool) error {
+	err := r.LinkRepository.MarkSuperseded(ctx, host, path, successor, redirect)
+	r.invalidate(ctx, host)
+	return err
+}
+
+func (r *cachedLinkRepository) DeleteLink(ctx context.Context, host, path string) error {
+	err := r.LinkRepository.DeleteLink(ctx, host, path)
+	r.invalidate(ctx, host)
+	return err
+}
+
+func (r *cachedLinkRepository) SoftDeleteLink(ctx context.Context, host, path string) error {
+	err := r.LinkRepository.SoftDeleteLink(ctx, host, path)
+	r.invalidate(ctx, host)
+	return err
+}
+
+func (r *cachedLinkRepository) ArchiveStaleLinks(ctx context.Context, before time.Time) ([]models.StoredLink, error) {
+	archived, err := r.LinkRepository.ArchiveStaleLinks(ctx, before)
+	r.invalidateLinks(ctx, archived)
+	return archived, err
+}
+
+// invalidate drops the cached lookups of hosts, even when a change failed part way. A lookup
+// reading the database before the change and caching its result after can still leave a stale
+// entry behind, which lives until the cache expires it.
+func (r *cachedLinkRepository) invalidate(ctx context.Context, hosts ...string) {
+	if len(hosts) > 0 {
+		r.cache.Invalidate(context.WithoutCancel(ctx), hosts...)
+	}
+}
+
+func (r *cachedLinkRepository) invalidateLinks(ctx context.Context, links []models.StoredLink) {
+	var hosts []string
+	for _, link := range links {
+		if !slices.Contains(hosts, link.Host) {
+			hosts = append(hosts, link.Host)
+		}
+	}
+	r.invalidate(ctx, hosts...)
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"durable-links-generator/api/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryLinkCache map[string]map[string]string
+
+func (c memoryLinkCache) Get(_ context.Context, host, key string) (string, bool) {
+	value, ok := c[host][key]
+	return value, ok
+}
+
+func (c memoryLinkCache) Set(_ context.Context, host string, entries map[string]string) {
+	if c[host] == nil {
+		c[host] = map[string]string{}
+	}
+	for key, value := range entries {
+		c[host][key] = value
+	}
+}
+
+func (c memoryLinkCache) Invalidate(_ context.Context, hosts ...string) {
+	for _, host := range hosts {
+		delete(c, host)
+	}
+}
+
+func TestCachedLinkRepository(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+	cache := memoryLinkCache{}
+	cached := NewCachedLinkRepository(repo, cache)
+	ctx := context.Background()
+
+	// Only the first lookup reaches the database.
+	mock.ExpectQuery(`SELECT query_params, deleted_at IS NOT NULL FROM durable_links`).
+		WithArgs("example.com", "abc").
+		WillReturnRows(sqlmock.NewRows([]string{"query_params", "deleted"}).AddRow("link=a", false))
+	for range 2 {
+		rawQS, err := cached.GetQueryParamsByHostAndPath(ctx, "example.com", "abc")
+		assert.NoError(t, err)
+		assert.Equal(t, "link=a", rawQS)
+	}
+
+	// Misses aren't cached.
+	for range 2 {
+		mock.ExpectQuery(`SELECT path FROM durable_links`).
+			WithArgs("example.com", "link=b").
+			WillReturnRows(sqlmock.NewRows([]string{"path"}))
+		_, err := cached.FindExistingShortLink(ctx, "example.com", "link=b")
+		assert.Error(t, err)
+	}
+
+	// New links are written through.
+	mock.ExpectExec(`INSERT INTO durable_links`).
+		WithArgs("example.com", "def", "link=b", false, "def b", sql.NullTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	assert.NoError(t, cached.CreateShortLink(ctx, "example.com", "def", "link=b", false))
+	path, err := cached.FindExistingShortLink(ctx, "example.com", "link=b")
+	assert.NoError(t, err)
+	assert.Equal(t, "def", path)
+	rawQS, err := cached.GetQueryParamsByHostAndPath(ctx, "example.com", "def")
+	assert.NoError(t, err)
+	assert.Equal(t, "link=b", rawQS)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO durable_links`).WillReturnResult(sqlmock.NewResult(2, 2))
+	mock.ExpectCommit()
+	assert.NoError(t, cached.CreateShortLinks(ctx, []models.StoredLink{
+		{Host: "example.com", Path: "ghi", QueryParams: "link=c", Unguessable: true},
+		{Host: "other.com", Path: "abc", QueryParams: "link=d"},
+	}))
+	assert.Equal(t, memoryLinkCache{
+		"example.com": {"path:abc": "link=a", "path:def": "link=b", "query:link=b": "def", "path:ghi": "link=c"},
+		"other.com":   {"path:abc": "link=d", "query:link=d": "abc"},
+	}, cache)
+
+	// Changes drop the cached lookups of the host.
+	mock.ExpectExec(`UPDATE durable_links SET deleted_at = now\(\)`).
+		WithArgs("example.com", "abc").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, cached.SoftDeleteLink(ctx, "example.com", "abc"))
+	assert.Equal(t, memoryLinkCache{"other.com": {"path:abc": "link=d", "query:link=d": "abc"}}, cache)
+
+	// Geo destinations are cached even when there are none, and setting them drops the host.
+	mock.ExpectQuery(`SELECT country, link FROM link_geo_destinations`).
+		WithArgs("other.com", "abc").
+		WillReturnRows(sqlmock.NewRows([]string{"country", "link"}))
+	for range 2 {
+		destinations, err := cached.ResolveGeoDestinations(ctx, "other.com", "abc")
+		assert.NoError(t, err)
+		assert.Empty(t, destinations)
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM link_geo_destinations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO link_geo_destinations`).
+		WithArgs("other.com", "abc", "DE", "https://example.com/de").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, cached.SetGeoDestinations(ctx, "other.com", "abc", []models.GeoDestination{{Country: "DE", Link: "https://example.com/de"}}))
+	assert.Empty(t, cache)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Same(t, repo, NewCachedLinkRepository(repo, nil))
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+)
+
+type CampaignRepository interface {
+	CreateCampaign(ctx context.Context, campaign models.Campaign) (*models.Campaign, error)
+	ListCampaigns(ctx context.Context, host string) ([]models.Campaign, error)
+	GetCampaign(ctx context.Context, host, id string) (*models.Campaign, error)
+	DeleteCampaign(ctx context.Context, host, id string) error
+	AddCampaignLinks(ctx context.Context, host, id string, paths []string) error
+	RemoveCampaignLink(ctx context.Context, host, id, path string) error
+	ListCampaignLinks(ctx context.Context, host, id string) ([]models.CampaignLink, error)
+}
+
+type campaignRepository struct {
+	db *sql.DB
+}
+
+// NewCampaignRepository returns the campaign repository for any dialect: its queries are the same
+// in Postgres, SQLite and MySQL.
+func NewCampaignRepository(db *sql.DB) CampaignRepository {
+	return &campaignRepository{
+		db: db,
+	}
+}
+
+// Links of deleted links aren't counted: they stay in the campaign until purged, but neither
+// resolve nor show up in listings.
+const campaignColumns = `c.id, c.host, c.name, c.created_at,
+           (SELECT COUNT(*)
+              FROM campaign_links l
+              JOIN durable_links d ON d.host = l.host AND d.path = l.path
+             WHERE l.host = c.host AND l.campaign_id = c.id AND d.deleted_at IS NULL)`
+
+func scanCampaign(row interface{ Scan(...any) error }) (*models.Campaign, error) {
+	var campaign models.Campaign
+	if err := row.Scan(&campaign.ID, &campaign.Host, &campaign.Name, &campaign.CreatedAt, &campaign.LinkCount); err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+func (r *campaignRepository) CreateCampaign(ctx context.Context, campaign models.Campaign) (*models.Campaign, error) {
+	const stmt = `
+    INSERT INTO campaigns
+      (host, id, name)
+    VALUES ($1, $2, $3)`
+	if _, err := r.db.ExecContext(ctx, stmt, campaign.Host, campaign.ID, campaign.Name); err != nil {
+		if isUniqueViolation(err) {
+			return nil, apperrors.ErrCampaignExists
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return r.GetCampaign(ctx, campaign.Host, campaign.ID)
+}
+
+func (r *campaignRepository) ListCampaigns(ctx context.Context, host string) ([]models.Campaign, error) {
+	const q = `
+    SELECT ` + campaignColumns + `
+      FROM campaigns c
+     WHERE c.host = $1
+     ORDER BY c.id`
+	rows, err := r.db.QueryContext(ctx, q, host)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	campaigns := []models.Campaign{}
+	for rows.Next() {
+		campaign, err := scanCampaign(rows)
+		if err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		campaigns = append(campaigns, *campaign)
+	}
+	return campaigns, rows.Err()
+}
+
+func (r *campaignRepository) GetCampaign(ctx context.Context, host, id string) (*models.Campaign, error) {
+	const q = `
+    SELECT ` + campaignColumns + `
+      FROM campaigns c
+     WHERE c.host = $1 AND c.id = $2`
+	campaign, err := scanCampaign(r.db.QueryRowContext(ctx, q, host, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrCampaignNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return campaign, nil
+}
+
+// DeleteCampaign deletes the campaign and lets go of its links, which are kept.
+func (r *campaignRepository) DeleteCampaign(ctx context.Context, host, id string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+
+	const linksStmt = `
+    DELETE FROM campaign_links
+     WHERE host = $1 AND campaign_id = $2`
+	if _, err := tx.ExecContext(ctx, linksStmt, host, id); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	const stmt = `
+    DELETE FROM campaigns
+     WHERE host = $1 AND id = $2`
+	res, err := tx.ExecContext(ctx, stmt, host, id)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return apperrors.ErrCampaignNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// AddCampaignLinks puts the links at paths, which must exist, in the campaign, moving those in
+// another campaign. Links already in it are left as they are.
+func (r *campaignRepository) AddCampaignLinks(ctx context.Context, host, id string, paths []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	const campaignQuery = `
+    SELECT EXISTS (SELECT 1 FROM campaigns WHERE host = $1 AND id = $2)`
+	if err := tx.QueryRowContext(ctx, campaignQuery, host, id).Scan(&exists); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if !exists {
+		return apperrors.ErrCampaignNotFound
+	}
+
+	const currentQuery = `
+    SELECT campaign_id
+      FROM campaign_links
+     WHERE host = $1 AND path = $2`
+	const addStmt = `
+    INSERT INTO campaign_links
+      (host, path, campaign_id)
+    VALUES ($1, $2, $3)`
+	const moveStmt = `
+    UPDATE campaign_links
+       SET campaign_id = $3, created_at = now()
+     WHERE host = $1 AND path = $2`
+	for _, path := range paths {
+		var current string
+		err := tx.QueryRowContext(ctx, currentQuery, host, path).Scan(&current)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			_, err = tx.ExecContext(ctx, addStmt, host, path, id)
+		case err == nil && current != id:
+			_, err = tx.ExecContext(ctx, moveStmt, host, path, id)
+		}
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// RemoveCampaignLink takes the link at path out of the campaign, failing with ErrLinkNotFound
+// when it isn't in it.
+func (r *campaignRepository) RemoveCampaignLink(ctx context.Context, host, id, path string) error {
+	const stmt = `
+    DELETE FROM campaign_links
+     WHERE host = $1 AND campaign_id = $2 AND path = $3`
+	res, err := r.db.ExecContext(ctx, stmt, host, id, path)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return apperrors.ErrLinkNotFound
+	}
+	return nil
+}
+
+// ListCampaignLinks returns the links of the campaign in the order they were added, without
+// deleted links.
+func (r *campaignRepository) ListCampaignLinks(ctx context.Context, host, id string) ([]models.CampaignLink, error) {
+	const q = `
+    SELECT l.path, l.created_at
+      FROM campaign_links l
+      JOIN durable_links d ON d.host = l.host AND d.path = l.path
+     WHERE l.host = $1 AND l.campaign_id = $2 AND d.deleted_at IS NULL
+     ORDER BY l.created_at, l.path`
+	rows, err := r.db.QueryContext(ctx, q, host, id)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	links := []models.CampaignLink{}
+	for rows.Next() {
+		var link models.CampaignLink
+		if err := rows.Scan(&link.Path, &link.AddedAt); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"durable-links-generator/api/models"
+	"durable-links-generator/db"
+)
+
+// DashboardRepository counts links, clicks and rows across all hosts for the admin dashboard API.
+type DashboardRepository interface {
+	Overview(ctx context.Context) (models.DashboardOverview, error)
+	LinksCreatedPerDay(ctx context.Context, since time.Time) ([]models.DailyCount, error)
+	RevisionsPerDay(ctx context.Context, since time.Time) ([]models.DailyCount, error)
+	ClickEventsPerDay(ctx context.Context, since time.Time) ([]models.DailyCount, error)
+	TopDestinations(ctx context.Context, limit int) ([]models.DestinationCount, error)
+	TopHosts(ctx context.Context, limit int) ([]models.HostCount, error)
+	TableSizes(ctx context.Context) ([]models.TableSize, error)
+}
+
+// dashboardSQL is the SQL the dashboard queries differ in between databases.
+type dashboardSQL struct {
+	// day formats the time in column as its UTC day, YYYY-MM-DD.
+	day func(column string) string
+	// destinationHost is the lowercased host of the "link" param of query_params, or NULL.
+	destinationHost string
+	// tableBytes reads the bytes the table named $1 and its indexes take, or is empty when the
+	// database doesn't tell.
+	tableBytes string
+}
+
+var postgresDashboard = dashboardSQL{
+	day:             func(column string) string { return `to_char(` + column + ` AT TIME ZONE 'UTC', 'YYYY-MM-DD')` },
+	destinationHost: `link_destination_host(query_params)`,
+	tableBytes:      `SELECT pg_total_relation_size($1::regclass)`,
+}
+
+var sqliteDashboard = dashboardSQL{
+	day:             func(column string) string { return `strftime('%Y-%m-%d', ` + column + `)` },
+	destinationHost: postgresDashboard.destinationHost,
+}
+
+var mysqlDashboard = dashboardSQL{
+	day: func(column string) string { return `DATE_FORMAT(` + column + `, '%Y-%m-%d')` },
+	destinationHost: `LOWER(SUBSTRING_INDEX(REGEXP_SUBSTR(query_params, ` +
+		`'(^|&)link=[A-Za-z][A-Za-z0-9+.-]*%3A%2F%2F[^%&/?#]+'), '%2F%2F', -1))`,
+	tableBytes: `
+    SELECT COALESCE(data_length + index_length, 0)
+      FROM information_schema.tables
+     WHERE table_schema = DATABASE()
+       AND table_name = $1`,
+}
+
+// dashboardTables are the tables whose size the dashboard reports: those growing with links and
+// traffic.
+var dashboardTables = []string{
+	"durable_links",
+	"link_aliases",
+	"link_revisions",
+	"link_clicks",
+	"link_daily_clicks",
+	"webhook_deliveries",
+	"idempotency_keys",
+}
+
+type dashboardRepository struct {
+	db      *sql.DB
+	dialect dashboardSQL
+}
+
+// NewDashboardRepositoryForDB returns the repository for the dialect of database, running queries
+// on its write pool, which serves the admin API.
+func NewDashboardRepositoryForDB(database *db.DB) DashboardRepository {
+	dialect := postgresDashboard
+	switch database.Dialect {
+	case db.SQLite:
+		dialect = sqliteDashboard
+	case db.MySQL:
+		dialect = mysqlDashboard
+	}
+	return &dashboardRepository{db: database.Write, dialect: dialect}
+}
+
+func (r *dashboardRepository) Overview(ctx context.Context) (models.DashboardOverview, error) {
+	const q = `
+    SELECT COUNT(*),
+           COALESCE(SUM(CASE WHEN archived_at IS NULL AND deleted_at IS NULL THEN 1 ELSE 0 END), 0),
+           COALESCE(SUM(CASE WHEN archived_at IS NOT NULL AND deleted_at IS NULL THEN 1 ELSE 0 END), 0),
+           COALESCE(SUM(CASE WHEN deleted_at IS NOT NULL THEN 1 ELSE 0 END), 0),
+           COUNT(DISTINCT host),
+           COALESCE(SUM(total_clicks), 0)
+      FROM durable_links`
+	var overview models.DashboardOverview
+	err := r.db.QueryRowContext(ctx, q).Scan(
+		&overview.Links,
+		&overview.ActiveLinks,
+		&overview.ArchivedLinks,
+		&overview.DeletedLinks,
+		&overview.Hosts,
+		&overview.Clicks,
+	)
+	if err != nil {
+		return models.DashboardOverview{}, fmt.Errorf("database error: %w", err)
+	}
+	return overview, nil
+}
+
+// LinksCreatedPerDay counts the links created on each UTC day from since on, deleted ones
+// included, oldest day first.
+func (r *dashboardRepository) LinksCreatedPerDay(ctx context.Context, since time.Time) ([]models.DailyCount, error) {
+	return r.dailyCounts(ctx, "durable_links", "created_at", since)
+}
+
+// RevisionsPerDay counts the link revisions written on each UTC day from since on.
+func (r *dashboardRepository) RevisionsPerDay(ctx context.Context, since time.Time) ([]models.DailyCount, error) {
+	return r.dailyCounts(ctx, "link_revisions", "created_at", since)
+}
+
+// ClickEventsPerDay counts the click events recorded on each UTC day from since on.
+func (r *dashboardRepository) ClickEventsPerDay(ctx context.Context, since time.Time) ([]models.DailyCount, error) {
+	return r.dailyCounts(ctx, "link_clicks", "clicked_at", since)
+}
+
+func (r *dashboardRepository) dailyCounts(ctx context.Context, table, column string, since time.Time) ([]models.DailyCount, error) {
+	q := `
+    SELECT ` + r.dialect.day(column) + ` AS day, COUNT(*)
+      FROM ` + table + `
+     WHERE ` + column + ` >= $1
+     GROUP BY day
+     ORDER BY day`
+	rows, err := r.db.QueryContext(ctx, q, since)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []models.DailyCount{}
+	for rows.Next() {
+		var count models.DailyCount
+		if err := rows.Scan(&count.Day, &count.Count); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return counts, nil
+}
+
+// TopDestinations counts the links that aren't deleted per destination domain, ranked by links
+// and then clicks. Links whose destination has no host are left out.
+func (r *dashboardRepository) TopDestinations(ctx context.Context, limit int) ([]models.DestinationCount, error) {
+	q := `
+    SELECT ` + r.dialect.destinationHost + ` AS domain, COUNT(*) AS links, COALESCE(SUM(total_clicks), 0) AS clicks
+      FROM durable_links
+     WHERE deleted_at IS NULL
+       AND ` + r.dialect.destinationHost + ` IS NOT NULL
+     GROUP BY domain
+     ORDER BY links DESC, clicks DESC, domain
+     LIMIT $1`
+	rows, err := r.db.QueryContext(ctx, q, limit)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	destinations := []models.DestinationCount{}
+	for rows.Next() {
+		var destination models.DestinationCount
+		if err := rows.Scan(&destination.Domain, &destination.Links, &destination.Clicks); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		destinations = append(destinations, destination)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return destinations, nil
+}
+
+// TopHosts counts the links that aren't deleted per host, ranked by links and then clicks.
+func (r *dashboardRepository) TopHosts(ctx context.Context, limit int) ([]models.HostCount, error) {
+	const q = `
+    SELECT host, COUNT(*) AS links, COALESCE(SUM(total_clicks), 0) AS clicks
+      FROM durable_links
+     WHERE deleted_at IS NULL
+     GROUP BY host
+     ORDER BY links DESC, clicks DESC, host
+     LIMIT $1`
+	rows, err := r.db.QueryContext(ctx, q, limit)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	hosts := []models.HostCount{}
+	for rows.Next() {
+		var host models.HostCount
+		if err := rows.Scan(&host.Host, &host.Links, &host.Clicks); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		hosts = append(hosts, host)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return hosts, nil
+}
+
+// TableSizes counts the rows of the tables growing with links and traffic, and reads the bytes
+// they take where the database tells. Row counts are exact, so they scan whole tables.
+func (r *dashboardRepository) TableSizes(ctx context.Context) ([]models.TableSize, error) {
+	sizes := make([]models.TableSize, 0, len(dashboardTables))
+	for _, table := range dashboardTables {
+		size := models.TableSize{Table: table}
+		if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table).Scan(&size.Rows); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		if r.dialect.tableBytes != "" {
+			var bytes int64
+			err := r.db.QueryRowContext(ctx, r.dialect.tableBytes, table).Scan(&bytes)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("database error: %w", err)
+			}
+			if err == nil {
+				size.Bytes = &bytes
+			}
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
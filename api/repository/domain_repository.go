@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/db"
+
+	"github.com/lib/pq"
+)
+
+type DomainRepository interface {
+	CreateDomain(ctx context.Context, domain models.DomainConfig) (*models.DomainConfig, error)
+	ListDomains(ctx context.Context) ([]models.DomainConfig, error)
+	GetDomain(ctx context.Context, host string) (*models.DomainConfig, error)
+	UpdateDomain(ctx context.Context, domain models.DomainConfig) (*models.DomainConfig, error)
+	DeleteDomain(ctx context.Context, host string) error
+}
+
+type domainRepository struct {
+	db *sql.DB
+}
+
+func NewDomainRepository(db *sql.DB) DomainRepository {
+	return &domainRepository{
+		db: db,
+	}
+}
+
+// NewDomainRepositoryForDB returns the repository for the dialect of database, running queries
+// on its write pool.
+func NewDomainRepositoryForDB(database *db.DB) DomainRepository {
+	if database.Dialect == db.MySQL {
+		return NewMySQLDomainRepository(database.Write)
+	}
+	return NewDomainRepository(database.Write)
+}
+
+const domainColumns = `host, allowed_domains, default_android_package_name, default_ios_store_id, created_at, updated_at`
+
+func scanDomain(row interface{ Scan(...any) error }) (*models.DomainConfig, error) {
+	var domain models.DomainConfig
+	err := row.Scan(
+		&domain.Host,
+		pq.Array(&domain.AllowedDomains),
+		&domain.DefaultAndroidPackageName,
+		&domain.DefaultIosStoreId,
+		&domain.CreatedAt,
+		&domain.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if domain.AllowedDomains == nil {
+		domain.AllowedDomains = []string{}
+	}
+	return &domain, nil
+}
+
+func (r *domainRepository) CreateDomain(ctx context.Context, domain models.DomainConfig) (*models.DomainConfig, error) {
+	const stmt = `
+    INSERT INTO domains
+      (host, allowed_domains, default_android_package_name, default_ios_store_id)
+    VALUES ($1, $2, $3, $4)
+    RETURNING ` + domainColumns
+	row := r.db.QueryRowContext(ctx, stmt,
+		domain.Host, pq.Array(domain.AllowedDomains), domain.DefaultAndroidPackageName, domain.DefaultIosStoreId)
+	created, err := scanDomain(row)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, apperrors.ErrDomainExists
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return created, nil
+}
+
+func (r *domainRepository) ListDomains(ctx context.Context) ([]models.DomainConfig, error) {
+	const q = `
+    SELECT ` + domainColumns + `
+      FROM domains
+     ORDER BY host`
+	rows, err := r.db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	domains := []models.DomainConfig{}
+	for rows.Next() {
+		domain, err := scanDomain(rows)
+		if err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		domains = append(domains, *domain)
+	}
+	return domains, rows.Err()
+}
+
+func (r *domainRepository) GetDomain(ctx context.Context, host string) (*models.DomainConfig, error) {
+	const q = `
+    SELECT ` + domainColumns + `
+      FROM domains
+     WHERE host = $1`
+	domain, err := scanDomain(r.db.QueryRowContext(ctx, q, host))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrDomainNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return domain, nil
+}
+
+func (r *domainRepository) UpdateDomain(ctx context.Context, domain models.DomainConfig) (*models.DomainConfig, error) {
+	const stmt = `
+    UPDATE domains
+       SET allowed_domains = $2,
+           default_android_package_name = $3,
+           default_ios_store_id = $4,
+           updated_at = now()
+     WHERE host = $1
+    RETURNING ` + domainColumns
+	row := r.db.QueryRowContext(ctx, stmt,
+		domain.Host, pq.Array(domain.AllowedDomains), domain.DefaultAndroidPackageName, domain.DefaultIosStoreId)
+	updated, err := scanDomain(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrDomainNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return updated, nil
+}
+
+func (r *domainRepository) DeleteDomain(ctx context.Context, host string) error {
+	const stmt = `
+    DELETE FROM domains
+     WHERE host = $1`
+	res, err := r.db.ExecContext(ctx, stmt, host)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return apperrors.ErrDomainNotFound
+	}
+	return nil
+}
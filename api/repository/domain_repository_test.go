@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateDomain(t *testing.T) {
+	db, mock, _ := setupMockDB(t)
+	defer db.Close()
+	repo := NewDomainRepository(db)
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"host", "allowed_domains", "default_android_package_name", "default_ios_store_id", "created_at", "updated_at"}
+
+	mock.ExpectQuery(`INSERT INTO domains .* RETURNING host, allowed_domains`).
+		WithArgs("acme.link", pq.Array([]string{"example.com"}), "com.acme.app", "").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("acme.link", "{example.com}", "com.acme.app", "", createdAt, createdAt))
+	mock.ExpectQuery(`INSERT INTO domains`).
+		WithArgs("acme.link", pq.Array([]string{"example.com"}), "com.acme.app", "").
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	domain := models.DomainConfig{Host: "acme.link", AllowedDomains: []string{"example.com"}, DefaultAndroidPackageName: "com.acme.app"}
+	created, err := repo.CreateDomain(context.Background(), domain)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"example.com"}, created.AllowedDomains)
+	assert.Equal(t, createdAt, created.CreatedAt)
+
+	_, err = repo.CreateDomain(context.Background(), domain)
+	assert.ErrorIs(t, err, apperrors.ErrDomainExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateDomain_NotFound(t *testing.T) {
+	db, mock, _ := setupMockDB(t)
+	defer db.Close()
+	repo := NewDomainRepository(db)
+
+	mock.ExpectQuery(`UPDATE domains`).
+		WithArgs("acme.link", pq.Array([]string{"example.com"}), "", "").
+		WillReturnRows(sqlmock.NewRows([]string{"host"}))
+	mock.ExpectExec(`DELETE FROM domains`).
+		WithArgs("acme.link").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, err := repo.UpdateDomain(context.Background(), models.DomainConfig{Host: "acme.link", AllowedDomains: []string{"example.com"}})
+	assert.ErrorIs(t, err, apperrors.ErrDomainNotFound)
+	assert.ErrorIs(t, repo.DeleteDomain(context.Background(), "acme.link"), apperrors.ErrDomainNotFound)
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"durable-links-generator/api/models"
+)
+
+type IdempotencyRepository interface {
+	// ClaimIdempotencyKey records that caller sent key with a request hashing to requestHash,
+	// once the entry of a previous use created before expiredBefore is deleted. It returns nil
+	// when the key is claimed, or the entry of the earlier request using it.
+	ClaimIdempotencyKey(ctx context.Context, caller, key, requestHash string, expiredBefore time.Time) (*models.IdempotentResponse, error)
+	// SaveIdempotentResponse stores the response of the request that claimed the key.
+	SaveIdempotentResponse(ctx context.Context, caller, key string, statusCode int, body string) error
+	// ReleaseIdempotencyKey deletes a claim, so the key can be sent again.
+	ReleaseIdempotencyKey(ctx context.Context, caller, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) IdempotencyRepository {
+	return &idempotencyRepository{
+		db: db,
+	}
+}
+
+func (r *idempotencyRepository) ClaimIdempotencyKey(ctx context.Context, caller, key, requestHash string, expiredBefore time.Time) (*models.IdempotentResponse, error) {
+	const deleteStmt = `
+    DELETE FROM idempotency_keys
+     WHERE caller = $1 AND idempotency_key = $2 AND created_at < $3`
+	if _, err := r.db.ExecContext(ctx, deleteStmt, caller, key, expiredBefore); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	const stmt = `
+    INSERT INTO idempotency_keys
+      (caller, idempotency_key, request_hash, body)
+    VALUES ($1, $2, $3, '')`
+	_, err := r.db.ExecContext(ctx, stmt, caller, key, requestHash)
+	if err == nil {
+		return nil, nil
+	}
+	if !isUniqueViolation(err) {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	const q = `
+    SELECT caller, idempotency_key, request_hash, status_code, body, created_at
+      FROM idempotency_keys
+     WHERE caller = $1 AND idempotency_key = $2`
+	var resp models.IdempotentResponse
+	err = r.db.QueryRowContext(ctx, q, caller, key).Scan(
+		&resp.Caller, &resp.Key, &resp.RequestHash, &resp.StatusCode, &resp.Body, &resp.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &resp, nil
+}
+
+func (r *idempotencyRepository) SaveIdempotentResponse(ctx context.Context, caller, key string, statusCode int, body string) error {
+	const stmt = `
+    UPDATE idempotency_keys
+       SET status_code = $3, body = $4
+     WHERE caller = $1 AND idempotency_key = $2`
+	if _, err := r.db.ExecContext(ctx, stmt, caller, key, statusCode, body); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) ReleaseIdempotencyKey(ctx context.Context, caller, key string) error {
+	const stmt = `
+    DELETE FROM idempotency_keys
+     WHERE caller = $1 AND idempotency_key = $2`
+	if _, err := r.db.ExecContext(ctx, stmt, caller, key); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	const stmt = `
+    DELETE FROM idempotency_keys
+     WHERE created_at < $1`
+	res, err := r.db.ExecContext(ctx, stmt, before)
+	if err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -3,54 +3,168 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/clicks"
+	"durable-links-generator/api/models"
+	"durable-links-generator/db"
+	"durable-links-generator/db/mysql"
+	"durable-links-generator/db/sqlite"
 
+	"github.com/lib/pq"
 	"github.com/rs/zerolog/log"
 )
 
+// isUniqueViolation reports whether err is a Postgres, SQLite or MySQL unique constraint
+// violation.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" || sqlite.IsUniqueViolation(err) || mysql.IsUniqueViolation(err)
+}
+
+// Matches the link that path $2 on host $1 refers to, following an alias.
+const canonicalPathCond = `path = COALESCE(
+             (SELECT path FROM link_aliases WHERE host = $1 AND alias_path = $2),
+             $2)`
+
+// Matches the link that path $2 on host $1 resolves to, following an alias and a successor the
+// link redirects to.
+const resolvedPathCond = `path = (
+             SELECT CASE WHEN redirect_to_successor AND superseded_by IS NOT NULL
+                         THEN superseded_by ELSE path END
+               FROM durable_links
+              WHERE host = $1
+                AND ` + canonicalPathCond + `)`
+
 type LinkRepository interface {
 	GetQueryParamsByHostAndPath(ctx context.Context, host, path string) (string, error)
 	FindExistingShortLink(ctx context.Context, host, rawQS string) (string, error)
 	CreateShortLink(ctx context.Context, host, path, rawQS string, unguessable bool) error
+	CreateShortLinks(ctx context.Context, links []models.StoredLink) error
+	ListLinksByHost(ctx context.Context, host string) ([]models.StoredLink, error)
+	ListLinks(ctx context.Context, host string, page models.LinkPage) ([]models.StoredLink, error)
+	UpdateQueryParams(ctx context.Context, links []models.StoredLink, reason string) error
+	ReplaceQueryParams(ctx context.Context, link models.StoredLink, previous, reason string) error
+	SetLinkMetadata(ctx context.Context, host, path string, metadata models.LinkMetadata) error
+	SetGeoDestinations(ctx context.Context, host, path string, destinations []models.GeoDestination) error
+	ListGeoDestinations(ctx context.Context, host, path string) ([]models.GeoDestination, error)
+	ResolveGeoDestinations(ctx context.Context, host, path string) ([]models.GeoDestination, error)
+	SetDeviceRules(ctx context.Context, host, path string, rules []models.DeviceRule) error
+	ResolveDeviceRules(ctx context.Context, host, path string) ([]models.DeviceRule, error)
+	SetLinkVariants(ctx context.Context, host, path string, variants []models.LinkVariant) error
+	ResolveLinkVariants(ctx context.Context, host, path string) ([]models.LinkVariant, error)
+	ListRevisions(ctx context.Context, host, path string) ([]models.LinkRevision, error)
+	GetCanonicalPath(ctx context.Context, host, path string) (string, error)
+	PathExists(ctx context.Context, host, path string) (bool, error)
+	CreateAlias(ctx context.Context, host, aliasPath, path string) error
+	ListAliases(ctx context.Context, host, path string) ([]string, error)
+	DeleteAlias(ctx context.Context, host, aliasPath string) error
+	MergeLinks(ctx context.Context, host, canonical string, duplicates []string) error
+	MarkSuperseded(ctx context.Context, host, path, successor string, redirect bool) error
+	GetLink(ctx context.Context, host, path string) (*models.StoredLink, error)
+	// GetLinkSnapshot returns the link path refers to as stored, following an alias but not a
+	// successor, and whether or not it is deleted.
+	GetLinkSnapshot(ctx context.Context, host, path string) (*models.LinkSnapshot, error)
+	DeleteLink(ctx context.Context, host, path string) error
+	SoftDeleteLink(ctx context.Context, host, path string) error
+	RestoreLink(ctx context.Context, host, path string) error
+	NextPathSequence(ctx context.Context) (int64, error)
+	CreateCode(ctx context.Context, host, code, path string) error
+	GetCodeByPath(ctx context.Context, host, path string) (string, error)
+	GetPathByCode(ctx context.Context, host, code string) (string, error)
+	SearchLinks(ctx context.Context, host, query, sort string, limit int) ([]models.StoredLink, error)
+	AddClicks(ctx context.Context, counts []clicks.Count) error
+	ClickTotal(ctx context.Context, host, path string) (string, int64, error)
+	UseClick(ctx context.Context, host, path string, maxClicks int64) (bool, error)
+	ListStaleLinks(ctx context.Context, host string, before time.Time, limit int) ([]models.StoredLink, error)
+	ArchiveStaleLinks(ctx context.Context, before time.Time) ([]models.StoredLink, error)
+	UnarchiveLink(ctx context.Context, host, path string) error
+	CountActiveLinks(ctx context.Context, host string) (int64, error)
+	ClickTotals(ctx context.Context, host string, today time.Time) (models.ClickTotals, error)
+	TopLinksSince(ctx context.Context, host string, since time.Time, limit int) ([]models.StoredLink, error)
+	AddClickEvents(ctx context.Context, events []clicks.Event) error
+	ListClickEvents(ctx context.Context, host string, filter models.ClickEventFilter) ([]clicks.Event, error)
+	ClickStats(ctx context.Context, host string, query models.ClickStatsQuery) (*models.ClickStats, error)
 }
 
 type linkRepository struct {
-	db *sql.DB
+	readDB  *sql.DB
+	writeDB *sql.DB
 }
 
 func NewLinkRepository(db *sql.DB) LinkRepository {
+	return NewLinkRepositoryWithPools(db, db)
+}
+
+// NewLinkRepositoryWithPools returns a repository running each query on the pool of the lane
+// its context belongs to.
+func NewLinkRepositoryWithPools(readDB, writeDB *sql.DB) LinkRepository {
 	return &linkRepository{
-		db: db,
+		readDB:  readDB,
+		writeDB: writeDB,
+	}
+}
+
+// NewLinkRepositoryForDB returns the repository for the dialect of database, running queries on
+// its read and write pools.
+func NewLinkRepositoryForDB(database *db.DB) LinkRepository {
+	switch database.Dialect {
+	case db.SQLite:
+		return NewSQLiteLinkRepository(database.DB, database.Write)
+	case db.MySQL:
+		return NewMySQLLinkRepository(database.DB, database.Write)
 	}
+	return NewLinkRepositoryWithPools(database.DB, database.Write)
 }
 
+func (r *linkRepository) conn(ctx context.Context) *sql.DB {
+	if db.LaneFromContext(ctx) == db.LaneWrite {
+		return r.writeDB
+	}
+	return r.readDB
+}
+
+// GetQueryParamsByHostAndPath returns the query params of the link path resolves to. A deleted
+// link fails with ErrLinkDeleted, wrapped with ErrLinkNotFound for callers that don't tell them
+// apart.
 func (r *linkRepository) GetQueryParamsByHostAndPath(ctx context.Context, host, path string) (string, error) {
 	var rawQueryStr string
+	var deleted bool
 
-	row := r.db.QueryRowContext(
+	row := r.conn(ctx).QueryRowContext(
 		ctx,
-		`SELECT query_params
+		`SELECT query_params, deleted_at IS NOT NULL
            FROM durable_links
-          WHERE host = $1 AND path = $2`,
+          WHERE host = $1
+            AND archived_at IS NULL
+            AND `+resolvedPathCond,
 		host,
 		path,
 	)
-	if err := row.Scan(&rawQueryStr); err != nil {
+	if err := row.Scan(&rawQueryStr, &deleted); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			log.Debug().
+			log.Ctx(ctx).Debug().
 				Str("path", path).
 				Msg("Link not found in database")
 			return "", apperrors.ErrLinkNotFound
 		}
-		log.Error().
+		log.Ctx(ctx).Error().
 			Err(err).
 			Str("path", path).
 			Msg("Failed to retrieve link from database")
 		return "", fmt.Errorf("database error: %w", err)
 	}
+	if deleted {
+		return "", fmt.Errorf("%w: %w", apperrors.ErrLinkDeleted, apperrors.ErrLinkNotFound)
+	}
 
 	return rawQueryStr, nil
 }
@@ -63,23 +177,1564 @@ func (r *linkRepository) FindExistingShortLink(ctx context.Context, host, rawQS
      WHERE host                = $1
        AND query_params        = $2
        AND is_unguessable_path = FALSE
+       AND archived_at IS NULL
+       AND deleted_at IS NULL
      LIMIT 1`
-	err := r.db.QueryRowContext(ctx, q, host, rawQS).Scan(&path)
+	err := r.conn(ctx).QueryRowContext(ctx, q, host, rawQS).Scan(&path)
 	return path, err
 }
 
 func (r *linkRepository) CreateShortLink(ctx context.Context, host, path, rawQS string, unguessable bool) error {
 	const stmt = `
     INSERT INTO durable_links
-      (host, path, query_params, is_unguessable_path)
-    VALUES ($1, $2, $3, $4)`
-	_, err := r.db.ExecContext(
+      (host, path, query_params, is_unguessable_path, search_text, active_until)
+    VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.conn(ctx).ExecContext(
 		ctx,
 		stmt,
 		host,
 		path,
 		rawQS,
 		unguessable,
+		searchText(path, rawQS, models.LinkMetadata{}),
+		activeUntil(rawQS),
 	)
+	if isUniqueViolation(err) {
+		return apperrors.ErrPathTaken
+	}
 	return err
 }
+
+// Links per INSERT statement in CreateShortLinks, well below Postgres' limit of 65535 bind
+// params per statement.
+const insertChunkSize = 1000
+
+// CreateShortLinks stores links in one transaction, inserting up to insertChunkSize of them per
+// statement. Either all links are stored or none is.
+func (r *linkRepository) CreateShortLinks(ctx context.Context, links []models.StoredLink) error {
+	tx, err := r.conn(ctx).BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+
+	for chunk := range slices.Chunk(links, insertChunkSize) {
+		var stmt strings.Builder
+		stmt.WriteString(`
+    INSERT INTO durable_links
+      (host, path, query_params, is_unguessable_path, search_text, active_until, name, notes, tags)
+    VALUES `)
+		args := make([]any, 0, len(chunk)*9)
+		for i, link := range chunk {
+			if i > 0 {
+				stmt.WriteString(", ")
+			}
+			n := len(args)
+			fmt.Fprintf(&stmt, "($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9)
+			args = append(args,
+				link.Host, link.Path, link.QueryParams, link.Unguessable, searchText(link.Path, link.QueryParams, link.Metadata),
+				activeUntil(link.QueryParams), link.Metadata.Name, link.Metadata.Notes, tagsArray(link.Metadata.Tags))
+		}
+		if _, err := tx.ExecContext(ctx, stmt.String(), args...); err != nil {
+			if isUniqueViolation(err) {
+				return apperrors.ErrPathTaken
+			}
+			return fmt.Errorf("database error: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *linkRepository) ListLinksByHost(ctx context.Context, host string) ([]models.StoredLink, error) {
+	const q = `
+    SELECT path, query_params, is_unguessable_path, created_at
+      FROM durable_links
+     WHERE host = $1
+     ORDER BY id`
+	rows, err := r.conn(ctx).QueryContext(ctx, q, host)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var links []models.StoredLink
+	for rows.Next() {
+		link := models.StoredLink{Host: host}
+		if err := rows.Scan(&link.Path, &link.QueryParams, &link.Unguessable, &link.CreatedAt); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// sortColumn is a column links can be paged through by, with the type its cursor values are
+// cast to.
+type sortColumn struct {
+	expr string
+	typ  string
+}
+
+// sortColumns maps the sort fields ListLinks accepts to their column.
+var sortColumns = map[string]sortColumn{
+	"createdAt":     {expr: "created_at", typ: "timestamptz"},
+	"clickCount":    {expr: "total_clicks", typ: "bigint"},
+	"lastClickedAt": {expr: "COALESCE(last_clicked_at, 'epoch')", typ: "timestamptz"},
+	"expiresAt":     {expr: "COALESCE(active_until, '9999-12-31 23:59:59Z')", typ: "timestamptz"},
+}
+
+// ListLinks returns one page of the host's links passing page.Filter. Ties on the sort column are broken by id, so
+// pages neither skip nor repeat links.
+func (r *linkRepository) ListLinks(ctx context.Context, host string, page models.LinkPage) ([]models.StoredLink, error) {
+	column, ok := sortColumns[page.Sort]
+	if !ok {
+		return nil, apperrors.ErrInvalidListSort
+	}
+	cmp, order := ">", "ASC"
+	if page.Desc {
+		cmp, order = "<", "DESC"
+	}
+
+	afterValue := sql.NullString{String: page.AfterValue, Valid: page.AfterID != 0}
+	args := []any{host, afterValue, page.AfterID, page.Limit}
+	filters := linkFilterConds(page.Filter, &args)
+
+	q := fmt.Sprintf(`
+    SELECT id, path, query_params, is_unguessable_path, created_at, total_clicks, last_clicked_at,
+           active_until, name, notes, tags
+      FROM durable_links
+     WHERE host = $1
+       AND archived_at IS NULL
+       AND deleted_at IS NULL
+       AND ($3 = 0 OR (%[1]s, id) %[3]s ($2::%[2]s, $3))%[5]s
+     ORDER BY %[1]s %[4]s, id %[4]s
+     LIMIT $4`, column.expr, column.typ, cmp, order, filters)
+	rows, err := r.conn(ctx).QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	return scanLinkRows(rows, host)
+}
+
+// parsedSortColumn is a column links can be paged through by on databases without casts, with
+// the parser of its cursor values.
+type parsedSortColumn struct {
+	expr  string
+	parse func(string) (any, error)
+}
+
+func parseCursorTime(v string) (any, error) {
+	return time.Parse(time.RFC3339Nano, v)
+}
+
+func parseCursorInt(v string) (any, error) {
+	return strconv.ParseInt(v, 10, 64)
+}
+
+// listLinksParsed is ListLinks with the cursor value parsed before the query rather than cast in
+// it, and the filter conditions of filterConds.
+func (r *linkRepository) listLinksParsed(ctx context.Context, host string, page models.LinkPage, columns map[string]parsedSortColumn, filterConds func(models.LinkFilter, *[]any) string) ([]models.StoredLink, error) {
+	column, ok := columns[page.Sort]
+	if !ok {
+		return nil, apperrors.ErrInvalidListSort
+	}
+	cmp, order := ">", "ASC"
+	if page.Desc {
+		cmp, order = "<", "DESC"
+	}
+
+	var afterValue any
+	if page.AfterID != 0 {
+		value, err := column.parse(page.AfterValue)
+		if err != nil {
+			return nil, apperrors.ErrInvalidPageToken
+		}
+		afterValue = value
+	}
+	args := []any{host, afterValue, page.AfterID, page.Limit}
+	filters := filterConds(page.Filter, &args)
+
+	q := fmt.Sprintf(`
+    SELECT id, path, query_params, is_unguessable_path, created_at, total_clicks, last_clicked_at,
+           active_until, name, notes, tags
+      FROM durable_links
+     WHERE host = $1
+       AND archived_at IS NULL
+       AND deleted_at IS NULL
+       AND ($3 = 0 OR (%[1]s, id) %[2]s ($2, $3))%[4]s
+     ORDER BY %[1]s %[3]s, id %[3]s
+     LIMIT $4`, column.expr, cmp, order, filters)
+	rows, err := r.conn(ctx).QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	return scanLinkRows(rows, host)
+}
+
+// linkFilterConds returns the conditions selecting the links filter lets through, appending
+// their params to args.
+func linkFilterConds(filter models.LinkFilter, args *[]any) string {
+	var conds strings.Builder
+	add := func(cond string, arg any) {
+		*args = append(*args, arg)
+		conds.WriteString("\n       AND ")
+		conds.WriteString(strings.ReplaceAll(cond, "$?", fmt.Sprintf("$%d", len(*args))))
+	}
+	if !filter.CreatedFrom.IsZero() {
+		add("created_at >= $?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		add("created_at < $?", filter.CreatedTo)
+	}
+	if filter.Unguessable != nil {
+		add("is_unguessable_path = $?", *filter.Unguessable)
+	}
+	if filter.DestinationDomain != "" {
+		// The domain itself through the index, or any of its subdomains.
+		add("(link_destination_host(query_params) = $? OR link_destination_host(query_params) LIKE '%.' || $?)", filter.DestinationDomain)
+	}
+	if len(filter.Tags) > 0 {
+		add("tags @> $?", pq.Array(filter.Tags))
+	}
+	return conds.String()
+}
+
+// textArrayLinkFilterConds is linkFilterConds for databases storing tags as the text of a
+// Postgres array. pq.Array quotes every element, and tags hold no quotes, so a tag is in the
+// array exactly when it is found in quotes.
+func textArrayLinkFilterConds(filter models.LinkFilter, args *[]any, conds func(models.LinkFilter, *[]any) string) string {
+	tags := filter.Tags
+	filter.Tags = nil
+	s := conds(filter, args)
+	for _, tag := range tags {
+		*args = append(*args, `"`+tag+`"`)
+		s += fmt.Sprintf("\n       AND instr(tags, $%d) > 0", len(*args))
+	}
+	return s
+}
+
+// scanLinkRows reads links of host selected as id, path, query_params, is_unguessable_path,
+// created_at, total_clicks, last_clicked_at, active_until, name, notes and tags.
+func scanLinkRows(rows *sql.Rows, host string) ([]models.StoredLink, error) {
+	var links []models.StoredLink
+	for rows.Next() {
+		link := models.StoredLink{Host: host}
+		var lastClickedAt, activeUntil sql.NullTime
+		if err := rows.Scan(
+			&link.ID, &link.Path, &link.QueryParams, &link.Unguessable, &link.CreatedAt, &link.TotalClicks, &lastClickedAt,
+			&activeUntil, &link.Metadata.Name, &link.Metadata.Notes, pq.Array(&link.Metadata.Tags),
+		); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		if lastClickedAt.Valid {
+			link.LastClickedAt = &lastClickedAt.Time
+		}
+		if activeUntil.Valid {
+			link.ActiveUntil = &activeUntil.Time
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// AddClicks adds click counts to the totals and daily counts of the links they resolved to,
+// following aliases and successors like redirects do. Counts for links deleted in the meantime
+// are dropped.
+func (r *linkRepository) AddClicks(ctx context.Context, counts []clicks.Count) error {
+	tx, err := r.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+
+	totalStmt := `
+    UPDATE durable_links
+       SET total_clicks    = total_clicks + $3,
+           last_clicked_at = GREATEST(last_clicked_at, $4)
+     WHERE host = $1
+       AND ` + resolvedPathCond
+	dailyStmt := `
+    INSERT INTO link_daily_clicks
+      (host, path, day, clicks)
+    SELECT host, path, $4::date, $3
+      FROM durable_links
+     WHERE host = $1
+       AND ` + resolvedPathCond + `
+    ON CONFLICT (host, path, day)
+    DO UPDATE SET clicks = link_daily_clicks.clicks + EXCLUDED.clicks`
+	for _, count := range counts {
+		day := count.LastClickedAt.UTC().Format(time.DateOnly)
+		if _, err := tx.ExecContext(ctx, totalStmt, count.Host, count.Path, count.Clicks, count.LastClickedAt); err != nil {
+			return fmt.Errorf("failed to add clicks: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, dailyStmt, count.Host, count.Path, count.Clicks, day); err != nil {
+			return fmt.Errorf("failed to add daily clicks: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ClickTotal returns the path of the link path resolves to, following aliases and successors like
+// AddClicks does, and its total clicks. The path is empty when there is no such link. It reads
+// from the write pool, so that totals just added are seen.
+func (r *linkRepository) ClickTotal(ctx context.Context, host, path string) (string, int64, error) {
+	q := `
+    SELECT path, total_clicks
+      FROM durable_links
+     WHERE host = $1
+       AND ` + resolvedPathCond
+	var resolved string
+	var total int64
+	err := r.writeDB.QueryRowContext(ctx, q, host, path).Scan(&resolved, &total)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", 0, nil
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("database error: %w", err)
+	}
+	return resolved, total, nil
+}
+
+// UseClick counts a click on the link path resolves to against its limit of maxClicks, and
+// reports whether the click was within the limit. The count is checked and incremented in one
+// statement, so concurrent clicks can't both take the last one.
+func (r *linkRepository) UseClick(ctx context.Context, host, path string, maxClicks int64) (bool, error) {
+	stmt := `
+    UPDATE durable_links
+       SET use_count = use_count + 1
+     WHERE host = $1
+       AND ` + resolvedPathCond + `
+       AND use_count < $3`
+	res, err := r.writeDB.ExecContext(ctx, stmt, host, path, maxClicks)
+	if err != nil {
+		return false, fmt.Errorf("database error: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("database error: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *linkRepository) CountActiveLinks(ctx context.Context, host string) (int64, error) {
+	const q = `
+    SELECT count(*)
+      FROM durable_links
+     WHERE host = $1
+       AND archived_at IS NULL
+       AND deleted_at IS NULL`
+	var n int64
+	if err := r.conn(ctx).QueryRowContext(ctx, q, host).Scan(&n); err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	return n, nil
+}
+
+// ClickTotals sums the host's clicks on today, the UTC day given, and over the last 7 and 30
+// days including today.
+func (r *linkRepository) ClickTotals(ctx context.Context, host string, today time.Time) (models.ClickTotals, error) {
+	const q = `
+    SELECT COALESCE(SUM(clicks) FILTER (WHERE day = $2::date), 0),
+           COALESCE(SUM(clicks) FILTER (WHERE day > $2::date - 7), 0),
+           COALESCE(SUM(clicks), 0)
+      FROM link_daily_clicks
+     WHERE host = $1
+       AND day > $2::date - 30`
+	var totals models.ClickTotals
+	err := r.conn(ctx).QueryRowContext(ctx, q, host, today.UTC().Format(time.DateOnly)).
+		Scan(&totals.Today, &totals.Last7Days, &totals.Last30Days)
+	if err != nil {
+		return totals, fmt.Errorf("database error: %w", err)
+	}
+	return totals, nil
+}
+
+// TopLinksSince returns the host's most clicked links since the given UTC day, with their clicks
+// in that time in TotalClicks.
+func (r *linkRepository) TopLinksSince(ctx context.Context, host string, since time.Time, limit int) ([]models.StoredLink, error) {
+	const q = `
+    SELECT l.path, l.query_params, SUM(d.clicks) AS clicks
+      FROM link_daily_clicks d
+      JOIN durable_links l ON l.host = d.host AND l.path = d.path
+     WHERE d.host = $1
+       AND d.day >= $2::date
+       AND l.archived_at IS NULL
+       AND l.deleted_at IS NULL
+     GROUP BY l.path, l.query_params
+     ORDER BY clicks DESC, l.path
+     LIMIT $3`
+	rows, err := r.conn(ctx).QueryContext(ctx, q, host, since.UTC().Format(time.DateOnly), limit)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var links []models.StoredLink
+	for rows.Next() {
+		link := models.StoredLink{Host: host}
+		if err := rows.Scan(&link.Path, &link.QueryParams, &link.TotalClicks); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// topLinksSinceDay is TopLinksSince comparing days to since as text, for databases without the
+// cast.
+func (r *linkRepository) topLinksSinceDay(ctx context.Context, host string, since time.Time, limit int) ([]models.StoredLink, error) {
+	const q = `
+    SELECT l.path, l.query_params, SUM(d.clicks) AS clicks
+      FROM link_daily_clicks d
+      JOIN durable_links l ON l.host = d.host AND l.path = d.path
+     WHERE d.host = $1
+       AND d.day >= $2
+       AND l.archived_at IS NULL
+       AND l.deleted_at IS NULL
+     GROUP BY l.path, l.query_params
+     ORDER BY clicks DESC, l.path
+     LIMIT $3`
+	rows, err := r.conn(ctx).QueryContext(ctx, q, host, since.UTC().Format(time.DateOnly), limit)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var links []models.StoredLink
+	for rows.Next() {
+		link := models.StoredLink{Host: host}
+		if err := rows.Scan(&link.Path, &link.QueryParams, &link.TotalClicks); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// AddClickEvents stores click events, up to insertChunkSize of them per statement.
+func (r *linkRepository) AddClickEvents(ctx context.Context, events []clicks.Event) error {
+	for chunk := range slices.Chunk(events, insertChunkSize) {
+		var stmt strings.Builder
+		stmt.WriteString(`
+    INSERT INTO link_clicks
+      (host, path, clicked_at, source, platform, country, referrer, user_agent, visitor, variant, consented)
+    VALUES `)
+		args := make([]any, 0, len(chunk)*11)
+		for i, event := range chunk {
+			if i > 0 {
+				stmt.WriteString(", ")
+			}
+			n := len(args)
+			fmt.Fprintf(&stmt, "($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10, n+11)
+			args = append(args, event.Host, event.Path, event.At, event.Source, event.Platform, event.Country, event.Referrer, event.UserAgent, event.Visitor, event.Variant, event.Consented)
+		}
+		if _, err := r.writeDB.ExecContext(ctx, stmt.String(), args...); err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListClickEvents returns the host's click events matching filter, oldest first.
+func (r *linkRepository) ListClickEvents(ctx context.Context, host string, filter models.ClickEventFilter) ([]clicks.Event, error) {
+	q := `
+    SELECT host, path, clicked_at, source, platform, country, referrer, user_agent, visitor, variant, consented
+      FROM link_clicks
+     WHERE host = $1`
+	args := []any{host}
+	if filter.Path != "" {
+		args = append(args, filter.Path)
+		q += fmt.Sprintf(" AND path = $%d", len(args))
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		q += fmt.Sprintf(" AND clicked_at >= $%d", len(args))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		q += fmt.Sprintf(" AND clicked_at < $%d", len(args))
+	}
+	q += " ORDER BY clicked_at, id"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		q += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.conn(ctx).QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	events := []clicks.Event{}
+	for rows.Next() {
+		var event clicks.Event
+		err := rows.Scan(&event.Host, &event.Path, &event.At, &event.Source, &event.Platform, &event.Country, &event.Referrer, &event.UserAgent, &event.Visitor, &event.Variant, &event.Consented)
+		if err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// Matches the click events of link path $2 on host $1, the links it superseded and the aliases
+// of both, between $3 and $4. MarkSuperseded keeps predecessors one hop from their successor.
+const clickStatsCond = `host = $1
+       AND (path = $2
+            OR path IN (SELECT path FROM durable_links WHERE host = $1 AND superseded_by = $2)
+            OR path IN (SELECT alias_path FROM link_aliases
+                         WHERE host = $1
+                           AND (path = $2
+                                OR path IN (SELECT path FROM durable_links WHERE host = $1 AND superseded_by = $2))))
+       AND clicked_at >= $3 AND clicked_at < $4`
+
+// Matches the click events of the links of campaign $2 on host $1 and their aliases, between $3
+// and $4.
+const campaignClickStatsCond = `host = $1
+       AND path IN (SELECT path FROM campaign_links WHERE host = $1 AND campaign_id = $2
+                    UNION
+                    SELECT a.alias_path
+                      FROM link_aliases a
+                      JOIN campaign_links c ON c.host = a.host AND c.path = a.path
+                     WHERE a.host = $1 AND c.campaign_id = $2)
+       AND clicked_at >= $3 AND clicked_at < $4`
+
+// clickStatsSQL is the SQL ClickStats differs in between databases.
+type clickStatsSQL struct {
+	// buckets truncates clicked_at to the start of its UTC time bucket, by granularity.
+	buckets map[string]string
+	// uniqueClicks counts each visitor once, and each click without a known visitor on its own.
+	uniqueClicks string
+	// referrerSite is the lowercased host of the referrer URL, or NULL.
+	referrerSite string
+}
+
+var postgresClickStats = clickStatsSQL{
+	buckets: map[string]string{
+		"hour":  `date_trunc('hour', clicked_at, 'UTC')`,
+		"day":   `date_trunc('day', clicked_at, 'UTC')`,
+		"week":  `date_trunc('week', clicked_at, 'UTC')`,
+		"month": `date_trunc('month', clicked_at, 'UTC')`,
+	},
+	uniqueClicks: `COUNT(DISTINCT NULLIF(visitor, '')) + COUNT(*) FILTER (WHERE visitor = '')`,
+	referrerSite: `lower(substring(referrer FROM '^[A-Za-z][A-Za-z0-9+.-]*://([^/:?#]+)'))`,
+}
+
+// ClickStats counts the clicks on a link, the links it superseded and their aliases, or on the links of a campaign, for the
+// stats API: redirects per UTC time bucket, all resolutions per source and platform, and the
+// sites referring the most redirects, plus the redirects per link for a campaign. Buckets and
+// links without clicks are left out.
+func (r *linkRepository) ClickStats(ctx context.Context, host string, query models.ClickStatsQuery) (*models.ClickStats, error) {
+	return r.clickStats(ctx, host, query, postgresClickStats)
+}
+
+func (r *linkRepository) clickStats(ctx context.Context, host string, query models.ClickStatsQuery, dialect clickStatsSQL) (*models.ClickStats, error) {
+	bucketExpr, ok := dialect.buckets[query.Granularity]
+	if !ok {
+		return nil, apperrors.ErrInvalidGranularity
+	}
+	conn := r.conn(ctx)
+	cond, key := clickStatsCond, query.Path
+	if query.Campaign != "" {
+		cond, key = campaignClickStatsCond, query.Campaign
+	}
+	args := []any{host, key, query.From, query.To}
+	stats := &models.ClickStats{
+		Buckets:      []models.ClickBucket{},
+		Sources:      []models.SourceClicks{},
+		TopReferrers: []models.ReferrerClicks{},
+	}
+
+	totalsQuery := `
+    SELECT COUNT(*), ` + dialect.uniqueClicks + `, COALESCE(SUM(CASE WHEN consented THEN 1 ELSE 0 END), 0)
+      FROM link_clicks
+     WHERE ` + cond + `
+       AND source = 'redirect'`
+	if err := conn.QueryRowContext(ctx, totalsQuery, args...).Scan(&stats.Clicks, &stats.UniqueClicks, &stats.ConsentedClicks); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	bucketsQuery := `
+    SELECT ` + bucketExpr + ` AS bucket, COUNT(*), ` + dialect.uniqueClicks + `
+      FROM link_clicks
+     WHERE ` + cond + `
+       AND source = 'redirect'
+     GROUP BY bucket
+     ORDER BY bucket`
+	rows, err := conn.QueryContext(ctx, bucketsQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var bucket models.ClickBucket
+		var start any
+		if err := rows.Scan(&start, &bucket.Clicks, &bucket.UniqueClicks); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		if bucket.Start, err = bucketTime(start); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		stats.Buckets = append(stats.Buckets, bucket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	sourcesQuery := `
+    SELECT source, platform, COUNT(*), ` + dialect.uniqueClicks + `
+      FROM link_clicks
+     WHERE ` + cond + `
+     GROUP BY source, platform
+     ORDER BY source, platform`
+	rows, err = conn.QueryContext(ctx, sourcesQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var source models.SourceClicks
+		if err := rows.Scan(&source.Source, &source.Platform, &source.Clicks, &source.UniqueClicks); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		stats.Sources = append(stats.Sources, source)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	referrersQuery := `
+    SELECT site, COUNT(*) AS clicks
+      FROM (SELECT ` + dialect.referrerSite + ` AS site
+              FROM link_clicks
+             WHERE ` + cond + `
+               AND source = 'redirect'
+               AND consented
+               AND referrer <> '') referrers
+     WHERE site IS NOT NULL
+     GROUP BY site
+     ORDER BY clicks DESC, site
+     LIMIT $5`
+	rows, err = conn.QueryContext(ctx, referrersQuery, append(args, query.TopReferrers)...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var referrer models.ReferrerClicks
+		if err := rows.Scan(&referrer.Referrer, &referrer.Clicks); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		stats.TopReferrers = append(stats.TopReferrers, referrer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	variantsQuery := `
+    SELECT variant, COUNT(*), ` + dialect.uniqueClicks + `
+      FROM link_clicks
+     WHERE ` + cond + `
+       AND source = 'redirect'
+       AND variant <> ''
+     GROUP BY variant
+     ORDER BY variant`
+	rows, err = conn.QueryContext(ctx, variantsQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var variant models.VariantClicks
+		if err := rows.Scan(&variant.Variant, &variant.Clicks, &variant.UniqueClicks); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		stats.Variants = append(stats.Variants, variant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if query.Campaign == "" {
+		return stats, nil
+	}
+
+	// Clicks on an alias count for the link it is an alias of.
+	linksQuery := `
+    SELECT link, COUNT(*) AS clicks, ` + dialect.uniqueClicks + `
+      FROM (SELECT COALESCE((SELECT a.path
+                               FROM link_aliases a
+                              WHERE a.host = link_clicks.host AND a.alias_path = link_clicks.path), path) AS link,
+                   visitor
+              FROM link_clicks
+             WHERE ` + cond + `
+               AND source = 'redirect') campaign_clicks
+     GROUP BY link
+     ORDER BY clicks DESC, link`
+	rows, err = conn.QueryContext(ctx, linksQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	stats.Links = []models.LinkClicks{}
+	for rows.Next() {
+		var link models.LinkClicks
+		if err := rows.Scan(&link.Path, &link.Clicks, &link.UniqueClicks); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		stats.Links = append(stats.Links, link)
+	}
+	return stats, rows.Err()
+}
+
+// bucketTime reads the start of a time bucket, which databases without a time type for
+// expressions return as text.
+func bucketTime(v any) (time.Time, error) {
+	switch v := v.(type) {
+	case time.Time:
+		return v.UTC(), nil
+	case string:
+		return time.Parse(time.DateTime, v)
+	case []byte:
+		return time.Parse(time.DateTime, string(v))
+	}
+	return time.Time{}, fmt.Errorf("unexpected time bucket %T", v)
+}
+
+// ListStaleLinks returns the host's links that haven't been clicked, or were created without
+// being clicked since, before the given time. Least recently active links come first.
+func (r *linkRepository) ListStaleLinks(ctx context.Context, host string, before time.Time, limit int) ([]models.StoredLink, error) {
+	const q = `
+    SELECT id, path, query_params, is_unguessable_path, created_at, total_clicks, last_clicked_at,
+           active_until, name, notes, tags
+      FROM durable_links
+     WHERE host = $1
+       AND archived_at IS NULL
+       AND deleted_at IS NULL
+       AND COALESCE(last_clicked_at, created_at) < $2
+     ORDER BY COALESCE(last_clicked_at, created_at), id
+     LIMIT $3`
+	rows, err := r.conn(ctx).QueryContext(ctx, q, host, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+	return scanLinkRows(rows, host)
+}
+
+// ArchiveStaleLinks archives the links of all hosts that have been inactive since before and
+// returns them.
+func (r *linkRepository) ArchiveStaleLinks(ctx context.Context, before time.Time) ([]models.StoredLink, error) {
+	const stmt = `
+    UPDATE durable_links
+       SET archived_at = now()
+     WHERE archived_at IS NULL
+       AND deleted_at IS NULL
+       AND COALESCE(last_clicked_at, created_at) < $1
+    RETURNING host, path`
+	rows, err := r.writeDB.QueryContext(ctx, stmt, before)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var links []models.StoredLink
+	for rows.Next() {
+		var link models.StoredLink
+		if err := rows.Scan(&link.Host, &link.Path); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// SoftDeleteLink marks a link deleted. It keeps its path, and can be restored with RestoreLink.
+func (r *linkRepository) SoftDeleteLink(ctx context.Context, host, path string) error {
+	const stmt = `
+    UPDATE durable_links
+       SET deleted_at = now()
+     WHERE host = $1 AND path = $2
+       AND deleted_at IS NULL`
+	res, err := r.conn(ctx).ExecContext(ctx, stmt, host, path)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return apperrors.ErrLinkNotFound
+	}
+	return nil
+}
+
+func (r *linkRepository) RestoreLink(ctx context.Context, host, path string) error {
+	const stmt = `
+    UPDATE durable_links
+       SET deleted_at = NULL
+     WHERE host = $1 AND path = $2
+       AND deleted_at IS NOT NULL`
+	res, err := r.conn(ctx).ExecContext(ctx, stmt, host, path)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return apperrors.ErrLinkNotFound
+	}
+	return nil
+}
+
+func (r *linkRepository) UnarchiveLink(ctx context.Context, host, path string) error {
+	const stmt = `
+    UPDATE durable_links
+       SET archived_at = NULL
+     WHERE host = $1 AND path = $2
+       AND archived_at IS NOT NULL`
+	res, err := r.conn(ctx).ExecContext(ctx, stmt, host, path)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return apperrors.ErrLinkNotFound
+	}
+	return nil
+}
+
+// UpdateQueryParams replaces the query params of the given links in one transaction, keeping the
+// previous values in link_revisions.
+func (r *linkRepository) UpdateQueryParams(ctx context.Context, links []models.StoredLink, reason string) error {
+	tx, err := r.conn(ctx).BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+
+	const revisionStmt = `
+    INSERT INTO link_revisions
+      (host, path, query_params, reason)
+    SELECT host, path, query_params, $3
+      FROM durable_links
+     WHERE host = $1 AND path = $2`
+	const updateStmt = `
+    UPDATE durable_links
+       SET query_params = $3,
+           active_until = $4
+     WHERE host = $1 AND path = $2`
+
+	for _, link := range links {
+		if _, err := tx.ExecContext(ctx, revisionStmt, link.Host, link.Path, reason); err != nil {
+			return fmt.Errorf("failed to record revision: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, updateStmt, link.Host, link.Path, link.QueryParams, activeUntil(link.QueryParams)); err != nil {
+			return fmt.Errorf("failed to update link: %w", err)
+		}
+		if err := refreshSearchText(ctx, tx, link.Host, link.Path); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceQueryParams sets the query params of link if they still are previous, keeping previous
+// in link_revisions. It fails with ErrLinkChanged when they are not.
+func (r *linkRepository) ReplaceQueryParams(ctx context.Context, link models.StoredLink, previous, reason string) error {
+	tx, err := r.conn(ctx).BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+
+	const updateStmt = `
+    UPDATE durable_links
+       SET query_params = $4,
+           active_until = $5
+     WHERE host = $1 AND path = $2
+       AND query_params = $3`
+	res, err := tx.ExecContext(ctx, updateStmt, link.Host, link.Path, previous, link.QueryParams, activeUntil(link.QueryParams))
+	if err != nil {
+		return fmt.Errorf("failed to update link: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return apperrors.ErrLinkChanged
+	}
+	if err := refreshSearchText(ctx, tx, link.Host, link.Path); err != nil {
+		return err
+	}
+
+	const revisionStmt = `
+    INSERT INTO link_revisions
+      (host, path, query_params, reason)
+    VALUES ($1, $2, $3, $4)`
+	if _, err := tx.ExecContext(ctx, revisionStmt, link.Host, link.Path, previous, reason); err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// SetLinkMetadata replaces the name, notes and tags of the link at path, which SearchLinks
+// matches it by.
+func (r *linkRepository) SetLinkMetadata(ctx context.Context, host, path string, metadata models.LinkMetadata) error {
+	tx, err := r.conn(ctx).BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+
+	const stmt = `
+    UPDATE durable_links
+       SET name  = $3,
+           notes = $4,
+           tags  = $5
+     WHERE host = $1 AND path = $2`
+	if _, err := tx.ExecContext(ctx, stmt, host, path, metadata.Name, metadata.Notes, tagsArray(metadata.Tags)); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if err := refreshSearchText(ctx, tx, host, path); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetGeoDestinations replaces the geo destinations of the link at path.
+func (r *linkRepository) SetGeoDestinations(ctx context.Context, host, path string, destinations []models.GeoDestination) error {
+	tx, err := r.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+
+	const deleteStmt = `
+    DELETE FROM link_geo_destinations
+     WHERE host = $1 AND path = $2`
+	if _, err := tx.ExecContext(ctx, deleteStmt, host, path); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	const insertStmt = `
+    INSERT INTO link_geo_destinations
+      (host, path, country, link)
+    VALUES ($1, $2, $3, $4)`
+	for _, destination := range destinations {
+		if _, err := tx.ExecContext(ctx, insertStmt, host, path, destination.Country, destination.Link); err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// ListGeoDestinations returns the geo destinations of the link at path, by country.
+func (r *linkRepository) ListGeoDestinations(ctx context.Context, host, path string) ([]models.GeoDestination, error) {
+	const q = `
+    SELECT country, link
+      FROM link_geo_destinations
+     WHERE host = $1 AND path = $2
+     ORDER BY country`
+	return r.geoDestinations(ctx, q, host, path)
+}
+
+// ResolveGeoDestinations returns the geo destinations of the link path resolves to, as
+// GetQueryParamsByHostAndPath resolves it.
+func (r *linkRepository) ResolveGeoDestinations(ctx context.Context, host, path string) ([]models.GeoDestination, error) {
+	const q = `
+    SELECT country, link
+      FROM link_geo_destinations
+     WHERE host = $1
+       AND ` + resolvedPathCond + `
+     ORDER BY country`
+	return r.geoDestinations(ctx, q, host, path)
+}
+
+func (r *linkRepository) geoDestinations(ctx context.Context, q, host, path string) ([]models.GeoDestination, error) {
+	rows, err := r.conn(ctx).QueryContext(ctx, q, host, path)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	destinations := []models.GeoDestination{}
+	for rows.Next() {
+		var destination models.GeoDestination
+		if err := rows.Scan(&destination.Country, &destination.Link); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		destinations = append(destinations, destination)
+	}
+	return destinations, rows.Err()
+}
+
+// SetLinkVariants replaces the A/B test variants of the link at path.
+func (r *linkRepository) SetLinkVariants(ctx context.Context, host, path string, variants []models.LinkVariant) error {
+	tx, err := r.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+
+	const deleteStmt = `
+    DELETE FROM link_variants
+     WHERE host = $1 AND path = $2`
+	if _, err := tx.ExecContext(ctx, deleteStmt, host, path); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	const insertStmt = `
+    INSERT INTO link_variants
+      (host, path, name, link, weight)
+    VALUES ($1, $2, $3, $4, $5)`
+	for _, variant := range variants {
+		if _, err := tx.ExecContext(ctx, insertStmt, host, path, variant.Name, variant.Link, variant.Weight); err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// ResolveLinkVariants returns the A/B test variants of the link path resolves to, as
+// GetQueryParamsByHostAndPath resolves it, by name.
+func (r *linkRepository) ResolveLinkVariants(ctx context.Context, host, path string) ([]models.LinkVariant, error) {
+	const q = `
+    SELECT name, link, weight
+      FROM link_variants
+     WHERE host = $1
+       AND ` + resolvedPathCond + `
+     ORDER BY name`
+	rows, err := r.conn(ctx).QueryContext(ctx, q, host, path)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	variants := []models.LinkVariant{}
+	for rows.Next() {
+		var variant models.LinkVariant
+		if err := rows.Scan(&variant.Name, &variant.Link, &variant.Weight); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		variants = append(variants, variant)
+	}
+	return variants, rows.Err()
+}
+
+// SetDeviceRules stores the device rules of the link at path as JSON, or NULL when there are
+// none.
+func (r *linkRepository) SetDeviceRules(ctx context.Context, host, path string, rules []models.DeviceRule) error {
+	var encoded sql.NullString
+	if len(rules) > 0 {
+		data, err := json.Marshal(rules)
+		if err != nil {
+			return err
+		}
+		encoded = sql.NullString{String: string(data), Valid: true}
+	}
+	const stmt = `
+    UPDATE durable_links
+       SET device_rules = $3
+     WHERE host = $1 AND path = $2`
+	if _, err := r.conn(ctx).ExecContext(ctx, stmt, host, path, encoded); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// ResolveDeviceRules returns the device rules of the link path resolves to, as
+// GetQueryParamsByHostAndPath resolves it.
+func (r *linkRepository) ResolveDeviceRules(ctx context.Context, host, path string) ([]models.DeviceRule, error) {
+	const q = `
+    SELECT device_rules
+      FROM durable_links
+     WHERE host = $1
+       AND ` + resolvedPathCond
+	var encoded sql.NullString
+	if err := r.conn(ctx).QueryRowContext(ctx, q, host, path).Scan(&encoded); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrLinkNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if !encoded.Valid {
+		return nil, nil
+	}
+	var rules []models.DeviceRule
+	if err := json.Unmarshal([]byte(encoded.String), &rules); err != nil {
+		return nil, fmt.Errorf("invalid stored device rules: %w", err)
+	}
+	return rules, nil
+}
+
+// tagsArray is pq.Array of tags, writing no tags as an empty array rather than NULL.
+func tagsArray(tags []string) any {
+	if tags == nil {
+		tags = []string{}
+	}
+	return pq.Array(tags)
+}
+
+func (r *linkRepository) ListRevisions(ctx context.Context, host, path string) ([]models.LinkRevision, error) {
+	const q = `
+    SELECT query_params, reason, created_at
+      FROM link_revisions
+     WHERE host = $1 AND path = $2
+     ORDER BY created_at DESC, id DESC`
+	rows, err := r.conn(ctx).QueryContext(ctx, q, host, path)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []models.LinkRevision{}
+	for rows.Next() {
+		var rev models.LinkRevision
+		if err := rows.Scan(&rev.QueryParams, &rev.Reason, &rev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+// GetCanonicalPath returns the path of the link that path resolves to, following an alias if
+// path is one.
+func (r *linkRepository) GetCanonicalPath(ctx context.Context, host, path string) (string, error) {
+	const q = `
+    SELECT path
+      FROM durable_links
+     WHERE host = $1
+       AND ` + canonicalPathCond
+	var canonical string
+	if err := r.conn(ctx).QueryRowContext(ctx, q, host, path).Scan(&canonical); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", apperrors.ErrLinkNotFound
+		}
+		return "", fmt.Errorf("database error: %w", err)
+	}
+	return canonical, nil
+}
+
+// PathExists reports whether path is taken on host, either by a link or an alias.
+func (r *linkRepository) PathExists(ctx context.Context, host, path string) (bool, error) {
+	const q = `
+    SELECT EXISTS (SELECT 1 FROM durable_links WHERE host = $1 AND path = $2)
+        OR EXISTS (SELECT 1 FROM link_aliases WHERE host = $1 AND alias_path = $2)`
+	var exists bool
+	if err := r.conn(ctx).QueryRowContext(ctx, q, host, path).Scan(&exists); err != nil {
+		return false, fmt.Errorf("database error: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *linkRepository) CreateAlias(ctx context.Context, host, aliasPath, path string) error {
+	const stmt = `
+    INSERT INTO link_aliases
+      (host, alias_path, path)
+    VALUES ($1, $2, $3)`
+	if _, err := r.conn(ctx).ExecContext(ctx, stmt, host, aliasPath, path); err != nil {
+		if isUniqueViolation(err) {
+			return apperrors.ErrPathTaken
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+func (r *linkRepository) ListAliases(ctx context.Context, host, path string) ([]string, error) {
+	const q = `
+    SELECT alias_path
+      FROM link_aliases
+     WHERE host = $1 AND path = $2
+     ORDER BY created_at`
+	rows, err := r.conn(ctx).QueryContext(ctx, q, host, path)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := []string{}
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		aliases = append(aliases, alias)
+	}
+	return aliases, rows.Err()
+}
+
+func (r *linkRepository) DeleteAlias(ctx context.Context, host, aliasPath string) error {
+	const stmt = `
+    DELETE FROM link_aliases
+     WHERE host = $1 AND alias_path = $2`
+	res, err := r.conn(ctx).ExecContext(ctx, stmt, host, aliasPath)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return apperrors.ErrLinkNotFound
+	}
+	return nil
+}
+
+// Adds the daily clicks of link $2 on host $1 to those of link $3.
+const moveDailyClicksStmt = `
+    INSERT INTO link_daily_clicks
+      (host, path, day, clicks)
+    SELECT host, $3, day, clicks
+      FROM link_daily_clicks
+     WHERE host = $1 AND path = $2
+    ON CONFLICT (host, path, day)
+    DO UPDATE SET clicks = link_daily_clicks.clicks + excluded.clicks`
+
+// MergeLinks turns each duplicate into an alias of canonical in one transaction. Aliases of the
+// duplicates are moved over, their clicks are added to those of canonical and their query params
+// are kept in link_revisions.
+func (r *linkRepository) MergeLinks(ctx context.Context, host, canonical string, duplicates []string) error {
+	return r.mergeLinks(ctx, host, canonical, duplicates, moveDailyClicksStmt)
+}
+
+func (r *linkRepository) mergeLinks(ctx context.Context, host, canonical string, duplicates []string, moveDailyStmt string) error {
+	tx, err := r.conn(ctx).BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+
+	reason := "merged into " + canonical
+	const revisionStmt = `
+    INSERT INTO link_revisions
+      (host, path, query_params, reason)
+    SELECT host, path, query_params, $3
+      FROM durable_links
+     WHERE host = $1 AND path = $2`
+	const moveAliasesStmt = `
+    UPDATE link_aliases
+       SET path = $3
+     WHERE host = $1 AND path = $2`
+	const moveCodesStmt = `
+    UPDATE link_codes
+       SET path = $3
+     WHERE host = $1 AND path = $2`
+	const deleteStmt = `
+    DELETE FROM durable_links
+     WHERE host = $1 AND path = $2`
+	const aliasStmt = `
+    INSERT INTO link_aliases
+      (host, alias_path, path)
+    VALUES ($1, $2, $3)`
+
+	for _, dup := range duplicates {
+		if _, err := tx.ExecContext(ctx, revisionStmt, host, dup, reason); err != nil {
+			return fmt.Errorf("failed to record revision: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, moveAliasesStmt, host, dup, canonical); err != nil {
+			return fmt.Errorf("failed to move aliases: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, moveCodesStmt, host, dup, canonical); err != nil {
+			return fmt.Errorf("failed to move codes: %w", err)
+		}
+		if err := moveClicks(ctx, tx, host, dup, canonical); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, moveDailyStmt, host, dup, canonical); err != nil {
+			return fmt.Errorf("failed to move daily clicks: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, deleteStmt, host, dup); err != nil {
+			return fmt.Errorf("failed to delete duplicate: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, aliasStmt, host, dup, canonical); err != nil {
+			return fmt.Errorf("failed to create alias: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// moveClicks adds the total clicks of link from on host to those of link to, keeping the latest
+// click of both. The latest click is picked here rather than in SQL, which every database
+// writes differently.
+func moveClicks(ctx context.Context, tx *sql.Tx, host, from, to string) error {
+	const readQuery = `
+    SELECT total_clicks, last_clicked_at
+      FROM durable_links
+     WHERE host = $1 AND path = $2`
+	const updateStmt = `
+    UPDATE durable_links
+       SET total_clicks = total_clicks + $3, last_clicked_at = $4
+     WHERE host = $1 AND path = $2`
+
+	var moved int64
+	var movedLast, last sql.NullTime
+	err := tx.QueryRowContext(ctx, readQuery, host, from).Scan(&moved, &movedLast)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read clicks: %w", err)
+	}
+	var total int64
+	if err := tx.QueryRowContext(ctx, readQuery, host, to).Scan(&total, &last); err != nil {
+		return fmt.Errorf("failed to read clicks: %w", err)
+	}
+	if movedLast.Valid && (!last.Valid || movedLast.Time.After(last.Time)) {
+		last = movedLast
+	}
+	if _, err := tx.ExecContext(ctx, updateStmt, host, to, moved, last); err != nil {
+		return fmt.Errorf("failed to move clicks: %w", err)
+	}
+	return nil
+}
+
+// MarkSuperseded points path at its successor. Links already superseded by path are moved along
+// so redirects never need more than one hop.
+func (r *linkRepository) MarkSuperseded(ctx context.Context, host, path, successor string, redirect bool) error {
+	tx, err := r.conn(ctx).BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+
+	const markStmt = `
+    UPDATE durable_links
+       SET superseded_by = $3, redirect_to_successor = $4
+     WHERE host = $1 AND path = $2`
+	res, err := tx.ExecContext(ctx, markStmt, host, path, successor, redirect)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return apperrors.ErrLinkNotFound
+	}
+
+	const chainStmt = `
+    UPDATE durable_links
+       SET superseded_by = $3
+     WHERE host = $1 AND superseded_by = $2`
+	if _, err := tx.ExecContext(ctx, chainStmt, host, path, successor); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetLink returns the link that path resolves to on host.
+func (r *linkRepository) GetLink(ctx context.Context, host, path string) (*models.StoredLink, error) {
+	q := `
+    SELECT path, query_params, is_unguessable_path, created_at, name, notes, tags
+      FROM durable_links
+     WHERE host = $1
+       AND ` + resolvedPathCond
+	link := models.StoredLink{Host: host}
+	err := r.conn(ctx).QueryRowContext(ctx, q, host, path).
+		Scan(&link.Path, &link.QueryParams, &link.Unguessable, &link.CreatedAt, &link.Metadata.Name, &link.Metadata.Notes, pq.Array(&link.Metadata.Tags))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrLinkNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &link, nil
+}
+
+func (r *linkRepository) GetLinkSnapshot(ctx context.Context, host, path string) (*models.LinkSnapshot, error) {
+	q := `
+    SELECT path, query_params, is_unguessable_path, name, notes, tags,
+           COALESCE(superseded_by, ''), archived_at IS NOT NULL, deleted_at IS NOT NULL
+      FROM durable_links
+     WHERE host = $1
+       AND ` + canonicalPathCond
+	var link models.LinkSnapshot
+	err := r.conn(ctx).QueryRowContext(ctx, q, host, path).
+		Scan(&link.Path, &link.QueryParams, &link.Unguessable, &link.Metadata.Name, &link.Metadata.Notes, pq.Array(&link.Metadata.Tags),
+			&link.SupersededBy, &link.Archived, &link.Deleted)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrLinkNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &link, nil
+}
+
+// DeleteLink removes a link outright. Its aliases and codes go with it through the foreign key.
+func (r *linkRepository) DeleteLink(ctx context.Context, host, path string) error {
+	const stmt = `
+    DELETE FROM durable_links
+     WHERE host = $1 AND path = $2`
+	res, err := r.conn(ctx).ExecContext(ctx, stmt, host, path)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return apperrors.ErrLinkNotFound
+	}
+	return nil
+}
+
+func (r *linkRepository) NextPathSequence(ctx context.Context) (int64, error) {
+	var n int64
+	if err := r.conn(ctx).QueryRowContext(ctx, `SELECT nextval('durable_link_path_seq')`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	return n, nil
+}
+
+func (r *linkRepository) CreateCode(ctx context.Context, host, code, path string) error {
+	const stmt = `
+    INSERT INTO link_codes
+      (host, code, path)
+    VALUES ($1, $2, $3)`
+	if _, err := r.conn(ctx).ExecContext(ctx, stmt, host, code, path); err != nil {
+		if isUniqueViolation(err) {
+			return apperrors.ErrCodeTaken
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// GetCodeByPath returns the oldest code of the link at path, or "" when it has none.
+func (r *linkRepository) GetCodeByPath(ctx context.Context, host, path string) (string, error) {
+	const q = `
+    SELECT code
+      FROM link_codes
+     WHERE host = $1 AND path = $2
+     ORDER BY created_at
+     LIMIT 1`
+	var code string
+	if err := r.conn(ctx).QueryRowContext(ctx, q, host, path).Scan(&code); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("database error: %w", err)
+	}
+	return code, nil
+}
+
+func (r *linkRepository) GetPathByCode(ctx context.Context, host, code string) (string, error) {
+	const q = `
+    SELECT path
+      FROM link_codes
+     WHERE host = $1 AND code = $2`
+	var path string
+	if err := r.conn(ctx).QueryRowContext(ctx, q, host, code).Scan(&path); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", apperrors.ErrLinkNotFound
+		}
+		return "", fmt.Errorf("database error: %w", err)
+	}
+	return path, nil
+}
+
+// searchOrders maps the sort orders SearchLinks accepts to their ORDER BY clause.
+var searchOrders = map[string]string{
+	models.SortRelevance: "ts_rank(to_tsvector('simple', search_text), plainto_tsquery('simple', $2)) DESC, id DESC",
+	models.SortNewest:    "created_at DESC, id DESC",
+	models.SortOldest:    "created_at, id",
+}
+
+// SearchLinks returns up to limit links whose destination, social title or description, deep
+// link route, path, name, notes or tags match query, in the given sort order. An empty host
+// searches all hosts.
+func (r *linkRepository) SearchLinks(ctx context.Context, host, query, sort string, limit int) ([]models.StoredLink, error) {
+	order, ok := searchOrders[sort]
+	if !ok {
+		return nil, apperrors.ErrInvalidSort
+	}
+	q := `
+    SELECT host, path, query_params, is_unguessable_path, created_at
+      FROM durable_links
+     WHERE ($1 = '' OR host = $1)
+       AND archived_at IS NULL
+       AND deleted_at IS NULL
+       AND (to_tsvector('simple', search_text) @@ plainto_tsquery('simple', $2)
+            OR search_text ILIKE $3)
+     ORDER BY ` + order + `
+     LIMIT $4`
+	rows, err := r.conn(ctx).QueryContext(ctx, q, host, query, "%"+escapeLike(query)+"%", limit)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var links []models.StoredLink
+	for rows.Next() {
+		var link models.StoredLink
+		if err := rows.Scan(&link.Host, &link.Path, &link.QueryParams, &link.Unguessable, &link.CreatedAt); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// searchedParams are the query params whose decoded values go into search_text.
+var searchedParams = []string{"link", "st", "sd", "dlr"}
+
+// searchText is the text SearchLinks matches a link by.
+func searchText(path, rawQS string, metadata models.LinkMetadata) string {
+	fields := []string{path}
+	if params, err := url.ParseQuery(rawQS); err == nil {
+		for _, key := range searchedParams {
+			if value := params.Get(key); value != "" {
+				fields = append(fields, value)
+			}
+		}
+	}
+	for _, value := range append([]string{metadata.Name, metadata.Notes}, metadata.Tags...) {
+		if value != "" {
+			fields = append(fields, value)
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// refreshSearchText rebuilds the search_text of the link at path from its query params and
+// metadata, after tx changed either. tx holds the lock of the row it updated, so a concurrent
+// change to the other can't leave a stale text behind.
+func refreshSearchText(ctx context.Context, tx *sql.Tx, host, path string) error {
+	const q = `
+    SELECT query_params, name, notes, tags
+      FROM durable_links
+     WHERE host = $1 AND path = $2`
+	var rawQS string
+	var metadata models.LinkMetadata
+	err := tx.QueryRowContext(ctx, q, host, path).Scan(&rawQS, &metadata.Name, &metadata.Notes, pq.Array(&metadata.Tags))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	const stmt = `
+    UPDATE durable_links
+       SET search_text = $3
+     WHERE host = $1 AND path = $2`
+	if _, err := tx.ExecContext(ctx, stmt, host, path, searchText(path, rawQS, metadata)); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// activeUntil is the end of the activation window of a link with rawQS, its "au" param, stored
+// in active_until for links to be sorted by when they expire.
+func activeUntil(rawQS string) sql.NullTime {
+	params, err := url.ParseQuery(rawQS)
+	if err != nil {
+		return sql.NullTime{}
+	}
+	t, err := time.Parse(time.RFC3339, params.Get("au"))
+	if err != nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t, Valid: true}
+}
+
+// escapeLike escapes the LIKE wildcards in s.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
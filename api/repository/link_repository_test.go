@@ -6,10 +6,15 @@ import (
 	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/clicks"
+	"durable-links-generator/api/models"
+	"durable-links-generator/db"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 )
@@ -39,20 +44,33 @@ func TestGetQueryParamsByHostAndPath_Success(t *testing.T) {
 	path := "test"
 	expected := "apn=com.app&amv=1"
 
-	mock.ExpectQuery(`SELECT query_params FROM durable_links`).
+	mock.ExpectQuery(`SELECT query_params, deleted_at IS NOT NULL FROM durable_links`).
 		WithArgs(host, path).
-		WillReturnRows(sqlmock.NewRows([]string{"query_params"}).AddRow(expected))
+		WillReturnRows(sqlmock.NewRows([]string{"query_params", "deleted"}).AddRow(expected, false))
 
 	result, err := repo.GetQueryParamsByHostAndPath(context.Background(), host, path)
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 }
 
+func TestGetQueryParamsByHostAndPath_Deleted(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT query_params, deleted_at IS NOT NULL FROM durable_links`).
+		WithArgs("example.com", "gone").
+		WillReturnRows(sqlmock.NewRows([]string{"query_params", "deleted"}).AddRow("link=x", true))
+
+	_, err := repo.GetQueryParamsByHostAndPath(context.Background(), "example.com", "gone")
+	assert.ErrorIs(t, err, apperrors.ErrLinkDeleted)
+	assert.ErrorIs(t, err, apperrors.ErrLinkNotFound)
+}
+
 func TestGetQueryParamsByHostAndPath_NotFound(t *testing.T) {
 	db, mock, repo := setupMockDB(t)
 	defer db.Close()
 
-	mock.ExpectQuery(`SELECT query_params FROM durable_links`).
+	mock.ExpectQuery(`SELECT query_params, deleted_at IS NOT NULL FROM durable_links`).
 		WithArgs("unknown.com", "notfound").
 		WillReturnError(sql.ErrNoRows)
 
@@ -82,7 +100,7 @@ func TestCreateShortLink(t *testing.T) {
 	defer db.Close()
 
 	mock.ExpectExec(`INSERT INTO durable_links`).
-		WithArgs("example.com", "abc123", "apn=com.app&amv=1", true).
+		WithArgs("example.com", "abc123", "apn=com.app&amv=1", true, "abc123", sql.NullTime{}).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.CreateShortLink(context.Background(), "example.com", "abc123", "apn=com.app&amv=1", true)
@@ -107,7 +125,7 @@ func TestCreateShortLink_DBError(t *testing.T) {
 	defer db.Close()
 
 	mock.ExpectExec(`INSERT INTO durable_links`).
-		WithArgs("example.com", "abc123", "apn=com.app&amv=1", true).
+		WithArgs("example.com", "abc123", "apn=com.app&amv=1", true, "abc123", sql.NullTime{}).
 		WillReturnError(errors.New("insert failed"))
 
 	err := repo.CreateShortLink(context.Background(), "example.com", "abc123", "apn=com.app&amv=1", true)
@@ -115,11 +133,60 @@ func TestCreateShortLink_DBError(t *testing.T) {
 	assert.Contains(t, err.Error(), "insert failed")
 }
 
+func TestCreateShortLink_PathTaken(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectExec(`INSERT INTO durable_links`).
+		WithArgs("example.com", "summer-sale", "link=x", false, "summer-sale x", sql.NullTime{}).
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	err := repo.CreateShortLink(context.Background(), "example.com", "summer-sale", "link=x", false)
+	assert.ErrorIs(t, err, apperrors.ErrPathTaken)
+}
+
+func TestCreateShortLinks(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO durable_links .* VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8, \$9\), \(\$10, \$11, \$12, \$13, \$14, \$15, \$16, \$17, \$18\)`).
+		WithArgs(
+			"example.com", "abc", "link=a", true, "abc a", sql.NullTime{}, "", "", pq.Array([]string{}),
+			"example.com", "def", "link=b", false, "def b Spring sale spring", sql.NullTime{}, "Spring sale", "", pq.Array([]string{"spring"}),
+		).
+		WillReturnResult(sqlmock.NewResult(2, 2))
+	mock.ExpectCommit()
+
+	err := repo.CreateShortLinks(context.Background(), []models.StoredLink{
+		{Host: "example.com", Path: "abc", QueryParams: "link=a", Unguessable: true},
+		{Host: "example.com", Path: "def", QueryParams: "link=b", Metadata: models.LinkMetadata{Name: "Spring sale", Tags: []string{"spring"}}},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateShortLinks_PathTaken(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO durable_links`).
+		WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectRollback()
+
+	err := repo.CreateShortLinks(context.Background(), []models.StoredLink{
+		{Host: "example.com", Path: "abc", QueryParams: "link=a"},
+	})
+	assert.ErrorIs(t, err, apperrors.ErrPathTaken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetQueryParamsByHostAndPath_DBError(t *testing.T) {
 	db, mock, repo := setupMockDB(t)
 	defer db.Close()
 
-	mock.ExpectQuery(`SELECT query_params FROM durable_links`).
+	mock.ExpectQuery(`SELECT query_params, deleted_at IS NOT NULL FROM durable_links`).
 		WithArgs("example.com", "test").
 		WillReturnError(errors.New("connection lost"))
 
@@ -127,3 +194,534 @@ func TestGetQueryParamsByHostAndPath_DBError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "connection lost")
 }
+
+func TestListLinksByHost(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT path, query_params, is_unguessable_path, created_at FROM durable_links`).
+		WithArgs("example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"path", "query_params", "is_unguessable_path", "created_at"}).
+			AddRow("abc", "link=https%3A%2F%2Fa.com", false, time.Now()).
+			AddRow("def", "link=https%3A%2F%2Fb.com", true, time.Now()))
+
+	links, err := repo.ListLinksByHost(context.Background(), "example.com")
+	assert.NoError(t, err)
+	assert.Len(t, links, 2)
+	assert.Equal(t, "example.com", links[0].Host)
+	assert.Equal(t, "def", links[1].Path)
+	assert.True(t, links[1].Unguessable)
+}
+
+func TestListLinks(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT id, path, query_params, is_unguessable_path, created_at, total_clicks, last_clicked_at, active_until, name, notes, tags FROM durable_links WHERE host = \$1 AND archived_at IS NULL AND deleted_at IS NULL AND \(\$3 = 0 OR \(created_at, id\) < \(\$2::timestamptz, \$3\)\) ORDER BY created_at DESC, id DESC`).
+		WithArgs("example.com", sql.NullString{String: "2024-01-02T00:00:00Z", Valid: true}, int64(7), 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "path", "query_params", "is_unguessable_path", "created_at", "total_clicks", "last_clicked_at", "active_until", "name", "notes", "tags"}).
+			AddRow(6, "abc", "link=https%3A%2F%2Fa.com", false, created, 3, nil, nil, "Launch", "", "{launch,spring}"))
+
+	links, err := repo.ListLinks(context.Background(), "example.com", models.LinkPage{
+		Sort: "createdAt", Desc: true, AfterValue: "2024-01-02T00:00:00Z", AfterID: 7, Limit: 2,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, links, 1) {
+		assert.Equal(t, int64(6), links[0].ID)
+		assert.Equal(t, "example.com", links[0].Host)
+		assert.Equal(t, int64(3), links[0].TotalClicks)
+		assert.Nil(t, links[0].LastClickedAt)
+		assert.Equal(t, models.LinkMetadata{Name: "Launch", Tags: []string{"launch", "spring"}}, links[0].Metadata)
+	}
+
+	_, err = repo.ListLinks(context.Background(), "example.com", models.LinkPage{Sort: "path"})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidListSort)
+}
+
+func TestListLinks_Filter(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	unguessable := false
+	mock.ExpectQuery(`FROM durable_links WHERE host = \$1 AND archived_at IS NULL AND .* AND created_at >= \$5 AND created_at < \$6 AND is_unguessable_path = \$7 AND \(link_destination_host\(query_params\) = \$8 OR link_destination_host\(query_params\) LIKE '%\.' \|\| \$8\) AND tags @> \$9 ORDER BY created_at DESC`).
+		WithArgs("example.com", sql.NullString{}, int64(0), 10, from, to, false, "example.org", pq.Array([]string{"spring"})).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "path", "query_params", "is_unguessable_path", "created_at", "total_clicks", "last_clicked_at", "active_until", "name", "notes", "tags"}))
+
+	links, err := repo.ListLinks(context.Background(), "example.com", models.LinkPage{
+		Sort: "createdAt", Desc: true, Limit: 10,
+		Filter: models.LinkFilter{CreatedFrom: from, CreatedTo: to, Unguessable: &unguessable, DestinationDomain: "example.org", Tags: []string{"spring"}},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, links)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSoftDeleteLink(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectExec(`UPDATE durable_links SET deleted_at = now\(\) WHERE host = \$1 AND path = \$2 AND deleted_at IS NULL`).
+		WithArgs("example.com", "abc").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE durable_links SET deleted_at = now\(\)`).
+		WithArgs("example.com", "abc").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.SoftDeleteLink(context.Background(), "example.com", "abc"))
+	assert.ErrorIs(t, repo.SoftDeleteLink(context.Background(), "example.com", "abc"), apperrors.ErrLinkNotFound)
+}
+
+func TestRestoreLink(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectExec(`UPDATE durable_links SET deleted_at = NULL WHERE host = \$1 AND path = \$2 AND deleted_at IS NOT NULL`).
+		WithArgs("example.com", "abc").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.RestoreLink(context.Background(), "example.com", "abc"))
+}
+
+func TestReplaceQueryParams(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	link := models.StoredLink{Host: "example.com", Path: "abc", QueryParams: "link=new"}
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE durable_links SET query_params = \$4, active_until = \$5 WHERE host = \$1 AND path = \$2 AND query_params = \$3`).
+		WithArgs("example.com", "abc", "link=old", "link=new", sql.NullTime{}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT query_params, name, notes, tags FROM durable_links`).
+		WithArgs("example.com", "abc").
+		WillReturnRows(sqlmock.NewRows([]string{"query_params", "name", "notes", "tags"}).AddRow("link=new", "Launch", "", "{spring}"))
+	mock.ExpectExec(`UPDATE durable_links SET search_text = \$3`).
+		WithArgs("example.com", "abc", "abc new Launch spring").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO link_revisions`).
+		WithArgs("example.com", "abc", "link=old", "update").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.ReplaceQueryParams(context.Background(), link, "link=old", "update"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplaceQueryParams_Changed(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE durable_links SET query_params`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	link := models.StoredLink{Host: "example.com", Path: "abc", QueryParams: "link=new"}
+	err := repo.ReplaceQueryParams(context.Background(), link, "link=stale", "update")
+	assert.ErrorIs(t, err, apperrors.ErrLinkChanged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddClicks(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE durable_links SET total_clicks = total_clicks \+ \$3, last_clicked_at = GREATEST\(last_clicked_at, \$4\)`).
+		WithArgs("example.com", "abc", int64(2), at).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO link_daily_clicks .* ON CONFLICT \(host, path, day\)`).
+		WithArgs("example.com", "abc", int64(2), "2024-05-01").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.AddClicks(context.Background(), []clicks.Count{{Host: "example.com", Path: "abc", Clicks: 2, LastClickedAt: at}})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddClickEvents(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectExec(`INSERT INTO link_clicks \(host, path, clicked_at, source, platform, country, referrer, user_agent, visitor, variant, consented\) VALUES \(\$1, .*\), \(\$12, .*\$22\)$`).
+		WithArgs(
+			"example.com", "abc", at, clicks.SourceRedirect, "ios", "AU", "https://news.example", "Safari", "v1", "b", true,
+			"example.com", "def", at, clicks.SourceExchange, "android", "", "", "", "", "", false,
+		).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err := repo.AddClickEvents(context.Background(), []clicks.Event{
+		{Host: "example.com", Path: "abc", At: at, Source: clicks.SourceRedirect, Platform: "ios", Country: "AU", Referrer: "https://news.example", UserAgent: "Safari", Visitor: "v1", Variant: "b", Consented: true},
+		{Host: "example.com", Path: "def", At: at, Source: clicks.SourceExchange, Platform: "android"},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListClickEvents(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	at := from.Add(time.Hour)
+	columns := []string{"host", "path", "clicked_at", "source", "platform", "country", "referrer", "user_agent", "visitor", "variant", "consented"}
+	mock.ExpectQuery(`FROM link_clicks WHERE host = \$1 AND path = \$2 AND clicked_at >= \$3 ORDER BY clicked_at, id LIMIT \$4`).
+		WithArgs("example.com", "abc", from, 10).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("example.com", "abc", at, "redirect", "ios", "AU", "", "", "v1", "b", true))
+
+	events, err := repo.ListClickEvents(context.Background(), "example.com", models.ClickEventFilter{Path: "abc", From: from, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []clicks.Event{{Host: "example.com", Path: "abc", At: at, Source: "redirect", Platform: "ios", Country: "AU", Visitor: "v1", Variant: "b", Consented: true}}, events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClickStats(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 2)
+	mock.ExpectQuery(`SELECT COUNT\(\*\), COUNT\(DISTINCT NULLIF\(visitor, ''\)\) .* FROM link_clicks WHERE host = \$1 .* AND source = 'redirect'$`).
+		WithArgs("example.com", "abc", from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "unique", "consented"}).AddRow(5, 3, 4))
+	mock.ExpectQuery(`SELECT date_trunc\('day', clicked_at, 'UTC'\) AS bucket`).
+		WithArgs("example.com", "abc", from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"bucket", "count", "unique"}).AddRow(from, 5, 3))
+	mock.ExpectQuery(`SELECT source, platform, COUNT\(\*\)`).
+		WithArgs("example.com", "abc", from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"source", "platform", "count", "unique"}).
+			AddRow("exchange", "ios", 1, 1).
+			AddRow("redirect", "android", 5, 3))
+	mock.ExpectQuery(`SELECT site, COUNT\(\*\) AS clicks .* AND consented AND referrer <> ''.* LIMIT \$5`).
+		WithArgs("example.com", "abc", from, to, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"site", "clicks"}).AddRow("news.example.org", 2))
+	mock.ExpectQuery(`SELECT variant, COUNT\(\*\), .* AND variant <> '' GROUP BY variant`).
+		WithArgs("example.com", "abc", from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"variant", "count", "unique"}).AddRow("a", 3, 2).AddRow("b", 2, 1))
+
+	stats, err := repo.ClickStats(context.Background(), "example.com", models.ClickStatsQuery{
+		Path: "abc", From: from, To: to, Granularity: "day", TopReferrers: 10,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ClickStats{
+		Clicks:          5,
+		UniqueClicks:    3,
+		ConsentedClicks: 4,
+		Buckets:         []models.ClickBucket{{Start: from, Clicks: 5, UniqueClicks: 3}},
+		Sources: []models.SourceClicks{
+			{Source: "exchange", Platform: "ios", Clicks: 1, UniqueClicks: 1},
+			{Source: "redirect", Platform: "android", Clicks: 5, UniqueClicks: 3},
+		},
+		TopReferrers: []models.ReferrerClicks{{Referrer: "news.example.org", Clicks: 2}},
+		Variants:     []models.VariantClicks{{Variant: "a", Clicks: 3, UniqueClicks: 2}, {Variant: "b", Clicks: 2, UniqueClicks: 1}},
+	}, stats)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestArchiveStaleLinks(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`UPDATE durable_links SET archived_at = now\(\) WHERE archived_at IS NULL AND deleted_at IS NULL AND COALESCE\(last_clicked_at, created_at\) < \$1 RETURNING host, path`).
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"host", "path"}).AddRow("example.com", "abc"))
+
+	links, err := repo.ArchiveStaleLinks(context.Background(), before)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.StoredLink{{Host: "example.com", Path: "abc"}}, links)
+}
+
+func TestUnarchiveLink_NotArchived(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectExec(`UPDATE durable_links SET archived_at = NULL`).
+		WithArgs("example.com", "abc").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.UnarchiveLink(context.Background(), "example.com", "abc")
+	assert.ErrorIs(t, err, apperrors.ErrLinkNotFound)
+}
+
+func TestClickTotals(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(`FROM link_daily_clicks WHERE host = \$1 AND day > \$2::date - 30`).
+		WithArgs("example.com", "2024-05-01").
+		WillReturnRows(sqlmock.NewRows([]string{"today", "week", "month"}).AddRow(1, 5, 12))
+
+	totals, err := repo.ClickTotals(context.Background(), "example.com", time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, models.ClickTotals{Today: 1, Last7Days: 5, Last30Days: 12}, totals)
+}
+
+func TestUpdateQueryParams(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO link_revisions`).
+		WithArgs("example.com", "abc", "rewrite").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE durable_links SET query_params`).
+		WithArgs("example.com", "abc", "link=new", sql.NullTime{}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT query_params, name, notes, tags FROM durable_links`).
+		WithArgs("example.com", "abc").
+		WillReturnRows(sqlmock.NewRows([]string{"query_params", "name", "notes", "tags"}).AddRow("link=new", "", "", "{}"))
+	mock.ExpectExec(`UPDATE durable_links SET search_text = \$3`).
+		WithArgs("example.com", "abc", "abc new").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.UpdateQueryParams(context.Background(), []models.StoredLink{
+		{Host: "example.com", Path: "abc", QueryParams: "link=new"},
+	}, "rewrite")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateQueryParams_RollsBackOnError(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO link_revisions`).
+		WillReturnError(errors.New("insert failed"))
+	mock.ExpectRollback()
+
+	err := repo.UpdateQueryParams(context.Background(), []models.StoredLink{
+		{Host: "example.com", Path: "abc", QueryParams: "link=new"},
+	}, "rewrite")
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateAlias_PathTaken(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectExec(`INSERT INTO link_aliases`).
+		WithArgs("example.com", "summer", "abc123").
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	err := repo.CreateAlias(context.Background(), "example.com", "summer", "abc123")
+	assert.ErrorIs(t, err, apperrors.ErrPathTaken)
+}
+
+func TestDeleteAlias_NotFound(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectExec(`DELETE FROM link_aliases`).
+		WithArgs("example.com", "summer").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.DeleteAlias(context.Background(), "example.com", "summer")
+	assert.ErrorIs(t, err, apperrors.ErrLinkNotFound)
+}
+
+func TestGetCanonicalPath(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT path FROM durable_links`).
+		WithArgs("example.com", "summer").
+		WillReturnRows(sqlmock.NewRows([]string{"path"}).AddRow("abc123"))
+
+	path, err := repo.GetCanonicalPath(context.Background(), "example.com", "summer")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", path)
+}
+
+func TestMergeLinks(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO link_revisions`).
+		WithArgs("example.com", "dup", "merged into abc").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE link_aliases SET path`).
+		WithArgs("example.com", "dup", "abc").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE link_codes SET path`).
+		WithArgs("example.com", "dup", "abc").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	dupClicked := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT total_clicks, last_clicked_at FROM durable_links`).
+		WithArgs("example.com", "dup").
+		WillReturnRows(sqlmock.NewRows([]string{"total_clicks", "last_clicked_at"}).AddRow(4, dupClicked))
+	mock.ExpectQuery(`SELECT total_clicks, last_clicked_at FROM durable_links`).
+		WithArgs("example.com", "abc").
+		WillReturnRows(sqlmock.NewRows([]string{"total_clicks", "last_clicked_at"}).AddRow(7, dupClicked.AddDate(0, 0, -1)))
+	mock.ExpectExec(`UPDATE durable_links SET total_clicks = total_clicks \+ \$3, last_clicked_at = \$4`).
+		WithArgs("example.com", "abc", int64(4), sql.NullTime{Time: dupClicked, Valid: true}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO link_daily_clicks .* SELECT host, \$3, day, clicks FROM link_daily_clicks`).
+		WithArgs("example.com", "dup", "abc").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`DELETE FROM durable_links`).
+		WithArgs("example.com", "dup").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO link_aliases`).
+		WithArgs("example.com", "dup", "abc").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := repo.MergeLinks(context.Background(), "example.com", "abc", []string{"dup"})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkSuperseded(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE durable_links SET superseded_by = \$3, redirect_to_successor`).
+		WithArgs("example.com", "old", "new", true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE durable_links SET superseded_by = \$3 WHERE host = \$1 AND superseded_by = \$2`).
+		WithArgs("example.com", "old", "new").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	err := repo.MarkSuperseded(context.Background(), "example.com", "old", "new", true)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkSuperseded_NotFound(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE durable_links SET superseded_by`).
+		WithArgs("example.com", "old", "new", false).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := repo.MarkSuperseded(context.Background(), "example.com", "old", "new", false)
+	assert.ErrorIs(t, err, apperrors.ErrLinkNotFound)
+}
+
+func TestDeleteLink(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectExec(`DELETE FROM durable_links`).
+		WithArgs("example.com", "abc123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.DeleteLink(context.Background(), "example.com", "abc123")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLanePools(t *testing.T) {
+	readDB, readMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer readDB.Close()
+	writeDB, writeMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer writeDB.Close()
+
+	repo := NewLinkRepositoryWithPools(readDB, writeDB)
+
+	readMock.ExpectQuery(`SELECT query_params, deleted_at IS NOT NULL FROM durable_links`).
+		WithArgs("example.com", "abc").
+		WillReturnRows(sqlmock.NewRows([]string{"query_params", "deleted"}).AddRow("link=https://a.com", false))
+	_, err = repo.GetQueryParamsByHostAndPath(context.Background(), "example.com", "abc")
+	assert.NoError(t, err)
+
+	writeMock.ExpectQuery(`SELECT query_params, deleted_at IS NOT NULL FROM durable_links`).
+		WithArgs("example.com", "abc").
+		WillReturnRows(sqlmock.NewRows([]string{"query_params", "deleted"}).AddRow("link=https://a.com", false))
+	_, err = repo.GetQueryParamsByHostAndPath(db.WithLane(context.Background(), db.LaneWrite), "example.com", "abc")
+	assert.NoError(t, err)
+
+	assert.NoError(t, readMock.ExpectationsWereMet())
+	assert.NoError(t, writeMock.ExpectationsWereMet())
+}
+
+func TestNextPathSequence(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT nextval\('durable_link_path_seq'\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"nextval"}).AddRow(42))
+
+	n, err := repo.NextPathSequence(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), n)
+}
+
+func TestCreateCode_Taken(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectExec(`INSERT INTO link_codes`).
+		WithArgs("example.com", "123456", "abc123").
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	err := repo.CreateCode(context.Background(), "example.com", "123456", "abc123")
+	assert.ErrorIs(t, err, apperrors.ErrCodeTaken)
+}
+
+func TestGetPathByCode(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT path FROM link_codes`).
+		WithArgs("example.com", "123456").
+		WillReturnRows(sqlmock.NewRows([]string{"path"}).AddRow("abc123"))
+	mock.ExpectQuery(`SELECT path FROM link_codes`).
+		WithArgs("example.com", "654321").
+		WillReturnError(sql.ErrNoRows)
+
+	path, err := repo.GetPathByCode(context.Background(), "example.com", "123456")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", path)
+
+	_, err = repo.GetPathByCode(context.Background(), "example.com", "654321")
+	assert.ErrorIs(t, err, apperrors.ErrLinkNotFound)
+}
+
+func TestSearchLinks(t *testing.T) {
+	db, mock, repo := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT host, path, query_params, is_unguessable_path, created_at FROM durable_links`).
+		WithArgs("", "spring_sale 50%", `%spring\_sale 50\%%`, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"host", "path", "query_params", "is_unguessable_path", "created_at"}).
+			AddRow("a.page.link", "abc", "link=https%3A%2F%2Fa.com%2Fspring_sale", false, time.Now()))
+
+	links, err := repo.SearchLinks(context.Background(), "", "spring_sale 50%", models.SortRelevance, 20)
+	assert.NoError(t, err)
+	if assert.Len(t, links, 1) {
+		assert.Equal(t, "a.page.link", links[0].Host)
+		assert.Equal(t, "abc", links[0].Path)
+	}
+}
+
+func TestSearchLinks_InvalidSort(t *testing.T) {
+	db, _, repo := setupMockDB(t)
+	defer db.Close()
+
+	_, err := repo.SearchLinks(context.Background(), "", "spring", "path; DROP TABLE durable_links", 20)
+	assert.ErrorIs(t, err, apperrors.ErrInvalidSort)
+}
+
+func TestSearchText(t *testing.T) {
+	got := searchText("spring", "link=https%3A%2F%2Fexample.com%2Fspring&st=Spring+Landing+Page&apn=com.app&dlr=promo%2Fspring",
+		models.LinkMetadata{Notes: "Q2 push", Tags: []string{"email", "spring sale"}})
+	assert.Equal(t, "spring https://example.com/spring Spring Landing Page promo/spring Q2 push email spring sale", got)
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+)
+
+// mysqlAppFingerprintRepository reads the creation time of a fingerprint back after adding it,
+// as MySQL has no RETURNING.
+type mysqlAppFingerprintRepository struct {
+	*appFingerprintRepository
+}
+
+func NewMySQLAppFingerprintRepository(db *sql.DB) AppFingerprintRepository {
+	return &mysqlAppFingerprintRepository{appFingerprintRepository: &appFingerprintRepository{db: db}}
+}
+
+func (r *mysqlAppFingerprintRepository) AddAppFingerprint(ctx context.Context, host string, fingerprint models.AndroidAppFingerprint) (*models.AndroidAppFingerprint, error) {
+	const stmt = `
+    INSERT INTO android_app_fingerprints
+      (host, package_name, sha256_fingerprint)
+    VALUES ($1, $2, $3)`
+	if _, err := r.db.ExecContext(ctx, stmt, host, fingerprint.PackageName, fingerprint.SHA256CertFingerprint); err != nil {
+		if isUniqueViolation(err) {
+			return nil, apperrors.ErrFingerprintExists
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	const q = `
+    SELECT created_at
+      FROM android_app_fingerprints
+     WHERE host = $1 AND package_name = $2 AND sha256_fingerprint = $3`
+	if err := r.db.QueryRowContext(ctx, q, host, fingerprint.PackageName, fingerprint.SHA256CertFingerprint).Scan(&fingerprint.CreatedAt); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &fingerprint, nil
+}
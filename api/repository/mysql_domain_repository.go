@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+
+	"github.com/lib/pq"
+)
+
+// mysqlDomainRepository reads domains back after writing them, as MySQL has no RETURNING.
+type mysqlDomainRepository struct {
+	*domainRepository
+}
+
+func NewMySQLDomainRepository(db *sql.DB) DomainRepository {
+	return &mysqlDomainRepository{domainRepository: &domainRepository{db: db}}
+}
+
+func (r *mysqlDomainRepository) CreateDomain(ctx context.Context, domain models.DomainConfig) (*models.DomainConfig, error) {
+	const stmt = `
+    INSERT INTO domains
+      (host, allowed_domains, default_android_package_name, default_ios_store_id)
+    VALUES ($1, $2, $3, $4)`
+	_, err := r.db.ExecContext(ctx, stmt,
+		domain.Host, pq.Array(domain.AllowedDomains), domain.DefaultAndroidPackageName, domain.DefaultIosStoreId)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, apperrors.ErrDomainExists
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return r.GetDomain(ctx, domain.Host)
+}
+
+func (r *mysqlDomainRepository) UpdateDomain(ctx context.Context, domain models.DomainConfig) (*models.DomainConfig, error) {
+	const stmt = `
+    UPDATE domains
+       SET allowed_domains = $2,
+           default_android_package_name = $3,
+           default_ios_store_id = $4,
+           updated_at = now(6)
+     WHERE host = $1`
+	res, err := r.db.ExecContext(ctx, stmt,
+		domain.Host, pq.Array(domain.AllowedDomains), domain.DefaultAndroidPackageName, domain.DefaultIosStoreId)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return nil, apperrors.ErrDomainNotFound
+	}
+	return r.GetDomain(ctx, domain.Host)
+}
@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/clicks"
+	"durable-links-generator/api/models"
+)
+
+// mysqlLinkRepository runs the link queries on MySQL and MariaDB. The mysql connections take the
+// Postgres queries of linkRepository as they are, except those using casts, date arithmetic,
+// FILTER, RETURNING, ON CONFLICT, full text search or sequences, which are written again here.
+type mysqlLinkRepository struct {
+	*linkRepository
+}
+
+func NewMySQLLinkRepository(readDB, writeDB *sql.DB) LinkRepository {
+	return &mysqlLinkRepository{linkRepository: &linkRepository{readDB: readDB, writeDB: writeDB}}
+}
+
+var mysqlSortColumns = map[string]parsedSortColumn{
+	"createdAt":     {expr: "created_at", parse: parseCursorTime},
+	"clickCount":    {expr: "total_clicks", parse: parseCursorInt},
+	"lastClickedAt": {expr: "COALESCE(last_clicked_at, TIMESTAMP '1970-01-01 00:00:00')", parse: parseCursorTime},
+	"expiresAt":     {expr: "COALESCE(active_until, TIMESTAMP '9999-12-31 23:59:59')", parse: parseCursorTime},
+}
+
+func (r *mysqlLinkRepository) ListLinks(ctx context.Context, host string, page models.LinkPage) ([]models.StoredLink, error) {
+	return r.listLinksParsed(ctx, host, page, mysqlSortColumns, func(filter models.LinkFilter, args *[]any) string {
+		return textArrayLinkFilterConds(filter, args, mysqlLinkFilterConds)
+	})
+}
+
+// mysqlLinkFilterConds is linkFilterConds matching the destination domain with a regular
+// expression on the query params, as MySQL has no link_destination_host function.
+func mysqlLinkFilterConds(filter models.LinkFilter, args *[]any) string {
+	domain := filter.DestinationDomain
+	filter.DestinationDomain = ""
+	conds := linkFilterConds(filter, args)
+	if domain == "" {
+		return conds
+	}
+	*args = append(*args, destinationDomainPattern(domain))
+	return conds + fmt.Sprintf("\n       AND query_params REGEXP $%d", len(*args))
+}
+
+// destinationDomainPattern matches form encoded query params whose "link" param is a URL on
+// domain or any of its subdomains.
+func destinationDomainPattern(domain string) string {
+	return `(?i)(^|&)link=[A-Za-z][A-Za-z0-9+.-]*%3A%2F%2F([^%&/?#]+\.)?` + regexp.QuoteMeta(domain) + `([%&/?#]|$)`
+}
+
+// AddClicks looks up the link each count resolved to before updating it, as MySQL can't update
+// a table selected from in a subquery.
+func (r *mysqlLinkRepository) AddClicks(ctx context.Context, counts []clicks.Count) error {
+	tx, err := r.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+
+	resolveQuery := `
+    SELECT path
+      FROM durable_links
+     WHERE host = $1
+       AND ` + resolvedPathCond
+	const totalStmt = `
+    UPDATE durable_links
+       SET total_clicks    = total_clicks + $3,
+           last_clicked_at = GREATEST(COALESCE(last_clicked_at, $4), $4)
+     WHERE host = $1 AND path = $2`
+	const dailyStmt = `
+    INSERT INTO link_daily_clicks
+      (host, path, day, clicks)
+    VALUES ($1, $2, $4, $3)
+    ON DUPLICATE KEY UPDATE clicks = clicks + VALUES(clicks)`
+	for _, count := range counts {
+		var path string
+		if err := tx.QueryRowContext(ctx, resolveQuery, count.Host, count.Path).Scan(&path); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return fmt.Errorf("failed to resolve clicked link: %w", err)
+		}
+		day := count.LastClickedAt.UTC().Format(time.DateOnly)
+		if _, err := tx.ExecContext(ctx, totalStmt, count.Host, path, count.Clicks, count.LastClickedAt); err != nil {
+			return fmt.Errorf("failed to add clicks: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, dailyStmt, count.Host, path, count.Clicks, day); err != nil {
+			return fmt.Errorf("failed to add daily clicks: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// MergeLinks adds the daily clicks of the duplicates with ON DUPLICATE KEY UPDATE.
+func (r *mysqlLinkRepository) MergeLinks(ctx context.Context, host, canonical string, duplicates []string) error {
+	const moveDailyStmt = `
+    INSERT INTO link_daily_clicks
+      (host, path, day, clicks)
+    SELECT d.host, $3, d.day, d.clicks
+      FROM link_daily_clicks d
+     WHERE d.host = $1 AND d.path = $2
+    ON DUPLICATE KEY UPDATE clicks = link_daily_clicks.clicks + VALUES(clicks)`
+	return r.mergeLinks(ctx, host, canonical, duplicates, moveDailyStmt)
+}
+
+// UseClick looks up the link path resolves to before counting the click, as MySQL can't update
+// a table selected from in a subquery.
+func (r *mysqlLinkRepository) UseClick(ctx context.Context, host, path string, maxClicks int64) (bool, error) {
+	resolved, _, err := r.ClickTotal(ctx, host, path)
+	if err != nil || resolved == "" {
+		return false, err
+	}
+	const stmt = `
+    UPDATE durable_links
+       SET use_count = use_count + 1
+     WHERE host = $1 AND path = $2
+       AND use_count < $3`
+	res, err := r.writeDB.ExecContext(ctx, stmt, host, resolved, maxClicks)
+	if err != nil {
+		return false, fmt.Errorf("database error: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("database error: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *mysqlLinkRepository) ClickTotals(ctx context.Context, host string, today time.Time) (models.ClickTotals, error) {
+	const q = `
+    SELECT COALESCE(SUM(CASE WHEN day = DATE($2) THEN clicks END), 0),
+           COALESCE(SUM(CASE WHEN day > DATE($2) - INTERVAL 7 DAY THEN clicks END), 0),
+           COALESCE(SUM(clicks), 0)
+      FROM link_daily_clicks
+     WHERE host = $1
+       AND day > DATE($2) - INTERVAL 30 DAY`
+	var totals models.ClickTotals
+	err := r.conn(ctx).QueryRowContext(ctx, q, host, today.UTC().Format(time.DateOnly)).
+		Scan(&totals.Today, &totals.Last7Days, &totals.Last30Days)
+	if err != nil {
+		return totals, fmt.Errorf("database error: %w", err)
+	}
+	return totals, nil
+}
+
+func (r *mysqlLinkRepository) TopLinksSince(ctx context.Context, host string, since time.Time, limit int) ([]models.StoredLink, error) {
+	return r.topLinksSinceDay(ctx, host, since, limit)
+}
+
+var mysqlClickStats = clickStatsSQL{
+	buckets: map[string]string{
+		"hour":  `DATE_FORMAT(clicked_at, '%Y-%m-%d %H:00:00')`,
+		"day":   `DATE_FORMAT(clicked_at, '%Y-%m-%d 00:00:00')`,
+		"week":  `DATE_FORMAT(clicked_at - INTERVAL WEEKDAY(clicked_at) DAY, '%Y-%m-%d 00:00:00')`,
+		"month": `DATE_FORMAT(clicked_at, '%Y-%m-01 00:00:00')`,
+	},
+	uniqueClicks: `COUNT(DISTINCT NULLIF(visitor, '')) + COALESCE(SUM(visitor = ''), 0)`,
+	referrerSite: `LOWER(SUBSTRING_INDEX(REGEXP_SUBSTR(referrer, '^[A-Za-z][A-Za-z0-9+.-]*://[^/:?#]+'), '://', -1))`,
+}
+
+func (r *mysqlLinkRepository) ClickStats(ctx context.Context, host string, query models.ClickStatsQuery) (*models.ClickStats, error) {
+	return r.clickStats(ctx, host, query, mysqlClickStats)
+}
+
+// ArchiveStaleLinks locks the stale links while reading them, as MySQL has no RETURNING.
+func (r *mysqlLinkRepository) ArchiveStaleLinks(ctx context.Context, before time.Time) ([]models.StoredLink, error) {
+	tx, err := r.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+
+	const staleCond = `archived_at IS NULL
+       AND deleted_at IS NULL
+       AND COALESCE(last_clicked_at, created_at) < $1`
+	rows, err := tx.QueryContext(ctx, `
+    SELECT host, path
+      FROM durable_links
+     WHERE `+staleCond+`
+       FOR UPDATE`, before)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var links []models.StoredLink
+	for rows.Next() {
+		var link models.StoredLink
+		if err := rows.Scan(&link.Host, &link.Path); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+    UPDATE durable_links
+       SET archived_at = now()
+     WHERE `+staleCond, before); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return links, nil
+}
+
+// NextPathSequence increments the single row standing in for the sequence, reading the new
+// value back through LAST_INSERT_ID so concurrent calls never see the same one.
+func (r *mysqlLinkRepository) NextPathSequence(ctx context.Context) (int64, error) {
+	const stmt = `
+    UPDATE durable_link_path_seq
+       SET value = LAST_INSERT_ID(value + 1)
+     WHERE id = 1`
+	res, err := r.conn(ctx).ExecContext(ctx, stmt)
+	if err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	n, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	return n, nil
+}
+
+// mysqlSearchOrders maps the sort orders SearchLinks accepts to their ORDER BY clause.
+var mysqlSearchOrders = map[string]string{
+	models.SortRelevance: "MATCH (search_text) AGAINST ($2) DESC, id DESC",
+	models.SortNewest:    "created_at DESC, id DESC",
+	models.SortOldest:    "created_at, id",
+}
+
+// SearchLinks matches query through the FULLTEXT index of search_text, or as a case insensitive
+// substring of it.
+func (r *mysqlLinkRepository) SearchLinks(ctx context.Context, host, query, sort string, limit int) ([]models.StoredLink, error) {
+	order, ok := mysqlSearchOrders[sort]
+	if !ok {
+		return nil, apperrors.ErrInvalidSort
+	}
+	q := `
+    SELECT host, path, query_params, is_unguessable_path, created_at
+      FROM durable_links
+     WHERE ($1 = '' OR host = $1)
+       AND archived_at IS NULL
+       AND deleted_at IS NULL
+       AND (MATCH (search_text) AGAINST ($2)
+            OR search_text LIKE $3)
+     ORDER BY ` + order + `
+     LIMIT $4`
+	rows, err := r.conn(ctx).QueryContext(ctx, q, host, query, "%"+escapeLike(query)+"%", limit)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var links []models.StoredLink
+	for rows.Next() {
+		var link models.StoredLink
+		if err := rows.Scan(&link.Host, &link.Path, &link.QueryParams, &link.Unguessable, &link.CreatedAt); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
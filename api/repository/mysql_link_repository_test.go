@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/clicks"
+	"durable-links-generator/api/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupMySQLMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock, LinkRepository) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	return db, mock, NewMySQLLinkRepository(db, db)
+}
+
+func TestMySQLAddClicks(t *testing.T) {
+	db, mock, repo := setupMySQLMock(t)
+	defer db.Close()
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT path FROM durable_links WHERE host = \$1 AND path = \(`).
+		WithArgs("example.com", "sale").
+		WillReturnRows(sqlmock.NewRows([]string{"path"}).AddRow("abc"))
+	mock.ExpectExec(`UPDATE durable_links SET total_clicks = total_clicks \+ \$3, last_clicked_at = GREATEST\(COALESCE\(last_clicked_at, \$4\), \$4\) WHERE host = \$1 AND path = \$2`).
+		WithArgs("example.com", "abc", int64(2), at).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO link_daily_clicks .* ON DUPLICATE KEY UPDATE clicks = clicks \+ VALUES\(clicks\)`).
+		WithArgs("example.com", "abc", int64(2), "2024-05-01").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Clicks on a link deleted in the meantime are dropped.
+	mock.ExpectQuery(`SELECT path FROM durable_links`).
+		WithArgs("example.com", "gone").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectCommit()
+
+	err := repo.AddClicks(context.Background(), []clicks.Count{
+		{Host: "example.com", Path: "sale", Clicks: 2, LastClickedAt: at},
+		{Host: "example.com", Path: "gone", Clicks: 1, LastClickedAt: at},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMySQLArchiveStaleLinks(t *testing.T) {
+	db, mock, repo := setupMySQLMock(t)
+	defer db.Close()
+
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT host, path FROM durable_links WHERE archived_at IS NULL .* FOR UPDATE`).
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"host", "path"}).AddRow("example.com", "abc"))
+	mock.ExpectExec(`UPDATE durable_links SET archived_at = now\(\) WHERE archived_at IS NULL`).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	links, err := repo.ArchiveStaleLinks(context.Background(), before)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.StoredLink{{Host: "example.com", Path: "abc"}}, links)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMySQLNextPathSequence(t *testing.T) {
+	db, mock, repo := setupMySQLMock(t)
+	defer db.Close()
+
+	mock.ExpectExec(`UPDATE durable_link_path_seq SET value = LAST_INSERT_ID\(value \+ 1\)`).
+		WillReturnResult(sqlmock.NewResult(42, 1))
+
+	n, err := repo.NextPathSequence(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), n)
+}
+
+func TestMySQLListLinks_Filter(t *testing.T) {
+	db, mock, repo := setupMySQLMock(t)
+	defer db.Close()
+
+	after := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`AND \(\$3 = 0 OR \(COALESCE\(last_clicked_at, TIMESTAMP '1970-01-01 00:00:00'\), id\) < \(\$2, \$3\)\) AND query_params REGEXP \$5 ORDER BY`).
+		WithArgs("example.com", after, int64(7), 10, destinationDomainPattern("example.com")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "path", "query_params", "is_unguessable_path", "created_at", "total_clicks", "last_clicked_at", "active_until", "name", "notes", "tags"}))
+
+	_, err := repo.ListLinks(context.Background(), "example.com", models.LinkPage{
+		Sort:       "lastClickedAt",
+		Desc:       true,
+		Limit:      10,
+		AfterValue: after.Format(time.RFC3339Nano),
+		AfterID:    7,
+		Filter:     models.LinkFilter{DestinationDomain: "example.com"},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDestinationDomainPattern(t *testing.T) {
+	pattern := regexp.MustCompile(destinationDomainPattern("example.com"))
+	assert.True(t, pattern.MatchString("link=https%3A%2F%2Fexample.com"))
+	assert.True(t, pattern.MatchString("apn=a&link=https%3A%2F%2FShop.Example.com%3A8443%2Fx"))
+	assert.False(t, pattern.MatchString("link=https%3A%2F%2Fnotexample.com%2Fx"))
+	assert.False(t, pattern.MatchString("link=https%3A%2F%2Fexample.com.evil.io"))
+	assert.False(t, pattern.MatchString("deeplink=https%3A%2F%2Fexample.com"))
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"durable-links-generator/api/models"
+
+	"github.com/lib/pq"
+)
+
+// mysqlWebhookRepository reads webhooks back after creating them, as MySQL has no RETURNING.
+type mysqlWebhookRepository struct {
+	*webhookRepository
+}
+
+func NewMySQLWebhookRepository(db *sql.DB) WebhookRepository {
+	return &mysqlWebhookRepository{webhookRepository: &webhookRepository{db: db}}
+}
+
+func (r *mysqlWebhookRepository) CreateWebhook(ctx context.Context, hook models.Webhook) (*models.Webhook, error) {
+	const stmt = `
+    INSERT INTO webhooks
+      (host, url, secret, events, click_thresholds)
+    VALUES ($1, $2, $3, $4, $5)`
+	res, err := r.db.ExecContext(ctx, stmt,
+		hook.Host, hook.URL, hook.Secret, pq.Array(hook.Events), pq.Array(hook.ClickThresholds))
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return r.GetWebhook(ctx, hook.Host, id)
+}
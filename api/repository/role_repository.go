@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+)
+
+// RoleRepository stores who has which role: the API keys created through /v1/apiKeys and the
+// roles of OIDC token subjects.
+type RoleRepository interface {
+	CreateAPIKey(ctx context.Context, key models.APIKey, keyHash string) (*models.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	// GetAPIKeyByHash returns the key whose SHA-256 is keyHash.
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, id string) error
+
+	// SetSubjectRole creates or replaces the role of subject.
+	SetSubjectRole(ctx context.Context, subject, role string) (*models.SubjectRole, error)
+	ListSubjectRoles(ctx context.Context) ([]models.SubjectRole, error)
+	GetSubjectRole(ctx context.Context, subject string) (*models.SubjectRole, error)
+	DeleteSubjectRole(ctx context.Context, subject string) error
+}
+
+type roleRepository struct {
+	db *sql.DB
+}
+
+// NewRoleRepository returns the role repository for any dialect: its queries are the same in
+// Postgres, SQLite and MySQL.
+func NewRoleRepository(db *sql.DB) RoleRepository {
+	return &roleRepository{
+		db: db,
+	}
+}
+
+const apiKeyColumns = `id, name, role, created_at`
+
+func scanAPIKey(row interface{ Scan(...any) error }) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := row.Scan(&key.ID, &key.Name, &key.Role, &key.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *roleRepository) CreateAPIKey(ctx context.Context, key models.APIKey, keyHash string) (*models.APIKey, error) {
+	const stmt = `
+    INSERT INTO api_keys (id, key_hash, name, role)
+    VALUES ($1, $2, $3, $4)`
+	if _, err := r.db.ExecContext(ctx, stmt, key.ID, keyHash, key.Name, key.Role); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return r.GetAPIKeyByHash(ctx, keyHash)
+}
+
+func (r *roleRepository) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	const q = `
+    SELECT ` + apiKeyColumns + `
+      FROM api_keys
+     ORDER BY created_at, id`
+	rows, err := r.db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *roleRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	const q = `
+    SELECT ` + apiKeyColumns + `
+      FROM api_keys
+     WHERE key_hash = $1`
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, q, keyHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return key, nil
+}
+
+func (r *roleRepository) DeleteAPIKey(ctx context.Context, id string) error {
+	const stmt = `
+    DELETE FROM api_keys
+     WHERE id = $1`
+	res, err := r.db.ExecContext(ctx, stmt, id)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return apperrors.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+const subjectRoleColumns = `subject, role, updated_at`
+
+func scanSubjectRole(row interface{ Scan(...any) error }) (*models.SubjectRole, error) {
+	var subjectRole models.SubjectRole
+	if err := row.Scan(&subjectRole.Subject, &subjectRole.Role, &subjectRole.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &subjectRole, nil
+}
+
+// SetSubjectRole replaces the row of subject in a transaction, as the dialects don't share an
+// upsert syntax.
+func (r *roleRepository) SetSubjectRole(ctx context.Context, subject, role string) (*models.SubjectRole, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+
+	const deleteStmt = `
+    DELETE FROM subject_roles
+     WHERE subject = $1`
+	if _, err := tx.ExecContext(ctx, deleteStmt, subject); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	const insertStmt = `
+    INSERT INTO subject_roles (subject, role)
+    VALUES ($1, $2)`
+	if _, err := tx.ExecContext(ctx, insertStmt, subject, role); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return r.GetSubjectRole(ctx, subject)
+}
+
+func (r *roleRepository) ListSubjectRoles(ctx context.Context) ([]models.SubjectRole, error) {
+	const q = `
+    SELECT ` + subjectRoleColumns + `
+      FROM subject_roles
+     ORDER BY subject`
+	rows, err := r.db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	subjectRoles := []models.SubjectRole{}
+	for rows.Next() {
+		subjectRole, err := scanSubjectRole(rows)
+		if err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		subjectRoles = append(subjectRoles, *subjectRole)
+	}
+	return subjectRoles, rows.Err()
+}
+
+func (r *roleRepository) GetSubjectRole(ctx context.Context, subject string) (*models.SubjectRole, error) {
+	const q = `
+    SELECT ` + subjectRoleColumns + `
+      FROM subject_roles
+     WHERE subject = $1`
+	subjectRole, err := scanSubjectRole(r.db.QueryRowContext(ctx, q, subject))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrSubjectRoleNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return subjectRole, nil
+}
+
+func (r *roleRepository) DeleteSubjectRole(ctx context.Context, subject string) error {
+	const stmt = `
+    DELETE FROM subject_roles
+     WHERE subject = $1`
+	res, err := r.db.ExecContext(ctx, stmt, subject)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return apperrors.ErrSubjectRoleNotFound
+	}
+	return nil
+}
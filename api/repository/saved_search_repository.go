@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+)
+
+type SavedSearchRepository interface {
+	CreateSavedSearch(ctx context.Context, owner string, search models.SavedSearch) error
+	ListSavedSearches(ctx context.Context, owner string) ([]models.SavedSearch, error)
+	GetSavedSearch(ctx context.Context, owner, name string) (*models.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, owner, name string) error
+}
+
+type savedSearchRepository struct {
+	db *sql.DB
+}
+
+func NewSavedSearchRepository(db *sql.DB) SavedSearchRepository {
+	return &savedSearchRepository{
+		db: db,
+	}
+}
+
+func (r *savedSearchRepository) CreateSavedSearch(ctx context.Context, owner string, search models.SavedSearch) error {
+	const stmt = `
+    INSERT INTO saved_searches
+      (owner, name, query, host, sort)
+    VALUES ($1, $2, $3, $4, $5)`
+	if _, err := r.db.ExecContext(ctx, stmt, owner, search.Name, search.Query, search.Host, search.Sort); err != nil {
+		if isUniqueViolation(err) {
+			return apperrors.ErrSavedSearchExists
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+func (r *savedSearchRepository) ListSavedSearches(ctx context.Context, owner string) ([]models.SavedSearch, error) {
+	const q = `
+    SELECT name, query, host, sort, created_at
+      FROM saved_searches
+     WHERE owner = $1
+     ORDER BY name`
+	rows, err := r.db.QueryContext(ctx, q, owner)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	searches := []models.SavedSearch{}
+	for rows.Next() {
+		var search models.SavedSearch
+		if err := rows.Scan(&search.Name, &search.Query, &search.Host, &search.Sort, &search.CreatedAt); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		searches = append(searches, search)
+	}
+	return searches, rows.Err()
+}
+
+func (r *savedSearchRepository) GetSavedSearch(ctx context.Context, owner, name string) (*models.SavedSearch, error) {
+	const q = `
+    SELECT name, query, host, sort, created_at
+      FROM saved_searches
+     WHERE owner = $1 AND name = $2`
+	var search models.SavedSearch
+	err := r.db.QueryRowContext(ctx, q, owner, name).
+		Scan(&search.Name, &search.Query, &search.Host, &search.Sort, &search.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrSavedSearchNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &search, nil
+}
+
+func (r *savedSearchRepository) DeleteSavedSearch(ctx context.Context, owner, name string) error {
+	const stmt = `
+    DELETE FROM saved_searches
+     WHERE owner = $1 AND name = $2`
+	res, err := r.db.ExecContext(ctx, stmt, owner, name)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return apperrors.ErrSavedSearchNotFound
+	}
+	return nil
+}
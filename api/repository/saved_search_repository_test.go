@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateSavedSearch_Exists(t *testing.T) {
+	db, mock, _ := setupMockDB(t)
+	defer db.Close()
+	repo := NewSavedSearchRepository(db)
+
+	mock.ExpectExec(`INSERT INTO saved_searches`).
+		WithArgs("owner", "spring", "spring", "", models.SortNewest).
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	err := repo.CreateSavedSearch(context.Background(), "owner", models.SavedSearch{Name: "spring", Query: "spring", Sort: models.SortNewest})
+	assert.ErrorIs(t, err, apperrors.ErrSavedSearchExists)
+}
+
+func TestListSavedSearches(t *testing.T) {
+	db, mock, _ := setupMockDB(t)
+	defer db.Close()
+	repo := NewSavedSearchRepository(db)
+
+	mock.ExpectQuery(`SELECT name, query, host, sort, created_at FROM saved_searches`).
+		WithArgs("owner").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "query", "host", "sort", "created_at"}).
+			AddRow("spring", "spring", "a.page.link", models.SortRelevance, time.Now()))
+
+	searches, err := repo.ListSavedSearches(context.Background(), "owner")
+	assert.NoError(t, err)
+	if assert.Len(t, searches, 1) {
+		assert.Equal(t, "a.page.link", searches[0].Host)
+	}
+}
+
+func TestDeleteSavedSearch_NotFound(t *testing.T) {
+	db, mock, _ := setupMockDB(t)
+	defer db.Close()
+	repo := NewSavedSearchRepository(db)
+
+	mock.ExpectExec(`DELETE FROM saved_searches`).
+		WithArgs("owner", "missing").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.DeleteSavedSearch(context.Background(), "owner", "missing")
+	assert.ErrorIs(t, err, apperrors.ErrSavedSearchNotFound)
+}
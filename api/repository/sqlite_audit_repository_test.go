@@ -0,0 +1,67 @@
+//go:build cgo
+
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"durable-links-generator/api/audit"
+	"durable-links-generator/api/models"
+	"durable-links-generator/db/sqlite"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLiteAuditLog(t *testing.T) {
+	db := openSQLite(t)
+	auditRepo := NewAuditRepository(db)
+	repo := NewAuditedLinkRepository(NewSQLiteLinkRepository(db, db), auditRepo)
+	ctx := audit.WithActor(context.Background(), audit.Actor{Key: "0123456789abcdef", Role: models.RoleEditor, RequestID: "req-1"})
+
+	assert.NoError(t, repo.CreateShortLink(ctx, "example.com", "abc", "link=https%3A%2F%2Fexample.org", false))
+	assert.NoError(t, repo.SetLinkMetadata(ctx, "example.com", "abc", models.LinkMetadata{Name: "Spring"}))
+	assert.NoError(t, repo.CreateAlias(ctx, "example.com", "spring", "abc"))
+	assert.NoError(t, repo.SoftDeleteLink(context.Background(), "example.com", "abc"))
+
+	entries, err := auditRepo.ListAuditEntries(ctx, models.AuditFilter{Host: "example.com", Limit: 10})
+	assert.NoError(t, err)
+	if !assert.Len(t, entries, 4) {
+		return
+	}
+	deleted, aliased, updated, created := entries[0], entries[1], entries[2], entries[3]
+
+	assert.Equal(t, models.AuditLinkCreate, created.Action)
+	assert.Equal(t, "0123456789abcdef", created.Actor)
+	assert.Equal(t, models.RoleEditor, created.Role)
+	assert.Equal(t, "req-1", created.RequestID)
+	assert.JSONEq(t, "null", string(created.Before))
+
+	var before, after models.LinkSnapshot
+	assert.NoError(t, json.Unmarshal(updated.Before, &before))
+	assert.NoError(t, json.Unmarshal(updated.After, &after))
+	assert.Equal(t, "", before.Metadata.Name)
+	assert.Equal(t, "Spring", after.Metadata.Name)
+
+	assert.Equal(t, models.AuditAliasCreate, aliased.Action)
+	assert.JSONEq(t, `{"alias":"spring","path":"abc"}`, string(aliased.After))
+
+	assert.Equal(t, models.AuditLinkDelete, deleted.Action)
+	assert.Equal(t, "abc", deleted.Resource)
+	assert.Empty(t, deleted.Actor, "changes without an actor are recorded as made by nobody")
+	assert.NoError(t, json.Unmarshal(deleted.After, &after))
+	assert.True(t, after.Deleted)
+
+	filtered, err := auditRepo.ListAuditEntries(ctx, models.AuditFilter{Actor: "0123456789abcdef", Action: models.AuditLinkUpdate, Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, filtered, 1)
+	filtered, err = auditRepo.ListAuditEntries(ctx, models.AuditFilter{BeforeID: created.ID, Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, filtered)
+
+	_, err = db.Exec(sqlite.Rebind(`UPDATE audit_log SET actor = ''`))
+	assert.Error(t, err, "the audit log is append-only")
+	_, err = db.Exec(`DELETE FROM audit_log`)
+	assert.Error(t, err, "the audit log is append-only")
+}
@@ -0,0 +1,85 @@
+//go:build cgo
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/clicks"
+	"durable-links-generator/api/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLiteCampaigns(t *testing.T) {
+	db := openSQLite(t)
+	links := NewSQLiteLinkRepository(db, db)
+	repo := NewCampaignRepository(db)
+	ctx := context.Background()
+
+	for _, path := range []string{"abc", "def", "ghi"} {
+		assert.NoError(t, links.CreateShortLink(ctx, "acme.link", path, "link=https%3A%2F%2Fshop.example.com%2F"+path, false))
+	}
+	assert.NoError(t, links.CreateAlias(ctx, "acme.link", "sale", "abc"))
+
+	spring, err := repo.CreateCampaign(ctx, models.Campaign{Host: "acme.link", ID: "spring", Name: "Spring sale"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Spring sale", spring.Name)
+	assert.False(t, spring.CreatedAt.IsZero())
+	_, err = repo.CreateCampaign(ctx, models.Campaign{Host: "acme.link", ID: "spring"})
+	assert.ErrorIs(t, err, apperrors.ErrCampaignExists)
+	_, err = repo.CreateCampaign(ctx, models.Campaign{Host: "acme.link", ID: "summer"})
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, repo.AddCampaignLinks(ctx, "acme.link", "winter", []string{"abc"}), apperrors.ErrCampaignNotFound)
+	assert.NoError(t, repo.AddCampaignLinks(ctx, "acme.link", "summer", []string{"abc", "ghi"}))
+	assert.NoError(t, repo.AddCampaignLinks(ctx, "acme.link", "spring", []string{"abc", "def"}))
+	assert.NoError(t, repo.AddCampaignLinks(ctx, "acme.link", "spring", []string{"abc"}), "adding a link twice is a no-op")
+
+	campaigns, err := repo.ListCampaigns(ctx, "acme.link")
+	assert.NoError(t, err)
+	if assert.Len(t, campaigns, 2) {
+		assert.Equal(t, "spring", campaigns[0].ID)
+		assert.Equal(t, int64(2), campaigns[0].LinkCount)
+		assert.Equal(t, int64(1), campaigns[1].LinkCount, "abc moved to spring")
+	}
+
+	monday := time.Date(2024, 5, 13, 9, 0, 0, 0, time.UTC)
+	assert.NoError(t, links.AddClickEvents(ctx, []clicks.Event{
+		{Host: "acme.link", Path: "abc", At: monday, Source: clicks.SourceRedirect, Platform: "ios", Visitor: "v1"},
+		{Host: "acme.link", Path: "sale", At: monday.Add(time.Hour), Source: clicks.SourceRedirect, Platform: "ios", Visitor: "v1"},
+		{Host: "acme.link", Path: "def", At: monday.Add(2 * time.Hour), Source: clicks.SourceRedirect, Platform: "web"},
+		{Host: "acme.link", Path: "ghi", At: monday.Add(3 * time.Hour), Source: clicks.SourceRedirect, Platform: "web"},
+	}))
+	stats, err := links.ClickStats(ctx, "acme.link", models.ClickStatsQuery{
+		Campaign:     "spring",
+		From:         monday.AddDate(0, 0, -1),
+		To:           monday.AddDate(0, 0, 1),
+		Granularity:  "day",
+		TopReferrers: 10,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), stats.Clicks, "clicks on ghi aren't counted")
+	assert.Equal(t, int64(2), stats.UniqueClicks)
+	assert.Equal(t, []models.LinkClicks{
+		{Path: "abc", Clicks: 2, UniqueClicks: 1},
+		{Path: "def", Clicks: 1, UniqueClicks: 1},
+	}, stats.Links, "clicks on the alias count for abc")
+
+	assert.NoError(t, repo.RemoveCampaignLink(ctx, "acme.link", "spring", "def"))
+	assert.ErrorIs(t, repo.RemoveCampaignLink(ctx, "acme.link", "spring", "def"), apperrors.ErrLinkNotFound)
+	assert.NoError(t, links.SoftDeleteLink(ctx, "acme.link", "ghi"))
+	summerLinks, err := repo.ListCampaignLinks(ctx, "acme.link", "summer")
+	assert.NoError(t, err)
+	assert.Empty(t, summerLinks, "deleted links aren't listed")
+
+	assert.NoError(t, repo.DeleteCampaign(ctx, "acme.link", "spring"))
+	assert.ErrorIs(t, repo.DeleteCampaign(ctx, "acme.link", "spring"), apperrors.ErrCampaignNotFound)
+	_, err = repo.GetCampaign(ctx, "acme.link", "spring")
+	assert.ErrorIs(t, err, apperrors.ErrCampaignNotFound)
+	_, err = links.GetLink(ctx, "acme.link", "abc")
+	assert.NoError(t, err, "links outlive their campaign")
+}
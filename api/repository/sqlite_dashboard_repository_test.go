@@ -0,0 +1,95 @@
+//go:build cgo
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/clicks"
+	"durable-links-generator/api/models"
+	"durable-links-generator/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLiteDashboard(t *testing.T) {
+	conn := openSQLite(t)
+	links := NewSQLiteLinkRepository(conn, conn)
+	repo := NewDashboardRepositoryForDB(&db.DB{DB: conn, Write: conn, Dialect: db.SQLite})
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	for _, link := range []struct{ host, path, params string }{
+		{"acme.link", "abc", "link=https%3A%2F%2Fshop.example.com%2Fabc"},
+		{"acme.link", "def", "link=https%3A%2F%2Fshop.example.com%2Fdef"},
+		{"acme.link", "ghi", "link=https%3A%2F%2Fblog.example.com%2Fghi"},
+		{"beta.link", "x", "link=https%3A%2F%2FSHOP.example.com%3A8443%2Fx"},
+		{"beta.link", "y", "apn=com.example.app"},
+		{"beta.link", "z", "link=https%3A%2F%2Fblog.example.com%2Fz"},
+	} {
+		assert.NoError(t, links.CreateShortLink(ctx, link.host, link.path, link.params, false))
+	}
+	assert.NoError(t, links.AddClicks(ctx, []clicks.Count{
+		{Host: "acme.link", Path: "abc", Clicks: 5, LastClickedAt: now.Add(24 * time.Hour)},
+		{Host: "beta.link", Path: "x", Clicks: 2, LastClickedAt: now.Add(24 * time.Hour)},
+	}))
+	assert.NoError(t, links.SoftDeleteLink(ctx, "acme.link", "ghi"))
+	archived, err := links.ArchiveStaleLinks(ctx, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, archived, 3, "def, y and z were never clicked")
+
+	overview, err := repo.Overview(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, models.DashboardOverview{
+		Links:         6,
+		ActiveLinks:   2,
+		ArchivedLinks: 3,
+		DeletedLinks:  1,
+		Hosts:         2,
+		Clicks:        7,
+	}, overview)
+
+	created, err := repo.LinksCreatedPerDay(ctx, now.Add(-24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, []models.DailyCount{{Day: now.Format(time.DateOnly), Count: 6}}, created)
+	created, err = repo.LinksCreatedPerDay(ctx, now.Add(24*time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, created)
+
+	destinations, err := repo.TopDestinations(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.DestinationCount{
+		{Domain: "shop.example.com", Links: 3, Clicks: 7},
+		{Domain: "blog.example.com", Links: 1, Clicks: 0},
+	}, destinations, "deleted links and links without a destination are left out")
+
+	hosts, err := repo.TopHosts(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.HostCount{{Host: "beta.link", Links: 3, Clicks: 2}}, hosts)
+
+	monday := time.Date(2024, 5, 13, 22, 0, 0, 0, time.UTC)
+	assert.NoError(t, links.AddClickEvents(ctx, []clicks.Event{
+		{Host: "acme.link", Path: "abc", At: monday, Source: clicks.SourceRedirect, Platform: "ios"},
+		{Host: "acme.link", Path: "abc", At: monday.Add(time.Hour), Source: clicks.SourceRedirect, Platform: "ios"},
+		{Host: "beta.link", Path: "x", At: monday.Add(3 * time.Hour), Source: clicks.SourceRedirect, Platform: "web"},
+	}))
+	events, err := repo.ClickEventsPerDay(ctx, monday)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.DailyCount{{Day: "2024-05-13", Count: 2}, {Day: "2024-05-14", Count: 1}}, events)
+	events, err = repo.ClickEventsPerDay(ctx, monday.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, []models.DailyCount{{Day: "2024-05-13", Count: 1}, {Day: "2024-05-14", Count: 1}}, events)
+
+	sizes, err := repo.TableSizes(ctx)
+	assert.NoError(t, err)
+	rows := map[string]int64{}
+	for _, size := range sizes {
+		assert.Nil(t, size.Bytes, "SQLite doesn't report table sizes")
+		rows[size.Table] = size.Rows
+	}
+	assert.Equal(t, int64(6), rows["durable_links"])
+	assert.Equal(t, int64(3), rows["link_clicks"])
+	assert.Contains(t, rows, "link_revisions")
+}
@@ -0,0 +1,55 @@
+//go:build cgo
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLiteIdempotencyKeys(t *testing.T) {
+	repo := NewIdempotencyRepository(openSQLite(t))
+	ctx := context.Background()
+	longAgo := time.Now().Add(-time.Hour)
+
+	existing, err := repo.ClaimIdempotencyKey(ctx, "key:abc", "retry-1", "hash", longAgo)
+	assert.NoError(t, err)
+	assert.Nil(t, existing)
+
+	existing, err = repo.ClaimIdempotencyKey(ctx, "key:abc", "retry-1", "other", longAgo)
+	assert.NoError(t, err)
+	if assert.NotNil(t, existing) {
+		assert.Equal(t, "hash", existing.RequestHash)
+		assert.Zero(t, existing.StatusCode)
+	}
+
+	// Keys are per caller.
+	existing, err = repo.ClaimIdempotencyKey(ctx, "ip:10.0.0.1", "retry-1", "hash", longAgo)
+	assert.NoError(t, err)
+	assert.Nil(t, existing)
+
+	assert.NoError(t, repo.SaveIdempotentResponse(ctx, "key:abc", "retry-1", 200, `{"shortLink":"https://acme.link/x"}`))
+	existing, err = repo.ClaimIdempotencyKey(ctx, "key:abc", "retry-1", "hash", longAgo)
+	assert.NoError(t, err)
+	if assert.NotNil(t, existing) {
+		assert.Equal(t, 200, existing.StatusCode)
+		assert.Equal(t, `{"shortLink":"https://acme.link/x"}`, existing.Body)
+	}
+
+	// Once expired, the key is claimed again.
+	existing, err = repo.ClaimIdempotencyKey(ctx, "key:abc", "retry-1", "other", time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Nil(t, existing)
+
+	assert.NoError(t, repo.ReleaseIdempotencyKey(ctx, "key:abc", "retry-1"))
+	existing, err = repo.ClaimIdempotencyKey(ctx, "key:abc", "retry-1", "hash", longAgo)
+	assert.NoError(t, err)
+	assert.Nil(t, existing)
+
+	deleted, err := repo.DeleteExpiredIdempotencyKeys(ctx, time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/clicks"
+	"durable-links-generator/api/models"
+)
+
+// sqliteLinkRepository runs the link queries on SQLite. The sqlite driver takes the Postgres
+// queries of linkRepository as they are, except those using casts, date arithmetic, regular
+// expressions, full text search or sequences, which are written again here.
+type sqliteLinkRepository struct {
+	*linkRepository
+}
+
+func NewSQLiteLinkRepository(readDB, writeDB *sql.DB) LinkRepository {
+	return &sqliteLinkRepository{linkRepository: &linkRepository{readDB: readDB, writeDB: writeDB}}
+}
+
+var sqliteSortColumns = map[string]parsedSortColumn{
+	"createdAt":     {expr: "created_at", parse: parseCursorTime},
+	"clickCount":    {expr: "total_clicks", parse: parseCursorInt},
+	"lastClickedAt": {expr: "COALESCE(last_clicked_at, '1970-01-01 00:00:00.000')", parse: parseCursorTime},
+	"expiresAt":     {expr: "COALESCE(active_until, '9999-12-31 23:59:59.000')", parse: parseCursorTime},
+}
+
+func (r *sqliteLinkRepository) ListLinks(ctx context.Context, host string, page models.LinkPage) ([]models.StoredLink, error) {
+	return r.listLinksParsed(ctx, host, page, sqliteSortColumns, func(filter models.LinkFilter, args *[]any) string {
+		return textArrayLinkFilterConds(filter, args, linkFilterConds)
+	})
+}
+
+func (r *sqliteLinkRepository) AddClicks(ctx context.Context, counts []clicks.Count) error {
+	tx, err := r.writeDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+
+	totalStmt := `
+    UPDATE durable_links
+       SET total_clicks    = total_clicks + $3,
+           last_clicked_at = max(COALESCE(last_clicked_at, $4), $4)
+     WHERE host = $1
+       AND ` + resolvedPathCond
+	dailyStmt := `
+    INSERT INTO link_daily_clicks
+      (host, path, day, clicks)
+    SELECT host, path, $4, $3
+      FROM durable_links
+     WHERE host = $1
+       AND ` + resolvedPathCond + `
+    ON CONFLICT (host, path, day)
+    DO UPDATE SET clicks = link_daily_clicks.clicks + excluded.clicks`
+	for _, count := range counts {
+		day := count.LastClickedAt.UTC().Format(time.DateOnly)
+		if _, err := tx.ExecContext(ctx, totalStmt, count.Host, count.Path, count.Clicks, count.LastClickedAt); err != nil {
+			return fmt.Errorf("failed to add clicks: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, dailyStmt, count.Host, count.Path, count.Clicks, day); err != nil {
+			return fmt.Errorf("failed to add daily clicks: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *sqliteLinkRepository) ClickTotals(ctx context.Context, host string, today time.Time) (models.ClickTotals, error) {
+	const q = `
+    SELECT COALESCE(SUM(clicks) FILTER (WHERE day = $2), 0),
+           COALESCE(SUM(clicks) FILTER (WHERE day > date($2, '-7 days')), 0),
+           COALESCE(SUM(clicks), 0)
+      FROM link_daily_clicks
+     WHERE host = $1
+       AND day > date($2, '-30 days')`
+	var totals models.ClickTotals
+	err := r.conn(ctx).QueryRowContext(ctx, q, host, today.UTC().Format(time.DateOnly)).
+		Scan(&totals.Today, &totals.Last7Days, &totals.Last30Days)
+	if err != nil {
+		return totals, fmt.Errorf("database error: %w", err)
+	}
+	return totals, nil
+}
+
+func (r *sqliteLinkRepository) TopLinksSince(ctx context.Context, host string, since time.Time, limit int) ([]models.StoredLink, error) {
+	return r.topLinksSinceDay(ctx, host, since, limit)
+}
+
+var sqliteClickStats = clickStatsSQL{
+	buckets: map[string]string{
+		"hour":  `strftime('%Y-%m-%d %H:00:00', clicked_at)`,
+		"day":   `strftime('%Y-%m-%d 00:00:00', clicked_at)`,
+		"week":  `strftime('%Y-%m-%d 00:00:00', clicked_at, '-6 days', 'weekday 1')`,
+		"month": `strftime('%Y-%m-01 00:00:00', clicked_at)`,
+	},
+	uniqueClicks: postgresClickStats.uniqueClicks,
+	referrerSite: `url_host(referrer)`,
+}
+
+func (r *sqliteLinkRepository) ClickStats(ctx context.Context, host string, query models.ClickStatsQuery) (*models.ClickStats, error) {
+	return r.clickStats(ctx, host, query, sqliteClickStats)
+}
+
+func (r *sqliteLinkRepository) NextPathSequence(ctx context.Context) (int64, error) {
+	const stmt = `
+    UPDATE durable_link_path_seq
+       SET value = value + 1
+    RETURNING value`
+	var n int64
+	if err := r.conn(ctx).QueryRowContext(ctx, stmt).Scan(&n); err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	return n, nil
+}
+
+// sqliteSearchOrders maps the sort orders SearchLinks accepts to their ORDER BY clause. Without
+// full text search, relevance falls back to newest first.
+var sqliteSearchOrders = map[string]string{
+	models.SortRelevance: "created_at DESC, id DESC",
+	models.SortNewest:    "created_at DESC, id DESC",
+	models.SortOldest:    "created_at, id",
+}
+
+// SearchLinks matches query as a case insensitive substring of the search text, for ASCII
+// letters.
+func (r *sqliteLinkRepository) SearchLinks(ctx context.Context, host, query, sort string, limit int) ([]models.StoredLink, error) {
+	order, ok := sqliteSearchOrders[sort]
+	if !ok {
+		return nil, apperrors.ErrInvalidSort
+	}
+	q := `
+    SELECT host, path, query_params, is_unguessable_path, created_at
+      FROM durable_links
+     WHERE ($1 = '' OR host = $1)
+       AND archived_at IS NULL
+       AND deleted_at IS NULL
+       AND search_text LIKE $2 ESCAPE '\'
+     ORDER BY ` + order + `
+     LIMIT $3`
+	rows, err := r.conn(ctx).QueryContext(ctx, q, host, "%"+escapeLike(query)+"%", limit)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var links []models.StoredLink
+	for rows.Next() {
+		var link models.StoredLink
+		if err := rows.Scan(&link.Host, &link.Path, &link.QueryParams, &link.Unguessable, &link.CreatedAt); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
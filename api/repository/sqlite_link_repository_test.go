@@ -0,0 +1,403 @@
+//go:build cgo
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/clicks"
+	"durable-links-generator/api/models"
+	"durable-links-generator/db/sqlite"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setupSQLite(t *testing.T) LinkRepository {
+	db := openSQLite(t)
+	return NewSQLiteLinkRepository(db, db)
+}
+
+// openSQLite opens an in-memory database with the schema.
+func openSQLite(t *testing.T) *sql.DB {
+	db, err := sql.Open(sqlite.DriverName, ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open sqlite database: %s", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(sqlite.Schema); err != nil {
+		t.Fatalf("failed to create schema: %s", err)
+	}
+	return db
+}
+
+func TestSQLiteLinks(t *testing.T) {
+	repo := setupSQLite(t)
+	ctx := context.Background()
+
+	assert.NoError(t, repo.CreateShortLink(ctx, "acme.link", "abc", "link=https%3A%2F%2Fshop.example.com%2Fa", false))
+	assert.ErrorIs(t, repo.CreateShortLink(ctx, "acme.link", "abc", "link=x", false), apperrors.ErrPathTaken)
+	assert.NoError(t, repo.CreateShortLinks(ctx, []models.StoredLink{
+		{Host: "acme.link", Path: "def", QueryParams: "link=https%3A%2F%2Fexample.org%2Fb"},
+		{Host: "acme.link", Path: "ghi", QueryParams: "link=https%3A%2F%2Fexample.com%2Fc", Unguessable: true,
+			Metadata: models.LinkMetadata{Name: "Newsletter", Tags: []string{"email", "spring sale"}}},
+	}))
+	assert.NoError(t, repo.CreateAlias(ctx, "acme.link", "sale", "abc"))
+
+	qs, err := repo.GetQueryParamsByHostAndPath(ctx, "acme.link", "sale")
+	assert.NoError(t, err)
+	assert.Equal(t, "link=https%3A%2F%2Fshop.example.com%2Fa", qs)
+
+	path, err := repo.FindExistingShortLink(ctx, "acme.link", "link=https%3A%2F%2Fexample.org%2Fb")
+	assert.NoError(t, err)
+	assert.Equal(t, "def", path)
+
+	exists, err := repo.PathExists(ctx, "acme.link", "sale")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	link, err := repo.GetLink(ctx, "acme.link", "ghi")
+	assert.NoError(t, err)
+	assert.True(t, link.Unguessable)
+	assert.WithinDuration(t, time.Now(), link.CreatedAt, time.Minute)
+	assert.Equal(t, models.LinkMetadata{Name: "Newsletter", Tags: []string{"email", "spring sale"}}, link.Metadata)
+
+	assert.NoError(t, repo.SetLinkMetadata(ctx, "acme.link", "abc", models.LinkMetadata{Notes: "Shop launch", Tags: []string{"spring"}}))
+	link, err = repo.GetLink(ctx, "acme.link", "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, models.LinkMetadata{Notes: "Shop launch", Tags: []string{"spring"}}, link.Metadata)
+	found, err := repo.SearchLinks(ctx, "acme.link", "shop launch", models.SortRelevance, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, found, 1, "links are found by their notes") {
+		assert.Equal(t, "abc", found[0].Path)
+	}
+	found, err = repo.SearchLinks(ctx, "acme.link", "newsletter", models.SortRelevance, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, found, 1, "links are found by the name they were created with") {
+		assert.Equal(t, "ghi", found[0].Path)
+	}
+
+	// A tag matches whole tags only: "spring" isn't found in "spring sale".
+	tagged, err := repo.ListLinks(ctx, "acme.link", models.LinkPage{Sort: "createdAt", Limit: 10, Filter: models.LinkFilter{Tags: []string{"spring"}}})
+	assert.NoError(t, err)
+	if assert.Len(t, tagged, 1) {
+		assert.Equal(t, "abc", tagged[0].Path)
+	}
+	tagged, err = repo.ListLinks(ctx, "acme.link", models.LinkPage{Sort: "createdAt", Limit: 10, Filter: models.LinkFilter{Tags: []string{"email", "spring sale"}}})
+	assert.NoError(t, err)
+	if assert.Len(t, tagged, 1) {
+		assert.Equal(t, "ghi", tagged[0].Path)
+		assert.Equal(t, "Newsletter", tagged[0].Metadata.Name)
+	}
+
+	// Pages of one link, oldest first, carry on after the cursor.
+	page := models.LinkPage{Sort: "createdAt", Limit: 1}
+	var paths []string
+	for {
+		links, err := repo.ListLinks(ctx, "acme.link", page)
+		assert.NoError(t, err)
+		if len(links) == 0 {
+			break
+		}
+		paths = append(paths, links[0].Path)
+		page.AfterValue, page.AfterID = links[0].CreatedAt.Format(time.RFC3339Nano), links[0].ID
+	}
+	assert.Equal(t, []string{"abc", "def", "ghi"}, paths)
+
+	links, err := repo.ListLinks(ctx, "acme.link", models.LinkPage{
+		Sort:   "createdAt",
+		Limit:  10,
+		Filter: models.LinkFilter{DestinationDomain: "example.com"},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, links, 2) {
+		assert.Equal(t, "abc", links[0].Path)
+		assert.Equal(t, "ghi", links[1].Path)
+	}
+
+	found, err = repo.SearchLinks(ctx, "acme.link", "EXAMPLE.ORG", models.SortRelevance, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, found, 1) {
+		assert.Equal(t, "def", found[0].Path)
+	}
+
+	assert.NoError(t, repo.SoftDeleteLink(ctx, "acme.link", "def"))
+	_, err = repo.GetQueryParamsByHostAndPath(ctx, "acme.link", "def")
+	assert.ErrorIs(t, err, apperrors.ErrLinkDeleted)
+
+	// The alias goes with its link through the foreign key.
+	assert.NoError(t, repo.DeleteLink(ctx, "acme.link", "abc"))
+	aliases, err := repo.ListAliases(ctx, "acme.link", "abc")
+	assert.NoError(t, err)
+	assert.Empty(t, aliases)
+
+	for want := int64(1); want <= 2; want++ {
+		n, err := repo.NextPathSequence(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, want, n)
+	}
+}
+
+func TestSQLiteClicks(t *testing.T) {
+	repo := setupSQLite(t)
+	ctx := context.Background()
+	today := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, repo.CreateShortLink(ctx, "acme.link", "abc", "link=https%3A%2F%2Fexample.com", false))
+	assert.NoError(t, repo.CreateAlias(ctx, "acme.link", "sale", "abc"))
+	assert.NoError(t, repo.AddClicks(ctx, []clicks.Count{
+		{Host: "acme.link", Path: "abc", Clicks: 3, LastClickedAt: today.Add(10 * time.Hour)},
+		{Host: "acme.link", Path: "sale", Clicks: 2, LastClickedAt: today.Add(-5 * 24 * time.Hour)},
+		{Host: "acme.link", Path: "abc", Clicks: 1, LastClickedAt: today.Add(-20 * 24 * time.Hour)},
+	}))
+
+	path, total, err := repo.ClickTotal(ctx, "acme.link", "sale")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", path)
+	assert.Equal(t, int64(6), total)
+	path, _, err = repo.ClickTotal(ctx, "acme.link", "nope")
+	assert.NoError(t, err)
+	assert.Empty(t, path)
+
+	totals, err := repo.ClickTotals(ctx, "acme.link", today)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ClickTotals{Today: 3, Last7Days: 5, Last30Days: 6}, totals)
+
+	top, err := repo.TopLinksSince(ctx, "acme.link", today.AddDate(0, 0, -6), 10)
+	assert.NoError(t, err)
+	if assert.Len(t, top, 1) {
+		assert.Equal(t, int64(5), top[0].TotalClicks)
+	}
+
+	link, err := repo.ListLinks(ctx, "acme.link", models.LinkPage{Sort: "lastClickedAt", Limit: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, link, 1) && assert.NotNil(t, link[0].LastClickedAt) {
+		assert.Equal(t, today.Add(10*time.Hour), *link[0].LastClickedAt, "the latest click is kept")
+		assert.Equal(t, int64(6), link[0].TotalClicks)
+	}
+
+	monday := time.Date(2024, 5, 13, 9, 0, 0, 0, time.UTC)
+	assert.NoError(t, repo.AddClickEvents(ctx, []clicks.Event{
+		{Host: "acme.link", Path: "abc", At: monday, Source: clicks.SourceRedirect, Platform: "ios", Referrer: "https://News.example.com/a", Visitor: "v1", Variant: "a", Consented: true},
+		{Host: "acme.link", Path: "sale", At: monday.Add(2 * 24 * time.Hour), Source: clicks.SourceRedirect, Platform: "ios", Visitor: "v1", Variant: "a", Consented: true},
+		{Host: "acme.link", Path: "abc", At: monday.Add(3 * time.Hour), Source: clicks.SourceRedirect, Platform: "web", Referrer: "https://news.example.com/b", Variant: "b", Consented: true},
+		{Host: "acme.link", Path: "abc", At: monday.Add(time.Hour), Source: clicks.SourceExchange, Platform: "android"},
+	}))
+
+	stats, err := repo.ClickStats(ctx, "acme.link", models.ClickStatsQuery{
+		Path:         "abc",
+		From:         monday.AddDate(0, 0, -7),
+		To:           monday.AddDate(0, 0, 7),
+		Granularity:  "week",
+		TopReferrers: 10,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), stats.Clicks)
+	assert.Equal(t, int64(2), stats.UniqueClicks)
+	assert.Equal(t, int64(3), stats.ConsentedClicks)
+	assert.Equal(t, []models.ClickBucket{{Start: time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC), Clicks: 3, UniqueClicks: 2}}, stats.Buckets)
+	assert.Equal(t, []models.SourceClicks{
+		{Source: clicks.SourceExchange, Platform: "android", Clicks: 1, UniqueClicks: 1},
+		{Source: clicks.SourceRedirect, Platform: "ios", Clicks: 2, UniqueClicks: 1},
+		{Source: clicks.SourceRedirect, Platform: "web", Clicks: 1, UniqueClicks: 1},
+	}, stats.Sources)
+	assert.Equal(t, []models.ReferrerClicks{{Referrer: "news.example.com", Clicks: 2}}, stats.TopReferrers)
+	assert.Equal(t, []models.VariantClicks{
+		{Variant: "a", Clicks: 2, UniqueClicks: 1},
+		{Variant: "b", Clicks: 1, UniqueClicks: 1},
+	}, stats.Variants)
+
+	events, err := repo.ListClickEvents(ctx, "acme.link", models.ClickEventFilter{From: monday.Add(time.Hour)})
+	assert.NoError(t, err)
+	if assert.Len(t, events, 3) {
+		assert.False(t, events[0].Consented, "the exchange")
+		assert.True(t, events[1].Consented)
+	}
+}
+
+func TestSQLiteClickStatsPredecessors(t *testing.T) {
+	repo := setupSQLite(t)
+	ctx := context.Background()
+
+	assert.NoError(t, repo.CreateShortLink(ctx, "acme.link", "old", "link=https%3A%2F%2Fexample.com%2Fv1", false))
+	assert.NoError(t, repo.CreateShortLink(ctx, "acme.link", "new", "link=https%3A%2F%2Fexample.com%2Fv2", false))
+	assert.NoError(t, repo.CreateShortLink(ctx, "acme.link", "other", "link=https%3A%2F%2Fexample.com%2Fx", false))
+	assert.NoError(t, repo.CreateAlias(ctx, "acme.link", "old-sale", "old"))
+	assert.NoError(t, repo.MarkSuperseded(ctx, "acme.link", "old", "new", true))
+
+	monday := time.Date(2024, 5, 13, 9, 0, 0, 0, time.UTC)
+	assert.NoError(t, repo.AddClickEvents(ctx, []clicks.Event{
+		{Host: "acme.link", Path: "old", At: monday, Source: clicks.SourceRedirect, Platform: "ios"},
+		{Host: "acme.link", Path: "old-sale", At: monday, Source: clicks.SourceRedirect, Platform: "ios"},
+		{Host: "acme.link", Path: "new", At: monday, Source: clicks.SourceRedirect, Platform: "web"},
+		{Host: "acme.link", Path: "other", At: monday, Source: clicks.SourceRedirect, Platform: "web"},
+	}))
+
+	stats, err := repo.ClickStats(ctx, "acme.link", models.ClickStatsQuery{
+		Path:        "new",
+		From:        monday.AddDate(0, 0, -1),
+		To:          monday.AddDate(0, 0, 1),
+		Granularity: "day",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), stats.Clicks, "clicks on the superseded link and its alias count")
+}
+
+func TestSQLiteMergeLinksMovesClicks(t *testing.T) {
+	repo := setupSQLite(t)
+	ctx := context.Background()
+
+	today := time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, repo.CreateShortLink(ctx, "acme.link", "abc", "link=https%3A%2F%2Fexample.com", false))
+	assert.NoError(t, repo.CreateShortLink(ctx, "acme.link", "dup", "link=https%3A%2F%2Fexample.com", false))
+	assert.NoError(t, repo.AddClicks(ctx, []clicks.Count{
+		{Host: "acme.link", Path: "abc", Clicks: 3, LastClickedAt: today.Add(-24 * time.Hour)},
+		{Host: "acme.link", Path: "dup", Clicks: 2, LastClickedAt: today.Add(-24 * time.Hour)},
+		{Host: "acme.link", Path: "dup", Clicks: 4, LastClickedAt: today.Add(5 * time.Hour)},
+	}))
+
+	assert.NoError(t, repo.MergeLinks(ctx, "acme.link", "abc", []string{"dup"}))
+
+	path, total, err := repo.ClickTotal(ctx, "acme.link", "dup")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", path)
+	assert.Equal(t, int64(9), total)
+
+	totals, err := repo.ClickTotals(ctx, "acme.link", today)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ClickTotals{Today: 4, Last7Days: 9, Last30Days: 9}, totals)
+
+	links, err := repo.ListLinks(ctx, "acme.link", models.LinkPage{Sort: "lastClickedAt", Limit: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, links, 1) && assert.NotNil(t, links[0].LastClickedAt) {
+		assert.Equal(t, today.Add(5*time.Hour), *links[0].LastClickedAt)
+	}
+}
+
+func TestSQLiteListLinksByExpiry(t *testing.T) {
+	repo := setupSQLite(t)
+	ctx := context.Background()
+
+	assert.NoError(t, repo.CreateShortLink(ctx, "acme.link", "never", "link=https%3A%2F%2Fexample.com", false))
+	assert.NoError(t, repo.CreateShortLink(ctx, "acme.link", "late", "au=2025-06-01T00%3A00%3A00Z&link=https%3A%2F%2Fexample.com", false))
+	assert.NoError(t, repo.CreateShortLinks(ctx, []models.StoredLink{
+		{Host: "acme.link", Path: "soon", QueryParams: "au=2025-01-01T00%3A00%3A00Z&link=https%3A%2F%2Fexample.com"},
+	}))
+	assert.NoError(t, repo.CreateShortLink(ctx, "acme.link", "changed", "link=https%3A%2F%2Fexample.com%2Fc", false))
+	assert.NoError(t, repo.ReplaceQueryParams(ctx, models.StoredLink{
+		Host: "acme.link", Path: "changed", QueryParams: "au=2025-03-01T00%3A00%3A00Z&link=https%3A%2F%2Fexample.com%2Fc",
+	}, "link=https%3A%2F%2Fexample.com%2Fc", "edit"))
+
+	var paths []string
+	page := models.LinkPage{Sort: "expiresAt", Limit: 2}
+	for {
+		links, err := repo.ListLinks(ctx, "acme.link", page)
+		if !assert.NoError(t, err) || len(links) == 0 {
+			break
+		}
+		for _, link := range links {
+			paths = append(paths, link.Path)
+		}
+		last := links[len(links)-1]
+		page.AfterID, page.AfterValue = last.ID, "9999-12-31T23:59:59Z"
+		if last.ActiveUntil != nil {
+			page.AfterValue = last.ActiveUntil.Format(time.RFC3339Nano)
+		}
+	}
+	assert.Equal(t, []string{"soon", "changed", "late", "never"}, paths, "links that never expire come last")
+}
+
+func TestSQLiteUseClick(t *testing.T) {
+	repo := setupSQLite(t)
+	ctx := context.Background()
+
+	assert.NoError(t, repo.CreateShortLink(ctx, "acme.link", "abc", "link=https%3A%2F%2Fexample.com&mc=2", false))
+	assert.NoError(t, repo.CreateAlias(ctx, "acme.link", "sale", "abc"))
+	for _, want := range []bool{true, true, false} {
+		ok, err := repo.UseClick(ctx, "acme.link", "sale", 2)
+		assert.NoError(t, err)
+		assert.Equal(t, want, ok)
+	}
+	ok, err := repo.UseClick(ctx, "acme.link", "missing", 2)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSQLiteLinkVariants(t *testing.T) {
+	repo := setupSQLite(t)
+	ctx := context.Background()
+
+	assert.NoError(t, repo.CreateShortLink(ctx, "acme.link", "abc", "link=https%3A%2F%2Fexample.com", false))
+	assert.NoError(t, repo.CreateAlias(ctx, "acme.link", "sale", "abc"))
+	assert.NoError(t, repo.SetLinkVariants(ctx, "acme.link", "abc", []models.LinkVariant{
+		{Name: "b", Link: "https://example.com/b", Weight: 1},
+		{Name: "a", Link: "https://example.com/a", Weight: 3},
+	}))
+
+	variants, err := repo.ResolveLinkVariants(ctx, "acme.link", "sale")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.LinkVariant{
+		{Name: "a", Link: "https://example.com/a", Weight: 3},
+		{Name: "b", Link: "https://example.com/b", Weight: 1},
+	}, variants, "aliases resolve to the link's variants")
+
+	assert.NoError(t, repo.SetLinkVariants(ctx, "acme.link", "abc", nil))
+	variants, err = repo.ResolveLinkVariants(ctx, "acme.link", "abc")
+	assert.NoError(t, err)
+	assert.Empty(t, variants)
+}
+
+func TestSQLiteGeoDestinations(t *testing.T) {
+	repo := setupSQLite(t)
+	ctx := context.Background()
+
+	assert.NoError(t, repo.CreateShortLink(ctx, "acme.link", "abc", "link=https%3A%2F%2Fexample.com", false))
+	assert.NoError(t, repo.CreateAlias(ctx, "acme.link", "sale", "abc"))
+	assert.NoError(t, repo.SetGeoDestinations(ctx, "acme.link", "abc", []models.GeoDestination{
+		{Country: "FR", Link: "https://example.com/fr"},
+		{Country: "DE", Link: "https://example.com/de"},
+	}))
+
+	destinations, err := repo.ListGeoDestinations(ctx, "acme.link", "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.GeoDestination{
+		{Country: "DE", Link: "https://example.com/de"},
+		{Country: "FR", Link: "https://example.com/fr"},
+	}, destinations)
+	resolved, err := repo.ResolveGeoDestinations(ctx, "acme.link", "sale")
+	assert.NoError(t, err)
+	assert.Equal(t, destinations, resolved, "aliases resolve to the link's destinations")
+
+	assert.NoError(t, repo.SetGeoDestinations(ctx, "acme.link", "abc", nil))
+	destinations, err = repo.ListGeoDestinations(ctx, "acme.link", "abc")
+	assert.NoError(t, err)
+	assert.Empty(t, destinations)
+}
+
+func TestSQLiteDeviceRules(t *testing.T) {
+	repo := setupSQLite(t)
+	ctx := context.Background()
+
+	assert.NoError(t, repo.CreateShortLink(ctx, "acme.link", "abc", "link=https%3A%2F%2Fexample.com", false))
+	assert.NoError(t, repo.CreateAlias(ctx, "acme.link", "sale", "abc"))
+	rules, err := repo.ResolveDeviceRules(ctx, "acme.link", "abc")
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+
+	want := []models.DeviceRule{{OS: "android", VersionBelow: "10", Link: "https://example.com/legacy"}}
+	assert.NoError(t, repo.SetDeviceRules(ctx, "acme.link", "abc", want))
+	rules, err = repo.ResolveDeviceRules(ctx, "acme.link", "sale")
+	assert.NoError(t, err)
+	assert.Equal(t, want, rules)
+
+	assert.NoError(t, repo.SetDeviceRules(ctx, "acme.link", "abc", nil))
+	rules, err = repo.ResolveDeviceRules(ctx, "acme.link", "abc")
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+	_, err = repo.ResolveDeviceRules(ctx, "acme.link", "missing")
+	assert.ErrorIs(t, err, apperrors.ErrLinkNotFound)
+}
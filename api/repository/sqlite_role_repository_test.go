@@ -0,0 +1,65 @@
+//go:build cgo
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLiteAPIKeys(t *testing.T) {
+	repo := NewRoleRepository(openSQLite(t))
+	ctx := context.Background()
+
+	created, err := repo.CreateAPIKey(ctx, models.APIKey{ID: "0123456789abcdef", Name: "CI", Role: models.RoleEditor}, "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleEditor, created.Role)
+	assert.False(t, created.CreatedAt.IsZero())
+	_, err = repo.CreateAPIKey(ctx, models.APIKey{ID: "fedcba9876543210", Name: "dup", Role: models.RoleViewer}, "hash")
+	assert.Error(t, err, "key hashes are unique")
+
+	key, err := repo.GetAPIKeyByHash(ctx, "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, "CI", key.Name)
+	_, err = repo.GetAPIKeyByHash(ctx, "other")
+	assert.ErrorIs(t, err, apperrors.ErrAPIKeyNotFound)
+
+	keys, err := repo.ListAPIKeys(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+
+	assert.NoError(t, repo.DeleteAPIKey(ctx, "0123456789abcdef"))
+	assert.ErrorIs(t, repo.DeleteAPIKey(ctx, "0123456789abcdef"), apperrors.ErrAPIKeyNotFound)
+	_, err = repo.GetAPIKeyByHash(ctx, "hash")
+	assert.ErrorIs(t, err, apperrors.ErrAPIKeyNotFound)
+}
+
+func TestSQLiteSubjectRoles(t *testing.T) {
+	repo := NewRoleRepository(openSQLite(t))
+	ctx := context.Background()
+
+	_, err := repo.GetSubjectRole(ctx, "alice")
+	assert.ErrorIs(t, err, apperrors.ErrSubjectRoleNotFound)
+	set, err := repo.SetSubjectRole(ctx, "alice", models.RoleViewer)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleViewer, set.Role)
+	set, err = repo.SetSubjectRole(ctx, "alice", models.RoleAdmin)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, set.Role, "setting a role replaces the previous one")
+	_, err = repo.SetSubjectRole(ctx, "auth0|bob", models.RoleEditor)
+	assert.NoError(t, err)
+
+	subjectRoles, err := repo.ListSubjectRoles(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, subjectRoles, 2) {
+		assert.Equal(t, "alice", subjectRoles[0].Subject)
+	}
+
+	assert.NoError(t, repo.DeleteSubjectRole(ctx, "alice"))
+	assert.ErrorIs(t, repo.DeleteSubjectRole(ctx, "alice"), apperrors.ErrSubjectRoleNotFound)
+}
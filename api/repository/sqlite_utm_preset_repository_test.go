@@ -0,0 +1,40 @@
+//go:build cgo
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLiteUTMPresets(t *testing.T) {
+	repo := NewUTMPresetRepository(openSQLite(t))
+	ctx := context.Background()
+
+	created, err := repo.CreateUTMPreset(ctx, models.UTMPreset{
+		Host:                "acme.link",
+		Name:                "newsletter",
+		MarketingParameters: models.MarketingParameters{UtmSource: "newsletter", UtmMedium: "email"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "email", created.MarketingParameters.UtmMedium)
+	assert.False(t, created.CreatedAt.IsZero())
+	_, err = repo.CreateUTMPreset(ctx, models.UTMPreset{Host: "acme.link", Name: "newsletter"})
+	assert.ErrorIs(t, err, apperrors.ErrUTMPresetExists)
+	_, err = repo.CreateUTMPreset(ctx, models.UTMPreset{Host: "other.link", Name: "newsletter"})
+	assert.NoError(t, err)
+
+	presets, err := repo.ListUTMPresets(ctx, "acme.link")
+	assert.NoError(t, err)
+	assert.Len(t, presets, 1)
+
+	assert.NoError(t, repo.DeleteUTMPreset(ctx, "acme.link", "newsletter"))
+	assert.ErrorIs(t, repo.DeleteUTMPreset(ctx, "acme.link", "newsletter"), apperrors.ErrUTMPresetNotFound)
+	_, err = repo.GetUTMPreset(ctx, "other.link", "newsletter")
+	assert.NoError(t, err)
+}
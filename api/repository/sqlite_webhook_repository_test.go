@@ -0,0 +1,67 @@
+//go:build cgo
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLiteWebhooks(t *testing.T) {
+	repo := NewWebhookRepository(openSQLite(t))
+	ctx := context.Background()
+
+	created, err := repo.CreateWebhook(ctx, models.Webhook{
+		Host:            "acme.link",
+		URL:             "https://hooks.example.com/links",
+		Secret:          "s3cret",
+		Events:          []string{"link.created"},
+		ClickThresholds: []int64{100, 1000},
+	})
+	assert.NoError(t, err)
+	assert.NotZero(t, created.ID)
+	assert.Equal(t, []int64{100, 1000}, created.ClickThresholds)
+	_, err = repo.CreateWebhook(ctx, models.Webhook{Host: "other.link", URL: "https://hooks.example.com", Secret: "x", Events: []string{}, ClickThresholds: []int64{}})
+	assert.NoError(t, err)
+
+	hooks, err := repo.ListWebhooks(ctx, "acme.link")
+	assert.NoError(t, err)
+	if assert.Len(t, hooks, 1) {
+		assert.Equal(t, []string{"link.created"}, hooks[0].Events)
+		assert.Equal(t, "s3cret", hooks[0].Secret)
+	}
+
+	deliveredAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for attempt := 1; attempt <= 2; attempt++ {
+		assert.NoError(t, repo.LogDelivery(ctx, models.WebhookDelivery{
+			WebhookID:   created.ID,
+			EventID:     "evt_1",
+			EventType:   "link.created",
+			Payload:     `{"id":"evt_1"}`,
+			Attempt:     attempt,
+			StatusCode:  500,
+			Error:       "endpoint returned status 500",
+			DeliveredAt: deliveredAt.Add(time.Duration(attempt) * time.Second),
+		}))
+	}
+	deliveries, err := repo.ListDeliveries(ctx, created.ID, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, deliveries, 2) {
+		assert.Equal(t, 2, deliveries[0].Attempt, "newest first")
+		assert.Equal(t, deliveredAt.Add(2*time.Second), deliveries[0].DeliveredAt)
+	}
+
+	assert.ErrorIs(t, repo.DeleteWebhook(ctx, "other.link", created.ID), apperrors.ErrWebhookNotFound)
+	assert.NoError(t, repo.DeleteWebhook(ctx, "acme.link", created.ID))
+	_, err = repo.GetWebhook(ctx, "acme.link", created.ID)
+	assert.ErrorIs(t, err, apperrors.ErrWebhookNotFound)
+	deliveries, err = repo.ListDeliveries(ctx, created.ID, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, deliveries)
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+)
+
+type UTMPresetRepository interface {
+	CreateUTMPreset(ctx context.Context, preset models.UTMPreset) (*models.UTMPreset, error)
+	ListUTMPresets(ctx context.Context, host string) ([]models.UTMPreset, error)
+	GetUTMPreset(ctx context.Context, host, name string) (*models.UTMPreset, error)
+	DeleteUTMPreset(ctx context.Context, host, name string) error
+}
+
+type utmPresetRepository struct {
+	db *sql.DB
+}
+
+// NewUTMPresetRepository returns the UTM preset repository for any dialect: its queries are the
+// same in Postgres, SQLite and MySQL.
+func NewUTMPresetRepository(db *sql.DB) UTMPresetRepository {
+	return &utmPresetRepository{
+		db: db,
+	}
+}
+
+const utmPresetColumns = `name, host, utm_source, utm_medium, utm_campaign, utm_term, utm_content, created_at`
+
+func scanUTMPreset(row interface{ Scan(...any) error }) (*models.UTMPreset, error) {
+	var preset models.UTMPreset
+	params := &preset.MarketingParameters
+	err := row.Scan(
+		&preset.Name,
+		&preset.Host,
+		&params.UtmSource,
+		&params.UtmMedium,
+		&params.UtmCampaign,
+		&params.UtmTerm,
+		&params.UtmContent,
+		&preset.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &preset, nil
+}
+
+func (r *utmPresetRepository) CreateUTMPreset(ctx context.Context, preset models.UTMPreset) (*models.UTMPreset, error) {
+	const stmt = `
+    INSERT INTO utm_presets
+      (host, name, utm_source, utm_medium, utm_campaign, utm_term, utm_content)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	params := preset.MarketingParameters
+	_, err := r.db.ExecContext(ctx, stmt, preset.Host, preset.Name,
+		params.UtmSource, params.UtmMedium, params.UtmCampaign, params.UtmTerm, params.UtmContent)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, apperrors.ErrUTMPresetExists
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return r.GetUTMPreset(ctx, preset.Host, preset.Name)
+}
+
+func (r *utmPresetRepository) ListUTMPresets(ctx context.Context, host string) ([]models.UTMPreset, error) {
+	const q = `
+    SELECT ` + utmPresetColumns + `
+      FROM utm_presets
+     WHERE host = $1
+     ORDER BY name`
+	rows, err := r.db.QueryContext(ctx, q, host)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	presets := []models.UTMPreset{}
+	for rows.Next() {
+		preset, err := scanUTMPreset(rows)
+		if err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		presets = append(presets, *preset)
+	}
+	return presets, rows.Err()
+}
+
+func (r *utmPresetRepository) GetUTMPreset(ctx context.Context, host, name string) (*models.UTMPreset, error) {
+	const q = `
+    SELECT ` + utmPresetColumns + `
+      FROM utm_presets
+     WHERE host = $1 AND name = $2`
+	preset, err := scanUTMPreset(r.db.QueryRowContext(ctx, q, host, name))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrUTMPresetNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return preset, nil
+}
+
+func (r *utmPresetRepository) DeleteUTMPreset(ctx context.Context, host, name string) error {
+	const stmt = `
+    DELETE FROM utm_presets
+     WHERE host = $1 AND name = $2`
+	res, err := r.db.ExecContext(ctx, stmt, host, name)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return apperrors.ErrUTMPresetNotFound
+	}
+	return nil
+}
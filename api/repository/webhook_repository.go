@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/db"
+
+	"github.com/lib/pq"
+)
+
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, hook models.Webhook) (*models.Webhook, error)
+	ListWebhooks(ctx context.Context, host string) ([]models.Webhook, error)
+	GetWebhook(ctx context.Context, host string, id int64) (*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, host string, id int64) error
+	LogDelivery(ctx context.Context, delivery models.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, webhookID int64, limit int) ([]models.WebhookDelivery, error)
+}
+
+type webhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
+
+// NewWebhookRepositoryForDB returns the repository for the dialect of database, running queries
+// on its write pool.
+func NewWebhookRepositoryForDB(database *db.DB) WebhookRepository {
+	if database.Dialect == db.MySQL {
+		return NewMySQLWebhookRepository(database.Write)
+	}
+	return NewWebhookRepository(database.Write)
+}
+
+const webhookColumns = `id, host, url, secret, events, click_thresholds, created_at`
+
+func scanWebhook(row interface{ Scan(...any) error }) (*models.Webhook, error) {
+	var hook models.Webhook
+	err := row.Scan(
+		&hook.ID,
+		&hook.Host,
+		&hook.URL,
+		&hook.Secret,
+		pq.Array(&hook.Events),
+		pq.Array(&hook.ClickThresholds),
+		&hook.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if hook.Events == nil {
+		hook.Events = []string{}
+	}
+	if hook.ClickThresholds == nil {
+		hook.ClickThresholds = []int64{}
+	}
+	return &hook, nil
+}
+
+func (r *webhookRepository) CreateWebhook(ctx context.Context, hook models.Webhook) (*models.Webhook, error) {
+	const stmt = `
+    INSERT INTO webhooks
+      (host, url, secret, events, click_thresholds)
+    VALUES ($1, $2, $3, $4, $5)
+    RETURNING ` + webhookColumns
+	row := r.db.QueryRowContext(ctx, stmt,
+		hook.Host, hook.URL, hook.Secret, pq.Array(hook.Events), pq.Array(hook.ClickThresholds))
+	created, err := scanWebhook(row)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return created, nil
+}
+
+func (r *webhookRepository) ListWebhooks(ctx context.Context, host string) ([]models.Webhook, error) {
+	const q = `
+    SELECT ` + webhookColumns + `
+      FROM webhooks
+     WHERE host = $1
+     ORDER BY id`
+	rows, err := r.db.QueryContext(ctx, q, host)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	hooks := []models.Webhook{}
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		hooks = append(hooks, *hook)
+	}
+	return hooks, rows.Err()
+}
+
+func (r *webhookRepository) GetWebhook(ctx context.Context, host string, id int64) (*models.Webhook, error) {
+	const q = `
+    SELECT ` + webhookColumns + `
+      FROM webhooks
+     WHERE host = $1 AND id = $2`
+	hook, err := scanWebhook(r.db.QueryRowContext(ctx, q, host, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return hook, nil
+}
+
+// DeleteWebhook deletes the webhook along with its delivery log.
+func (r *webhookRepository) DeleteWebhook(ctx context.Context, host string, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	defer tx.Rollback()
+
+	const stmt = `
+    DELETE FROM webhooks
+     WHERE host = $1 AND id = $2`
+	res, err := tx.ExecContext(ctx, stmt, host, id)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return apperrors.ErrWebhookNotFound
+	}
+	const deliveriesStmt = `
+    DELETE FROM webhook_deliveries
+     WHERE webhook_id = $1`
+	if _, err := tx.ExecContext(ctx, deliveriesStmt, id); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) LogDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	const stmt = `
+    INSERT INTO webhook_deliveries
+      (webhook_id, event_id, event_type, payload, attempt, status_code, error, duration_ms, delivered_at)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	_, err := r.db.ExecContext(ctx, stmt,
+		delivery.WebhookID, delivery.EventID, delivery.EventType, delivery.Payload, delivery.Attempt,
+		delivery.StatusCode, delivery.Error, delivery.DurationMs, delivery.DeliveredAt)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries returns the latest deliveries to the webhook, newest first.
+func (r *webhookRepository) ListDeliveries(ctx context.Context, webhookID int64, limit int) ([]models.WebhookDelivery, error) {
+	const q = `
+    SELECT id, webhook_id, event_id, event_type, payload, attempt, status_code, error, duration_ms, delivered_at
+      FROM webhook_deliveries
+     WHERE webhook_id = $1
+     ORDER BY id DESC
+     LIMIT $2`
+	rows, err := r.db.QueryContext(ctx, q, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Payload, &d.Attempt,
+			&d.StatusCode, &d.Error, &d.DurationMs, &d.DeliveredAt)
+		if err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
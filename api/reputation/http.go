@@ -0,0 +1,76 @@
+package reputation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HTTP looks URLs up with a reputation service of one's own. It POSTs
+//
+//	{"urls": ["https://example.com/a", ...]}
+//
+// to the service, with the API key as bearer token when there is one, and expects a 200 response
+// listing the flagged URLs:
+//
+//	{"matches": [{"url": "https://example.com/a", "threat": "PHISHING"}]}
+type HTTP struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func NewHTTP(serviceURL, apiKey string, timeout time.Duration) *HTTP {
+	return &HTTP{
+		url:    serviceURL,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (h *HTTP) Check(ctx context.Context, urls []string) ([]Match, error) {
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	body, err := json.Marshal(map[string][]string{"urls": urls})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.apiKey)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("reputation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("reputation service returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Matches []struct {
+			URL    string `json:"url"`
+			Threat string `json:"threat"`
+		} `json:"matches"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid reputation response: %w", err)
+	}
+	matches := make([]Match, 0, len(result.Matches))
+	for _, m := range result.Matches {
+		matches = append(matches, Match{URL: m.URL, Threat: m.Threat})
+	}
+	return matches, nil
+}
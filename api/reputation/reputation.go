@@ -0,0 +1,69 @@
+// Package reputation checks the destinations of new links against a URL reputation service, so
+// that links to malware and phishing pages can be flagged or refused when they are created.
+package reputation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Match is a URL the reputation service flagged.
+type Match struct {
+	URL string
+	// Threat is the kind of threat, in the service's words, e.g. "MALWARE" or "SOCIAL_ENGINEERING".
+	Threat string
+}
+
+// Checker looks up the reputation of URLs. Check returns the flagged ones, and fails only when
+// the service couldn't answer.
+type Checker interface {
+	Check(ctx context.Context, urls []string) ([]Match, error)
+}
+
+// Nop flags nothing, for deployments without a reputation service.
+type Nop struct{}
+
+func (Nop) Check(context.Context, []string) ([]Match, error) { return nil, nil }
+
+const (
+	ProviderNone         = "none"
+	ProviderSafeBrowsing = "safebrowsing"
+	ProviderHTTP         = "http"
+)
+
+// Options selects and configures a provider.
+type Options struct {
+	Provider string
+	// APIKey is the Safe Browsing API key, or the bearer token sent to an HTTP provider.
+	APIKey string
+	// URL is the endpoint of an HTTP provider. It replaces the Safe Browsing endpoint when set
+	// for Safe Browsing.
+	URL     string
+	Timeout time.Duration
+}
+
+// New returns the checker for opts.Provider. An empty provider is the same as "none".
+func New(opts Options) (Checker, error) {
+	switch opts.Provider {
+	case "", ProviderNone:
+		return Nop{}, nil
+	case ProviderSafeBrowsing:
+		if opts.APIKey == "" {
+			return nil, errors.New("safebrowsing needs an API key")
+		}
+		endpoint := opts.URL
+		if endpoint == "" {
+			endpoint = SafeBrowsingURL
+		}
+		return NewSafeBrowsing(endpoint, opts.APIKey, opts.Timeout), nil
+	case ProviderHTTP:
+		if opts.URL == "" {
+			return nil, errors.New("http reputation provider needs a URL")
+		}
+		return NewHTTP(opts.URL, opts.APIKey, opts.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown URL reputation provider %q", opts.Provider)
+	}
+}
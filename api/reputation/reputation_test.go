@@ -0,0 +1,81 @@
+package reputation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeBrowsing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "key", r.URL.Query().Get("key"))
+		var req struct {
+			ThreatInfo struct {
+				ThreatEntries []struct {
+					URL string `json:"url"`
+				} `json:"threatEntries"`
+			} `json:"threatInfo"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Len(t, req.ThreatInfo.ThreatEntries, 2)
+		w.Write([]byte(`{"matches": [{"threatType": "MALWARE", "platformType": "ANY_PLATFORM", "threat": {"url": "https://bad.example/"}}]}`))
+	}))
+	defer server.Close()
+
+	checker, err := New(Options{Provider: ProviderSafeBrowsing, APIKey: "key", URL: server.URL, Timeout: time.Second})
+	assert.NoError(t, err)
+	matches, err := checker.Check(context.Background(), []string{"https://good.example/", "https://bad.example/"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Match{{URL: "https://bad.example/", Threat: "MALWARE"}}, matches)
+}
+
+func TestHTTP(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    []Match
+		wantErr bool
+	}{
+		{name: "Clean", status: http.StatusOK, body: `{}`, want: []Match{}},
+		{name: "Flagged", status: http.StatusOK, body: `{"matches": [{"url": "https://bad.example/", "threat": "PHISHING"}]}`, want: []Match{{URL: "https://bad.example/", Threat: "PHISHING"}}},
+		{name: "Down", status: http.StatusBadGateway, body: ``, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			matches, err := NewHTTP(server.URL, "token", time.Second).Check(context.Background(), []string{"https://bad.example/"})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, matches)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	checker, err := New(Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, Nop{}, checker)
+
+	_, err = New(Options{Provider: ProviderSafeBrowsing})
+	assert.Error(t, err, "safebrowsing needs an API key")
+	_, err = New(Options{Provider: ProviderHTTP})
+	assert.Error(t, err, "http needs a URL")
+	_, err = New(Options{Provider: "virustotal"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,89 @@
+package reputation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const SafeBrowsingURL = "https://safebrowsing.googleapis.com/v4/threatMatches:find"
+
+// safeBrowsingThreats are the threat types URLs are looked up for.
+var safeBrowsingThreats = []string{"MALWARE", "SOCIAL_ENGINEERING", "UNWANTED_SOFTWARE", "POTENTIALLY_HARMFUL_APPLICATION"}
+
+// SafeBrowsing looks URLs up with the Google Safe Browsing Lookup API (v4).
+type SafeBrowsing struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func NewSafeBrowsing(lookupURL, apiKey string, timeout time.Duration) *SafeBrowsing {
+	return &SafeBrowsing{
+		url:    lookupURL,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+type safeBrowsingEntry struct {
+	URL string `json:"url"`
+}
+
+func (s *SafeBrowsing) Check(ctx context.Context, urls []string) ([]Match, error) {
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	entries := make([]safeBrowsingEntry, len(urls))
+	for i, u := range urls {
+		entries[i] = safeBrowsingEntry{URL: u}
+	}
+	body, err := json.Marshal(map[string]any{
+		"client": map[string]string{"clientId": "durable-links-generator", "clientVersion": "1.0"},
+		"threatInfo": map[string]any{
+			"threatTypes":      safeBrowsingThreats,
+			"platformTypes":    []string{"ANY_PLATFORM"},
+			"threatEntryTypes": []string{"URL"},
+			"threatEntries":    entries,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url+"?key="+url.QueryEscape(s.apiKey), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("safe browsing request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("safe browsing returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Matches []struct {
+			ThreatType string            `json:"threatType"`
+			Threat     safeBrowsingEntry `json:"threat"`
+		} `json:"matches"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid safe browsing response: %w", err)
+	}
+	matches := make([]Match, 0, len(result.Matches))
+	for _, m := range result.Matches {
+		matches = append(matches, Match{URL: m.Threat.URL, Threat: m.ThreatType})
+	}
+	return matches, nil
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type RoleHandler interface {
+	CreateAPIKey(w http.ResponseWriter, r *http.Request)
+	ListAPIKeys(w http.ResponseWriter, r *http.Request)
+	DeleteAPIKey(w http.ResponseWriter, r *http.Request)
+	SetSubjectRole(w http.ResponseWriter, r *http.Request)
+	ListSubjectRoles(w http.ResponseWriter, r *http.Request)
+	DeleteSubjectRole(w http.ResponseWriter, r *http.Request)
+}
+
+type roleHandler struct {
+	roleService service.RoleService
+}
+
+func NewRoleHandler(roleService service.RoleService) RoleHandler {
+	return &roleHandler{
+		roleService: roleService,
+	}
+}
+
+// CreateAPIKey creates an API key with a role. The response is the only one to hold the key.
+func (h *roleHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+
+	created, err := h.roleService.CreateAPIKey(r.Context(), req)
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (h *roleHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.roleService.ListAPIKeys(r.Context())
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	writeProjectedJSON(w, r, models.APIKeyListResponse{APIKeys: keys}, "apiKeys")
+}
+
+func (h *roleHandler) DeleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	if err := h.roleService.DeleteAPIKey(r.Context(), chi.URLParam(r, "id")); err != nil {
+		writeRoleError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *roleHandler) SetSubjectRole(w http.ResponseWriter, r *http.Request) {
+	var req models.SetSubjectRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+
+	subjectRole, err := h.roleService.SetSubjectRole(r.Context(), chi.URLParam(r, "subject"), req.Role)
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subjectRole)
+}
+
+func (h *roleHandler) ListSubjectRoles(w http.ResponseWriter, r *http.Request) {
+	subjectRoles, err := h.roleService.ListSubjectRoles(r.Context())
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	writeProjectedJSON(w, r, models.SubjectRoleListResponse{SubjectRoles: subjectRoles}, "subjectRoles")
+}
+
+func (h *roleHandler) DeleteSubjectRole(w http.ResponseWriter, r *http.Request) {
+	if err := h.roleService.DeleteSubjectRole(r.Context(), chi.URLParam(r, "subject")); err != nil {
+		writeRoleError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeRoleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrInvalidAPIKeyName),
+		errors.Is(err, apperrors.ErrInvalidSubject),
+		errors.Is(err, apperrors.ErrInvalidRole):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrAPIKeyNotFound),
+		errors.Is(err, apperrors.ErrSubjectRoleNotFound):
+		WriteError(w, err, http.StatusNotFound, err.Error(), models.StatusNotFound)
+	default:
+		requestLog(w).Error().Err(err).Msg("Role request failed")
+		WriteError(w, err, http.StatusInternalServerError, "Role request failed", models.StatusInternal)
+	}
+}
@@ -0,0 +1,272 @@
+//go:build cgo
+
+package api
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/clicks"
+	"durable-links-generator/api/geoip"
+	"durable-links-generator/api/notify"
+	"durable-links-generator/api/webhooks"
+	"durable-links-generator/config"
+	"durable-links-generator/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testAdminKey = "admin-secret"
+	testAudience = "durable-links"
+)
+
+// testIssuer serves the JWKS of one RSA key and signs tokens with it.
+type testIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	jwk := map[string]string{
+		"kty": "RSA", "kid": "test", "use": "sig",
+		"n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes()),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []any{jwk}})
+	}))
+	t.Cleanup(server.Close)
+	return &testIssuer{server: server, key: key}
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// token signs a token of subject with role, limited to projects.
+func (i *testIssuer) token(t *testing.T, subject, role string, projects []string) string {
+	claims := map[string]any{
+		"iss": i.server.URL, "aud": testAudience, "sub": subject, "role": role,
+		"exp": time.Now().Add(time.Hour).Unix(), "projects": projects,
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "test", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, i.key, crypto.SHA256, digest[:])
+	assert.NoError(t, err)
+	return signed + "." + b64(signature)
+}
+
+// newTestRouter builds the router the server runs on an in-memory SQLite database, with env
+// setting the config on top of the defaults.
+func newTestRouter(t *testing.T, issuer *testIssuer, env map[string]string) http.Handler {
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DATABASE_URL", ":memory:")
+	t.Setenv("ADMIN_API_KEYS", testAdminKey)
+	t.Setenv("DOMAINS", "allowed.link,victim.link")
+	t.Setenv("ALLOWED_DOMAINS", "example.com")
+	t.Setenv("DOMAIN_VERIFICATION_SECRET", "secret")
+	t.Setenv("OIDC_ISSUER", issuer.server.URL)
+	t.Setenv("OIDC_AUDIENCE", testAudience)
+	t.Setenv("OIDC_JWKS_URL", issuer.server.URL)
+	t.Setenv("OIDC_PROJECT_CLAIM", "projects")
+	t.Setenv("ADMIN_CORS_ORIGINS", "https://admin.example.com")
+	t.Setenv("PUBLIC_CORS_ORIGINS", "*")
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+	cfg := config.New()
+
+	database, err := db.New(cfg)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { database.Close() })
+
+	services, err := NewServices(database, cfg, notify.Nop{}, clicks.Nop{}, clicks.Nop{}, nil, webhooks.Nop{}, geoip.Nop{})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	router, err := NewRouter(database, cfg, notify.Nop{}, services, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return router
+}
+
+func serve(router http.Handler, method, target, credential, body string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	for name, values := range header {
+		r.Header[name] = values
+	}
+	if credential != "" {
+		r.Header.Set("Authorization", "Bearer "+credential)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+func TestRouter_Roles(t *testing.T) {
+	issuer := newTestIssuer(t)
+	router := newTestRouter(t, issuer, nil)
+	viewer := issuer.token(t, "viewer", "viewer", []string{"*"})
+	editor := issuer.token(t, "editor", "editor", []string{"*"})
+	link := `{"durableLinkInfo":{"host":"allowed.link","link":"https://example.com/a"}}`
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		credential string
+		body       string
+		wantCode   int
+	}{
+		{name: "no credential", method: http.MethodGet, target: "/shortLinks", wantCode: http.StatusUnauthorized},
+		{name: "unknown key", method: http.MethodGet, target: "/shortLinks", credential: "nope", wantCode: http.StatusForbidden},
+		{name: "invalid token", method: http.MethodGet, target: "/shortLinks", credential: viewer + "x", wantCode: http.StatusUnauthorized},
+		{name: "token without role", method: http.MethodGet, target: "/shortLinks", credential: issuer.token(t, "nobody", "", []string{"*"}), wantCode: http.StatusForbidden},
+		{name: "token without projects", method: http.MethodGet, target: "/shortLinks?host=allowed.link", credential: issuer.token(t, "nobody", "viewer", nil), wantCode: http.StatusForbidden},
+		{name: "viewer lists", method: http.MethodGet, target: "/shortLinks?host=allowed.link", credential: viewer, wantCode: http.StatusOK},
+		{name: "viewer creates", method: http.MethodPost, target: "/shortLinks", credential: viewer, body: link, wantCode: http.StatusForbidden},
+		{name: "editor creates", method: http.MethodPost, target: "/shortLinks", credential: editor, body: link, wantCode: http.StatusOK},
+		{name: "editor rewrites", method: http.MethodPost, target: "/v1/links:rewrite", credential: editor, body: `{}`, wantCode: http.StatusForbidden},
+		{name: "editor merges", method: http.MethodPost, target: "/v1/links:merge", credential: editor, body: `{}`, wantCode: http.StatusForbidden},
+		{name: "editor lists keys", method: http.MethodGet, target: "/v1/apiKeys", credential: editor, wantCode: http.StatusForbidden},
+		{name: "admin lists keys", method: http.MethodGet, target: "/v1/apiKeys", credential: testAdminKey, wantCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, tt.method, tt.target, tt.credential, tt.body, nil)
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestRouter_ProjectScope(t *testing.T) {
+	issuer := newTestIssuer(t)
+	router := newTestRouter(t, issuer, nil)
+	scoped := issuer.token(t, "scoped", "editor", []string{"allowed.link"})
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		wantCode    int
+		wantBody    string
+	}{
+		{name: "own project", method: http.MethodPost, target: "/shortLinks", body: `{"durableLinkInfo":{"host":"allowed.link","link":"https://example.com/a"}}`, wantCode: http.StatusOK, wantBody: "https://allowed.link/"},
+		{name: "other project", method: http.MethodPost, target: "/shortLinks", body: `{"durableLinkInfo":{"host":"victim.link","link":"https://example.com/a"}}`, wantCode: http.StatusForbidden},
+		{name: "other project in query", method: http.MethodGet, target: "/shortLinks?host=victim.link", wantCode: http.StatusForbidden},
+		{name: "keys differing in case", method: http.MethodPost, target: "/shortLinks", body: `{"durableLinkInfo":{"host":"victim.link","link":"https://example.com/a"},"DurableLinkInfo":{"host":"allowed.link"}}`, wantCode: http.StatusForbidden},
+		{name: "duplicate keys", method: http.MethodPost, target: "/shortLinks", body: `{"durableLinkInfo":{"host":"victim.link","link":"https://example.com/a","host":"allowed.link"}}`, wantCode: http.StatusOK, wantBody: "https://allowed.link/"},
+		{name: "other project in batch", method: http.MethodPost, target: "/shortLinks:batch", body: `{"requests":[{"durableLinkInfo":{"host":"allowed.link","link":"https://example.com/a"}},{"longDurableLink":"https://victim.link/?link=https://example.com/b"}]}`, wantCode: http.StatusForbidden},
+		{name: "body too large", method: http.MethodPost, target: "/shortLinks", body: `{"durableLinkInfo":{"host":"allowed.link","link":"https://example.com/a"}` + strings.Repeat(" ", maxRequestBody) + `}`, wantCode: http.StatusForbidden},
+		{name: "more than one value", method: http.MethodPost, target: "/shortLinks", body: `{"durableLinkInfo":{"host":"allowed.link","link":"https://example.com/a"}} {"durableLinkInfo":{"host":"victim.link"}}`, wantCode: http.StatusForbidden},
+		{name: "import rows", method: http.MethodPost, target: "/shortLinks/import?host=allowed.link", contentType: "text/csv", body: "Path,Long Durable Link\na,https://allowed.link/?link=https://example.com/a\nb,https://victim.link/?link=https://example.com/b\n", wantCode: http.StatusOK, wantBody: `"row":3,"error":{"code":403`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.contentType != "" {
+				header.Set("Content-Type", tt.contentType)
+			}
+			w := serve(router, tt.method, tt.target, scoped, tt.body, header)
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestRouter_Preflight(t *testing.T) {
+	issuer := newTestIssuer(t)
+	router := newTestRouter(t, issuer, map[string]string{"ANONYMOUS_SHORTENER_ENABLED": "true", "ANONYMOUS_HOST": "allowed.link", "ANONYMOUS_ALLOWED_DOMAINS": "example.com"})
+
+	tests := []struct {
+		name       string
+		target     string
+		origin     string
+		headers    string
+		wantOrigin string
+	}{
+		{name: "public CAPTCHA token", target: "/v1/anonymous/shortLinks", origin: "https://app.example.com", headers: "Content-Type, X-Captcha-Token", wantOrigin: "*"},
+		{name: "admin idempotency key", target: "/shortLinks", origin: "https://admin.example.com", headers: "Authorization, Idempotency-Key", wantOrigin: "https://admin.example.com"},
+		{name: "admin from another origin", target: "/shortLinks", origin: "https://app.example.com", headers: "Authorization"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("Origin", tt.origin)
+			header.Set("Access-Control-Request-Method", http.MethodPost)
+			header.Set("Access-Control-Request-Headers", tt.headers)
+			w := serve(router, http.MethodOptions, tt.target, "", "", header)
+
+			assert.Less(t, w.Code, 300)
+			assert.Equal(t, tt.wantOrigin, w.Header().Get("Access-Control-Allow-Origin"))
+			if tt.wantOrigin != "" {
+				for _, name := range strings.Split(tt.headers, ", ") {
+					assert.Contains(t, strings.ToLower(w.Header().Get("Access-Control-Allow-Headers")), strings.ToLower(name))
+				}
+			}
+		})
+	}
+}
+
+func TestRouter_Idempotent(t *testing.T) {
+	issuer := newTestIssuer(t)
+	router := newTestRouter(t, issuer, nil)
+	header := http.Header{}
+	header.Set(idempotencyKeyHeader, "create-a")
+	link := `{"durableLinkInfo":{"host":"allowed.link","link":"https://example.com/a"}}`
+
+	first := serve(router, http.MethodPost, "/shortLinks", testAdminKey, link, header)
+	assert.Equal(t, http.StatusOK, first.Code, first.Body.String())
+	assert.Empty(t, first.Header().Get(idempotentReplayedHeader))
+
+	again := serve(router, http.MethodPost, "/shortLinks", testAdminKey, link, header)
+	assert.Equal(t, http.StatusOK, again.Code)
+	assert.Equal(t, "true", again.Header().Get(idempotentReplayedHeader))
+	assert.JSONEq(t, first.Body.String(), again.Body.String())
+
+	other := serve(router, http.MethodPost, "/shortLinks", testAdminKey, `{"durableLinkInfo":{"host":"allowed.link","link":"https://example.com/b"}}`, header)
+	assert.Equal(t, http.StatusUnprocessableEntity, other.Code)
+}
+
+func TestRouter_BodyLimit(t *testing.T) {
+	issuer := newTestIssuer(t)
+	router := newTestRouter(t, issuer, nil)
+	tooLarge := `{"durableLinkInfo":{"host":"allowed.link","link":"https://example.com/` + strings.Repeat("a", maxRequestBody) + `"}}`
+	withKey := http.Header{}
+	withKey.Set(idempotencyKeyHeader, "large")
+
+	tests := []struct {
+		name   string
+		target string
+		header http.Header
+	}{
+		{name: "create", target: "/shortLinks"},
+		{name: "batch", target: "/shortLinks:batch"},
+		{name: "idempotency key", target: "/shortLinks", header: withKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(router, http.MethodPost, tt.target, testAdminKey, tooLarge, tt.header)
+			assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+		})
+	}
+}
@@ -1,36 +1,395 @@
 package api
 
 import (
-	"database/sql"
+	"fmt"
+	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/cors"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
 
+	"durable-links-generator/api/authz"
+	"durable-links-generator/api/cache"
+	"durable-links-generator/api/captcha"
+	"durable-links-generator/api/clientip"
+	"durable-links-generator/api/limiter"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/notify"
 	"durable-links-generator/api/repository"
 	"durable-links-generator/api/service"
+	"durable-links-generator/api/webui"
 	"durable-links-generator/config"
+	"durable-links-generator/db"
 )
 
-func NewRouter(database *sql.DB, cfg *config.Config) *chi.Mux {
+func NewRouter(database *db.DB, cfg *config.Config, notifier notify.Notifier, services *Services, redisClient *redis.Client) (*chi.Mux, error) {
 	r := chi.NewRouter()
+	r.Use(RequestID)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.RealIP)
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		AllowCredentials: true,
-		MaxAge:           300,
-	}))
-
-	linkRepository := repository.NewLinkRepository(database)
-	linkService := service.NewLinkService(linkRepository, cfg)
-	handler := NewHandler(linkService)
-
-	r.Post("/shortLinks", handler.CreateLink)
-	r.Post("/exchangeShortLink", handler.ExchangeShortLink)
-
-	return r
+	proxies, err := clientip.New(cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+	r.Use(proxies.Middleware)
+	r.Use(middleware.GetHead)
+
+	verifier, err := captcha.New(captcha.Options{
+		Provider:   cfg.Server.CaptchaProvider,
+		Secret:     cfg.Server.CaptchaSecret,
+		Timeout:    cfg.Server.CaptchaTimeout,
+		Difficulty: cfg.Server.CaptchaDifficulty,
+		TTL:        cfg.Server.CaptchaTTL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("set up CAPTCHA verification: %w", err)
+	}
+	r.Use(RequireCaptcha(verifier, cfg.Server.CaptchaEndpoints))
+
+	if cfg.Server.ConcurrencyLimitEnabled {
+		r.Use(LimitConcurrency(limiter.New(limiter.Options{
+			InitialLimit:  cfg.Server.ConcurrencyInitialLimit,
+			MinLimit:      cfg.Server.ConcurrencyMinLimit,
+			MaxLimit:      cfg.Server.ConcurrencyMaxLimit,
+			LatencyTarget: cfg.Server.ConcurrencyLatencyTarget,
+		})))
+	}
+
+	// Redirects and exchanges run in the read lane, link creation and the admin API in the write
+	// lane, each with its own connection pool and queue.
+	readLane := UseLane(db.LaneRead, laneQueue(cfg, cfg.Server.ReadLaneLimit, cfg.Server.ReadLaneQueue))
+	writeLane := UseLane(db.LaneWrite, laneQueue(cfg, cfg.Server.WriteLaneLimit, cfg.Server.WriteLaneQueue))
+
+	var rateLimiter limiter.RateLimiter = limiter.NewMemory()
+	if redisClient != nil {
+		rateLimiter = limiter.NewRedis(redisClient)
+	}
+	rateLimit := RateLimit(rateLimiter, newRateQuotas(cfg))
+	idempotent := Idempotent(repository.NewIdempotencyRepository(database.Write), cfg.Server.IdempotencyKeyTTL)
+
+	// With OPA configured, link creation and the admin API also need a policy decision.
+	authorize := func(next http.Handler) http.Handler { return next }
+	if cfg.Server.OPAURL != "" {
+		opa := authz.NewOPA(cfg.Server.OPAURL, cfg.Server.OPAPolicyPath, cfg.Server.OPATimeout)
+		authorize = Authorize(opa, cfg.Server.OPAFailOpen)
+	}
+
+	linkService := services.linkService
+	linkRepository := services.linkRepository
+	domainRepository := services.domainRepository
+	domainConfigs := services.domainConfigs
+
+	var attributionHandler AttributionHandler
+	if services.installClicks != nil {
+		attributionHandler = NewAttributionHandler(service.NewAttributionService(services.installClicks, cfg))
+	}
+	previewTemplates, err := loadPreviewTemplates(cfg.App.PreviewPageTemplates)
+	if err != nil {
+		return nil, fmt.Errorf("load preview page templates: %w", err)
+	}
+	for name, behavior := range map[string]string{
+		"RESERVED_LINK_BEHAVIOR":  cfg.App.ReservedLinkBehavior,
+		"PRELAUNCH_LINK_BEHAVIOR": cfg.App.PrelaunchLinkBehavior,
+		"EXPIRED_LINK_BEHAVIOR":   cfg.App.ExpiredLinkBehavior,
+	} {
+		if err := validateLinkBehavior(behavior); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	handler := NewHandler(linkService, cfg, previewTemplates)
+
+	domainService, err := service.NewDomainService(cfg, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid domain verification config: %w", err)
+	}
+	domainService.WithDomainConfigs(domainConfigs)
+	domainHandler := NewDomainHandler(domainService)
+	domainConfigHandler := NewDomainConfigHandler(service.NewDomainConfigService(domainRepository, domainConfigs, domainService))
+
+	sdkService, err := service.NewSDKService(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("load SDK signing key: %w", err)
+	}
+	sdkHandler := NewSDKHandler(cfg, sdkService)
+	versionHandler := NewVersionHandler(cfg, database)
+	openAPIHandler, err := NewOpenAPIHandler(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("build the OpenAPI document: %w", err)
+	}
+
+	webhookRepository := repository.NewWebhookRepositoryForDB(database)
+
+	selfTestService := service.NewSelfTestService(linkService, linkRepository, cfg, renderScannerPreview, notifier).
+		WithWebhooks(webhookRepository)
+	selfTestHandler := NewSelfTestHandler(selfTestService, cfg)
+
+	savedSearchService := service.NewSavedSearchService(repository.NewSavedSearchRepository(database.Write), linkService)
+	savedSearchHandler := NewSavedSearchHandler(savedSearchService)
+
+	assetLinksHandler := NewAssetLinksHandler(service.NewAssetLinksService(repository.NewAppFingerprintRepositoryForDB(database)))
+
+	webhookHandler := NewWebhookHandler(service.NewWebhookService(webhookRepository))
+
+	utmPresetHandler := NewUTMPresetHandler(service.NewUTMPresetService(services.utmPresets))
+
+	campaignHandler := NewCampaignHandler(service.NewCampaignService(repository.NewCampaignRepository(database.Write), linkRepository, cfg))
+
+	staleLinkHandler := NewStaleLinkHandler(service.NewStaleLinkService(linkRepository, cfg, notifier))
+
+	importHandler := NewImportHandler(service.NewFirebaseImportService(linkService, cfg), cfg)
+	linkTransferHandler := NewLinkTransferHandler(service.NewLinkTransferService(linkService, linkRepository, cfg))
+
+	summaryHandler := NewSummaryHandler(service.NewSummaryService(linkRepository, cfg))
+
+	roleHandler := NewRoleHandler(services.roleService)
+	auditHandler := NewAuditHandler(service.NewAuditService(services.auditRepository))
+
+	dashboardHandler := NewDashboardHandler(service.NewDashboardService(repository.NewDashboardRepositoryForDB(database)))
+
+	fixtureHandler := NewFixtureHandler(service.NewFixtureService(linkRepository, cfg))
+
+	cacheStats, _ := services.linkCache.(cache.StatsReporter)
+	cacheHandler := NewCacheHandler(cacheStats)
+
+	cardRenderer, err := newCardRenderer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("set up link cards: %w", err)
+	}
+	cardHandler := NewCardHandler(linkService, cardRenderer)
+
+	qrCodeHandler, err := NewQRCodeHandler(linkService, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("set up QR codes: %w", err)
+	}
+
+	var anonymousHandler AnonymousHandler
+	if cfg.App.AnonymousEnabled {
+		anonymousService, err := service.NewAnonymousService(linkService, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("set up anonymous shortener: %w", err)
+		}
+		anonymousHandler = NewAnonymousHandler(anonymousService)
+	}
+
+	r.Group(func(r chi.Router) {
+		r.Use(publicCORS(cfg))
+		r.Use(RecordActor)
+
+		// Creating links takes an editor's key, unless PUBLIC_LINK_CREATION opens it to anyone
+		// like the Firebase API, which only asked for a project's public web API key.
+		if cfg.App.PublicLinkCreation {
+			r.Group(func(r chi.Router) {
+				r.Use(writeLane)
+				r.Use(authorize)
+				r.With(rateLimit, idempotent).Post("/shortLinks", handler.CreateLink)
+				r.Options("/shortLinks", preflight)
+				r.With(rateLimit, idempotent).Post("/shortLinks:batch", handler.CreateLinks)
+				r.Options("/shortLinks:batch", preflight)
+			})
+		}
+
+		// Keyless creation for public shortener front-ends, behind its own rate limit and the
+		// CAPTCHA check (see CAPTCHA_ENDPOINTS) instead of the key based authorization.
+		if anonymousHandler != nil {
+			r.Group(func(r chi.Router) {
+				r.Use(writeLane)
+				r.Post("/v1/anonymous/shortLinks", anonymousHandler.CreateLink)
+				r.Options("/v1/anonymous/shortLinks", preflight)
+			})
+		}
+
+		r.Group(func(r chi.Router) {
+			r.Use(readLane)
+			r.With(rateLimit).Post("/exchangeShortLink", handler.ExchangeShortLink)
+			r.Options("/exchangeShortLink", preflight)
+			r.Get("/v1/resolve.txt", handler.ResolveText)
+			r.Options("/v1/resolve.txt", preflight)
+			r.Get("/.well-known/link-policy", domainHandler.LinkPolicy)
+			r.Options("/.well-known/link-policy", preflight)
+			r.Get("/.well-known/apple-app-site-association", domainHandler.AppSiteAssociation)
+			r.Get("/apple-app-site-association", domainHandler.AppSiteAssociation)
+			r.Get("/.well-known/assetlinks.json", assetLinksHandler.AssetLinks)
+			r.Get("/v1/sdk/webConfig.js", sdkHandler.WebConfigJS)
+			r.Options("/v1/sdk/webConfig.js", preflight)
+			r.Get("/v1/version", versionHandler.Version)
+			r.Options("/v1/version", preflight)
+			r.Get("/openapi.json", openAPIHandler.Spec)
+			r.Options("/openapi.json", preflight)
+			if cfg.Server.SwaggerUIEnabled {
+				r.Get("/v1/docs", openAPIHandler.SwaggerUI)
+			}
+			if challenger, ok := verifier.(captcha.Challenger); ok {
+				r.Get("/v1/captcha/challenge", NewCaptchaHandler(challenger).Challenge)
+				r.Options("/v1/captcha/challenge", preflight)
+			}
+			if attributionHandler != nil {
+				r.Post("/installAttribution", attributionHandler.InstallAttribution)
+				r.Options("/installAttribution", preflight)
+			}
+			r.Get("/v1/links/{path}/card.png", cardHandler.Card)
+			r.Options("/v1/links/{path}/card.png", preflight)
+			r.Get("/shortLinks/{path}/qr", qrCodeHandler.QRCode)
+			r.Options("/shortLinks/{path}/qr", preflight)
+			if cfg.App.FixtureSeed != "" {
+				r.Get("/v1/fixtures", fixtureHandler.ListFixtures)
+				r.Options("/v1/fixtures", preflight)
+			}
+		})
+	})
+
+	// Viewers can list and read links, editors can also create and update them, and admins can
+	// delete, bulk rewrite and merge them and manage domains and API keys.
+	viewer := RequireRole(models.RoleViewer)
+	editor := RequireRole(models.RoleEditor)
+	admin := RequireRole(models.RoleAdmin)
+
+	r.Group(func(r chi.Router) {
+		r.Use(adminCORS(cfg))
+		r.Use(Authenticate(services.authenticator))
+		r.Use(RecordActor)
+		r.Use(authorize)
+		r.Use(writeLane)
+		r.With(admin).Post("/v1/domains", domainConfigHandler.Create)
+		r.With(viewer).Get("/v1/domains", domainConfigHandler.List)
+		r.Options("/v1/domains", preflight)
+		r.With(viewer).Get("/v1/domains/{domain}", domainConfigHandler.Get)
+		r.With(admin).Put("/v1/domains/{domain}", domainConfigHandler.Update)
+		r.With(admin).Delete("/v1/domains/{domain}", domainConfigHandler.Delete)
+		r.Options("/v1/domains/{domain}", preflight)
+		r.With(viewer).Get("/v1/domains/verification", domainHandler.ListVerifications)
+		r.Options("/v1/domains/verification", preflight)
+		r.With(viewer).Get("/v1/domains/{domain}/verification", domainHandler.VerifyDomain)
+		r.Options("/v1/domains/{domain}/verification", preflight)
+		r.With(admin).Post("/v1/domains/{domain}/androidApps", assetLinksHandler.AddFingerprint)
+		r.With(viewer).Get("/v1/domains/{domain}/androidApps", assetLinksHandler.ListFingerprints)
+		r.Options("/v1/domains/{domain}/androidApps", preflight)
+		r.With(admin).Delete("/v1/domains/{domain}/androidApps/{packageName}/{fingerprint}", assetLinksHandler.DeleteFingerprint)
+		r.Options("/v1/domains/{domain}/androidApps/{packageName}/{fingerprint}", preflight)
+		r.With(admin).Post("/v1/domains/{domain}/webhooks", webhookHandler.Create)
+		r.With(viewer).Get("/v1/domains/{domain}/webhooks", webhookHandler.List)
+		r.Options("/v1/domains/{domain}/webhooks", preflight)
+		r.With(admin).Delete("/v1/domains/{domain}/webhooks/{id}", webhookHandler.Delete)
+		r.Options("/v1/domains/{domain}/webhooks/{id}", preflight)
+		r.With(viewer).Get("/v1/domains/{domain}/webhooks/{id}/deliveries", webhookHandler.Deliveries)
+		r.Options("/v1/domains/{domain}/webhooks/{id}/deliveries", preflight)
+		r.With(admin).Post("/v1/domains/{domain}/utmPresets", utmPresetHandler.Create)
+		r.With(viewer).Get("/v1/domains/{domain}/utmPresets", utmPresetHandler.List)
+		r.Options("/v1/domains/{domain}/utmPresets", preflight)
+		r.With(admin).Delete("/v1/domains/{domain}/utmPresets/{name}", utmPresetHandler.Delete)
+		r.Options("/v1/domains/{domain}/utmPresets/{name}", preflight)
+		r.With(editor).Post("/v1/domains/{domain}/campaigns", campaignHandler.Create)
+		r.With(viewer).Get("/v1/domains/{domain}/campaigns", campaignHandler.List)
+		r.Options("/v1/domains/{domain}/campaigns", preflight)
+		r.With(viewer).Get("/v1/domains/{domain}/campaigns/{id}", campaignHandler.Get)
+		r.With(admin).Delete("/v1/domains/{domain}/campaigns/{id}", campaignHandler.Delete)
+		r.Options("/v1/domains/{domain}/campaigns/{id}", preflight)
+		r.With(editor).Post("/v1/domains/{domain}/campaigns/{id}/links", campaignHandler.AddLinks)
+		r.With(viewer).Get("/v1/domains/{domain}/campaigns/{id}/links", campaignHandler.ListLinks)
+		r.Options("/v1/domains/{domain}/campaigns/{id}/links", preflight)
+		r.With(editor).Delete("/v1/domains/{domain}/campaigns/{id}/links/{path}", campaignHandler.RemoveLink)
+		r.Options("/v1/domains/{domain}/campaigns/{id}/links/{path}", preflight)
+		r.With(viewer).Get("/v1/domains/{domain}/campaigns/{id}/stats", campaignHandler.Stats)
+		r.Options("/v1/domains/{domain}/campaigns/{id}/stats", preflight)
+		r.With(admin).Post("/v1/links:rewrite", handler.RewriteLinks)
+		r.Options("/v1/links:rewrite", preflight)
+		r.With(admin).Post("/v1/links:merge", handler.MergeLinks)
+		r.Options("/v1/links:merge", preflight)
+		r.With(editor).Post("/import/firebase", importHandler.ImportFirebase)
+		r.Options("/import/firebase", preflight)
+		r.With(editor).Post("/shortLinks/import", linkTransferHandler.ImportLinks)
+		r.Options("/shortLinks/import", preflight)
+		r.With(viewer).Get("/shortLinks/export", linkTransferHandler.ExportLinks)
+		r.Options("/shortLinks/export", preflight)
+		r.With(editor).Post("/shortLinks/{path}:supersede", handler.SupersedeLink)
+		r.Options("/shortLinks/{path}:supersede", preflight)
+		r.With(editor).Post("/shortLinks/{path}:unarchive", staleLinkHandler.UnarchiveLink)
+		r.Options("/shortLinks/{path}:unarchive", preflight)
+		r.With(viewer).Get("/shortLinks/{path}", handler.GetLink)
+		r.With(editor).Patch("/shortLinks/{path}", handler.UpdateLink)
+		r.With(admin).Delete("/shortLinks/{path}", handler.DeleteLink)
+		r.Options("/shortLinks/{path}", preflight)
+		r.With(editor).Post("/shortLinks/{path}:restore", handler.RestoreLink)
+		r.Options("/shortLinks/{path}:restore", preflight)
+		r.With(editor).Post("/shortLinks/{path}:reserve", handler.ReserveLink)
+		r.Options("/shortLinks/{path}:reserve", preflight)
+		r.With(viewer).Get("/shortLinks/{path}/stats", handler.LinkStats)
+		r.Options("/shortLinks/{path}/stats", preflight)
+		r.With(viewer).Get("/v1/reports/staleLinks", staleLinkHandler.StaleLinks)
+		r.Options("/v1/reports/staleLinks", preflight)
+		r.With(viewer).Get("/v1/summary", summaryHandler.Summary)
+		r.Options("/v1/summary", preflight)
+		r.With(viewer).Get("/v1/links/revisions", handler.ListRevisions)
+		r.Options("/v1/links/revisions", preflight)
+		if !cfg.App.PublicLinkCreation {
+			r.With(editor, rateLimit, idempotent).Post("/shortLinks", handler.CreateLink)
+			r.Options("/shortLinks", preflight)
+			r.With(editor, rateLimit, idempotent).Post("/shortLinks:batch", handler.CreateLinks)
+			r.Options("/shortLinks:batch", preflight)
+		}
+		// The preflight for /shortLinks is registered with its POST.
+		r.With(viewer).Get("/shortLinks", handler.ListLinks)
+		r.With(viewer).Get("/v1/search", handler.SearchLinks)
+		r.Options("/v1/search", preflight)
+		r.With(viewer).Post("/v1/savedSearches", savedSearchHandler.Create)
+		r.With(viewer).Get("/v1/savedSearches", savedSearchHandler.List)
+		r.Options("/v1/savedSearches", preflight)
+		r.With(viewer).Delete("/v1/savedSearches/{name}", savedSearchHandler.Delete)
+		r.Options("/v1/savedSearches/{name}", preflight)
+		r.With(viewer).Get("/v1/savedSearches/{name}/results", savedSearchHandler.Run)
+		r.Options("/v1/savedSearches/{name}/results", preflight)
+		r.With(editor).Post("/v1/links/{path}/aliases", handler.CreateAlias)
+		r.With(viewer).Get("/v1/links/{path}/aliases", handler.ListAliases)
+		r.Options("/v1/links/{path}/aliases", preflight)
+		r.With(admin).Delete("/v1/links/{path}/aliases/{alias}", handler.DeleteAlias)
+		r.Options("/v1/links/{path}/aliases/{alias}", preflight)
+		r.With(viewer).Get("/v1/links/{path}/geoDestinations", handler.GeoDestinations)
+		r.With(editor).Put("/v1/links/{path}/geoDestinations", handler.SetGeoDestinations)
+		r.Options("/v1/links/{path}/geoDestinations", preflight)
+		r.With(editor).Post("/v1/signedLinks", handler.CreateSignedLink)
+		r.Options("/v1/signedLinks", preflight)
+		r.With(admin).Post("/v1/admin/selftest", selfTestHandler.SelfTest)
+		r.Options("/v1/admin/selftest", preflight)
+		r.With(admin).Get("/v1/admin/cacheStats", cacheHandler.CacheStats)
+		r.Options("/v1/admin/cacheStats", preflight)
+		r.With(viewer).Get("/admin/api/overview", dashboardHandler.Overview)
+		r.Options("/admin/api/overview", preflight)
+		r.With(viewer).Get("/admin/api/linksCreated", dashboardHandler.LinksCreated)
+		r.Options("/admin/api/linksCreated", preflight)
+		r.With(viewer).Get("/admin/api/topDestinations", dashboardHandler.TopDestinations)
+		r.Options("/admin/api/topDestinations", preflight)
+		r.With(viewer).Get("/admin/api/topHosts", dashboardHandler.TopHosts)
+		r.Options("/admin/api/topHosts", preflight)
+		r.With(viewer).Get("/admin/api/storage", dashboardHandler.Storage)
+		r.Options("/admin/api/storage", preflight)
+		r.With(admin).Post("/v1/apiKeys", roleHandler.CreateAPIKey)
+		r.With(admin).Get("/v1/apiKeys", roleHandler.ListAPIKeys)
+		r.Options("/v1/apiKeys", preflight)
+		r.With(admin).Delete("/v1/apiKeys/{id}", roleHandler.DeleteAPIKey)
+		r.Options("/v1/apiKeys/{id}", preflight)
+		r.With(admin).Get("/v1/subjectRoles", roleHandler.ListSubjectRoles)
+		r.Options("/v1/subjectRoles", preflight)
+		r.With(admin).Put("/v1/subjectRoles/{subject}", roleHandler.SetSubjectRole)
+		r.With(admin).Delete("/v1/subjectRoles/{subject}", roleHandler.DeleteSubjectRole)
+		r.Options("/v1/subjectRoles/{subject}", preflight)
+		r.With(admin).Get("/auditlog", auditHandler.List)
+		r.Options("/auditlog", preflight)
+	})
+
+	// The web UI's files hold nothing secret, so they are served to anyone; the API calls the UI
+	// makes carry the API key the user signs in with.
+	if cfg.Server.WebUIEnabled {
+		if !services.authenticator.enabled() {
+			log.Warn().Msg("WEB_UI_ENABLED is set without ADMIN_API_KEYS or OIDC_ISSUER, so nobody can sign in to the web UI")
+		}
+		r.Get("/ui", http.RedirectHandler("/ui/", http.StatusMovedPermanently).ServeHTTP)
+		r.Get("/ui/*", http.StripPrefix("/ui", webui.Handler()).ServeHTTP)
+	}
+
+	r.With(readLane).Get("/c/{code}", handler.RedirectCode)
+	// Signed links resolve without the database, so they skip the read lane.
+	r.Get("/s/{token}", handler.RedirectSigned)
+	r.With(readLane).Get("/{shortPath}", handler.Redirect)
+
+	return r, nil
 }
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type SavedSearchHandler interface {
+	Create(w http.ResponseWriter, r *http.Request)
+	List(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+	Run(w http.ResponseWriter, r *http.Request)
+}
+
+type savedSearchHandler struct {
+	savedSearchService service.SavedSearchService
+}
+
+func NewSavedSearchHandler(savedSearchService service.SavedSearchService) SavedSearchHandler {
+	return &savedSearchHandler{
+		savedSearchService: savedSearchService,
+	}
+}
+
+// owner identifies whose saved searches a request works on: the fingerprint of its API key, or
+// of its token's subject.
+func owner(r *http.Request) string {
+	return callerFingerprint(r)
+}
+
+func (h *savedSearchHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var search models.SavedSearch
+	if err := json.NewDecoder(r.Body).Decode(&search); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+
+	saved, err := h.savedSearchService.Create(r.Context(), owner(r), search)
+	if err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(saved)
+}
+
+func (h *savedSearchHandler) List(w http.ResponseWriter, r *http.Request) {
+	searches, err := h.savedSearchService.List(r.Context(), owner(r))
+	if err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+
+	writeProjectedJSON(w, r, models.SavedSearchesResponse{SavedSearches: searches}, "savedSearches")
+}
+
+func (h *savedSearchHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.savedSearchService.Delete(r.Context(), owner(r), chi.URLParam(r, "name")); err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *savedSearchHandler) Run(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			WriteErrorResponse(w, http.StatusBadRequest, "limit must be a positive integer", models.StatusInvalidArgument)
+			return
+		}
+		limit = n
+	}
+
+	resp, err := h.savedSearchService.Run(r.Context(), owner(r), chi.URLParam(r, "name"), limit)
+	if err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+
+	writeProjectedJSON(w, r, resp, "results")
+}
+
+func writeSavedSearchError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrInvalidSearchName),
+		errors.Is(err, apperrors.ErrMissingQuery),
+		errors.Is(err, apperrors.ErrMissingHost),
+		errors.Is(err, apperrors.ErrInvalidSort):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrSavedSearchNotFound):
+		WriteError(w, err, http.StatusNotFound, err.Error(), models.StatusNotFound)
+	case errors.Is(err, apperrors.ErrSavedSearchExists):
+		WriteError(w, err, http.StatusConflict, err.Error(), models.StatusAlreadyExists)
+	default:
+		requestLog(w).Error().Err(err).Msg("Saved search request failed")
+		WriteError(w, err, http.StatusInternalServerError, "Saved search request failed", models.StatusInternal)
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"durable-links-generator/api/service"
+	"durable-links-generator/config"
+
+	"github.com/rs/zerolog/log"
+)
+
+type SDKHandler interface {
+	WebConfigJS(w http.ResponseWriter, r *http.Request)
+}
+
+type sdkHandler struct {
+	cfg        *config.Config
+	sdkService service.SDKService
+}
+
+func NewSDKHandler(cfg *config.Config, sdkService service.SDKService) SDKHandler {
+	return &sdkHandler{
+		cfg:        cfg,
+		sdkService: sdkService,
+	}
+}
+
+// WebConfigJS serves the deployment's web SDK config as a script that assigns it to
+// window.DurableLinksConfig. Its API base is PUBLIC_API_BASE; without it, the base is taken from
+// the request, and the script may only be cached by the browser that asked for it.
+func (h *sdkHandler) WebConfigJS(w http.ResponseWriter, r *http.Request) {
+	apiBase := h.cfg.App.PublicAPIBase
+	cacheControl := "public, max-age=300"
+	if apiBase == "" {
+		scheme := "https"
+		if r.TLS == nil {
+			scheme = "http"
+		}
+		apiBase = fmt.Sprintf("%s://%s", scheme, r.Host)
+		cacheControl = "private, max-age=300"
+		w.Header().Set("Vary", "Host")
+	}
+
+	signed, err := h.sdkService.WebConfig(apiBase)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to build web SDK config")
+		http.Error(w, "Failed to build config", http.StatusInternalServerError)
+		return
+	}
+
+	payload, err := json.Marshal(signed)
+	if err != nil {
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to encode web SDK config")
+		http.Error(w, "Failed to build config", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+	w.Header().Set("Cache-Control", cacheControl)
+	fmt.Fprintf(w, "window.DurableLinksConfig = Object.freeze(%s);\n", payload)
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/service"
+	"durable-links-generator/config"
+)
+
+type SelfTestHandler interface {
+	SelfTest(w http.ResponseWriter, r *http.Request)
+}
+
+type selfTestHandler struct {
+	selfTestService service.SelfTestService
+	cfg             *config.Config
+}
+
+func NewSelfTestHandler(selfTestService service.SelfTestService, cfg *config.Config) SelfTestHandler {
+	return &selfTestHandler{
+		selfTestService: selfTestService,
+		cfg:             cfg,
+	}
+}
+
+// SelfTest runs the pipeline smoke test against the requested host, the first configured domain
+// or the request's own host. It answers 503 when any step failed.
+func (h *selfTestHandler) SelfTest(w http.ResponseWriter, r *http.Request) {
+	var req models.SelfTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+
+	host := req.Host
+	if host == "" && len(h.cfg.App.Domains) > 0 {
+		host = h.cfg.App.Domains[0]
+	}
+	if host == "" {
+		host = r.Host
+	}
+
+	report := h.selfTestService.Run(r.Context(), host)
+
+	status := http.StatusOK
+	if !report.Passed {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
+
+// renderScannerPreview renders the scanner preview page for target, discarding the output.
+func renderScannerPreview(target *models.RedirectTarget) error {
+	return pageTemplates.ExecuteTemplate(io.Discard, "scanner_preview.html", scannerPreviewPage{
+		Destination:     target.Destination,
+		DestinationHost: hostOf(target.Destination),
+	})
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/limiter"
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+type AnonymousService interface {
+	CreateLink(ctx context.Context, req models.AnonymousLinkRequest, clientIP string) (*models.ShortLinkResponse, error)
+}
+
+type anonymousService struct {
+	linkService LinkService
+	cfg         *config.Config
+	clients     *limiter.Tenants
+}
+
+// NewAnonymousService sets up keyless link creation for public shortener front-ends. Every
+// client IP gets ANONYMOUS_RATE_PER_MINUTE links with bursts of ANONYMOUS_BURST, on
+// ANONYMOUS_HOST, to destinations on ANONYMOUS_ALLOWED_DOMAINS only. The client IP must come
+// from the connection or TRUSTED_PROXIES, not from headers any client can set.
+func NewAnonymousService(linkService LinkService, cfg *config.Config) (*anonymousService, error) {
+	if cfg.App.AnonymousHost == "" {
+		return nil, errors.New("ANONYMOUS_HOST must be set for the anonymous shortener")
+	}
+	if len(cfg.App.AnonymousAllowedDomains) == 0 {
+		return nil, errors.New("ANONYMOUS_ALLOWED_DOMAINS must be set for the anonymous shortener")
+	}
+	if cfg.App.AnonymousRatePerMinute <= 0 {
+		return nil, errors.New("ANONYMOUS_RATE_PER_MINUTE must be positive")
+	}
+
+	return &anonymousService{
+		linkService: linkService,
+		cfg:         cfg,
+		clients: limiter.NewTenants(limiter.TenantLimits{
+			QPS:   float64(cfg.App.AnonymousRatePerMinute) / 60,
+			Burst: cfg.App.AnonymousBurst,
+		}, nil),
+	}, nil
+}
+
+func (s *anonymousService) CreateLink(ctx context.Context, req models.AnonymousLinkRequest, clientIP string) (*models.ShortLinkResponse, error) {
+	release, ok := s.clients.Acquire(anonymousClient(clientIP))
+	if !ok {
+		log.Ctx(ctx).Warn().Str("client_ip", clientIP).Msg("Anonymous creation rate limit reached")
+		return nil, apperrors.ErrRateLimited
+	}
+	defer release()
+
+	if req.Link == "" {
+		return nil, apperrors.ErrMissingLink
+	}
+	if err := utils.ValidateURLScheme(req.Link); err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrInvalidFormat, err)
+	}
+	if !utils.IsDomainAllowed(s.cfg.App.AnonymousAllowedDomains, req.Link) {
+		return nil, apperrors.ErrDomainLinkNotAllowed
+	}
+
+	resp, err := s.linkService.CreateDurableLink(ctx, models.CreateDurableLinkRequest{
+		DurableLinkInfo: models.DurableLinkInfo{Host: s.cfg.App.AnonymousHost, Link: req.Link},
+		Suffix:          models.Suffix{Option: "SHORT"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Info().
+		Str("client_ip", clientIP).
+		Str("short_link", resp.ShortLink).
+		Msg("Anonymous link created")
+	return resp, nil
+}
+
+// anonymousClient returns the key clientIP is rate limited under: the address itself, or its /64
+// network for IPv6, as every IPv6 client can pick any address of its network.
+func anonymousClient(clientIP string) string {
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil || !addr.Unmap().Is6() {
+		return clientIP
+	}
+	prefix, _ := addr.Prefix(64)
+	return prefix.String()
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnonymousService(t *testing.T) {
+	cfg := &config.Config{App: &config.AppConfig{
+		URLScheme:               "https",
+		ShortPathLength:         6,
+		AllowedDomains:          []string{"example.com", "partner.com"},
+		AnonymousHost:           "go.page.link",
+		AnonymousAllowedDomains: []string{"example.com"},
+		AnonymousRatePerMinute:  1,
+		AnonymousBurst:          2,
+	}}
+	repo := &fakeLinkRepository{}
+	s, err := NewAnonymousService(newTestLinkService(t, repo, cfg), cfg)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	resp, err := s.CreateLink(ctx, models.AnonymousLinkRequest{Link: "https://example.com/a"}, "10.0.0.1")
+	assert.NoError(t, err)
+	if assert.NotNil(t, resp) {
+		assert.Regexp(t, `^https://go\.page\.link/\w{6}$`, resp.ShortLink)
+	}
+
+	_, err = s.CreateLink(ctx, models.AnonymousLinkRequest{Link: "https://partner.com/a"}, "10.0.0.1")
+	assert.ErrorIs(t, err, apperrors.ErrDomainLinkNotAllowed, "only the anonymous allow-list applies")
+
+	_, err = s.CreateLink(ctx, models.AnonymousLinkRequest{Link: "https://example.com/b"}, "10.0.0.1")
+	assert.ErrorIs(t, err, apperrors.ErrRateLimited)
+
+	for i, ip := range []string{"2001:db8::1", "2001:db8::2"} {
+		_, err = s.CreateLink(ctx, models.AnonymousLinkRequest{Link: fmt.Sprintf("https://example.com/v6-%d", i)}, ip)
+		assert.NoError(t, err)
+	}
+	_, err = s.CreateLink(ctx, models.AnonymousLinkRequest{Link: "https://example.com/v6"}, "2001:db8::3")
+	assert.ErrorIs(t, err, apperrors.ErrRateLimited, "IPv6 clients are limited per /64")
+
+	_, err = s.CreateLink(ctx, models.AnonymousLinkRequest{}, "10.0.0.2")
+	assert.ErrorIs(t, err, apperrors.ErrMissingLink)
+	assert.Len(t, repo.links, 3)
+
+	_, err = NewAnonymousService(newTestLinkService(t, repo, cfg), &config.Config{App: &config.AppConfig{AnonymousHost: "go.page.link"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+	"durable-links-generator/utils"
+)
+
+// Android application IDs: at least two dot separated segments, each starting with a letter.
+var packageNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*(\.[A-Za-z][A-Za-z0-9_]*)+$`)
+
+type AssetLinksService interface {
+	AssetLinks(ctx context.Context, host string) ([]models.AssetStatement, error)
+	AddFingerprint(ctx context.Context, host string, fingerprint models.AndroidAppFingerprint) (*models.AndroidAppFingerprint, error)
+	ListFingerprints(ctx context.Context, host string) ([]models.AndroidAppFingerprint, error)
+	DeleteFingerprint(ctx context.Context, host, packageName, fingerprint string) error
+}
+
+type assetLinksService struct {
+	repo repository.AppFingerprintRepository
+}
+
+// NewAssetLinksService manages the Android apps allowed to open each host's links as App Links.
+func NewAssetLinksService(repo repository.AppFingerprintRepository) *assetLinksService {
+	return &assetLinksService{
+		repo: repo,
+	}
+}
+
+// AssetLinks returns the assetlinks.json statements for host, one per app. Preview hosts have
+// none, so their links open the preview page instead of the app.
+func (s *assetLinksService) AssetLinks(ctx context.Context, host string) ([]models.AssetStatement, error) {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return nil, apperrors.ErrDomainNotFound
+	}
+	fingerprints, err := s.repo.ListAppFingerprints(ctx, strings.ToLower(host))
+	if err != nil {
+		return nil, err
+	}
+	if len(fingerprints) == 0 {
+		return nil, apperrors.ErrDomainNotFound
+	}
+
+	// Fingerprints come ordered by package, so each app's are adjacent.
+	var statements []models.AssetStatement
+	for _, fp := range fingerprints {
+		if n := len(statements); n > 0 && statements[n-1].Target.PackageName == fp.PackageName {
+			statements[n-1].Target.SHA256CertFingerprints = append(statements[n-1].Target.SHA256CertFingerprints, fp.SHA256CertFingerprint)
+			continue
+		}
+		statements = append(statements, models.AssetStatement{
+			Relation: []string{"delegate_permission/common.handle_all_urls"},
+			Target: models.AssetTarget{
+				Namespace:              "android_app",
+				PackageName:            fp.PackageName,
+				SHA256CertFingerprints: []string{fp.SHA256CertFingerprint},
+			},
+		})
+	}
+	return statements, nil
+}
+
+func (s *assetLinksService) AddFingerprint(ctx context.Context, host string, fingerprint models.AndroidAppFingerprint) (*models.AndroidAppFingerprint, error) {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return nil, err
+	}
+	if !packageNamePattern.MatchString(fingerprint.PackageName) {
+		return nil, apperrors.ErrInvalidPackageName
+	}
+	if fingerprint.SHA256CertFingerprint, err = normalizeFingerprint(fingerprint.SHA256CertFingerprint); err != nil {
+		return nil, err
+	}
+
+	return s.repo.AddAppFingerprint(ctx, host, fingerprint)
+}
+
+func (s *assetLinksService) ListFingerprints(ctx context.Context, host string) ([]models.AndroidAppFingerprint, error) {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListAppFingerprints(ctx, host)
+}
+
+func (s *assetLinksService) DeleteFingerprint(ctx context.Context, host, packageName, fingerprint string) error {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return err
+	}
+	fingerprint, err = normalizeFingerprint(fingerprint)
+	if err != nil {
+		return apperrors.ErrFingerprintNotFound
+	}
+	return s.repo.DeleteAppFingerprint(ctx, host, packageName, fingerprint)
+}
+
+func normalizeDomain(domain string) (string, error) {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if domain == "" {
+		return "", apperrors.ErrMissingHost
+	}
+	return domain, nil
+}
+
+// normalizeFingerprint accepts a SHA-256 fingerprint with or without colons, in either case, and
+// returns it the way keytool and the Play Console print it: "14:6D:E9:...".
+func normalizeFingerprint(fingerprint string) (string, error) {
+	raw, err := hex.DecodeString(strings.ReplaceAll(strings.TrimSpace(fingerprint), ":", ""))
+	if err != nil || len(raw) != 32 {
+		return "", apperrors.ErrInvalidFingerprint
+	}
+	parts := make([]string, len(raw))
+	for i, b := range raw {
+		parts[i] = strings.ToUpper(hex.EncodeToString([]byte{b}))
+	}
+	return strings.Join(parts, ":"), nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testFingerprint = "14:6D:E9:83:C5:73:06:50:D8:EE:B9:95:2F:34:FC:64:16:A0:83:42:E6:1D:BE:A8:8A:04:96:B2:3F:CF:44:E5"
+
+type fakeAppFingerprintRepository struct {
+	fingerprints map[string][]models.AndroidAppFingerprint
+}
+
+func (f *fakeAppFingerprintRepository) AddAppFingerprint(_ context.Context, host string, fp models.AndroidAppFingerprint) (*models.AndroidAppFingerprint, error) {
+	if f.fingerprints == nil {
+		f.fingerprints = map[string][]models.AndroidAppFingerprint{}
+	}
+	f.fingerprints[host] = append(f.fingerprints[host], fp)
+	return &fp, nil
+}
+
+func (f *fakeAppFingerprintRepository) ListAppFingerprints(_ context.Context, host string) ([]models.AndroidAppFingerprint, error) {
+	return f.fingerprints[host], nil
+}
+
+func (f *fakeAppFingerprintRepository) DeleteAppFingerprint(context.Context, string, string, string) error {
+	return apperrors.ErrFingerprintNotFound
+}
+
+func TestAssetLinks(t *testing.T) {
+	repo := &fakeAppFingerprintRepository{}
+	s := NewAssetLinksService(repo)
+	ctx := context.Background()
+
+	lower := "146de983c5730650d8eeb9952f34fc6416a08342e61dbea88a0496b23fcf44e5"
+	added, err := s.AddFingerprint(ctx, " Acme.link ", models.AndroidAppFingerprint{PackageName: "com.acme.app", SHA256CertFingerprint: lower})
+	assert.NoError(t, err)
+	assert.Equal(t, testFingerprint, added.SHA256CertFingerprint)
+	_, err = s.AddFingerprint(ctx, "acme.link", models.AndroidAppFingerprint{PackageName: "com.acme.app", SHA256CertFingerprint: "AB:" + testFingerprint[3:]})
+	assert.NoError(t, err)
+	_, err = s.AddFingerprint(ctx, "acme.link", models.AndroidAppFingerprint{PackageName: "com.acme.beta", SHA256CertFingerprint: testFingerprint})
+	assert.NoError(t, err)
+
+	statements, err := s.AssetLinks(ctx, "acme.link:443")
+	assert.NoError(t, err)
+	if assert.Len(t, statements, 2) {
+		assert.Equal(t, []string{"delegate_permission/common.handle_all_urls"}, statements[0].Relation)
+		assert.Equal(t, "com.acme.app", statements[0].Target.PackageName)
+		assert.Len(t, statements[0].Target.SHA256CertFingerprints, 2)
+		assert.Equal(t, "com.acme.beta", statements[1].Target.PackageName)
+	}
+
+	_, err = s.AssetLinks(ctx, "other.link")
+	assert.ErrorIs(t, err, apperrors.ErrDomainNotFound)
+}
+
+func TestAddFingerprint_Invalid(t *testing.T) {
+	s := NewAssetLinksService(&fakeAppFingerprintRepository{})
+	ctx := context.Background()
+
+	_, err := s.AddFingerprint(ctx, "acme.link", models.AndroidAppFingerprint{PackageName: "acme", SHA256CertFingerprint: testFingerprint})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidPackageName)
+	_, err = s.AddFingerprint(ctx, "acme.link", models.AndroidAppFingerprint{PackageName: "com.acme.app", SHA256CertFingerprint: "14:6D:E9"})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidFingerprint)
+	_, err = s.AddFingerprint(ctx, "", models.AndroidAppFingerprint{PackageName: "com.acme.app", SHA256CertFingerprint: testFingerprint})
+	assert.ErrorIs(t, err, apperrors.ErrMissingHost)
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/attribution"
+	"durable-links-generator/api/device"
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+)
+
+const (
+	MatchNone    = "NONE"
+	MatchWeak    = "WEAK"
+	MatchDefault = "DEFAULT"
+)
+
+type AttributionService interface {
+	Attribute(ctx context.Context, req models.InstallAttributionRequest) (*models.InstallAttributionResponse, error)
+}
+
+type attributionService struct {
+	store attribution.Store
+	cfg   *config.Config
+}
+
+// NewAttributionService matches app installs to the clicks that led to them, for deferred deep
+// linking.
+func NewAttributionService(store attribution.Store, cfg *config.Config) *attributionService {
+	return &attributionService{
+		store: store,
+		cfg:   cfg,
+	}
+}
+
+// Attribute claims the click most likely to have led to the install. Only clicks from the same
+// address and OS are candidates; among them the one matching the most of OS version and language
+// wins, the most recent on a tie. Claimed clicks can't be claimed again.
+func (s *attributionService) Attribute(_ context.Context, req models.InstallAttributionRequest) (*models.InstallAttributionResponse, error) {
+	if req.Platform != device.PlatformAndroid && req.Platform != device.PlatformIOS {
+		return nil, apperrors.ErrInvalidPlatform
+	}
+	fp := attribution.Fingerprint{
+		IP:        req.IP,
+		OS:        req.Platform,
+		OSVersion: majorVersion(req.OSVersion),
+		Language:  attribution.Language(req.Locale),
+	}
+
+	matchType := MatchNone
+	click, ok := s.store.Claim(fp.IP, func(candidates []attribution.Click) int {
+		best, bestScore, sameOS := -1, -1, 0
+		for i, c := range candidates {
+			if c.OS != fp.OS {
+				continue
+			}
+			sameOS++
+			if score := fingerprintScore(c.Fingerprint, fp); score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best >= 0 {
+			matchType = MatchWeak
+			if sameOS == 1 && bestScore > 0 {
+				matchType = MatchDefault
+			}
+		}
+		return best
+	})
+	if !ok {
+		return &models.InstallAttributionResponse{MatchType: MatchNone}, nil
+	}
+
+	return &models.InstallAttributionResponse{
+		MatchType: matchType,
+		DeepLink:  click.DeepLink,
+		ShortLink: s.cfg.App.URLScheme + "://" + click.Host + "/" + click.Path,
+		Variant:   click.Variant,
+	}, nil
+}
+
+// fingerprintScore counts the known attributes two fingerprints share beyond address and OS.
+func fingerprintScore(a, b attribution.Fingerprint) int {
+	score := 0
+	if a.OSVersion != "" && a.OSVersion == b.OSVersion {
+		score++
+	}
+	if a.Language != "" && a.Language == b.Language {
+		score++
+	}
+	return score
+}
+
+// majorVersion returns "17" for "17.4.1".
+func majorVersion(version string) string {
+	for i, r := range version {
+		if r < '0' || r > '9' {
+			return version[:i]
+		}
+	}
+	return version
+}
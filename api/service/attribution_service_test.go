@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/attribution"
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttribute(t *testing.T) {
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{Host: "acme.link", Path: "app", QueryParams: "link=https%3A%2F%2Fexample.com%2Fitem%2F42&apn=com.acme.app"},
+		{Host: "acme.link", Path: "web", QueryParams: "link=https%3A%2F%2Fexample.com"},
+	}}
+	cfg := &config.Config{App: &config.AppConfig{URLScheme: "https"}}
+	store := attribution.NewMemoryStore(time.Hour, 0)
+	links := newTestLinkService(t, repo, cfg).WithInstallClicks(store)
+	s := NewAttributionService(store, cfg)
+	ctx := context.Background()
+
+	click := models.ClickContext{
+		UserAgent:      "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36",
+		IP:             "203.0.113.7",
+		AcceptLanguage: "de-DE,de;q=0.9",
+	}
+	for _, path := range []string{"app", "web"} {
+		_, err := links.ResolveRedirect(ctx, "acme.link", path, click)
+		assert.NoError(t, err)
+	}
+
+	resp, err := s.Attribute(ctx, models.InstallAttributionRequest{Platform: "ios", IP: "203.0.113.7"})
+	assert.NoError(t, err)
+	assert.Equal(t, MatchNone, resp.MatchType, "other OS")
+
+	resp, err = s.Attribute(ctx, models.InstallAttributionRequest{Platform: "android", OSVersion: "14.0", Locale: "de_DE", IP: "203.0.113.7"})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.InstallAttributionResponse{
+		MatchType: MatchDefault,
+		DeepLink:  "https://example.com/item/42",
+		ShortLink: "https://acme.link/app",
+	}, resp)
+
+	resp, err = s.Attribute(ctx, models.InstallAttributionRequest{Platform: "android", IP: "203.0.113.7"})
+	assert.NoError(t, err)
+	assert.Equal(t, MatchNone, resp.MatchType, "already claimed, and links without an app aren't kept")
+
+	_, err = s.Attribute(ctx, models.InstallAttributionRequest{Platform: "web"})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidPlatform)
+}
+
+func TestAttribute_Weak(t *testing.T) {
+	store := attribution.NewMemoryStore(time.Hour, 0)
+	s := NewAttributionService(store, &config.Config{App: &config.AppConfig{URLScheme: "https"}})
+	now := time.Now()
+	for _, lang := range []string{"en", "fr"} {
+		store.Record(attribution.Click{
+			Fingerprint: attribution.Fingerprint{IP: "203.0.113.7", OS: "ios", Language: lang},
+			Host:        "acme.link",
+			Path:        lang,
+			At:          now,
+		})
+	}
+
+	resp, err := s.Attribute(context.Background(), models.InstallAttributionRequest{Platform: "ios", Locale: "fr-FR", IP: "203.0.113.7"})
+	assert.NoError(t, err)
+	assert.Equal(t, MatchWeak, resp.MatchType, "more than one candidate")
+	assert.Equal(t, "https://acme.link/fr", resp.ShortLink)
+}
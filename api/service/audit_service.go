@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 500
+	// auditPageSort is the sort of audit log page tokens, which can't be used to page links.
+	auditPageSort = "auditlog"
+)
+
+// AuditService reads the audit log the audited link and domain repositories append to.
+type AuditService interface {
+	List(ctx context.Context, req models.AuditLogRequest) (*models.AuditLogResponse, error)
+}
+
+type auditService struct {
+	repo repository.AuditRepository
+}
+
+func NewAuditService(repo repository.AuditRepository) *auditService {
+	return &auditService{
+		repo: repo,
+	}
+}
+
+// List pages through the entries matching the request's filters, newest first.
+func (s *auditService) List(ctx context.Context, req models.AuditLogRequest) (*models.AuditLogResponse, error) {
+	if !req.From.IsZero() && !req.To.IsZero() && !req.From.Before(req.To) {
+		return nil, apperrors.ErrInvalidAuditRange
+	}
+	filter := models.AuditFilter{
+		Host:     strings.ToLower(strings.TrimSpace(req.Host)),
+		Resource: req.Resource,
+		Action:   req.Action,
+		Actor:    req.Actor,
+		From:     req.From,
+		To:       req.To,
+		Limit:    defaultAuditPageSize,
+	}
+	if req.PageSize > 0 {
+		filter.Limit = min(req.PageSize, maxAuditPageSize)
+	}
+	if req.PageToken != "" {
+		token, err := decodePageToken(req.PageToken)
+		if err != nil || token.Sort != auditPageSort {
+			return nil, apperrors.ErrInvalidPageToken
+		}
+		filter.BeforeID = token.ID
+	}
+
+	// One extra entry tells whether there is a next page.
+	filter.Limit++
+	entries, err := s.repo.ListAuditEntries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	resp := &models.AuditLogResponse{Entries: entries}
+	if len(entries) == filter.Limit {
+		resp.Entries = entries[:filter.Limit-1]
+		last := resp.Entries[len(resp.Entries)-1]
+		resp.NextPageToken = encodePageToken(pageToken{Sort: auditPageSort, ID: last.ID})
+	}
+	return resp, nil
+}
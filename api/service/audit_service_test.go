@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAuditRepository struct {
+	repository.AuditRepository
+	entries []models.AuditEntry
+}
+
+func (f *fakeAuditRepository) ListAuditEntries(_ context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	entries := []models.AuditEntry{}
+	for i := len(f.entries) - 1; i >= 0 && len(entries) < filter.Limit; i-- {
+		if filter.BeforeID == 0 || f.entries[i].ID < filter.BeforeID {
+			entries = append(entries, f.entries[i])
+		}
+	}
+	return entries, nil
+}
+
+func TestAuditService(t *testing.T) {
+	repo := &fakeAuditRepository{}
+	for id := int64(1); id <= 5; id++ {
+		repo.entries = append(repo.entries, models.AuditEntry{ID: id, Action: models.AuditLinkCreate})
+	}
+	s := NewAuditService(repo)
+	ctx := context.Background()
+
+	page, err := s.List(ctx, models.AuditLogRequest{PageSize: 3})
+	assert.NoError(t, err)
+	if assert.Len(t, page.Entries, 3) {
+		assert.Equal(t, int64(5), page.Entries[0].ID, "newest first")
+	}
+	assert.NotEmpty(t, page.NextPageToken)
+
+	page, err = s.List(ctx, models.AuditLogRequest{PageSize: 3, PageToken: page.NextPageToken})
+	assert.NoError(t, err)
+	assert.Len(t, page.Entries, 2)
+	assert.Empty(t, page.NextPageToken)
+
+	_, err = s.List(ctx, models.AuditLogRequest{PageToken: encodePageToken(pageToken{Sort: "createdAt", ID: 3})})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidPageToken, "link page tokens don't page the audit log")
+	now := time.Now()
+	_, err = s.List(ctx, models.AuditLogRequest{From: now, To: now.Add(-time.Hour)})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidAuditRange)
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+	"durable-links-generator/config"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	maxCampaignNameLength = 255
+	// maxCampaignLinks bounds the links added to a campaign in one request.
+	maxCampaignLinks = 1000
+)
+
+// Campaign ids go in URLs as they are and are usually the utm_campaign of their links.
+var campaignIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+type CampaignService interface {
+	Create(ctx context.Context, host string, campaign models.Campaign) (*models.Campaign, error)
+	List(ctx context.Context, host string) ([]models.Campaign, error)
+	Get(ctx context.Context, host, id string) (*models.Campaign, error)
+	Delete(ctx context.Context, host, id string) error
+	AddLinks(ctx context.Context, host, id string, paths []string) (*models.Campaign, error)
+	RemoveLink(ctx context.Context, host, id, path string) error
+	ListLinks(ctx context.Context, host, id string) ([]models.CampaignLink, error)
+	Stats(ctx context.Context, host, id string, req models.LinkStatsRequest) (*models.CampaignStatsResponse, error)
+}
+
+type campaignService struct {
+	repo  repository.CampaignRepository
+	links repository.LinkRepository
+	cfg   *config.Config
+	now   func() time.Time
+}
+
+func NewCampaignService(repo repository.CampaignRepository, links repository.LinkRepository, cfg *config.Config) *campaignService {
+	return &campaignService{
+		repo:  repo,
+		links: links,
+		cfg:   cfg,
+		now:   time.Now,
+	}
+}
+
+func (s *campaignService) Create(ctx context.Context, host string, campaign models.Campaign) (*models.Campaign, error) {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return nil, err
+	}
+	id := normalizeCampaignID(campaign.ID)
+	if !campaignIDPattern.MatchString(id) {
+		return nil, apperrors.ErrInvalidCampaignID
+	}
+	name := strings.TrimSpace(campaign.Name)
+	if utf8.RuneCountInString(name) > maxCampaignNameLength {
+		return nil, apperrors.ErrInvalidCampaignName
+	}
+
+	created, err := s.repo.CreateCampaign(ctx, models.Campaign{Host: host, ID: id, Name: name})
+	if err != nil {
+		return nil, err
+	}
+	log.Ctx(ctx).Info().Str("host", host).Str("campaign", id).Msg("Campaign created")
+	return created, nil
+}
+
+func (s *campaignService) List(ctx context.Context, host string) ([]models.Campaign, error) {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListCampaigns(ctx, host)
+}
+
+func (s *campaignService) Get(ctx context.Context, host, id string) (*models.Campaign, error) {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetCampaign(ctx, host, normalizeCampaignID(id))
+}
+
+// Delete deletes the campaign. Its links stay as they are.
+func (s *campaignService) Delete(ctx context.Context, host, id string) error {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return err
+	}
+	id = normalizeCampaignID(id)
+	if err := s.repo.DeleteCampaign(ctx, host, id); err != nil {
+		return err
+	}
+	log.Ctx(ctx).Info().Str("host", host).Str("campaign", id).Msg("Campaign deleted")
+	return nil
+}
+
+// AddLinks puts the links at paths in the campaign, moving them out of the campaign they were
+// in. Aliases add the link they are an alias of. Nothing is added when a link doesn't exist.
+func (s *campaignService) AddLinks(ctx context.Context, host, id string, paths []string) (*models.Campaign, error) {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return nil, err
+	}
+	id = normalizeCampaignID(id)
+	if len(paths) == 0 || len(paths) > maxCampaignLinks {
+		return nil, apperrors.ErrInvalidCampaignLinks
+	}
+	if _, err := s.repo.GetCampaign(ctx, host, id); err != nil {
+		return nil, err
+	}
+
+	canonical := make([]string, 0, len(paths))
+	for _, path := range paths {
+		resolved, err := s.links.GetCanonicalPath(ctx, host, path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if !slices.Contains(canonical, resolved) {
+			canonical = append(canonical, resolved)
+		}
+	}
+	if err := s.repo.AddCampaignLinks(ctx, host, id, canonical); err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Info().
+		Str("host", host).
+		Str("campaign", id).
+		Int("links", len(canonical)).
+		Msg("Links added to campaign")
+	return s.repo.GetCampaign(ctx, host, id)
+}
+
+// RemoveLink takes the link at path, or the link path is an alias of, out of the campaign.
+func (s *campaignService) RemoveLink(ctx context.Context, host, id, path string) error {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return err
+	}
+	id = normalizeCampaignID(id)
+	if _, err := s.repo.GetCampaign(ctx, host, id); err != nil {
+		return err
+	}
+	// A deleted link no longer resolves but can still be taken out by its own path.
+	if canonical, err := s.links.GetCanonicalPath(ctx, host, path); err == nil {
+		path = canonical
+	}
+	return s.repo.RemoveCampaignLink(ctx, host, id, path)
+}
+
+func (s *campaignService) ListLinks(ctx context.Context, host, id string) ([]models.CampaignLink, error) {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return nil, err
+	}
+	id = normalizeCampaignID(id)
+	if _, err := s.repo.GetCampaign(ctx, host, id); err != nil {
+		return nil, err
+	}
+	links, err := s.repo.ListCampaignLinks(ctx, host, id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range links {
+		links[i].ShortLink = s.shortLink(host, links[i].Path)
+	}
+	return links, nil
+}
+
+// Stats counts the clicks on the links now in the campaign and their aliases, those from
+// before they joined it included, as LinkStats counts them for one link.
+func (s *campaignService) Stats(ctx context.Context, host, id string, req models.LinkStatsRequest) (*models.CampaignStatsResponse, error) {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return nil, err
+	}
+	id = normalizeCampaignID(id)
+	from, to, granularity, err := statsWindow(req, s.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetCampaign(ctx, host, id); err != nil {
+		return nil, err
+	}
+
+	stats, err := s.links.ClickStats(ctx, host, models.ClickStatsQuery{
+		Campaign:     id,
+		From:         from,
+		To:           to,
+		Granularity:  granularity,
+		TopReferrers: statsTopReferrers,
+	})
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.repo.ListCampaignLinks(ctx, host, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.CampaignStatsResponse{
+		Campaign:        id,
+		From:            from,
+		To:              to,
+		Granularity:     granularity,
+		Clicks:          stats.Clicks,
+		UniqueClicks:    stats.UniqueClicks,
+		ConsentedClicks: stats.ConsentedClicks,
+		Buckets:         allBuckets(stats.Buckets, from, to, granularity),
+		Platforms:       redirectPlatforms(stats.Sources),
+		TopReferrers:    stats.TopReferrers,
+		Links:           []models.LinkClicks{},
+	}
+	// Links without clicks come last, in the order they were added.
+	clicked := map[string]bool{}
+	for _, link := range stats.Links {
+		clicked[link.Path] = true
+		link.ShortLink = s.shortLink(host, link.Path)
+		resp.Links = append(resp.Links, link)
+	}
+	for _, link := range links {
+		if !clicked[link.Path] {
+			resp.Links = append(resp.Links, models.LinkClicks{Path: link.Path, ShortLink: s.shortLink(host, link.Path)})
+		}
+	}
+	return resp, nil
+}
+
+func (s *campaignService) shortLink(host, path string) string {
+	return fmt.Sprintf("%s://%s/%s", s.cfg.App.URLScheme, host, path)
+}
+
+// normalizeCampaignID lowercases id, so a campaign can be named after a utm_campaign in any case.
+func normalizeCampaignID(id string) string {
+	return strings.ToLower(strings.TrimSpace(id))
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeCampaignRepository struct {
+	repository.CampaignRepository
+	campaigns []models.Campaign
+	links     map[string]string // path -> campaign id
+}
+
+func (f *fakeCampaignRepository) CreateCampaign(_ context.Context, campaign models.Campaign) (*models.Campaign, error) {
+	for _, c := range f.campaigns {
+		if c.Host == campaign.Host && c.ID == campaign.ID {
+			return nil, apperrors.ErrCampaignExists
+		}
+	}
+	f.campaigns = append(f.campaigns, campaign)
+	return &campaign, nil
+}
+
+func (f *fakeCampaignRepository) GetCampaign(_ context.Context, host, id string) (*models.Campaign, error) {
+	for _, c := range f.campaigns {
+		if c.Host == host && c.ID == id {
+			for _, campaign := range f.links {
+				if campaign == id {
+					c.LinkCount++
+				}
+			}
+			return &c, nil
+		}
+	}
+	return nil, apperrors.ErrCampaignNotFound
+}
+
+func (f *fakeCampaignRepository) AddCampaignLinks(_ context.Context, _, id string, paths []string) error {
+	for _, path := range paths {
+		f.links[path] = id
+	}
+	return nil
+}
+
+func (f *fakeCampaignRepository) ListCampaignLinks(_ context.Context, _, id string) ([]models.CampaignLink, error) {
+	links := []models.CampaignLink{}
+	for _, path := range []string{"abc", "def", "ghi"} {
+		if f.links[path] == id {
+			links = append(links, models.CampaignLink{Path: path})
+		}
+	}
+	return links, nil
+}
+
+func TestCampaignService(t *testing.T) {
+	repo := &fakeCampaignRepository{links: map[string]string{}}
+	links := &fakeLinkRepository{
+		links: []models.StoredLink{
+			{Host: "acme.link", Path: "abc"},
+			{Host: "acme.link", Path: "def"},
+		},
+		aliases: map[string]string{"sale": "abc"},
+		clickStats: &models.ClickStats{
+			Clicks:       3,
+			UniqueClicks: 2,
+			Sources: []models.SourceClicks{
+				{Source: "redirect", Platform: "ios", Clicks: 3, UniqueClicks: 2},
+			},
+			TopReferrers: []models.ReferrerClicks{},
+			Links:        []models.LinkClicks{{Path: "abc", Clicks: 3, UniqueClicks: 2}},
+		},
+	}
+	s := NewCampaignService(repo, links, &config.Config{App: &config.AppConfig{URLScheme: "https"}})
+	ctx := context.Background()
+
+	created, err := s.Create(ctx, "Acme.link", models.Campaign{ID: "Spring-Sale", Name: "  Spring sale "})
+	assert.NoError(t, err)
+	assert.Equal(t, models.Campaign{Host: "acme.link", ID: "spring-sale", Name: "Spring sale"}, *created)
+	_, err = s.Create(ctx, "acme.link", models.Campaign{ID: "spring-sale"})
+	assert.ErrorIs(t, err, apperrors.ErrCampaignExists)
+	for _, id := range []string{"", "-spring", "spring sale", "spring/sale"} {
+		_, err = s.Create(ctx, "acme.link", models.Campaign{ID: id})
+		assert.ErrorIs(t, err, apperrors.ErrInvalidCampaignID, id)
+	}
+
+	campaign, err := s.AddLinks(ctx, "acme.link", "spring-sale", []string{"sale", "abc", "def"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), campaign.LinkCount, "the alias adds the link it points to")
+	_, err = s.AddLinks(ctx, "acme.link", "spring-sale", []string{"nope"})
+	assert.ErrorIs(t, err, apperrors.ErrLinkNotFound)
+	_, err = s.AddLinks(ctx, "acme.link", "spring-sale", nil)
+	assert.ErrorIs(t, err, apperrors.ErrInvalidCampaignLinks)
+	_, err = s.AddLinks(ctx, "acme.link", "winter", []string{"abc"})
+	assert.ErrorIs(t, err, apperrors.ErrCampaignNotFound)
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	stats, err := s.Stats(ctx, "acme.link", "Spring-Sale", models.LinkStatsRequest{From: from, To: from.AddDate(0, 0, 2)})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ClickStatsQuery{Campaign: "spring-sale", From: from, To: from.AddDate(0, 0, 2), Granularity: "day", TopReferrers: 10}, links.statsQuery)
+	assert.Len(t, stats.Buckets, 2)
+	assert.Equal(t, []models.PlatformClicks{{Platform: "ios", Clicks: 3, UniqueClicks: 2}}, stats.Platforms)
+	assert.Equal(t, []models.LinkClicks{
+		{Path: "abc", ShortLink: "https://acme.link/abc", Clicks: 3, UniqueClicks: 2},
+		{Path: "def", ShortLink: "https://acme.link/def"},
+	}, stats.Links, "links without clicks are listed too")
+
+	_, err = s.Stats(ctx, "acme.link", "spring-sale", models.LinkStatsRequest{Granularity: "minute"})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidGranularity)
+}
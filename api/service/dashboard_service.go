@@ -0,0 +1,154 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+)
+
+const (
+	defaultDashboardDays  = 30
+	maxDashboardDays      = 366
+	defaultDashboardLimit = 10
+	maxDashboardLimit     = 100
+)
+
+// DashboardService answers the admin dashboard API: read-only counts across all hosts. Days are
+// UTC days.
+type DashboardService interface {
+	Overview(ctx context.Context) (*models.DashboardOverviewResponse, error)
+	LinksCreated(ctx context.Context, days int) (*models.LinksCreatedResponse, error)
+	TopDestinations(ctx context.Context, limit int) (*models.TopDestinationsResponse, error)
+	TopHosts(ctx context.Context, limit int) (*models.TopHostsResponse, error)
+	Storage(ctx context.Context, days int) (*models.StorageResponse, error)
+}
+
+type dashboardService struct {
+	repo repository.DashboardRepository
+	now  func() time.Time
+}
+
+func NewDashboardService(repo repository.DashboardRepository) *dashboardService {
+	return &dashboardService{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+func (s *dashboardService) Overview(ctx context.Context) (*models.DashboardOverviewResponse, error) {
+	overview, err := s.repo.Overview(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &models.DashboardOverviewResponse{DashboardOverview: overview, GeneratedAt: s.now().UTC().Truncate(time.Second)}, nil
+}
+
+// LinksCreated counts the links created on each of the last days days, today included, 30 by
+// default and 366 at most.
+func (s *dashboardService) LinksCreated(ctx context.Context, days int) (*models.LinksCreatedResponse, error) {
+	from, to := s.dayRange(days)
+	counts, err := s.repo.LinksCreatedPerDay(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	resp := &models.LinksCreatedResponse{
+		From: from.Format(time.DateOnly),
+		To:   to.Format(time.DateOnly),
+		Days: counts,
+	}
+	for _, count := range counts {
+		resp.Total += count.Count
+	}
+	return resp, nil
+}
+
+// TopDestinations ranks destination domains by their links, 10 by default and 100 at most.
+func (s *dashboardService) TopDestinations(ctx context.Context, limit int) (*models.TopDestinationsResponse, error) {
+	destinations, err := s.repo.TopDestinations(ctx, dashboardLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	return &models.TopDestinationsResponse{Destinations: destinations}, nil
+}
+
+// TopHosts ranks hosts by their links, 10 by default and 100 at most.
+func (s *dashboardService) TopHosts(ctx context.Context, limit int) (*models.TopHostsResponse, error) {
+	hosts, err := s.repo.TopHosts(ctx, dashboardLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	return &models.TopHostsResponse{Hosts: hosts}, nil
+}
+
+// Storage reports the size of the tables and the rows added on each of the last days days, like
+// LinksCreated.
+func (s *dashboardService) Storage(ctx context.Context, days int) (*models.StorageResponse, error) {
+	from, _ := s.dayRange(days)
+	tables, err := s.repo.TableSizes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.repo.LinksCreatedPerDay(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := s.repo.RevisionsPerDay(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	clickEvents, err := s.repo.ClickEventsPerDay(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+
+	growth := map[string]*models.StorageDay{}
+	storageDay := func(day string) *models.StorageDay {
+		if growth[day] == nil {
+			growth[day] = &models.StorageDay{Day: day}
+		}
+		return growth[day]
+	}
+	for _, count := range links {
+		storageDay(count.Day).Links = count.Count
+	}
+	for _, count := range revisions {
+		storageDay(count.Day).Revisions = count.Count
+	}
+	for _, count := range clickEvents {
+		storageDay(count.Day).ClickEvents = count.Count
+	}
+
+	resp := &models.StorageResponse{
+		Tables: tables,
+		From:   from.Format(time.DateOnly),
+		Growth: make([]models.StorageDay, 0, len(growth)),
+	}
+	for _, day := range growth {
+		resp.Growth = append(resp.Growth, *day)
+	}
+	slices.SortFunc(resp.Growth, func(a, b models.StorageDay) int {
+		return cmp.Compare(a.Day, b.Day)
+	})
+	return resp, nil
+}
+
+// dayRange returns the first and last of the last days UTC days, today being the last.
+func (s *dashboardService) dayRange(days int) (time.Time, time.Time) {
+	if days <= 0 {
+		days = defaultDashboardDays
+	}
+	days = min(days, maxDashboardDays)
+	today := s.now().UTC().Truncate(24 * time.Hour)
+	return today.AddDate(0, 0, 1-days), today
+}
+
+func dashboardLimit(limit int) int {
+	if limit <= 0 {
+		return defaultDashboardLimit
+	}
+	return min(limit, maxDashboardLimit)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDashboardRepository struct {
+	repository.DashboardRepository
+	since time.Time
+	limit int
+}
+
+func (f *fakeDashboardRepository) LinksCreatedPerDay(_ context.Context, since time.Time) ([]models.DailyCount, error) {
+	f.since = since
+	return []models.DailyCount{{Day: "2024-06-01", Count: 4}, {Day: "2024-06-03", Count: 6}}, nil
+}
+
+func (f *fakeDashboardRepository) RevisionsPerDay(context.Context, time.Time) ([]models.DailyCount, error) {
+	return []models.DailyCount{{Day: "2024-06-03", Count: 2}}, nil
+}
+
+func (f *fakeDashboardRepository) ClickEventsPerDay(context.Context, time.Time) ([]models.DailyCount, error) {
+	return []models.DailyCount{{Day: "2024-05-31", Count: 9}, {Day: "2024-06-03", Count: 1}}, nil
+}
+
+func (f *fakeDashboardRepository) TableSizes(context.Context) ([]models.TableSize, error) {
+	return []models.TableSize{{Table: "durable_links", Rows: 10}}, nil
+}
+
+func (f *fakeDashboardRepository) TopHosts(_ context.Context, limit int) ([]models.HostCount, error) {
+	f.limit = limit
+	return []models.HostCount{}, nil
+}
+
+func TestDashboard(t *testing.T) {
+	repo := &fakeDashboardRepository{}
+	s := NewDashboardService(repo)
+	s.now = func() time.Time { return time.Date(2024, 6, 30, 23, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	created, err := s.LinksCreated(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-06-01", created.From, "30 days including today")
+	assert.Equal(t, "2024-06-30", created.To)
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), repo.since)
+	assert.Equal(t, int64(10), created.Total)
+
+	_, err = s.LinksCreated(ctx, 5000)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC), repo.since, "at most 366 days")
+
+	storage, err := s.Storage(ctx, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-06-24", storage.From)
+	assert.Equal(t, []models.StorageDay{
+		{Day: "2024-05-31", ClickEvents: 9},
+		{Day: "2024-06-01", Links: 4},
+		{Day: "2024-06-03", Links: 6, Revisions: 2, ClickEvents: 1},
+	}, storage.Growth)
+
+	_, err = s.TopHosts(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultDashboardLimit, repo.limit)
+	_, err = s.TopHosts(ctx, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, maxDashboardLimit, repo.limit)
+}
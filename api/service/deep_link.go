@@ -0,0 +1,80 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+)
+
+var deepLinkRoutePattern = regexp.MustCompile(`^[A-Za-z0-9._~/-]*$`)
+
+// validDeepLinkRoute reports whether route is a plain relative path.
+func validDeepLinkRoute(route string) bool {
+	return deepLinkRoutePattern.MatchString(route) && !strings.Contains(route, "..")
+}
+
+// deepLinkBase returns the web base URL deep link routes on host are composed against.
+func (s *linkService) deepLinkBase(host string) string {
+	if base, ok := s.cfg.App.DeepLinkBaseURLs[strings.ToLower(host)]; ok {
+		return base
+	}
+	return s.cfg.App.DeepLinkBaseURL
+}
+
+// composeDeepLink joins the route and params onto base, e.g. "https://app.example.com" with
+// route "product" and params id=42 gives "https://app.example.com/product?id=42".
+func composeDeepLink(base string, deepLink *models.DeepLink) (string, error) {
+	route := strings.Trim(deepLink.Route, "/")
+	if !validDeepLinkRoute(route) {
+		return "", apperrors.ErrInvalidDeepLink
+	}
+
+	u, err := url.Parse(base)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid deep link base URL %q", base)
+	}
+	if route != "" {
+		u.Path = strings.TrimRight(u.Path, "/") + "/" + route
+	}
+
+	query := u.Query()
+	for k, v := range deepLink.Params {
+		query.Set(k, v)
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// deepLinkQueryParams returns the stored form of a deep link: the route in "dlr" and the
+// encoded params in "dlp".
+func deepLinkQueryParams(deepLink *models.DeepLink) (route, params string) {
+	if deepLink == nil {
+		return "", ""
+	}
+	values := url.Values{}
+	for k, v := range deepLink.Params {
+		values.Set(k, v)
+	}
+	return strings.Trim(deepLink.Route, "/"), values.Encode()
+}
+
+// parseDeepLink reads a deep link back from the "dlr" and "dlp" params.
+func parseDeepLink(params url.Values) *models.DeepLink {
+	route, rawParams := params.Get("dlr"), params.Get("dlp")
+	if route == "" && rawParams == "" {
+		return nil
+	}
+
+	deepLink := &models.DeepLink{Route: route}
+	if values, err := url.ParseQuery(rawParams); err == nil && len(values) > 0 {
+		deepLink.Params = make(map[string]string, len(values))
+		for k := range values {
+			deepLink.Params[k] = values.Get(k)
+		}
+	}
+	return deepLink
+}
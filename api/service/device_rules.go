@@ -0,0 +1,148 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/device"
+	"durable-links-generator/api/models"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Device rules a link can have, at most.
+const maxDeviceRules = 20
+
+// OSes device rules can match. "ipados" is iOS on an iPad.
+var deviceRuleOSes = []string{"android", "ios", "ipados", "windows", "macos", "linux", "chromeos"}
+
+// Params of the link a device rule can send visitors to.
+var deviceRuleParams = []string{"link", "afl", "agl", "ifl", "ipfl", "ofl", "wl", "al", "il"}
+
+var osVersionPattern = regexp.MustCompile(`^\d+(\.\d+){0,3}$`)
+
+// normalizeDeviceRules validates rules, lower-casing their OSes. Their links must be allowed
+// destinations, like the link they override, and the params they name must be set in params.
+func normalizeDeviceRules(rules []models.DeviceRule, params url.Values, allowedDomains []string) ([]models.DeviceRule, error) {
+	if len(rules) > maxDeviceRules {
+		return nil, fmt.Errorf("%w: a link may have at most %d device rules", apperrors.ErrInvalidDeviceRule, maxDeviceRules)
+	}
+	normalized := make([]models.DeviceRule, 0, len(rules))
+	for i, rule := range rules {
+		invalid := func(format string, args ...any) error {
+			return fmt.Errorf("%w: rule %d: %s", apperrors.ErrInvalidDeviceRule, i, fmt.Sprintf(format, args...))
+		}
+
+		rule.OS = strings.ToLower(strings.TrimSpace(rule.OS))
+		if !slices.Contains(deviceRuleOSes, rule.OS) {
+			return nil, invalid("os must be one of: %s", strings.Join(deviceRuleOSes, ", "))
+		}
+		for _, version := range []string{rule.VersionBelow, rule.VersionAtLeast} {
+			if version != "" && !osVersionPattern.MatchString(version) {
+				return nil, invalid("%q is not a version like 10 or 16.4", version)
+			}
+		}
+		if rule.VersionBelow != "" && rule.VersionAtLeast != "" && compareVersions(rule.VersionAtLeast, rule.VersionBelow) >= 0 {
+			return nil, invalid("versionAtLeast must be lower than versionBelow")
+		}
+
+		switch {
+		case (rule.Link == "") == (rule.Param == ""):
+			return nil, invalid("exactly one of link and param must be set")
+		case rule.Link != "":
+			if err := utils.ValidateURLScheme(rule.Link); err != nil {
+				return nil, invalid("link must be an absolute http(s) URL")
+			}
+			if !utils.IsDomainAllowed(allowedDomains, rule.Link) {
+				return nil, apperrors.ErrDomainLinkNotAllowed
+			}
+		case !slices.Contains(deviceRuleParams, rule.Param):
+			return nil, invalid("param must be one of: %s", strings.Join(deviceRuleParams, ", "))
+		case params.Get(rule.Param) == "":
+			return nil, invalid("the link has no %s", rule.Param)
+		}
+		normalized = append(normalized, rule)
+	}
+	return normalized, nil
+}
+
+// deviceRuleLink returns where the first of the device rules of the link at path matching visitor
+// sends them, or "" when none does. Failed lookups are logged and match nothing.
+func (s *linkService) deviceRuleLink(ctx context.Context, host, path string, params url.Values, visitor device.Device) string {
+	if visitor.OS == "" {
+		return ""
+	}
+	rules, err := s.repo.ResolveDeviceRules(ctx, host, path)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("host", host).Str("path", path).Msg("Failed to read device rules")
+		return ""
+	}
+	for _, rule := range rules {
+		if !matchesDevice(rule, visitor) {
+			continue
+		}
+		link := rule.Link
+		if rule.Param != "" {
+			link = params.Get(rule.Param)
+		}
+		// A param emptied since the rule was made leaves the rule out.
+		if link != "" {
+			return link
+		}
+	}
+	return ""
+}
+
+func matchesDevice(rule models.DeviceRule, visitor device.Device) bool {
+	switch rule.OS {
+	case "ipados":
+		if visitor.OS != "ios" || !visitor.Tablet {
+			return false
+		}
+	default:
+		if visitor.OS != rule.OS {
+			return false
+		}
+	}
+	if rule.VersionBelow == "" && rule.VersionAtLeast == "" {
+		return true
+	}
+	if visitor.OSVersion == "" {
+		return false
+	}
+	if rule.VersionBelow != "" && compareVersions(visitor.OSVersion, rule.VersionBelow) >= 0 {
+		return false
+	}
+	if rule.VersionAtLeast != "" && compareVersions(visitor.OSVersion, rule.VersionAtLeast) < 0 {
+		return false
+	}
+	return true
+}
+
+// compareVersions compares dotted versions part by part, missing parts counting as 0, so "16"
+// equals "16.0".
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := range max(len(as), len(bs)) {
+		if c := cmp.Compare(versionPart(as, i), versionPart(bs, i)); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func versionPart(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	n, _ := strconv.Atoi(parts[i])
+	return n
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	android9UserAgent  = "Mozilla/5.0 (Linux; Android 9; SM-G960F) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36"
+	android14UserAgent = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36"
+	iPadUserAgent      = "Mozilla/5.0 (iPad; CPU OS 16_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.6 Mobile/15E148 Safari/604.1"
+	iPhoneUserAgent    = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1"
+)
+
+func TestDeviceRules(t *testing.T) {
+	repo := &fakeLinkRepository{}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:      "https",
+		AllowedDomains: []string{"example.com"},
+	}})
+	ctx := context.Background()
+	req := func(rules ...models.DeviceRule) models.CreateDurableLinkRequest {
+		return models.CreateDurableLinkRequest{
+			DurableLinkInfo: models.DurableLinkInfo{
+				Host:          "acme.link",
+				Link:          "https://example.com/app",
+				IosParameters: models.IosParameters{IosIpadFallbackLink: "https://example.com/ipad"},
+				DeviceRules:   rules,
+			},
+			Suffix: models.Suffix{Option: "CUSTOM", CustomPath: "app"},
+		}
+	}
+
+	_, err := s.CreateDurableLink(ctx, req(
+		models.DeviceRule{OS: "Android", VersionBelow: "10", Link: "https://example.com/legacy"},
+		models.DeviceRule{OS: "ipados", Param: "ipfl"},
+	))
+	assert.NoError(t, err)
+	assert.Equal(t, "android", repo.deviceRules["app"][0].OS)
+
+	for userAgent, want := range map[string]string{
+		android9UserAgent:  "https://example.com/legacy",
+		android14UserAgent: "https://example.com/app",
+		iPadUserAgent:      "https://example.com/ipad",
+		iPhoneUserAgent:    "https://example.com/app",
+	} {
+		target, err := s.ResolveRedirect(ctx, "acme.link", "app", models.ClickContext{UserAgent: userAgent})
+		assert.NoError(t, err)
+		assert.Equal(t, want, target.Destination, userAgent)
+	}
+
+	for _, rule := range []models.DeviceRule{
+		{OS: "symbian", Link: "https://example.com/a"},
+		{OS: "android", VersionBelow: "ten", Link: "https://example.com/a"},
+		{OS: "android", VersionAtLeast: "12", VersionBelow: "10", Link: "https://example.com/a"},
+		{OS: "android"},
+		{OS: "android", Link: "https://example.com/a", Param: "afl"},
+		{OS: "android", Param: "isi"},
+		{OS: "android", Param: "afl"},
+	} {
+		_, err := s.CreateDurableLink(ctx, req(rule))
+		assert.ErrorIs(t, err, apperrors.ErrInvalidDeviceRule, "%+v", rule)
+	}
+	_, err = s.CreateDurableLink(ctx, req(models.DeviceRule{OS: "ios", Link: "https://evil.test"}))
+	assert.ErrorIs(t, err, apperrors.ErrDomainLinkNotAllowed)
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, 0, compareVersions("16", "16.0"))
+	assert.Equal(t, -1, compareVersions("9", "10"))
+	assert.Equal(t, 1, compareVersions("16.4.1", "16.4"))
+}
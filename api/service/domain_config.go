@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Hosts whose settings are cached at once. Lookups come from request hosts, so the cache is
+// bounded rather than trusting every Host header to be one of ours.
+const maxCachedDomainConfigs = 1024
+
+// DomainConfigs reads the link settings of each host from the domains table and caches them for
+// DOMAIN_CONFIG_TTL. Changes made through DomainConfigService show on this instance at once and
+// on the others once their cached copy expires.
+type DomainConfigs struct {
+	repo    repository.DomainRepository
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]domainConfigEntry
+}
+
+type domainConfigEntry struct {
+	// domain is nil for hosts without a row.
+	domain  *models.DomainConfig
+	expires time.Time
+}
+
+func NewDomainConfigs(repo repository.DomainRepository, ttl time.Duration) *DomainConfigs {
+	return &DomainConfigs{
+		repo:    repo,
+		ttl:     ttl,
+		entries: map[string]domainConfigEntry{},
+	}
+}
+
+// Get returns the settings stored for host, or nil if it has none. The result is shared with
+// other callers and must not be modified.
+func (c *DomainConfigs) Get(ctx context.Context, host string) (*models.DomainConfig, error) {
+	host = strings.ToLower(host)
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[host]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.domain, nil
+	}
+
+	domain, err := c.repo.GetDomain(ctx, host)
+	if errors.Is(err, apperrors.ErrDomainNotFound) {
+		domain, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCachedDomainConfigs {
+		for cached, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, cached)
+			}
+		}
+		if len(c.entries) >= maxCachedDomainConfigs {
+			clear(c.entries)
+		}
+	}
+	c.entries[host] = domainConfigEntry{domain: domain, expires: now.Add(c.ttl)}
+	return domain, nil
+}
+
+// Invalidate drops the cached settings of host, so the next lookup reads the table.
+func (c *DomainConfigs) Invalidate(host string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, strings.ToLower(host))
+}
+
+// linkSettings are the settings links on a host are created with.
+type linkSettings struct {
+	allowedDomains []string
+	// fallbackDomains and imageDomains are the allow lists of fallback URLs and si, allowedDomains
+	// unless FALLBACK_ALLOWED_DOMAINS and IMAGE_ALLOWED_DOMAINS are set.
+	fallbackDomains           []string
+	imageDomains              []string
+	defaultAndroidPackageName string
+	defaultIosStoreId         string
+}
+
+// linkSettings returns the settings of host: its row in the domains table, or the env settings
+// for hosts without one.
+func (s *linkService) linkSettings(ctx context.Context, host string) (linkSettings, error) {
+	if s.domainConfigs != nil {
+		domain, err := s.domainConfigs.Get(ctx, host)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("host", host).Msg("Failed to read domain settings")
+			return linkSettings{}, err
+		}
+		if domain != nil {
+			return s.withParamDomains(linkSettings{
+				allowedDomains:            domain.AllowedDomains,
+				defaultAndroidPackageName: domain.DefaultAndroidPackageName,
+				defaultIosStoreId:         domain.DefaultIosStoreId,
+			}), nil
+		}
+	}
+
+	settings := linkSettings{allowedDomains: s.cfg.App.AllowedDomains}
+	if s.cfg.App.DefaultAndroidPackageName != nil {
+		settings.defaultAndroidPackageName = *s.cfg.App.DefaultAndroidPackageName
+	}
+	if s.cfg.App.DefaultIosStoreId != nil {
+		settings.defaultIosStoreId = *s.cfg.App.DefaultIosStoreId
+	}
+	return s.withParamDomains(settings), nil
+}
+
+// withParamDomains sets the allow lists of fallback URLs and si.
+func (s *linkService) withParamDomains(settings linkSettings) linkSettings {
+	settings.fallbackDomains = settings.allowedDomains
+	if len(s.cfg.App.FallbackAllowedDomains) > 0 {
+		settings.fallbackDomains = s.cfg.App.FallbackAllowedDomains
+	}
+	settings.imageDomains = settings.allowedDomains
+	if len(s.cfg.App.ImageAllowedDomains) > 0 {
+		settings.imageDomains = s.cfg.App.ImageAllowedDomains
+	}
+	return settings
+}
+
+type DomainConfigService interface {
+	Create(ctx context.Context, domain models.DomainConfig) (*models.DomainConfig, error)
+	List(ctx context.Context) ([]models.DomainConfig, error)
+	Get(ctx context.Context, host string) (*models.DomainConfig, error)
+	Update(ctx context.Context, host string, domain models.DomainConfig) (*models.DomainConfig, error)
+	Delete(ctx context.Context, host string) error
+}
+
+// DomainVerifier checks the DNS verification records of domains being registered.
+type DomainVerifier interface {
+	CheckVerification(ctx context.Context, domain string) models.DomainVerification
+}
+
+type domainConfigService struct {
+	repo     repository.DomainRepository
+	configs  *DomainConfigs
+	verifier DomainVerifier
+}
+
+// NewDomainConfigService manages the domains table, dropping the cached settings of every host it
+// changes. Domains are only registered once they publish their verification TXT record.
+func NewDomainConfigService(repo repository.DomainRepository, configs *DomainConfigs, verifier DomainVerifier) *domainConfigService {
+	return &domainConfigService{
+		repo:     repo,
+		configs:  configs,
+		verifier: verifier,
+	}
+}
+
+func (s *domainConfigService) Create(ctx context.Context, domain models.DomainConfig) (*models.DomainConfig, error) {
+	domain, err := normalizeDomainConfig(domain.Host, domain)
+	if err != nil {
+		return nil, err
+	}
+	if verification := s.verifier.CheckVerification(ctx, domain.Host); !verification.Verified {
+		return nil, fmt.Errorf("%w: publish the TXT record %q at %s", apperrors.ErrDomainUnverified, verification.Record, domain.Host)
+	}
+
+	created, err := s.repo.CreateDomain(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	s.configs.Invalidate(created.Host)
+
+	log.Ctx(ctx).Info().
+		Str("host", created.Host).
+		Strs("allowed_domains", created.AllowedDomains).
+		Msg("Domain configured")
+	return created, nil
+}
+
+func (s *domainConfigService) List(ctx context.Context) ([]models.DomainConfig, error) {
+	return s.repo.ListDomains(ctx)
+}
+
+func (s *domainConfigService) Get(ctx context.Context, host string) (*models.DomainConfig, error) {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetDomain(ctx, host)
+}
+
+// Update replaces the settings of host with those of domain.
+func (s *domainConfigService) Update(ctx context.Context, host string, domain models.DomainConfig) (*models.DomainConfig, error) {
+	domain, err := normalizeDomainConfig(host, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.UpdateDomain(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	s.configs.Invalidate(updated.Host)
+
+	log.Ctx(ctx).Info().
+		Str("host", updated.Host).
+		Strs("allowed_domains", updated.AllowedDomains).
+		Msg("Domain settings updated")
+	return updated, nil
+}
+
+// Delete removes the settings of host. Its links go back to the env settings.
+func (s *domainConfigService) Delete(ctx context.Context, host string) error {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteDomain(ctx, host); err != nil {
+		return err
+	}
+	s.configs.Invalidate(host)
+
+	log.Ctx(ctx).Info().
+		Str("host", host).
+		Msg("Domain settings removed")
+	return nil
+}
+
+// normalizeDomainConfig validates domain as the settings of host, lowercasing the host and the
+// allowed domains and dropping duplicates.
+func normalizeDomainConfig(host string, domain models.DomainConfig) (models.DomainConfig, error) {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return models.DomainConfig{}, err
+	}
+	if cleaned, err := utils.CleanHost(host); err != nil || cleaned != host {
+		return models.DomainConfig{}, apperrors.ErrHostInvalid
+	}
+
+	allowed := []string{}
+	for _, d := range domain.AllowedDomains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == "" || slices.Contains(allowed, d) {
+			continue
+		}
+		if !destinationDomainPattern.MatchString(d) {
+			return models.DomainConfig{}, fmt.Errorf("%w: %q", apperrors.ErrInvalidAllowedDomain, d)
+		}
+		allowed = append(allowed, d)
+	}
+	if len(allowed) == 0 {
+		return models.DomainConfig{}, apperrors.ErrMissingAllowedDomains
+	}
+
+	apn := strings.TrimSpace(domain.DefaultAndroidPackageName)
+	if apn != "" && !packageNamePattern.MatchString(apn) {
+		return models.DomainConfig{}, fmt.Errorf("defaultAndroidPackageName: %w", apperrors.ErrInvalidPackageName)
+	}
+	isi := strings.TrimSpace(domain.DefaultIosStoreId)
+	if isi != "" && !utils.IsNumericString(isi) {
+		return models.DomainConfig{}, fmt.Errorf("defaultIosStoreId: %w", apperrors.ErrInvalidAppStoreID)
+	}
+
+	return models.DomainConfig{
+		Host:                      host,
+		AllowedDomains:            allowed,
+		DefaultAndroidPackageName: apn,
+		DefaultIosStoreId:         isi,
+	}, nil
+}
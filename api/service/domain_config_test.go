@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDomainRepository keeps domains in memory and counts lookups, to check the cache.
+type fakeDomainRepository struct {
+	repository.DomainRepository
+	domains map[string]models.DomainConfig
+	gets    int
+}
+
+func (f *fakeDomainRepository) CreateDomain(_ context.Context, domain models.DomainConfig) (*models.DomainConfig, error) {
+	if _, ok := f.domains[domain.Host]; ok {
+		return nil, apperrors.ErrDomainExists
+	}
+	f.domains[domain.Host] = domain
+	return &domain, nil
+}
+
+func (f *fakeDomainRepository) GetDomain(_ context.Context, host string) (*models.DomainConfig, error) {
+	f.gets++
+	domain, ok := f.domains[host]
+	if !ok {
+		return nil, apperrors.ErrDomainNotFound
+	}
+	return &domain, nil
+}
+
+func (f *fakeDomainRepository) UpdateDomain(_ context.Context, domain models.DomainConfig) (*models.DomainConfig, error) {
+	if _, ok := f.domains[domain.Host]; !ok {
+		return nil, apperrors.ErrDomainNotFound
+	}
+	f.domains[domain.Host] = domain
+	return &domain, nil
+}
+
+// verifiedDomains reports the domains in it as verified.
+type verifiedDomains map[string]bool
+
+func (v verifiedDomains) CheckVerification(_ context.Context, domain string) models.DomainVerification {
+	return models.DomainVerification{Domain: domain, Record: "durable-links-verification=abc", Verified: v[domain]}
+}
+
+func TestDomainConfigs(t *testing.T) {
+	repo := &fakeDomainRepository{domains: map[string]models.DomainConfig{}}
+	configs := NewDomainConfigs(repo, time.Hour)
+	svc := NewDomainConfigService(repo, configs, verifiedDomains{"acme.link": true})
+	ctx := context.Background()
+
+	// Hosts without a row are cached too.
+	for range 2 {
+		domain, err := configs.Get(ctx, "acme.link")
+		assert.NoError(t, err)
+		assert.Nil(t, domain)
+	}
+	assert.Equal(t, 1, repo.gets)
+
+	_, err := svc.Create(ctx, models.DomainConfig{Host: "Acme.Link", AllowedDomains: []string{"Example.com", "example.com", " "}})
+	assert.NoError(t, err)
+	domain, err := configs.Get(ctx, "ACME.link")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"example.com"}, domain.AllowedDomains)
+
+	_, err = svc.Update(ctx, "acme.link", models.DomainConfig{AllowedDomains: []string{"shop.example.com"}, DefaultIosStoreId: "123"})
+	assert.NoError(t, err)
+	domain, err = configs.Get(ctx, "acme.link")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"shop.example.com"}, domain.AllowedDomains)
+	assert.Equal(t, "123", domain.DefaultIosStoreId)
+	assert.Equal(t, 3, repo.gets)
+
+	_, err = svc.Create(ctx, models.DomainConfig{Host: "acme.link", AllowedDomains: []string{"example.com"}})
+	assert.ErrorIs(t, err, apperrors.ErrDomainExists)
+
+	_, err = svc.Create(ctx, models.DomainConfig{Host: "other.link", AllowedDomains: []string{"example.com"}})
+	assert.ErrorIs(t, err, apperrors.ErrDomainUnverified)
+	assert.ErrorContains(t, err, "durable-links-verification=abc", "the error tells the record to publish")
+	assert.NotContains(t, repo.domains, "other.link")
+}
+
+func TestNormalizeDomainConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		domain  models.DomainConfig
+		wantErr error
+	}{
+		{name: "valid", host: "acme.link", domain: models.DomainConfig{AllowedDomains: []string{"example.com"}, DefaultAndroidPackageName: "com.acme.app", DefaultIosStoreId: "123"}},
+		{name: "missing host", host: " ", domain: models.DomainConfig{AllowedDomains: []string{"example.com"}}, wantErr: apperrors.ErrMissingHost},
+		{name: "host with path", host: "acme.link/x", domain: models.DomainConfig{AllowedDomains: []string{"example.com"}}, wantErr: apperrors.ErrHostInvalid},
+		{name: "no allowed domains", host: "acme.link", wantErr: apperrors.ErrMissingAllowedDomains},
+		{name: "allowed URL", host: "acme.link", domain: models.DomainConfig{AllowedDomains: []string{"https://example.com"}}, wantErr: apperrors.ErrInvalidAllowedDomain},
+		{name: "bad package", host: "acme.link", domain: models.DomainConfig{AllowedDomains: []string{"example.com"}, DefaultAndroidPackageName: "acme"}, wantErr: apperrors.ErrInvalidPackageName},
+		{name: "bad store id", host: "acme.link", domain: models.DomainConfig{AllowedDomains: []string{"example.com"}, DefaultIosStoreId: "id123"}, wantErr: apperrors.ErrInvalidAppStoreID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := normalizeDomainConfig(tt.host, tt.domain)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestLinkSettings(t *testing.T) {
+	repo := &fakeDomainRepository{domains: map[string]models.DomainConfig{
+		"acme.link": {Host: "acme.link", AllowedDomains: []string{"acme.com"}, DefaultAndroidPackageName: "com.acme.app"},
+	}}
+	apn := "com.default.app"
+	s := newTestLinkService(t, &fakeLinkRepository{}, &config.Config{App: &config.AppConfig{
+		URLScheme:                 "https",
+		AllowedDomains:            []string{"example.com"},
+		DefaultAndroidPackageName: &apn,
+		ShortPathLength:           6,
+		UnguessablePathLength:     10,
+	}}).WithDomainConfigs(NewDomainConfigs(repo, time.Hour))
+	ctx := context.Background()
+
+	req, err := s.ParseLongDurableLink(ctx, "https://acme.link/?link=https://acme.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "com.acme.app", req.DurableLinkInfo.AndroidParameters.AndroidPackageName)
+	req, err = s.ParseLongDurableLink(ctx, "https://other.link/?link=https://example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "com.default.app", req.DurableLinkInfo.AndroidParameters.AndroidPackageName)
+
+	create := func(host, link string) error {
+		_, err := s.CreateDurableLink(ctx, models.CreateDurableLinkRequest{
+			DurableLinkInfo: models.DurableLinkInfo{Host: host, Link: link},
+		})
+		return err
+	}
+	assert.NoError(t, create("acme.link", "https://acme.com/a"))
+	assert.ErrorIs(t, create("acme.link", "https://example.com/a"), apperrors.ErrDomainLinkNotAllowed)
+	assert.NoError(t, create("other.link", "https://example.com/a"))
+	assert.ErrorIs(t, create("other.link", "https://acme.com/a"), apperrors.ErrDomainLinkNotAllowed)
+}
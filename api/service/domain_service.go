@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+const verificationRecordPrefix = "durable-links-verification="
+
+type DomainService interface {
+	VerificationRecord(domain string) string
+	VerifyDomain(ctx context.Context, domain string) (models.DomainVerification, error)
+	VerifyAllDomains(ctx context.Context) []models.DomainVerification
+	LinkPolicy(ctx context.Context, host string) (*models.LinkPolicy, error)
+	AppSiteAssociation(host string) (*models.AppSiteAssociation, error)
+}
+
+// TXTResolver looks up DNS TXT records. *net.Resolver satisfies it.
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+type domainService struct {
+	cfg      *config.Config
+	resolver TXTResolver
+	configs  *DomainConfigs
+}
+
+// NewDomainService fails without DOMAIN_VERIFICATION_SECRET: verification records are HMACs
+// keyed with it, so without one anybody could compute the record of any domain.
+func NewDomainService(cfg *config.Config, resolver TXTResolver) (*domainService, error) {
+	if cfg.App.DomainVerificationSecret == "" {
+		return nil, errors.New("DOMAIN_VERIFICATION_SECRET must be set")
+	}
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &domainService{
+		cfg:      cfg,
+		resolver: resolver,
+	}, nil
+}
+
+// WithDomainConfigs makes link policies show the allow list stored for their host in the domains
+// table, and serves them for hosts with a row there even if they aren't in DOMAINS.
+func (s *domainService) WithDomainConfigs(configs *DomainConfigs) *domainService {
+	s.configs = configs
+	return s
+}
+
+// VerificationRecord returns the TXT record value that proves control of domain. The token is an
+// HMAC of the domain so it can be recomputed at any time without storing it.
+func (s *domainService) VerificationRecord(domain string) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.App.DomainVerificationSecret))
+	mac.Write([]byte(strings.ToLower(domain)))
+	return verificationRecordPrefix + hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// VerifyDomain checks whether the domain, configured in DOMAINS or registered in the domains
+// table, publishes its verification TXT record. DNS failures are reported on the result rather
+// than as an error; only unknown domains are rejected.
+func (s *domainService) VerifyDomain(ctx context.Context, domain string) (models.DomainVerification, error) {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	registered := false
+	if s.configs != nil {
+		config, err := s.configs.Get(ctx, domain)
+		if err != nil {
+			return models.DomainVerification{}, err
+		}
+		registered = config != nil
+	}
+	if !registered && (len(s.cfg.App.Domains) == 0 || !s.isManaged(domain)) {
+		return models.DomainVerification{}, apperrors.ErrDomainNotFound
+	}
+	return s.CheckVerification(ctx, domain), nil
+}
+
+// CheckVerification checks whether domain publishes its verification TXT record, whether or not
+// it is served yet. DNS failures are reported on the result.
+func (s *domainService) CheckVerification(ctx context.Context, domain string) models.DomainVerification {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	result := models.DomainVerification{
+		Domain:    domain,
+		Record:    s.VerificationRecord(domain),
+		CheckedAt: time.Now().UTC(),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	records, err := s.resolver.LookupTXT(ctx, domain)
+	if err != nil {
+		log.Ctx(ctx).Warn().
+			Err(err).
+			Str("domain", domain).
+			Msg("TXT lookup failed")
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Verified = slices.Contains(records, result.Record)
+	return result
+}
+
+// isManaged reports whether host is one of the configured domains. With no domains configured
+// every host is served.
+func (s *domainService) isManaged(host string) bool {
+	if len(s.cfg.App.Domains) == 0 {
+		return true
+	}
+	return containsHost(s.cfg.App.Domains, host)
+}
+
+// LinkPolicy describes redirect behavior, data collection and retention for host, built from the
+// same settings the redirect path uses.
+func (s *domainService) LinkPolicy(ctx context.Context, host string) (*models.LinkPolicy, error) {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return nil, apperrors.ErrDomainNotFound
+	}
+	host = removePreviewFromHost(strings.ToLower(host))
+
+	app := s.cfg.App
+	allowed := app.AllowedDomains
+	var domain *models.DomainConfig
+	if s.configs != nil {
+		if domain, err = s.configs.Get(ctx, host); err != nil {
+			return nil, err
+		}
+	}
+	if domain != nil {
+		allowed = domain.AllowedDomains
+	} else if !s.isManaged(host) {
+		return nil, apperrors.ErrDomainNotFound
+	}
+
+	policy := &models.LinkPolicy{
+		Domain: host,
+		Redirects: models.RedirectPolicy{
+			StatusCode:          302,
+			AllowedDestinations: allowed,
+			Interstitial:        app.InterstitialGates[host],
+			PreviewHosts:        utils.PreviewHostVariants(host)[1:],
+		},
+		DataCollection: models.DataCollectionPolicy{
+			Fields:                  []string{"path", "consent", "time", "platform", "country", "referrer", "userAgent"},
+			ConsentRequired:         app.ConsentRequired,
+			StripsMarketingParams:   app.ConsentRequired,
+			PersonalDataScanOnLinks: app.PIIScanPolicy,
+		},
+		Retention: models.RetentionPolicy{
+			ClickDataDays: app.ClickRetentionDays,
+		},
+		Contact: app.PolicyContact,
+	}
+	if policy.Redirects.AllowedDestinations == nil {
+		policy.Redirects.AllowedDestinations = []string{}
+	}
+	if containsHost(app.LinkInfoDomains, host) {
+		policy.Redirects.LinkInfoPage = "/{path}+"
+	}
+	if containsHost(app.ScannerFriendlyDomains, host) {
+		policy.Redirects.ScannerStatusCode = 200
+	}
+	return policy, nil
+}
+
+func (s *domainService) VerifyAllDomains(ctx context.Context) []models.DomainVerification {
+	results := make([]models.DomainVerification, 0, len(s.cfg.App.Domains))
+	for _, domain := range s.cfg.App.Domains {
+		result, err := s.VerifyDomain(ctx, domain)
+		if err != nil {
+			continue
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// AppSiteAssociation returns the apple-app-site-association document for host from its IOS_APP_IDS
+// entry. Preview hosts have none, so their links open the preview page instead of the app.
+func (s *domainService) AppSiteAssociation(host string) (*models.AppSiteAssociation, error) {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return nil, apperrors.ErrDomainNotFound
+	}
+	appIDs := strings.Fields(s.cfg.App.IOSAppIDs[strings.ToLower(host)])
+	if len(appIDs) == 0 {
+		return nil, apperrors.ErrDomainNotFound
+	}
+
+	return &models.AppSiteAssociation{
+		AppLinks: models.AppLinks{
+			Apps: []string{},
+			Details: []models.AppLinkDetail{{
+				AppIDs: appIDs,
+				Components: []models.AppLinkComponent{
+					{Path: "/v1/*", Exclude: true, Comment: "API"},
+					{Path: "*+", Exclude: true, Comment: "Link info pages"},
+					{Path: "*"},
+				},
+			}},
+		},
+	}, nil
+}
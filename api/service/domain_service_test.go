@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTXTResolver struct {
+	records map[string][]string
+}
+
+func (f *fakeTXTResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	records, ok := f.records[name]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return records, nil
+}
+
+func TestVerifyDomain(t *testing.T) {
+	cfg := &config.Config{App: &config.AppConfig{
+		Domains:                  []string{"verified.link", "pending.link", "missing.link"},
+		DomainVerificationSecret: "secret",
+	}}
+	resolver := &fakeTXTResolver{records: map[string][]string{}}
+	service, err := NewDomainService(cfg, resolver)
+	assert.NoError(t, err)
+	resolver.records["verified.link"] = []string{"v=spf1 -all", service.VerificationRecord("verified.link")}
+	resolver.records["pending.link"] = []string{"durable-links-verification=stale"}
+
+	tests := []struct {
+		name         string
+		domain       string
+		wantVerified bool
+		wantErr      error
+		wantLookup   bool
+	}{
+		{name: "record published", domain: "verified.link", wantVerified: true},
+		{name: "case insensitive", domain: "Verified.Link", wantVerified: true},
+		{name: "wrong record", domain: "pending.link", wantVerified: false},
+		{name: "lookup fails", domain: "missing.link", wantVerified: false, wantLookup: true},
+		{name: "unknown domain", domain: "other.link", wantErr: apperrors.ErrDomainNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.VerifyDomain(context.Background(), tt.domain)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantVerified, got.Verified)
+			assert.Equal(t, tt.wantLookup, got.Error != "")
+		})
+	}
+
+	// Domains registered through POST /v1/domains are verified too.
+	repo := &fakeDomainRepository{domains: map[string]models.DomainConfig{"registered.link": {Host: "registered.link"}}}
+	service.WithDomainConfigs(NewDomainConfigs(repo, time.Hour))
+	resolver.records["registered.link"] = []string{service.VerificationRecord("registered.link")}
+	got, err := service.VerifyDomain(context.Background(), "registered.link")
+	assert.NoError(t, err)
+	assert.True(t, got.Verified)
+}
+
+func TestVerificationRecord(t *testing.T) {
+	a, _ := NewDomainService(&config.Config{App: &config.AppConfig{DomainVerificationSecret: "a"}}, nil)
+	b, _ := NewDomainService(&config.Config{App: &config.AppConfig{DomainVerificationSecret: "b"}}, nil)
+
+	assert.Equal(t, a.VerificationRecord("acme.link"), a.VerificationRecord("ACME.link"))
+	assert.NotEqual(t, a.VerificationRecord("acme.link"), b.VerificationRecord("acme.link"))
+	assert.Contains(t, a.VerificationRecord("acme.link"), "durable-links-verification=")
+
+	_, err := NewDomainService(&config.Config{App: &config.AppConfig{}}, nil)
+	assert.Error(t, err, "records can't be predictable")
+}
+
+func TestLinkPolicy(t *testing.T) {
+	cfg := &config.Config{App: &config.AppConfig{
+		Domains:                  []string{"acme.link"},
+		AllowedDomains:           []string{"example.com"},
+		InterstitialGates:        map[string]string{"acme.link": "terms"},
+		LinkInfoDomains:          []string{"acme.link"},
+		ConsentRequired:          true,
+		ClickRetentionDays:       30,
+		DomainVerificationSecret: "secret",
+	}}
+	service, _ := NewDomainService(cfg, nil)
+
+	policy, err := service.LinkPolicy(context.Background(), "preview.acme.link:443")
+	assert.NoError(t, err)
+	assert.Equal(t, "acme.link", policy.Domain)
+	assert.Equal(t, "terms", policy.Redirects.Interstitial)
+	assert.Equal(t, "/{path}+", policy.Redirects.LinkInfoPage)
+	assert.Equal(t, []string{"preview.acme.link"}, policy.Redirects.PreviewHosts)
+	assert.True(t, policy.DataCollection.StripsMarketingParams)
+	assert.Equal(t, 30, policy.Retention.ClickDataDays)
+
+	_, err = service.LinkPolicy(context.Background(), "other.link")
+	assert.ErrorIs(t, err, apperrors.ErrDomainNotFound)
+}
+
+func TestAppSiteAssociation(t *testing.T) {
+	service, _ := NewDomainService(&config.Config{App: &config.AppConfig{
+		IOSAppIDs:                map[string]string{"acme.link": "ABCDE12345.com.acme.app ABCDE12345.com.acme.beta"},
+		DomainVerificationSecret: "secret",
+	}}, nil)
+
+	aasa, err := service.AppSiteAssociation("Acme.link:443")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, aasa.AppLinks.Apps)
+	if assert.Len(t, aasa.AppLinks.Details, 1) {
+		assert.Equal(t, []string{"ABCDE12345.com.acme.app", "ABCDE12345.com.acme.beta"}, aasa.AppLinks.Details[0].AppIDs)
+	}
+
+	_, err = service.AppSiteAssociation("preview.acme.link")
+	assert.ErrorIs(t, err, apperrors.ErrDomainNotFound)
+	_, err = service.AppSiteAssociation("other.link")
+	assert.ErrorIs(t, err, apperrors.ErrDomainNotFound)
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/authz"
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Columns of a Firebase Dynamic Links CSV export, by their normalizeColumn name. The console
+// export and the shutdown tooling name them differently.
+var (
+	shortLinkColumns = []string{"shortlink", "shortdynamiclink", "dynamiclink", "shorturl"}
+	longLinkColumns  = []string{"longlink", "longdynamiclink", "longurl"}
+	deepLinkColumns  = []string{"link", "deeplink"}
+)
+
+// firebaseLinkParams are the long link params a row may hold as columns of their own, for exports
+// without a long link column.
+var firebaseLinkParams = []string{
+	"apn", "afl", "amv", "ifl", "ipfl", "isi", "ofl", "st", "sd", "si",
+	"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content", "at", "ct", "mt", "pt",
+}
+
+// ImportResult is the outcome of one row of an import: the created link, or why it wasn't
+// created.
+type ImportResult struct {
+	// Row is the row's line in the file, the header being line 1.
+	Row        int
+	SourceLink string
+	Link       *models.ShortLinkResponse
+	Err        error
+}
+
+type FirebaseImportService interface {
+	ImportFirebase(ctx context.Context, r io.Reader, host string, maxRows int) ([]ImportResult, error)
+}
+
+type firebaseImportService struct {
+	linkService LinkService
+	cfg         *config.Config
+}
+
+func NewFirebaseImportService(linkService LinkService, cfg *config.Config) *firebaseImportService {
+	return &firebaseImportService{
+		linkService: linkService,
+		cfg:         cfg,
+	}
+}
+
+// importColumns are the positions of the columns of an export, -1 for those it doesn't have.
+type importColumns struct {
+	shortLink int
+	longLink  int
+	deepLink  int
+	params    map[string]int
+}
+
+// ImportFirebase creates a link for every row of a Firebase Dynamic Links CSV export, under the
+// path of the row's short link, and reports the outcome of every row in file order. The links are
+// created on host, or on the host of their short link when host is empty. A row's link comes from
+// its long link, or from its link and param columns when it has none. With maxRows set, larger
+// files fail with ErrImportTooLarge before anything is created.
+func (s *firebaseImportService) ImportFirebase(ctx context.Context, r io.Reader, host string, maxRows int) ([]ImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: the file is empty", apperrors.ErrInvalidImportFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrInvalidImportFile, err)
+	}
+	columns, err := parseImportHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var records [][]string
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", apperrors.ErrInvalidImportFile, err)
+		}
+		if maxRows > 0 && len(records) == maxRows {
+			return nil, fmt.Errorf("%w: at most %d rows are allowed", apperrors.ErrImportTooLarge, maxRows)
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+
+	results := make([]ImportResult, len(records))
+	var requests []models.CreateDurableLinkRequest
+	// indexes maps the position in requests to the position in results.
+	var indexes []int
+	for i, record := range records {
+		results[i] = ImportResult{Row: lines[i], SourceLink: importField(record, columns.shortLink)}
+		req, err := s.importRequest(ctx, record, columns, host)
+		if err == nil && !authz.MayAccess(ctx, req.DurableLinkInfo.Host) {
+			err = apperrors.ErrProjectNotAllowed
+		}
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		requests = append(requests, req)
+		indexes = append(indexes, i)
+	}
+
+	// Created in batches as large as CreateDurableLinks takes.
+	batchSize := max(s.cfg.App.BatchMaxLinks, 1)
+	for start := 0; start < len(requests); start += batchSize {
+		end := min(start+batchSize, len(requests))
+		created, err := s.linkService.CreateDurableLinks(ctx, requests[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for j, result := range created {
+			results[indexes[start+j]].Link = result.Link
+			results[indexes[start+j]].Err = result.Err
+		}
+	}
+
+	log.Ctx(ctx).Info().
+		Int("rows", len(records)).
+		Int("requested", len(requests)).
+		Msg("Firebase export imported")
+	return results, nil
+}
+
+func parseImportHeader(header []string) (importColumns, error) {
+	positions := map[string]int{}
+	for i, name := range header {
+		name = normalizeColumn(name)
+		if _, ok := positions[name]; !ok {
+			positions[name] = i
+		}
+	}
+	find := func(names ...string) int {
+		for _, name := range names {
+			if i, ok := positions[name]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+
+	columns := importColumns{
+		shortLink: find(shortLinkColumns...),
+		longLink:  find(longLinkColumns...),
+		deepLink:  find(deepLinkColumns...),
+		params:    map[string]int{},
+	}
+	if columns.shortLink < 0 {
+		return columns, fmt.Errorf("%w: no short link column", apperrors.ErrInvalidImportFile)
+	}
+	if columns.longLink < 0 && columns.deepLink < 0 {
+		return columns, fmt.Errorf("%w: no long link or link column", apperrors.ErrInvalidImportFile)
+	}
+	for _, param := range firebaseLinkParams {
+		if i := find(strings.ReplaceAll(param, "_", "")); i >= 0 {
+			columns.params[param] = i
+		}
+	}
+	return columns, nil
+}
+
+// normalizeColumn returns the normalized name of a CSV header: lowercase, without spaces,
+// underscores and dashes, nor the byte order mark spreadsheets start files with.
+func normalizeColumn(name string) string {
+	name = strings.TrimPrefix(name, "\ufeff")
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// importRequest builds the create request of a row, keeping the path of its short link.
+func (s *firebaseImportService) importRequest(ctx context.Context, record []string, columns importColumns, host string) (models.CreateDurableLinkRequest, error) {
+	shortLink := importField(record, columns.shortLink)
+	source, err := url.Parse(shortLink)
+	if shortLink == "" || err != nil || source.Host == "" {
+		return models.CreateDurableLinkRequest{}, fmt.Errorf("%w: short link %q is not a URL", apperrors.ErrInvalidImportRow, shortLink)
+	}
+	path := strings.Trim(source.Path, "/")
+	if path == "" || strings.Contains(path, "/") {
+		return models.CreateDurableLinkRequest{}, fmt.Errorf("%w: short link %q must have a single segment path", apperrors.ErrInvalidImportRow, shortLink)
+	}
+	if host == "" {
+		host = source.Host
+	}
+
+	var longLink *url.URL
+	if raw := importField(record, columns.longLink); raw != "" {
+		longLink, err = url.Parse(raw)
+		if err != nil {
+			return models.CreateDurableLinkRequest{}, fmt.Errorf("%w: long link %q is not a URL", apperrors.ErrInvalidImportRow, raw)
+		}
+	} else {
+		params := url.Values{}
+		if link := importField(record, columns.deepLink); link != "" {
+			params.Set("link", link)
+		}
+		for param, i := range columns.params {
+			if value := importField(record, i); value != "" {
+				params.Set(param, value)
+			}
+		}
+		longLink = &url.URL{Scheme: "https", Path: "/", RawQuery: params.Encode()}
+	}
+	longLink.Host = host
+
+	req, err := s.linkService.PrepareDurableLinkRequest(ctx, map[string]any{"longDurableLink": longLink.String()})
+	if err != nil {
+		return models.CreateDurableLinkRequest{}, err
+	}
+	req.Suffix.Option = "CUSTOM"
+	req.Suffix.CustomPath = path
+	return req, nil
+}
+
+// importField returns the trimmed value of column i of record, or "" when it has no such column.
+func importField(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/authz"
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportFirebase(t *testing.T) {
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{Host: "acme.link", Path: "taken", QueryParams: "link=https%3A%2F%2Fexample.com%2Ftaken"},
+	}}
+	cfg := &config.Config{App: &config.AppConfig{
+		URLScheme:      "https",
+		AllowedDomains: []string{"example.com"},
+		BatchMaxLinks:  2,
+	}}
+	s := NewFirebaseImportService(newTestLinkService(t, repo, cfg), cfg)
+
+	export := "\ufeffShort Dynamic Link,Long Dynamic Link,Link,Created\n" +
+		"https://acme.page.link/abc,https://acme.page.link/?link=https://example.com/a&apn=com.acme&st=Sale,,2023-01-01\n" +
+		"https://acme.page.link/def,,https://example.com/b,2023-01-02\n" +
+		"https://acme.page.link/taken,,https://example.com/taken,2023-01-03\n" +
+		"https://acme.page.link/,,https://example.com/c,2023-01-04\n" +
+		"https://acme.page.link/ghi,,,2023-01-05\n" +
+		"https://acme.page.link/jkl,,https://evil.com,2023-01-06\n"
+	results, err := s.ImportFirebase(context.Background(), strings.NewReader(export), "acme.link", 0)
+	assert.NoError(t, err)
+	if !assert.Len(t, results, 6) {
+		return
+	}
+
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, 2, results[0].Row)
+	assert.Equal(t, "https://acme.page.link/abc", results[0].SourceLink)
+	assert.Equal(t, "https://acme.link/abc", results[0].Link.ShortLink)
+	assert.NoError(t, results[1].Err)
+	assert.Equal(t, "https://acme.link/def", results[1].Link.ShortLink)
+	assert.ErrorIs(t, results[2].Err, apperrors.ErrPathTaken)
+	assert.ErrorIs(t, results[3].Err, apperrors.ErrInvalidImportRow)
+	assert.ErrorIs(t, results[4].Err, apperrors.ErrMissingLink)
+	assert.ErrorIs(t, results[5].Err, apperrors.ErrDomainLinkNotAllowed)
+	assert.Equal(t, 7, results[5].Row)
+
+	stored := map[string]url.Values{}
+	for _, link := range repo.links {
+		stored[link.Path], _ = url.ParseQuery(link.QueryParams)
+	}
+	assert.Len(t, stored, 3)
+	assert.Equal(t, "https://example.com/a", stored["abc"].Get("link"))
+	assert.Equal(t, "com.acme", stored["abc"].Get("apn"))
+	assert.Equal(t, "Sale", stored["abc"].Get("st"))
+	assert.Equal(t, "https://example.com/b", stored["def"].Get("link"))
+}
+
+func TestImportFirebase_ProjectScope(t *testing.T) {
+	cfg := &config.Config{App: &config.AppConfig{URLScheme: "https", AllowedDomains: []string{"example.com"}, BatchMaxLinks: 10}}
+	repo := &fakeLinkRepository{}
+	s := NewFirebaseImportService(newTestLinkService(t, repo, cfg), cfg)
+	ctx := authz.WithProjects(context.Background(), []string{"acme.page.link"})
+
+	export := "Short Dynamic Link,Long Dynamic Link,Link,Created\n" +
+		"https://acme.page.link/abc,,https://example.com/a,2023-01-01\n" +
+		"https://victim.page.link/def,,https://example.com/b,2023-01-02\n"
+	results, err := s.ImportFirebase(ctx, strings.NewReader(export), "", 0)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 2) {
+		assert.NoError(t, results[0].Err)
+		assert.ErrorIs(t, results[1].Err, apperrors.ErrProjectNotAllowed)
+	}
+	assert.Len(t, repo.links, 1)
+}
+
+func TestImportFirebase_InvalidFile(t *testing.T) {
+	cfg := &config.Config{App: &config.AppConfig{URLScheme: "https", BatchMaxLinks: 10}}
+	s := NewFirebaseImportService(newTestLinkService(t, &fakeLinkRepository{}, cfg), cfg)
+	ctx := context.Background()
+
+	_, err := s.ImportFirebase(ctx, strings.NewReader(""), "", 0)
+	assert.ErrorIs(t, err, apperrors.ErrInvalidImportFile)
+	_, err = s.ImportFirebase(ctx, strings.NewReader("link\nhttps://example.com\n"), "", 0)
+	assert.ErrorIs(t, err, apperrors.ErrInvalidImportFile, "no short link column")
+	_, err = s.ImportFirebase(ctx, strings.NewReader("short_link,link\na,b\nc,d\n"), "", 1)
+	assert.ErrorIs(t, err, apperrors.ErrImportTooLarge)
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+	"durable-links-generator/config"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+// FixtureService manages a namespace of links derived from FIXTURE_SEED for preview
+// environments. The same seed always yields the same short links, so frontends can hardcode
+// them, and they live on their own host so they never mix with real links.
+type FixtureService interface {
+	Fixtures() ([]models.Fixture, error)
+	Seed(ctx context.Context) (int, error)
+}
+
+type fixtureService struct {
+	repo repository.LinkRepository
+	cfg  *config.Config
+}
+
+func NewFixtureService(repo repository.LinkRepository, cfg *config.Config) *fixtureService {
+	return &fixtureService{
+		repo: repo,
+		cfg:  cfg,
+	}
+}
+
+type fixture struct {
+	models.Fixture
+	host string
+	path string
+}
+
+func (s *fixtureService) fixtures() ([]fixture, error) {
+	seed := s.cfg.App.FixtureSeed
+	if seed == "" {
+		return nil, fmt.Errorf("%w: FIXTURE_SEED is not set", apperrors.ErrInvalidFormat)
+	}
+	host, err := utils.CleanHost(s.cfg.App.FixtureHost)
+	if err != nil {
+		return nil, fmt.Errorf("%w: FIXTURE_HOST: %v", apperrors.ErrMissingHost, err)
+	}
+
+	fixtures := make([]fixture, 0, s.cfg.App.FixtureCount)
+	for i := 1; i <= s.cfg.App.FixtureCount; i++ {
+		name := fmt.Sprintf("fixture-%02d", i)
+		path := utils.DeterministicAlphanumericString(seed, host+"/"+name, s.cfg.App.ShortPathLength)
+		fixtures = append(fixtures, fixture{
+			Fixture: models.Fixture{
+				Name:        name,
+				ShortLink:   fmt.Sprintf("%s://%s/%s", s.cfg.App.URLScheme, host, path),
+				Destination: strings.TrimRight(s.cfg.App.FixtureDestination, "/") + "/" + name,
+			},
+			host: host,
+			path: path,
+		})
+	}
+	return fixtures, nil
+}
+
+func (s *fixtureService) Fixtures() ([]models.Fixture, error) {
+	fixtures, err := s.fixtures()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.Fixture, len(fixtures))
+	for i, f := range fixtures {
+		result[i] = f.Fixture
+	}
+	return result, nil
+}
+
+// Seed stores every fixture that doesn't exist yet and returns how many were created. Existing
+// paths are left alone, so seeding is safe to repeat on every start.
+func (s *fixtureService) Seed(ctx context.Context) (int, error) {
+	fixtures, err := s.fixtures()
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, f := range fixtures {
+		exists, err := s.repo.PathExists(ctx, f.host, f.path)
+		if err != nil {
+			return created, err
+		}
+		if exists {
+			continue
+		}
+
+		rawQS := url.Values{"link": {f.Destination}}.Encode()
+		if err := s.repo.CreateShortLink(ctx, f.host, f.path, rawQS, false); err != nil {
+			return created, fmt.Errorf("failed to store fixture %s: %w", f.Name, err)
+		}
+		created++
+	}
+
+	log.Ctx(ctx).Info().
+		Str("host", s.cfg.App.FixtureHost).
+		Int("created", created).
+		Int("total", len(fixtures)).
+		Msg("Link fixtures seeded")
+
+	return created, nil
+}
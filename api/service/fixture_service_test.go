@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixtures(t *testing.T) {
+	newCfg := func(seed string) *config.Config {
+		return &config.Config{App: &config.AppConfig{
+			URLScheme:          "https",
+			ShortPathLength:    6,
+			FixtureSeed:        seed,
+			FixtureHost:        "pr-42.page.link",
+			FixtureCount:       3,
+			FixtureDestination: "https://app.example.com/fixtures/",
+		}}
+	}
+
+	first, err := NewFixtureService(nil, newCfg("seed-a")).Fixtures()
+	assert.NoError(t, err)
+	assert.Len(t, first, 3)
+	assert.Equal(t, "fixture-01", first[0].Name)
+	assert.Equal(t, "https://app.example.com/fixtures/fixture-01", first[0].Destination)
+	assert.Regexp(t, `^https://pr-42\.page\.link/[A-Za-z0-9]{6}$`, first[0].ShortLink)
+
+	again, err := NewFixtureService(nil, newCfg("seed-a")).Fixtures()
+	assert.NoError(t, err)
+	assert.Equal(t, first, again)
+
+	other, err := NewFixtureService(nil, newCfg("seed-b")).Fixtures()
+	assert.NoError(t, err)
+	assert.NotEqual(t, first[0].ShortLink, other[0].ShortLink)
+
+	_, err = NewFixtureService(nil, newCfg("")).Fixtures()
+	assert.Error(t, err)
+}
+
+func TestSeedFixtures(t *testing.T) {
+	repo := &fakeLinkRepository{}
+	s := NewFixtureService(repo, &config.Config{App: &config.AppConfig{
+		URLScheme:          "https",
+		ShortPathLength:    6,
+		FixtureSeed:        "seed",
+		FixtureHost:        "pr-42.page.link",
+		FixtureCount:       2,
+		FixtureDestination: "https://app.example.com",
+	}})
+
+	created, err := s.Seed(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, created)
+	assert.Equal(t, "link=https%3A%2F%2Fapp.example.com%2Ffixture-01", repo.links[0].QueryParams)
+
+	created, err = s.Seed(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, created, "seeding again keeps the existing fixtures")
+	assert.Len(t, repo.links, 2)
+}
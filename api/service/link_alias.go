@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"regexp"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Paths chosen by callers rather than generated.
+var customPathPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// CreateAlias adds aliasPath as another path for the link at path. Aliases of aliases point at
+// the original link so all of them resolve, and count, as one.
+func (s *linkService) CreateAlias(ctx context.Context, path string, req models.CreateAliasRequest) (*models.AliasesResponse, error) {
+	host, err := utils.CleanHost(req.Host)
+	if err != nil {
+		return nil, apperrors.ErrMissingHost
+	}
+	canonical, err := s.repo.GetCanonicalPath(ctx, host, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkCustomPath(ctx, host, req.Alias); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateAlias(ctx, host, req.Alias, canonical); err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Info().
+		Str("host", host).
+		Str("path", canonical).
+		Str("alias", req.Alias).
+		Msg("Alias created")
+
+	return s.listAliases(ctx, host, canonical)
+}
+
+func (s *linkService) ListAliases(ctx context.Context, host, path string) (*models.AliasesResponse, error) {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return nil, apperrors.ErrMissingHost
+	}
+
+	canonical, err := s.repo.GetCanonicalPath(ctx, host, path)
+	if err != nil {
+		return nil, err
+	}
+	return s.listAliases(ctx, host, canonical)
+}
+
+func (s *linkService) listAliases(ctx context.Context, host, canonical string) (*models.AliasesResponse, error) {
+	aliases, err := s.repo.ListAliases(ctx, host, canonical)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AliasesResponse{Path: canonical, Aliases: aliases}, nil
+}
+
+func (s *linkService) DeleteAlias(ctx context.Context, host, aliasPath string) error {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return apperrors.ErrMissingHost
+	}
+	return s.repo.DeleteAlias(ctx, host, aliasPath)
+}
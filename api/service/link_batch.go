@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/webhooks"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Paths tried for a link of a batch before giving up, when the generated ones are already used by
+// earlier links of the same batch.
+const maxBatchPathAttempts = 10
+
+// BatchResult is the outcome of one link of a batch: the created link, or why it wasn't created.
+type BatchResult struct {
+	Link *models.ShortLinkResponse
+	Err  error
+}
+
+// batchLink is a link of a batch that passed validation.
+type batchLink struct {
+	index int
+	plan  *linkPlan
+	path  string
+	// existing is set for SHORT links reusing a link stored before the batch.
+	existing bool
+}
+
+// CreateDurableLinks creates links like CreateDurableLink does, reporting the outcome of every
+// request separately, in request order. The new links are stored together in one transaction.
+// When a path turns out to be taken meanwhile they are stored one by one instead, so only the
+// colliding links get new paths or fail; other storage errors fail all of them, while validation
+// errors only fail their own link.
+func (s *linkService) CreateDurableLinks(ctx context.Context, requests []models.CreateDurableLinkRequest) ([]BatchResult, error) {
+	if len(requests) == 0 {
+		return nil, apperrors.ErrEmptyBatch
+	}
+	if len(requests) > s.cfg.App.BatchMaxLinks {
+		return nil, fmt.Errorf("%w: %d, at most %d are allowed", apperrors.ErrBatchTooLarge, len(requests), s.cfg.App.BatchMaxLinks)
+	}
+
+	results := make([]BatchResult, len(requests))
+	var links []batchLink
+	var rows []models.StoredLink
+	// rowLinks holds the links stored as each row, by their index in links; SHORT links of the
+	// batch with the same params share a row.
+	var rowLinks [][]int
+	// Paths given out in this batch, by host and path, with the row they are stored as, and the
+	// paths of its SHORT links, by host and params.
+	taken := map[string]bool{}
+	rowOf := map[string]int{}
+	shortPaths := map[string]string{}
+
+	for i, params := range requests {
+		plan, err := s.planBatchLink(ctx, params)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		link := batchLink{index: i, plan: plan}
+		link.path, link.existing, err = s.batchPath(ctx, plan, taken, shortPaths)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		links = append(links, link)
+
+		if plan.shortPath && plan.reusable() {
+			shortPaths[plan.host+" "+plan.queryParams.Encode()] = link.path
+		}
+		key := plan.host + "/" + link.path
+		if link.existing {
+			continue
+		}
+		if row, ok := rowOf[key]; ok {
+			rowLinks[row] = append(rowLinks[row], len(links)-1)
+			continue
+		}
+		taken[key] = true
+		rowOf[key] = len(rows)
+		rowLinks = append(rowLinks, []int{len(links) - 1})
+		rows = append(rows, models.StoredLink{
+			Host:        plan.host,
+			Path:        link.path,
+			QueryParams: plan.queryParams.Encode(),
+			Unguessable: !plan.shortPath && !plan.custom,
+			Metadata:    plan.metadata,
+		})
+	}
+
+	if len(rows) > 0 {
+		err := s.repo.CreateShortLinks(ctx, rows)
+		if errors.Is(err, apperrors.ErrPathTaken) {
+			log.Ctx(ctx).Warn().Int("links", len(rows)).Msg("Path of a link batch is taken, storing its links one by one")
+			for i := range rows {
+				err := s.storeBatchRow(ctx, &rows[i], links[rowLinks[i][0]].plan, taken)
+				for _, l := range rowLinks[i] {
+					if err != nil {
+						results[links[l].index].Err = err
+					} else {
+						links[l].path = rows[i].Path
+					}
+				}
+			}
+		} else if err != nil {
+			log.Ctx(ctx).Error().Err(err).Int("links", len(rows)).Msg("Failed to store link batch")
+			for _, link := range links {
+				if !link.existing {
+					results[link.index].Err = fmt.Errorf("failed to store link: %w", err)
+				}
+			}
+		}
+	}
+
+	published := map[string]bool{}
+	for _, link := range links {
+		if results[link.index].Err != nil {
+			continue
+		}
+		if key := link.plan.host + "/" + link.path; !link.existing && !published[key] {
+			published[key] = true
+			s.publishLink(ctx, webhooks.EventLinkCreated, link.plan.host, link.path, link.plan.queryParams.Encode())
+		}
+		if len(link.plan.geoDestinations) > 0 {
+			if err := s.repo.SetGeoDestinations(ctx, link.plan.host, link.path, link.plan.geoDestinations); err != nil {
+				results[link.index].Err = fmt.Errorf("failed to store geo destinations: %w", err)
+				continue
+			}
+		}
+		if len(link.plan.deviceRules) > 0 {
+			if err := s.repo.SetDeviceRules(ctx, link.plan.host, link.path, link.plan.deviceRules); err != nil {
+				results[link.index].Err = fmt.Errorf("failed to store device rules: %w", err)
+				continue
+			}
+		}
+		if len(link.plan.variants) > 0 {
+			if err := s.repo.SetLinkVariants(ctx, link.plan.host, link.path, link.plan.variants); err != nil {
+				results[link.index].Err = fmt.Errorf("failed to store variants: %w", err)
+				continue
+			}
+		}
+		response := &models.ShortLinkResponse{
+			ShortLink: fmt.Sprintf("%s://%s/%s", s.cfg.App.URLScheme, link.plan.host, link.path),
+			Warnings:  link.plan.warnings,
+		}
+		if link.plan.numericCode {
+			code, err := s.assignCode(ctx, link.plan.host, link.path)
+			if err != nil {
+				results[link.index].Err = err
+				continue
+			}
+			response.Code = code
+		}
+		results[link.index].Link = response
+	}
+
+	log.Ctx(ctx).Info().
+		Int("requested", len(requests)).
+		Int("stored", len(rows)).
+		Msg("Link batch created")
+	return results, nil
+}
+
+// planBatchLink validates one link of a batch. Every link counts against its host's tenant
+// limits on its own.
+func (s *linkService) planBatchLink(ctx context.Context, params models.CreateDurableLinkRequest) (*linkPlan, error) {
+	host, err := cleanLinkHost(params)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := s.acquireTenant(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return s.planDurableLink(ctx, host, params)
+}
+
+// batchPath picks the path of a link of a batch, avoiding the paths earlier links of the batch
+// got. SHORT links reuse a stored link or an earlier link of the batch with the same params,
+// unless they have metadata, geo destinations, device rules or variants of their own.
+func (s *linkService) batchPath(ctx context.Context, plan *linkPlan, taken map[string]bool, shortPaths map[string]string) (path string, existing bool, err error) {
+	if plan.custom {
+		if taken[plan.host+"/"+plan.customPath] {
+			return "", false, apperrors.ErrPathTaken
+		}
+		if err := s.checkCustomPath(ctx, plan.host, plan.customPath); err != nil {
+			return "", false, err
+		}
+		return plan.customPath, false, nil
+	}
+
+	if plan.shortPath && plan.reusable() {
+		rawQS := plan.queryParams.Encode()
+		if path, ok := shortPaths[plan.host+" "+rawQS]; ok {
+			return path, false, nil
+		}
+		path, err := s.findExistingShortLink(ctx, plan.host, rawQS)
+		if err == nil {
+			return path, true, nil
+		}
+		if err != sql.ErrNoRows {
+			return "", false, err
+		}
+	}
+
+	for attempt := range maxBatchPathAttempts {
+		path, free, err := s.freeBatchPath(ctx, plan, taken, attempt)
+		if err != nil {
+			return "", false, err
+		}
+		if free {
+			return path, false, nil
+		}
+	}
+	return "", false, fmt.Errorf("no free path on %s after %d attempts", plan.host, maxBatchPathAttempts)
+}
+
+// freeBatchPath generates the path of the given attempt for a link of a batch, and reports
+// whether it is free: not given out in the batch yet, nor used by a stored link or alias.
+func (s *linkService) freeBatchPath(ctx context.Context, plan *linkPlan, taken map[string]bool, attempt int) (string, bool, error) {
+	path, err := s.generatePath(ctx, plan.host, plan.queryParams, plan.shortPath, attempt)
+	if err != nil {
+		return "", false, err
+	}
+	if taken[plan.host+"/"+path] {
+		return path, false, nil
+	}
+	exists, err := s.repo.PathExists(ctx, plan.host, path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to store link: %w", err)
+	}
+	return path, !exists, nil
+}
+
+// storeBatchRow stores a link of a batch on its own. When its generated path is taken it moves
+// to new ones, up to PATH_COLLISION_RETRIES times as storeGeneratedPath does, while a custom
+// path fails with ErrPathTaken.
+func (s *linkService) storeBatchRow(ctx context.Context, row *models.StoredLink, plan *linkPlan, taken map[string]bool) error {
+	attempt := 0
+	for {
+		err := s.repo.CreateShortLinks(ctx, []models.StoredLink{*row})
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, apperrors.ErrPathTaken) {
+			return fmt.Errorf("failed to store link: %w", err)
+		}
+		if plan.custom {
+			return apperrors.ErrPathTaken
+		}
+
+		for free := false; !free; {
+			attempt++
+			if attempt > s.cfg.App.PathCollisionRetries {
+				log.Ctx(ctx).Error().
+					Str("host", plan.host).
+					Int("attempts", attempt).
+					Msg("Generated paths keep colliding, consider longer paths")
+				return apperrors.ErrNoFreePath
+			}
+			var path string
+			if path, free, err = s.freeBatchPath(ctx, plan, taken, attempt); err != nil {
+				return err
+			}
+			if free {
+				taken[plan.host+"/"+path] = true
+				row.Path = path
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"durable-links-generator/api/models"
+	"durable-links-generator/utils"
+)
+
+// GetLinkCard returns what the link's social card shows: the social title and description, with
+// the destination domain standing in for a missing title.
+func (s *linkService) GetLinkCard(ctx context.Context, host, path string) (*models.LinkCard, error) {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host: %w", err)
+	}
+	host = removePreviewFromHost(host)
+
+	link, err := s.repo.GetLink(ctx, host, path)
+	if err != nil {
+		return nil, err
+	}
+	params, err := url.ParseQuery(link.QueryParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stored query params: %w", err)
+	}
+	if err := s.checkResolvePolicy(ctx, host, path, params); err != nil {
+		return nil, err
+	}
+
+	card := &models.LinkCard{
+		Title:       params.Get("st"),
+		Description: params.Get("sd"),
+	}
+	if u, err := url.Parse(params.Get("link")); err == nil {
+		card.Domain = u.Hostname()
+	}
+	if card.Title == "" {
+		card.Title = card.Domain
+	}
+	return card, nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Codes tried before giving up on finding a free one. The code space should be resized through
+// NUMERIC_CODE_LENGTH long before this is hit.
+const maxCodeAttempts = 10
+
+// assignCode returns the numeric code of the link at path, assigning a random free one when it
+// has none yet.
+func (s *linkService) assignCode(ctx context.Context, host, path string) (string, error) {
+	code, err := s.repo.GetCodeByPath(ctx, host, path)
+	if err != nil || code != "" {
+		return code, err
+	}
+
+	for range maxCodeAttempts {
+		code = utils.GenerateRandomNumericString(s.cfg.App.NumericCodeLength)
+		err := s.repo.CreateCode(ctx, host, code, path)
+		if errors.Is(err, apperrors.ErrCodeTaken) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		log.Ctx(ctx).Info().
+			Str("host", host).
+			Str("path", path).
+			Str("code", code).
+			Msg("Numeric code assigned")
+		return code, nil
+	}
+	return "", fmt.Errorf("no free numeric code on %s after %d attempts", host, maxCodeAttempts)
+}
+
+// ResolveCode returns the path of the link a numeric code belongs to.
+func (s *linkService) ResolveCode(ctx context.Context, host, code string) (string, error) {
+	if code == "" || !utils.IsNumericString(code) {
+		return "", apperrors.ErrLinkNotFound
+	}
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return "", fmt.Errorf("invalid host: %w", err)
+	}
+	return s.repo.GetPathByCode(ctx, removePreviewFromHost(host), code)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/webhooks"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Paths the router serves itself, or may in future, which a caller chosen path would shadow or
+// be shadowed by. RESERVED_PATHS adds to them.
+var reservedPaths = []string{
+	"shortLinks", "exchangeShortLink", "installAttribution", "apple-app-site-association", "auditlog",
+	"v1", "v2", "c", "api", "admin", "ui", "static", "assets", "robots", "favicon", "health",
+}
+
+// checkCustomPath validates a caller chosen path: its characters, the reserved words, and that
+// no link or alias on host uses it yet.
+func (s *linkService) checkCustomPath(ctx context.Context, host, path string) error {
+	if !customPathPattern.MatchString(path) {
+		return apperrors.ErrInvalidPath
+	}
+	isReserved := func(reserved string) bool { return strings.EqualFold(reserved, path) }
+	if slices.ContainsFunc(reservedPaths, isReserved) || slices.ContainsFunc(s.cfg.App.ReservedPaths, isReserved) {
+		return apperrors.ErrReservedPath
+	}
+
+	taken, err := s.repo.PathExists(ctx, host, path)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return apperrors.ErrPathTaken
+	}
+	return nil
+}
+
+// createCustomShortLink stores the link under the caller's path. Unlike SHORT links an existing
+// link with the same params is never reused: the caller asked for this path.
+func (s *linkService) createCustomShortLink(ctx context.Context, host string, queryParams url.Values, path string) (*models.ShortLinkResponse, error) {
+	if err := s.checkCustomPath(ctx, host, path); err != nil {
+		return nil, err
+	}
+
+	rawQS := queryParams.Encode()
+	if err := s.createShortLink(ctx, host, path, rawQS, false); err != nil {
+		return nil, fmt.Errorf("failed to store link: %w", err)
+	}
+	s.publishLink(ctx, webhooks.EventLinkCreated, host, path, rawQS)
+
+	log.Ctx(ctx).Debug().
+		Str("path", path).
+		Str("query_params", rawQS).
+		Msg("New link stored under custom path")
+
+	full := fmt.Sprintf("%s://%s/%s", s.cfg.App.URLScheme, host, path)
+	return &models.ShortLinkResponse{ShortLink: full, Warnings: []models.DurableLinkCreationWarning{}}, nil
+}
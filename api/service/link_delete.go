@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/webhooks"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DeleteLink deletes the link at path, or the link path is an alias of. The link answers 410
+// until it is restored, unless hard is set: then it is purged along with its aliases and codes,
+// and its path becomes free again.
+func (s *linkService) DeleteLink(ctx context.Context, host, path string, hard bool) error {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return apperrors.ErrMissingHost
+	}
+	canonical, err := s.repo.GetCanonicalPath(ctx, host, path)
+	if err != nil {
+		return err
+	}
+
+	if hard {
+		err = s.repo.DeleteLink(ctx, host, canonical)
+	} else {
+		err = s.repo.SoftDeleteLink(ctx, host, canonical)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Ctx(ctx).Info().
+		Str("host", host).
+		Str("path", canonical).
+		Bool("hard", hard).
+		Msg("Link deleted")
+	s.publishLink(ctx, webhooks.EventLinkDeleted, host, canonical, "")
+	return nil
+}
+
+// RestoreLink undoes the soft deletion of the link at path, or the link path is an alias of.
+func (s *linkService) RestoreLink(ctx context.Context, host, path string) error {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return apperrors.ErrMissingHost
+	}
+	canonical, err := s.repo.GetCanonicalPath(ctx, host, path)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.RestoreLink(ctx, host, canonical); err != nil {
+		return err
+	}
+
+	log.Ctx(ctx).Info().
+		Str("host", host).
+		Str("path", canonical).
+		Msg("Link restored")
+	return nil
+}
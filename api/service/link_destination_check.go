@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"durable-links-generator/api/models"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+// checkDestination sends a HEAD request to the destination of a link created with
+// validateDestination=true, following at most DESTINATION_CHECK_MAX_REDIRECTS redirects, and
+// warns when it can't be reached, answers with an error status or redirects to a host off
+// allowedDomains. Redirects off the allow list aren't followed, so the check only calls hosts
+// links may point to.
+func (s *linkService) checkDestination(ctx context.Context, link string, allowedDomains []string) []models.DurableLinkCreationWarning {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.App.DestinationCheckTimeout)
+	defer cancel()
+
+	var offDomain string
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !utils.IsDomainAllowed(allowedDomains, req.URL.String()) {
+				offDomain = req.URL.String()
+				return http.ErrUseLastResponse
+			}
+			if len(via) > s.cfg.App.DestinationCheckRedirects {
+				return fmt.Errorf("more than %d redirects", s.cfg.App.DestinationCheckRedirects)
+			}
+			return nil
+		},
+	}
+
+	status, err := probeDestination(ctx, client, http.MethodHead, link)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		// Not every server answers HEAD requests.
+		status, err = probeDestination(ctx, client, http.MethodGet, link)
+	}
+
+	var warning models.DurableLinkCreationWarning
+	switch {
+	case err != nil:
+		warning = models.DurableLinkCreationWarning{
+			WarningCode:    WarningDestinationUnreachable,
+			WarningMessage: fmt.Sprintf("Param 'link' could not be reached: %s", err),
+		}
+	case offDomain != "":
+		warning = models.DurableLinkCreationWarning{
+			WarningCode:    WarningOffDomainRedirect,
+			WarningMessage: fmt.Sprintf("Param 'link' redirects to '%s', which is not in the allow list", offDomain),
+		}
+	case status >= http.StatusBadRequest:
+		warning = models.DurableLinkCreationWarning{
+			WarningCode:    WarningDestinationError,
+			WarningMessage: fmt.Sprintf("Param 'link' answers with status %d", status),
+		}
+	default:
+		return nil
+	}
+
+	log.Ctx(ctx).Info().
+		Str("code", warning.WarningCode).
+		Str("message", warning.WarningMessage).
+		Msg("Destination failed validation")
+	warning.Severity = models.WarningSeverityWarning
+	return []models.DurableLinkCreationWarning{warning}
+}
+
+// probeDestination requests link with method, returning the status of the last response.
+func probeDestination(ctx context.Context, client *http.Client, method, link string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "durable-links-generator-destination-check")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDestination(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/get-only":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/moved":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		case "/away":
+			http.Redirect(w, r, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)+"/ok", http.StatusFound)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path     string
+		wantCode string
+	}{
+		{path: "/ok"},
+		{path: "/get-only"},
+		{path: "/moved"},
+		{path: "/missing", wantCode: WarningDestinationError},
+		{path: "/loop", wantCode: WarningDestinationUnreachable},
+		{path: "/away", wantCode: WarningOffDomainRedirect},
+		{path: "/slow", wantCode: WarningDestinationUnreachable},
+	}
+
+	service := &linkService{cfg: &config.Config{App: &config.AppConfig{
+		DestinationCheckTimeout:   100 * time.Millisecond,
+		DestinationCheckRedirects: 3,
+	}}}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			warnings := service.checkDestination(context.Background(), server.URL+tt.path, []string{"127.0.0.1"})
+			if tt.wantCode == "" {
+				assert.Empty(t, warnings)
+				return
+			}
+			if assert.Len(t, warnings, 1) {
+				assert.Equal(t, tt.wantCode, warnings[0].WarningCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/geoip"
+	"durable-links-generator/api/models"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Geo destinations a link can have, at most.
+const maxGeoDestinations = 50
+
+var countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// WithGeoIP sets how visitors' countries are found for links with geo destinations. Without it,
+// every visitor gets the link's default destination.
+func (s *linkService) WithGeoIP(locator geoip.Locator) *linkService {
+	s.locator = locator
+	return s
+}
+
+// GeoDestinations returns the geo destinations of the link at path.
+func (s *linkService) GeoDestinations(ctx context.Context, host, path string) (*models.GeoDestinationsResponse, error) {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return nil, apperrors.ErrMissingHost
+	}
+	canonical, err := s.repo.GetCanonicalPath(ctx, host, path)
+	if err != nil {
+		return nil, err
+	}
+	return s.geoDestinations(ctx, host, canonical)
+}
+
+// SetGeoDestinations replaces the geo destinations of the link at path; an empty list removes
+// them.
+func (s *linkService) SetGeoDestinations(ctx context.Context, path string, req models.SetGeoDestinationsRequest) (*models.GeoDestinationsResponse, error) {
+	host, err := utils.CleanHost(req.Host)
+	if err != nil {
+		return nil, apperrors.ErrMissingHost
+	}
+	canonical, err := s.repo.GetCanonicalPath(ctx, host, path)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.linkSettings(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	destinations, err := normalizeGeoDestinations(req.GeoDestinations, settings.allowedDomains)
+	if err != nil {
+		return nil, err
+	}
+	warnings, err := s.checkReputation(ctx, models.DurableLinkInfo{GeoDestinations: destinations})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetGeoDestinations(ctx, host, canonical, destinations); err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Info().
+		Str("host", host).
+		Str("path", canonical).
+		Int("geo_destinations", len(destinations)).
+		Msg("Geo destinations set")
+
+	resp, err := s.geoDestinations(ctx, host, canonical)
+	if err != nil {
+		return nil, err
+	}
+	if len(warnings) > 0 {
+		resp.Warnings = warnings
+	}
+	return resp, nil
+}
+
+func (s *linkService) geoDestinations(ctx context.Context, host, canonical string) (*models.GeoDestinationsResponse, error) {
+	destinations, err := s.repo.ListGeoDestinations(ctx, host, canonical)
+	if err != nil {
+		return nil, err
+	}
+	return &models.GeoDestinationsResponse{Path: canonical, GeoDestinations: destinations}, nil
+}
+
+// normalizeGeoDestinations validates destinations, upper-casing their country codes. Their links
+// must be allowed destinations, like the link they override.
+func normalizeGeoDestinations(destinations []models.GeoDestination, allowedDomains []string) ([]models.GeoDestination, error) {
+	if len(destinations) > maxGeoDestinations {
+		return nil, apperrors.ErrInvalidGeoDestination
+	}
+	normalized := make([]models.GeoDestination, 0, len(destinations))
+	seen := map[string]bool{}
+	for _, destination := range destinations {
+		country := strings.ToUpper(strings.TrimSpace(destination.Country))
+		if !countryCodePattern.MatchString(country) || seen[country] {
+			return nil, apperrors.ErrInvalidGeoDestination
+		}
+		seen[country] = true
+		if err := utils.ValidateURLScheme(destination.Link); err != nil {
+			return nil, apperrors.ErrInvalidGeoDestination
+		}
+		if !utils.IsDomainAllowed(allowedDomains, destination.Link) {
+			return nil, apperrors.ErrDomainLinkNotAllowed
+		}
+		normalized = append(normalized, models.GeoDestination{Country: country, Link: destination.Link})
+	}
+	return normalized, nil
+}
+
+// applyGeoDestination points params' link at the geo destination for the visitor at ip, if the
+// link at path has one for their country, and reports whether it did. Failed lookups leave the
+// default destination.
+func (s *linkService) applyGeoDestination(ctx context.Context, host, path, ip string, params url.Values) bool {
+	country := s.locator.Country(ip)
+	if country == "" {
+		return false
+	}
+	destinations, err := s.repo.ResolveGeoDestinations(ctx, host, path)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("host", host).Str("path", path).Msg("Failed to read geo destinations")
+		return false
+	}
+	for _, destination := range destinations {
+		if destination.Country == country {
+			params.Set("link", destination.Link)
+			return true
+		}
+	}
+	return false
+}
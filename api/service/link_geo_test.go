@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// geoLinkRepository adds geo destinations, by path, to fakeLinkRepository.
+type geoLinkRepository struct {
+	*fakeLinkRepository
+	geo map[string][]models.GeoDestination
+}
+
+func (f *geoLinkRepository) SetGeoDestinations(_ context.Context, _, path string, destinations []models.GeoDestination) error {
+	f.geo[path] = destinations
+	return nil
+}
+
+func (f *geoLinkRepository) ListGeoDestinations(_ context.Context, _, path string) ([]models.GeoDestination, error) {
+	return append([]models.GeoDestination{}, f.geo[path]...), nil
+}
+
+func (f *geoLinkRepository) ResolveGeoDestinations(ctx context.Context, host, path string) ([]models.GeoDestination, error) {
+	canonical, err := f.GetCanonicalPath(ctx, host, path)
+	if err != nil {
+		return nil, err
+	}
+	return f.ListGeoDestinations(ctx, host, canonical)
+}
+
+// countries locates IPs by a fixed table.
+type countries map[string]string
+
+func (c countries) Country(ip string) string { return c[ip] }
+
+func TestGeoDestinations(t *testing.T) {
+	repo := &geoLinkRepository{fakeLinkRepository: &fakeLinkRepository{}, geo: map[string][]models.GeoDestination{}}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:      "https",
+		AllowedDomains: []string{"example.com"},
+	}}).WithGeoIP(countries{"192.0.2.1": "DE", "192.0.2.2": "FR"})
+	ctx := context.Background()
+	req := func(geo ...models.GeoDestination) models.CreateDurableLinkRequest {
+		return models.CreateDurableLinkRequest{
+			DurableLinkInfo: models.DurableLinkInfo{Host: "acme.link", Link: "https://example.com/store", GeoDestinations: geo},
+			Suffix:          models.Suffix{Option: "CUSTOM", CustomPath: "store"},
+		}
+	}
+
+	_, err := s.CreateDurableLink(ctx, req(models.GeoDestination{Country: " de ", Link: "https://example.com/de/store"}))
+	assert.NoError(t, err)
+	assert.Equal(t, []models.GeoDestination{{Country: "DE", Link: "https://example.com/de/store"}}, repo.geo["store"])
+
+	target, err := s.ResolveRedirect(ctx, "acme.link", "store", models.ClickContext{IP: "192.0.2.1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/de/store", target.Destination)
+	target, err = s.ResolveRedirect(ctx, "acme.link", "store", models.ClickContext{IP: "192.0.2.2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/store", target.Destination, "no destination for FR")
+
+	resp, err := s.SetGeoDestinations(ctx, "store", models.SetGeoDestinationsRequest{
+		Host:            "acme.link",
+		GeoDestinations: []models.GeoDestination{{Country: "fr", Link: "https://example.com/fr/store"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.GeoDestination{{Country: "FR", Link: "https://example.com/fr/store"}}, resp.GeoDestinations)
+	target, err = s.ResolveRedirect(ctx, "acme.link", "store", models.ClickContext{IP: "192.0.2.1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/store", target.Destination, "the DE destination was replaced")
+
+	for _, geo := range [][]models.GeoDestination{
+		{{Country: "Germany", Link: "https://example.com/de"}},
+		{{Country: "DE", Link: "https://example.com/a"}, {Country: "de", Link: "https://example.com/b"}},
+		{{Country: "DE", Link: "ftp://example.com/de"}},
+	} {
+		_, err := s.SetGeoDestinations(ctx, "store", models.SetGeoDestinationsRequest{Host: "acme.link", GeoDestinations: geo})
+		assert.ErrorIs(t, err, apperrors.ErrInvalidGeoDestination)
+	}
+	_, err = s.CreateDurableLink(ctx, req(models.GeoDestination{Country: "DE", Link: "https://evil.test/de"}))
+	assert.ErrorIs(t, err, apperrors.ErrDomainLinkNotAllowed)
+
+	s.WithReputation(&fakeChecker{flagged: map[string]string{"https://example.com/bad": "MALWARE"}})
+	_, err = s.SetGeoDestinations(ctx, "store", models.SetGeoDestinationsRequest{
+		Host:            "acme.link",
+		GeoDestinations: []models.GeoDestination{{Country: "DE", Link: "https://example.com/bad"}},
+	})
+	assert.ErrorIs(t, err, apperrors.ErrUnsafeURL)
+	_, err = s.CreateDurableLink(ctx, req(models.GeoDestination{Country: "DE", Link: "https://example.com/bad"}))
+	assert.ErrorIs(t, err, apperrors.ErrUnsafeURL)
+	assert.Equal(t, []models.GeoDestination{{Country: "FR", Link: "https://example.com/fr/store"}}, repo.geo["store"])
+
+	_, err = s.GeoDestinations(ctx, "acme.link", "missing")
+	assert.ErrorIs(t, err, apperrors.ErrLinkNotFound)
+}
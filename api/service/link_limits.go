@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/utils"
+)
+
+// validateClickLimit checks the click limit of a link and where it leads once expired, which
+// must be an allowed destination like the link itself.
+func validateClickLimit(info models.DurableLinkInfo, allowedDomains []string) error {
+	if info.MaxClicks < 0 {
+		return apperrors.ErrInvalidMaxClicks
+	}
+	if info.ExpiredLink == "" {
+		return nil
+	}
+	if err := utils.ValidateURLScheme(info.ExpiredLink); err != nil {
+		return apperrors.ErrInvalidExpiredLink
+	}
+	if !utils.IsDomainAllowed(allowedDomains, info.ExpiredLink) {
+		return apperrors.ErrDomainLinkNotAllowed
+	}
+	return nil
+}
+
+// useClick counts a click against the click limit of the link at path, if it has one, and fails
+// with ErrLinkExpired once the limit is used up. The repository counts atomically, so concurrent
+// clicks never let more visitors through than the limit.
+func (s *linkService) useClick(ctx context.Context, host, path string, params url.Values) error {
+	maxClicks, _ := strconv.ParseInt(params.Get("mc"), 10, 64)
+	if maxClicks <= 0 {
+		return nil
+	}
+	ok, err := s.repo.UseClick(ctx, host, path, maxClicks)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apperrors.ErrLinkExpired
+	}
+	return nil
+}
+
+// expiredTarget sends visitors of an expired link to its expired link, or fails with err for the
+// expired page when it has none.
+func expiredTarget(params url.Values, err error) (*models.RedirectTarget, error) {
+	if expired := params.Get("efl"); expired != "" && errors.Is(err, apperrors.ErrLinkExpired) {
+		return &models.RedirectTarget{Destination: expired}, nil
+	}
+	return nil, err
+}
+
+// limitParams are the params keeping a link's click limit and activation window, which are
+// ours and not the app's, so exchanges leave them out of the long link.
+var limitParams = []string{"mc", "efl", "af", "au"}
+
+// expiredLongLink answers the exchange of an expired link with a long link to its expired link,
+// or fails with err when it has none.
+func (s *linkService) expiredLongLink(host, path, platform string, params url.Values, err error) (*models.LongLinkResponse, error) {
+	expired := params.Get("efl")
+	if expired == "" || !errors.Is(err, apperrors.ErrLinkExpired) {
+		return nil, err
+	}
+	response := &models.LongLinkResponse{
+		LongLink: fmt.Sprintf("%s://%s/%s?%s", s.cfg.App.URLScheme, host, path, url.Values{"link": {expired}}.Encode()),
+	}
+	if platform != "" {
+		response.Link = expired
+	}
+	return response, nil
+}
+
+// withoutParams returns rawQuery without the pairs of keys, leaving the others as they are.
+func withoutParams(rawQuery string, keys []string) string {
+	var kept []string
+	for _, pair := range strings.Split(rawQuery, "&") {
+		key, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		if pair != "" && !slices.Contains(keys, key) {
+			kept = append(kept, pair)
+		}
+	}
+	return strings.Join(kept, "&")
+}
+
+// validateActiveWindow checks that a link's activation window, if bounded on both ends, isn't
+// empty.
+func validateActiveWindow(info models.DurableLinkInfo) error {
+	if info.ActiveFrom != nil && info.ActiveUntil != nil && !info.ActiveUntil.After(*info.ActiveFrom) {
+		return apperrors.ErrInvalidActiveWindow
+	}
+	return nil
+}
+
+// formatActiveTime formats a bound of the activation window for the link's params.
+func formatActiveTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// parseActiveTime parses a bound of the activation window from the link's params, returning nil
+// for an unbounded end.
+func parseActiveTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, apperrors.ErrInvalidActiveWindow
+	}
+	return &t, nil
+}
+
+// checkActiveWindow fails with ErrLinkNotActive before the link's activation window opens and
+// with ErrLinkExpired once it closed. Malformed bounds are ignored rather than taking the link
+// down.
+func checkActiveWindow(params url.Values, now time.Time) error {
+	if from, err := parseActiveTime(params.Get("af")); err == nil && from != nil && now.Before(*from) {
+		return apperrors.ErrLinkNotActive
+	}
+	if until, err := parseActiveTime(params.Get("au")); err == nil && until != nil && !now.Before(*until) {
+		return apperrors.ErrLinkExpired
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxClicks(t *testing.T) {
+	repo := &fakeLinkRepository{}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:      "https",
+		AllowedDomains: []string{"example.com"},
+	}})
+	ctx := context.Background()
+	req := func(path string, maxClicks int64, expiredLink string) models.CreateDurableLinkRequest {
+		return models.CreateDurableLinkRequest{
+			DurableLinkInfo: models.DurableLinkInfo{
+				Host:        "acme.link",
+				Link:        "https://example.com/offer",
+				MaxClicks:   maxClicks,
+				ExpiredLink: expiredLink,
+			},
+			Suffix: models.Suffix{Option: "CUSTOM", CustomPath: path},
+		}
+	}
+
+	_, err := s.CreateDurableLink(ctx, req("once", 2, ""))
+	assert.NoError(t, err)
+	_, err = s.CreateDurableLink(ctx, req("fallback", 1, "https://example.com/sold-out"))
+	assert.NoError(t, err)
+
+	// Previews don't use up the limit.
+	_, err = s.ResolveRedirect(ctx, "acme.link", "once", models.ClickContext{Head: true})
+	assert.NoError(t, err)
+	for range 2 {
+		target, err := s.ResolveRedirect(ctx, "acme.link", "once", models.ClickContext{})
+		assert.NoError(t, err)
+		assert.Equal(t, "https://example.com/offer", target.Destination)
+	}
+	_, err = s.ResolveRedirect(ctx, "acme.link", "once", models.ClickContext{})
+	assert.ErrorIs(t, err, apperrors.ErrLinkExpired)
+
+	_, err = s.ResolveRedirect(ctx, "acme.link", "fallback", models.ClickContext{})
+	assert.NoError(t, err)
+	target, err := s.ResolveRedirect(ctx, "acme.link", "fallback", models.ClickContext{})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/sold-out", target.Destination)
+
+	_, err = s.CreateDurableLink(ctx, req("negative", -1, ""))
+	assert.ErrorIs(t, err, apperrors.ErrInvalidMaxClicks)
+	_, err = s.CreateDurableLink(ctx, req("relative", 1, "/sold-out"))
+	assert.ErrorIs(t, err, apperrors.ErrInvalidExpiredLink)
+	_, err = s.CreateDurableLink(ctx, req("elsewhere", 1, "https://evil.test"))
+	assert.ErrorIs(t, err, apperrors.ErrDomainLinkNotAllowed)
+}
+
+func TestMaxClicks_Gate(t *testing.T) {
+	repo := &fakeLinkRepository{}
+	recorder := &fakeClickRecorder{}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:      "https",
+		AllowedDomains: []string{"example.com"},
+	}}).WithClickRecorder(recorder)
+	ctx := context.Background()
+
+	_, err := s.CreateDurableLink(ctx, models.CreateDurableLinkRequest{
+		DurableLinkInfo: models.DurableLinkInfo{Host: "acme.link", Link: "https://example.com/offer", Gate: "age", MaxClicks: 1},
+		Suffix:          models.Suffix{Option: "CUSTOM", CustomPath: "once"},
+	})
+	assert.NoError(t, err)
+
+	// The interstitial doesn't use up the link, acknowledging it does.
+	target, err := s.ResolveRedirect(ctx, "acme.link", "once", models.ClickContext{})
+	assert.NoError(t, err)
+	assert.Equal(t, "age", target.Gate)
+	assert.Empty(t, recorder.paths)
+	target, err = s.ResolveRedirect(ctx, "acme.link", "once", models.ClickContext{Acknowledged: []string{"terms", "age"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/offer", target.Destination)
+	assert.Equal(t, []string{"acme.link/once"}, recorder.paths)
+
+	_, err = s.ResolveRedirect(ctx, "acme.link", "once", models.ClickContext{Acknowledged: []string{"age"}})
+	assert.ErrorIs(t, err, apperrors.ErrLinkExpired)
+}
+
+func TestExchangeLimits(t *testing.T) {
+	repo := &fakeLinkRepository{}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:      "https",
+		AllowedDomains: []string{"example.com"},
+	}})
+	ctx := context.Background()
+	inAnHour := time.Now().Add(time.Hour)
+	create := func(path string, info models.DurableLinkInfo) {
+		info.Host, info.Link = "acme.link", "https://example.com/offer"
+		_, err := s.CreateDurableLink(ctx, models.CreateDurableLinkRequest{
+			DurableLinkInfo: info,
+			Suffix:          models.Suffix{Option: "CUSTOM", CustomPath: path},
+		})
+		assert.NoError(t, err)
+	}
+	create("once", models.DurableLinkInfo{MaxClicks: 1})
+	create("fallback", models.DurableLinkInfo{MaxClicks: 1, ExpiredLink: "https://example.com/sold-out"})
+	create("soon", models.DurableLinkInfo{ActiveFrom: &inAnHour})
+
+	resp, err := s.ResolveShortPath(ctx, "https://acme.link/once", "", models.ClickContext{})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acme.link/once?link=https%3A%2F%2Fexample.com%2Foffer", resp.LongLink, "limits aren't handed out")
+	_, err = s.ResolveShortPath(ctx, "https://acme.link/once", "", models.ClickContext{})
+	assert.ErrorIs(t, err, apperrors.ErrLinkExpired)
+
+	_, err = s.ResolveShortPath(ctx, "https://acme.link/fallback", "web", models.ClickContext{})
+	assert.NoError(t, err)
+	resp, err = s.ResolveShortPath(ctx, "https://acme.link/fallback", "web", models.ClickContext{})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acme.link/fallback?link=https%3A%2F%2Fexample.com%2Fsold-out", resp.LongLink)
+	assert.Equal(t, "https://example.com/sold-out", resp.Link)
+
+	_, err = s.ResolveShortPath(ctx, "https://acme.link/soon", "", models.ClickContext{})
+	assert.ErrorIs(t, err, apperrors.ErrLinkNotActive)
+}
+
+func TestActiveWindow(t *testing.T) {
+	repo := &fakeLinkRepository{}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:      "https",
+		AllowedDomains: []string{"example.com"},
+	}})
+	ctx := context.Background()
+	now := time.Now()
+	hourAgo, inAnHour := now.Add(-time.Hour), now.Add(time.Hour)
+	req := func(path string, from, until *time.Time, expiredLink string) models.CreateDurableLinkRequest {
+		return models.CreateDurableLinkRequest{
+			DurableLinkInfo: models.DurableLinkInfo{
+				Host:        "acme.link",
+				Link:        "https://example.com/launch",
+				ActiveFrom:  from,
+				ActiveUntil: until,
+				ExpiredLink: expiredLink,
+			},
+			Suffix: models.Suffix{Option: "CUSTOM", CustomPath: path},
+		}
+	}
+
+	for path, tc := range map[string]struct {
+		from, until *time.Time
+		expiredLink string
+		want        string
+		err         error
+	}{
+		"live":     {from: &hourAgo, until: &inAnHour, want: "https://example.com/launch"},
+		"upcoming": {from: &inAnHour, expiredLink: "https://example.com/over", err: apperrors.ErrLinkNotActive},
+		"over":     {until: &hourAgo, err: apperrors.ErrLinkExpired},
+		"replaced": {until: &hourAgo, expiredLink: "https://example.com/over", want: "https://example.com/over"},
+	} {
+		_, err := s.CreateDurableLink(ctx, req(path, tc.from, tc.until, tc.expiredLink))
+		assert.NoError(t, err)
+		target, err := s.ResolveRedirect(ctx, "acme.link", path, models.ClickContext{})
+		if tc.err != nil {
+			assert.ErrorIs(t, err, tc.err, path)
+			continue
+		}
+		if assert.NoError(t, err, path) {
+			assert.Equal(t, tc.want, target.Destination, path)
+		}
+	}
+
+	_, err := s.CreateDurableLink(ctx, req("backwards", &inAnHour, &hourAgo, ""))
+	assert.ErrorIs(t, err, apperrors.ErrInvalidActiveWindow)
+
+	parsed, err := s.ParseLongDurableLink(ctx, "https://acme.link/?link=https%3A%2F%2Fexample.com&af=2030-01-02T15%3A04%3A05Z")
+	assert.NoError(t, err)
+	if assert.NotNil(t, parsed.DurableLinkInfo.ActiveFrom) {
+		assert.Equal(t, time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC), *parsed.DurableLinkInfo.ActiveFrom)
+	}
+}
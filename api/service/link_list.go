@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/utils"
+)
+
+const (
+	defaultListPageSize = 100
+	maxListPageSize     = 1000
+	defaultListSort     = "-createdAt"
+)
+
+var destinationDomainPattern = regexp.MustCompile(`^[a-z0-9-]+(\.[a-z0-9-]+)*$`)
+
+// listSortValues returns the value of a link's sort column, as carried in page tokens.
+var listSortValues = map[string]func(models.StoredLink) string{
+	"createdAt":  func(l models.StoredLink) string { return l.CreatedAt.Format(time.RFC3339Nano) },
+	"clickCount": func(l models.StoredLink) string { return strconv.FormatInt(l.TotalClicks, 10) },
+	"lastClickedAt": func(l models.StoredLink) string {
+		if l.LastClickedAt == nil {
+			return time.Unix(0, 0).UTC().Format(time.RFC3339Nano)
+		}
+		return l.LastClickedAt.Format(time.RFC3339Nano)
+	},
+	// Links that never expire sort last, as if they expired at the end of time.
+	"expiresAt": func(l models.StoredLink) string {
+		if l.ActiveUntil == nil {
+			return time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC).Format(time.RFC3339Nano)
+		}
+		return l.ActiveUntil.Format(time.RFC3339Nano)
+	},
+}
+
+// validSortValue reports whether value, taken from a page token, is a value of the sort column
+// field as listSortValues writes it. The databases would fail on anything else.
+func validSortValue(field, value string) bool {
+	var err error
+	if field == "clickCount" {
+		_, err = strconv.ParseInt(value, 10, 64)
+	} else {
+		_, err = time.Parse(time.RFC3339Nano, value)
+	}
+	return err == nil
+}
+
+// pageToken is the position after the last link of a page. It records the sort it was issued
+// for so it can't be replayed against another order.
+type pageToken struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int64  `json:"i"`
+}
+
+// ListLinks pages through the host's links, newest first unless another sort is asked for, and
+// narrowed by the request's filters.
+func (s *linkService) ListLinks(ctx context.Context, req models.ListLinksRequest) (*models.ListLinksResponse, error) {
+	host, err := utils.CleanHost(req.Host)
+	if err != nil {
+		return nil, apperrors.ErrMissingHost
+	}
+
+	sort := req.Sort
+	if sort == "" {
+		sort = defaultListSort
+	}
+	field, desc := strings.TrimPrefix(sort, "-"), strings.HasPrefix(sort, "-")
+	sortValue, ok := listSortValues[field]
+	if !ok {
+		return nil, fmt.Errorf("%w, got %q", apperrors.ErrInvalidListSort, sort)
+	}
+
+	filter, err := listFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	page := models.LinkPage{Sort: field, Desc: desc, Limit: defaultListPageSize, Filter: filter}
+	if req.PageSize > 0 {
+		page.Limit = min(req.PageSize, maxListPageSize)
+	}
+	if req.PageToken != "" {
+		token, err := decodePageToken(req.PageToken)
+		if err != nil || token.Sort != sort || !validSortValue(field, token.Value) {
+			return nil, apperrors.ErrInvalidPageToken
+		}
+		page.AfterValue, page.AfterID = token.Value, token.ID
+	}
+
+	// One extra link tells whether there is a next page.
+	page.Limit++
+	links, err := s.repo.ListLinks(ctx, host, page)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.ListLinksResponse{Links: []models.LinkSummary{}}
+	if len(links) == page.Limit {
+		links = links[:len(links)-1]
+		last := links[len(links)-1]
+		resp.NextPageToken = encodePageToken(pageToken{Sort: sort, Value: sortValue(last), ID: last.ID})
+	}
+	for _, link := range links {
+		summary, err := linkSummary(s.cfg.App.URLScheme, link)
+		if err != nil {
+			return nil, err
+		}
+		resp.Links = append(resp.Links, summary)
+	}
+	return resp, nil
+}
+
+func listFilter(req models.ListLinksRequest) (models.LinkFilter, error) {
+	filter := models.LinkFilter{CreatedFrom: req.CreatedAfter, CreatedTo: req.CreatedBefore}
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return filter, err
+	}
+	filter.Tags = tags
+	if !req.CreatedAfter.IsZero() && !req.CreatedBefore.IsZero() && !req.CreatedAfter.Before(req.CreatedBefore) {
+		return filter, apperrors.ErrInvalidDateRange
+	}
+
+	switch strings.ToUpper(req.Suffix) {
+	case "":
+	case "SHORT":
+		unguessable := false
+		filter.Unguessable = &unguessable
+	case "UNGUESSABLE":
+		unguessable := true
+		filter.Unguessable = &unguessable
+	default:
+		return filter, apperrors.ErrInvalidListSuffix
+	}
+
+	if req.DestinationDomain != "" {
+		domain, err := utils.CleanHost(req.DestinationDomain)
+		domain = strings.ToLower(domain)
+		if err != nil || !destinationDomainPattern.MatchString(domain) {
+			return filter, apperrors.ErrInvalidDestinationDomain
+		}
+		filter.DestinationDomain = domain
+	}
+	return filter, nil
+}
+
+func linkSummary(scheme string, link models.StoredLink) (models.LinkSummary, error) {
+	params, err := url.ParseQuery(link.QueryParams)
+	if err != nil {
+		return models.LinkSummary{}, fmt.Errorf("invalid stored query params: %w", err)
+	}
+	return models.LinkSummary{
+		ShortLink:     fmt.Sprintf("%s://%s/%s", scheme, link.Host, link.Path),
+		Path:          link.Path,
+		Link:          params.Get("link"),
+		Title:         params.Get("st"),
+		CreatedAt:     link.CreatedAt,
+		TotalClicks:   link.TotalClicks,
+		LastClickedAt: link.LastClickedAt,
+		LinkMetadata:  link.Metadata,
+	}, nil
+}
+
+func encodePageToken(token pageToken) string {
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodePageToken(raw string) (pageToken, error) {
+	var token pageToken
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return token, err
+	}
+	if err := json.Unmarshal(data, &token); err != nil {
+		return token, err
+	}
+	if token.ID <= 0 {
+		return token, apperrors.ErrInvalidPageToken
+	}
+	return token, nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+// MergeDuplicates finds links on a host with the same parameters and merges each group into one
+// link, keeping the other paths as aliases so they still resolve and adding their clicks to it.
+func (s *linkService) MergeDuplicates(ctx context.Context, req models.MergeLinksRequest) (*models.MergeLinksResponse, error) {
+	host, err := utils.CleanHost(req.Host)
+	if err != nil {
+		return nil, apperrors.ErrMissingHost
+	}
+
+	strategy := req.Strategy
+	if strategy == "" {
+		strategy = "oldest"
+	}
+	if strategy != "oldest" && strategy != "shortest" {
+		return nil, fmt.Errorf("%w: strategy must be oldest or shortest", apperrors.ErrInvalidFormat)
+	}
+
+	release, err := s.acquireTenant(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	links, err := s.repo.ListLinksByHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	// Links are listed oldest first, so the first link of each group is the oldest.
+	groups := map[string][]models.StoredLink{}
+	var order []string
+	for _, link := range links {
+		key, err := canonicalQuery(link.QueryParams)
+		if err != nil {
+			continue
+		}
+		// Reserved links have no destination to share with another link.
+		if params, _ := url.ParseQuery(key); isReserved(params) {
+			continue
+		}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], link)
+	}
+
+	resp := &models.MergeLinksResponse{
+		DryRun: req.DryRun,
+		Groups: []models.MergeGroup{},
+	}
+	for _, key := range order {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+
+		canonical := group[0]
+		if strategy == "shortest" {
+			for _, link := range group[1:] {
+				if len(link.Path) < len(canonical.Path) {
+					canonical = link
+				}
+			}
+		}
+
+		merged := []string{}
+		for _, link := range group {
+			if link.Path != canonical.Path {
+				merged = append(merged, link.Path)
+			}
+		}
+
+		if !req.DryRun {
+			if err := s.repo.MergeLinks(ctx, host, canonical.Path, merged); err != nil {
+				return nil, err
+			}
+			log.Ctx(ctx).Info().
+				Str("host", host).
+				Str("canonical", canonical.Path).
+				Strs("merged", merged).
+				Msg("Merged duplicate links")
+		}
+
+		resp.Groups = append(resp.Groups, models.MergeGroup{Canonical: canonical.Path, Merged: merged})
+		resp.Merged += len(merged)
+	}
+
+	return resp, nil
+}
+
+// canonicalQuery re-encodes query params with sorted keys so equal parameter sets compare equal
+// however they were stored.
+func canonicalQuery(rawQS string) (string, error) {
+	values, err := url.ParseQuery(rawQS)
+	if err != nil {
+		return "", err
+	}
+	return values.Encode(), nil
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeDuplicates(t *testing.T) {
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{Host: "acme.link", Path: "first1", QueryParams: "apn=com.app&link=https%3A%2F%2Fa.com"},
+		{Host: "acme.link", Path: "unique", QueryParams: "link=https%3A%2F%2Fb.com"},
+		{Host: "acme.link", Path: "dup", QueryParams: "link=https%3A%2F%2Fa.com&apn=com.app"},
+		{Host: "acme.link", Path: "x", QueryParams: "apn=com.app&link=https%3A%2F%2Fa.com"},
+	}}
+	service := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{}})
+
+	resp, err := service.MergeDuplicates(context.Background(), models.MergeLinksRequest{Host: "acme.link", DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.MergeGroup{{Canonical: "first1", Merged: []string{"dup", "x"}}}, resp.Groups)
+	assert.Empty(t, repo.merged)
+
+	resp, err = service.MergeDuplicates(context.Background(), models.MergeLinksRequest{Host: "acme.link", Strategy: "shortest"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, resp.Merged)
+	assert.Equal(t, map[string][]string{"x": {"first1", "dup"}}, repo.merged)
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+)
+
+// Limits of the metadata of a link.
+const (
+	maxLinkNameLength  = 255
+	maxLinkNotesLength = 4000
+	maxLinkTags        = 20
+	maxTagLength       = 50
+)
+
+// tagPattern keeps quotes and backslashes out of tags, so a tag is found in the array literal
+// SQLite and MySQL store tags as by looking for it in quotes.
+var tagPattern = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N} _.:/-]*$`)
+
+// normalizeMetadata validates metadata, trimming its name and notes and normalizing its tags.
+func normalizeMetadata(metadata models.LinkMetadata) (models.LinkMetadata, error) {
+	metadata.Name = strings.TrimSpace(metadata.Name)
+	if utf8.RuneCountInString(metadata.Name) > maxLinkNameLength {
+		return metadata, fmt.Errorf("%w: name may hold at most %d characters", apperrors.ErrInvalidMetadata, maxLinkNameLength)
+	}
+	metadata.Notes = strings.TrimSpace(metadata.Notes)
+	if utf8.RuneCountInString(metadata.Notes) > maxLinkNotesLength {
+		return metadata, fmt.Errorf("%w: notes may hold at most %d characters", apperrors.ErrInvalidMetadata, maxLinkNotesLength)
+	}
+	tags, err := normalizeTags(metadata.Tags)
+	if err != nil {
+		return metadata, err
+	}
+	if len(tags) > maxLinkTags {
+		return metadata, fmt.Errorf("%w: a link may have at most %d tags", apperrors.ErrInvalidMetadata, maxLinkTags)
+	}
+	metadata.Tags = tags
+	return metadata, nil
+}
+
+// normalizeTags lowercases and trims tags, sorting them and dropping repeats.
+func normalizeTags(tags []string) ([]string, error) {
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !tagPattern.MatchString(tag) || utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, fmt.Errorf("%w: tag %q must be 1 to %d letters, digits, spaces or '_', '.', ':', '/', '-'", apperrors.ErrInvalidMetadata, tag, maxTagLength)
+		}
+		normalized = append(normalized, tag)
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/utils"
+)
+
+// QRCodeLink returns the short link a QR code of path on host encodes, failing with
+// ErrLinkNotFound unless path resolves, so codes are never printed for links that don't work.
+func (s *linkService) QRCodeLink(ctx context.Context, host, path string) (string, error) {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return "", apperrors.ErrMissingHost
+	}
+	if _, err := s.repo.GetQueryParamsByHostAndPath(ctx, host, path); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s://%s/%s", s.cfg.App.URLScheme, host, path), nil
+}
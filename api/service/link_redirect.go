@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/attribution"
+	"durable-links-generator/api/clicks"
+	"durable-links-generator/api/device"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/policy"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Marketing params forwarded to the destination on redirect, and stripped from it when the visitor
+// hasn't consented to tracking.
+var marketingParams = []string{"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content"}
+
+// Click identifiers added by ad networks. They are never added by us but are stripped without consent.
+var clickIDParams = []string{"gclid", "dclid", "fbclid", "msclkid", "twclid", "ttclid"}
+
+// TCF purposes that must be granted: store/access information and measure advertising performance.
+var requiredTCFPurposes = []int{1, 7}
+
+func (s *linkService) ResolveRedirect(ctx context.Context, host, path string, click models.ClickContext) (*models.RedirectTarget, error) {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host: %w", err)
+	}
+	requestHost := host
+	host = removePreviewFromHost(host)
+
+	rawQueryStr, err := s.repo.GetQueryParamsByHostAndPath(ctx, host, path)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := url.ParseQuery(rawQueryStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stored query params: %w", err)
+	}
+	if isReserved(params) {
+		return nil, apperrors.ErrLinkReserved
+	}
+	if err := checkActiveWindow(params, time.Now()); err != nil {
+		return expiredTarget(params, err)
+	}
+	if err := s.checkResolvePolicy(ctx, host, path, params); err != nil {
+		return nil, err
+	}
+	consented := s.hasConsent(click)
+	var variant *models.LinkVariant
+	visitorID := click.VisitorID
+	if visitorID == "" {
+		visitorID = visitorFingerprint(click)
+	}
+	if !s.applyGeoDestination(ctx, host, path, click.IP, params) {
+		if variant = s.linkVariant(ctx, host, path, visitorID); variant != nil {
+			params.Set("link", variant.Link)
+		}
+	}
+
+	visitor := s.detector.Detect(device.Hints{
+		UserAgent: click.UserAgent,
+		Platform:  click.PlatformHint,
+		Model:     click.ModelHint,
+	})
+	link := deviceLink(params, visitor)
+	if ruled := s.deviceRuleLink(ctx, host, path, params, visitor); ruled != "" {
+		link = ruled
+	}
+	devicePolicy := s.cfg.App.DevicePolicies[visitor.FormFactor]
+	if ofl := params.Get("ofl"); devicePolicy == devicePolicyFallback && ofl != "" {
+		link = ofl
+	}
+	destination, err := buildDestination(link, params, consented)
+	if err != nil {
+		return nil, err
+	}
+
+	gate := params.Get("gate")
+	if gate == "" {
+		gate = s.cfg.App.InterstitialGates[strings.ToLower(host)]
+	}
+
+	preview := models.SocialMetaTagInfo{
+		SocialTitle:       params.Get("st"),
+		SocialDescription: params.Get("sd"),
+		SocialImageLink:   params.Get("si"),
+	}
+	// Links without social tags still redirect crawlers, which then scrape the destination.
+	socialPreview := utils.IsSocialCrawler(click.UserAgent) && preview != models.SocialMetaTagInfo{}
+
+	target := &models.RedirectTarget{
+		Destination:    destination,
+		Gate:           gate,
+		SocialPreview:  socialPreview,
+		ScannerPreview: !socialPreview && s.isScannerRequest(host, click),
+		DesktopApp:     desktopApp(params, visitor),
+	}
+	if socialPreview {
+		target.Preview = preview
+	}
+	if variant != nil {
+		target.Variant = variant.Name
+		if click.VisitorID == "" && consented {
+			target.VisitorID = visitorID
+		}
+	}
+	if requestHost != host {
+		target.AppPreview = s.appPreview(params, host, path)
+		target.Preview = preview
+	}
+	qrFirst := params.Get("qrf") == "1" && visitor.Desktop() && target.DesktopApp == nil
+	if devicePolicy == devicePolicyQRCode || qrFirst {
+		target.QRCode = fmt.Sprintf("%s://%s/%s", s.cfg.App.URLScheme, host, path)
+		target.Preview = preview
+	}
+
+	if s.countsAsClick(target, click) {
+		if err := s.useClick(ctx, host, path, params); err != nil {
+			return expiredTarget(params, err)
+		}
+		log.Ctx(ctx).Info().
+			Str("host", host).
+			Str("path", path).
+			Bool("consented", consented).
+			Str("platform", visitor.Platform).
+			Str("os", visitor.OS).
+			Str("model", visitor.Model).
+			Bool("hms", visitor.HMS).
+			Str("form_factor", visitor.FormFactor).
+			Str("variant", target.Variant).
+			Msg("Link clicked")
+		s.clicks.Record(host, path, time.Now())
+		s.recordClickEvent(host, path, clicks.SourceRedirect, visitor.Platform, target.Variant, click, consented)
+		if consented {
+			s.recordInstallClick(host, path, target.Variant, params, visitor, click)
+		}
+	}
+
+	return target, nil
+}
+
+// recordClickEvent logs a resolution for click analytics. The referrer, the user agent and the IP
+// the country is looked up from are only passed on with consent.
+func (s *linkService) recordClickEvent(host, path, source, platform, variant string, click models.ClickContext, consented bool) {
+	event := clicks.Event{
+		Host:      host,
+		Path:      path,
+		At:        time.Now(),
+		Source:    source,
+		Platform:  platform,
+		Variant:   variant,
+		Consented: consented,
+	}
+	if consented {
+		event.Referrer = click.Referrer
+		event.UserAgent = click.UserAgent
+		event.IP = click.IP
+	}
+	s.clickEvents.RecordEvent(event)
+}
+
+// recordInstallClick keeps a fingerprint of clicks on mobile devices that may be followed by an
+// install of the link's app, for the app to claim the link on first launch.
+func (s *linkService) recordInstallClick(host, path, variant string, params url.Values, visitor device.Device, click models.ClickContext) {
+	hasApp := visitor.Platform == device.PlatformAndroid && params.Get("apn") != "" ||
+		visitor.Platform == device.PlatformIOS && params.Get("isi") != ""
+	if !hasApp {
+		return
+	}
+	s.installClicks.Record(attribution.Click{
+		Fingerprint: attribution.Fingerprint{
+			IP:        click.IP,
+			OS:        visitor.Platform,
+			OSVersion: attribution.OSVersion(click.UserAgent),
+			Language:  attribution.Language(click.AcceptLanguage),
+		},
+		Host:     host,
+		Path:     path,
+		DeepLink: params.Get("link"),
+		Variant:  variant,
+		At:       time.Now(),
+	})
+}
+
+// checkResolvePolicy runs the policy hooks before a link is handed out. Warnings are only
+// logged, there is nobody to show them to.
+func (s *linkService) checkResolvePolicy(ctx context.Context, host, path string, params url.Values) error {
+	decision := s.policy.BeforeResolve(ctx, &policy.Link{Host: host, Path: path, Params: params})
+	for _, warning := range decision.Warnings {
+		log.Ctx(ctx).Warn().Str("host", host).Str("path", path).Str("warning", warning).Msg("Policy warning on resolve")
+	}
+	if decision.Deny {
+		return fmt.Errorf("%w: %s", apperrors.ErrPolicyDenied, decision.Reason)
+	}
+	return nil
+}
+
+// countsAsClick reports whether a resolution should be recorded. Scanner and crawler hits and,
+// unless configured otherwise, HEAD probes aren't clicks: counting them would pollute stats and
+// use up one-time links. Neither are preview pages, the click is counted when the visitor
+// continues to the main host, nor interstitials, counted once the visitor acknowledges them.
+func (s *linkService) countsAsClick(target *models.RedirectTarget, click models.ClickContext) bool {
+	if target.ScannerPreview || target.SocialPreview || target.AppPreview != nil {
+		return false
+	}
+	if target.Gate != "" && !slices.Contains(click.Acknowledged, target.Gate) {
+		return false
+	}
+	return !click.Head || s.cfg.App.HeadCountsAsClick
+}
+
+// isScannerRequest reports whether a click on a scanner friendly domain comes from a mail
+// security scanner or another client that isn't a known browser. HEAD requests always count as
+// probes there so they get the same stable response.
+func (s *linkService) isScannerRequest(host string, click models.ClickContext) bool {
+	if !containsHost(s.cfg.App.ScannerFriendlyDomains, host) {
+		return false
+	}
+	return click.Head || utils.IsLinkScanner(click.UserAgent) || !utils.IsBrowserUserAgent(click.UserAgent)
+}
+
+func containsHost(hosts []string, host string) bool {
+	return slices.ContainsFunc(hosts, func(h string) bool {
+		return strings.EqualFold(strings.TrimSpace(h), host)
+	})
+}
+
+// hasConsent reports whether marketing params may be passed on. Without CONSENT_REQUIRED every
+// click counts as consented.
+func (s *linkService) hasConsent(click models.ClickContext) bool {
+	if !s.cfg.App.ConsentRequired {
+		return true
+	}
+	if click.TCString != "" && utils.TCFPurposesConsented(click.TCString, requiredTCFPurposes...) {
+		return true
+	}
+	switch strings.ToLower(strings.TrimSpace(click.ConsentValue)) {
+	case "1", "true", "yes", "granted":
+		return true
+	}
+	return false
+}
+
+// buildDestination returns link with the stored marketing params appended, or with all
+// marketing params and click identifiers removed when there is no consent.
+func buildDestination(link string, params url.Values, consented bool) (string, error) {
+	u, err := url.Parse(link)
+	if err != nil || link == "" {
+		return "", fmt.Errorf("invalid stored link %q", link)
+	}
+
+	query := u.Query()
+	for _, key := range marketingParams {
+		if !consented {
+			query.Del(key)
+			continue
+		}
+		if v := params.Get(key); v != "" && query.Get(key) == "" {
+			query.Set(key, v)
+		}
+	}
+	if !consented {
+		for key := range query {
+			if slices.Contains(clickIDParams, key) {
+				query.Del(key)
+			}
+		}
+	}
+
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// GetLinkInfo describes where a link leads without counting a click. Hosts that haven't enabled
+// the link info page report the link as not found.
+func (s *linkService) GetLinkInfo(ctx context.Context, host, path string) (*models.LinkInfo, error) {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host: %w", err)
+	}
+	host = removePreviewFromHost(host)
+
+	if !containsHost(s.cfg.App.LinkInfoDomains, host) {
+		return nil, apperrors.ErrLinkNotFound
+	}
+
+	link, err := s.repo.GetLink(ctx, host, path)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := url.ParseQuery(link.QueryParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stored query params: %w", err)
+	}
+	if isReserved(params) {
+		return nil, apperrors.ErrLinkReserved
+	}
+	if err := s.checkResolvePolicy(ctx, host, path, params); err != nil {
+		return nil, err
+	}
+
+	// Marketing params are left out: they don't change where the link leads.
+	destination, err := buildDestination(params.Get("link"), params, false)
+	if err != nil {
+		return nil, err
+	}
+	u, _ := url.Parse(destination)
+
+	return &models.LinkInfo{
+		ShortLink:         fmt.Sprintf("%s://%s/%s", s.cfg.App.URLScheme, host, path),
+		Destination:       destination,
+		DestinationDomain: u.Hostname(),
+		CreatedAt:         link.CreatedAt,
+	}, nil
+}
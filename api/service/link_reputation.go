@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/reputation"
+
+	"github.com/rs/zerolog/log"
+)
+
+// WithReputation sets the URL reputation service new destinations are looked up with.
+func (s *linkService) WithReputation(checker reputation.Checker) *linkService {
+	s.reputation = checker
+	return s
+}
+
+// checkReputation looks the destination and fallback URLs of info, and the links of its geo
+// destinations, device rules and variants, up with the URL reputation service. A flagged URL rejects the link, or with URL_REPUTATION_POLICY=warn is reported as an
+// ERROR warning. When the service can't answer, the link is created unchecked unless
+// URL_REPUTATION_FAIL_OPEN is off.
+func (s *linkService) checkReputation(ctx context.Context, info models.DurableLinkInfo) ([]models.DurableLinkCreationWarning, error) {
+	destinations := []struct{ param, value string }{
+		{"link", info.Link},
+		{"afl", info.AndroidParameters.AndroidFallbackLink},
+		{"agl", info.AndroidParameters.AndroidAppGalleryLink},
+		{"ifl", info.IosParameters.IosFallbackLink},
+		{"ipfl", info.IosParameters.IosIpadFallbackLink},
+		{"ofl", info.OtherPlatformParameters.FallbackURL},
+		{"wl", info.PlatformLinks.WebLink},
+		{"al", info.PlatformLinks.AndroidLink},
+		{"il", info.PlatformLinks.IosLink},
+		{"efl", info.ExpiredLink},
+	}
+	for _, destination := range info.GeoDestinations {
+		destinations = append(destinations, struct{ param, value string }{"geoDestinations[" + destination.Country + "]", destination.Link})
+	}
+	for i, rule := range info.DeviceRules {
+		destinations = append(destinations, struct{ param, value string }{fmt.Sprintf("deviceRules[%d]", i), rule.Link})
+	}
+	for _, variant := range info.Variants {
+		destinations = append(destinations, struct{ param, value string }{"variants[" + variant.Name + "]", variant.Link})
+	}
+
+	// The same URL is often given for several params; it is looked up once.
+	var urls []string
+	params := map[string][]string{}
+	for _, d := range destinations {
+		if d.value == "" {
+			continue
+		}
+		if _, ok := params[d.value]; !ok {
+			urls = append(urls, d.value)
+		}
+		params[d.value] = append(params[d.value], d.param)
+	}
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	matches, err := s.lookUpReputation(ctx, urls)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := []models.DurableLinkCreationWarning{}
+	for _, match := range matches {
+		flagged := fmt.Sprintf("URL '%s'", match.URL)
+		switch names := params[match.URL]; len(names) {
+		case 0:
+		case 1:
+			flagged = fmt.Sprintf("Param '%s'", names[0])
+		default:
+			flagged = fmt.Sprintf("Params '%s'", strings.Join(names, "', '"))
+		}
+
+		log.Ctx(ctx).Warn().
+			Strs("params", params[match.URL]).
+			Str("threat", match.Threat).
+			Msg("Destination flagged by URL reputation check")
+
+		if s.cfg.App.URLReputationPolicy != "warn" {
+			return nil, fmt.Errorf("%s: destination flagged as %s: %w", flagged, match.Threat, apperrors.ErrUnsafeURL)
+		}
+		warnings = append(warnings, models.DurableLinkCreationWarning{
+			WarningCode:    WarningUnsafeURL,
+			WarningMessage: fmt.Sprintf("%s: destination flagged as %s", flagged, match.Threat),
+			Severity:       models.WarningSeverityError,
+		})
+	}
+	return warnings, nil
+}
+
+// lookUpReputation returns the urls the URL reputation service flags. When the service can't
+// answer it fails with ErrReputationUnavailable, or flags nothing with URL_REPUTATION_FAIL_OPEN.
+func (s *linkService) lookUpReputation(ctx context.Context, urls []string) ([]reputation.Match, error) {
+	matches, err := s.reputation.Check(ctx, urls)
+	if err != nil {
+		if !s.cfg.App.URLReputationFailOpen {
+			return nil, fmt.Errorf("%w: %w", apperrors.ErrReputationUnavailable, err)
+		}
+		log.Ctx(ctx).Warn().Err(err).Msg("URL reputation check failed, destinations left unchecked")
+		return nil, nil
+	}
+	return matches, nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/reputation"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeChecker struct {
+	flagged map[string]string // URL -> threat
+	err     error
+	checked []string
+}
+
+func (f *fakeChecker) Check(_ context.Context, urls []string) ([]reputation.Match, error) {
+	f.checked = urls
+	if f.err != nil {
+		return nil, f.err
+	}
+	var matches []reputation.Match
+	for _, u := range urls {
+		if threat, ok := f.flagged[u]; ok {
+			matches = append(matches, reputation.Match{URL: u, Threat: threat})
+		}
+	}
+	return matches, nil
+}
+
+func TestCheckReputation(t *testing.T) {
+	info := models.DurableLinkInfo{
+		Link:                    "https://example.com/welcome",
+		AndroidParameters:       models.AndroidParameters{AndroidFallbackLink: "https://bad.example/"},
+		OtherPlatformParameters: models.OtherPlatformParameters{FallbackURL: "https://bad.example/"},
+	}
+
+	tests := []struct {
+		name         string
+		policy       string
+		failOpen     bool
+		err          error
+		wantWarnings int
+		wantErr      error
+	}{
+		{name: "block", policy: "block", wantErr: apperrors.ErrUnsafeURL},
+		{name: "warn", policy: "warn", wantWarnings: 1},
+		{name: "unavailable", policy: "block", err: errors.New("timeout"), wantErr: apperrors.ErrReputationUnavailable},
+		{name: "unavailable, fail open", policy: "block", failOpen: true, err: errors.New("timeout")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := &fakeChecker{flagged: map[string]string{"https://bad.example/": "SOCIAL_ENGINEERING"}, err: tt.err}
+			service := &linkService{
+				cfg:        &config.Config{App: &config.AppConfig{URLReputationPolicy: tt.policy, URLReputationFailOpen: tt.failOpen}},
+				reputation: checker,
+			}
+			warnings, err := service.checkReputation(context.Background(), info)
+			assert.Equal(t, []string{"https://example.com/welcome", "https://bad.example/"}, checker.checked, "each URL is looked up once")
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, warnings, tt.wantWarnings)
+			for _, warning := range warnings {
+				assert.Equal(t, WarningUnsafeURL, warning.WarningCode)
+				assert.Equal(t, models.WarningSeverityError, warning.Severity)
+				assert.Equal(t, "Params 'afl', 'ofl': destination flagged as SOCIAL_ENGINEERING", warning.WarningMessage)
+			}
+		})
+	}
+}
+
+func TestCheckReputation_RuleDestinations(t *testing.T) {
+	checker := &fakeChecker{flagged: map[string]string{"https://example.com/bad": "MALWARE"}}
+	service := &linkService{
+		cfg:        &config.Config{App: &config.AppConfig{URLReputationPolicy: "warn"}},
+		reputation: checker,
+	}
+	warnings, err := service.checkReputation(context.Background(), models.DurableLinkInfo{
+		Link:            "https://example.com/",
+		GeoDestinations: []models.GeoDestination{{Country: "DE", Link: "https://example.com/de"}},
+		DeviceRules:     []models.DeviceRule{{OS: "ios", Param: "ifl"}, {OS: "android", Link: "https://example.com/bad"}},
+		Variants:        []models.LinkVariant{{Name: "a", Link: "https://example.com/a"}, {Name: "b", Link: "https://example.com/bad"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com/", "https://example.com/de", "https://example.com/bad", "https://example.com/a"}, checker.checked)
+	if assert.Len(t, warnings, 1) {
+		assert.Equal(t, "Params 'deviceRules[1]', 'variants[b]': destination flagged as MALWARE", warnings[0].WarningMessage)
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/webhooks"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ReserveLink takes path on host for a link whose destination isn't known yet, e.g. to print its
+// QR code ahead of a campaign. The link is stored without params; setting its link with
+// UpdateLink makes it resolve.
+func (s *linkService) ReserveLink(ctx context.Context, host, path string) (*models.StoredLinkResponse, error) {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return nil, apperrors.ErrMissingHost
+	}
+
+	release, err := s.acquireTenant(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err := s.checkCustomPath(ctx, host, path); err != nil {
+		return nil, err
+	}
+	if err := s.createShortLink(ctx, host, path, "", false); err != nil {
+		return nil, fmt.Errorf("failed to store link: %w", err)
+	}
+	s.publishLink(ctx, webhooks.EventLinkCreated, host, path, "")
+
+	log.Ctx(ctx).Info().
+		Str("host", host).
+		Str("path", path).
+		Msg("Link reserved")
+
+	return s.linkResponse(models.StoredLink{Host: host, Path: path}), nil
+}
+
+// isReserved reports whether a link with the given params was reserved and has no destination
+// yet.
+func isReserved(params url.Values) bool {
+	return params.Get("link") == ""
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/webhooks"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+// URL params a rewrite rule may be applied to.
+var rewritableParams = []string{"link", "afl", "ifl", "ipfl", "ofl"}
+
+func (s *linkService) RewriteDestinations(ctx context.Context, req models.RewriteLinksRequest) (*models.RewriteLinksResponse, error) {
+	host, err := utils.CleanHost(req.Host)
+	if err != nil {
+		return nil, apperrors.ErrMissingHost
+	}
+	if req.Match == "" {
+		return nil, fmt.Errorf("%w: match is required", apperrors.ErrInvalidRewriteRule)
+	}
+	re, err := regexp.Compile(req.Match)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrInvalidRewriteRule, err)
+	}
+
+	release, err := s.acquireTenant(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	params := req.Params
+	if len(params) == 0 {
+		params = []string{"link"}
+	}
+	for _, p := range params {
+		if !slices.Contains(rewritableParams, p) {
+			return nil, fmt.Errorf("%w: param '%s' can't be rewritten", apperrors.ErrInvalidRewriteRule, p)
+		}
+	}
+
+	settings, err := s.linkSettings(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := s.repo.ListLinksByHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.RewriteLinksResponse{
+		DryRun:  req.DryRun,
+		Changes: []models.LinkRewrite{},
+	}
+
+	// A change is only applied once every rewritten URL is looked up, all in one call.
+	type candidate struct {
+		values url.Values
+		change int
+	}
+	var candidates []candidate
+	var rewritten []string
+	for _, link := range links {
+		values, err := url.ParseQuery(link.QueryParams)
+		if err != nil {
+			log.Ctx(ctx).Warn().
+				Err(err).
+				Str("path", link.Path).
+				Msg("Skipping link with unparsable query params")
+			continue
+		}
+
+		for _, p := range params {
+			before := values.Get(p)
+			if before == "" || !re.MatchString(before) {
+				continue
+			}
+			after := re.ReplaceAllString(before, req.Replace)
+			if after == before {
+				continue
+			}
+
+			resp.Matched++
+			change := models.LinkRewrite{Path: link.Path, Param: p, Before: before, After: after}
+			if err := validateRewrittenURL(p, after, settings); err != nil {
+				change.Error = err.Error()
+				resp.Changes = append(resp.Changes, change)
+				continue
+			}
+			candidates = append(candidates, candidate{values: values, change: len(resp.Changes)})
+			if !slices.Contains(rewritten, after) {
+				rewritten = append(rewritten, after)
+			}
+			resp.Changes = append(resp.Changes, change)
+		}
+	}
+
+	// Rewritten destinations get the URL reputation check of new ones, dry runs included.
+	threats := map[string]string{}
+	if len(rewritten) > 0 {
+		matches, err := s.lookUpReputation(ctx, rewritten)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			threats[match.URL] = match.Threat
+		}
+	}
+
+	var updates []models.StoredLink
+	for _, c := range candidates {
+		change := &resp.Changes[c.change]
+		if threat, ok := threats[change.After]; ok {
+			flagged := "destination flagged as " + threat
+			if s.cfg.App.URLReputationPolicy != "warn" {
+				change.Error = fmt.Sprintf("%s: %s", flagged, apperrors.ErrUnsafeURL)
+				continue
+			}
+			change.Warning = flagged
+		}
+		c.values.Set(change.Param, change.After)
+		resp.Rewritten++
+		if n := len(updates); n == 0 || updates[n-1].Path != change.Path {
+			updates = append(updates, models.StoredLink{Host: host, Path: change.Path})
+		}
+		updates[len(updates)-1].QueryParams = c.values.Encode()
+	}
+
+	if req.DryRun || len(updates) == 0 {
+		return resp, nil
+	}
+
+	reason := fmt.Sprintf("rewrite %q -> %q", req.Match, req.Replace)
+	if err := s.repo.UpdateQueryParams(ctx, updates, reason); err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Info().
+		Str("host", host).
+		Int("links", len(updates)).
+		Str("reason", reason).
+		Msg("Rewrote link destinations")
+	for _, update := range updates {
+		s.publishLink(ctx, webhooks.EventLinkUpdated, host, update.Path, update.QueryParams)
+	}
+
+	return resp, nil
+}
+
+// validateRewrittenURL applies the same checks a rewritten URL would have passed on creation.
+func validateRewrittenURL(param, rawURL string, settings linkSettings) error {
+	if err := utils.ValidateURLScheme(rawURL); err != nil {
+		return err
+	}
+	return validateParamDomain(param, rawURL, settings)
+}
+
+func (s *linkService) ListRevisions(ctx context.Context, host, path string) ([]models.LinkRevision, error) {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return nil, apperrors.ErrMissingHost
+	}
+	return s.repo.ListRevisions(ctx, host, path)
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewriteDestinations(t *testing.T) {
+	newRepo := func() *fakeLinkRepository {
+		return &fakeLinkRepository{links: []models.StoredLink{
+			{Host: "acme.link", Path: "a", QueryParams: "link=https%3A%2F%2Fexample.com%2Fblog%2Fspring"},
+			{Host: "acme.link", Path: "b", QueryParams: "link=https%3A%2F%2Fexample.com%2Fshop"},
+			{Host: "acme.link", Path: "c", QueryParams: "link=https%3A%2F%2Fexample.com%2Fblog%2Fsummer"},
+		}}
+	}
+	cfg := &config.Config{App: &config.AppConfig{AllowedDomains: []string{"example.com"}}}
+	req := models.RewriteLinksRequest{
+		Host:    "acme.link",
+		Match:   `^https://example\.com/blog/(.*)$`,
+		Replace: "https://example.com/articles/$1",
+	}
+
+	t.Run("dry run reports without writing", func(t *testing.T) {
+		repo := newRepo()
+		dryRun := req
+		dryRun.DryRun = true
+
+		resp, err := newTestLinkService(t, repo, cfg).RewriteDestinations(context.Background(), dryRun)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, resp.Matched)
+		assert.Equal(t, "https://example.com/articles/spring", resp.Changes[0].After)
+		assert.Empty(t, repo.updated)
+	})
+
+	t.Run("applies rewrite", func(t *testing.T) {
+		repo := newRepo()
+
+		resp, err := newTestLinkService(t, repo, cfg).RewriteDestinations(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, resp.Rewritten)
+		assert.Len(t, repo.updated, 2)
+		assert.Equal(t, "link=https%3A%2F%2Fexample.com%2Farticles%2Fsummer", repo.updated[1].QueryParams)
+	})
+
+	t.Run("rejects destinations outside the allow list", func(t *testing.T) {
+		repo := newRepo()
+		offDomain := req
+		offDomain.Replace = "https://elsewhere.com/$1"
+
+		resp, err := newTestLinkService(t, repo, cfg).RewriteDestinations(context.Background(), offDomain)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, resp.Matched)
+		assert.Equal(t, 0, resp.Rewritten)
+		assert.NotEmpty(t, resp.Changes[0].Error)
+		assert.Empty(t, repo.updated)
+	})
+
+	t.Run("rejects flagged destinations", func(t *testing.T) {
+		repo := newRepo()
+		checker := &fakeChecker{flagged: map[string]string{"https://example.com/articles/spring": "MALWARE"}}
+
+		resp, err := newTestLinkService(t, repo, cfg).WithReputation(checker).RewriteDestinations(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"https://example.com/articles/spring", "https://example.com/articles/summer"}, checker.checked)
+		assert.Equal(t, 1, resp.Rewritten)
+		assert.Contains(t, resp.Changes[0].Error, "MALWARE")
+		if assert.Len(t, repo.updated, 1) {
+			assert.Equal(t, "c", repo.updated[0].Path)
+		}
+	})
+
+	t.Run("flagged destinations in a dry run", func(t *testing.T) {
+		repo := newRepo()
+		dryRun := req
+		dryRun.DryRun = true
+		checker := &fakeChecker{flagged: map[string]string{"https://example.com/articles/spring": "MALWARE"}}
+
+		resp, err := newTestLinkService(t, repo, cfg).WithReputation(checker).RewriteDestinations(context.Background(), dryRun)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, resp.Rewritten)
+		assert.Contains(t, resp.Changes[0].Error, "MALWARE")
+		assert.Empty(t, repo.updated)
+	})
+
+	t.Run("warns about flagged destinations", func(t *testing.T) {
+		repo := newRepo()
+		warnCfg := &config.Config{App: &config.AppConfig{AllowedDomains: []string{"example.com"}, URLReputationPolicy: "warn"}}
+		checker := &fakeChecker{flagged: map[string]string{"https://example.com/articles/spring": "MALWARE"}}
+
+		resp, err := newTestLinkService(t, repo, warnCfg).WithReputation(checker).RewriteDestinations(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, resp.Rewritten)
+		assert.Empty(t, resp.Changes[0].Error)
+		assert.Contains(t, resp.Changes[0].Warning, "MALWARE")
+		assert.Len(t, repo.updated, 2)
+	})
+
+	t.Run("reputation service down", func(t *testing.T) {
+		repo := newRepo()
+		checker := &fakeChecker{err: errors.New("timeout")}
+
+		_, err := newTestLinkService(t, repo, cfg).WithReputation(checker).RewriteDestinations(context.Background(), req)
+		assert.ErrorIs(t, err, apperrors.ErrReputationUnavailable)
+		assert.Empty(t, repo.updated)
+	})
+
+	t.Run("invalid regex", func(t *testing.T) {
+		bad := req
+		bad.Match = "("
+
+		_, err := newTestLinkService(t, newRepo(), cfg).RewriteDestinations(context.Background(), bad)
+		assert.ErrorIs(t, err, apperrors.ErrInvalidRewriteRule)
+	})
+
+	t.Run("param not rewritable", func(t *testing.T) {
+		bad := req
+		bad.Params = []string{"apn"}
+
+		_, err := newTestLinkService(t, newRepo(), cfg).RewriteDestinations(context.Background(), bad)
+		assert.ErrorIs(t, err, apperrors.ErrInvalidRewriteRule)
+	})
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/utils"
+)
+
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 200
+)
+
+// SearchLinks finds links by words or fragments of their destination, social title and
+// description, deep link route, path, name, notes or tags.
+func (s *linkService) SearchLinks(ctx context.Context, req models.SearchRequest) (*models.SearchResponse, error) {
+	req, err := normalizeSearch(req)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	limit = min(limit, maxSearchLimit)
+
+	links, err := s.repo.SearchLinks(ctx, req.Host, req.Query, req.Sort, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.SearchResult, 0, len(links))
+	for _, link := range links {
+		params, err := url.ParseQuery(link.QueryParams)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stored query params: %w", err)
+		}
+		results = append(results, models.SearchResult{
+			ShortLink:   fmt.Sprintf("%s://%s/%s", s.cfg.App.URLScheme, link.Host, link.Path),
+			Host:        link.Host,
+			Path:        link.Path,
+			Link:        params.Get("link"),
+			Title:       params.Get("st"),
+			Description: params.Get("sd"),
+			CreatedAt:   link.CreatedAt,
+		})
+	}
+	return &models.SearchResponse{Results: results}, nil
+}
+
+// normalizeSearch trims the query, cleans the host and defaults the sort order, rejecting
+// requests that can't be run.
+func normalizeSearch(req models.SearchRequest) (models.SearchRequest, error) {
+	req.Query = strings.TrimSpace(req.Query)
+	if req.Query == "" {
+		return req, apperrors.ErrMissingQuery
+	}
+
+	if req.Host != "" {
+		host, err := utils.CleanHost(req.Host)
+		if err != nil {
+			return req, apperrors.ErrMissingHost
+		}
+		req.Host = host
+	}
+
+	switch req.Sort {
+	case "":
+		req.Sort = models.SortRelevance
+	case models.SortRelevance, models.SortNewest, models.SortOldest:
+	default:
+		return req, apperrors.ErrInvalidSort
+	}
+	return req, nil
+}
@@ -4,35 +4,161 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/attribution"
+	"durable-links-generator/api/clicks"
+	"durable-links-generator/api/device"
+	"durable-links-generator/api/geoip"
+	"durable-links-generator/api/limiter"
 	"durable-links-generator/api/models"
+	"durable-links-generator/api/notify"
+	"durable-links-generator/api/pathgen"
+	"durable-links-generator/api/policy"
 	"durable-links-generator/api/repository"
+	"durable-links-generator/api/reputation"
+	"durable-links-generator/api/signedlinks"
+	"durable-links-generator/api/webhooks"
 	"durable-links-generator/config"
 	"durable-links-generator/utils"
 
 	"github.com/rs/zerolog/log"
 )
 
+// Interstitials a link or domain can require before redirecting.
+var interstitialGates = []string{"age", "terms"}
+
 type LinkService interface {
 	CreateDurableLink(ctx context.Context, params models.CreateDurableLinkRequest) (*models.ShortLinkResponse, error)
-	ParseLongDurableLink(longLink string) (models.CreateDurableLinkRequest, error)
-	ResolveShortPath(ctx context.Context, rawURL string) (*models.LongLinkResponse, error)
-	PrepareDurableLinkRequest(input map[string]any) (models.CreateDurableLinkRequest, error)
+	CreateDurableLinks(ctx context.Context, requests []models.CreateDurableLinkRequest) ([]BatchResult, error)
+	ParseLongDurableLink(ctx context.Context, longLink string) (models.CreateDurableLinkRequest, error)
+	ResolveShortPath(ctx context.Context, rawURL, platform string, click models.ClickContext) (*models.LongLinkResponse, error)
+	ResolveRedirect(ctx context.Context, host, path string, click models.ClickContext) (*models.RedirectTarget, error)
+	GetLinkInfo(ctx context.Context, host, path string) (*models.LinkInfo, error)
+	ResolveCode(ctx context.Context, host, code string) (string, error)
+	GetLinkCard(ctx context.Context, host, path string) (*models.LinkCard, error)
+	PrepareDurableLinkRequest(ctx context.Context, input map[string]any) (models.CreateDurableLinkRequest, error)
+	RewriteDestinations(ctx context.Context, req models.RewriteLinksRequest) (*models.RewriteLinksResponse, error)
+	ListRevisions(ctx context.Context, host, path string) ([]models.LinkRevision, error)
+	SearchLinks(ctx context.Context, req models.SearchRequest) (*models.SearchResponse, error)
+	ListLinks(ctx context.Context, req models.ListLinksRequest) (*models.ListLinksResponse, error)
+	CreateAlias(ctx context.Context, path string, req models.CreateAliasRequest) (*models.AliasesResponse, error)
+	ListAliases(ctx context.Context, host, path string) (*models.AliasesResponse, error)
+	DeleteAlias(ctx context.Context, host, aliasPath string) error
+	GeoDestinations(ctx context.Context, host, path string) (*models.GeoDestinationsResponse, error)
+	SetGeoDestinations(ctx context.Context, path string, req models.SetGeoDestinationsRequest) (*models.GeoDestinationsResponse, error)
+	DeleteLink(ctx context.Context, host, path string, hard bool) error
+	RestoreLink(ctx context.Context, host, path string) error
+	GetLink(ctx context.Context, host, path string) (*models.StoredLinkResponse, error)
+	UpdateLink(ctx context.Context, host, path, ifMatch string, req models.UpdateLinkRequest) (*models.StoredLinkResponse, error)
+	ReserveLink(ctx context.Context, host, path string) (*models.StoredLinkResponse, error)
+	MergeDuplicates(ctx context.Context, req models.MergeLinksRequest) (*models.MergeLinksResponse, error)
+	SupersedeLink(ctx context.Context, path string, params models.CreateDurableLinkRequest, redirect bool) (*models.SupersedeLinkResponse, error)
+	LinkStats(ctx context.Context, req models.LinkStatsRequest) (*models.LinkStatsResponse, error)
+	QRCodeLink(ctx context.Context, host, path string) (string, error)
+	CreateSignedLink(ctx context.Context, req models.CreateSignedLinkRequest) (*models.SignedLinkResponse, error)
+	ResolveSignedLink(ctx context.Context, host, token string, click models.ClickContext) (*models.RedirectTarget, error)
 }
 
 type linkService struct {
-	repo repository.LinkRepository
-	cfg  *config.Config
+	repo          repository.LinkRepository
+	cfg           *config.Config
+	tenants       *limiter.Tenants
+	pathGenerator pathgen.PathGenerator
+	policy        *policy.Chain
+	notifier      notify.Notifier
+	detector      device.Detector
+	clicks        clicks.Recorder
+	clickEvents   clicks.EventRecorder
+	installClicks attribution.Recorder
+	domainConfigs *DomainConfigs
+	webhooks      webhooks.Publisher
+	utmPresets    repository.UTMPresetRepository
+	locator       geoip.Locator
+	signer        *signedlinks.Signer
+	reputation    reputation.Checker
 }
 
-func NewLinkService(repo repository.LinkRepository, cfg *config.Config) *linkService {
+func NewLinkService(repo repository.LinkRepository, cfg *config.Config) (*linkService, error) {
+	pathGenerator, err := pathgen.New(cfg, repo)
+	if err != nil {
+		return nil, fmt.Errorf("set up path generator: %w", err)
+	}
+	policyChain, err := policy.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("set up policy hooks: %w", err)
+	}
+	if err := validateDevicePolicies(cfg.App.DevicePolicies); err != nil {
+		return nil, fmt.Errorf("invalid DEVICE_POLICIES: %w", err)
+	}
+
 	return &linkService{
-		repo: repo,
-		cfg:  cfg,
+		repo:          repo,
+		cfg:           cfg,
+		tenants:       newTenantLimits(cfg),
+		pathGenerator: pathGenerator,
+		policy:        policyChain,
+		notifier:      notify.Nop{},
+		detector:      device.Default,
+		clicks:        clicks.Nop{},
+		clickEvents:   clicks.Nop{},
+		installClicks: attribution.Nop{},
+		webhooks:      webhooks.Nop{},
+		locator:       geoip.Nop{},
+		reputation:    reputation.Nop{},
+	}, nil
+}
+
+// WithNotifier sets where operational alerts raised by the service go.
+func (s *linkService) WithNotifier(notifier notify.Notifier) *linkService {
+	s.notifier = notifier
+	return s
+}
+
+// WithDetector replaces the built-in device detection used on redirects.
+func (s *linkService) WithDetector(detector device.Detector) *linkService {
+	s.detector = detector
+	return s
+}
+
+// WithInstallClicks sets where clicks on links with an app are kept for install attribution.
+func (s *linkService) WithInstallClicks(recorder attribution.Recorder) *linkService {
+	s.installClicks = recorder
+	return s
+}
+
+// WithClickEvents sets where every resolution of a link is logged for click analytics.
+func (s *linkService) WithClickEvents(recorder clicks.EventRecorder) *linkService {
+	s.clickEvents = recorder
+	return s
+}
+
+// WithDomainConfigs makes the service read each host's allow list and default apps from the
+// domains table instead of the env settings.
+func (s *linkService) WithDomainConfigs(configs *DomainConfigs) *linkService {
+	s.domainConfigs = configs
+	return s
+}
+
+// WithClickRecorder sets where clicks on redirects are counted.
+func (s *linkService) WithClickRecorder(recorder clicks.Recorder) *linkService {
+	s.clicks = recorder
+	return s
+}
+
+func (s *linkService) notify(ctx context.Context, event notify.Event) {
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.Notify(ctx, event); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("kind", event.Kind).Msg("Failed to queue notification")
 	}
 }
 
@@ -40,49 +166,256 @@ func (s *linkService) getLongLinkFromHostAndPath(
 	ctx context.Context,
 	host string,
 	path string,
+	platform string,
 ) (*models.LongLinkResponse, error) {
 	rawQueryStr, err := s.repo.GetQueryParamsByHostAndPath(ctx, host, path)
 	if err != nil {
 		return nil, err
 	}
+	// The long link passes the stored params on verbatim, so a malformed pair doesn't fail the
+	// exchange; hooks see whatever could be parsed.
+	params, _ := url.ParseQuery(rawQueryStr)
+	if isReserved(params) {
+		return nil, apperrors.ErrLinkReserved
+	}
+	if err := checkActiveWindow(params, time.Now()); err != nil {
+		return s.expiredLongLink(host, path, platform, params, err)
+	}
+	if err := s.checkResolvePolicy(ctx, host, path, params); err != nil {
+		return nil, err
+	}
+	// An exchange is the click of an app opened by the link, so it uses up the link like one.
+	if err := s.useClick(ctx, host, path, params); err != nil {
+		return s.expiredLongLink(host, path, platform, params, err)
+	}
 
 	longLink := fmt.Sprintf("%s://%s/%s", s.cfg.App.URLScheme, host, path)
-	if rawQueryStr != "" {
-		longLink += "?" + rawQueryStr
+	if rawQuery := withoutParams(rawQueryStr, limitParams); rawQuery != "" {
+		longLink += "?" + rawQuery
 	}
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Str("path", path).
 		Str("long_link", longLink).
 		Msg("Link retrieved from service")
 
-	return &models.LongLinkResponse{
+	response := &models.LongLinkResponse{
 		LongLink: longLink,
-	}, nil
+	}
+	if platform != "" {
+		response.Link = platformLink(params, platform)
+	}
+	return response, nil
 }
 
 func (s *linkService) CreateDurableLink(ctx context.Context, params models.CreateDurableLinkRequest) (*models.ShortLinkResponse, error) {
-	warnings := []models.DurableLinkCreationWarning{}
+	response, _, err := s.createDurableLink(ctx, params)
+	return response, err
+}
 
-	log.Debug().
+// createDurableLink validates params and stores the link, returning the path it was stored under
+// along with the response.
+func (s *linkService) createDurableLink(ctx context.Context, params models.CreateDurableLinkRequest) (*models.ShortLinkResponse, string, error) {
+	log.Ctx(ctx).Debug().
 		Str("params", fmt.Sprintf("%+v", params)).
 		Msg("Durable link parameters")
 
+	host, err := cleanLinkHost(params)
+	if err != nil {
+		return nil, "", err
+	}
+
+	release, err := s.acquireTenant(ctx, host)
+	if err != nil {
+		return nil, "", err
+	}
+	defer release()
+
+	plan, err := s.planDurableLink(ctx, host, params)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var response *models.ShortLinkResponse
+	var path string
+	if plan.custom {
+		path = plan.customPath
+		response, err = s.createCustomShortLink(ctx, host, plan.queryParams, path)
+	} else {
+		// A link given its own metadata, geo destinations, device rules or variants is new, not an
+		// existing link with the same params.
+		reuse := plan.shortPath && plan.reusable()
+		response, path, err = s.createOrGetShortLink(ctx, host, plan.queryParams, plan.shortPath, reuse)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if !plan.metadata.IsZero() {
+		if err := s.repo.SetLinkMetadata(ctx, host, path, plan.metadata); err != nil {
+			return nil, "", fmt.Errorf("failed to store link metadata: %w", err)
+		}
+	}
+	if len(plan.geoDestinations) > 0 {
+		if err := s.repo.SetGeoDestinations(ctx, host, path, plan.geoDestinations); err != nil {
+			return nil, "", fmt.Errorf("failed to store geo destinations: %w", err)
+		}
+	}
+	if len(plan.deviceRules) > 0 {
+		if err := s.repo.SetDeviceRules(ctx, host, path, plan.deviceRules); err != nil {
+			return nil, "", fmt.Errorf("failed to store device rules: %w", err)
+		}
+	}
+	if len(plan.variants) > 0 {
+		if err := s.repo.SetLinkVariants(ctx, host, path, plan.variants); err != nil {
+			return nil, "", fmt.Errorf("failed to store variants: %w", err)
+		}
+	}
+
+	if plan.numericCode {
+		if response.Code, err = s.assignCode(ctx, host, path); err != nil {
+			return nil, "", err
+		}
+	}
+
+	response.Warnings = plan.warnings
+	return response, path, nil
+}
+
+// validatePolicyChanges checks the URLs a BeforeCreate hook changed from requested like those of
+// the request, so that policies can't send links off the allow lists or to unsafe destinations.
+func (s *linkService) validatePolicyChanges(ctx context.Context, requested, params url.Values, settings linkSettings) ([]models.DurableLinkCreationWarning, error) {
+	changed := url.Values{}
+	for param := range params {
+		if value := params.Get(param); value != "" && value != requested.Get(param) {
+			changed.Set(param, value)
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	info := destinationInfo(changed)
+	if err := validateDestinations(info, settings); err != nil {
+		return nil, fmt.Errorf("link as changed by policy: %w", err)
+	}
+
+	warnings, err := s.scanForPII(info)
+	if err != nil {
+		return nil, err
+	}
+	reputationWarnings, err := s.checkReputation(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+	return append(warnings, reputationWarnings...), nil
+}
+
+// validateDestinations runs the allow list and scheme checks of planDurableLink over the URLs
+// of info that are set.
+func validateDestinations(info models.DurableLinkInfo, settings linkSettings) error {
+	if info.Link != "" {
+		if err := validateParamDomain("link", info.Link, settings); err != nil {
+			return err
+		}
+	}
+	if err := validatePlatformLinks(info, settings.allowedDomains); err != nil {
+		return err
+	}
+	if err := validateParamDomains(info, settings); err != nil {
+		return err
+	}
+	return validateClickLimit(info, settings.allowedDomains)
+}
+
+func cloneValues(values url.Values) url.Values {
+	clone := make(url.Values, len(values))
+	for key, vs := range values {
+		clone[key] = slices.Clone(vs)
+	}
+	return clone
+}
+
+func cleanLinkHost(params models.CreateDurableLinkRequest) (string, error) {
 	host, err := utils.CleanHost(params.DurableLinkInfo.Host)
 	if err != nil {
 		log.Error().
 			Str("host", params.DurableLinkInfo.Host).
 			Msg("Invalid host")
-		return nil, fmt.Errorf("invalid host: %w", err)
+		return "", fmt.Errorf("invalid host: %w", err)
 	}
+	return host, nil
+}
 
-	if !utils.IsDomainAllowed(s.cfg.App.AllowedDomains, params.DurableLinkInfo.Link) {
-		log.Error().
+// linkPlan is a validated link request, ready to be stored.
+type linkPlan struct {
+	host        string
+	queryParams url.Values
+	shortPath   bool
+	custom      bool
+	customPath  string
+	numericCode bool
+	metadata    models.LinkMetadata
+	// geoDestinations, deviceRules and variants are stored next to the link once it is created.
+	geoDestinations []models.GeoDestination
+	deviceRules     []models.DeviceRule
+	variants        []models.LinkVariant
+	warnings        []models.DurableLinkCreationWarning
+}
+
+// reusable reports whether an existing link with the same params can stand in for the planned
+// one, which holds when it has nothing stored beside its params. A link with a click limit has
+// its own count of clicks.
+func (p *linkPlan) reusable() bool {
+	return p.metadata.IsZero() && len(p.geoDestinations) == 0 && len(p.deviceRules) == 0 && len(p.variants) == 0 &&
+		p.queryParams.Get("mc") == ""
+}
+
+// planDurableLink validates params and builds the query params the link is stored with, without
+// touching the links stored so far.
+func (s *linkService) planDurableLink(ctx context.Context, host string, params models.CreateDurableLinkRequest) (*linkPlan, error) {
+	warnings := []models.DurableLinkCreationWarning{}
+
+	settings, err := s.linkSettings(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	if !utils.IsDomainAllowed(settings.allowedDomains, params.DurableLinkInfo.Link) {
+		log.Ctx(ctx).Error().
 			Str("link", params.DurableLinkInfo.Link).
 			Msg("Domain link not in allow list")
 		return nil, apperrors.ErrDomainLinkNotAllowed
 	}
 
+	if err := validatePlatformLinks(params.DurableLinkInfo, settings.allowedDomains); err != nil {
+		return nil, err
+	}
+	if err := validateParamDomains(params.DurableLinkInfo, settings); err != nil {
+		return nil, err
+	}
+
+	geoDestinations, err := normalizeGeoDestinations(params.DurableLinkInfo.GeoDestinations, settings.allowedDomains)
+	if err != nil {
+		return nil, err
+	}
+	variants, err := normalizeLinkVariants(params.DurableLinkInfo.Variants, settings.allowedDomains)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateClickLimit(params.DurableLinkInfo, settings.allowedDomains); err != nil {
+		return nil, err
+	}
+	if err := validateActiveWindow(params.DurableLinkInfo); err != nil {
+		return nil, err
+	}
+
+	piiWarnings, err := s.scanForPII(params.DurableLinkInfo)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, piiWarnings...)
+	warnings = append(warnings, linkWarnings(params.DurableLinkInfo)...)
+
 	isi := params.DurableLinkInfo.IosParameters.IosAppStoreId
 
 	if isi != "" {
@@ -91,6 +424,21 @@ func (s *linkService) CreateDurableLink(ctx context.Context, params models.Creat
 		}
 	}
 
+	gate := params.DurableLinkInfo.Gate
+	if gate != "" && !slices.Contains(interstitialGates, gate) {
+		return nil, apperrors.ErrInvalidGate
+	}
+
+	metadata, err := normalizeMetadata(params.LinkMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	marketing, err := s.marketingParameters(ctx, host, params.DurableLinkInfo.AnalyticsInfo)
+	if err != nil {
+		return nil, err
+	}
+
 	queryParams := url.Values{}
 	queryParams.Add("link", params.DurableLinkInfo.Link)
 
@@ -103,6 +451,7 @@ func (s *linkService) CreateDurableLink(ctx context.Context, params models.Creat
 	addParam("apn", params.DurableLinkInfo.AndroidParameters.AndroidPackageName)
 	addParam("afl", params.DurableLinkInfo.AndroidParameters.AndroidFallbackLink)
 	addParam("amv", params.DurableLinkInfo.AndroidParameters.AndroidMinPackageVersionCode)
+	addParam("agl", params.DurableLinkInfo.AndroidParameters.AndroidAppGalleryLink)
 
 	addParam("ifl", params.DurableLinkInfo.IosParameters.IosFallbackLink)
 	addParam("ipfl", params.DurableLinkInfo.IosParameters.IosIpadFallbackLink)
@@ -110,96 +459,185 @@ func (s *linkService) CreateDurableLink(ctx context.Context, params models.Creat
 
 	addParam("ofl", params.DurableLinkInfo.OtherPlatformParameters.FallbackURL)
 
+	addParam("dpl", params.DurableLinkInfo.DesktopParameters.DesktopProtocolLink)
+	addParam("msl", params.DurableLinkInfo.DesktopParameters.MicrosoftStoreLink)
+	addParam("masl", params.DurableLinkInfo.DesktopParameters.MacAppStoreLink)
+
+	addParam("wl", params.DurableLinkInfo.PlatformLinks.WebLink)
+	addParam("al", params.DurableLinkInfo.PlatformLinks.AndroidLink)
+	addParam("il", params.DurableLinkInfo.PlatformLinks.IosLink)
+
 	addParam("st", params.DurableLinkInfo.SocialMetaTagInfo.SocialTitle)
 	addParam("sd", params.DurableLinkInfo.SocialMetaTagInfo.SocialDescription)
 
-	si := params.DurableLinkInfo.SocialMetaTagInfo.SocialImageLink
+	addParam("si", params.DurableLinkInfo.SocialMetaTagInfo.SocialImageLink)
 
-	addParam("si", si)
+	addParam("utm_source", marketing.UtmSource)
+	addParam("utm_medium", marketing.UtmMedium)
+	addParam("utm_campaign", marketing.UtmCampaign)
+	addParam("utm_term", marketing.UtmTerm)
+	addParam("utm_content", marketing.UtmContent)
+	addParam("pt", params.DurableLinkInfo.AnalyticsInfo.ItunesConnectAnalytics.Pt)
 
-	if si != "" {
-		if !utils.IsURL(si) {
-			warnings = append(warnings, models.DurableLinkCreationWarning{
-				WarningCode:    "MALFORMED_PARAM",
-				WarningMessage: "Param 'si' is not a valid URL",
-			})
-		}
+	addParam("at", params.DurableLinkInfo.AnalyticsInfo.ItunesConnectAnalytics.At)
+	addParam("ct", params.DurableLinkInfo.AnalyticsInfo.ItunesConnectAnalytics.Ct)
+	addParam("mt", params.DurableLinkInfo.AnalyticsInfo.ItunesConnectAnalytics.Mt)
+
+	addParam("gate", gate)
+
+	if params.DurableLinkInfo.QRFirst {
+		queryParams.Add("qrf", "1")
+	}
+	if params.DurableLinkInfo.MaxClicks > 0 {
+		queryParams.Add("mc", strconv.FormatInt(params.DurableLinkInfo.MaxClicks, 10))
+	}
+	if params.DurableLinkInfo.ActiveFrom != nil {
+		queryParams.Add("af", formatActiveTime(*params.DurableLinkInfo.ActiveFrom))
+	}
+	if params.DurableLinkInfo.ActiveUntil != nil {
+		queryParams.Add("au", formatActiveTime(*params.DurableLinkInfo.ActiveUntil))
 	}
+	addParam("efl", params.DurableLinkInfo.ExpiredLink)
 
-	addParam("utm_source", params.DurableLinkInfo.AnalyticsInfo.MarketingParameters.UtmSource)
-	addParam("utm_medium", params.DurableLinkInfo.AnalyticsInfo.MarketingParameters.UtmMedium)
-	addParam("utm_campaign", params.DurableLinkInfo.AnalyticsInfo.MarketingParameters.UtmCampaign)
-	addParam("utm_term", params.DurableLinkInfo.AnalyticsInfo.MarketingParameters.UtmTerm)
-	addParam("utm_content", params.DurableLinkInfo.AnalyticsInfo.MarketingParameters.UtmContent)
-	pt := params.DurableLinkInfo.AnalyticsInfo.ItunesConnectAnalytics.Pt
-	addParam("pt", pt)
-
-	if isi == "" {
-		if at := params.DurableLinkInfo.AnalyticsInfo.ItunesConnectAnalytics.At; at != "" {
-			warnings = append(warnings, models.DurableLinkCreationWarning{
-				WarningCode:    "UNRECOGNIZED_PARAM",
-				WarningMessage: "Param 'at' is not needed, since 'isi' is not specified.",
-			})
-		}
-		if ct := params.DurableLinkInfo.AnalyticsInfo.ItunesConnectAnalytics.Ct; ct != "" {
-			warnings = append(warnings, models.DurableLinkCreationWarning{
-				WarningCode:    "UNRECOGNIZED_PARAM",
-				WarningMessage: "Param 'ct' is not needed, since 'isi' is not specified.",
-			})
-		}
-		if mt := params.DurableLinkInfo.AnalyticsInfo.ItunesConnectAnalytics.Mt; mt != "" {
-			warnings = append(warnings, models.DurableLinkCreationWarning{
-				WarningCode:    "UNRECOGNIZED_PARAM",
-				WarningMessage: "Param 'mt' is not needed, since 'isi' is not specified.",
-			})
-		}
-		if pt != "" {
-			warnings = append(warnings, models.DurableLinkCreationWarning{
-				WarningCode:    "UNRECOGNIZED_PARAM",
-				WarningMessage: "Param 'pt' is not needed, since 'isi' is not specified.",
-			})
-		}
+	route, deepLinkParams := deepLinkQueryParams(params.DurableLinkInfo.DeepLink)
+	if !validDeepLinkRoute(route) {
+		return nil, apperrors.ErrInvalidDeepLink
 	}
+	addParam("dlr", route)
+	addParam("dlp", deepLinkParams)
 
-	if pt == "" {
-		if at := params.DurableLinkInfo.AnalyticsInfo.ItunesConnectAnalytics.At; at != "" {
-			warnings = append(warnings, models.DurableLinkCreationWarning{
-				WarningCode:    "UNRECOGNIZED_PARAM",
-				WarningMessage: "Param 'at' is not needed, since 'pt' is not specified.",
-			})
-		}
-		if ct := params.DurableLinkInfo.AnalyticsInfo.ItunesConnectAnalytics.Ct; ct != "" {
-			warnings = append(warnings, models.DurableLinkCreationWarning{
-				WarningCode:    "UNRECOGNIZED_PARAM",
-				WarningMessage: "Param 'ct' is not needed, since 'pt' is not specified.",
-			})
-		}
-		if mt := params.DurableLinkInfo.AnalyticsInfo.ItunesConnectAnalytics.Mt; mt != "" {
-			warnings = append(warnings, models.DurableLinkCreationWarning{
-				WarningCode:    "UNRECOGNIZED_PARAM",
-				WarningMessage: "Param 'mt' is not needed, since 'pt' is not specified.",
-			})
-		}
+	deviceRules, err := normalizeDeviceRules(params.DurableLinkInfo.DeviceRules, queryParams, settings.allowedDomains)
+	if err != nil {
+		return nil, err
 	}
 
-	addParam("at", params.DurableLinkInfo.AnalyticsInfo.ItunesConnectAnalytics.At)
-	addParam("ct", params.DurableLinkInfo.AnalyticsInfo.ItunesConnectAnalytics.Ct)
-	addParam("mt", params.DurableLinkInfo.AnalyticsInfo.ItunesConnectAnalytics.Mt)
+	// Destinations are looked up last, so that invalid requests don't cost a call to another
+	// service.
+	checked := params.DurableLinkInfo
+	checked.GeoDestinations, checked.DeviceRules, checked.Variants = geoDestinations, deviceRules, variants
+	reputationWarnings, err := s.checkReputation(ctx, checked)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, reputationWarnings...)
+	if params.ValidateDestination {
+		warnings = append(warnings, s.checkDestination(ctx, params.DurableLinkInfo.Link, settings.allowedDomains)...)
+	}
 
-	shortPath := params.Suffix.Option == "SHORT"
-	response, err := s.createOrGetShortLink(ctx, host, queryParams, shortPath)
+	requested := cloneValues(queryParams)
+	decision := s.policy.BeforeCreate(ctx, &policy.Link{Host: host, Params: queryParams})
+	if decision.Deny {
+		return nil, fmt.Errorf("%w: %s", apperrors.ErrPolicyDenied, decision.Reason)
+	}
+	policyWarnings, err := s.validatePolicyChanges(ctx, requested, queryParams, settings)
 	if err != nil {
 		return nil, err
 	}
+	warnings = append(warnings, policyWarnings...)
+	for _, warning := range decision.Warnings {
+		warnings = append(warnings, models.DurableLinkCreationWarning{
+			WarningCode:    WarningPolicy,
+			WarningMessage: warning,
+			Severity:       models.WarningSeverityWarning,
+		})
+	}
+	if err := s.checkWarnings(warnings); err != nil {
+		return nil, err
+	}
 
-	response.Warnings = warnings
-	return response, nil
+	custom := params.Suffix.Option == "CUSTOM"
+	if !custom && params.Suffix.CustomPath != "" {
+		return nil, apperrors.ErrInvalidSuffix
+	}
+	shortPath := params.Suffix.Option == "SHORT"
+	// A short numeric code would make an unguessable link guessable.
+	if params.Suffix.NumericCode && !custom && !shortPath {
+		return nil, apperrors.ErrCodeNotAllowed
+	}
+	if params.Suffix.SMSSafe {
+		pathLength := s.cfg.App.UnguessablePathLength
+		switch {
+		case custom:
+			pathLength = len(params.Suffix.CustomPath)
+		case shortPath:
+			pathLength = s.cfg.App.ShortPathLength
+		}
+		if err := s.checkSMSBudget(host, pathLength); err != nil {
+			return nil, err
+		}
+		queryParams.Set("sms", "1")
+	}
+
+	return &linkPlan{
+		host:            host,
+		queryParams:     queryParams,
+		shortPath:       shortPath,
+		custom:          custom,
+		customPath:      params.Suffix.CustomPath,
+		numericCode:     params.Suffix.NumericCode,
+		metadata:        metadata,
+		geoDestinations: geoDestinations,
+		deviceRules:     deviceRules,
+		variants:        variants,
+		warnings:        warnings,
+	}, nil
+}
+
+// scanForPII looks for personal data in the destination and fallback URLs. Depending on the
+// configured policy a match is reported as a warning or rejects the link.
+func (s *linkService) scanForPII(info models.DurableLinkInfo) ([]models.DurableLinkCreationWarning, error) {
+	policy := s.cfg.App.PIIScanPolicy
+	if policy != "warn" && policy != "block" {
+		return nil, nil
+	}
+
+	destinations := []struct {
+		param string
+		value string
+	}{
+		{"link", info.Link},
+		{"afl", info.AndroidParameters.AndroidFallbackLink},
+		{"agl", info.AndroidParameters.AndroidAppGalleryLink},
+		{"ifl", info.IosParameters.IosFallbackLink},
+		{"ipfl", info.IosParameters.IosIpadFallbackLink},
+		{"ofl", info.OtherPlatformParameters.FallbackURL},
+		{"wl", info.PlatformLinks.WebLink},
+		{"al", info.PlatformLinks.AndroidLink},
+		{"il", info.PlatformLinks.IosLink},
+		{"dpl", info.DesktopParameters.DesktopProtocolLink},
+	}
+
+	warnings := []models.DurableLinkCreationWarning{}
+	for _, d := range destinations {
+		if d.value == "" {
+			continue
+		}
+		found := utils.FindPII(d.value)
+		if len(found) == 0 {
+			continue
+		}
+
+		log.Warn().
+			Str("param", d.param).
+			Strs("fields", found).
+			Msg("Personal data detected in destination")
+
+		if policy == "block" {
+			return nil, fmt.Errorf("param '%s': %w", d.param, apperrors.ErrPIIDetected)
+		}
+		warnings = append(warnings, models.DurableLinkCreationWarning{
+			WarningCode:    WarningPIIDetected,
+			WarningMessage: fmt.Sprintf("Param '%s' looks like it contains personal data in: %s", d.param, strings.Join(found, ", ")),
+			Severity:       models.WarningSeverityWarning,
+		})
+	}
+	return warnings, nil
 }
 
-func (s *linkService) ParseLongDurableLink(longDurableLink string) (models.CreateDurableLinkRequest, error) {
+func (s *linkService) ParseLongDurableLink(ctx context.Context, longDurableLink string) (models.CreateDurableLinkRequest, error) {
 	var req models.CreateDurableLinkRequest
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Str("long_link", longDurableLink).
 		Msg("Parsing long durable link")
 
@@ -218,14 +656,17 @@ func (s *linkService) ParseLongDurableLink(longDurableLink string) (models.Creat
 
 	req.DurableLinkInfo.Link = params.Get("link")
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Str("link", req.DurableLinkInfo.Link).
 		Msg("Parsed link")
 
-	if s.cfg.App.DefaultAndroidPackageName != nil {
-		req.DurableLinkInfo.AndroidParameters.AndroidPackageName = *s.cfg.App.DefaultAndroidPackageName
+	settings, err := s.linkSettings(ctx, strings.ToLower(u.Hostname()))
+	if err != nil {
+		return req, err
 	}
 
+	req.DurableLinkInfo.AndroidParameters.AndroidPackageName = settings.defaultAndroidPackageName
+
 	if apn := params.Get("apn"); apn != "" {
 		req.DurableLinkInfo.AndroidParameters.AndroidPackageName = apn
 	}
@@ -235,11 +676,18 @@ func (s *linkService) ParseLongDurableLink(longDurableLink string) (models.Creat
 	if apv := params.Get("amv"); apv != "" {
 		req.DurableLinkInfo.AndroidParameters.AndroidMinPackageVersionCode = apv
 	}
+	if agl := params.Get("agl"); agl != "" {
+		req.DurableLinkInfo.AndroidParameters.AndroidAppGalleryLink = agl
+	}
 
-	if s.cfg.App.DefaultIosStoreId != nil {
-		req.DurableLinkInfo.IosParameters.IosAppStoreId = *s.cfg.App.DefaultIosStoreId
+	req.DurableLinkInfo.DesktopParameters = models.DesktopParameters{
+		DesktopProtocolLink: params.Get("dpl"),
+		MicrosoftStoreLink:  params.Get("msl"),
+		MacAppStoreLink:     params.Get("masl"),
 	}
 
+	req.DurableLinkInfo.IosParameters.IosAppStoreId = settings.defaultIosStoreId
+
 	if isi := params.Get("isi"); isi != "" {
 		req.DurableLinkInfo.IosParameters.IosAppStoreId = isi
 	}
@@ -292,58 +740,157 @@ func (s *linkService) ParseLongDurableLink(longDurableLink string) (models.Creat
 		req.DurableLinkInfo.SocialMetaTagInfo.SocialImageLink = socialImageLink
 	}
 
+	if gate := params.Get("gate"); gate != "" {
+		req.DurableLinkInfo.Gate = gate
+	}
+
+	req.DurableLinkInfo.DeepLink = parseDeepLink(params)
+	req.DurableLinkInfo.QRFirst = params.Get("qrf") == "1"
+	if mc := params.Get("mc"); mc != "" {
+		maxClicks, err := strconv.ParseInt(mc, 10, 64)
+		if err != nil {
+			return req, apperrors.ErrInvalidMaxClicks
+		}
+		req.DurableLinkInfo.MaxClicks = maxClicks
+	}
+	if req.DurableLinkInfo.ActiveFrom, err = parseActiveTime(params.Get("af")); err != nil {
+		return req, err
+	}
+	if req.DurableLinkInfo.ActiveUntil, err = parseActiveTime(params.Get("au")); err != nil {
+		return req, err
+	}
+	req.DurableLinkInfo.ExpiredLink = params.Get("efl")
+
+	req.DurableLinkInfo.PlatformLinks = models.PlatformLinks{
+		WebLink:     params.Get("wl"),
+		AndroidLink: params.Get("al"),
+		IosLink:     params.Get("il"),
+	}
+
 	if pathOption := params.Get("path"); pathOption != "" {
 		req.Suffix.Option = pathOption
 	}
+	req.Suffix.SMSSafe = params.Get("sms") == "1"
 
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Str("req", fmt.Sprintf("%+v", req)).
 		Msg("Parsed long durable link")
 
 	return req, nil
 }
 
+// checkSMSBudget rejects SMS-safe links whose full short link wouldn't fit SMS_LINK_MAX_LENGTH.
+func (s *linkService) checkSMSBudget(host string, pathLength int) error {
+	total := len(s.cfg.App.URLScheme) + len("://") + len(host) + len("/") + pathLength
+	if total > s.cfg.App.SMSLinkMaxLength {
+		return fmt.Errorf("%w: %d characters, budget is %d", apperrors.ErrSMSBudgetExceeded, total, s.cfg.App.SMSLinkMaxLength)
+	}
+	return nil
+}
+
 func (s *linkService) createOrGetShortLink(
 	ctx context.Context,
 	host string,
 	queryParams url.Values,
-	shortPath bool,
-) (*models.ShortLinkResponse, error) {
+	shortPath, reuse bool,
+) (*models.ShortLinkResponse, string, error) {
 	rawQS := queryParams.Encode()
-	if shortPath {
+	if reuse {
 		if path, err := s.findExistingShortLink(ctx, host, rawQS); err == nil {
 			full := fmt.Sprintf("%s://%s/%s", s.cfg.App.URLScheme, host, path)
-			log.Debug().
+			log.Ctx(ctx).Debug().
 				Str("path", path).
 				Str("query_params", rawQS).
 				Msg("Re‑using existing short link")
-			return &models.ShortLinkResponse{ShortLink: full, Warnings: []models.DurableLinkCreationWarning{}}, nil
+			return &models.ShortLinkResponse{ShortLink: full, Warnings: []models.DurableLinkCreationWarning{}}, path, nil
 
 		} else if err != sql.ErrNoRows {
-			log.Error().
+			log.Ctx(ctx).Error().
 				Err(err).
 				Msg("Error querying for existing short link")
-			return nil, err
+			return nil, "", err
 		}
 	}
 
-	length := s.cfg.App.ShortPathLength
-	if !shortPath {
-		length = s.cfg.App.UnguessablePathLength
-	}
-	path := utils.GenerateRandomAlphanumericString(length)
-
-	if err := s.createShortLink(ctx, host, path, rawQS, !shortPath); err != nil {
-		return nil, fmt.Errorf("failed to store link: %w", err)
+	path, err := s.storeGeneratedPath(ctx, host, queryParams, shortPath)
+	if err != nil {
+		return nil, "", err
 	}
+	s.publishLink(ctx, webhooks.EventLinkCreated, host, path, rawQS)
 
 	full := fmt.Sprintf("%s://%s/%s", s.cfg.App.URLScheme, host, path)
-	log.Debug().
+	log.Ctx(ctx).Debug().
 		Str("path", path).
 		Str("query_params", rawQS).
 		Msg("New link stored in database")
 
-	return &models.ShortLinkResponse{ShortLink: full, Warnings: []models.DurableLinkCreationWarning{}}, nil
+	return &models.ShortLinkResponse{ShortLink: full, Warnings: []models.DurableLinkCreationWarning{}}, path, nil
+}
+
+// storeGeneratedPath stores a new link under a generated path. When the path is taken by a link
+// or an alias, e.g. one created concurrently or because short paths are running out, it retries
+// with new paths up to PATH_COLLISION_RETRIES times.
+func (s *linkService) storeGeneratedPath(ctx context.Context, host string, queryParams url.Values, shortPath bool) (string, error) {
+	rawQS := queryParams.Encode()
+	for attempt := 0; ; attempt++ {
+		path, err := s.generatePath(ctx, host, queryParams, shortPath, attempt)
+		if err != nil {
+			return "", err
+		}
+		// The unique key of durable_links doesn't cover aliases, so they are looked up first.
+		taken, err := s.repo.PathExists(ctx, host, path)
+		if err != nil {
+			return "", fmt.Errorf("failed to store link: %w", err)
+		}
+		if !taken {
+			err = s.createShortLink(ctx, host, path, rawQS, !shortPath)
+			if err == nil {
+				return path, nil
+			}
+			if !errors.Is(err, apperrors.ErrPathTaken) {
+				return "", fmt.Errorf("failed to store link: %w", err)
+			}
+		}
+		if attempt >= s.cfg.App.PathCollisionRetries {
+			log.Ctx(ctx).Error().
+				Str("host", host).
+				Int("attempts", attempt+1).
+				Msg("Generated paths keep colliding, consider longer paths")
+			return "", apperrors.ErrNoFreePath
+		}
+		log.Ctx(ctx).Warn().
+			Str("host", host).
+			Str("path", path).
+			Int("attempt", attempt+1).
+			Msg("Generated path is taken, retrying")
+	}
+}
+
+// generatePath picks the path for a new link with the configured generator, or the SMS safe one
+// for SMS links. After every PATH_COLLISION_ESCALATE_AFTER attempts that found their path taken,
+// paths get a character longer, except for SMS links, which must stay within their budget.
+func (s *linkService) generatePath(ctx context.Context, host string, queryParams url.Values, shortPath bool, attempt int) (string, error) {
+	length := s.cfg.App.ShortPathLength
+	if !shortPath {
+		length = s.cfg.App.UnguessablePathLength
+	}
+	generator := s.pathGenerator
+	if queryParams.Get("sms") == "1" {
+		generator = pathgen.SMSSafe{}
+	} else if escalateAfter := s.cfg.App.PathCollisionEscalateAfter; escalateAfter > 0 {
+		length += attempt / escalateAfter
+	}
+	path, err := generator.Generate(ctx, pathgen.Request{
+		Host:        host,
+		QueryParams: queryParams.Encode(),
+		Length:      length,
+		Unguessable: !shortPath,
+		Attempt:     attempt,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate path: %w", err)
+	}
+	return path, nil
 }
 
 func (s *linkService) findExistingShortLink(
@@ -361,7 +908,13 @@ func (s *linkService) createShortLink(
 	return s.repo.CreateShortLink(ctx, host, path, rawQS, unguessable)
 }
 
-func (s *linkService) ResolveShortPath(ctx context.Context, rawURL string) (*models.LongLinkResponse, error) {
+// ResolveShortPath exchanges a short link for its long form. With a platform hint the response
+// also carries the destination for that platform.
+func (s *linkService) ResolveShortPath(ctx context.Context, rawURL, platform string, click models.ClickContext) (*models.LongLinkResponse, error) {
+	if platform != "" && !slices.Contains(platforms, platform) {
+		return nil, apperrors.ErrInvalidPlatform
+	}
+
 	u, err := url.Parse(rawURL)
 	if err != nil {
 		return nil, apperrors.ErrInvalidRequestedLink
@@ -374,7 +927,16 @@ func (s *linkService) ResolveShortPath(ctx context.Context, rawURL string) (*mod
 		return nil, fmt.Errorf("unexpected path format: %w", apperrors.ErrInvalidPathFormat)
 	}
 
-	return s.getLongLinkFromHostAndPath(ctx, normalizedHost, pathParts[0])
+	link, err := s.getLongLinkFromHostAndPath(ctx, normalizedHost, pathParts[0], platform)
+	if err != nil {
+		return nil, err
+	}
+
+	if platform == "" {
+		platform = s.detector.Detect(device.Hints{UserAgent: click.UserAgent, Platform: click.PlatformHint}).Platform
+	}
+	s.recordClickEvent(normalizedHost, pathParts[0], clicks.SourceExchange, platform, "", click, s.hasConsent(click))
+	return link, nil
 }
 
 func removePreviewFromHost(host string) string {
@@ -390,15 +952,23 @@ func removePreviewFromHost(host string) string {
 	return host
 }
 
-func (s *linkService) PrepareDurableLinkRequest(input map[string]any) (models.CreateDurableLinkRequest, error) {
+func (s *linkService) PrepareDurableLinkRequest(ctx context.Context, input map[string]any) (models.CreateDurableLinkRequest, error) {
 	var req models.CreateDurableLinkRequest
 
 	if longLink, ok := input["longDurableLink"].(string); ok && longLink != "" {
-		parsedReq, err := s.ParseLongDurableLink(longLink)
+		parsedReq, err := s.ParseLongDurableLink(ctx, longLink)
 		if err != nil {
 			return models.CreateDurableLinkRequest{}, err
 		}
 		req = parsedReq
+		// The metadata sits next to longDurableLink in the body.
+		reqBytes, err := json.Marshal(input)
+		if err != nil {
+			return models.CreateDurableLinkRequest{}, apperrors.ErrInvalidFormat
+		}
+		if err := json.Unmarshal(reqBytes, &req.LinkMetadata); err != nil {
+			return models.CreateDurableLinkRequest{}, apperrors.ErrInvalidFormat
+		}
 	} else {
 		reqBytes, err := json.Marshal(input)
 		if err != nil {
@@ -412,6 +982,17 @@ func (s *linkService) PrepareDurableLinkRequest(input map[string]any) (models.Cr
 	if req.DurableLinkInfo.Host == "" {
 		return models.CreateDurableLinkRequest{}, apperrors.ErrMissingHost
 	}
+	if req.DurableLinkInfo.Link == "" && req.DurableLinkInfo.DeepLink != nil {
+		base := s.deepLinkBase(req.DurableLinkInfo.Host)
+		if base == "" {
+			return models.CreateDurableLinkRequest{}, fmt.Errorf("%w: no deep link base URL configured for this host", apperrors.ErrMissingLink)
+		}
+		link, err := composeDeepLink(base, req.DurableLinkInfo.DeepLink)
+		if err != nil {
+			return models.CreateDurableLinkRequest{}, err
+		}
+		req.DurableLinkInfo.Link = link
+	}
 	if req.DurableLinkInfo.Link == "" {
 		return models.CreateDurableLinkRequest{}, apperrors.ErrMissingLink
 	}
@@ -1,10 +1,25 @@
 package service
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
 	"os"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/clicks"
+	"durable-links-generator/api/device"
 	"durable-links-generator/api/models"
+	"durable-links-generator/api/pathgen"
+	"durable-links-generator/api/policy"
+	"durable-links-generator/api/repository"
+	"durable-links-generator/api/webhooks"
+	"durable-links-generator/config"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
@@ -18,6 +33,612 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
+// newTestLinkService is NewLinkService for configs the test expects to be valid.
+func newTestLinkService(t *testing.T, repo repository.LinkRepository, cfg *config.Config) *linkService {
+	t.Helper()
+	s, err := NewLinkService(repo, cfg)
+	if err != nil {
+		t.Fatalf("NewLinkService: %v", err)
+	}
+	return s
+}
+
+// fakeLinkRepository keeps links in memory. Methods a test doesn't need fall through to the
+// embedded nil interface and panic.
+type fakeLinkRepository struct {
+	repository.LinkRepository
+	links   []models.StoredLink
+	updated []models.StoredLink
+	aliases map[string]string   // alias path -> path
+	merged  map[string][]string // canonical path -> merged paths
+	codes   map[string]string   // code -> path
+	// successors maps superseded paths to the path that replaced them.
+	successors  map[string]string
+	searchLimit int
+	archived    map[string]bool // path -> archived
+	deleted     map[string]bool // path -> soft deleted
+	clickStats  *models.ClickStats
+	statsQuery  models.ClickStatsQuery
+	deviceRules map[string][]models.DeviceRule  // path -> device rules
+	variants    map[string][]models.LinkVariant // path -> variants
+	useCounts   map[string]int64                // path -> clicks counted against maxClicks
+}
+
+func (f *fakeLinkRepository) ListLinksByHost(_ context.Context, host string) ([]models.StoredLink, error) {
+	var links []models.StoredLink
+	for _, l := range f.links {
+		if l.Host == host {
+			links = append(links, l)
+		}
+	}
+	return links, nil
+}
+
+func (f *fakeLinkRepository) UpdateQueryParams(_ context.Context, links []models.StoredLink, _ string) error {
+	f.updated = append(f.updated, links...)
+	return nil
+}
+
+func (f *fakeLinkRepository) GetCanonicalPath(_ context.Context, host, path string) (string, error) {
+	if canonical, ok := f.aliases[path]; ok {
+		path = canonical
+	}
+	for _, l := range f.links {
+		if l.Host == host && l.Path == path {
+			return path, nil
+		}
+	}
+	return "", apperrors.ErrLinkNotFound
+}
+
+func (f *fakeLinkRepository) SetDeviceRules(_ context.Context, _, path string, rules []models.DeviceRule) error {
+	if f.deviceRules == nil {
+		f.deviceRules = map[string][]models.DeviceRule{}
+	}
+	f.deviceRules[path] = rules
+	return nil
+}
+
+func (f *fakeLinkRepository) ResolveDeviceRules(ctx context.Context, host, path string) ([]models.DeviceRule, error) {
+	canonical, err := f.GetCanonicalPath(ctx, host, path)
+	if err != nil {
+		return nil, err
+	}
+	return f.deviceRules[canonical], nil
+}
+
+func (f *fakeLinkRepository) SetLinkVariants(_ context.Context, _, path string, variants []models.LinkVariant) error {
+	if f.variants == nil {
+		f.variants = map[string][]models.LinkVariant{}
+	}
+	f.variants[path] = variants
+	return nil
+}
+
+func (f *fakeLinkRepository) ResolveLinkVariants(ctx context.Context, host, path string) ([]models.LinkVariant, error) {
+	canonical, err := f.GetCanonicalPath(ctx, host, path)
+	if err != nil {
+		return nil, err
+	}
+	return f.variants[canonical], nil
+}
+
+func (f *fakeLinkRepository) UseClick(ctx context.Context, host, path string, maxClicks int64) (bool, error) {
+	canonical, err := f.GetCanonicalPath(ctx, host, path)
+	if err != nil {
+		return false, err
+	}
+	if f.useCounts == nil {
+		f.useCounts = map[string]int64{}
+	}
+	if f.useCounts[canonical] >= maxClicks {
+		return false, nil
+	}
+	f.useCounts[canonical]++
+	return true, nil
+}
+
+func (f *fakeLinkRepository) PathExists(ctx context.Context, host, path string) (bool, error) {
+	_, err := f.GetCanonicalPath(ctx, host, path)
+	return err == nil, nil
+}
+
+func (f *fakeLinkRepository) CreateAlias(_ context.Context, _, aliasPath, path string) error {
+	if f.aliases == nil {
+		f.aliases = map[string]string{}
+	}
+	f.aliases[aliasPath] = path
+	return nil
+}
+
+func (f *fakeLinkRepository) ListAliases(_ context.Context, _, path string) ([]string, error) {
+	aliases := []string{}
+	for alias, p := range f.aliases {
+		if p == path {
+			aliases = append(aliases, alias)
+		}
+	}
+	slices.Sort(aliases)
+	return aliases, nil
+}
+
+func (f *fakeLinkRepository) MergeLinks(_ context.Context, _, canonical string, duplicates []string) error {
+	if f.merged == nil {
+		f.merged = map[string][]string{}
+	}
+	f.merged[canonical] = append(f.merged[canonical], duplicates...)
+	return nil
+}
+
+func (f *fakeLinkRepository) CreateCode(_ context.Context, _, code, path string) error {
+	if _, ok := f.codes[code]; ok {
+		return apperrors.ErrCodeTaken
+	}
+	if f.codes == nil {
+		f.codes = map[string]string{}
+	}
+	f.codes[code] = path
+	return nil
+}
+
+func (f *fakeLinkRepository) GetCodeByPath(_ context.Context, _, path string) (string, error) {
+	for code, p := range f.codes {
+		if p == path {
+			return code, nil
+		}
+	}
+	return "", nil
+}
+
+func (f *fakeLinkRepository) GetPathByCode(_ context.Context, _, code string) (string, error) {
+	if path, ok := f.codes[code]; ok {
+		return path, nil
+	}
+	return "", apperrors.ErrLinkNotFound
+}
+
+// SearchLinks matches the query as a substring of the decoded params, ignoring case.
+func (f *fakeLinkRepository) SearchLinks(_ context.Context, host, query, _ string, limit int) ([]models.StoredLink, error) {
+	f.searchLimit = limit
+	var links []models.StoredLink
+	for _, l := range f.links {
+		decoded, _ := url.QueryUnescape(l.QueryParams)
+		if (host == "" || l.Host == host) && strings.Contains(strings.ToLower(decoded), strings.ToLower(query)) {
+			links = append(links, l)
+		}
+	}
+	return links, nil
+}
+
+// ListLinks pages through links by creation time, breaking ties by ID like the database does.
+func (f *fakeLinkRepository) ListLinks(_ context.Context, host string, page models.LinkPage) ([]models.StoredLink, error) {
+	filter := page.Filter
+	var links []models.StoredLink
+	for _, l := range f.links {
+		params, _ := url.ParseQuery(l.QueryParams)
+		destination, _ := url.Parse(params.Get("link"))
+		switch {
+		case l.Host != host,
+			!filter.CreatedFrom.IsZero() && l.CreatedAt.Before(filter.CreatedFrom),
+			!filter.CreatedTo.IsZero() && !l.CreatedAt.Before(filter.CreatedTo),
+			filter.Unguessable != nil && l.Unguessable != *filter.Unguessable,
+			filter.DestinationDomain != "" && destination.Hostname() != filter.DestinationDomain &&
+				!strings.HasSuffix(destination.Hostname(), "."+filter.DestinationDomain),
+			slices.ContainsFunc(filter.Tags, func(tag string) bool { return !slices.Contains(l.Metadata.Tags, tag) }):
+			continue
+		}
+		links = append(links, l)
+	}
+	cmp := func(a, b models.StoredLink) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return int(a.ID - b.ID)
+	}
+	slices.SortFunc(links, cmp)
+	if page.Desc {
+		slices.Reverse(links)
+	}
+
+	var after models.StoredLink
+	if page.AfterID != 0 {
+		after.ID = page.AfterID
+		after.CreatedAt, _ = time.Parse(time.RFC3339Nano, page.AfterValue)
+	}
+	var result []models.StoredLink
+	for _, l := range links {
+		if page.AfterID != 0 && (page.Desc && cmp(l, after) >= 0 || !page.Desc && cmp(l, after) <= 0) {
+			continue
+		}
+		result = append(result, l)
+		if len(result) == page.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// lastActivity is when the link was last clicked, or created if it never was.
+func lastActivity(l models.StoredLink) time.Time {
+	if l.LastClickedAt != nil {
+		return *l.LastClickedAt
+	}
+	return l.CreatedAt
+}
+
+func (f *fakeLinkRepository) ListStaleLinks(_ context.Context, host string, before time.Time, limit int) ([]models.StoredLink, error) {
+	var links []models.StoredLink
+	for _, l := range f.links {
+		if l.Host == host && !f.archived[l.Path] && lastActivity(l).Before(before) && len(links) < limit {
+			links = append(links, l)
+		}
+	}
+	return links, nil
+}
+
+func (f *fakeLinkRepository) ArchiveStaleLinks(_ context.Context, before time.Time) ([]models.StoredLink, error) {
+	if f.archived == nil {
+		f.archived = map[string]bool{}
+	}
+	var links []models.StoredLink
+	for _, l := range f.links {
+		if !f.archived[l.Path] && lastActivity(l).Before(before) {
+			f.archived[l.Path] = true
+			links = append(links, l)
+		}
+	}
+	return links, nil
+}
+
+func (f *fakeLinkRepository) UnarchiveLink(_ context.Context, _, path string) error {
+	if !f.archived[path] {
+		return apperrors.ErrLinkNotFound
+	}
+	delete(f.archived, path)
+	return nil
+}
+
+func (f *fakeLinkRepository) FindExistingShortLink(_ context.Context, host, rawQS string) (string, error) {
+	for _, l := range f.links {
+		if l.Host == host && l.QueryParams == rawQS && !l.Unguessable {
+			return l.Path, nil
+		}
+	}
+	return "", sql.ErrNoRows
+}
+
+// CreateShortLink only fails on paths of other links, as the unique key of durable_links does.
+func (f *fakeLinkRepository) CreateShortLink(_ context.Context, host, path, rawQS string, unguessable bool) error {
+	for _, l := range f.links {
+		if l.Host == host && l.Path == path {
+			return apperrors.ErrPathTaken
+		}
+	}
+	f.links = append(f.links, models.StoredLink{Host: host, Path: path, QueryParams: rawQS, Unguessable: unguessable})
+	return nil
+}
+
+func (f *fakeLinkRepository) CreateShortLinks(_ context.Context, links []models.StoredLink) error {
+	for _, link := range links {
+		if slices.ContainsFunc(f.links, func(l models.StoredLink) bool { return l.Host == link.Host && l.Path == link.Path }) {
+			return apperrors.ErrPathTaken
+		}
+	}
+	f.links = append(f.links, links...)
+	return nil
+}
+
+func (f *fakeLinkRepository) MarkSuperseded(_ context.Context, _, path, successor string, _ bool) error {
+	if f.successors == nil {
+		f.successors = map[string]string{}
+	}
+	f.successors[path] = successor
+	return nil
+}
+
+func (f *fakeLinkRepository) GetLink(ctx context.Context, host, path string) (*models.StoredLink, error) {
+	path, err := f.GetCanonicalPath(ctx, host, path)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range f.links {
+		if l.Host == host && l.Path == path {
+			return &l, nil
+		}
+	}
+	return nil, apperrors.ErrLinkNotFound
+}
+
+func (f *fakeLinkRepository) GetQueryParamsByHostAndPath(ctx context.Context, host, path string) (string, error) {
+	link, err := f.GetLink(ctx, host, path)
+	if err != nil {
+		return "", err
+	}
+	if f.deleted[link.Path] {
+		return "", fmt.Errorf("%w: %w", apperrors.ErrLinkDeleted, apperrors.ErrLinkNotFound)
+	}
+	return link.QueryParams, nil
+}
+
+func (f *fakeLinkRepository) ReplaceQueryParams(_ context.Context, link models.StoredLink, previous, _ string) error {
+	for i, l := range f.links {
+		if l.Host == link.Host && l.Path == link.Path {
+			if l.QueryParams != previous {
+				return apperrors.ErrLinkChanged
+			}
+			f.links[i].QueryParams = link.QueryParams
+			return nil
+		}
+	}
+	return apperrors.ErrLinkNotFound
+}
+
+func (f *fakeLinkRepository) SetLinkMetadata(_ context.Context, host, path string, metadata models.LinkMetadata) error {
+	for i, l := range f.links {
+		if l.Host == host && l.Path == path {
+			f.links[i].Metadata = metadata
+			return nil
+		}
+	}
+	return apperrors.ErrLinkNotFound
+}
+
+func (f *fakeLinkRepository) SoftDeleteLink(_ context.Context, _, path string) error {
+	if f.deleted[path] {
+		return apperrors.ErrLinkNotFound
+	}
+	if f.deleted == nil {
+		f.deleted = map[string]bool{}
+	}
+	f.deleted[path] = true
+	return nil
+}
+
+func (f *fakeLinkRepository) RestoreLink(_ context.Context, _, path string) error {
+	if !f.deleted[path] {
+		return apperrors.ErrLinkNotFound
+	}
+	delete(f.deleted, path)
+	return nil
+}
+
+func (f *fakeLinkRepository) DeleteLink(_ context.Context, host, path string) error {
+	for i, l := range f.links {
+		if l.Host == host && l.Path == path {
+			f.links = slices.Delete(f.links, i, i+1)
+			return nil
+		}
+	}
+	return apperrors.ErrLinkNotFound
+}
+
+func (f *fakeLinkRepository) ClickStats(_ context.Context, _ string, query models.ClickStatsQuery) (*models.ClickStats, error) {
+	f.statsQuery = query
+	return f.clickStats, nil
+}
+
+func TestLinkStats(t *testing.T) {
+	from := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 3)
+	repo := &fakeLinkRepository{
+		links:   []models.StoredLink{{Host: "acme.link", Path: "abc"}},
+		aliases: map[string]string{"sale": "abc"},
+		clickStats: &models.ClickStats{
+			Clicks:       6,
+			UniqueClicks: 4,
+			Buckets: []models.ClickBucket{
+				{Start: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), Clicks: 6, UniqueClicks: 4},
+			},
+			Sources: []models.SourceClicks{
+				{Source: "exchange", Platform: "ios", Clicks: 2, UniqueClicks: 2},
+				{Source: "redirect", Platform: "", Clicks: 1, UniqueClicks: 1},
+				{Source: "redirect", Platform: "android", Clicks: 3, UniqueClicks: 2},
+				{Source: "redirect", Platform: "bot", Clicks: 2, UniqueClicks: 1},
+			},
+			TopReferrers: []models.ReferrerClicks{{Referrer: "news.example.org", Clicks: 2}},
+		},
+	}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{URLScheme: "https"}})
+	ctx := context.Background()
+
+	resp, err := s.LinkStats(ctx, models.LinkStatsRequest{Host: "acme.link", Path: "sale", From: from, To: to})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ClickStatsQuery{Path: "abc", From: from, To: to, Granularity: "day", TopReferrers: 10}, repo.statsQuery)
+	assert.Equal(t, "https://acme.link/abc", resp.ShortLink)
+	assert.Equal(t, []models.LinkEventStat{
+		{Platform: "IOS", Count: 2, Event: "APP_RE_OPEN"},
+		{Platform: "OTHER", Count: 3, Event: "CLICK"},
+		{Platform: "ANDROID", Count: 3, Event: "CLICK"},
+	}, resp.LinkEventStats)
+	assert.Equal(t, []models.ClickBucket{
+		{Start: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Start: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), Clicks: 6, UniqueClicks: 4},
+		{Start: time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)},
+		{Start: time.Date(2024, 5, 4, 0, 0, 0, 0, time.UTC)},
+	}, resp.Buckets)
+	assert.Len(t, resp.Platforms, 3)
+	assert.Equal(t, int64(6), resp.Clicks)
+
+	resp, err = s.LinkStats(ctx, models.LinkStatsRequest{Host: "acme.link", Path: "abc", To: to, DurationDays: 30, Granularity: "week"})
+	assert.NoError(t, err)
+	assert.Equal(t, to.AddDate(0, 0, -30), repo.statsQuery.From)
+	// 2024-04-04 is a Thursday; its week starts on Monday the 1st.
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), resp.Buckets[0].Start)
+	assert.Len(t, resp.Buckets, 5)
+
+	for _, req := range []models.LinkStatsRequest{
+		{Host: "acme.link", Path: "abc", Granularity: "minute"},
+		{Host: "acme.link", Path: "abc", From: to, To: from},
+		{Host: "acme.link", Path: "abc", From: from, DurationDays: 3},
+		{Host: "acme.link", Path: "abc", DurationDays: 400},
+		{Host: "acme.link", Path: "abc", DurationDays: 60, Granularity: "hour"},
+	} {
+		_, err := s.LinkStats(ctx, req)
+		assert.Error(t, err, "%+v", req)
+	}
+	_, err = s.LinkStats(ctx, models.LinkStatsRequest{Host: "acme.link", Path: "nope"})
+	assert.ErrorIs(t, err, apperrors.ErrLinkNotFound)
+	_, err = s.LinkStats(ctx, models.LinkStatsRequest{Path: "abc"})
+	assert.ErrorIs(t, err, apperrors.ErrMissingHost)
+}
+
+func TestQRCodeLink(t *testing.T) {
+	repo := &fakeLinkRepository{
+		links:   []models.StoredLink{{Host: "acme.link", Path: "abc", QueryParams: "link=https%3A%2F%2Fexample.com"}},
+		aliases: map[string]string{"sale": "abc"},
+	}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{URLScheme: "https"}})
+	ctx := context.Background()
+
+	link, err := s.QRCodeLink(ctx, "https://acme.link/", "sale")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acme.link/sale", link)
+	_, err = s.QRCodeLink(ctx, "acme.link", "nope")
+	assert.ErrorIs(t, err, apperrors.ErrLinkNotFound)
+	_, err = s.QRCodeLink(ctx, "", "abc")
+	assert.ErrorIs(t, err, apperrors.ErrMissingHost)
+}
+
+// webhookEvents records the events published by a service.
+type webhookEvents []webhooks.Event
+
+func (e *webhookEvents) Publish(_ context.Context, event webhooks.Event) {
+	*e = append(*e, event)
+}
+
+func TestDeleteLink(t *testing.T) {
+	repo := &fakeLinkRepository{
+		links: []models.StoredLink{
+			{Host: "acme.link", Path: "abc", QueryParams: "link=https%3A%2F%2Fexample.com"},
+		},
+		aliases: map[string]string{"sale": "abc"},
+	}
+	events := &webhookEvents{}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{URLScheme: "https"}}).WithWebhooks(events)
+	ctx := context.Background()
+
+	assert.NoError(t, s.DeleteLink(ctx, "acme.link", "sale", false))
+	if assert.Len(t, *events, 1) {
+		assert.Equal(t, webhooks.EventLinkDeleted, (*events)[0].Type)
+		assert.Equal(t, "https://acme.link/abc", (*events)[0].Link.ShortLink, "the event is about the canonical link")
+	}
+	_, err := s.ResolveRedirect(ctx, "acme.link", "abc", models.ClickContext{})
+	assert.ErrorIs(t, err, apperrors.ErrLinkDeleted)
+	assert.ErrorIs(t, s.DeleteLink(ctx, "acme.link", "abc", false), apperrors.ErrLinkNotFound, "already deleted")
+
+	assert.NoError(t, s.RestoreLink(ctx, "acme.link", "abc"))
+	_, err = s.ResolveRedirect(ctx, "acme.link", "abc", models.ClickContext{})
+	assert.NoError(t, err)
+	assert.ErrorIs(t, s.RestoreLink(ctx, "acme.link", "abc"), apperrors.ErrLinkNotFound, "not deleted")
+
+	assert.NoError(t, s.DeleteLink(ctx, "acme.link", "abc", true))
+	assert.Empty(t, repo.links)
+	assert.ErrorIs(t, s.DeleteLink(ctx, "", "abc", false), apperrors.ErrMissingHost)
+}
+
+func TestUpdateLink(t *testing.T) {
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{Host: "acme.link", Path: "abc", QueryParams: "afl=https%3A%2F%2Fexample.com%2Fandroid&link=https%3A%2F%2Fexample.com%2Fold"},
+	}}
+	events := &webhookEvents{}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:      "https",
+		AllowedDomains: []string{"example.com"},
+	}}).WithWebhooks(events)
+	ctx := context.Background()
+	str := func(s string) *string { return &s }
+
+	current, err := s.GetLink(ctx, "acme.link", "abc")
+	assert.NoError(t, err)
+
+	updated, err := s.UpdateLink(ctx, "acme.link", "abc", current.ETag, models.UpdateLinkRequest{
+		Link:                str("https://example.com/new"),
+		AndroidFallbackLink: str(""),
+		SocialTitle:         str("New"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acme.link/abc", updated.ShortLink)
+	assert.Equal(t, "link=https%3A%2F%2Fexample.com%2Fnew&st=New", repo.links[0].QueryParams)
+	assert.NotEqual(t, current.ETag, updated.ETag)
+	if assert.Len(t, *events, 1) {
+		assert.Equal(t, webhooks.EventLinkUpdated, (*events)[0].Type)
+		assert.Equal(t, "https://example.com/new", (*events)[0].Link.Link)
+	}
+
+	_, err = s.UpdateLink(ctx, "acme.link", "abc", current.ETag, models.UpdateLinkRequest{SocialTitle: str("Stale")})
+	assert.ErrorIs(t, err, apperrors.ErrLinkChanged, "the ETag read before the first update")
+	listed, err := s.UpdateLink(ctx, "acme.link", "abc", `"other", `+updated.ETag, models.UpdateLinkRequest{SocialTitle: str("Listed")})
+	assert.NoError(t, err)
+
+	tagged, err := s.UpdateLink(ctx, "acme.link", "abc", listed.ETag, models.UpdateLinkRequest{
+		Name: str("Spring sale"),
+		Tags: &[]string{"Spring", "email"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, models.LinkMetadata{Name: "Spring sale", Tags: []string{"email", "spring"}}, tagged.LinkMetadata)
+	assert.Equal(t, tagged.LinkMetadata, repo.links[0].Metadata)
+	assert.NotEqual(t, listed.ETag, tagged.ETag, "metadata is part of the ETag")
+	_, err = s.UpdateLink(ctx, "acme.link", "abc", listed.ETag, models.UpdateLinkRequest{Notes: str("Stale")})
+	assert.ErrorIs(t, err, apperrors.ErrLinkChanged)
+	cleared, err := s.UpdateLink(ctx, "acme.link", "abc", tagged.ETag, models.UpdateLinkRequest{Name: str(""), Tags: &[]string{}})
+	assert.NoError(t, err)
+	assert.True(t, cleared.LinkMetadata.IsZero())
+	assert.Equal(t, listed.ETag, cleared.ETag, "links without metadata have the ETag of their params")
+
+	tests := []struct {
+		name string
+		req  models.UpdateLinkRequest
+		want error
+	}{
+		{"removed link", models.UpdateLinkRequest{Link: str("")}, apperrors.ErrMissingLink},
+		{"link outside the allow list", models.UpdateLinkRequest{Link: str("https://evil.com")}, apperrors.ErrDomainLinkNotAllowed},
+		{"fallback without scheme", models.UpdateLinkRequest{IosFallbackLink: str("javascript:alert(1)")}, apperrors.ErrInvalidLinkParam},
+		{"invalid tag", models.UpdateLinkRequest{Tags: &[]string{"a,b"}}, apperrors.ErrInvalidMetadata},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.UpdateLink(ctx, "acme.link", "abc", "", tt.req)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestReserveLink(t *testing.T) {
+	repo := &fakeLinkRepository{}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:       "https",
+		AllowedDomains:  []string{"example.com"},
+		LinkInfoDomains: []string{"acme.link"},
+	}})
+	ctx := context.Background()
+	str := func(s string) *string { return &s }
+
+	reserved, err := s.ReserveLink(ctx, "acme.link", "launch")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acme.link/launch", reserved.ShortLink)
+	assert.True(t, reserved.Reserved)
+	assert.Empty(t, reserved.LongLink)
+
+	_, err = s.ReserveLink(ctx, "acme.link", "launch")
+	assert.ErrorIs(t, err, apperrors.ErrPathTaken)
+	_, err = s.ReserveLink(ctx, "", "launch")
+	assert.ErrorIs(t, err, apperrors.ErrMissingHost)
+
+	_, err = s.ResolveRedirect(ctx, "acme.link", "launch", models.ClickContext{})
+	assert.ErrorIs(t, err, apperrors.ErrLinkReserved)
+	_, err = s.GetLinkInfo(ctx, "acme.link", "launch")
+	assert.ErrorIs(t, err, apperrors.ErrLinkReserved)
+
+	updated, err := s.UpdateLink(ctx, "acme.link", "launch", reserved.ETag, models.UpdateLinkRequest{
+		Link: str("https://example.com/campaign"),
+	})
+	assert.NoError(t, err)
+	assert.False(t, updated.Reserved)
+	redirect, err := s.ResolveRedirect(ctx, "acme.link", "launch", models.ClickContext{})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/campaign", redirect.Destination)
+}
+
 func TestParseLongDurableLink(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -47,6 +668,9 @@ func TestParseLongDurableLink(t *testing.T) {
 				"&st=social title" +
 				"&sd=social description" +
 				"&si=https://social-image.com" +
+				"&gate=terms" +
+				"&dlr=product" +
+				"&dlp=id%3D42" +
 				"&path=SHORT",
 			want: models.CreateDurableLinkRequest{
 				DurableLinkInfo: models.DurableLinkInfo{
@@ -85,6 +709,8 @@ func TestParseLongDurableLink(t *testing.T) {
 						SocialDescription: "social description",
 						SocialImageLink:   "https://social-image.com",
 					},
+					Gate:     "terms",
+					DeepLink: &models.DeepLink{Route: "product", Params: map[string]string{"id": "42"}},
 				},
 				Suffix: models.Suffix{
 					Option: "SHORT",
@@ -102,8 +728,8 @@ func TestParseLongDurableLink(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			service := &linkService{}
-			got, err := service.ParseLongDurableLink(tt.longLink)
+			service := &linkService{cfg: &config.Config{App: &config.AppConfig{}}}
+			got, err := service.ParseLongDurableLink(context.Background(), tt.longLink)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -168,3 +794,1144 @@ func TestRemovePreviewFromHost(t *testing.T) {
 		})
 	}
 }
+
+func TestScanForPII(t *testing.T) {
+	info := models.DurableLinkInfo{
+		Link: "https://example.com/welcome?email=jane%40example.org",
+		OtherPlatformParameters: models.OtherPlatformParameters{
+			FallbackURL: "https://example.com/desktop",
+		},
+	}
+
+	tests := []struct {
+		name         string
+		policy       string
+		wantWarnings int
+		wantErr      error
+	}{
+		{name: "disabled", policy: "off", wantWarnings: 0},
+		{name: "warn", policy: "warn", wantWarnings: 1},
+		{name: "block", policy: "block", wantErr: apperrors.ErrPIIDetected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &linkService{cfg: &config.Config{App: &config.AppConfig{PIIScanPolicy: tt.policy}}}
+			warnings, err := service.scanForPII(info)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, warnings, tt.wantWarnings)
+		})
+	}
+}
+
+func TestBuildDestination(t *testing.T) {
+	params := url.Values{}
+	params.Set("link", "https://target.com/page?gclid=abc&id=1")
+	params.Set("utm_source", "newsletter")
+	params.Set("utm_campaign", "spring")
+
+	tests := []struct {
+		name      string
+		consented bool
+		want      string
+	}{
+		{
+			name:      "consented appends marketing params",
+			consented: true,
+			want:      "https://target.com/page?gclid=abc&id=1&utm_campaign=spring&utm_source=newsletter",
+		},
+		{
+			name:      "no consent strips marketing params and click ids",
+			consented: false,
+			want:      "https://target.com/page?id=1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildDestination(params.Get("link"), params, tt.consented)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHasConsent(t *testing.T) {
+	required := &linkService{cfg: &config.Config{App: &config.AppConfig{ConsentRequired: true}}}
+	optional := &linkService{cfg: &config.Config{App: &config.AppConfig{}}}
+
+	assert.True(t, optional.hasConsent(models.ClickContext{}))
+	assert.False(t, required.hasConsent(models.ClickContext{}))
+	assert.True(t, required.hasConsent(models.ClickContext{ConsentValue: "granted"}))
+	assert.False(t, required.hasConsent(models.ClickContext{ConsentValue: "denied"}))
+	assert.False(t, required.hasConsent(models.ClickContext{TCString: "not-a-tc-string"}))
+}
+
+func TestCreateAlias(t *testing.T) {
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{Host: "acme.link", Path: "abc123", QueryParams: "link=https%3A%2F%2Fexample.com"},
+		{Host: "acme.link", Path: "taken", QueryParams: "link=https%3A%2F%2Fexample.com"},
+	}}
+	service := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{}})
+	ctx := context.Background()
+
+	resp, err := service.CreateAlias(ctx, "abc123", models.CreateAliasRequest{Host: "acme.link", Alias: "summer-sale"})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.AliasesResponse{Path: "abc123", Aliases: []string{"summer-sale"}}, resp)
+
+	resp, err = service.CreateAlias(ctx, "summer-sale", models.CreateAliasRequest{Host: "acme.link", Alias: "summer"})
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", resp.Path, "alias of an alias points at the original link")
+	assert.Equal(t, []string{"summer", "summer-sale"}, resp.Aliases)
+
+	_, err = service.CreateAlias(ctx, "abc123", models.CreateAliasRequest{Host: "acme.link", Alias: "taken"})
+	assert.ErrorIs(t, err, apperrors.ErrPathTaken)
+
+	_, err = service.CreateAlias(ctx, "abc123", models.CreateAliasRequest{Host: "acme.link", Alias: "no/slashes"})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidPath)
+
+	_, err = service.CreateAlias(ctx, "abc123", models.CreateAliasRequest{Host: "acme.link", Alias: "ShortLinks"})
+	assert.ErrorIs(t, err, apperrors.ErrReservedPath)
+
+	_, err = service.CreateAlias(ctx, "missing", models.CreateAliasRequest{Host: "acme.link", Alias: "new"})
+	assert.ErrorIs(t, err, apperrors.ErrLinkNotFound)
+}
+
+func TestCreateDurableLink_CustomPath(t *testing.T) {
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{Host: "acme.link", Path: "taken", QueryParams: "link=https%3A%2F%2Fexample.com"},
+	}}
+	service := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:      "https",
+		AllowedDomains: []string{"example.com"},
+		ReservedPaths:  []string{"careers"},
+	}})
+	ctx := context.Background()
+	req := func(option, path string) models.CreateDurableLinkRequest {
+		return models.CreateDurableLinkRequest{
+			DurableLinkInfo: models.DurableLinkInfo{Host: "acme.link", Link: "https://example.com"},
+			Suffix:          models.Suffix{Option: option, CustomPath: path},
+		}
+	}
+
+	resp, err := service.CreateDurableLink(ctx, req("CUSTOM", "summer-sale"))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acme.link/summer-sale", resp.ShortLink)
+	assert.False(t, repo.links[len(repo.links)-1].Unguessable)
+
+	tests := []struct {
+		name   string
+		option string
+		path   string
+		want   error
+	}{
+		{"taken by a link", "CUSTOM", "taken", apperrors.ErrPathTaken},
+		{"taken by this test", "CUSTOM", "summer-sale", apperrors.ErrPathTaken},
+		{"built-in reserved word", "CUSTOM", "v1", apperrors.ErrReservedPath},
+		{"audit log route", "CUSTOM", "auditlog", apperrors.ErrReservedPath},
+		{"configured reserved word", "CUSTOM", "Careers", apperrors.ErrReservedPath},
+		{"invalid characters", "CUSTOM", "summer sale", apperrors.ErrInvalidPath},
+		{"missing path", "CUSTOM", "", apperrors.ErrInvalidPath},
+		{"path without CUSTOM", "SHORT", "summer", apperrors.ErrInvalidSuffix},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.CreateDurableLink(ctx, req(tt.option, tt.path))
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestCreateDurableLink_Metadata(t *testing.T) {
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{Host: "acme.link", Path: "abc", QueryParams: "link=https%3A%2F%2Fexample.com"},
+	}}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:      "https",
+		AllowedDomains: []string{"example.com"},
+	}})
+	ctx := context.Background()
+	req := func(metadata models.LinkMetadata) models.CreateDurableLinkRequest {
+		return models.CreateDurableLinkRequest{
+			DurableLinkInfo: models.DurableLinkInfo{Host: "acme.link", Link: "https://example.com"},
+			Suffix:          models.Suffix{Option: "SHORT"},
+			LinkMetadata:    metadata,
+		}
+	}
+
+	resp, err := s.CreateDurableLink(ctx, req(models.LinkMetadata{}))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acme.link/abc", resp.ShortLink, "reused without metadata")
+
+	resp, err = s.CreateDurableLink(ctx, req(models.LinkMetadata{Name: " Spring sale ", Tags: []string{"Spring", "email", "spring"}}))
+	assert.NoError(t, err)
+	assert.NotEqual(t, "https://acme.link/abc", resp.ShortLink, "a new link for its own metadata")
+	stored := repo.links[len(repo.links)-1]
+	assert.Equal(t, models.LinkMetadata{Name: "Spring sale", Tags: []string{"email", "spring"}}, stored.Metadata)
+
+	tests := []struct {
+		name     string
+		metadata models.LinkMetadata
+	}{
+		{"long name", models.LinkMetadata{Name: strings.Repeat("a", maxLinkNameLength+1)}},
+		{"long notes", models.LinkMetadata{Notes: strings.Repeat("a", maxLinkNotesLength+1)}},
+		{"empty tag", models.LinkMetadata{Tags: []string{" "}}},
+		{"tag with a quote", models.LinkMetadata{Tags: []string{`say "hi"`}}},
+		{"too many tags", models.LinkMetadata{Tags: strings.Split("a b c d e f g h i j k l m n o p q r s t u", " ")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.CreateDurableLink(ctx, req(tt.metadata))
+			assert.ErrorIs(t, err, apperrors.ErrInvalidMetadata)
+		})
+	}
+}
+
+// repeatedPaths generates paths of "a" of the requested length, so they collide until longer.
+type repeatedPaths struct {
+	attempts []int
+}
+
+func (g *repeatedPaths) Generate(_ context.Context, req pathgen.Request) (string, error) {
+	g.attempts = append(g.attempts, req.Attempt)
+	return strings.Repeat("a", req.Length), nil
+}
+
+func TestCreateDurableLink_PathCollisions(t *testing.T) {
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{Host: "acme.link", Path: "aaaaaa", QueryParams: "link=https%3A%2F%2Fexample.com%2Fa"},
+	}}
+	cfg := &config.Config{App: &config.AppConfig{
+		URLScheme:                  "https",
+		AllowedDomains:             []string{"example.com"},
+		ShortPathLength:            6,
+		PathCollisionRetries:       3,
+		PathCollisionEscalateAfter: 2,
+	}}
+	s := newTestLinkService(t, repo, cfg)
+	generator := &repeatedPaths{}
+	s.pathGenerator = generator
+	ctx := context.Background()
+	req := func(link string) models.CreateDurableLinkRequest {
+		return models.CreateDurableLinkRequest{
+			DurableLinkInfo: models.DurableLinkInfo{Host: "acme.link", Link: link},
+			Suffix:          models.Suffix{Option: "SHORT"},
+		}
+	}
+
+	resp, err := s.CreateDurableLink(ctx, req("https://example.com/b"))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acme.link/aaaaaaa", resp.ShortLink)
+	assert.Equal(t, []int{0, 1, 2}, generator.attempts)
+
+	cfg.App.PathCollisionRetries = 1
+	_, err = s.CreateDurableLink(ctx, req("https://example.com/c"))
+	assert.ErrorIs(t, err, apperrors.ErrNoFreePath)
+}
+
+func TestCreateDurableLink_AliasCollision(t *testing.T) {
+	repo := &fakeLinkRepository{
+		links:   []models.StoredLink{{Host: "acme.link", Path: "stored", QueryParams: "link=https%3A%2F%2Fexample.com%2Fa"}},
+		aliases: map[string]string{"aaaaaa": "stored"},
+	}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:                  "https",
+		AllowedDomains:             []string{"example.com"},
+		ShortPathLength:            6,
+		PathCollisionRetries:       3,
+		PathCollisionEscalateAfter: 1,
+	}})
+	generator := &repeatedPaths{}
+	s.pathGenerator = generator
+
+	resp, err := s.CreateDurableLink(context.Background(), models.CreateDurableLinkRequest{
+		DurableLinkInfo: models.DurableLinkInfo{Host: "acme.link", Link: "https://example.com/b"},
+		Suffix:          models.Suffix{Option: "SHORT"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acme.link/aaaaaaa", resp.ShortLink, "the aliased path counts as taken")
+	assert.Equal(t, []int{0, 1}, generator.attempts)
+}
+
+func TestCreateDurableLinks(t *testing.T) {
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{Host: "acme.link", Path: "stored", QueryParams: "link=https%3A%2F%2Fexample.com%2Fstored"},
+	}}
+	service := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:             "https",
+		AllowedDomains:        []string{"example.com"},
+		ShortPathLength:       6,
+		UnguessablePathLength: 10,
+		BatchMaxLinks:         10,
+	}})
+	req := func(link, option, path string) models.CreateDurableLinkRequest {
+		return models.CreateDurableLinkRequest{
+			DurableLinkInfo: models.DurableLinkInfo{Host: "acme.link", Link: link},
+			Suffix:          models.Suffix{Option: option, CustomPath: path},
+		}
+	}
+
+	results, err := service.CreateDurableLinks(context.Background(), []models.CreateDurableLinkRequest{
+		req("https://example.com/a", "UNGUESSABLE", ""),
+		req("https://example.com/stored", "SHORT", ""),
+		req("https://example.com/b", "SHORT", ""),
+		req("https://example.com/b", "SHORT", ""),
+		req("https://evil.com", "SHORT", ""),
+		req("https://example.com/c", "CUSTOM", "sale"),
+		req("https://example.com/d", "CUSTOM", "sale"),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 7)
+
+	assert.NoError(t, results[0].Err)
+	assert.Len(t, strings.TrimPrefix(results[0].Link.ShortLink, "https://acme.link/"), 10)
+	assert.Equal(t, "https://acme.link/stored", results[1].Link.ShortLink)
+	assert.NoError(t, results[2].Err)
+	assert.Equal(t, results[2].Link.ShortLink, results[3].Link.ShortLink)
+	assert.ErrorIs(t, results[4].Err, apperrors.ErrDomainLinkNotAllowed)
+	assert.Equal(t, "https://acme.link/sale", results[5].Link.ShortLink)
+	assert.ErrorIs(t, results[6].Err, apperrors.ErrPathTaken)
+
+	// The stored link and the repeated SHORT link are reused, not stored again.
+	assert.Len(t, repo.links, 4)
+
+	_, err = service.CreateDurableLinks(context.Background(), make([]models.CreateDurableLinkRequest, 11))
+	assert.ErrorIs(t, err, apperrors.ErrBatchTooLarge)
+	_, err = service.CreateDurableLinks(context.Background(), nil)
+	assert.ErrorIs(t, err, apperrors.ErrEmptyBatch)
+}
+
+// racingLinkRepository takes the paths given to race for itself right before the first batch is
+// stored, as a concurrent request would.
+type racingLinkRepository struct {
+	*fakeLinkRepository
+	race  func(rows []models.StoredLink) []string
+	raced []string
+}
+
+func (r *racingLinkRepository) CreateShortLinks(ctx context.Context, links []models.StoredLink) error {
+	if r.race != nil {
+		r.raced = r.race(links)
+		for _, path := range r.raced {
+			r.links = append(r.links, models.StoredLink{Host: "acme.link", Path: path, QueryParams: "link=https%3A%2F%2Fexample.com%2Fother"})
+		}
+		r.race = nil
+	}
+	return r.fakeLinkRepository.CreateShortLinks(ctx, links)
+}
+
+func TestCreateDurableLinks_Collision(t *testing.T) {
+	repo := &racingLinkRepository{fakeLinkRepository: &fakeLinkRepository{}}
+	repo.race = func(rows []models.StoredLink) []string { return []string{rows[0].Path, "sale"} }
+	service := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:             "https",
+		AllowedDomains:        []string{"example.com"},
+		UnguessablePathLength: 10,
+		BatchMaxLinks:         10,
+		PathCollisionRetries:  3,
+	}})
+	req := func(link, option, path string) models.CreateDurableLinkRequest {
+		return models.CreateDurableLinkRequest{
+			DurableLinkInfo: models.DurableLinkInfo{Host: "acme.link", Link: link},
+			Suffix:          models.Suffix{Option: option, CustomPath: path},
+		}
+	}
+
+	results, err := service.CreateDurableLinks(context.Background(), []models.CreateDurableLinkRequest{
+		req("https://example.com/a", "UNGUESSABLE", ""),
+		req("https://example.com/b", "CUSTOM", "sale"),
+		req("https://example.com/c", "UNGUESSABLE", ""),
+	})
+	assert.NoError(t, err)
+	if !assert.Len(t, results, 3) {
+		return
+	}
+
+	if assert.NoError(t, results[0].Err, "a generated path taken meanwhile is replaced") {
+		assert.NotEqual(t, "https://acme.link/"+repo.raced[0], results[0].Link.ShortLink)
+	}
+	assert.ErrorIs(t, results[1].Err, apperrors.ErrPathTaken)
+	assert.NoError(t, results[2].Err, "links without a collision are stored")
+	assert.Len(t, repo.links, 4)
+}
+
+func TestSupersedeLink(t *testing.T) {
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{Host: "acme.link", Path: "old", QueryParams: "link=https%3A%2F%2Fexample.com%2Fspring"},
+	}}
+	cfg := &config.Config{App: &config.AppConfig{
+		AllowedDomains:  []string{"example.com"},
+		ShortPathLength: 6,
+		URLScheme:       "https",
+	}}
+	service := newTestLinkService(t, repo, cfg)
+	ctx := context.Background()
+
+	params := models.CreateDurableLinkRequest{
+		DurableLinkInfo: models.DurableLinkInfo{Host: "acme.link", Link: "https://example.com/summer"},
+		Suffix:          models.Suffix{Option: "SHORT"},
+	}
+	resp, err := service.SupersedeLink(ctx, "old", params, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "old", resp.Supersedes)
+	assert.Equal(t, "https://acme.link/"+repo.successors["old"], resp.ShortLink)
+
+	params.DurableLinkInfo.Link = "https://example.com/spring"
+	_, err = service.SupersedeLink(ctx, "old", params, true)
+	assert.ErrorIs(t, err, apperrors.ErrSupersedeSelf)
+
+	_, err = service.SupersedeLink(ctx, "missing", params, true)
+	assert.ErrorIs(t, err, apperrors.ErrLinkNotFound)
+}
+
+func TestGetLinkInfo(t *testing.T) {
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{Host: "acme.link", Path: "abc", QueryParams: "link=https%3A%2F%2Fexample.com%2Fpage&utm_source=mail"},
+	}}
+	cfg := &config.Config{App: &config.AppConfig{URLScheme: "https", LinkInfoDomains: []string{"acme.link"}}}
+	service := newTestLinkService(t, repo, cfg)
+
+	info, err := service.GetLinkInfo(context.Background(), "acme.link", "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acme.link/abc", info.ShortLink)
+	assert.Equal(t, "https://example.com/page", info.Destination)
+	assert.Equal(t, "example.com", info.DestinationDomain)
+
+	cfg.App.LinkInfoDomains = nil
+	_, err = service.GetLinkInfo(context.Background(), "acme.link", "abc")
+	assert.ErrorIs(t, err, apperrors.ErrLinkNotFound, "disabled for the domain")
+}
+
+func TestIsScannerRequest(t *testing.T) {
+	service := &linkService{cfg: &config.Config{App: &config.AppConfig{ScannerFriendlyDomains: []string{"acme.link"}}}}
+	browser := "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+
+	assert.False(t, service.isScannerRequest("acme.link", models.ClickContext{UserAgent: browser}))
+	assert.True(t, service.isScannerRequest("acme.link", models.ClickContext{UserAgent: browser, Head: true}))
+	assert.True(t, service.isScannerRequest("acme.link", models.ClickContext{UserAgent: "Proofpoint URL Defense"}))
+	assert.True(t, service.isScannerRequest("acme.link", models.ClickContext{}))
+	assert.False(t, service.isScannerRequest("other.link", models.ClickContext{}), "domain not opted in")
+}
+
+func TestCountsAsClick(t *testing.T) {
+	service := &linkService{cfg: &config.Config{App: &config.AppConfig{}}}
+
+	assert.True(t, service.countsAsClick(&models.RedirectTarget{}, models.ClickContext{}))
+	assert.False(t, service.countsAsClick(&models.RedirectTarget{}, models.ClickContext{Head: true}))
+	assert.False(t, service.countsAsClick(&models.RedirectTarget{ScannerPreview: true}, models.ClickContext{}))
+	assert.False(t, service.countsAsClick(&models.RedirectTarget{SocialPreview: true}, models.ClickContext{}))
+
+	service.cfg.App.HeadCountsAsClick = true
+	assert.True(t, service.countsAsClick(&models.RedirectTarget{}, models.ClickContext{Head: true}))
+}
+
+type fakeClickRecorder struct {
+	paths  []string
+	events []clicks.Event
+}
+
+func (f *fakeClickRecorder) Record(host, path string, _ time.Time) {
+	f.paths = append(f.paths, host+"/"+path)
+}
+
+func (f *fakeClickRecorder) RecordEvent(event clicks.Event) {
+	f.events = append(f.events, event)
+}
+
+func TestClickRecording(t *testing.T) {
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{Host: "acme.link", Path: "abc", QueryParams: "link=https%3A%2F%2Fexample.com"},
+	}}
+	recorder := &fakeClickRecorder{}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{URLScheme: "https"}}).WithClickRecorder(recorder)
+	ctx := context.Background()
+
+	_, err := s.ResolveRedirect(ctx, "acme.link", "abc", models.ClickContext{})
+	assert.NoError(t, err)
+	_, err = s.ResolveRedirect(ctx, "acme.link", "abc", models.ClickContext{Head: true})
+	assert.NoError(t, err)
+	_, err = s.ResolveRedirect(ctx, "acme.link", "missing", models.ClickContext{})
+	assert.ErrorIs(t, err, apperrors.ErrLinkNotFound)
+
+	assert.Equal(t, []string{"acme.link/abc"}, recorder.paths)
+}
+
+func TestClickEvents(t *testing.T) {
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{Host: "acme.link", Path: "abc", QueryParams: "link=https%3A%2F%2Fexample.com"},
+	}}
+	recorder := &fakeClickRecorder{}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:       "https",
+		ConsentRequired: true,
+		ConsentParam:    "consent",
+	}}).WithClickEvents(recorder)
+	ctx := context.Background()
+	iphone := "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148 Safari/604.1"
+
+	_, err := s.ResolveRedirect(ctx, "acme.link", "abc", models.ClickContext{
+		UserAgent: iphone, IP: "1.2.3.4", Referrer: "https://news.example", ConsentValue: "yes",
+	})
+	assert.NoError(t, err)
+	_, err = s.ResolveRedirect(ctx, "acme.link", "abc", models.ClickContext{UserAgent: iphone, IP: "1.2.3.4", Referrer: "https://news.example"})
+	assert.NoError(t, err)
+	_, err = s.ResolveShortPath(ctx, "https://acme.link/abc", "android", models.ClickContext{ConsentValue: "yes"})
+	assert.NoError(t, err)
+
+	if assert.Len(t, recorder.events, 3) {
+		consented := recorder.events[0]
+		assert.Equal(t, clicks.SourceRedirect, consented.Source)
+		assert.Equal(t, device.PlatformIOS, consented.Platform)
+		assert.Equal(t, "1.2.3.4", consented.IP)
+		assert.Equal(t, "https://news.example", consented.Referrer)
+		assert.Equal(t, iphone, consented.UserAgent)
+		assert.True(t, consented.Consented)
+
+		// Without consent only the platform is kept.
+		anonymous := recorder.events[1]
+		assert.Equal(t, device.PlatformIOS, anonymous.Platform)
+		assert.Empty(t, anonymous.IP)
+		assert.Empty(t, anonymous.Referrer)
+		assert.Empty(t, anonymous.UserAgent)
+		assert.False(t, anonymous.Consented)
+
+		assert.Equal(t, clicks.SourceExchange, recorder.events[2].Source)
+		assert.Equal(t, "android", recorder.events[2].Platform)
+	}
+}
+
+func TestSocialPreview(t *testing.T) {
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{Host: "acme.link", Path: "abc", QueryParams: "link=https%3A%2F%2Fexample.com&st=Summer+sale&si=https%3A%2F%2Fexample.com%2Fsale.png"},
+		{Host: "acme.link", Path: "plain", QueryParams: "link=https%3A%2F%2Fexample.com"},
+	}}
+	recorder := &fakeClickRecorder{}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:              "https",
+		ScannerFriendlyDomains: []string{"acme.link"},
+	}}).WithClickRecorder(recorder)
+	ctx := context.Background()
+	crawler := models.ClickContext{UserAgent: "facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)"}
+
+	target, err := s.ResolveRedirect(ctx, "acme.link", "abc", crawler)
+	assert.NoError(t, err)
+	assert.True(t, target.SocialPreview)
+	assert.False(t, target.ScannerPreview)
+	assert.Equal(t, models.SocialMetaTagInfo{SocialTitle: "Summer sale", SocialImageLink: "https://example.com/sale.png"}, target.Preview)
+
+	target, err = s.ResolveRedirect(ctx, "acme.link", "plain", crawler)
+	assert.NoError(t, err)
+	assert.False(t, target.SocialPreview, "no social tags to show")
+
+	assert.Empty(t, recorder.paths, "crawler hits aren't clicks")
+}
+
+func TestAppPreview(t *testing.T) {
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{Host: "acme.link", Path: "abc", QueryParams: "link=https%3A%2F%2Fexample.com&apn=com.acme&isi=123456&st=Summer+sale"},
+	}}
+	recorder := &fakeClickRecorder{}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{URLScheme: "https"}}).WithClickRecorder(recorder)
+	ctx := context.Background()
+
+	target, err := s.ResolveRedirect(ctx, "preview.acme.link", "abc", models.ClickContext{})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.AppPreview{
+		Host:               "acme.link",
+		ShortLink:          "https://acme.link/abc",
+		PlayStoreLink:      "https://play.google.com/store/apps/details?id=com.acme",
+		AndroidPackageName: "com.acme",
+		AppStoreLink:       "https://apps.apple.com/app/id123456",
+	}, target.AppPreview)
+	assert.Equal(t, "Summer sale", target.Preview.SocialTitle)
+	assert.Empty(t, recorder.paths, "counted once the visitor continues")
+
+	target, err = s.ResolveRedirect(ctx, "acme.link", "abc", models.ClickContext{})
+	assert.NoError(t, err)
+	assert.Nil(t, target.AppPreview)
+	assert.Equal(t, []string{"acme.link/abc"}, recorder.paths)
+}
+
+func TestPolicyHooks(t *testing.T) {
+	repo := &fakeLinkRepository{}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:                  "https",
+		ShortPathLength:            6,
+		AllowedDomains:             []string{"example.com", "bad.com"},
+		PolicyHooks:                []string{"utm_naming", "takedown"},
+		PolicyUTMCampaignPattern:   `^[a-z-]+$`,
+		PolicyTakedownDestinations: []string{"bad.com"},
+	}})
+	ctx := context.Background()
+
+	info := models.DurableLinkInfo{Host: "example.page.link", Link: "https://example.com"}
+	info.AnalyticsInfo.MarketingParameters.UtmCampaign = "Spring_Sale"
+	resp, err := s.CreateDurableLink(ctx, models.CreateDurableLinkRequest{DurableLinkInfo: info})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Warnings, 1)
+	assert.Equal(t, "POLICY_WARNING", resp.Warnings[0].WarningCode)
+	assert.Contains(t, repo.links[0].QueryParams, "utm_campaign=spring_sale")
+
+	_, err = s.CreateDurableLink(ctx, models.CreateDurableLinkRequest{
+		DurableLinkInfo: models.DurableLinkInfo{Host: "example.page.link", Link: "https://bad.com"},
+	})
+	assert.ErrorIs(t, err, apperrors.ErrPolicyDenied)
+
+	repo.links = append(repo.links, models.StoredLink{Host: "example.page.link", Path: "old", QueryParams: "link=https%3A%2F%2Fbad.com"})
+	_, err = s.ResolveRedirect(ctx, "example.page.link", "old", models.ClickContext{})
+	assert.ErrorIs(t, err, apperrors.ErrPolicyDenied)
+	_, err = s.ResolveShortPath(ctx, "https://example.page.link/old", "", models.ClickContext{})
+	assert.ErrorIs(t, err, apperrors.ErrPolicyDenied)
+
+	_, err = NewLinkService(repo, &config.Config{App: &config.AppConfig{PolicyHooks: []string{"missing"}}})
+	assert.ErrorContains(t, err, `unknown policy hook "missing"`)
+}
+
+// rewriteHook sends every link to destination.
+type rewriteHook struct{ destination string }
+
+func (h rewriteHook) BeforeCreate(_ context.Context, link *policy.Link) policy.Decision {
+	link.Params.Set("link", h.destination)
+	return policy.Allow()
+}
+
+func (rewriteHook) BeforeResolve(context.Context, *policy.Link) policy.Decision {
+	return policy.Allow()
+}
+
+func TestPolicyRewriteValidated(t *testing.T) {
+	destination := "https://evil.example/"
+	policy.Register("test_rewrite", func(*config.Config) (policy.Hook, error) {
+		return rewriteHook{destination: destination}, nil
+	})
+	cfg := &config.Config{App: &config.AppConfig{
+		URLScheme:       "https",
+		ShortPathLength: 6,
+		AllowedDomains:  []string{"example.com", "shop.example.com"},
+		PolicyHooks:     []string{"test_rewrite"},
+	}}
+	repo := &fakeLinkRepository{}
+	req := models.CreateDurableLinkRequest{DurableLinkInfo: models.DurableLinkInfo{Host: "example.page.link", Link: "https://example.com"}}
+
+	_, err := newTestLinkService(t, repo, cfg).CreateDurableLink(context.Background(), req)
+	assert.ErrorIs(t, err, apperrors.ErrDomainLinkNotAllowed, "rewritten destinations are checked against the allow list")
+	assert.Empty(t, repo.links)
+
+	destination = "https://shop.example.com/"
+	_, err = newTestLinkService(t, repo, cfg).CreateDurableLink(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Contains(t, repo.links[0].QueryParams, "shop.example.com")
+}
+
+func TestDeepLink(t *testing.T) {
+	repo := &fakeLinkRepository{}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:        "https",
+		ShortPathLength:  6,
+		AllowedDomains:   []string{"example.com", "shop.example.com"},
+		DeepLinkBaseURL:  "https://example.com/app",
+		DeepLinkBaseURLs: map[string]string{"shop.page.link": "https://shop.example.com"},
+	}})
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		input    map[string]any
+		wantLink string
+		wantErr  error
+	}{
+		{
+			name: "composes destination from default base",
+			input: map[string]any{"durableLinkInfo": map[string]any{
+				"host":     "example.page.link",
+				"deepLink": map[string]any{"route": "/product", "params": map[string]any{"id": "42", "screen": "detail"}},
+			}},
+			wantLink: "https://example.com/app/product?id=42&screen=detail",
+		},
+		{
+			name: "uses per-host base",
+			input: map[string]any{"durableLinkInfo": map[string]any{
+				"host":     "shop.page.link",
+				"deepLink": map[string]any{"route": "cart"},
+			}},
+			wantLink: "https://shop.example.com/cart",
+		},
+		{
+			name: "explicit link wins",
+			input: map[string]any{"durableLinkInfo": map[string]any{
+				"host":     "example.page.link",
+				"link":     "https://example.com/landing",
+				"deepLink": map[string]any{"route": "product"},
+			}},
+			wantLink: "https://example.com/landing",
+		},
+		{
+			name: "rejects route escaping the base",
+			input: map[string]any{"durableLinkInfo": map[string]any{
+				"host":     "example.page.link",
+				"deepLink": map[string]any{"route": "../admin"},
+			}},
+			wantErr: apperrors.ErrInvalidDeepLink,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := s.PrepareDurableLinkRequest(context.Background(), tt.input)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantLink, req.DurableLinkInfo.Link)
+		})
+	}
+
+	req, err := s.PrepareDurableLinkRequest(context.Background(), map[string]any{"durableLinkInfo": map[string]any{
+		"host":     "example.page.link",
+		"deepLink": map[string]any{"route": "product", "params": map[string]any{"id": "42"}},
+	}})
+	assert.NoError(t, err)
+	_, err = s.CreateDurableLink(ctx, req)
+	assert.NoError(t, err)
+	stored, err := url.ParseQuery(repo.links[len(repo.links)-1].QueryParams)
+	assert.NoError(t, err)
+	assert.Equal(t, "product", stored.Get("dlr"))
+	assert.Equal(t, "id=42", stored.Get("dlp"))
+}
+
+func TestPlatformLinks(t *testing.T) {
+	repo := &fakeLinkRepository{}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:       "https",
+		ShortPathLength: 6,
+		AllowedDomains:  []string{"example.com"},
+	}})
+	ctx := context.Background()
+
+	info := models.DurableLinkInfo{
+		Host: "example.page.link",
+		Link: "https://example.com",
+		PlatformLinks: models.PlatformLinks{
+			WebLink:     "https://example.com/web",
+			AndroidLink: "myapp://product/42",
+		},
+	}
+	resp, err := s.CreateDurableLink(ctx, models.CreateDurableLinkRequest{DurableLinkInfo: info})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		platform string
+		want     string
+		wantErr  error
+	}{
+		{platform: "", want: ""},
+		{platform: "web", want: "https://example.com/web"},
+		{platform: "android", want: "myapp://product/42"},
+		{platform: "ios", want: "https://example.com"},
+		{platform: "tv", wantErr: apperrors.ErrInvalidPlatform},
+	}
+	for _, tt := range tests {
+		t.Run(tt.platform, func(t *testing.T) {
+			got, err := s.ResolveShortPath(ctx, resp.ShortLink, tt.platform, models.ClickContext{})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.Link)
+		})
+	}
+
+	path := strings.TrimPrefix(resp.ShortLink, "https://example.page.link/")
+	target, err := s.ResolveRedirect(ctx, "example.page.link", path, models.ClickContext{PlatformHint: `"Android"`})
+	assert.NoError(t, err)
+	assert.Equal(t, "myapp://product/42", target.Destination)
+
+	info.PlatformLinks = models.PlatformLinks{WebLink: "https://other.com"}
+	_, err = s.CreateDurableLink(ctx, models.CreateDurableLinkRequest{DurableLinkInfo: info})
+	assert.ErrorIs(t, err, apperrors.ErrDomainLinkNotAllowed)
+
+	info.PlatformLinks = models.PlatformLinks{IosLink: "product/42"}
+	_, err = s.CreateDurableLink(ctx, models.CreateDurableLinkRequest{DurableLinkInfo: info})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidPlatformLink)
+}
+
+func TestDeviceLink(t *testing.T) {
+	params := url.Values{}
+	params.Set("link", "https://example.com")
+	params.Set("al", "https://play.google.com/store/apps/details?id=com.example")
+	params.Set("agl", "https://appgallery.huawei.com/app/C100000000")
+
+	tests := []struct {
+		name    string
+		visitor device.Device
+		want    string
+	}{
+		{"huawei without google play", device.Device{Platform: device.PlatformAndroid, HMS: true}, "https://appgallery.huawei.com/app/C100000000"},
+		{"other android", device.Device{Platform: device.PlatformAndroid}, "https://play.google.com/store/apps/details?id=com.example"},
+		{"web", device.Device{Platform: device.PlatformWeb}, "https://example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, deviceLink(params, tt.visitor))
+		})
+	}
+
+	params.Set("al", "https://example.com/android")
+	assert.Equal(t, "https://example.com/android", deviceLink(params, device.Device{Platform: device.PlatformAndroid, HMS: true}))
+}
+
+func TestDeviceLinkFallbacks(t *testing.T) {
+	params := url.Values{}
+	params.Set("link", "https://example.com")
+	params.Set("apn", "com.example")
+	params.Set("isi", "123456")
+	params.Set("ofl", "https://example.com/other")
+	params.Set("agl", "https://appgallery.huawei.com/app/C100000000")
+
+	tests := []struct {
+		name    string
+		visitor device.Device
+		want    string
+	}{
+		{"android gets play store", device.Device{Platform: device.PlatformAndroid}, "https://play.google.com/store/apps/details?id=com.example"},
+		{"huawei gets appgallery", device.Device{Platform: device.PlatformAndroid, HMS: true}, "https://appgallery.huawei.com/app/C100000000"},
+		{"ios gets app store", device.Device{Platform: device.PlatformIOS}, "https://apps.apple.com/app/id123456"},
+		{"desktop gets ofl", device.Device{Platform: device.PlatformWeb, OS: "windows"}, "https://example.com/other"},
+		{"tv follows device policy", device.Device{Platform: device.PlatformWeb, FormFactor: device.FormFactorTV}, "https://example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, deviceLink(params, tt.visitor))
+		})
+	}
+
+	params.Set("afl", "https://example.com/android")
+	params.Set("ifl", "https://example.com/ios")
+	assert.Equal(t, "https://example.com/android", deviceLink(params, device.Device{Platform: device.PlatformAndroid}))
+	assert.Equal(t, "https://example.com/ios", deviceLink(params, device.Device{Platform: device.PlatformIOS}))
+
+	params.Set("il", "myapp://ios")
+	assert.Equal(t, "myapp://ios", deviceLink(params, device.Device{Platform: device.PlatformIOS}), "platform link wins over fallback")
+}
+
+func TestDesktopApp(t *testing.T) {
+	params := url.Values{}
+	params.Set("link", "https://example.com")
+	params.Set("dpl", "myapp://open/42")
+	params.Set("msl", "ms-windows-store://pdp/?productid=9N0000000000")
+
+	tests := []struct {
+		name    string
+		visitor device.Device
+		want    *models.DesktopApp
+	}{
+		{"windows", device.Device{Platform: device.PlatformWeb, OS: "windows"}, &models.DesktopApp{ProtocolLink: "myapp://open/42", StoreLink: "ms-windows-store://pdp/?productid=9N0000000000", StoreName: "Microsoft Store"}},
+		{"macos without store link", device.Device{Platform: device.PlatformWeb, OS: "macos"}, &models.DesktopApp{ProtocolLink: "myapp://open/42", StoreName: "Mac App Store"}},
+		{"linux", device.Device{Platform: device.PlatformWeb, OS: "linux"}, nil},
+		{"android", device.Device{Platform: device.PlatformAndroid, OS: "android"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, desktopApp(params, tt.visitor))
+		})
+	}
+
+	s := newTestLinkService(t, &fakeLinkRepository{}, &config.Config{App: &config.AppConfig{
+		URLScheme:       "https",
+		ShortPathLength: 6,
+		AllowedDomains:  []string{"example.com"},
+	}})
+	info := models.DurableLinkInfo{Host: "example.page.link", Link: "https://example.com"}
+	info.DesktopParameters.DesktopProtocolLink = "javascript:alert(1)"
+	_, err := s.CreateDurableLink(context.Background(), models.CreateDurableLinkRequest{DurableLinkInfo: info})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidPlatformLink)
+}
+
+func TestDevicePolicies(t *testing.T) {
+	repo := &fakeLinkRepository{links: []models.StoredLink{{
+		Host:        "example.page.link",
+		Path:        "abc",
+		QueryParams: "link=https%3A%2F%2Fexample.com&ofl=https%3A%2F%2Fexample.com%2Ftv",
+	}}}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:      "https",
+		DevicePolicies: map[string]string{"tv": "ofl", "console": "qr"},
+	}})
+	ctx := context.Background()
+
+	tests := []struct {
+		name            string
+		ua              string
+		wantDestination string
+		wantQRCode      string
+	}{
+		{"phone redirects", "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36", "https://example.com", ""},
+		{"tv goes to fallback", "Mozilla/5.0 (SMART-TV; LINUX; Tizen 6.0) AppleWebKit/537.36", "https://example.com/tv", ""},
+		{"console gets qr code", "Mozilla/5.0 (PlayStation; PlayStation 5/2.26) AppleWebKit/605.1.15", "https://example.com", "https://example.page.link/abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := s.ResolveRedirect(ctx, "example.page.link", "abc", models.ClickContext{UserAgent: tt.ua})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantDestination, target.Destination)
+			assert.Equal(t, tt.wantQRCode, target.QRCode)
+		})
+	}
+
+	assert.Error(t, validateDevicePolicies(map[string]string{"fridge": "qr"}))
+	assert.Error(t, validateDevicePolicies(map[string]string{"tv": "ignore"}))
+
+	_, err := NewLinkService(&fakeLinkRepository{}, &config.Config{App: &config.AppConfig{DevicePolicies: map[string]string{"tv": "ignore"}}})
+	assert.ErrorContains(t, err, "invalid DEVICE_POLICIES")
+}
+
+func TestQRFirst(t *testing.T) {
+	repo := &fakeLinkRepository{}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:       "https",
+		ShortPathLength: 6,
+		AllowedDomains:  []string{"example.com"},
+	}})
+	ctx := context.Background()
+
+	info := models.DurableLinkInfo{Host: "example.page.link", Link: "https://example.com", QRFirst: true}
+	info.SocialMetaTagInfo.SocialTitle = "Spring sale"
+	resp, err := s.CreateDurableLink(ctx, models.CreateDurableLinkRequest{DurableLinkInfo: info})
+	assert.NoError(t, err)
+	path := strings.TrimPrefix(resp.ShortLink, "https://example.page.link/")
+
+	desktop := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+	target, err := s.ResolveRedirect(ctx, "example.page.link", path, models.ClickContext{UserAgent: desktop})
+	assert.NoError(t, err)
+	assert.Equal(t, resp.ShortLink, target.QRCode)
+	assert.Equal(t, "Spring sale", target.Preview.SocialTitle)
+
+	phone := "Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148"
+	target, err = s.ResolveRedirect(ctx, "example.page.link", path, models.ClickContext{UserAgent: phone})
+	assert.NoError(t, err)
+	assert.Empty(t, target.QRCode)
+	assert.Equal(t, "https://example.com", target.Destination)
+}
+
+func TestNumericCode(t *testing.T) {
+	repo := &fakeLinkRepository{}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:         "https",
+		ShortPathLength:   6,
+		AllowedDomains:    []string{"example.com"},
+		NumericCodeLength: 6,
+	}})
+	ctx := context.Background()
+
+	req := models.CreateDurableLinkRequest{
+		DurableLinkInfo: models.DurableLinkInfo{Host: "example.page.link", Link: "https://example.com"},
+		Suffix:          models.Suffix{Option: "SHORT", NumericCode: true},
+	}
+	resp, err := s.CreateDurableLink(ctx, req)
+	assert.NoError(t, err)
+	assert.Regexp(t, `^[0-9]{6}$`, resp.Code)
+
+	again, err := s.CreateDurableLink(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, resp.Code, again.Code, "reused links keep their code")
+
+	path, err := s.ResolveCode(ctx, "example.page.link", resp.Code)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.TrimPrefix(resp.ShortLink, "https://example.page.link/"), path)
+
+	_, err = s.ResolveCode(ctx, "example.page.link", "12ab")
+	assert.ErrorIs(t, err, apperrors.ErrLinkNotFound)
+
+	req.Suffix.Option = "UNGUESSABLE"
+	_, err = s.CreateDurableLink(ctx, req)
+	assert.ErrorIs(t, err, apperrors.ErrCodeNotAllowed)
+}
+
+func TestSMSSafeLinks(t *testing.T) {
+	repo := &fakeLinkRepository{}
+	cfg := &config.Config{App: &config.AppConfig{
+		URLScheme:             "https",
+		ShortPathLength:       6,
+		UnguessablePathLength: 17,
+		AllowedDomains:        []string{"example.com"},
+		SMSLinkMaxLength:      32,
+	}}
+	s := newTestLinkService(t, repo, cfg)
+	ctx := context.Background()
+
+	info := models.DurableLinkInfo{Host: "example.page.link", Link: "https://example.com"}
+	resp, err := s.CreateDurableLink(ctx, models.CreateDurableLinkRequest{
+		DurableLinkInfo: info,
+		Suffix:          models.Suffix{Option: "SHORT", SMSSafe: true},
+	})
+	assert.NoError(t, err)
+	assert.Regexp(t, `^https://example\.page\.link/[2-9a-hjkmnp-z]{6}$`, resp.ShortLink)
+	assert.Contains(t, repo.links[0].QueryParams, "sms=1")
+
+	_, err = s.CreateDurableLink(ctx, models.CreateDurableLinkRequest{
+		DurableLinkInfo: info,
+		Suffix:          models.Suffix{Option: "UNGUESSABLE", SMSSafe: true},
+	})
+	assert.ErrorIs(t, err, apperrors.ErrSMSBudgetExceeded)
+}
+
+func TestGetLinkCard(t *testing.T) {
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{Host: "example.page.link", Path: "sale", QueryParams: "link=https%3A%2F%2Fshop.example.com%2Fsale&sd=Everything+must+go&st=Spring+sale"},
+		{Host: "example.page.link", Path: "bare", QueryParams: "link=https%3A%2F%2Fshop.example.com"},
+	}}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{}})
+	ctx := context.Background()
+
+	card, err := s.GetLinkCard(ctx, "example.page.link", "sale")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.LinkCard{Title: "Spring sale", Description: "Everything must go", Domain: "shop.example.com"}, card)
+
+	card, err = s.GetLinkCard(ctx, "example.page.link", "bare")
+	assert.NoError(t, err)
+	assert.Equal(t, "shop.example.com", card.Title)
+
+	_, err = s.GetLinkCard(ctx, "example.page.link", "missing")
+	assert.ErrorIs(t, err, apperrors.ErrLinkNotFound)
+}
+
+func TestSearchLinks(t *testing.T) {
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{Host: "a.page.link", Path: "spr", QueryParams: "link=https%3A%2F%2Fexample.com%2Fspring&st=Spring+landing+page"},
+		{Host: "a.page.link", Path: "fal", QueryParams: "link=https%3A%2F%2Fexample.com%2Ffall"},
+		{Host: "b.page.link", Path: "spr2", QueryParams: "link=https%3A%2F%2Fexample.com%2Fspring-sale"},
+	}}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{URLScheme: "https"}})
+	ctx := context.Background()
+
+	resp, err := s.SearchLinks(ctx, models.SearchRequest{Query: " spring "})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Results, 2)
+	assert.Equal(t, defaultSearchLimit, repo.searchLimit)
+
+	resp, err = s.SearchLinks(ctx, models.SearchRequest{Query: "spring", Host: "https://a.page.link/", Limit: 1000})
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Results, 1) {
+		assert.Equal(t, "https://a.page.link/spr", resp.Results[0].ShortLink)
+		assert.Equal(t, "https://example.com/spring", resp.Results[0].Link)
+		assert.Equal(t, "Spring landing page", resp.Results[0].Title)
+	}
+	assert.Equal(t, maxSearchLimit, repo.searchLimit)
+
+	_, err = s.SearchLinks(ctx, models.SearchRequest{Query: "  "})
+	assert.ErrorIs(t, err, apperrors.ErrMissingQuery)
+	_, err = s.SearchLinks(ctx, models.SearchRequest{Query: "spring", Sort: "popular"})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidSort)
+}
+
+func TestListLinks(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &fakeLinkRepository{}
+	for i := range 5 {
+		repo.links = append(repo.links, models.StoredLink{
+			ID:          int64(i + 1),
+			Host:        "a.page.link",
+			Path:        fmt.Sprintf("p%d", i+1),
+			QueryParams: "link=https%3A%2F%2Fexample.com",
+			CreatedAt:   start.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	// Links p2 and p3 share a timestamp, so only the ID keeps pages stable.
+	repo.links[2].CreatedAt = repo.links[1].CreatedAt
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{URLScheme: "https"}})
+	ctx := context.Background()
+
+	paths := func(sort string) []string {
+		var got []string
+		req := models.ListLinksRequest{Host: "a.page.link", Sort: sort, PageSize: 2}
+		for {
+			resp, err := s.ListLinks(ctx, req)
+			if !assert.NoError(t, err) {
+				return got
+			}
+			for _, l := range resp.Links {
+				got = append(got, l.Path)
+			}
+			if resp.NextPageToken == "" {
+				return got
+			}
+			req.PageToken = resp.NextPageToken
+		}
+	}
+	assert.Equal(t, []string{"p1", "p2", "p3", "p4", "p5"}, paths("createdAt"))
+	assert.Equal(t, []string{"p5", "p4", "p3", "p2", "p1"}, paths(""))
+
+	first, err := s.ListLinks(ctx, models.ListLinksRequest{Host: "a.page.link", PageSize: 2})
+	assert.NoError(t, err)
+	_, err = s.ListLinks(ctx, models.ListLinksRequest{Host: "a.page.link", Sort: "createdAt", PageToken: first.NextPageToken})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidPageToken, "tokens are bound to their sort")
+	_, err = s.ListLinks(ctx, models.ListLinksRequest{Host: "a.page.link", PageToken: "garbage"})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidPageToken)
+	for sort, value := range map[string]string{"-createdAt": "yesterday", "-clickCount": "1e3", "lastClickedAt": "2024-01-01"} {
+		tampered := encodePageToken(pageToken{Sort: sort, Value: value, ID: 1})
+		_, err = s.ListLinks(ctx, models.ListLinksRequest{Host: "a.page.link", Sort: sort, PageToken: tampered})
+		assert.ErrorIs(t, err, apperrors.ErrInvalidPageToken, "%s %s", sort, value)
+	}
+	_, err = s.ListLinks(ctx, models.ListLinksRequest{Host: "a.page.link", Sort: "-expiresAt"})
+	assert.NoError(t, err)
+	_, err = s.ListLinks(ctx, models.ListLinksRequest{Host: "a.page.link", Sort: "-path"})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidListSort)
+	_, err = s.ListLinks(ctx, models.ListLinksRequest{})
+	assert.ErrorIs(t, err, apperrors.ErrMissingHost)
+}
+
+func TestListLinks_Filter(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{ID: 1, Host: "a.page.link", Path: "p1", QueryParams: "link=https%3A%2F%2Fexample.com", CreatedAt: start},
+		{ID: 2, Host: "a.page.link", Path: "p2", QueryParams: "link=https%3A%2F%2Fshop.example.com%2Fsale", CreatedAt: start.AddDate(0, 1, 0), Unguessable: true},
+		{ID: 3, Host: "a.page.link", Path: "p3", QueryParams: "link=https%3A%2F%2Fnotexample.com", CreatedAt: start.AddDate(0, 2, 0),
+			Metadata: models.LinkMetadata{Tags: []string{"email", "spring"}}},
+	}}
+	repo.links[0].Metadata.Tags = []string{"spring"}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{URLScheme: "https"}})
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		req     models.ListLinksRequest
+		want    []string
+		wantErr error
+	}{
+		{"no filter", models.ListLinksRequest{}, []string{"p3", "p2", "p1"}, nil},
+		{"created range", models.ListLinksRequest{CreatedAfter: start.AddDate(0, 1, 0), CreatedBefore: start.AddDate(0, 2, 0)}, []string{"p2"}, nil},
+		{"short suffix", models.ListLinksRequest{Suffix: "SHORT"}, []string{"p3", "p1"}, nil},
+		{"unguessable suffix", models.ListLinksRequest{Suffix: "unguessable"}, []string{"p2"}, nil},
+		{"destination with subdomains", models.ListLinksRequest{DestinationDomain: "Example.com"}, []string{"p2", "p1"}, nil},
+		{"tag", models.ListLinksRequest{Tags: []string{"Spring"}}, []string{"p3", "p1"}, nil},
+		{"all tags", models.ListLinksRequest{Tags: []string{"spring", "email"}}, []string{"p3"}, nil},
+		{"empty range", models.ListLinksRequest{CreatedAfter: start, CreatedBefore: start}, nil, apperrors.ErrInvalidDateRange},
+		{"unknown suffix", models.ListLinksRequest{Suffix: "CUSTOM"}, nil, apperrors.ErrInvalidListSuffix},
+		{"invalid domain", models.ListLinksRequest{DestinationDomain: "exa%mple.com"}, nil, apperrors.ErrInvalidDestinationDomain},
+		{"invalid tag", models.ListLinksRequest{Tags: []string{`"spring"`}}, nil, apperrors.ErrInvalidMetadata},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Host = "a.page.link"
+			resp, err := s.ListLinks(ctx, tt.req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			var got []string
+			for _, l := range resp.Links {
+				got = append(got, l.Path)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
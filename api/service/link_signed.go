@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/policy"
+	"durable-links-generator/api/signedlinks"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+// WithSignedLinks sets the signer of signed links. Without it, signed links can't be created and
+// none resolve.
+func (s *linkService) WithSignedLinks(signer *signedlinks.Signer) *linkService {
+	s.signer = signer
+	return s
+}
+
+// CreateSignedLink makes a signed link, served at "/s/<token>". Nothing is stored: the token holds
+// the destination, so the link can't be listed, updated or deleted, and has no click stats. It
+// suits short-lived links in volumes the links table shouldn't hold. The destination gets the
+// allow list, policy and URL reputation checks of stored links, as it can't be taken back.
+func (s *linkService) CreateSignedLink(ctx context.Context, req models.CreateSignedLinkRequest) (*models.SignedLinkResponse, error) {
+	if s.signer == nil {
+		return nil, apperrors.ErrSignedLinksDisabled
+	}
+	host, err := utils.CleanHost(req.Host)
+	if err != nil {
+		return nil, apperrors.ErrMissingHost
+	}
+	if req.Link == "" {
+		return nil, apperrors.ErrMissingLink
+	}
+	if err := utils.ValidateURLScheme(req.Link); err != nil {
+		return nil, apperrors.ErrInvalidURLFormat
+	}
+	if req.TTLSeconds < 0 {
+		return nil, apperrors.ErrInvalidTTL
+	}
+	settings, err := s.linkSettings(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if !utils.IsDomainAllowed(settings.allowedDomains, req.Link) {
+		return nil, apperrors.ErrDomainLinkNotAllowed
+	}
+	decision := s.policy.BeforeCreate(ctx, &policy.Link{Host: host, Params: url.Values{"link": {req.Link}}})
+	if decision.Deny {
+		return nil, fmt.Errorf("%w: %s", apperrors.ErrPolicyDenied, decision.Reason)
+	}
+	warnings, err := s.checkReputation(ctx, models.DurableLinkInfo{Link: req.Link})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.SignedLinkResponse{Warnings: warnings}
+	var expires time.Time
+	if req.TTLSeconds > 0 {
+		expires = time.Now().Add(time.Duration(req.TTLSeconds) * time.Second).Truncate(time.Second).UTC()
+		resp.ExpiresAt = &expires
+	}
+	token := s.signer.Sign(host, req.Link, expires)
+	resp.ShortLink = fmt.Sprintf("%s://%s/s/%s", s.cfg.App.URLScheme, host, token)
+	return resp, nil
+}
+
+// ResolveSignedLink returns where the signed link with token on host leads, without a database
+// lookup, behind the host's interstitial gate if it has one. Tokens that don't verify are
+// ErrLinkNotFound, expired ones ErrLinkExpired.
+func (s *linkService) ResolveSignedLink(ctx context.Context, host, token string, click models.ClickContext) (*models.RedirectTarget, error) {
+	if s.signer == nil {
+		return nil, apperrors.ErrLinkNotFound
+	}
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host: %w", err)
+	}
+	link, err := s.signer.Verify(host, token)
+	switch {
+	case errors.Is(err, signedlinks.ErrExpired):
+		return nil, apperrors.ErrLinkExpired
+	case err != nil:
+		log.Ctx(ctx).Debug().Err(err).Str("host", host).Msg("Rejected signed link")
+		return nil, apperrors.ErrLinkNotFound
+	}
+
+	params := url.Values{"link": {link}}
+	if err := s.checkResolvePolicy(ctx, host, token, params); err != nil {
+		return nil, err
+	}
+	destination, err := buildDestination(link, params, s.hasConsent(click))
+	if err != nil {
+		return nil, err
+	}
+	return &models.RedirectTarget{Destination: destination, Gate: s.cfg.App.InterstitialGates[host]}, nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/signedlinks"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignedLinks(t *testing.T) {
+	signer, err := signedlinks.New(map[string]string{"k1": "0123456789abcdef0123456789abcdef"}, "k1")
+	assert.NoError(t, err)
+	// The repository holds no links: signed links resolve without it.
+	s := newTestLinkService(t, &fakeLinkRepository{}, &config.Config{App: &config.AppConfig{
+		URLScheme:      "https",
+		AllowedDomains: []string{"example.com"},
+	}}).WithSignedLinks(signer)
+	ctx := context.Background()
+
+	resp, err := s.CreateSignedLink(ctx, models.CreateSignedLinkRequest{Host: "acme.link", Link: "https://example.com/a?utm_source=mail", TTLSeconds: 60})
+	assert.NoError(t, err)
+	assert.NotNil(t, resp.ExpiresAt)
+	assert.True(t, strings.HasPrefix(resp.ShortLink, "https://acme.link/s/k1."))
+
+	u, err := url.Parse(resp.ShortLink)
+	assert.NoError(t, err)
+	token := strings.TrimPrefix(u.Path, "/s/")
+	target, err := s.ResolveSignedLink(ctx, "acme.link", token, models.ClickContext{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "https://example.com/a?utm_source=mail", target.Destination)
+		assert.Empty(t, target.Gate)
+	}
+
+	_, err = s.ResolveSignedLink(ctx, "other.link", token, models.ClickContext{})
+	assert.ErrorIs(t, err, apperrors.ErrLinkNotFound)
+
+	for req, want := range map[models.CreateSignedLinkRequest]error{
+		{Host: "acme.link", Link: "https://evil.test"}:                   apperrors.ErrDomainLinkNotAllowed,
+		{Host: "acme.link", Link: "ftp://example.com"}:                   apperrors.ErrInvalidURLFormat,
+		{Host: "acme.link"}:                                              apperrors.ErrMissingLink,
+		{Host: "acme.link", Link: "https://example.com", TTLSeconds: -1}: apperrors.ErrInvalidTTL,
+	} {
+		_, err := s.CreateSignedLink(ctx, req)
+		assert.ErrorIs(t, err, want, "%+v", req)
+	}
+
+	checker := &fakeChecker{flagged: map[string]string{"https://example.com/bad": "MALWARE"}}
+	s.WithReputation(checker)
+	_, err = s.CreateSignedLink(ctx, models.CreateSignedLinkRequest{Host: "acme.link", Link: "https://example.com/bad"})
+	assert.ErrorIs(t, err, apperrors.ErrUnsafeURL)
+	assert.Equal(t, []string{"https://example.com/bad"}, checker.checked)
+
+	unsigned := newTestLinkService(t, &fakeLinkRepository{}, &config.Config{App: &config.AppConfig{URLScheme: "https"}})
+	_, err = unsigned.CreateSignedLink(ctx, models.CreateSignedLinkRequest{Host: "acme.link", Link: "https://example.com"})
+	assert.ErrorIs(t, err, apperrors.ErrSignedLinksDisabled)
+	_, err = unsigned.ResolveSignedLink(ctx, "acme.link", token, models.ClickContext{})
+	assert.ErrorIs(t, err, apperrors.ErrLinkNotFound)
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/clicks"
+	"durable-links-generator/api/device"
+	"durable-links-generator/api/models"
+	"durable-links-generator/utils"
+)
+
+const (
+	defaultStatsDays = 7
+	// maxStatsDays bounds the time range of one stats request.
+	maxStatsDays = 366
+	// maxStatsBuckets bounds the time buckets of one stats request, e.g. hourly stats over more
+	// than 41 days.
+	maxStatsBuckets   = 1000
+	statsTopReferrers = 10
+)
+
+// Firebase Dynamic Links analytics names for the sources and platforms of clicks.
+var (
+	fdlEvents = map[string]string{
+		clicks.SourceRedirect: "CLICK",
+		clicks.SourceExchange: "APP_RE_OPEN",
+	}
+	fdlPlatforms = map[string]string{
+		device.PlatformAndroid: "ANDROID",
+		device.PlatformIOS:     "IOS",
+		device.PlatformWeb:     "DESKTOP",
+	}
+)
+
+// LinkStats counts the clicks on the link at path, or the link path is an alias of, along with
+// those on its aliases and on the links it superseded.
+func (s *linkService) LinkStats(ctx context.Context, req models.LinkStatsRequest) (*models.LinkStatsResponse, error) {
+	host, err := utils.CleanHost(req.Host)
+	if err != nil {
+		return nil, apperrors.ErrMissingHost
+	}
+	from, to, granularity, err := statsWindow(req, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	canonical, err := s.repo.GetCanonicalPath(ctx, host, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.repo.ClickStats(ctx, host, models.ClickStatsQuery{
+		Path:         canonical,
+		From:         from,
+		To:           to,
+		Granularity:  granularity,
+		TopReferrers: statsTopReferrers,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.LinkStatsResponse{
+		LinkEventStats:  []models.LinkEventStat{},
+		ShortLink:       fmt.Sprintf("%s://%s/%s", s.cfg.App.URLScheme, host, canonical),
+		From:            from,
+		To:              to,
+		Granularity:     granularity,
+		Clicks:          stats.Clicks,
+		UniqueClicks:    stats.UniqueClicks,
+		ConsentedClicks: stats.ConsentedClicks,
+		Buckets:         allBuckets(stats.Buckets, from, to, granularity),
+		Platforms:       redirectPlatforms(stats.Sources),
+		TopReferrers:    stats.TopReferrers,
+		Variants:        stats.Variants,
+	}
+
+	// Several platforms can map to the same Firebase platform, so counts are summed in order of
+	// first appearance.
+	eventStats := map[[2]string]int{}
+	for _, source := range stats.Sources {
+		event, ok := fdlEvents[source.Source]
+		if !ok {
+			continue
+		}
+		platform, ok := fdlPlatforms[source.Platform]
+		if !ok {
+			platform = "OTHER"
+		}
+		key := [2]string{event, platform}
+		if i, ok := eventStats[key]; ok {
+			resp.LinkEventStats[i].Count += source.Clicks
+			continue
+		}
+		eventStats[key] = len(resp.LinkEventStats)
+		resp.LinkEventStats = append(resp.LinkEventStats, models.LinkEventStat{
+			Platform: platform,
+			Count:    source.Clicks,
+			Event:    event,
+		})
+	}
+
+	return resp, nil
+}
+
+// statsWindow returns the time range and granularity req asks for, checking they make at most
+// maxStatsBuckets buckets.
+func statsWindow(req models.LinkStatsRequest, now time.Time) (from, to time.Time, granularity string, err error) {
+	granularity = req.Granularity
+	if granularity == "" {
+		granularity = "day"
+	}
+	if _, ok := bucketSteps[granularity]; !ok {
+		return from, to, granularity, apperrors.ErrInvalidGranularity
+	}
+	if from, to, err = statsRange(req, now); err != nil {
+		return from, to, granularity, err
+	}
+	if n := len(bucketStarts(from, to, granularity)); n > maxStatsBuckets {
+		return from, to, granularity, fmt.Errorf("%w: %d %s buckets, at most %d are allowed", apperrors.ErrInvalidStatsRange, n, granularity, maxStatsBuckets)
+	}
+	return from, to, granularity, nil
+}
+
+// allBuckets fills in the buckets from from to to that weren't counted, having no clicks.
+func allBuckets(counted []models.ClickBucket, from, to time.Time, granularity string) []models.ClickBucket {
+	byStart := map[time.Time]models.ClickBucket{}
+	for _, bucket := range counted {
+		byStart[bucket.Start] = bucket
+	}
+	buckets := []models.ClickBucket{}
+	for _, start := range bucketStarts(from, to, granularity) {
+		bucket, ok := byStart[start]
+		if !ok {
+			bucket = models.ClickBucket{Start: start}
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+// redirectPlatforms picks the redirects out of clicks counted by source and platform.
+func redirectPlatforms(sources []models.SourceClicks) []models.PlatformClicks {
+	platforms := []models.PlatformClicks{}
+	for _, source := range sources {
+		if source.Source == clicks.SourceRedirect {
+			platforms = append(platforms, models.PlatformClicks{
+				Platform:     source.Platform,
+				Clicks:       source.Clicks,
+				UniqueClicks: source.UniqueClicks,
+			})
+		}
+	}
+	return platforms
+}
+
+// statsRange returns the time range req asks for, ending now unless it says otherwise.
+func statsRange(req models.LinkStatsRequest, now time.Time) (from, to time.Time, err error) {
+	to = now
+	if !req.To.IsZero() {
+		to = req.To.UTC()
+	}
+	if !req.From.IsZero() {
+		if req.DurationDays != 0 {
+			return from, to, fmt.Errorf("%w: from and durationDays can't be combined", apperrors.ErrInvalidStatsRange)
+		}
+		from = req.From.UTC()
+	} else {
+		days := req.DurationDays
+		if days == 0 {
+			days = defaultStatsDays
+		}
+		if days < 1 || days > maxStatsDays {
+			return from, to, fmt.Errorf("%w: durationDays must be 1 to %d", apperrors.ErrInvalidStatsRange, maxStatsDays)
+		}
+		from = to.AddDate(0, 0, -days)
+	}
+
+	if !from.Before(to) {
+		return from, to, fmt.Errorf("%w: from must be before to", apperrors.ErrInvalidStatsRange)
+	}
+	if to.Sub(from) > maxStatsDays*24*time.Hour {
+		return from, to, fmt.Errorf("%w: at most %d days are allowed", apperrors.ErrInvalidStatsRange, maxStatsDays)
+	}
+	return from, to, nil
+}
+
+// How to get from the start of one UTC time bucket to the next, by granularity.
+var bucketSteps = map[string]func(time.Time) time.Time{
+	"hour":  func(t time.Time) time.Time { return t.Add(time.Hour) },
+	"day":   func(t time.Time) time.Time { return t.AddDate(0, 0, 1) },
+	"week":  func(t time.Time) time.Time { return t.AddDate(0, 0, 7) },
+	"month": func(t time.Time) time.Time { return t.AddDate(0, 1, 0) },
+}
+
+// bucketStart truncates t like Postgres' date_trunc does in UTC: weeks start on Monday.
+func bucketStart(t time.Time, granularity string) time.Time {
+	t = t.UTC()
+	switch granularity {
+	case "hour":
+		return t.Truncate(time.Hour)
+	case "week":
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// bucketStarts lists the starts of the time buckets overlapping from to to, up to one more than
+// maxStatsBuckets.
+func bucketStarts(from, to time.Time, granularity string) []time.Time {
+	var starts []time.Time
+	step := bucketSteps[granularity]
+	for start := bucketStart(from, granularity); start.Before(to) && len(starts) <= maxStatsBuckets; start = step(start) {
+		starts = append(starts, start)
+	}
+	return starts
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SupersedeLink creates a new link from params and marks the link at path as replaced by it. With
+// redirect set the old link resolves to the new one from then on.
+func (s *linkService) SupersedeLink(
+	ctx context.Context,
+	path string,
+	params models.CreateDurableLinkRequest,
+	redirect bool,
+) (*models.SupersedeLinkResponse, error) {
+	host, err := utils.CleanHost(params.DurableLinkInfo.Host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host: %w", err)
+	}
+
+	oldPath, err := s.repo.GetCanonicalPath(ctx, host, path)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, newPath, err := s.createDurableLink(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if newPath == oldPath {
+		return nil, apperrors.ErrSupersedeSelf
+	}
+
+	if err := s.repo.MarkSuperseded(ctx, host, oldPath, newPath, redirect); err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Info().
+		Str("host", host).
+		Str("path", oldPath).
+		Str("successor", newPath).
+		Bool("redirect", redirect).
+		Msg("Link superseded")
+
+	return &models.SupersedeLinkResponse{
+		ShortLinkResponse: *resp,
+		Supersedes:        oldPath,
+	}, nil
+}
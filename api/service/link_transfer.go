@@ -0,0 +1,260 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/authz"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+	"durable-links-generator/config"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Formats of a bulk import.
+const (
+	ImportCSV   = "csv"
+	ImportJSONL = "jsonl"
+)
+
+// exportColumns is the header of a CSV export, which imports back as is.
+var exportColumns = []string{"path", "shortLink", "longDurableLink", "createdAt", "totalClicks"}
+
+// Links read per query while exporting.
+const exportPageSize = 1000
+
+// maxImportLine bounds the length of a JSONL import line.
+const maxImportLine = 1 << 20
+
+type LinkTransferService interface {
+	ImportLinks(ctx context.Context, r io.Reader, format string, report func(ImportResult)) error
+	ExportLinks(ctx context.Context, host string, w io.Writer) error
+}
+
+type linkTransferService struct {
+	linkService LinkService
+	repo        repository.LinkRepository
+	cfg         *config.Config
+}
+
+func NewLinkTransferService(linkService LinkService, repo repository.LinkRepository, cfg *config.Config) *linkTransferService {
+	return &linkTransferService{
+		linkService: linkService,
+		repo:        repo,
+		cfg:         cfg,
+	}
+}
+
+// importChunk is the rows read since the last batch of links was created.
+type importChunk struct {
+	results  []ImportResult
+	requests []models.CreateDurableLinkRequest
+	// indexes maps the position in requests to the position in results.
+	indexes []int
+}
+
+// ImportLinks creates the links of a CSV or JSONL stream as it is read, BatchMaxLinks at a time,
+// and calls report with the outcome of every row in order. A CSV row holds a longDurableLink and
+// optionally the path to create it under, a JSONL line holds a POST /shortLinks body. Rows are
+// numbered by line, the CSV header being line 1. Links created before an unreadable row stay
+// created.
+func (s *linkTransferService) ImportLinks(ctx context.Context, r io.Reader, format string, report func(ImportResult)) error {
+	var next func() (row int, req models.CreateDurableLinkRequest, err error)
+	switch format {
+	case ImportCSV:
+		var err error
+		if next, err = s.csvRows(ctx, r); err != nil {
+			return err
+		}
+	case ImportJSONL:
+		next = s.jsonlRows(ctx, r)
+	default:
+		return fmt.Errorf("%w: format must be %s or %s", apperrors.ErrInvalidImportFile, ImportCSV, ImportJSONL)
+	}
+
+	var chunk importChunk
+	flush := func() error {
+		if len(chunk.requests) > 0 {
+			created, err := s.linkService.CreateDurableLinks(ctx, chunk.requests)
+			if err != nil {
+				return err
+			}
+			for j, result := range created {
+				chunk.results[chunk.indexes[j]].Link = result.Link
+				chunk.results[chunk.indexes[j]].Err = result.Err
+			}
+		}
+		for _, result := range chunk.results {
+			report(result)
+		}
+		chunk = importChunk{}
+		return nil
+	}
+
+	batchSize := max(s.cfg.App.BatchMaxLinks, 1)
+	rows := 0
+	for {
+		row, req, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, apperrors.ErrInvalidImportFile) {
+			if flushErr := flush(); flushErr != nil {
+				return flushErr
+			}
+			return err
+		}
+
+		// Each row names its host, so whether the caller may work on it is only known here.
+		if err == nil && !authz.MayAccess(ctx, req.DurableLinkInfo.Host) {
+			err = apperrors.ErrProjectNotAllowed
+		}
+		rows++
+		chunk.results = append(chunk.results, ImportResult{Row: row, Err: err})
+		if err != nil {
+			continue
+		}
+		chunk.requests = append(chunk.requests, req)
+		chunk.indexes = append(chunk.indexes, len(chunk.results)-1)
+		if len(chunk.requests) == batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	log.Ctx(ctx).Info().Int("rows", rows).Str("format", format).Msg("Links imported")
+	return nil
+}
+
+// csvRows reads the header of a CSV import, returning the reader of its rows.
+func (s *linkTransferService) csvRows(ctx context.Context, r io.Reader) (func() (int, models.CreateDurableLinkRequest, error), error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: the file is empty", apperrors.ErrInvalidImportFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrInvalidImportFile, err)
+	}
+	longLinkColumn, pathColumn := -1, -1
+	for i, name := range header {
+		switch normalizeColumn(name) {
+		case "longdurablelink", "longlink":
+			longLinkColumn = i
+		case "path":
+			pathColumn = i
+		}
+	}
+	if longLinkColumn < 0 {
+		return nil, fmt.Errorf("%w: no longDurableLink column", apperrors.ErrInvalidImportFile)
+	}
+
+	return func() (int, models.CreateDurableLinkRequest, error) {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return 0, models.CreateDurableLinkRequest{}, err
+		}
+		if err != nil {
+			return 0, models.CreateDurableLinkRequest{}, fmt.Errorf("%w: %v", apperrors.ErrInvalidImportFile, err)
+		}
+		row, _ := reader.FieldPos(0)
+
+		req, err := s.linkService.PrepareDurableLinkRequest(ctx, map[string]any{"longDurableLink": importField(record, longLinkColumn)})
+		if err != nil {
+			return row, req, err
+		}
+		if path := importField(record, pathColumn); path != "" {
+			req.Suffix.Option = "CUSTOM"
+			req.Suffix.CustomPath = path
+		}
+		return row, req, nil
+	}, nil
+}
+
+// jsonlRows returns the reader of the rows of a JSONL import, skipping blank lines.
+func (s *linkTransferService) jsonlRows(ctx context.Context, r io.Reader) func() (int, models.CreateDurableLinkRequest, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxImportLine)
+	line := 0
+	return func() (int, models.CreateDurableLinkRequest, error) {
+		for scanner.Scan() {
+			line++
+			if strings.TrimSpace(scanner.Text()) == "" {
+				continue
+			}
+			var rawReq map[string]any
+			if err := json.Unmarshal(scanner.Bytes(), &rawReq); err != nil {
+				return line, models.CreateDurableLinkRequest{}, fmt.Errorf("%w: not a JSON object", apperrors.ErrInvalidImportRow)
+			}
+			req, err := s.linkService.PrepareDurableLinkRequest(ctx, rawReq)
+			return line, req, err
+		}
+		if err := scanner.Err(); err != nil {
+			return 0, models.CreateDurableLinkRequest{}, fmt.Errorf("%w: %v", apperrors.ErrInvalidImportFile, err)
+		}
+		return 0, models.CreateDurableLinkRequest{}, io.EOF
+	}
+}
+
+// ExportLinks writes the host's active links to w as CSV, oldest first, reading them a page at a
+// time. Archived and deleted links are left out.
+func (s *linkTransferService) ExportLinks(ctx context.Context, host string, w io.Writer) error {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return apperrors.ErrMissingHost
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportColumns); err != nil {
+		return err
+	}
+	page := models.LinkPage{Sort: "createdAt", Limit: exportPageSize}
+	exported := 0
+	for {
+		links, err := s.repo.ListLinks(ctx, host, page)
+		if err != nil {
+			return err
+		}
+		for _, link := range links {
+			err := writer.Write([]string{
+				link.Path,
+				fmt.Sprintf("%s://%s/%s", s.cfg.App.URLScheme, host, link.Path),
+				fmt.Sprintf("%s://%s/?%s", s.cfg.App.URLScheme, host, link.QueryParams),
+				link.CreatedAt.UTC().Format(time.RFC3339),
+				strconv.FormatInt(link.TotalClicks, 10),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+		exported += len(links)
+		if len(links) < page.Limit {
+			break
+		}
+		last := links[len(links)-1]
+		page.AfterValue, page.AfterID = listSortValues[page.Sort](last), last.ID
+	}
+
+	log.Ctx(ctx).Info().Str("host", host).Int("links", exported).Msg("Links exported")
+	return nil
+}
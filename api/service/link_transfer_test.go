@@ -0,0 +1,160 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/authz"
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportLinks(t *testing.T) {
+	cfg := &config.Config{App: &config.AppConfig{
+		URLScheme:      "https",
+		AllowedDomains: []string{"example.com"},
+		BatchMaxLinks:  2,
+	}}
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		format string
+		input  string
+	}{
+		{
+			name:   "csv",
+			format: ImportCSV,
+			input: "Path,Long Durable Link\n" +
+				"promo,https://acme.link/?link=https://example.com/a\n" +
+				",https://acme.link/?link=https://example.com/b\n" +
+				"taken,https://acme.link/?link=https://example.com/c\n" +
+				",https://acme.link/?link=https://evil.com\n",
+		},
+		{
+			name:   "jsonl",
+			format: ImportJSONL,
+			input: `{"durableLinkInfo":{"host":"acme.link","link":"https://example.com/a"},"suffix":{"option":"CUSTOM","customPath":"promo"}}` + "\n" +
+				"\n" +
+				`{"longDurableLink":"https://acme.link/?link=https://example.com/b"}` + "\n" +
+				`{"durableLinkInfo":{"host":"acme.link","link":"https://example.com/c"},"suffix":{"option":"CUSTOM","customPath":"taken"}}` + "\n" +
+				`{"longDurableLink":"https://acme.link/?link=https://evil.com"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeLinkRepository{links: []models.StoredLink{{Host: "acme.link", Path: "taken"}}}
+			s := NewLinkTransferService(newTestLinkService(t, repo, cfg), repo, cfg)
+
+			var results []ImportResult
+			err := s.ImportLinks(ctx, strings.NewReader(tt.input), tt.format, func(result ImportResult) {
+				results = append(results, result)
+			})
+			assert.NoError(t, err)
+			if !assert.Len(t, results, 4) {
+				return
+			}
+
+			assert.NoError(t, results[0].Err)
+			assert.Equal(t, "https://acme.link/promo", results[0].Link.ShortLink)
+			assert.NoError(t, results[1].Err)
+			assert.Equal(t, 3, results[1].Row)
+			assert.ErrorIs(t, results[2].Err, apperrors.ErrPathTaken)
+			assert.ErrorIs(t, results[3].Err, apperrors.ErrDomainLinkNotAllowed)
+			assert.Equal(t, 5, results[3].Row)
+			assert.Len(t, repo.links, 3)
+		})
+	}
+}
+
+func TestImportLinks_ProjectScope(t *testing.T) {
+	cfg := &config.Config{App: &config.AppConfig{URLScheme: "https", AllowedDomains: []string{"example.com"}, BatchMaxLinks: 10}}
+	repo := &fakeLinkRepository{}
+	s := NewLinkTransferService(newTestLinkService(t, repo, cfg), repo, cfg)
+	ctx := authz.WithProjects(context.Background(), []string{"acme.link"})
+
+	input := "Path,Long Durable Link\n" +
+		"a,https://ACME.link/?link=https://example.com/a\n" +
+		"b,https://victim.link/?link=https://example.com/b\n"
+	var results []ImportResult
+	err := s.ImportLinks(ctx, strings.NewReader(input), ImportCSV, func(result ImportResult) {
+		results = append(results, result)
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, results, 2) {
+		assert.NoError(t, results[0].Err)
+		assert.ErrorIs(t, results[1].Err, apperrors.ErrProjectNotAllowed)
+	}
+	assert.Len(t, repo.links, 1)
+}
+
+func TestImportLinks_InvalidFile(t *testing.T) {
+	cfg := &config.Config{App: &config.AppConfig{URLScheme: "https", BatchMaxLinks: 10}}
+	repo := &fakeLinkRepository{}
+	s := NewLinkTransferService(newTestLinkService(t, repo, cfg), repo, cfg)
+	ctx := context.Background()
+	ignore := func(ImportResult) {}
+
+	assert.ErrorIs(t, s.ImportLinks(ctx, strings.NewReader("a\n"), "xml", ignore), apperrors.ErrInvalidImportFile)
+	assert.ErrorIs(t, s.ImportLinks(ctx, strings.NewReader(""), ImportCSV, ignore), apperrors.ErrInvalidImportFile)
+	assert.ErrorIs(t, s.ImportLinks(ctx, strings.NewReader("path,link\na,b\n"), ImportCSV, ignore),
+		apperrors.ErrInvalidImportFile, "no longDurableLink column")
+
+	var results []ImportResult
+	err := s.ImportLinks(ctx, strings.NewReader("not json\n"), ImportJSONL, func(result ImportResult) {
+		results = append(results, result)
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.ErrorIs(t, results[0].Err, apperrors.ErrInvalidImportRow)
+	}
+}
+
+func TestExportLinks(t *testing.T) {
+	cfg := &config.Config{App: &config.AppConfig{URLScheme: "https"}}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{ID: 1, Host: "other.link", Path: "x", QueryParams: "link=https%3A%2F%2Fexample.com", CreatedAt: start},
+	}}
+	// One more link than a page, so that the export reads two.
+	for i := range exportPageSize + 1 {
+		repo.links = append(repo.links, models.StoredLink{
+			ID:          int64(i + 2),
+			Host:        "acme.link",
+			Path:        fmt.Sprintf("p%d", i),
+			QueryParams: fmt.Sprintf("link=https%%3A%%2F%%2Fexample.com%%2F%d", i),
+			CreatedAt:   start.Add(time.Duration(i) * time.Second),
+			TotalClicks: int64(i),
+		})
+	}
+	s := NewLinkTransferService(newTestLinkService(t, repo, cfg), repo, cfg)
+
+	var out bytes.Buffer
+	assert.NoError(t, s.ExportLinks(context.Background(), "acme.link", &out))
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read export: %v", err)
+	}
+	if !assert.Len(t, records, exportPageSize+2) {
+		return
+	}
+	assert.Equal(t, exportColumns, records[0])
+	assert.Equal(t, []string{
+		"p0",
+		"https://acme.link/p0",
+		"https://acme.link/?link=https%3A%2F%2Fexample.com%2F0",
+		"2024-01-01T00:00:00Z",
+		"0",
+	}, records[1])
+	assert.Equal(t, "p1000", records[exportPageSize+1][0])
+
+	assert.ErrorIs(t, s.ExportLinks(context.Background(), "", &out), apperrors.ErrMissingHost)
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/webhooks"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+// GetLink returns the link path resolves to, following aliases and successors like redirects do,
+// along with its ETag.
+func (s *linkService) GetLink(ctx context.Context, host, path string) (*models.StoredLinkResponse, error) {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return nil, apperrors.ErrMissingHost
+	}
+	link, err := s.repo.GetLink(ctx, host, path)
+	if err != nil {
+		return nil, err
+	}
+	return s.linkResponse(*link), nil
+}
+
+// UpdateLink changes the destination, fallbacks, social metadata and metadata of the link path
+// resolves to, keeping its short link. With ifMatch set, the link is only changed if its ETag is one of
+// those listed. Either way a concurrent change between reading and writing the link fails the
+// update with ErrLinkChanged rather than being overwritten.
+func (s *linkService) UpdateLink(ctx context.Context, host, path, ifMatch string, req models.UpdateLinkRequest) (*models.StoredLinkResponse, error) {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return nil, apperrors.ErrMissingHost
+	}
+
+	release, err := s.acquireTenant(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	link, err := s.repo.GetLink(ctx, host, path)
+	if err != nil {
+		return nil, err
+	}
+	if ifMatch != "" && !etagMatches(ifMatch, linkETag(*link)) {
+		return nil, apperrors.ErrLinkChanged
+	}
+
+	params, err := url.ParseQuery(link.QueryParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stored query params: %w", err)
+	}
+	settings, err := s.linkSettings(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	changes := []struct {
+		param string
+		value *string
+		isURL bool
+	}{
+		{"link", req.Link, true},
+		{"afl", req.AndroidFallbackLink, true},
+		{"ifl", req.IosFallbackLink, true},
+		{"ipfl", req.IosIpadFallbackLink, true},
+		{"ofl", req.FallbackURL, true},
+		{"st", req.SocialTitle, false},
+		{"sd", req.SocialDescription, false},
+		{"si", req.SocialImageLink, true},
+	}
+	// updatedURLs are the URLs the request sets, the only ones looked up for their reputation.
+	updatedURLs := url.Values{}
+	for _, change := range changes {
+		switch {
+		case change.value == nil:
+			continue
+		case *change.value == "":
+			if change.param == "link" {
+				return nil, apperrors.ErrMissingLink
+			}
+			params.Del(change.param)
+			continue
+		case change.isURL:
+			if err := validateRewrittenURL(change.param, *change.value, settings); err != nil {
+				return nil, fmt.Errorf("%w '%s': %w", apperrors.ErrInvalidLinkParam, change.param, err)
+			}
+			updatedURLs.Set(change.param, *change.value)
+		}
+		params.Set(change.param, *change.value)
+	}
+
+	warnings, err := s.scanForPII(destinationInfo(params))
+	if err != nil {
+		return nil, err
+	}
+	reputationWarnings, err := s.checkReputation(ctx, destinationInfo(updatedURLs))
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, reputationWarnings...)
+
+	metadata := link.Metadata
+	if req.Name != nil {
+		metadata.Name = *req.Name
+	}
+	if req.Notes != nil {
+		metadata.Notes = *req.Notes
+	}
+	if req.Tags != nil {
+		metadata.Tags = *req.Tags
+	}
+	if metadata, err = normalizeMetadata(metadata); err != nil {
+		return nil, err
+	}
+
+	previous := link.QueryParams
+	link.QueryParams = params.Encode()
+	paramsChanged := link.QueryParams != previous
+	if paramsChanged {
+		if err := s.repo.ReplaceQueryParams(ctx, *link, previous, "update"); err != nil {
+			return nil, err
+		}
+	}
+	metadataChanged := !slices.Equal(metadata.Tags, link.Metadata.Tags) ||
+		metadata.Name != link.Metadata.Name || metadata.Notes != link.Metadata.Notes
+	if metadataChanged {
+		if err := s.repo.SetLinkMetadata(ctx, host, link.Path, metadata); err != nil {
+			return nil, err
+		}
+		link.Metadata = metadata
+	}
+	if paramsChanged || metadataChanged {
+		log.Ctx(ctx).Info().
+			Str("host", host).
+			Str("path", link.Path).
+			Msg("Link updated")
+		s.publishLink(ctx, webhooks.EventLinkUpdated, host, link.Path, link.QueryParams)
+	}
+
+	resp := s.linkResponse(*link)
+	resp.Warnings = warnings
+	return resp, nil
+}
+
+func (s *linkService) linkResponse(link models.StoredLink) *models.StoredLinkResponse {
+	shortLink := fmt.Sprintf("%s://%s/%s", s.cfg.App.URLScheme, link.Host, link.Path)
+	resp := &models.StoredLinkResponse{
+		ShortLink:    shortLink,
+		LongLink:     shortLink + "?" + link.QueryParams,
+		ETag:         linkETag(link),
+		LinkMetadata: link.Metadata,
+	}
+	if params, _ := url.ParseQuery(link.QueryParams); isReserved(params) {
+		resp.LongLink = ""
+		resp.Reserved = true
+	}
+	return resp
+}
+
+// linkETag is the strong ETag of link, covering its query params and metadata, everything a
+// PATCH can change. Links without metadata keep the ETag of their query params alone.
+func linkETag(link models.StoredLink) string {
+	h := sha256.New()
+	h.Write([]byte(link.QueryParams))
+	if metadata := link.Metadata; !metadata.IsZero() {
+		fmt.Fprintf(h, "\x00%q\x00%q\x00%q", metadata.Name, metadata.Notes, metadata.Tags)
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:8]) + `"`
+}
+
+// etagMatches reports whether an If-Match header value lists etag, or is "*".
+func etagMatches(ifMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// destinationInfo holds the destination, fallback, app and image URLs among params.
+func destinationInfo(params url.Values) models.DurableLinkInfo {
+	return models.DurableLinkInfo{
+		Link:                    params.Get("link"),
+		AndroidParameters:       models.AndroidParameters{AndroidFallbackLink: params.Get("afl"), AndroidAppGalleryLink: params.Get("agl")},
+		IosParameters:           models.IosParameters{IosFallbackLink: params.Get("ifl"), IosIpadFallbackLink: params.Get("ipfl")},
+		OtherPlatformParameters: models.OtherPlatformParameters{FallbackURL: params.Get("ofl")},
+		DesktopParameters: models.DesktopParameters{
+			DesktopProtocolLink: params.Get("dpl"),
+			MicrosoftStoreLink:  params.Get("msl"),
+			MacAppStoreLink:     params.Get("masl"),
+		},
+		PlatformLinks:     models.PlatformLinks{WebLink: params.Get("wl"), AndroidLink: params.Get("al"), IosLink: params.Get("il")},
+		SocialMetaTagInfo: models.SocialMetaTagInfo{SocialImageLink: params.Get("si")},
+		ExpiredLink:       params.Get("efl"),
+	}
+}
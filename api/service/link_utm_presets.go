@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+)
+
+// WithUTMPresets sets where the UTM presets links refer to by analyticsInfo.presetName are read
+// from. Without it, links naming a preset are rejected.
+func (s *linkService) WithUTMPresets(presets repository.UTMPresetRepository) *linkService {
+	s.utmPresets = presets
+	return s
+}
+
+// marketingParameters returns the UTM params of a link on host: those of info, with the ones it
+// leaves empty taken from the preset it names.
+func (s *linkService) marketingParameters(ctx context.Context, host string, info models.AnalyticsInfo) (models.MarketingParameters, error) {
+	params := info.MarketingParameters
+	if info.PresetName == "" {
+		return params, nil
+	}
+	if s.utmPresets == nil {
+		return params, fmt.Errorf("%w: %s", apperrors.ErrUTMPresetNotFound, info.PresetName)
+	}
+	preset, err := s.utmPresets.GetUTMPreset(ctx, host, info.PresetName)
+	if err != nil {
+		return params, fmt.Errorf("%w: %s", err, info.PresetName)
+	}
+
+	fill := func(value *string, preset string) {
+		if *value == "" {
+			*value = preset
+		}
+	}
+	fill(&params.UtmSource, preset.MarketingParameters.UtmSource)
+	fill(&params.UtmMedium, preset.MarketingParameters.UtmMedium)
+	fill(&params.UtmCampaign, preset.MarketingParameters.UtmCampaign)
+	fill(&params.UtmTerm, preset.MarketingParameters.UtmTerm)
+	fill(&params.UtmContent, preset.MarketingParameters.UtmContent)
+	return params, nil
+}
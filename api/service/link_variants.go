@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Variants an A/B test needs at least and can have at most, and the highest weight of one.
+const (
+	minLinkVariants  = 2
+	maxLinkVariants  = 10
+	maxVariantWeight = 1000
+)
+
+var variantNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// normalizeLinkVariants validates the A/B test variants of a link. Their links must be allowed
+// destinations, like the link they replace.
+func normalizeLinkVariants(variants []models.LinkVariant, allowedDomains []string) ([]models.LinkVariant, error) {
+	if len(variants) == 0 {
+		return nil, nil
+	}
+	if len(variants) < minLinkVariants || len(variants) > maxLinkVariants {
+		return nil, fmt.Errorf("%w: a link has from %d to %d variants", apperrors.ErrInvalidVariant, minLinkVariants, maxLinkVariants)
+	}
+	seen := map[string]bool{}
+	for _, variant := range variants {
+		if !variantNamePattern.MatchString(variant.Name) {
+			return nil, fmt.Errorf("%w: name %q may only hold up to 64 letters, digits, '-' and '_'", apperrors.ErrInvalidVariant, variant.Name)
+		}
+		if seen[variant.Name] {
+			return nil, fmt.Errorf("%w: name %q is used twice", apperrors.ErrInvalidVariant, variant.Name)
+		}
+		seen[variant.Name] = true
+		if variant.Weight < 1 || variant.Weight > maxVariantWeight {
+			return nil, fmt.Errorf("%w: weight of %q must be from 1 to %d", apperrors.ErrInvalidVariant, variant.Name, maxVariantWeight)
+		}
+		if err := utils.ValidateURLScheme(variant.Link); err != nil {
+			return nil, fmt.Errorf("%w: link of %q must be an absolute http(s) URL", apperrors.ErrInvalidVariant, variant.Name)
+		}
+		if !utils.IsDomainAllowed(allowedDomains, variant.Link) {
+			return nil, apperrors.ErrDomainLinkNotAllowed
+		}
+	}
+	return variants, nil
+}
+
+// linkVariant picks the variant of the link at path for the visitor identified by visitorID,
+// or returns nil when the link has none. Failed lookups are logged and pick none.
+func (s *linkService) linkVariant(ctx context.Context, host, path, visitorID string) *models.LinkVariant {
+	variants, err := s.repo.ResolveLinkVariants(ctx, host, path)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("host", host).Str("path", path).Msg("Failed to read link variants")
+		return nil
+	}
+	if len(variants) == 0 {
+		return nil
+	}
+	return pickVariant(variants, host+"/"+path, visitorID)
+}
+
+// pickVariant places the visitor on one of variants by a hash of their ID and the link, so a
+// visitor always gets the same variant of a link, and different links split visitors apart.
+func pickVariant(variants []models.LinkVariant, link, visitorID string) *models.LinkVariant {
+	total := 0
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+	sum := sha256.Sum256([]byte(link + "\x00" + visitorID))
+	n := int(binary.BigEndian.Uint64(sum[:8]) % uint64(total))
+	for i := range variants {
+		if n < variants[i].Weight {
+			return &variants[i]
+		}
+		n -= variants[i].Weight
+	}
+	return &variants[len(variants)-1]
+}
+
+// visitorFingerprint identifies a visitor without a visitor cookie by their address and browser.
+func visitorFingerprint(click models.ClickContext) string {
+	sum := sha256.Sum256([]byte(click.IP + "\x00" + click.UserAgent))
+	return hex.EncodeToString(sum[:16])
+}
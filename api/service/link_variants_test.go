@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkVariants(t *testing.T) {
+	repo := &fakeLinkRepository{}
+	recorder := &fakeClickRecorder{}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:      "https",
+		AllowedDomains: []string{"example.com"},
+	}}).WithClickEvents(recorder)
+	ctx := context.Background()
+	req := func(variants ...models.LinkVariant) models.CreateDurableLinkRequest {
+		return models.CreateDurableLinkRequest{
+			DurableLinkInfo: models.DurableLinkInfo{
+				Host:     "acme.link",
+				Link:     "https://example.com/app",
+				Variants: variants,
+			},
+			Suffix: models.Suffix{Option: "CUSTOM", CustomPath: "app"},
+		}
+	}
+
+	_, err := s.CreateDurableLink(ctx, req(
+		models.LinkVariant{Name: "a", Link: "https://example.com/a", Weight: 1},
+		models.LinkVariant{Name: "b", Link: "https://example.com/b", Weight: 1},
+	))
+	assert.NoError(t, err)
+
+	seen := map[string]int{}
+	for i := range 200 {
+		click := models.ClickContext{VisitorID: fmt.Sprintf("visitor-%d", i)}
+		target, err := s.ResolveRedirect(ctx, "acme.link", "app", click)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://example.com/"+target.Variant, target.Destination)
+		assert.Empty(t, target.VisitorID, "visitors with a cookie keep it")
+		seen[target.Variant]++
+
+		again, err := s.ResolveRedirect(ctx, "acme.link", "app", click)
+		assert.NoError(t, err)
+		assert.Equal(t, target.Variant, again.Variant, "a visitor always gets the same variant")
+	}
+	assert.Greater(t, seen["a"], 60)
+	assert.Greater(t, seen["b"], 60)
+	if assert.NotEmpty(t, recorder.events) {
+		assert.NotEmpty(t, recorder.events[0].Variant)
+	}
+
+	target, err := s.ResolveRedirect(ctx, "acme.link", "app", models.ClickContext{IP: "1.2.3.4", UserAgent: iPhoneUserAgent})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, target.VisitorID, "new visitors get a cookie")
+
+	for _, variants := range [][]models.LinkVariant{
+		{{Name: "a", Link: "https://example.com/a", Weight: 1}},
+		{{Name: "a", Link: "https://example.com/a", Weight: 1}, {Name: "a", Link: "https://example.com/b", Weight: 1}},
+		{{Name: "a b", Link: "https://example.com/a", Weight: 1}, {Name: "c", Link: "https://example.com/b", Weight: 1}},
+		{{Name: "a", Link: "https://example.com/a", Weight: 0}, {Name: "b", Link: "https://example.com/b", Weight: 1}},
+		{{Name: "a", Link: "ftp://example.com/a", Weight: 1}, {Name: "b", Link: "https://example.com/b", Weight: 1}},
+	} {
+		_, err := s.CreateDurableLink(ctx, req(variants...))
+		assert.ErrorIs(t, err, apperrors.ErrInvalidVariant, "%+v", variants)
+	}
+	_, err = s.CreateDurableLink(ctx, req(
+		models.LinkVariant{Name: "a", Link: "https://example.com/a", Weight: 1},
+		models.LinkVariant{Name: "b", Link: "https://evil.test/b", Weight: 1},
+	))
+	assert.ErrorIs(t, err, apperrors.ErrDomainLinkNotAllowed)
+}
+
+func TestPickVariant(t *testing.T) {
+	variants := []models.LinkVariant{{Name: "a", Weight: 9}, {Name: "b", Weight: 1}}
+	seen := map[string]int{}
+	for i := range 1000 {
+		seen[pickVariant(variants, "acme.link/app", fmt.Sprint(i)).Name]++
+	}
+	assert.InDelta(t, 900, seen["a"], 60)
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/utils"
+)
+
+// Codes of link creation warnings.
+const (
+	WarningMalformedParam    = "MALFORMED_PARAM"
+	WarningUnrecognizedParam = "UNRECOGNIZED_PARAM"
+	WarningMissingApp        = "MISSING_APP_PARAM"
+	WarningPIIDetected       = "PII_DETECTED"
+	WarningPolicy            = "POLICY_WARNING"
+	WarningUnsafeURL         = "UNSAFE_URL"
+	// Warnings of links created with validateDestination=true.
+	WarningDestinationUnreachable = "DESTINATION_UNREACHABLE"
+	WarningDestinationError       = "DESTINATION_ERROR"
+	WarningOffDomainRedirect      = "OFF_DOMAIN_REDIRECT"
+)
+
+// warningRule checks link requests for one kind of problem that doesn't stop the link from
+// working, returning a message for each place it is found.
+type warningRule struct {
+	code     string
+	severity string
+	check    func(info models.DurableLinkInfo) []string
+}
+
+// warningRules are run over every link request, in order.
+var warningRules = []warningRule{
+	{WarningMalformedParam, models.WarningSeverityWarning, malformedParams},
+	{WarningUnrecognizedParam, models.WarningSeverityInfo, unneededAnalyticsParams},
+	{WarningMissingApp, models.WarningSeverityInfo, paramsWithoutApp},
+}
+
+// linkWarnings runs the warning rules over info.
+func linkWarnings(info models.DurableLinkInfo) []models.DurableLinkCreationWarning {
+	warnings := []models.DurableLinkCreationWarning{}
+	for _, rule := range warningRules {
+		for _, message := range rule.check(info) {
+			warnings = append(warnings, models.DurableLinkCreationWarning{
+				WarningCode:    rule.code,
+				WarningMessage: message,
+				Severity:       rule.severity,
+			})
+		}
+	}
+	return warnings
+}
+
+// checkWarnings fails with ErrWarningAsError for the first of warnings whose code is listed in
+// WARNINGS_AS_ERRORS, so deployments can reject links they would only be warned about.
+func (s *linkService) checkWarnings(warnings []models.DurableLinkCreationWarning) error {
+	for _, warning := range warnings {
+		if slices.Contains(s.cfg.App.WarningsAsErrors, warning.WarningCode) {
+			return fmt.Errorf("%w: %s: %s", apperrors.ErrWarningAsError, warning.WarningCode, warning.WarningMessage)
+		}
+	}
+	return nil
+}
+
+func malformedParams(info models.DurableLinkInfo) []string {
+	var messages []string
+	urls := []struct{ param, value string }{
+		{"afl", info.AndroidParameters.AndroidFallbackLink},
+		{"ifl", info.IosParameters.IosFallbackLink},
+		{"ipfl", info.IosParameters.IosIpadFallbackLink},
+		{"ofl", info.OtherPlatformParameters.FallbackURL},
+		{"si", info.SocialMetaTagInfo.SocialImageLink},
+	}
+	for _, u := range urls {
+		if u.value != "" && !utils.IsURL(u.value) {
+			messages = append(messages, fmt.Sprintf("Param '%s' is not a valid URL", u.param))
+		}
+	}
+	if amv := info.AndroidParameters.AndroidMinPackageVersionCode; amv != "" && !utils.IsNumericString(amv) {
+		messages = append(messages, "Param 'amv' is not a version code")
+	}
+	return messages
+}
+
+// unneededAnalyticsParams reports App Store analytics params that can't be used, as Firebase did.
+func unneededAnalyticsParams(info models.DurableLinkInfo) []string {
+	analytics := info.AnalyticsInfo.ItunesConnectAnalytics
+	params := []struct{ param, value string }{
+		{"at", analytics.At},
+		{"ct", analytics.Ct},
+		{"mt", analytics.Mt},
+	}
+
+	var messages []string
+	if info.IosParameters.IosAppStoreId == "" {
+		for _, p := range append(params, struct{ param, value string }{"pt", analytics.Pt}) {
+			if p.value != "" {
+				messages = append(messages, fmt.Sprintf("Param '%s' is not needed, since 'isi' is not specified.", p.param))
+			}
+		}
+	}
+	if analytics.Pt == "" {
+		for _, p := range params {
+			if p.value != "" {
+				messages = append(messages, fmt.Sprintf("Param '%s' is not needed, since 'pt' is not specified.", p.param))
+			}
+		}
+	}
+	return messages
+}
+
+// paramsWithoutApp reports app params given for a platform the link has no app on.
+func paramsWithoutApp(info models.DurableLinkInfo) []string {
+	var messages []string
+	if info.AndroidParameters.AndroidPackageName == "" {
+		if info.AndroidParameters.AndroidFallbackLink != "" {
+			messages = append(messages, "Param 'afl' sends every Android visitor away from 'link', since 'apn' is not specified.")
+		}
+		if info.AndroidParameters.AndroidMinPackageVersionCode != "" {
+			messages = append(messages, "Param 'amv' is not needed, since 'apn' is not specified.")
+		}
+	}
+	if info.IosParameters.IosAppStoreId == "" {
+		if info.IosParameters.IosFallbackLink != "" {
+			messages = append(messages, "Param 'ifl' sends every iOS visitor away from 'link', since 'isi' is not specified.")
+		}
+		if info.IosParameters.IosIpadFallbackLink != "" {
+			messages = append(messages, "Param 'ipfl' is not needed, since 'isi' is not specified.")
+		}
+	}
+	return messages
+}
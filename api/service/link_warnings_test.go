@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkWarnings(t *testing.T) {
+	tests := []struct {
+		name  string
+		info  models.DurableLinkInfo
+		codes []string
+	}{
+		{
+			name: "complete link",
+			info: models.DurableLinkInfo{
+				Link:              "https://example.com",
+				AndroidParameters: models.AndroidParameters{AndroidPackageName: "com.acme", AndroidFallbackLink: "https://example.com/android", AndroidMinPackageVersionCode: "12"},
+				IosParameters:     models.IosParameters{IosAppStoreId: "123", IosIpadFallbackLink: "https://example.com/ipad"},
+			},
+		},
+		{
+			name:  "malformed URLs",
+			info:  models.DurableLinkInfo{OtherPlatformParameters: models.OtherPlatformParameters{FallbackURL: "example.com"}, SocialMetaTagInfo: models.SocialMetaTagInfo{SocialImageLink: "/a.png"}},
+			codes: []string{WarningMalformedParam, WarningMalformedParam},
+		},
+		{
+			name:  "version code",
+			info:  models.DurableLinkInfo{AndroidParameters: models.AndroidParameters{AndroidPackageName: "com.acme", AndroidMinPackageVersionCode: "1.2"}},
+			codes: []string{WarningMalformedParam},
+		},
+		{
+			name:  "analytics without isi or pt",
+			info:  models.DurableLinkInfo{AnalyticsInfo: models.AnalyticsInfo{ItunesConnectAnalytics: models.ItunesConnectAnalytics{At: "a"}}},
+			codes: []string{WarningUnrecognizedParam, WarningUnrecognizedParam},
+		},
+		{
+			name: "fallbacks without apps",
+			info: models.DurableLinkInfo{
+				AndroidParameters: models.AndroidParameters{AndroidFallbackLink: "https://example.com/android", AndroidMinPackageVersionCode: "12"},
+				IosParameters:     models.IosParameters{IosFallbackLink: "https://example.com/ios", IosIpadFallbackLink: "https://example.com/ipad"},
+			},
+			codes: []string{WarningMissingApp, WarningMissingApp, WarningMissingApp, WarningMissingApp},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var codes []string
+			for _, warning := range linkWarnings(tt.info) {
+				codes = append(codes, warning.WarningCode)
+				assert.NotEmpty(t, warning.Severity)
+			}
+			assert.Equal(t, tt.codes, codes)
+		})
+	}
+}
+
+func TestWarningsAsErrors(t *testing.T) {
+	repo := &fakeLinkRepository{}
+	cfg := &config.Config{App: &config.AppConfig{
+		URLScheme:      "https",
+		AllowedDomains: []string{"example.com"},
+	}}
+	s := newTestLinkService(t, repo, cfg)
+	ctx := context.Background()
+	req := models.CreateDurableLinkRequest{DurableLinkInfo: models.DurableLinkInfo{
+		Host:              "acme.link",
+		Link:              "https://example.com",
+		AndroidParameters: models.AndroidParameters{AndroidPackageName: "com.acme", AndroidMinPackageVersionCode: "1.2"},
+		IosParameters:     models.IosParameters{IosIpadFallbackLink: "https://example.com/ipad"},
+	}}
+
+	resp, err := s.CreateDurableLink(ctx, req)
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Warnings, 2) {
+		assert.Equal(t, models.DurableLinkCreationWarning{
+			WarningCode:    WarningMalformedParam,
+			WarningMessage: "Param 'amv' is not a version code",
+			Severity:       models.WarningSeverityWarning,
+		}, resp.Warnings[0])
+		assert.Equal(t, models.WarningSeverityInfo, resp.Warnings[1].Severity)
+	}
+
+	cfg.App.WarningsAsErrors = []string{WarningMalformedParam}
+	stored := len(repo.links)
+	_, err = s.CreateDurableLink(ctx, req)
+	assert.ErrorIs(t, err, apperrors.ErrWarningAsError)
+	assert.ErrorContains(t, err, "Param 'amv' is not a version code")
+	assert.Len(t, repo.links, stored, "rejected links aren't stored")
+}
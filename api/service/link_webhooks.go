@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"durable-links-generator/api/webhooks"
+)
+
+// WithWebhooks sets where link created, updated and deleted events are published for the
+// webhooks of their host.
+func (s *linkService) WithWebhooks(publisher webhooks.Publisher) *linkService {
+	s.webhooks = publisher
+	return s
+}
+
+// publishLink publishes an event of the given type about the link at path on host, stored with
+// queryParams.
+func (s *linkService) publishLink(ctx context.Context, eventType, host, path, queryParams string) {
+	s.webhooks.Publish(ctx, linkEvent(s.cfg.App.URLScheme, eventType, host, path, queryParams))
+}
+
+func linkEvent(urlScheme, eventType, host, path, queryParams string) webhooks.Event {
+	params, _ := url.ParseQuery(queryParams)
+	return webhooks.Event{
+		Type: eventType,
+		Host: host,
+		Link: webhooks.Link{
+			ShortLink: fmt.Sprintf("%s://%s/%s", urlScheme, host, path),
+			Path:      path,
+			Link:      params.Get("link"),
+		},
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/utils"
+)
+
+// Params redirecting visitors when the app isn't installed, checked against the fallback allow
+// list so that links can't be used as open redirects.
+var fallbackParams = []string{"afl", "ifl", "ipfl", "ofl"}
+
+// App links may use the app's own scheme, but web ones redirect like the main link (al, il) or a
+// fallback (agl, msl, masl) and are checked against the same allow list.
+var (
+	appLinkParams   = []string{"al", "il"}
+	storeLinkParams = []string{"agl", "msl", "masl"}
+)
+
+// validateParamDomains checks the fallback, app and social image URLs of info against their allow
+// lists.
+func validateParamDomains(info models.DurableLinkInfo, settings linkSettings) error {
+	for _, p := range []struct{ param, value string }{
+		{"afl", info.AndroidParameters.AndroidFallbackLink},
+		{"ifl", info.IosParameters.IosFallbackLink},
+		{"ipfl", info.IosParameters.IosIpadFallbackLink},
+		{"ofl", info.OtherPlatformParameters.FallbackURL},
+		{"si", info.SocialMetaTagInfo.SocialImageLink},
+		{"al", info.PlatformLinks.AndroidLink},
+		{"il", info.PlatformLinks.IosLink},
+		{"agl", info.AndroidParameters.AndroidAppGalleryLink},
+		{"msl", info.DesktopParameters.MicrosoftStoreLink},
+		{"masl", info.DesktopParameters.MacAppStoreLink},
+	} {
+		if p.value == "" {
+			continue
+		}
+		if err := validateParamDomain(p.param, p.value, settings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateParamDomain checks the host of rawURL against the allow list of param: ALLOWED_DOMAINS
+// for link and app links, FALLBACK_ALLOWED_DOMAINS for fallbacks and store links and
+// IMAGE_ALLOWED_DOMAINS for si. Fallbacks and images must be absolute web URLs, as browsers
+// resolve hostless values like "https:/\evil.example" to hosts of their own. App and store links
+// with an app's scheme aren't checked here.
+func validateParamDomain(param, rawURL string, settings linkSettings) error {
+	switch {
+	case param == "link":
+		if !utils.IsDomainAllowed(settings.allowedDomains, rawURL) {
+			return apperrors.ErrDomainLinkNotAllowed
+		}
+	case slices.Contains(appLinkParams, param):
+		if isWebScheme(rawURL) && (!isWebURL(rawURL) || !utils.IsDomainAllowed(settings.allowedDomains, rawURL)) {
+			return apperrors.ErrDomainLinkNotAllowed
+		}
+	case slices.Contains(storeLinkParams, param):
+		if isWebScheme(rawURL) && (!isWebURL(rawURL) || !domainAllowed(settings.fallbackDomains, rawURL)) {
+			return fmt.Errorf("param '%s': %w", param, apperrors.ErrFallbackNotAllowed)
+		}
+	case slices.Contains(fallbackParams, param):
+		if !isWebURL(rawURL) || !domainAllowed(settings.fallbackDomains, rawURL) {
+			return fmt.Errorf("param '%s': %w", param, apperrors.ErrFallbackNotAllowed)
+		}
+	case param == "si":
+		if !isWebURL(rawURL) || !domainAllowed(settings.imageDomains, rawURL) {
+			return fmt.Errorf("param '%s': %w", param, apperrors.ErrImageNotAllowed)
+		}
+	}
+	return nil
+}
+
+// isWebURL reports whether rawURL is an absolute http or https URL with a host.
+func isWebURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && isWebScheme(rawURL) && u.Host != "" && !strings.Contains(rawURL, `\`)
+}
+
+// isWebScheme reports whether rawURL starts with an http or https scheme, however malformed the
+// rest of it is.
+func isWebScheme(rawURL string) bool {
+	scheme, _, found := strings.Cut(strings.TrimSpace(rawURL), ":")
+	return found && (strings.EqualFold(scheme, "http") || strings.EqualFold(scheme, "https"))
+}
+
+// domainAllowed is utils.IsDomainAllowed, with "*" in allowList allowing any host.
+func domainAllowed(allowList []string, rawURL string) bool {
+	return slices.Contains(allowList, "*") || utils.IsDomainAllowed(allowList, rawURL)
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"testing"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateParamDomains(t *testing.T) {
+	tests := []struct {
+		name     string
+		fallback []string
+		image    []string
+		info     models.DurableLinkInfo
+		wantErr  error
+	}{
+		{
+			name: "Fallback on the link allow list",
+			info: models.DurableLinkInfo{OtherPlatformParameters: models.OtherPlatformParameters{FallbackURL: "https://example.com/desktop"}},
+		},
+		{
+			name:    "Fallback off the link allow list",
+			info:    models.DurableLinkInfo{AndroidParameters: models.AndroidParameters{AndroidFallbackLink: "https://evil.example/"}},
+			wantErr: apperrors.ErrFallbackNotAllowed,
+		},
+		{
+			name:    "Protocol-relative fallback",
+			info:    models.DurableLinkInfo{IosParameters: models.IosParameters{IosFallbackLink: "//evil.example/"}},
+			wantErr: apperrors.ErrFallbackNotAllowed,
+		},
+		{
+			name:     "Fallback allow list",
+			fallback: []string{"store.example.org"},
+			info:     models.DurableLinkInfo{IosParameters: models.IosParameters{IosIpadFallbackLink: "https://store.example.org/ipad"}},
+		},
+		{
+			name:     "Fallback allow list replaces the link allow list",
+			fallback: []string{"store.example.org"},
+			info:     models.DurableLinkInfo{IosParameters: models.IosParameters{IosIpadFallbackLink: "https://example.com/ipad"}},
+			wantErr:  apperrors.ErrFallbackNotAllowed,
+		},
+		{
+			name:    "Image off the link allow list",
+			info:    models.DurableLinkInfo{SocialMetaTagInfo: models.SocialMetaTagInfo{SocialImageLink: "https://cdn.example.net/a.png"}},
+			wantErr: apperrors.ErrImageNotAllowed,
+		},
+		{
+			name:  "Any image host",
+			image: []string{"*"},
+			info:  models.DurableLinkInfo{SocialMetaTagInfo: models.SocialMetaTagInfo{SocialImageLink: "https://cdn.example.net/a.png"}},
+		},
+		{
+			name:    "Relative image",
+			info:    models.DurableLinkInfo{SocialMetaTagInfo: models.SocialMetaTagInfo{SocialImageLink: "a.png"}},
+			wantErr: apperrors.ErrImageNotAllowed,
+		},
+		{
+			name:    "Hostless fallback",
+			info:    models.DurableLinkInfo{OtherPlatformParameters: models.OtherPlatformParameters{FallbackURL: `https:/\evil.example`}},
+			wantErr: apperrors.ErrFallbackNotAllowed,
+		},
+		{
+			name: "App link with the app's scheme",
+			info: models.DurableLinkInfo{PlatformLinks: models.PlatformLinks{AndroidLink: "acme://item/1"}},
+		},
+		{
+			name:    "Web app link off the link allow list",
+			info:    models.DurableLinkInfo{PlatformLinks: models.PlatformLinks{IosLink: "https://evil.example/"}},
+			wantErr: apperrors.ErrDomainLinkNotAllowed,
+		},
+		{
+			name:     "AppGallery link on the fallback allow list",
+			fallback: []string{"appgallery.huawei.com"},
+			info:     models.DurableLinkInfo{AndroidParameters: models.AndroidParameters{AndroidAppGalleryLink: "https://appgallery.huawei.com/app/C1"}},
+		},
+		{
+			name:    "AppGallery link off the fallback allow list",
+			info:    models.DurableLinkInfo{AndroidParameters: models.AndroidParameters{AndroidAppGalleryLink: "HTTPS:/\\evil.example"}},
+			wantErr: apperrors.ErrFallbackNotAllowed,
+		},
+		{
+			name: "Microsoft Store link with the store's scheme",
+			info: models.DurableLinkInfo{DesktopParameters: models.DesktopParameters{MicrosoftStoreLink: "ms-windows-store://pdp/?productid=9N"}},
+		},
+		{
+			name:    "Mac App Store link off the fallback allow list",
+			info:    models.DurableLinkInfo{DesktopParameters: models.DesktopParameters{MacAppStoreLink: "https://evil.example/app"}},
+			wantErr: apperrors.ErrFallbackNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &linkService{cfg: &config.Config{App: &config.AppConfig{
+				FallbackAllowedDomains: tt.fallback,
+				ImageAllowedDomains:    tt.image,
+			}}}
+			settings := s.withParamDomains(linkSettings{allowedDomains: []string{"example.com"}})
+
+			err := validateParamDomains(tt.info, settings)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateRewrittenURL(t *testing.T) {
+	settings := linkSettings{allowedDomains: []string{"example.com"}, fallbackDomains: []string{"store.example.org"}}
+
+	assert.NoError(t, validateRewrittenURL("ofl", "https://store.example.org/", settings))
+	assert.ErrorIs(t, validateRewrittenURL("ofl", "https://example.com/", settings), apperrors.ErrFallbackNotAllowed)
+	assert.ErrorIs(t, validateRewrittenURL("link", "https://store.example.org/", settings), apperrors.ErrDomainLinkNotAllowed)
+	assert.ErrorIs(t, validateRewrittenURL("si", "https://example.com/a.png", settings), apperrors.ErrImageNotAllowed)
+}
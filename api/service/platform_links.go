@@ -0,0 +1,184 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/device"
+	"durable-links-generator/api/models"
+	"durable-links-generator/utils"
+)
+
+var platforms = []string{device.PlatformWeb, device.PlatformAndroid, device.PlatformIOS}
+
+// What DEVICE_POLICIES can do with visitors on TVs, consoles and watches: redirect as usual, send
+// them to the link's "ofl" fallback, or show a QR code of the short link to open on a phone.
+const (
+	devicePolicyRedirect = "redirect"
+	devicePolicyFallback = "ofl"
+	devicePolicyQRCode   = "qr"
+)
+
+// validateDevicePolicies checks the DEVICE_POLICIES config.
+func validateDevicePolicies(policies map[string]string) error {
+	formFactors := []string{device.FormFactorTV, device.FormFactorConsole, device.FormFactorWatch}
+	actions := []string{devicePolicyRedirect, devicePolicyFallback, devicePolicyQRCode}
+	for formFactor, action := range policies {
+		if !slices.Contains(formFactors, formFactor) {
+			return fmt.Errorf("unknown form factor %q, must be one of %v", formFactor, formFactors)
+		}
+		if !slices.Contains(actions, action) {
+			return fmt.Errorf("unknown action %q for %s, must be one of %v", action, formFactor, actions)
+		}
+	}
+	return nil
+}
+
+// Schemes that run code or read local files rather than open an app.
+var unsafeSchemes = []string{"javascript", "vbscript", "data", "file", "blob"}
+
+// validatePlatformLinks checks the per-platform destinations. The web link must be on an allowed
+// domain like the main link; the app and store links may use custom schemes but must be absolute.
+// Web app and store links are checked against the allow lists by validateParamDomains.
+func validatePlatformLinks(info models.DurableLinkInfo, allowedDomains []string) error {
+	links := info.PlatformLinks
+	if links.WebLink != "" {
+		if err := utils.ValidateURLScheme(links.WebLink); err != nil {
+			return fmt.Errorf("param 'wl': %w", apperrors.ErrInvalidPlatformLink)
+		}
+		if !utils.IsDomainAllowed(allowedDomains, links.WebLink) {
+			return apperrors.ErrDomainLinkNotAllowed
+		}
+	}
+	for param, link := range map[string]string{
+		"al":   links.AndroidLink,
+		"il":   links.IosLink,
+		"agl":  info.AndroidParameters.AndroidAppGalleryLink,
+		"dpl":  info.DesktopParameters.DesktopProtocolLink,
+		"msl":  info.DesktopParameters.MicrosoftStoreLink,
+		"masl": info.DesktopParameters.MacAppStoreLink,
+	} {
+		if link == "" {
+			continue
+		}
+		u, err := url.Parse(link)
+		if err != nil || u.Scheme == "" || slices.Contains(unsafeSchemes, strings.ToLower(u.Scheme)) {
+			return fmt.Errorf("param '%s': %w", param, apperrors.ErrInvalidPlatformLink)
+		}
+	}
+	return nil
+}
+
+// The params holding each platform's destination override.
+var platformParams = map[string]string{
+	device.PlatformWeb:     "wl",
+	device.PlatformAndroid: "al",
+	device.PlatformIOS:     "il",
+}
+
+// platformLink returns the stored destination for platform, falling back to the main link when
+// the link has no override for it.
+func platformLink(params url.Values, platform string) string {
+	if link := params.Get(platformParams[platform]); link != "" {
+		return link
+	}
+	return params.Get("link")
+}
+
+// fallbackLink returns where a visitor goes when the app didn't open the link itself, as Firebase
+// did: the platform's fallback link, else the app's store page, else "" to use the main link. A
+// request reaching the server means the app isn't installed, or it would have claimed the link.
+// TVs, consoles and watches only get "ofl" when DEVICE_POLICIES says so.
+func fallbackLink(params url.Values, visitor device.Device) string {
+	switch visitor.Platform {
+	case device.PlatformAndroid:
+		if afl := params.Get("afl"); afl != "" {
+			return afl
+		}
+		if apn := params.Get("apn"); apn != "" {
+			return playStoreLink(apn)
+		}
+	case device.PlatformIOS:
+		if ifl := params.Get("ifl"); ifl != "" {
+			return ifl
+		}
+		if isi := params.Get("isi"); isi != "" {
+			return appStoreLink(isi)
+		}
+	default:
+		if visitor.FormFactor == "" {
+			return params.Get("ofl")
+		}
+	}
+	return ""
+}
+
+func playStoreLink(apn string) string {
+	return "https://play.google.com/store/apps/details?id=" + url.QueryEscape(apn)
+}
+
+func appStoreLink(isi string) string {
+	return "https://apps.apple.com/app/id" + isi
+}
+
+// appPreview returns what the preview page shows for the link at host and path.
+func (s *linkService) appPreview(params url.Values, host, path string) *models.AppPreview {
+	preview := &models.AppPreview{
+		Host:               host,
+		ShortLink:          fmt.Sprintf("%s://%s/%s", s.cfg.App.URLScheme, host, path),
+		AndroidPackageName: params.Get("apn"),
+	}
+	if apn := params.Get("apn"); apn != "" {
+		preview.PlayStoreLink = playStoreLink(apn)
+	}
+	if isi := params.Get("isi"); isi != "" {
+		preview.AppStoreLink = appStoreLink(isi)
+	}
+	return preview
+}
+
+// deviceLink returns the stored destination for the visitor's device: its platform link if the
+// link has one, then its fallback, then the main link. Huawei devices without Google Play are
+// sent to the AppGallery link instead of a Play Store destination they can't open. Every param
+// this can return was checked against its allow list by validateParamDomains.
+func deviceLink(params url.Values, visitor device.Device) string {
+	link := params.Get(platformParams[visitor.Platform])
+	if link == "" {
+		link = fallbackLink(params, visitor)
+	}
+	if link == "" {
+		link = params.Get("link")
+	}
+	if agl := params.Get("agl"); visitor.HMS && agl != "" && isPlayStoreLink(link) {
+		return agl
+	}
+	return link
+}
+
+func isPlayStoreLink(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "market" || strings.EqualFold(u.Hostname(), "play.google.com")
+}
+
+// desktopApp returns the desktop client to offer a visitor on Windows or macOS, or nil when the
+// link has none.
+func desktopApp(params url.Values, visitor device.Device) *models.DesktopApp {
+	protocolLink := params.Get("dpl")
+	if protocolLink == "" {
+		return nil
+	}
+
+	switch visitor.OS {
+	case "windows":
+		return &models.DesktopApp{ProtocolLink: protocolLink, StoreLink: params.Get("msl"), StoreName: "Microsoft Store"}
+	case "macos":
+		return &models.DesktopApp{ProtocolLink: protocolLink, StoreLink: params.Get("masl"), StoreName: "Mac App Store"}
+	}
+	return nil
+}
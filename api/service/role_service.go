@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/authz"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+const maxRoleNameLength = 255
+
+// RoleService manages the API keys and subject roles the admin API authenticates against, next
+// to ADMIN_API_KEYS and the role claim of OIDC tokens.
+type RoleService interface {
+	CreateAPIKey(ctx context.Context, req models.CreateAPIKeyRequest) (*models.CreatedAPIKey, error)
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, id string) error
+	// KeyRole returns the role of a created API key, failing with ErrAPIKeyNotFound for unknown
+	// keys.
+	KeyRole(ctx context.Context, key string) (string, error)
+
+	SetSubjectRole(ctx context.Context, subject, role string) (*models.SubjectRole, error)
+	ListSubjectRoles(ctx context.Context) ([]models.SubjectRole, error)
+	DeleteSubjectRole(ctx context.Context, subject string) error
+	// SubjectRole returns the role set for subject, failing with ErrSubjectRoleNotFound when
+	// there is none.
+	SubjectRole(ctx context.Context, subject string) (string, error)
+}
+
+type roleService struct {
+	repo repository.RoleRepository
+}
+
+func NewRoleService(repo repository.RoleRepository) *roleService {
+	return &roleService{
+		repo: repo,
+	}
+}
+
+// CreateAPIKey generates a key with the requested role. Only its hash is stored, so the returned
+// key can't be shown again.
+func (s *roleService) CreateAPIKey(ctx context.Context, req models.CreateAPIKeyRequest) (*models.CreatedAPIKey, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxRoleNameLength {
+		return nil, apperrors.ErrInvalidAPIKeyName
+	}
+	if !slices.Contains(models.Roles, req.Role) {
+		return nil, apperrors.ErrInvalidRole
+	}
+
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	key := "dlk_" + hex.EncodeToString(secret)
+	created, err := s.repo.CreateAPIKey(ctx, models.APIKey{ID: authz.Fingerprint(key), Name: name, Role: req.Role}, hashKey(key))
+	if err != nil {
+		return nil, err
+	}
+	log.Ctx(ctx).Info().Str("api_key", created.ID).Str("role", created.Role).Msg("API key created")
+	return &models.CreatedAPIKey{APIKey: *created, Key: key}, nil
+}
+
+func (s *roleService) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return s.repo.ListAPIKeys(ctx)
+}
+
+// DeleteAPIKey revokes the key; requests sending it are rejected right away.
+func (s *roleService) DeleteAPIKey(ctx context.Context, id string) error {
+	if err := s.repo.DeleteAPIKey(ctx, id); err != nil {
+		return err
+	}
+	log.Ctx(ctx).Info().Str("api_key", id).Msg("API key deleted")
+	return nil
+}
+
+func (s *roleService) KeyRole(ctx context.Context, key string) (string, error) {
+	apiKey, err := s.repo.GetAPIKeyByHash(ctx, hashKey(key))
+	if err != nil {
+		return "", err
+	}
+	return apiKey.Role, nil
+}
+
+func (s *roleService) SetSubjectRole(ctx context.Context, subject, role string) (*models.SubjectRole, error) {
+	if subject == "" || utf8.RuneCountInString(subject) > maxRoleNameLength {
+		return nil, apperrors.ErrInvalidSubject
+	}
+	if !slices.Contains(models.Roles, role) {
+		return nil, apperrors.ErrInvalidRole
+	}
+	subjectRole, err := s.repo.SetSubjectRole(ctx, subject, role)
+	if err != nil {
+		return nil, err
+	}
+	log.Ctx(ctx).Info().Str("subject", subject).Str("role", role).Msg("Subject role set")
+	return subjectRole, nil
+}
+
+func (s *roleService) ListSubjectRoles(ctx context.Context) ([]models.SubjectRole, error) {
+	return s.repo.ListSubjectRoles(ctx)
+}
+
+// DeleteSubjectRole deletes the role set for subject, whose tokens get the role of their role
+// claim again.
+func (s *roleService) DeleteSubjectRole(ctx context.Context, subject string) error {
+	if err := s.repo.DeleteSubjectRole(ctx, subject); err != nil {
+		return err
+	}
+	log.Ctx(ctx).Info().Str("subject", subject).Msg("Subject role deleted")
+	return nil
+}
+
+func (s *roleService) SubjectRole(ctx context.Context, subject string) (string, error) {
+	subjectRole, err := s.repo.GetSubjectRole(ctx, subject)
+	if err != nil {
+		return "", err
+	}
+	return subjectRole.Role, nil
+}
+
+// hashKey is how API keys are stored: unlike authz.Fingerprint, the whole SHA-256.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/authz"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRoleRepository struct {
+	repository.RoleRepository
+	keys         map[string]models.APIKey
+	subjectRoles map[string]string
+}
+
+func (f *fakeRoleRepository) CreateAPIKey(_ context.Context, key models.APIKey, keyHash string) (*models.APIKey, error) {
+	f.keys[keyHash] = key
+	return &key, nil
+}
+
+func (f *fakeRoleRepository) GetAPIKeyByHash(_ context.Context, keyHash string) (*models.APIKey, error) {
+	key, ok := f.keys[keyHash]
+	if !ok {
+		return nil, apperrors.ErrAPIKeyNotFound
+	}
+	return &key, nil
+}
+
+func (f *fakeRoleRepository) SetSubjectRole(_ context.Context, subject, role string) (*models.SubjectRole, error) {
+	f.subjectRoles[subject] = role
+	return &models.SubjectRole{Subject: subject, Role: role}, nil
+}
+
+func (f *fakeRoleRepository) GetSubjectRole(_ context.Context, subject string) (*models.SubjectRole, error) {
+	role, ok := f.subjectRoles[subject]
+	if !ok {
+		return nil, apperrors.ErrSubjectRoleNotFound
+	}
+	return &models.SubjectRole{Subject: subject, Role: role}, nil
+}
+
+func TestRoleService(t *testing.T) {
+	repo := &fakeRoleRepository{keys: map[string]models.APIKey{}, subjectRoles: map[string]string{}}
+	s := NewRoleService(repo)
+	ctx := context.Background()
+
+	created, err := s.CreateAPIKey(ctx, models.CreateAPIKeyRequest{Name: " CI ", Role: models.RoleEditor})
+	assert.NoError(t, err)
+	assert.Equal(t, "CI", created.Name)
+	assert.True(t, strings.HasPrefix(created.Key, "dlk_"))
+	assert.Equal(t, authz.Fingerprint(created.Key), created.ID, "the id is the fingerprint OPA and rate limits see")
+	for hash := range repo.keys {
+		assert.NotContains(t, hash, created.Key, "only the key's hash is stored")
+	}
+	role, err := s.KeyRole(ctx, created.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleEditor, role)
+	_, err = s.KeyRole(ctx, "dlk_unknown")
+	assert.ErrorIs(t, err, apperrors.ErrAPIKeyNotFound)
+
+	_, err = s.CreateAPIKey(ctx, models.CreateAPIKeyRequest{Name: " ", Role: models.RoleViewer})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidAPIKeyName)
+	_, err = s.CreateAPIKey(ctx, models.CreateAPIKeyRequest{Name: "CI", Role: "owner"})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidRole)
+
+	_, err = s.SetSubjectRole(ctx, "alice", models.RoleViewer)
+	assert.NoError(t, err)
+	role, err = s.SubjectRole(ctx, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleViewer, role)
+	_, err = s.SetSubjectRole(ctx, "", models.RoleViewer)
+	assert.ErrorIs(t, err, apperrors.ErrInvalidSubject)
+	_, err = s.SetSubjectRole(ctx, "alice", "Admin")
+	assert.ErrorIs(t, err, apperrors.ErrInvalidRole)
+}
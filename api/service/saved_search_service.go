@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+)
+
+const maxSavedSearchName = 100
+
+type SavedSearchService interface {
+	Create(ctx context.Context, owner string, search models.SavedSearch) (*models.SavedSearch, error)
+	List(ctx context.Context, owner string) ([]models.SavedSearch, error)
+	Delete(ctx context.Context, owner, name string) error
+	Run(ctx context.Context, owner, name string, limit int) (*models.SearchResponse, error)
+}
+
+type savedSearchService struct {
+	repo        repository.SavedSearchRepository
+	linkService LinkService
+}
+
+// NewSavedSearchService keeps named searches per owner, the fingerprint of the caller's API key.
+func NewSavedSearchService(repo repository.SavedSearchRepository, linkService LinkService) *savedSearchService {
+	return &savedSearchService{
+		repo:        repo,
+		linkService: linkService,
+	}
+}
+
+func (s *savedSearchService) Create(ctx context.Context, owner string, search models.SavedSearch) (*models.SavedSearch, error) {
+	search.Name = strings.TrimSpace(search.Name)
+	if search.Name == "" || utf8.RuneCountInString(search.Name) > maxSavedSearchName {
+		return nil, apperrors.ErrInvalidSearchName
+	}
+
+	req, err := normalizeSearch(models.SearchRequest{Query: search.Query, Host: search.Host, Sort: search.Sort})
+	if err != nil {
+		return nil, err
+	}
+	search.Query, search.Host, search.Sort = req.Query, req.Host, req.Sort
+
+	if err := s.repo.CreateSavedSearch(ctx, owner, search); err != nil {
+		return nil, err
+	}
+	return s.repo.GetSavedSearch(ctx, owner, search.Name)
+}
+
+func (s *savedSearchService) List(ctx context.Context, owner string) ([]models.SavedSearch, error) {
+	return s.repo.ListSavedSearches(ctx, owner)
+}
+
+func (s *savedSearchService) Delete(ctx context.Context, owner, name string) error {
+	return s.repo.DeleteSavedSearch(ctx, owner, name)
+}
+
+// Run runs the saved search against the current links.
+func (s *savedSearchService) Run(ctx context.Context, owner, name string, limit int) (*models.SearchResponse, error) {
+	search, err := s.repo.GetSavedSearch(ctx, owner, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.linkService.SearchLinks(ctx, models.SearchRequest{
+		Query: search.Query,
+		Host:  search.Host,
+		Sort:  search.Sort,
+		Limit: limit,
+	})
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSavedSearchRepository keeps saved searches in memory, keyed by owner and name.
+type fakeSavedSearchRepository struct {
+	searches map[[2]string]models.SavedSearch
+}
+
+func (f *fakeSavedSearchRepository) CreateSavedSearch(_ context.Context, owner string, search models.SavedSearch) error {
+	key := [2]string{owner, search.Name}
+	if _, ok := f.searches[key]; ok {
+		return apperrors.ErrSavedSearchExists
+	}
+	if f.searches == nil {
+		f.searches = map[[2]string]models.SavedSearch{}
+	}
+	f.searches[key] = search
+	return nil
+}
+
+func (f *fakeSavedSearchRepository) ListSavedSearches(_ context.Context, owner string) ([]models.SavedSearch, error) {
+	searches := []models.SavedSearch{}
+	for key, search := range f.searches {
+		if key[0] == owner {
+			searches = append(searches, search)
+		}
+	}
+	return searches, nil
+}
+
+func (f *fakeSavedSearchRepository) GetSavedSearch(_ context.Context, owner, name string) (*models.SavedSearch, error) {
+	search, ok := f.searches[[2]string{owner, name}]
+	if !ok {
+		return nil, apperrors.ErrSavedSearchNotFound
+	}
+	return &search, nil
+}
+
+func (f *fakeSavedSearchRepository) DeleteSavedSearch(_ context.Context, owner, name string) error {
+	if _, ok := f.searches[[2]string{owner, name}]; !ok {
+		return apperrors.ErrSavedSearchNotFound
+	}
+	delete(f.searches, [2]string{owner, name})
+	return nil
+}
+
+func TestSavedSearches(t *testing.T) {
+	links := &fakeLinkRepository{links: []models.StoredLink{
+		{Host: "a.page.link", Path: "spr", QueryParams: "link=https%3A%2F%2Fexample.com%2Fspring"},
+		{Host: "b.page.link", Path: "spr", QueryParams: "link=https%3A%2F%2Fexample.com%2Fspring"},
+	}}
+	linkService := newTestLinkService(t, links, &config.Config{App: &config.AppConfig{URLScheme: "https"}})
+	s := NewSavedSearchService(&fakeSavedSearchRepository{}, linkService)
+	ctx := context.Background()
+
+	saved, err := s.Create(ctx, "alice", models.SavedSearch{Name: " Spring A ", Query: "spring", Host: "https://a.page.link"})
+	assert.NoError(t, err)
+	if assert.NotNil(t, saved) {
+		assert.Equal(t, "Spring A", saved.Name)
+		assert.Equal(t, "a.page.link", saved.Host)
+		assert.Equal(t, models.SortRelevance, saved.Sort)
+	}
+
+	_, err = s.Create(ctx, "alice", models.SavedSearch{Name: "Spring A", Query: "spring"})
+	assert.ErrorIs(t, err, apperrors.ErrSavedSearchExists)
+	_, err = s.Create(ctx, "alice", models.SavedSearch{Name: "", Query: "spring"})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidSearchName)
+	_, err = s.Create(ctx, "alice", models.SavedSearch{Name: "Popular", Query: "spring", Sort: "clicks"})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidSort)
+
+	resp, err := s.Run(ctx, "alice", "Spring A", 0)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Results, 1)
+
+	_, err = s.Run(ctx, "bob", "Spring A", 0)
+	assert.ErrorIs(t, err, apperrors.ErrSavedSearchNotFound, "searches are private to their owner")
+
+	list, err := s.List(ctx, "bob")
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+
+	assert.NoError(t, s.Delete(ctx, "alice", "Spring A"))
+	assert.ErrorIs(t, s.Delete(ctx, "alice", "Spring A"), apperrors.ErrSavedSearchNotFound)
+}
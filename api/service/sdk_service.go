@@ -0,0 +1,67 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"durable-links-generator/api/models"
+	"durable-links-generator/config"
+
+	"github.com/rs/zerolog/log"
+)
+
+type SDKService interface {
+	WebConfig(apiBase string) (*models.SignedWebSDKConfig, error)
+}
+
+type sdkService struct {
+	cfg        *config.Config
+	signingKey ed25519.PrivateKey
+}
+
+// NewSDKService loads the Ed25519 key used to sign SDK configs from SDK_SIGNING_KEY, a base64
+// encoded 32 byte seed. Without a key configs are served unsigned.
+func NewSDKService(cfg *config.Config) (*sdkService, error) {
+	s := &sdkService{cfg: cfg}
+	if cfg.App.SDKSigningKey == "" {
+		log.Warn().Msg("SDK_SIGNING_KEY not set, web SDK config will be served unsigned")
+		return s, nil
+	}
+
+	seed, err := base64.StdEncoding.DecodeString(cfg.App.SDKSigningKey)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("SDK_SIGNING_KEY must be a base64 encoded %d byte seed", ed25519.SeedSize)
+	}
+	s.signingKey = ed25519.NewKeyFromSeed(seed)
+	return s, nil
+}
+
+func (s *sdkService) WebConfig(apiBase string) (*models.SignedWebSDKConfig, error) {
+	if s.cfg.App.PublicAPIBase != "" {
+		apiBase = s.cfg.App.PublicAPIBase
+	}
+
+	webConfig := models.WebSDKConfig{
+		APIBase: apiBase,
+		Domains: s.cfg.App.Domains,
+	}
+	if webConfig.Domains == nil {
+		webConfig.Domains = []string{}
+	}
+	if s.signingKey == nil {
+		return &models.SignedWebSDKConfig{Config: webConfig}, nil
+	}
+
+	webConfig.PublicKey = base64.StdEncoding.EncodeToString(s.signingKey.Public().(ed25519.PublicKey))
+	payload, err := json.Marshal(webConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SignedWebSDKConfig{
+		Config:    webConfig,
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(s.signingKey, payload)),
+	}, nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebConfig(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+
+	tests := []struct {
+		name          string
+		app           config.AppConfig
+		apiBase       string
+		expectedBase  string
+		expectSigned  bool
+		expectLoadErr bool
+	}{
+		{
+			name:         "Unsigned without key",
+			app:          config.AppConfig{Domains: []string{"example.page.link"}},
+			apiBase:      "http://localhost:8080",
+			expectedBase: "http://localhost:8080",
+		},
+		{
+			name:         "Signed with key and configured base",
+			app:          config.AppConfig{SDKSigningKey: base64.StdEncoding.EncodeToString(seed), PublicAPIBase: "https://api.example.com"},
+			apiBase:      "http://localhost:8080",
+			expectedBase: "https://api.example.com",
+			expectSigned: true,
+		},
+		{
+			name:          "Invalid key",
+			app:           config.AppConfig{SDKSigningKey: "not-a-key"},
+			expectLoadErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := tt.app
+			s, err := NewSDKService(&config.Config{App: &app})
+			if tt.expectLoadErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			signed, err := s.WebConfig(tt.apiBase)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedBase, signed.Config.APIBase)
+			assert.NotNil(t, signed.Config.Domains)
+
+			if !tt.expectSigned {
+				assert.Empty(t, signed.Signature)
+				assert.Empty(t, signed.Config.PublicKey)
+				return
+			}
+
+			publicKey, err := base64.StdEncoding.DecodeString(signed.Config.PublicKey)
+			assert.NoError(t, err)
+			signature, err := base64.StdEncoding.DecodeString(signed.Signature)
+			assert.NoError(t, err)
+			payload, _ := json.Marshal(signed.Config)
+			assert.True(t, ed25519.Verify(publicKey, payload, signature))
+		})
+	}
+}
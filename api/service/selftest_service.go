@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/notify"
+	"durable-links-generator/api/repository"
+	"durable-links-generator/api/webhooks"
+	"durable-links-generator/config"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+const selfTestUserAgent = "durable-links-selftest"
+
+// How long the webhook step waits for the link.created event of the test link to be delivered,
+// and how often it looks at the delivery log meanwhile.
+const (
+	selfTestWebhookTimeout = 15 * time.Second
+	selfTestWebhookPoll    = 250 * time.Millisecond
+)
+
+type SelfTestService interface {
+	Run(ctx context.Context, host string) *models.SelfTestReport
+}
+
+// PreviewRenderer renders the page a scanner would get for a link, so the self-test can catch
+// template errors without going through HTTP.
+type PreviewRenderer func(target *models.RedirectTarget) error
+
+type selfTestService struct {
+	linkService   LinkService
+	repo          repository.LinkRepository
+	cfg           *config.Config
+	renderPreview PreviewRenderer
+	notifier      notify.Notifier
+	webhooks      repository.WebhookRepository
+
+	webhookTimeout time.Duration
+	webhookPoll    time.Duration
+}
+
+func NewSelfTestService(linkService LinkService, repo repository.LinkRepository, cfg *config.Config, renderPreview PreviewRenderer, notifier notify.Notifier) *selfTestService {
+	return &selfTestService{
+		linkService:   linkService,
+		repo:          repo,
+		cfg:           cfg,
+		renderPreview: renderPreview,
+		notifier:      notifier,
+
+		webhookTimeout: selfTestWebhookTimeout,
+		webhookPoll:    selfTestWebhookPoll,
+	}
+}
+
+// WithWebhooks sets where the webhooks of the tested host and their deliveries are read, for the
+// webhook step to check the link.created event of the test link reaches them.
+func (s *selfTestService) WithWebhooks(repo repository.WebhookRepository) *selfTestService {
+	s.webhooks = repo
+	return s
+}
+
+// Run creates a throwaway link on host, resolves it, renders its preview, waits for its
+// link.created event to reach the webhooks of host, if any, and deletes it again. Every step is reported; a failed step skips the ones depending on it but cleanup always runs
+// once the link exists.
+func (s *selfTestService) Run(ctx context.Context, host string) *models.SelfTestReport {
+	report := s.run(ctx, host)
+
+	log.Ctx(ctx).Info().
+		Str("host", report.Host).
+		Bool("passed", report.Passed).
+		Msg("Self-test finished")
+
+	if !report.Passed {
+		s.notifyFailure(ctx, report)
+	}
+	return report
+}
+
+func (s *selfTestService) run(ctx context.Context, host string) *models.SelfTestReport {
+	report := &models.SelfTestReport{Host: host, Passed: true}
+
+	step := func(name string, fn func() (string, error)) bool {
+		start := time.Now()
+		detail, err := fn()
+		result := models.SelfTestStep{
+			Name:       name,
+			Status:     "pass",
+			DurationMs: time.Since(start).Milliseconds(),
+			Detail:     detail,
+		}
+		if err != nil {
+			result.Status = "fail"
+			result.Detail = err.Error()
+			report.Passed = false
+		}
+		report.Steps = append(report.Steps, result)
+		return err == nil
+	}
+	skip := func(detail string, names ...string) {
+		for _, name := range names {
+			report.Steps = append(report.Steps, models.SelfTestStep{Name: name, Status: "skip", Detail: detail})
+		}
+	}
+
+	host, err := utils.CleanHost(host)
+	if err == nil {
+		host = strings.ToLower(host)
+		report.Host = host
+	}
+	destination, derr := s.destination()
+	if err == nil {
+		err = derr
+	}
+	if err != nil {
+		step("create_link", func() (string, error) { return "", err })
+		skip("link was not created", "resolve", "render_preview", "webhook", "cleanup")
+		return report
+	}
+
+	var path string
+	created := step("create_link", func() (string, error) {
+		resp, err := s.linkService.CreateDurableLink(ctx, models.CreateDurableLinkRequest{
+			DurableLinkInfo: models.DurableLinkInfo{Host: host, Link: destination},
+		})
+		if err != nil {
+			return "", err
+		}
+		report.ShortLink = resp.ShortLink
+		u, err := url.Parse(resp.ShortLink)
+		if err != nil {
+			return "", fmt.Errorf("invalid short link %q: %w", resp.ShortLink, err)
+		}
+		path = strings.TrimPrefix(u.Path, "/")
+		return resp.ShortLink, nil
+	})
+	if !created {
+		skip("link was not created", "resolve", "render_preview", "webhook", "cleanup")
+		return report
+	}
+
+	var target *models.RedirectTarget
+	resolved := step("resolve", func() (string, error) {
+		t, err := s.linkService.ResolveRedirect(ctx, host, path, models.ClickContext{UserAgent: selfTestUserAgent, Head: true})
+		if err != nil {
+			return "", err
+		}
+		if t.Destination != destination {
+			return "", fmt.Errorf("resolved to %q, want %q", t.Destination, destination)
+		}
+		target = t
+		return t.Destination, nil
+	})
+
+	if resolved {
+		step("render_preview", func() (string, error) {
+			return "", s.renderPreview(target)
+		})
+	} else {
+		skip("link did not resolve", "render_preview")
+	}
+
+	hooks, err := s.subscribedWebhooks(ctx, host)
+	switch {
+	case err != nil:
+		step("webhook", func() (string, error) { return "", err })
+	case len(hooks) == 0:
+		skip("no webhooks configured", "webhook")
+	default:
+		step("webhook", func() (string, error) {
+			return s.awaitDeliveries(ctx, hooks, host, path)
+		})
+	}
+
+	step("cleanup", func() (string, error) {
+		return "", s.repo.DeleteLink(context.WithoutCancel(ctx), host, path)
+	})
+
+	return report
+}
+
+// subscribedWebhooks returns the webhooks of host the link.created event of the test link is sent
+// to.
+func (s *selfTestService) subscribedWebhooks(ctx context.Context, host string) ([]models.Webhook, error) {
+	if s.webhooks == nil {
+		return nil, nil
+	}
+	hooks, err := s.webhooks.ListWebhooks(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	subscribed := []models.Webhook{}
+	for _, hook := range hooks {
+		if len(hook.Events) == 0 || slices.Contains(hook.Events, webhooks.EventLinkCreated) {
+			subscribed = append(subscribed, hook)
+		}
+	}
+	return subscribed, nil
+}
+
+// awaitDeliveries waits until the link.created event of the link at path was delivered to every
+// one of hooks. It fails with the last delivery error when one of them isn't in time.
+func (s *selfTestService) awaitDeliveries(ctx context.Context, hooks []models.Webhook, host, path string) (string, error) {
+	deadline := time.Now().Add(s.webhookTimeout)
+	pending := hooks
+	lastErr := map[int64]string{}
+	for {
+		remaining := pending[:0:0]
+		for _, hook := range pending {
+			deliveries, err := s.webhooks.ListDeliveries(ctx, hook.ID, 20)
+			if err != nil {
+				return "", err
+			}
+			delivered := false
+			for _, delivery := range deliveries {
+				if !deliveryOf(delivery, host, path) {
+					continue
+				}
+				if delivery.Error == "" {
+					delivered = true
+					break
+				}
+				lastErr[hook.ID] = delivery.Error
+			}
+			if !delivered {
+				remaining = append(remaining, hook)
+			}
+		}
+		pending = remaining
+		if len(pending) == 0 {
+			return fmt.Sprintf("delivered to %d webhooks", len(hooks)), nil
+		}
+
+		if time.Now().After(deadline) {
+			hook := pending[0]
+			if msg, ok := lastErr[hook.ID]; ok {
+				return "", fmt.Errorf("webhook %d: %s", hook.ID, msg)
+			}
+			return "", fmt.Errorf("webhook %d: no delivery within %s", hook.ID, s.webhookTimeout)
+		}
+		select {
+		case <-time.After(s.webhookPoll):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// deliveryOf reports whether delivery carries the link.created event of the link at path on host.
+func deliveryOf(delivery models.WebhookDelivery, host, path string) bool {
+	if delivery.EventType != webhooks.EventLinkCreated {
+		return false
+	}
+	var event webhooks.Event
+	if err := json.Unmarshal([]byte(delivery.Payload), &event); err != nil {
+		return false
+	}
+	return event.Host == host && event.Link.Path == path
+}
+
+func (s *selfTestService) notifyFailure(ctx context.Context, report *models.SelfTestReport) {
+	fields := map[string]string{}
+	for _, step := range report.Steps {
+		if step.Status == "fail" {
+			fields[step.Name] = step.Detail
+		}
+	}
+	err := s.notifier.Notify(ctx, notify.Event{
+		Kind:     notify.KindSelfTestFailed,
+		Project:  report.Host,
+		Severity: notify.SeverityCritical,
+		Title:    "Self-test failed",
+		Fields:   fields,
+	})
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to queue notification")
+	}
+}
+
+// destination returns a unique URL on an allowed domain, so the test link is never deduplicated
+// against an existing one.
+func (s *selfTestService) destination() (string, error) {
+	if len(s.cfg.App.AllowedDomains) == 0 {
+		return "", errors.New("ALLOWED_DOMAINS is empty, no destination can be created")
+	}
+
+	nonce := make([]byte, 8)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	domain := strings.TrimSpace(s.cfg.App.AllowedDomains[0])
+	return fmt.Sprintf("https://%s/durable-links-selftest?run=%s", domain, hex.EncodeToString(nonce)), nil
+}
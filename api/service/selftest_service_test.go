@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/notify"
+	"durable-links-generator/api/webhooks"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeNotifier struct {
+	events []notify.Event
+}
+
+func (f *fakeNotifier) Notify(_ context.Context, event notify.Event) error {
+	f.events = append(f.events, event)
+	return nil
+}
+
+// deliveringPublisher logs a delivery of every event to each webhook of repo, failed with err when
+// it isn't empty, as the dispatcher would.
+type deliveringPublisher struct {
+	repo *fakeWebhookRepository
+	err  string
+}
+
+func (p *deliveringPublisher) Publish(_ context.Context, event webhooks.Event) {
+	payload, _ := json.Marshal(event)
+	for _, hook := range p.repo.hooks {
+		p.repo.deliveries = append(p.repo.deliveries, models.WebhookDelivery{
+			WebhookID: hook.ID,
+			EventType: event.Type,
+			Payload:   string(payload),
+			Error:     p.err,
+		})
+	}
+}
+
+func TestSelfTestRun(t *testing.T) {
+	tests := []struct {
+		name           string
+		host           string
+		allowedDomains []string
+		renderErr      error
+		webhooks       []models.Webhook
+		deliveryErr    string
+		wantPassed     bool
+		wantStatuses   map[string]string
+	}{
+		{
+			name:           "All steps pass",
+			host:           "example.page.link",
+			allowedDomains: []string{"allowed.com"},
+			wantPassed:     true,
+			wantStatuses: map[string]string{
+				"create_link":    "pass",
+				"resolve":        "pass",
+				"render_preview": "pass",
+				"webhook":        "skip",
+				"cleanup":        "pass",
+			},
+		},
+		{
+			name:           "Webhook receives the created link",
+			host:           "Example.Page.Link",
+			allowedDomains: []string{"allowed.com"},
+			webhooks:       []models.Webhook{{ID: 1, Host: "example.page.link", Events: []string{webhooks.EventLinkCreated}}},
+			wantPassed:     true,
+			wantStatuses: map[string]string{
+				"create_link":    "pass",
+				"resolve":        "pass",
+				"render_preview": "pass",
+				"webhook":        "pass",
+				"cleanup":        "pass",
+			},
+		},
+		{
+			name:           "Webhook not subscribed to created links is skipped",
+			host:           "example.page.link",
+			allowedDomains: []string{"allowed.com"},
+			webhooks:       []models.Webhook{{ID: 1, Host: "example.page.link", Events: []string{webhooks.EventLinkDeleted}}},
+			wantPassed:     true,
+			wantStatuses: map[string]string{
+				"create_link":    "pass",
+				"resolve":        "pass",
+				"render_preview": "pass",
+				"webhook":        "skip",
+				"cleanup":        "pass",
+			},
+		},
+		{
+			name:           "Failed webhook delivery fails the step",
+			host:           "example.page.link",
+			allowedDomains: []string{"allowed.com"},
+			webhooks:       []models.Webhook{{ID: 1, Host: "example.page.link"}},
+			deliveryErr:    "endpoint returned status 500",
+			wantPassed:     false,
+			wantStatuses: map[string]string{
+				"create_link":    "pass",
+				"resolve":        "pass",
+				"render_preview": "pass",
+				"webhook":        "fail",
+				"cleanup":        "pass",
+			},
+		},
+		{
+			name:           "Preview failure still cleans up",
+			host:           "example.page.link",
+			allowedDomains: []string{"allowed.com"},
+			renderErr:      errors.New("template broken"),
+			wantPassed:     false,
+			wantStatuses: map[string]string{
+				"create_link":    "pass",
+				"resolve":        "pass",
+				"render_preview": "fail",
+				"webhook":        "skip",
+				"cleanup":        "pass",
+			},
+		},
+		{
+			name:       "Create failure skips the rest",
+			host:       "",
+			wantPassed: false,
+			wantStatuses: map[string]string{
+				"create_link":    "fail",
+				"resolve":        "skip",
+				"render_preview": "skip",
+				"webhook":        "skip",
+				"cleanup":        "skip",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeLinkRepository{}
+			cfg := &config.Config{App: &config.AppConfig{
+				URLScheme:             "https",
+				UnguessablePathLength: 10,
+				AllowedDomains:        tt.allowedDomains,
+			}}
+			webhookRepo := &fakeWebhookRepository{hooks: tt.webhooks}
+			linkService := newTestLinkService(t, repo, cfg).
+				WithWebhooks(&deliveringPublisher{repo: webhookRepo, err: tt.deliveryErr})
+			notifier := &fakeNotifier{}
+			s := NewSelfTestService(linkService, repo, cfg, func(*models.RedirectTarget) error {
+				return tt.renderErr
+			}, notifier).WithWebhooks(webhookRepo)
+			s.webhookTimeout = 50 * time.Millisecond
+			s.webhookPoll = 10 * time.Millisecond
+
+			report := s.Run(context.Background(), tt.host)
+
+			assert.Equal(t, tt.wantPassed, report.Passed)
+			statuses := map[string]string{}
+			for _, step := range report.Steps {
+				statuses[step.Name] = step.Status
+			}
+			assert.Equal(t, tt.wantStatuses, statuses)
+			assert.Empty(t, repo.links, "self-test link should be removed")
+			if tt.wantPassed {
+				assert.Empty(t, notifier.events)
+			} else {
+				assert.Len(t, notifier.events, 1)
+				assert.Equal(t, notify.KindSelfTestFailed, notifier.events[0].Kind)
+			}
+		})
+	}
+}
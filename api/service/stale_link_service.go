@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/notify"
+	"durable-links-generator/api/repository"
+	"durable-links-generator/api/webhooks"
+	"durable-links-generator/config"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultStaleLimit = 100
+	maxStaleLimit     = 1000
+	// maxArchivedPathsListed bounds the paths named in one archive notification.
+	maxArchivedPathsListed = 20
+)
+
+type StaleLinkService interface {
+	StaleLinks(ctx context.Context, host string, days, limit int) (*models.StaleLinksResponse, error)
+	ArchiveStaleLinks(ctx context.Context) (int, error)
+	UnarchiveLink(ctx context.Context, host, path string) error
+}
+
+type staleLinkService struct {
+	repo     repository.LinkRepository
+	cfg      *config.Config
+	notifier notify.Notifier
+	webhooks webhooks.Publisher
+	now      func() time.Time
+}
+
+// NewStaleLinkService finds links nobody has clicked for a while and, with
+// STALE_ARCHIVE_AFTER_DAYS set, archives them.
+func NewStaleLinkService(repo repository.LinkRepository, cfg *config.Config, notifier notify.Notifier) *staleLinkService {
+	return &staleLinkService{
+		repo:     repo,
+		cfg:      cfg,
+		notifier: notifier,
+		webhooks: webhooks.Nop{},
+		now:      time.Now,
+	}
+}
+
+// WithWebhooks sets where a link.expired event is published for each archived link.
+func (s *staleLinkService) WithWebhooks(publisher webhooks.Publisher) *staleLinkService {
+	s.webhooks = publisher
+	return s
+}
+
+// StaleLinks lists the host's links without a click in the last days days, STALE_LINK_DAYS by
+// default. Links never clicked count from their creation.
+func (s *staleLinkService) StaleLinks(ctx context.Context, host string, days, limit int) (*models.StaleLinksResponse, error) {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return nil, apperrors.ErrMissingHost
+	}
+	if days <= 0 {
+		days = s.cfg.App.StaleLinkDays
+	}
+	if limit <= 0 {
+		limit = defaultStaleLimit
+	}
+	limit = min(limit, maxStaleLimit)
+
+	links, err := s.repo.ListStaleLinks(ctx, host, s.now().AddDate(0, 0, -days), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.StaleLinksResponse{Host: host, Days: days, Links: []models.LinkSummary{}}
+	for _, link := range links {
+		summary, err := linkSummary(s.cfg.App.URLScheme, link)
+		if err != nil {
+			return nil, err
+		}
+		resp.Links = append(resp.Links, summary)
+	}
+	return resp, nil
+}
+
+// ArchiveStaleLinks archives the links of every host that have gone STALE_ARCHIVE_AFTER_DAYS
+// without a click and notifies each host's channels. It does nothing when the policy is off.
+func (s *staleLinkService) ArchiveStaleLinks(ctx context.Context) (int, error) {
+	days := s.cfg.App.StaleArchiveAfterDays
+	if days <= 0 {
+		return 0, nil
+	}
+
+	archived, err := s.repo.ArchiveStaleLinks(ctx, s.now().AddDate(0, 0, -days))
+	if err != nil {
+		return 0, err
+	}
+
+	byHost := map[string][]string{}
+	for _, link := range archived {
+		byHost[link.Host] = append(byHost[link.Host], link.Path)
+		s.webhooks.Publish(ctx, linkEvent(s.cfg.App.URLScheme, webhooks.EventLinkExpired, link.Host, link.Path, link.QueryParams))
+	}
+	for host, paths := range byHost {
+		log.Ctx(ctx).Info().Str("host", host).Int("links", len(paths)).Msg("Archived stale links")
+
+		listed := paths[:min(len(paths), maxArchivedPathsListed)]
+		if err := s.notifier.Notify(ctx, notify.Event{
+			Kind:     notify.KindLinksArchived,
+			Project:  host,
+			Severity: notify.SeverityInfo,
+			Title:    fmt.Sprintf("%d stale links archived", len(paths)),
+			Message:  fmt.Sprintf("These links had no clicks in %d days and no longer resolve.", days),
+			Fields:   map[string]string{"paths": strings.Join(listed, ", ")},
+		}); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("host", host).Msg("Failed to queue notification")
+		}
+	}
+	return len(archived), nil
+}
+
+// UnarchiveLink makes an archived link resolve again.
+func (s *staleLinkService) UnarchiveLink(ctx context.Context, host, path string) error {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return apperrors.ErrMissingHost
+	}
+	return s.repo.UnarchiveLink(ctx, host, path)
+}
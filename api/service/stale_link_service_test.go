@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/notify"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaleLinks(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	recent := now.AddDate(0, 0, -5)
+	repo := &fakeLinkRepository{links: []models.StoredLink{
+		{Host: "a.page.link", Path: "old", QueryParams: "link=https%3A%2F%2Fexample.com%2Fold", CreatedAt: now.AddDate(-1, 0, 0)},
+		{Host: "a.page.link", Path: "clicked", QueryParams: "link=https%3A%2F%2Fexample.com", CreatedAt: now.AddDate(-1, 0, 0), LastClickedAt: &recent},
+		{Host: "a.page.link", Path: "new", QueryParams: "link=https%3A%2F%2Fexample.com", CreatedAt: recent},
+		{Host: "b.page.link", Path: "other", QueryParams: "link=https%3A%2F%2Fexample.com", CreatedAt: now.AddDate(-1, 0, 0)},
+	}}
+	cfg := &config.Config{App: &config.AppConfig{URLScheme: "https", StaleLinkDays: 90}}
+	notifier := &fakeNotifier{}
+	s := NewStaleLinkService(repo, cfg, notifier)
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	resp, err := s.StaleLinks(ctx, "a.page.link", 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 90, resp.Days)
+	if assert.Len(t, resp.Links, 1) {
+		assert.Equal(t, "https://a.page.link/old", resp.Links[0].ShortLink)
+		assert.Equal(t, "https://example.com/old", resp.Links[0].Link)
+	}
+
+	resp, err = s.StaleLinks(ctx, "a.page.link", 3, 0)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Links, 3)
+
+	n, err := s.ArchiveStaleLinks(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, n, "the archive policy is off by default")
+
+	cfg.App.StaleArchiveAfterDays = 180
+	n, err = s.ArchiveStaleLinks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	if assert.Len(t, notifier.events, 2) {
+		assert.Equal(t, notify.KindLinksArchived, notifier.events[0].Kind)
+	}
+
+	resp, err = s.StaleLinks(ctx, "a.page.link", 0, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Links, "archived links are no longer reported")
+
+	assert.NoError(t, s.UnarchiveLink(ctx, "a.page.link", "old"))
+	assert.ErrorIs(t, s.UnarchiveLink(ctx, "a.page.link", "old"), apperrors.ErrLinkNotFound)
+
+	_, err = s.StaleLinks(ctx, "", 0, 0)
+	assert.ErrorIs(t, err, apperrors.ErrMissingHost)
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+	"durable-links-generator/config"
+	"durable-links-generator/utils"
+)
+
+const (
+	summaryTopLinks   = 10
+	summaryTopDomains = 10
+	// summaryDomainLinks is how many of the most clicked links top domains are counted over.
+	summaryDomainLinks = 1000
+)
+
+type SummaryService interface {
+	Summary(ctx context.Context, host string) (*models.SummaryResponse, error)
+}
+
+type summaryService struct {
+	repo repository.LinkRepository
+	cfg  *config.Config
+	now  func() time.Time
+}
+
+func NewSummaryService(repo repository.LinkRepository, cfg *config.Config) *summaryService {
+	return &summaryService{
+		repo: repo,
+		cfg:  cfg,
+		now:  time.Now,
+	}
+}
+
+// Summary gathers a project's dashboard overview in one go. Days are UTC days.
+func (s *summaryService) Summary(ctx context.Context, host string) (*models.SummaryResponse, error) {
+	host, err := utils.CleanHost(host)
+	if err != nil {
+		return nil, apperrors.ErrMissingHost
+	}
+	today := s.now().UTC()
+
+	active, err := s.repo.CountActiveLinks(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.repo.ClickTotals(ctx, host, today)
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.repo.TopLinksSince(ctx, host, today.AddDate(0, 0, -29), summaryDomainLinks)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.SummaryResponse{
+		Host:        host,
+		ActiveLinks: active,
+		Clicks:      totals,
+		TopLinks:    []models.TopLink{},
+		TopDomains:  []models.TopDomain{},
+	}
+	domains := map[string]*models.TopDomain{}
+	for i, link := range links {
+		params, err := url.ParseQuery(link.QueryParams)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stored query params: %w", err)
+		}
+		destination := params.Get("link")
+
+		if i < summaryTopLinks {
+			resp.TopLinks = append(resp.TopLinks, models.TopLink{
+				ShortLink: fmt.Sprintf("%s://%s/%s", s.cfg.App.URLScheme, host, link.Path),
+				Path:      link.Path,
+				Link:      destination,
+				Clicks:    link.TotalClicks,
+			})
+		}
+
+		u, err := url.Parse(destination)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		domain := domains[u.Hostname()]
+		if domain == nil {
+			domain = &models.TopDomain{Domain: u.Hostname()}
+			domains[u.Hostname()] = domain
+		}
+		domain.Clicks += link.TotalClicks
+		domain.Links++
+	}
+
+	for _, domain := range domains {
+		resp.TopDomains = append(resp.TopDomains, *domain)
+	}
+	slices.SortFunc(resp.TopDomains, func(a, b models.TopDomain) int {
+		return cmp.Or(cmp.Compare(b.Clicks, a.Clicks), cmp.Compare(a.Domain, b.Domain))
+	})
+	resp.TopDomains = resp.TopDomains[:min(len(resp.TopDomains), summaryTopDomains)]
+	return resp, nil
+}
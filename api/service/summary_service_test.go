@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSummaryRepository struct {
+	repository.LinkRepository
+	totals models.ClickTotals
+	top    []models.StoredLink
+	since  time.Time
+}
+
+func (f *fakeSummaryRepository) CountActiveLinks(context.Context, string) (int64, error) {
+	return 42, nil
+}
+
+func (f *fakeSummaryRepository) ClickTotals(context.Context, string, time.Time) (models.ClickTotals, error) {
+	return f.totals, nil
+}
+
+func (f *fakeSummaryRepository) TopLinksSince(_ context.Context, _ string, since time.Time, limit int) ([]models.StoredLink, error) {
+	f.since = since
+	return f.top[:min(len(f.top), limit)], nil
+}
+
+func TestSummary(t *testing.T) {
+	repo := &fakeSummaryRepository{
+		totals: models.ClickTotals{Today: 3, Last7Days: 20, Last30Days: 90},
+		top: []models.StoredLink{
+			{Path: "a", QueryParams: "link=https%3A%2F%2Fshop.example.com%2Fa", TotalClicks: 50},
+			{Path: "b", QueryParams: "link=https%3A%2F%2Fblog.example.com%2Fb", TotalClicks: 30},
+			{Path: "c", QueryParams: "link=https%3A%2F%2Fshop.example.com%2Fc", TotalClicks: 10},
+		},
+	}
+	s := NewSummaryService(repo, &config.Config{App: &config.AppConfig{URLScheme: "https"}})
+	s.now = func() time.Time { return time.Date(2024, 6, 30, 23, 0, 0, 0, time.UTC) }
+
+	resp, err := s.Summary(context.Background(), "a.page.link")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), resp.ActiveLinks)
+	assert.Equal(t, int64(90), resp.Clicks.Last30Days)
+	assert.Equal(t, time.Date(2024, 6, 1, 23, 0, 0, 0, time.UTC), repo.since, "30 days including today")
+	if assert.Len(t, resp.TopLinks, 3) {
+		assert.Equal(t, models.TopLink{ShortLink: "https://a.page.link/a", Path: "a", Link: "https://shop.example.com/a", Clicks: 50}, resp.TopLinks[0])
+	}
+	assert.Equal(t, []models.TopDomain{
+		{Domain: "shop.example.com", Clicks: 60, Links: 2},
+		{Domain: "blog.example.com", Clicks: 30, Links: 1},
+	}, resp.TopDomains)
+
+	_, err = s.Summary(context.Background(), "")
+	assert.ErrorIs(t, err, apperrors.ErrMissingHost)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/limiter"
+	"durable-links-generator/api/notify"
+	"durable-links-generator/config"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+)
+
+// newTenantLimits caps link creation and bulk changes per short link domain, so one tenant's
+// creation storm can't hog the write pool. Returns nil when no caps are configured.
+func newTenantLimits(cfg *config.Config) *limiter.Tenants {
+	defaults := limiter.TenantLimits{
+		QPS:         float64(cfg.App.TenantCreateQPS),
+		Burst:       cfg.App.TenantCreateBurst,
+		Concurrency: cfg.App.TenantCreateConcurrency,
+	}
+
+	overrides := map[string]limiter.TenantLimits{}
+	for host, value := range cfg.App.TenantCreateQPSOverrides {
+		qps, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || qps < 0 {
+			log.Warn().Str("host", host).Str("value", value).Msg("Ignoring invalid tenant QPS override")
+			continue
+		}
+		limits := defaults
+		limits.QPS = float64(qps)
+		overrides[host] = limits
+	}
+
+	if defaults == (limiter.TenantLimits{}) && len(overrides) == 0 {
+		return nil
+	}
+	return limiter.NewTenants(defaults, overrides)
+}
+
+// acquireTenant admits one write for host, failing with ErrTenantLimited when the host is over
+// its caps. Hosts are validated first, so that malformed ones can't take up limiter entries.
+func (s *linkService) acquireTenant(ctx context.Context, host string) (release func(), err error) {
+	if s.tenants == nil {
+		return func() {}, nil
+	}
+
+	host = strings.ToLower(host)
+	if !utils.IsHostname(host) {
+		return nil, apperrors.ErrHostInvalid
+	}
+	release, ok := s.tenants.Acquire(host)
+	if !ok {
+		log.Ctx(ctx).Warn().Str("host", host).Msg("Tenant limit reached")
+		s.notify(ctx, notify.Event{
+			Kind:     notify.KindQuotaExhausted,
+			Project:  host,
+			Severity: notify.SeverityWarning,
+			Title:    "Link write limit reached",
+			Message:  "Link creation and bulk changes for this domain are being rejected with 429.",
+		})
+		return nil, apperrors.ErrTenantLimited
+	}
+	return release, nil
+}
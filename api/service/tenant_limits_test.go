@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/notify"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantLimits(t *testing.T) {
+	cfg := &config.Config{App: &config.AppConfig{
+		URLScheme:             "https",
+		UnguessablePathLength: 10,
+		AllowedDomains:        []string{"example.com"},
+		TenantCreateQPS:       1,
+		TenantCreateQPSOverrides: map[string]string{
+			"big.page.link": "3",
+			"bad.page.link": "many",
+		},
+	}}
+	notifier := &fakeNotifier{}
+	s := newTestLinkService(t, &fakeLinkRepository{}, cfg).WithNotifier(notifier)
+
+	create := func(host string) error {
+		_, err := s.CreateDurableLink(context.Background(), models.CreateDurableLinkRequest{
+			DurableLinkInfo: models.DurableLinkInfo{Host: host, Link: "https://example.com/" + host},
+		})
+		return err
+	}
+
+	assert.NoError(t, create("a.page.link"))
+	assert.ErrorIs(t, create("a.page.link"), apperrors.ErrTenantLimited)
+	assert.NoError(t, create("b.page.link"), "other tenants keep their own budget")
+	assert.Len(t, notifier.events, 1)
+	assert.Equal(t, notify.KindQuotaExhausted, notifier.events[0].Kind)
+	assert.Equal(t, "a.page.link", notifier.events[0].Project)
+
+	for range 3 {
+		assert.NoError(t, create("big.page.link"))
+	}
+	assert.ErrorIs(t, create("big.page.link"), apperrors.ErrTenantLimited)
+
+	assert.NoError(t, create("bad.page.link"))
+	assert.ErrorIs(t, create("bad.page.link"), apperrors.ErrTenantLimited, "invalid override falls back to the default")
+
+	assert.ErrorIs(t, create("a!b.page.link"), apperrors.ErrHostInvalid)
+}
+
+func TestNewTenantLimitsDisabled(t *testing.T) {
+	assert.Nil(t, newTenantLimits(&config.Config{App: &config.AppConfig{}}))
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+const maxUTMParamLength = 255
+
+var utmPresetNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+type UTMPresetService interface {
+	Create(ctx context.Context, host string, preset models.UTMPreset) (*models.UTMPreset, error)
+	List(ctx context.Context, host string) ([]models.UTMPreset, error)
+	Delete(ctx context.Context, host, name string) error
+}
+
+type utmPresetService struct {
+	repo repository.UTMPresetRepository
+}
+
+func NewUTMPresetService(repo repository.UTMPresetRepository) *utmPresetService {
+	return &utmPresetService{
+		repo: repo,
+	}
+}
+
+func (s *utmPresetService) Create(ctx context.Context, host string, preset models.UTMPreset) (*models.UTMPreset, error) {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return nil, err
+	}
+	if !utmPresetNamePattern.MatchString(preset.Name) {
+		return nil, apperrors.ErrInvalidUTMPresetName
+	}
+	params, err := normalizeMarketingParameters(preset.MarketingParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.CreateUTMPreset(ctx, models.UTMPreset{Host: host, Name: preset.Name, MarketingParameters: params})
+	if err != nil {
+		return nil, err
+	}
+	log.Ctx(ctx).Info().Str("host", host).Str("preset", created.Name).Msg("UTM preset created")
+	return created, nil
+}
+
+func (s *utmPresetService) List(ctx context.Context, host string) ([]models.UTMPreset, error) {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListUTMPresets(ctx, host)
+}
+
+// Delete deletes the preset. Links created with it keep its params.
+func (s *utmPresetService) Delete(ctx context.Context, host, name string) error {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteUTMPreset(ctx, host, name); err != nil {
+		return err
+	}
+	log.Ctx(ctx).Info().Str("host", host).Str("preset", name).Msg("UTM preset deleted")
+	return nil
+}
+
+// normalizeMarketingParameters trims the params of a preset, which must set at least one.
+func normalizeMarketingParameters(params models.MarketingParameters) (models.MarketingParameters, error) {
+	fields := []*string{&params.UtmSource, &params.UtmMedium, &params.UtmCampaign, &params.UtmTerm, &params.UtmContent}
+	set := false
+	for _, field := range fields {
+		*field = strings.TrimSpace(*field)
+		if utf8.RuneCountInString(*field) > maxUTMParamLength {
+			return params, apperrors.ErrInvalidUTMPreset
+		}
+		set = set || *field != ""
+	}
+	if !set {
+		return params, apperrors.ErrInvalidUTMPreset
+	}
+	return params, nil
+}
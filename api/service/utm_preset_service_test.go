@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+	"durable-links-generator/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeUTMPresetRepository struct {
+	repository.UTMPresetRepository
+	presets []models.UTMPreset
+}
+
+func (f *fakeUTMPresetRepository) CreateUTMPreset(_ context.Context, preset models.UTMPreset) (*models.UTMPreset, error) {
+	if _, err := f.GetUTMPreset(context.Background(), preset.Host, preset.Name); err == nil {
+		return nil, apperrors.ErrUTMPresetExists
+	}
+	f.presets = append(f.presets, preset)
+	return &preset, nil
+}
+
+func (f *fakeUTMPresetRepository) GetUTMPreset(_ context.Context, host, name string) (*models.UTMPreset, error) {
+	for _, preset := range f.presets {
+		if preset.Host == host && preset.Name == name {
+			return &preset, nil
+		}
+	}
+	return nil, apperrors.ErrUTMPresetNotFound
+}
+
+func TestUTMPresetService(t *testing.T) {
+	s := NewUTMPresetService(&fakeUTMPresetRepository{})
+	ctx := context.Background()
+
+	created, err := s.Create(ctx, "Acme.link", models.UTMPreset{
+		Name:                "newsletter",
+		MarketingParameters: models.MarketingParameters{UtmSource: " newsletter ", UtmMedium: "email"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "acme.link", created.Host)
+	assert.Equal(t, models.MarketingParameters{UtmSource: "newsletter", UtmMedium: "email"}, created.MarketingParameters)
+
+	_, err = s.Create(ctx, "acme.link", models.UTMPreset{Name: "newsletter", MarketingParameters: models.MarketingParameters{UtmSource: "x"}})
+	assert.ErrorIs(t, err, apperrors.ErrUTMPresetExists)
+	for _, name := range []string{"", ".hidden", "news letter", strings.Repeat("a", 65)} {
+		_, err = s.Create(ctx, "acme.link", models.UTMPreset{Name: name, MarketingParameters: models.MarketingParameters{UtmSource: "x"}})
+		assert.ErrorIs(t, err, apperrors.ErrInvalidUTMPresetName, name)
+	}
+	_, err = s.Create(ctx, "acme.link", models.UTMPreset{Name: "empty", MarketingParameters: models.MarketingParameters{UtmSource: " "}})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidUTMPreset)
+	_, err = s.Create(ctx, "acme.link", models.UTMPreset{Name: "long", MarketingParameters: models.MarketingParameters{UtmTerm: strings.Repeat("a", maxUTMParamLength+1)}})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidUTMPreset)
+}
+
+func TestCreateDurableLink_UTMPreset(t *testing.T) {
+	repo := &fakeLinkRepository{}
+	presets := &fakeUTMPresetRepository{presets: []models.UTMPreset{{
+		Host: "acme.link",
+		Name: "newsletter",
+		MarketingParameters: models.MarketingParameters{
+			UtmSource:   "newsletter",
+			UtmMedium:   "email",
+			UtmCampaign: "weekly",
+		},
+	}}}
+	s := newTestLinkService(t, repo, &config.Config{App: &config.AppConfig{
+		URLScheme:      "https",
+		AllowedDomains: []string{"example.com"},
+	}}).WithUTMPresets(presets)
+	ctx := context.Background()
+	req := func(analytics models.AnalyticsInfo) models.CreateDurableLinkRequest {
+		return models.CreateDurableLinkRequest{
+			DurableLinkInfo: models.DurableLinkInfo{Host: "acme.link", Link: "https://example.com", AnalyticsInfo: analytics},
+		}
+	}
+
+	_, err := s.CreateDurableLink(ctx, req(models.AnalyticsInfo{
+		PresetName:          "newsletter",
+		MarketingParameters: models.MarketingParameters{UtmCampaign: "spring", UtmContent: "header"},
+	}))
+	assert.NoError(t, err)
+	params, err := url.ParseQuery(repo.links[len(repo.links)-1].QueryParams)
+	assert.NoError(t, err)
+	assert.Equal(t, "newsletter", params.Get("utm_source"))
+	assert.Equal(t, "email", params.Get("utm_medium"))
+	assert.Equal(t, "spring", params.Get("utm_campaign"), "the link's own params win")
+	assert.Equal(t, "header", params.Get("utm_content"))
+	assert.False(t, params.Has("utm_term"))
+
+	_, err = s.CreateDurableLink(ctx, req(models.AnalyticsInfo{PresetName: "missing"}))
+	assert.ErrorIs(t, err, apperrors.ErrUTMPresetNotFound)
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"slices"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+	"durable-links-generator/api/webhooks"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Deliveries listed by default, and at most.
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 500
+)
+
+type WebhookService interface {
+	Create(ctx context.Context, host string, hook models.Webhook) (*models.Webhook, error)
+	List(ctx context.Context, host string) ([]models.Webhook, error)
+	Delete(ctx context.Context, host string, id int64) error
+	ListDeliveries(ctx context.Context, host string, id int64, limit int) ([]models.WebhookDelivery, error)
+}
+
+type webhookService struct {
+	repo repository.WebhookRepository
+}
+
+func NewWebhookService(repo repository.WebhookRepository) *webhookService {
+	return &webhookService{
+		repo: repo,
+	}
+}
+
+// Create registers a webhook on host. A secret is generated when hook has none; it is only ever
+// returned here.
+func (s *webhookService) Create(ctx context.Context, host string, hook models.Webhook) (*models.Webhook, error) {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return nil, err
+	}
+	hook, err = normalizeWebhook(hook)
+	if err != nil {
+		return nil, err
+	}
+	hook.Host = host
+	if hook.Secret == "" {
+		secret := make([]byte, 32)
+		rand.Read(secret)
+		hook.Secret = "whsec_" + hex.EncodeToString(secret)
+	}
+
+	created, err := s.repo.CreateWebhook(ctx, hook)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Info().
+		Str("host", created.Host).
+		Int64("webhook_id", created.ID).
+		Strs("events", created.Events).
+		Msg("Webhook registered")
+	return created, nil
+}
+
+// List returns the webhooks of host, without their secrets.
+func (s *webhookService) List(ctx context.Context, host string) ([]models.Webhook, error) {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return nil, err
+	}
+	hooks, err := s.repo.ListWebhooks(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+	return hooks, nil
+}
+
+func (s *webhookService) Delete(ctx context.Context, host string, id int64) error {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteWebhook(ctx, host, id); err != nil {
+		return err
+	}
+	log.Ctx(ctx).Info().Str("host", host).Int64("webhook_id", id).Msg("Webhook deleted")
+	return nil
+}
+
+// ListDeliveries returns the latest delivery attempts of a webhook of host, newest first.
+func (s *webhookService) ListDeliveries(ctx context.Context, host string, id int64, limit int) ([]models.WebhookDelivery, error) {
+	host, err := normalizeDomain(host)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetWebhook(ctx, host, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultDeliveryLimit
+	}
+	return s.repo.ListDeliveries(ctx, id, min(limit, maxDeliveryLimit))
+}
+
+// normalizeWebhook validates the settings of hook, dropping repeated events and thresholds and
+// sorting the thresholds.
+func normalizeWebhook(hook models.Webhook) (models.Webhook, error) {
+	u, err := url.Parse(hook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return models.Webhook{}, apperrors.ErrInvalidWebhookURL
+	}
+
+	events := []string{}
+	for _, event := range hook.Events {
+		if !slices.Contains(webhooks.LinkEvents, event) {
+			return models.Webhook{}, apperrors.ErrInvalidWebhookEvent
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+
+	thresholds := []int64{}
+	for _, threshold := range hook.ClickThresholds {
+		if threshold <= 0 {
+			return models.Webhook{}, apperrors.ErrInvalidClickThresholds
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	slices.Sort(thresholds)
+
+	return models.Webhook{
+		URL:             hook.URL,
+		Secret:          hook.Secret,
+		Events:          events,
+		ClickThresholds: slices.Compact(thresholds),
+	}, nil
+}
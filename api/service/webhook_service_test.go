@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeWebhookRepository struct {
+	repository.WebhookRepository
+	hooks      []models.Webhook
+	deliveries []models.WebhookDelivery
+}
+
+func (f *fakeWebhookRepository) CreateWebhook(_ context.Context, hook models.Webhook) (*models.Webhook, error) {
+	hook.ID = int64(len(f.hooks) + 1)
+	f.hooks = append(f.hooks, hook)
+	return &hook, nil
+}
+
+func (f *fakeWebhookRepository) ListWebhooks(_ context.Context, host string) ([]models.Webhook, error) {
+	hooks := []models.Webhook{}
+	for _, hook := range f.hooks {
+		if hook.Host == host {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks, nil
+}
+
+func (f *fakeWebhookRepository) GetWebhook(_ context.Context, host string, id int64) (*models.Webhook, error) {
+	for _, hook := range f.hooks {
+		if hook.Host == host && hook.ID == id {
+			return &hook, nil
+		}
+	}
+	return nil, apperrors.ErrWebhookNotFound
+}
+
+func (f *fakeWebhookRepository) ListDeliveries(_ context.Context, id int64, _ int) ([]models.WebhookDelivery, error) {
+	deliveries := []models.WebhookDelivery{}
+	for _, delivery := range f.deliveries {
+		if delivery.WebhookID == id {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func TestWebhookService(t *testing.T) {
+	repo := &fakeWebhookRepository{}
+	s := NewWebhookService(repo)
+	ctx := context.Background()
+
+	created, err := s.Create(ctx, "L.Example.com", models.Webhook{
+		URL:             "https://hooks.example.com/links",
+		Events:          []string{"link.created", "link.deleted", "link.created"},
+		ClickThresholds: []int64{1000, 100, 1000},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "l.example.com", created.Host)
+	assert.True(t, strings.HasPrefix(created.Secret, "whsec_"))
+	assert.Equal(t, []string{"link.created", "link.deleted"}, created.Events)
+	assert.Equal(t, []int64{100, 1000}, created.ClickThresholds)
+
+	hooks, err := s.List(ctx, "l.example.com")
+	assert.NoError(t, err)
+	if assert.Len(t, hooks, 1) {
+		assert.Empty(t, hooks[0].Secret, "secrets are only returned on creation")
+	}
+	assert.NotEmpty(t, repo.hooks[0].Secret)
+
+	_, err = s.ListDeliveries(ctx, "l.example.com", created.ID, 0)
+	assert.NoError(t, err)
+	_, err = s.ListDeliveries(ctx, "other.example.com", created.ID, 0)
+	assert.ErrorIs(t, err, apperrors.ErrWebhookNotFound)
+
+	for _, hook := range []struct {
+		hook models.Webhook
+		err  error
+	}{
+		{models.Webhook{URL: "ftp://hooks.example.com"}, apperrors.ErrInvalidWebhookURL},
+		{models.Webhook{URL: "/links"}, apperrors.ErrInvalidWebhookURL},
+		{models.Webhook{URL: "https://hooks.example.com", Events: []string{"link.click_threshold"}}, apperrors.ErrInvalidWebhookEvent},
+		{models.Webhook{URL: "https://hooks.example.com", ClickThresholds: []int64{0}}, apperrors.ErrInvalidClickThresholds},
+	} {
+		_, err := s.Create(ctx, "l.example.com", hook.hook)
+		assert.ErrorIs(t, err, hook.err, hook.hook)
+	}
+	_, err = s.Create(ctx, "", models.Webhook{URL: "https://hooks.example.com"})
+	assert.ErrorIs(t, err, apperrors.ErrMissingHost)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"fmt"
+
+	"durable-links-generator/api/attribution"
+	"durable-links-generator/api/clicks"
+	"durable-links-generator/api/geoip"
+	"durable-links-generator/api/notify"
+	"durable-links-generator/api/repository"
+	"durable-links-generator/api/reputation"
+	"durable-links-generator/api/service"
+	"durable-links-generator/api/signedlinks"
+	"durable-links-generator/api/webhooks"
+	"durable-links-generator/config"
+	"durable-links-generator/db"
+)
+
+// Services is the link service and the stores behind it, built once per process and shared by
+// the HTTP and gRPC servers, so both transports see the same caches, limits and install clicks.
+type Services struct {
+	linkCache        repository.LinkCache
+	linkRepository   repository.LinkRepository
+	domainRepository repository.DomainRepository
+	utmPresets       repository.UTMPresetRepository
+	domainConfigs    *service.DomainConfigs
+	linkService      service.LinkService
+	// installClicks is nil unless ATTRIBUTION_TTL is set.
+	installClicks *attribution.MemoryStore
+	roleService   service.RoleService
+	// auditRepository is appended to by linkRepository and domainRepository.
+	auditRepository repository.AuditRepository
+	authenticator   *authenticator
+}
+
+func NewServices(database *db.DB, cfg *config.Config, notifier notify.Notifier, clickRecorder clicks.Recorder, clickEvents clicks.EventRecorder, linkCache repository.LinkCache, publisher webhooks.Publisher, locator geoip.Locator) (*Services, error) {
+	auditRepository := repository.NewAuditRepository(database.Write)
+	domainRepository := repository.NewAuditedDomainRepository(repository.NewDomainRepositoryForDB(database), auditRepository)
+	domainConfigs := service.NewDomainConfigs(domainRepository, cfg.App.DomainConfigTTL)
+
+	utmPresets := repository.NewUTMPresetRepository(database.Write)
+
+	linkRepository := repository.NewCachedLinkRepository(
+		repository.NewAuditedLinkRepository(repository.NewLinkRepositoryForDB(database), auditRepository), linkCache)
+	linkService, err := service.NewLinkService(linkRepository, cfg)
+	if err != nil {
+		return nil, err
+	}
+	linkService.
+		WithNotifier(notifier).
+		WithClickRecorder(clickRecorder).
+		WithClickEvents(clickEvents).
+		WithDomainConfigs(domainConfigs).
+		WithWebhooks(publisher).
+		WithUTMPresets(utmPresets).
+		WithGeoIP(locator)
+
+	checker, err := reputation.New(reputation.Options{
+		Provider: cfg.App.URLReputationProvider,
+		APIKey:   cfg.App.URLReputationAPIKey,
+		URL:      cfg.App.URLReputationURL,
+		Timeout:  cfg.App.URLReputationTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL reputation config: %w", err)
+	}
+	linkService.WithReputation(checker)
+
+	if len(cfg.App.SignedLinkKeys) > 0 {
+		signer, err := signedlinks.New(cfg.App.SignedLinkKeys, cfg.App.SignedLinkKeyID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SIGNED_LINK_KEYS: %w", err)
+		}
+		linkService.WithSignedLinks(signer)
+	}
+
+	roleService := service.NewRoleService(repository.NewRoleRepository(database.Write))
+	authenticator, err := newAuthenticator(cfg, roleService)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OIDC config: %w", err)
+	}
+
+	var installClicks *attribution.MemoryStore
+	if cfg.App.AttributionTTL > 0 {
+		installClicks = attribution.NewMemoryStore(cfg.App.AttributionTTL, cfg.App.AttributionMaxIPs)
+		linkService.WithInstallClicks(installClicks)
+	}
+
+	return &Services{
+		linkCache:        linkCache,
+		linkRepository:   linkRepository,
+		domainRepository: domainRepository,
+		utmPresets:       utmPresets,
+		domainConfigs:    domainConfigs,
+		linkService:      linkService,
+		installClicks:    installClicks,
+		roleService:      roleService,
+		auditRepository:  auditRepository,
+		authenticator:    authenticator,
+	}, nil
+}
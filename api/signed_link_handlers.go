@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// CreateSignedLink serves "POST /v1/signedLinks", making a link that resolves from its path alone.
+func (h *handler) CreateSignedLink(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateSignedLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+
+	resp, err := h.linkService.CreateSignedLink(r.Context(), req)
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost),
+		errors.Is(err, apperrors.ErrMissingLink),
+		errors.Is(err, apperrors.ErrInvalidURLFormat),
+		errors.Is(err, apperrors.ErrInvalidTTL):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrDomainLinkNotAllowed):
+		WriteError(w, err, http.StatusBadRequest, "Link domain is not in the allow list", models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrPolicyDenied):
+		WriteError(w, err, http.StatusForbidden, err.Error(), models.StatusPermissionDenied)
+	case errors.Is(err, apperrors.ErrUnsafeURL),
+		errors.Is(err, apperrors.ErrReputationUnavailable):
+		details := createErrorDetails(r.Context(), err)
+		WriteError(w, err, details.Code, details.Message, details.Status)
+	case errors.Is(err, apperrors.ErrSignedLinksDisabled):
+		WriteError(w, err, http.StatusNotImplemented, "Signed links are not configured", models.StatusFailedPrecondition)
+	case err != nil:
+		requestLog(w).Error().Err(err).Msg("Failed to create signed link")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to create signed link", models.StatusInternal)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// RedirectSigned serves "/s/{token}", redirecting to the destination a signed link carries once
+// the visitor passed the host's interstitial gate, if any.
+func (h *handler) RedirectSigned(w http.ResponseWriter, r *http.Request) {
+	target, err := h.linkService.ResolveSignedLink(r.Context(), r.Host, chi.URLParam(r, "token"), h.clickContext(r))
+	switch {
+	case errors.Is(err, apperrors.ErrLinkNotFound):
+		http.NotFound(w, r)
+	case errors.Is(err, apperrors.ErrLinkExpired):
+		serveLinkBehavior(w, r, h.cfg.App.ExpiredLinkBehavior, "expired.html", http.StatusGone)
+	case errors.Is(err, apperrors.ErrPolicyDenied):
+		http.Error(w, "This link is not available", http.StatusForbidden)
+	case err != nil:
+		requestLog(w).Error().Err(err).Msg("Failed to resolve signed link")
+		http.Error(w, "Failed to resolve link", http.StatusInternalServerError)
+	case target.Gate != "" && !h.acknowledged(w, r, target.Gate):
+		h.renderInterstitial(w, r, target)
+	default:
+		http.Redirect(w, r, target.Destination, http.StatusFound)
+	}
+}
@@ -0,0 +1,107 @@
+// Package signedlinks makes short links that carry their destination and an HMAC over it, so they
+// resolve without a database lookup. Keys are named so they can be rotated: links are signed with
+// the current key and verified with whichever configured key signed them, so links signed with a
+// retired key keep working until the key is removed from the config.
+package signedlinks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalid = errors.New("signed link is invalid")
+	ErrExpired = errors.New("signed link has expired")
+)
+
+// Shortest secret accepted, in bytes.
+const minSecretLength = 32
+
+// Bytes of the HMAC kept in the token; 128 bits are plenty against forgery and keep paths short.
+const macLength = 16
+
+var keyIDPattern = regexp.MustCompile(`^[a-z0-9]{1,16}$`)
+
+// Signer signs and verifies the tokens of signed links.
+type Signer struct {
+	keys    map[string][]byte
+	current string
+	now     func() time.Time
+}
+
+// New returns a signer signing with the key named current among keys, which maps key IDs to
+// secrets.
+func New(keys map[string]string, current string) (*Signer, error) {
+	s := &Signer{keys: map[string][]byte{}, current: current, now: time.Now}
+	for id, secret := range keys {
+		if !keyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("key ID %q may only hold up to 16 lower case letters and digits", id)
+		}
+		if len(secret) < minSecretLength {
+			return nil, fmt.Errorf("secret of key %q must be at least %d bytes", id, minSecretLength)
+		}
+		s.keys[id] = []byte(secret)
+	}
+	if _, ok := s.keys[current]; !ok {
+		return nil, fmt.Errorf("current key %q is not among the keys", current)
+	}
+	return s, nil
+}
+
+// Sign returns the token of a link on host leading to link, expiring at expires unless it is the
+// zero time. The token is "<key ID>.<expiry>.<link>.<signature>", the expiry in base 36 Unix
+// seconds or 0, the link and signature in unpadded base64url, so it fits in a path segment.
+func (s *Signer) Sign(host, link string, expires time.Time) string {
+	expiry := "0"
+	if !expires.IsZero() {
+		expiry = strconv.FormatInt(expires.Unix(), 36)
+	}
+	payload := base64.RawURLEncoding.EncodeToString([]byte(link))
+	return s.current + "." + expiry + "." + payload + "." + s.sign(s.keys[s.current], host, s.current, expiry, link)
+}
+
+// Verify returns the link token leads to on host. It fails with ErrInvalid for tokens not signed
+// for host with a configured key, and with ErrExpired once a valid token expired.
+func (s *Signer) Verify(host, token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return "", ErrInvalid
+	}
+	id, expiry, payload, signature := parts[0], parts[1], parts[2], parts[3]
+	key, ok := s.keys[id]
+	if !ok {
+		return "", ErrInvalid
+	}
+	link, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", ErrInvalid
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, host, id, expiry, string(link)))) {
+		return "", ErrInvalid
+	}
+	if expiry != "0" {
+		expires, err := strconv.ParseInt(expiry, 36, 64)
+		if err != nil {
+			return "", ErrInvalid
+		}
+		if !s.now().Before(time.Unix(expires, 0)) {
+			return "", ErrExpired
+		}
+	}
+	return string(link), nil
+}
+
+// sign covers the host, so a token can't be replayed on another domain, and the key ID and
+// expiry, so neither can be swapped.
+func (s *Signer) sign(key []byte, host, id, expiry, link string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.ToLower(host) + "\x00" + id + "\x00" + expiry + "\x00" + link))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:macLength])
+}
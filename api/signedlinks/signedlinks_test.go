@@ -0,0 +1,74 @@
+package signedlinks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	oldSecret = "0123456789abcdef0123456789abcdef"
+	newSecret = "fedcba9876543210fedcba9876543210"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	signer, err := New(map[string]string{"k1": oldSecret}, "k1")
+	assert.NoError(t, err)
+	signer.now = func() time.Time { return now }
+
+	token := signer.Sign("acme.link", "https://example.com/a?b=c", time.Time{})
+	assert.True(t, strings.HasPrefix(token, "k1.0."))
+	link, err := signer.Verify("ACME.link", token)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/a?b=c", link)
+
+	_, err = signer.Verify("other.link", token)
+	assert.ErrorIs(t, err, ErrInvalid, "tokens are bound to their host")
+	parts := strings.Split(token, ".")
+	parts[2] = "aHR0cHM6Ly9ldmlsLnRlc3Q"
+	_, err = signer.Verify("acme.link", strings.Join(parts, "."))
+	assert.ErrorIs(t, err, ErrInvalid, "the link can't be swapped")
+	_, err = signer.Verify("acme.link", "k1.0.abc")
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	expiring := signer.Sign("acme.link", "https://example.com", now.Add(time.Minute))
+	_, err = signer.Verify("acme.link", expiring)
+	assert.NoError(t, err)
+	signer.now = func() time.Time { return now.Add(time.Minute) }
+	_, err = signer.Verify("acme.link", expiring)
+	assert.ErrorIs(t, err, ErrExpired)
+}
+
+func TestKeyRotation(t *testing.T) {
+	old, err := New(map[string]string{"k1": oldSecret}, "k1")
+	assert.NoError(t, err)
+	token := old.Sign("acme.link", "https://example.com", time.Time{})
+
+	rotated, err := New(map[string]string{"k1": oldSecret, "k2": newSecret}, "k2")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(rotated.Sign("acme.link", "https://example.com", time.Time{}), "k2."))
+	_, err = rotated.Verify("acme.link", token)
+	assert.NoError(t, err, "links signed with the previous key still resolve")
+
+	retired, err := New(map[string]string{"k2": newSecret}, "k2")
+	assert.NoError(t, err)
+	_, err = retired.Verify("acme.link", token)
+	assert.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestNewValidatesKeys(t *testing.T) {
+	for _, tc := range []struct {
+		keys    map[string]string
+		current string
+	}{
+		{map[string]string{"k1": "short"}, "k1"},
+		{map[string]string{"k.1": oldSecret}, "k.1"},
+		{map[string]string{"k1": oldSecret}, "k2"},
+	} {
+		_, err := New(tc.keys, tc.current)
+		assert.Error(t, err, "%v", tc.keys)
+	}
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+type StaleLinkHandler interface {
+	StaleLinks(w http.ResponseWriter, r *http.Request)
+	UnarchiveLink(w http.ResponseWriter, r *http.Request)
+}
+
+type staleLinkHandler struct {
+	staleLinkService service.StaleLinkService
+}
+
+func NewStaleLinkHandler(staleLinkService service.StaleLinkService) StaleLinkHandler {
+	return &staleLinkHandler{
+		staleLinkService: staleLinkService,
+	}
+}
+
+func (h *staleLinkHandler) StaleLinks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	days, ok := positiveIntParam(w, query.Get("days"), "days")
+	if !ok {
+		return
+	}
+	limit, ok := positiveIntParam(w, query.Get("limit"), "limit")
+	if !ok {
+		return
+	}
+
+	resp, err := h.staleLinkService.StaleLinks(r.Context(), query.Get("host"), days, limit)
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to list stale links")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to list stale links", models.StatusInternal)
+	default:
+		writeProjectedJSON(w, r, resp, "links")
+	}
+}
+
+func (h *staleLinkHandler) UnarchiveLink(w http.ResponseWriter, r *http.Request) {
+	err := h.staleLinkService.UnarchiveLink(r.Context(), r.URL.Query().Get("host"), chi.URLParam(r, "path"))
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrLinkNotFound):
+		WriteError(w, err, http.StatusNotFound, "Archived link not found", models.StatusNotFound)
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to unarchive link")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to unarchive link", models.StatusInternal)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// positiveIntParam parses an optional positive integer query param, answering 400 when it is
+// malformed. A missing param is 0.
+func positiveIntParam(w http.ResponseWriter, raw, name string) (int, bool) {
+	if raw == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		WriteErrorResponse(w, http.StatusBadRequest, name+" must be a positive integer", models.StatusInvalidArgument)
+		return 0, false
+	}
+	return n, true
+}
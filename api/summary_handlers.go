@@ -0,0 +1,39 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+type SummaryHandler interface {
+	Summary(w http.ResponseWriter, r *http.Request)
+}
+
+type summaryHandler struct {
+	summaryService service.SummaryService
+}
+
+func NewSummaryHandler(summaryService service.SummaryService) SummaryHandler {
+	return &summaryHandler{
+		summaryService: summaryService,
+	}
+}
+
+func (h *summaryHandler) Summary(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.summaryService.Summary(r.Context(), r.URL.Query().Get("host"))
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case err != nil:
+		log.Ctx(r.Context()).Error().Err(err).Msg("Failed to build summary")
+		WriteError(w, err, http.StatusInternalServerError, "Failed to build summary", models.StatusInternal)
+	default:
+		writeProjectedJSON(w, r, resp, "")
+	}
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type UTMPresetHandler interface {
+	Create(w http.ResponseWriter, r *http.Request)
+	List(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+}
+
+type utmPresetHandler struct {
+	utmPresetService service.UTMPresetService
+}
+
+func NewUTMPresetHandler(utmPresetService service.UTMPresetService) UTMPresetHandler {
+	return &utmPresetHandler{
+		utmPresetService: utmPresetService,
+	}
+}
+
+func (h *utmPresetHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var preset models.UTMPreset
+	if err := json.NewDecoder(r.Body).Decode(&preset); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+
+	created, err := h.utmPresetService.Create(r.Context(), chi.URLParam(r, "domain"), preset)
+	if err != nil {
+		writeUTMPresetError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (h *utmPresetHandler) List(w http.ResponseWriter, r *http.Request) {
+	presets, err := h.utmPresetService.List(r.Context(), chi.URLParam(r, "domain"))
+	if err != nil {
+		writeUTMPresetError(w, err)
+		return
+	}
+
+	writeProjectedJSON(w, r, models.UTMPresetListResponse{Presets: presets}, "presets")
+}
+
+func (h *utmPresetHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.utmPresetService.Delete(r.Context(), chi.URLParam(r, "domain"), chi.URLParam(r, "name")); err != nil {
+		writeUTMPresetError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeUTMPresetError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost),
+		errors.Is(err, apperrors.ErrInvalidUTMPresetName),
+		errors.Is(err, apperrors.ErrInvalidUTMPreset):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrUTMPresetNotFound):
+		WriteError(w, err, http.StatusNotFound, err.Error(), models.StatusNotFound)
+	case errors.Is(err, apperrors.ErrUTMPresetExists):
+		WriteError(w, err, http.StatusConflict, err.Error(), models.StatusAlreadyExists)
+	default:
+		requestLog(w).Error().Err(err).Msg("UTM preset request failed")
+		WriteError(w, err, http.StatusInternalServerError, "UTM preset request failed", models.StatusInternal)
+	}
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+
+	"durable-links-generator/api/models"
+	"durable-links-generator/buildinfo"
+	"durable-links-generator/config"
+	"durable-links-generator/db"
+)
+
+type VersionHandler interface {
+	Version(w http.ResponseWriter, r *http.Request)
+}
+
+type versionHandler struct {
+	cfg      *config.Config
+	database *db.DB
+}
+
+func NewVersionHandler(cfg *config.Config, database *db.DB) VersionHandler {
+	return &versionHandler{
+		cfg:      cfg,
+		database: database,
+	}
+}
+
+// Version reports the build, the newest migration applied to the database and the enabled
+// features.
+func (h *versionHandler) Version(w http.ResponseWriter, r *http.Request) {
+	version, commit, buildDate := buildinfo.Info()
+	migration, err := db.MigrationVersion(r.Context(), h.database)
+	if err != nil {
+		WriteError(w, err, http.StatusInternalServerError, "Failed to read the migration version", models.StatusInternal)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(models.VersionResponse{
+		Version:          version,
+		Commit:           commit,
+		BuildDate:        buildDate,
+		GoVersion:        runtime.Version(),
+		MigrationVersion: migration,
+		Features:         enabledFeatures(h.cfg),
+	})
+}
+
+// enabledFeatures lists the optional behaviours switched on in this instance's config.
+func enabledFeatures(cfg *config.Config) []string {
+	flags := []struct {
+		name    string
+		enabled bool
+	}{
+		{"autocert", cfg.Server.AutocertEnabled},
+		{"admin_api", len(cfg.Server.AdminAPIKeys) > 0 || cfg.Server.OIDCIssuer != ""},
+		{"oidc", cfg.Server.OIDCIssuer != ""},
+		{"concurrency_limit", cfg.Server.ConcurrencyLimitEnabled},
+		{"opa_authz", cfg.Server.OPAURL != ""},
+		{"captcha", cfg.Server.CaptchaProvider != "" && cfg.Server.CaptchaProvider != "none"},
+		{"pii_scan", cfg.App.PIIScanPolicy != "" && cfg.App.PIIScanPolicy != "off"},
+		{"url_reputation", cfg.App.URLReputationProvider != "" && cfg.App.URLReputationProvider != "none"},
+		{"consent_required", cfg.App.ConsentRequired},
+		{"interstitials", len(cfg.App.InterstitialGates) > 0},
+		{"link_info", len(cfg.App.LinkInfoDomains) > 0},
+		{"scanner_friendly", len(cfg.App.ScannerFriendlyDomains) > 0},
+		{"head_counts_as_click", cfg.App.HeadCountsAsClick},
+		{"domain_verification", cfg.App.DomainVerificationSecret != ""},
+		{"signed_sdk_config", cfg.App.SDKSigningKey != ""},
+		{"signed_links", len(cfg.App.SignedLinkKeys) > 0},
+		{"fixtures", cfg.App.FixtureSeed != ""},
+		{"policy_hooks", len(cfg.App.PolicyHooks) > 0},
+		{"device_policies", len(cfg.App.DevicePolicies) > 0},
+		{"anonymous_shortener", cfg.App.AnonymousEnabled},
+		{"stale_link_archive", cfg.App.StaleArchiveAfterDays > 0},
+		{"universal_links", len(cfg.App.IOSAppIDs) > 0},
+		{"install_attribution", cfg.App.AttributionTTL > 0},
+		{"link_cache", cfg.Server.RedisURL != "" || cfg.Server.LinkCacheSize > 0},
+		{"rate_limit", cfg.Server.RateLimitPerMinute > 0 || len(cfg.Server.RateLimitOverrides) > 0},
+		{"swagger_ui", cfg.Server.SwaggerUIEnabled},
+		{"web_ui", cfg.Server.WebUIEnabled},
+		{"grpc", cfg.Server.GRPCPort != ""},
+	}
+
+	features := []string{}
+	for _, flag := range flags {
+		if flag.enabled {
+			features = append(features, flag.name)
+		}
+	}
+	return features
+}
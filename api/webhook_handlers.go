@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+	"durable-links-generator/api/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type WebhookHandler interface {
+	Create(w http.ResponseWriter, r *http.Request)
+	List(w http.ResponseWriter, r *http.Request)
+	Delete(w http.ResponseWriter, r *http.Request)
+	Deliveries(w http.ResponseWriter, r *http.Request)
+}
+
+type webhookHandler struct {
+	webhookService service.WebhookService
+}
+
+func NewWebhookHandler(webhookService service.WebhookService) WebhookHandler {
+	return &webhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// Create registers a webhook on the domain. The response is the only one to hold its secret.
+func (h *webhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var hook models.Webhook
+	if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body", models.StatusInvalidArgument)
+		return
+	}
+
+	created, err := h.webhookService.Create(r.Context(), chi.URLParam(r, "domain"), hook)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (h *webhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	hooks, err := h.webhookService.List(r.Context(), chi.URLParam(r, "domain"))
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	writeProjectedJSON(w, r, models.WebhookListResponse{Webhooks: hooks}, "webhooks")
+}
+
+func (h *webhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.webhookService.Delete(r.Context(), chi.URLParam(r, "domain"), id); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries lists the latest delivery attempts of a webhook, newest first.
+func (h *webhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+	limit, ok := positiveIntParam(w, r.URL.Query().Get("limit"), "limit")
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(r.Context(), chi.URLParam(r, "domain"), id, limit)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	writeProjectedJSON(w, r, models.WebhookDeliveryListResponse{Deliveries: deliveries}, "deliveries")
+}
+
+// webhookID reads the id path param, answering 404 when it can't be the id of a webhook.
+func webhookID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id < 1 {
+		WriteError(w, apperrors.ErrWebhookNotFound, http.StatusNotFound, apperrors.ErrWebhookNotFound.Error(), models.StatusNotFound)
+		return 0, false
+	}
+	return id, true
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrMissingHost),
+		errors.Is(err, apperrors.ErrInvalidWebhookURL),
+		errors.Is(err, apperrors.ErrInvalidWebhookEvent),
+		errors.Is(err, apperrors.ErrInvalidClickThresholds):
+		WriteError(w, err, http.StatusBadRequest, err.Error(), models.StatusInvalidArgument)
+	case errors.Is(err, apperrors.ErrWebhookNotFound):
+		WriteError(w, err, http.StatusNotFound, err.Error(), models.StatusNotFound)
+	default:
+		requestLog(w).Error().Err(err).Msg("Webhook request failed")
+		WriteError(w, err, http.StatusInternalServerError, "Webhook request failed", models.StatusInternal)
+	}
+}
@@ -0,0 +1,225 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"durable-links-generator/api/models"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Deliveries sent at once. Deliveries waiting for a retry don't count.
+const maxConcurrentDeliveries = 32
+
+type Options struct {
+	// MaxAttempts is how many times an event is sent to a webhook before giving up.
+	MaxAttempts int
+	// Backoff is the delay before the second attempt, doubled for each attempt after it.
+	Backoff   time.Duration
+	QueueSize int
+	Client    *http.Client
+}
+
+// Dispatcher queues events and sends them from background goroutines to the webhooks of their
+// host, so publishing never slows down a request. Each attempt is logged to the store; failed
+// ones, without a 2xx response, are retried with exponential backoff.
+type Dispatcher struct {
+	store   Store
+	opts    Options
+	queue   chan Event
+	sending chan struct{}
+	now     func() time.Time
+
+	// mu guards closed, so events published while closing are dropped rather than sent on a
+	// closed queue.
+	mu     sync.RWMutex
+	closed bool
+
+	deliveries sync.WaitGroup
+	stop       chan struct{}
+	done       chan struct{}
+	once       sync.Once
+}
+
+func NewDispatcher(store Store, opts Options) *Dispatcher {
+	opts.MaxAttempts = max(opts.MaxAttempts, 1)
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	d := &Dispatcher{
+		store:   store,
+		opts:    opts,
+		queue:   make(chan Event, max(opts.QueueSize, 1)),
+		sending: make(chan struct{}, maxConcurrentDeliveries),
+		now:     time.Now,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go d.run()
+	return d
+}
+
+// Publish queues event, dropping it when the queue is full or the dispatcher is closed.
+func (d *Dispatcher) Publish(_ context.Context, event Event) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return
+	}
+	if event.ID == "" {
+		event.ID = newEventID()
+	}
+	if event.Time.IsZero() {
+		event.Time = d.now()
+	}
+	select {
+	case d.queue <- event:
+	default:
+		log.Warn().Str("type", event.Type).Str("host", event.Host).Msg("Webhook queue full, dropping event")
+	}
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+	for event := range d.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		hooks, err := d.store.ListWebhooks(ctx, event.Host)
+		cancel()
+		if err != nil {
+			log.Error().Err(err).Str("host", event.Host).Msg("Failed to read webhooks")
+			continue
+		}
+
+		var payload []byte
+		for _, hook := range hooks {
+			if !subscribed(hook, event) {
+				continue
+			}
+			if payload == nil {
+				if payload, err = json.Marshal(event); err != nil {
+					log.Error().Err(err).Str("type", event.Type).Msg("Failed to encode webhook event")
+					break
+				}
+			}
+			d.deliveries.Add(1)
+			go d.deliver(hook, event, payload)
+		}
+	}
+}
+
+// subscribed reports whether event is sent to hook.
+func subscribed(hook models.Webhook, event Event) bool {
+	if event.Type == EventClickThreshold {
+		return slices.Contains(hook.ClickThresholds, event.Threshold)
+	}
+	return len(hook.Events) == 0 || slices.Contains(hook.Events, event.Type)
+}
+
+// deliver sends event to hook until it succeeds, it runs out of attempts, or the dispatcher is
+// closed.
+func (d *Dispatcher) deliver(hook models.Webhook, event Event, payload []byte) {
+	defer d.deliveries.Done()
+	delay := d.opts.Backoff
+	for attempt := 1; ; attempt++ {
+		d.sending <- struct{}{}
+		delivery := d.send(hook, event, payload, attempt)
+		<-d.sending
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := d.store.LogDelivery(ctx, delivery); err != nil {
+			log.Error().Err(err).Int64("webhook_id", hook.ID).Msg("Failed to log webhook delivery")
+		}
+		cancel()
+		if delivery.Error == "" {
+			return
+		}
+		if attempt >= d.opts.MaxAttempts {
+			log.Warn().
+				Int64("webhook_id", hook.ID).
+				Str("event_id", event.ID).
+				Str("error", delivery.Error).
+				Msg("Webhook delivery failed, giving up")
+			return
+		}
+
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-d.stop:
+			log.Warn().Int64("webhook_id", hook.ID).Str("event_id", event.ID).Msg("Shutting down, webhook delivery not retried")
+			return
+		}
+	}
+}
+
+// send makes one attempt to deliver event to hook.
+func (d *Dispatcher) send(hook models.Webhook, event Event, payload []byte, attempt int) models.WebhookDelivery {
+	delivery := models.WebhookDelivery{
+		WebhookID:   hook.ID,
+		EventID:     event.ID,
+		EventType:   event.Type,
+		Payload:     string(payload),
+		Attempt:     attempt,
+		DeliveredAt: d.now(),
+	}
+	defer func() {
+		delivery.DurationMs = d.now().Sub(delivery.DeliveredAt).Milliseconds()
+	}()
+
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "durable-links-generator-webhooks")
+	req.Header.Set("X-Webhook-Event", event.Type)
+	req.Header.Set("X-Webhook-ID", event.ID)
+	req.Header.Set("X-Webhook-Attempt", strconv.Itoa(attempt))
+	req.Header.Set("X-Signature", Sign(hook.Secret, payload))
+
+	resp, err := d.opts.Client.Do(req)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	resp.Body.Close()
+	delivery.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		delivery.Error = fmt.Sprintf("endpoint returned status %d", resp.StatusCode)
+	}
+	return delivery
+}
+
+// Sign returns the X-Signature header of payload, "sha256=" followed by the hex HMAC-SHA256 of the
+// payload keyed with the webhook's secret, as notify.Webhook signs alerts.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Close sends the queued events and stops the dispatcher. Deliveries waiting for a retry are
+// given up.
+func (d *Dispatcher) Close() {
+	d.once.Do(func() {
+		d.mu.Lock()
+		d.closed = true
+		close(d.queue)
+		d.mu.Unlock()
+		<-d.done
+		close(d.stop)
+		d.deliveries.Wait()
+	})
+}
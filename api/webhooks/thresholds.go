@@ -0,0 +1,103 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	"durable-links-generator/api/clicks"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ClickTotals reads the total clicks of links.
+type ClickTotals interface {
+	// ClickTotal returns the path of the link path on host resolves to, and its total clicks.
+	ClickTotal(ctx context.Context, host, path string) (string, int64, error)
+}
+
+// ClickThresholds is a clicks.Store that publishes a link.click_threshold event when a count it
+// writes takes a link's total clicks to one of the click thresholds of its host's webhooks.
+// Thresholds are only checked for hosts that have some.
+type ClickThresholds struct {
+	clicks.Store
+	totals    ClickTotals
+	hooks     Store
+	publisher Publisher
+	urlScheme string
+}
+
+func NewClickThresholds(store clicks.Store, totals ClickTotals, hooks Store, publisher Publisher, urlScheme string) *ClickThresholds {
+	return &ClickThresholds{
+		Store:     store,
+		totals:    totals,
+		hooks:     hooks,
+		publisher: publisher,
+		urlScheme: urlScheme,
+	}
+}
+
+func (t *ClickThresholds) AddClicks(ctx context.Context, counts []clicks.Count) error {
+	if err := t.Store.AddClicks(ctx, counts); err != nil {
+		return err
+	}
+
+	byHost := map[string][]clicks.Count{}
+	for _, count := range counts {
+		byHost[count.Host] = append(byHost[count.Host], count)
+	}
+	for host, hostCounts := range byHost {
+		if err := t.checkThresholds(ctx, host, hostCounts); err != nil {
+			log.Error().Err(err).Str("host", host).Msg("Failed to check click thresholds")
+		}
+	}
+	return nil
+}
+
+func (t *ClickThresholds) checkThresholds(ctx context.Context, host string, counts []clicks.Count) error {
+	hooks, err := t.hooks.ListWebhooks(ctx, host)
+	if err != nil {
+		return err
+	}
+	thresholds := map[int64]bool{}
+	for _, hook := range hooks {
+		for _, threshold := range hook.ClickThresholds {
+			thresholds[threshold] = true
+		}
+	}
+	if len(thresholds) == 0 {
+		return nil
+	}
+
+	// Counts of several days or aliases may add to the same link.
+	added := map[string]int64{}
+	totals := map[string]int64{}
+	for _, count := range counts {
+		path, total, err := t.totals.ClickTotal(ctx, host, count.Path)
+		if err != nil {
+			return err
+		}
+		if path == "" {
+			continue
+		}
+		added[path] += count.Clicks
+		totals[path] = total
+	}
+	for path, total := range totals {
+		before := total - added[path]
+		for threshold := range thresholds {
+			if before < threshold && total >= threshold {
+				t.publisher.Publish(ctx, Event{
+					Type:      EventClickThreshold,
+					Host:      host,
+					Threshold: threshold,
+					Link: Link{
+						ShortLink:   fmt.Sprintf("%s://%s/%s", t.urlScheme, host, path),
+						Path:        path,
+						TotalClicks: total,
+					},
+				})
+			}
+		}
+	}
+	return nil
+}
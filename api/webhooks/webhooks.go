@@ -0,0 +1,67 @@
+// Package webhooks sends the link events of a short link domain to the endpoints registered for
+// it, as signed JSON payloads.
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"durable-links-generator/api/models"
+)
+
+// Types of events.
+const (
+	EventLinkCreated    = "link.created"
+	EventLinkUpdated    = "link.updated"
+	EventLinkDeleted    = "link.deleted"
+	EventLinkExpired    = "link.expired"
+	EventClickThreshold = "link.click_threshold"
+)
+
+// LinkEvents are the event types a webhook subscribes to through its events. Click threshold
+// events go to the webhooks whose click thresholds hold the threshold reached.
+var LinkEvents = []string{EventLinkCreated, EventLinkUpdated, EventLinkDeleted, EventLinkExpired}
+
+// Event is the payload posted to webhooks.
+type Event struct {
+	// ID identifies the event across delivery attempts, for receivers to drop duplicates.
+	ID   string    `json:"id"`
+	Type string    `json:"type"`
+	Host string    `json:"host"`
+	Time time.Time `json:"time"`
+	Link Link      `json:"link"`
+	// Threshold is the click count a link.click_threshold event reports reaching.
+	Threshold int64 `json:"threshold,omitempty"`
+}
+
+type Link struct {
+	ShortLink string `json:"shortLink"`
+	Path      string `json:"path"`
+	// Link is the destination, left out of events about links that are gone.
+	Link        string `json:"link,omitempty"`
+	TotalClicks int64  `json:"totalClicks,omitempty"`
+}
+
+// Publisher takes the events to send.
+type Publisher interface {
+	Publish(ctx context.Context, event Event)
+}
+
+// Nop drops every event.
+type Nop struct{}
+
+func (Nop) Publish(context.Context, Event) {}
+
+// Store reads the webhooks of a host and logs the deliveries to them.
+type Store interface {
+	ListWebhooks(ctx context.Context, host string) ([]models.Webhook, error)
+	LogDelivery(ctx context.Context, delivery models.WebhookDelivery) error
+}
+
+func newEventID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return "evt_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,181 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/clicks"
+	"durable-links-generator/api/models"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+	os.Exit(m.Run())
+}
+
+type memoryStore struct {
+	mu         sync.Mutex
+	hooks      []models.Webhook
+	deliveries []models.WebhookDelivery
+}
+
+func (s *memoryStore) ListWebhooks(_ context.Context, host string) ([]models.Webhook, error) {
+	var hooks []models.Webhook
+	for _, hook := range s.hooks {
+		if hook.Host == host {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks, nil
+}
+
+func (s *memoryStore) LogDelivery(_ context.Context, delivery models.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries = append(s.deliveries, delivery)
+	return nil
+}
+
+func TestDispatcher(t *testing.T) {
+	var calls atomic.Int32
+	var mu sync.Mutex
+	var bodies [][]byte
+	var signatures []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, body)
+		signatures = append(signatures, r.Header.Get("X-Signature"))
+		mu.Unlock()
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	store := &memoryStore{hooks: []models.Webhook{
+		{ID: 1, Host: "l.example.com", URL: server.URL, Secret: "s3cret", Events: []string{EventLinkCreated}},
+		{ID: 2, Host: "l.example.com", URL: server.URL, Secret: "other", Events: []string{EventLinkDeleted}},
+		{ID: 3, Host: "other.example.com", URL: server.URL, Secret: "other"},
+	}}
+	d := NewDispatcher(store, Options{MaxAttempts: 5, Backoff: time.Millisecond})
+	d.Publish(context.Background(), Event{
+		Type: EventLinkCreated,
+		Host: "l.example.com",
+		Link: Link{ShortLink: "https://l.example.com/abc", Path: "abc", Link: "https://example.com"},
+	})
+	assert.Eventually(t, func() bool { return calls.Load() == 3 }, 5*time.Second, 5*time.Millisecond)
+	d.Close()
+
+	assert.Len(t, bodies, 3, "only the subscribed webhook is called, until it answers 2xx")
+	var event Event
+	assert.NoError(t, json.Unmarshal(bodies[0], &event))
+	assert.Equal(t, EventLinkCreated, event.Type)
+	assert.Equal(t, "abc", event.Link.Path)
+	assert.NotEmpty(t, event.ID)
+	assert.Equal(t, Sign("s3cret", bodies[0]), signatures[0])
+	assert.Equal(t, bodies[0], bodies[2], "retries send the same payload")
+
+	if assert.Len(t, store.deliveries, 3) {
+		assert.Equal(t, 1, store.deliveries[0].Attempt)
+		assert.Equal(t, http.StatusServiceUnavailable, store.deliveries[0].StatusCode)
+		assert.NotEmpty(t, store.deliveries[0].Error)
+		assert.Equal(t, 3, store.deliveries[2].Attempt)
+		assert.Equal(t, http.StatusOK, store.deliveries[2].StatusCode)
+		assert.Empty(t, store.deliveries[2].Error)
+		assert.Equal(t, event.ID, store.deliveries[2].EventID)
+	}
+
+	d.Publish(context.Background(), Event{Type: EventLinkCreated, Host: "l.example.com"})
+	assert.Equal(t, int32(3), calls.Load(), "events published after Close are dropped")
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	store := &memoryStore{hooks: []models.Webhook{{ID: 1, Host: "l.example.com", URL: server.URL}}}
+	d := NewDispatcher(store, Options{MaxAttempts: 2, Backoff: time.Millisecond})
+	d.Publish(context.Background(), Event{Type: EventLinkDeleted, Host: "l.example.com"})
+	assert.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return len(store.deliveries) == 2
+	}, 5*time.Second, 5*time.Millisecond)
+	d.Close()
+
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+type countStore struct{ counts []clicks.Count }
+
+func (s *countStore) AddClicks(_ context.Context, counts []clicks.Count) error {
+	s.counts = append(s.counts, counts...)
+	return nil
+}
+
+// totals resolves every path of a host to "abc" and reports the clicks added to the host so far,
+// on top of base.
+type totals struct {
+	store *countStore
+	base  int64
+}
+
+func (t totals) ClickTotal(_ context.Context, host, _ string) (string, int64, error) {
+	total := t.base
+	for _, count := range t.store.counts {
+		if count.Host == host {
+			total += count.Clicks
+		}
+	}
+	return "abc", total, nil
+}
+
+type publisherFunc func(Event)
+
+func (f publisherFunc) Publish(_ context.Context, event Event) { f(event) }
+
+func TestClickThresholds(t *testing.T) {
+	store := &countStore{}
+	hooks := &memoryStore{hooks: []models.Webhook{
+		{ID: 1, Host: "l.example.com", ClickThresholds: []int64{100, 1000}},
+		{ID: 2, Host: "l.example.com", ClickThresholds: []int64{100}},
+	}}
+	var events []Event
+	thresholds := NewClickThresholds(store, totals{store: store, base: 95}, hooks, publisherFunc(func(event Event) {
+		events = append(events, event)
+	}), "https")
+	ctx := context.Background()
+
+	assert.NoError(t, thresholds.AddClicks(ctx, []clicks.Count{{Host: "l.example.com", Path: "abc", Clicks: 3}}))
+	assert.Empty(t, events)
+
+	assert.NoError(t, thresholds.AddClicks(ctx, []clicks.Count{
+		{Host: "l.example.com", Path: "abc", Clicks: 2},
+		{Host: "l.example.com", Path: "alias", Clicks: 1},
+		{Host: "other.example.com", Path: "abc", Clicks: 1000},
+	}))
+	if assert.Len(t, events, 1, "the threshold is reported once, however many webhooks hold it") {
+		assert.Equal(t, EventClickThreshold, events[0].Type)
+		assert.Equal(t, int64(100), events[0].Threshold)
+		assert.Equal(t, "https://l.example.com/abc", events[0].Link.ShortLink)
+	}
+
+	assert.NoError(t, thresholds.AddClicks(ctx, []clicks.Count{{Host: "l.example.com", Path: "abc", Clicks: 1}}))
+	assert.Len(t, events, 1, "passed thresholds aren't reported again")
+}
@@ -0,0 +1,267 @@
+// The web UI at /ui. It holds no data of its own: every call goes to the API with the API key
+// the user signs in with, kept in sessionStorage so it is gone when the tab closes.
+"use strict";
+
+const keyStorage = "durableLinks.apiKey";
+const hostStorage = "durableLinks.host";
+const pageSize = 25;
+
+const $ = (id) => document.getElementById(id);
+
+let apiKey = sessionStorage.getItem(keyStorage) || "";
+let nextPageToken = "";
+let createdPath = "";
+
+// api calls the API at path, returning the decoded JSON body, or a Blob with asBlob. Errors carry
+// the API's error message.
+async function api(path, { method = "GET", body, asBlob = false } = {}) {
+  const headers = { "X-API-Key": apiKey };
+  if (body !== undefined) {
+    headers["Content-Type"] = "application/json";
+  }
+  const resp = await fetch(path, {
+    method,
+    headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+    credentials: "omit",
+    cache: "no-store",
+  });
+  if (!resp.ok) {
+    let message = `Request failed with status ${resp.status}`;
+    try {
+      const data = await resp.json();
+      if (data.error && data.error.message) {
+        message = data.error.message;
+      }
+    } catch (_) {
+      // Not a JSON error body; keep the status.
+    }
+    const err = new Error(message);
+    err.status = resp.status;
+    throw err;
+  }
+  return asBlob ? resp.blob() : resp.json();
+}
+
+function showError(err) {
+  const el = $("error");
+  if (!err) {
+    el.hidden = true;
+    el.textContent = "";
+    return;
+  }
+  el.textContent = err.message || String(err);
+  el.hidden = false;
+  if (err.status === 401 || err.status === 403) {
+    signOut(false);
+  }
+}
+
+function host() {
+  return $("host").value.trim().toLowerCase();
+}
+
+function query(params) {
+  const q = new URLSearchParams();
+  for (const [name, value] of Object.entries(params)) {
+    if (value !== "" && value !== undefined) {
+      q.set(name, value);
+    }
+  }
+  return q.toString();
+}
+
+async function signIn(key) {
+  apiKey = key;
+  const { hosts } = await api("/admin/api/topHosts?limit=100");
+  sessionStorage.setItem(keyStorage, key);
+
+  const names = new Set(hosts.map((h) => h.host));
+  try {
+    const { domains } = await api("/v1/domains");
+    domains.forEach((d) => names.add(d.host));
+  } catch (_) {
+    // Domain configs are optional; the hosts with links are enough to pick from.
+  }
+  const list = $("hosts");
+  list.replaceChildren(...[...names].sort().map((name) => new Option(name, name)));
+  $("host").value = localStorage.getItem(hostStorage) || [...names][0] || "";
+
+  $("sign-in").hidden = true;
+  $("app").hidden = false;
+  $("host-form").hidden = false;
+  showError(null);
+  if (host()) {
+    await loadLinks(false);
+  }
+}
+
+function signOut(clearError = true) {
+  apiKey = "";
+  sessionStorage.removeItem(keyStorage);
+  $("app").hidden = true;
+  $("host-form").hidden = true;
+  $("sign-in").hidden = false;
+  $("api-key").value = "";
+  if (clearError) {
+    showError(null);
+  }
+}
+
+// loadLinks lists the host's links, newest first, or searches them when the search box isn't
+// empty. With more, the next page is appended.
+async function loadLinks(more) {
+  if (!host()) {
+    return;
+  }
+  const q = $("query").value.trim();
+  let links;
+  if (q) {
+    const resp = await api("/v1/search?" + query({ q, host: host(), sort: "newest", limit: 100 }));
+    links = resp.results.map((r) => ({ ...r, totalClicks: undefined }));
+    nextPageToken = "";
+  } else {
+    const resp = await api("/shortLinks?" + query({
+      host: host(),
+      pageSize,
+      pageToken: more ? nextPageToken : "",
+    }));
+    links = resp.links;
+    nextPageToken = resp.nextPageToken || "";
+  }
+
+  const body = $("links");
+  if (!more) {
+    body.replaceChildren();
+  }
+  links.forEach((link) => body.append(linkRow(link)));
+  $("empty").hidden = body.children.length > 0;
+  $("more").hidden = !nextPageToken;
+}
+
+function linkRow(link) {
+  const row = document.createElement("tr");
+
+  const short = document.createElement("a");
+  short.href = link.shortLink;
+  short.textContent = link.shortLink;
+  short.target = "_blank";
+  short.rel = "noopener noreferrer";
+  row.append(cell(short));
+
+  const destination = cell(link.link);
+  destination.className = "destination";
+  if (link.name || link.title) {
+    const label = document.createElement("div");
+    label.className = "hint";
+    label.textContent = link.name || link.title;
+    destination.append(label);
+  }
+  row.append(destination);
+
+  const clicks = cell(link.totalClicks === undefined ? "" : String(link.totalClicks));
+  clicks.className = "number";
+  row.append(clicks);
+  row.append(cell(new Date(link.createdAt).toLocaleDateString()));
+
+  const qr = document.createElement("button");
+  qr.type = "button";
+  qr.textContent = "PNG";
+  qr.addEventListener("click", () => downloadQR(link.path, "png").catch(showError));
+  row.append(cell(qr));
+  return row;
+}
+
+function cell(content) {
+  const td = document.createElement("td");
+  td.append(content);
+  return td;
+}
+
+async function createLink() {
+  const option = $("suffix").value;
+  const body = {
+    durableLinkInfo: { host: host(), link: $("link").value.trim() },
+    suffix: { option },
+  };
+  if (option === "CUSTOM") {
+    body.suffix.customPath = $("custom-path").value.trim();
+  }
+  const name = $("name").value.trim();
+  if (name) {
+    body.name = name;
+  }
+  const tags = $("tags").value.split(",").map((t) => t.trim()).filter(Boolean);
+  if (tags.length) {
+    body.tags = tags;
+  }
+
+  const resp = await api("/shortLinks", { method: "POST", body });
+  createdPath = new URL(resp.shortLink).pathname.slice(1);
+  $("created-link").href = resp.shortLink;
+  $("created-link").textContent = resp.shortLink;
+  $("warnings").replaceChildren(...(resp.warnings || []).map((w) => {
+    const item = document.createElement("li");
+    item.textContent = `${w.warningCode}: ${w.warningMessage}`;
+    return item;
+  }));
+  const preview = $("qr-preview");
+  if (preview.src.startsWith("blob:")) {
+    URL.revokeObjectURL(preview.src);
+  }
+  preview.src = URL.createObjectURL(await qrCode(createdPath, "png", 160));
+  $("created").hidden = false;
+  $("create-form").reset();
+  $("custom-path-label").hidden = true;
+  showError(null);
+  await loadLinks(false);
+}
+
+function qrCode(path, format, size = 512) {
+  return api(`/shortLinks/${encodeURIComponent(path)}/qr?` + query({ host: host(), format, size }), { asBlob: true });
+}
+
+async function downloadQR(path, format) {
+  const url = URL.createObjectURL(await qrCode(path, format));
+  const a = document.createElement("a");
+  a.href = url;
+  a.download = `${host()}-${path}.${format}`;
+  document.body.append(a);
+  a.click();
+  a.remove();
+  setTimeout(() => URL.revokeObjectURL(url), 1000);
+}
+
+function on(id, event, handler) {
+  $(id).addEventListener(event, (e) => {
+    if (event === "submit") {
+      e.preventDefault();
+    }
+    Promise.resolve(handler(e)).catch(showError);
+  });
+}
+
+on("sign-in-form", "submit", () => signIn($("api-key").value.trim()));
+on("sign-out", "click", () => signOut());
+on("host", "change", () => {
+  localStorage.setItem(hostStorage, host());
+  $("created").hidden = true;
+  return loadLinks(false);
+});
+on("host-form", "submit", () => loadLinks(false));
+on("suffix", "change", () => {
+  const custom = $("suffix").value === "CUSTOM";
+  $("custom-path-label").hidden = !custom;
+  $("custom-path").required = custom;
+});
+on("create-form", "submit", createLink);
+on("search-form", "submit", () => loadLinks(false));
+on("more", "click", () => loadLinks(true));
+on("copy", "click", () => navigator.clipboard.writeText($("created-link").textContent));
+document.querySelectorAll("[data-qr-format]").forEach((button) => {
+  button.addEventListener("click", () => downloadQR(createdPath, button.dataset.qrFormat).catch(showError));
+});
+
+if (apiKey) {
+  signIn(apiKey).catch(showError);
+}
@@ -0,0 +1,30 @@
+// Package webui is the single page web UI served at /ui, for creating, browsing and searching
+// links and downloading their QR codes without building a frontend. The page holds no data: it
+// asks for an API key and calls the API with it, so the API's key auth and roles guard
+// everything the UI shows, and the page's own files can be served to anyone.
+package webui
+
+import (
+	"embed"
+	"net/http"
+)
+
+//go:embed index.html app.js app.css
+var files embed.FS
+
+// The page only loads its own files and talks to its own origin, and may not be framed.
+const contentSecurityPolicy = "default-src 'none'; script-src 'self'; style-src 'self'; " +
+	"img-src 'self' blob:; connect-src 'self'; form-action 'self'; base-uri 'none'; frame-ancestors 'none'"
+
+// Handler serves the UI's files, index.html at the root. Mount it with the prefix stripped.
+func Handler() http.Handler {
+	fileServer := http.FileServer(http.FS(files))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", contentSecurityPolicy)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		// Revalidate, so a new release's files are picked up right away.
+		w.Header().Set("Cache-Control", "no-cache")
+		fileServer.ServeHTTP(w, r)
+	})
+}
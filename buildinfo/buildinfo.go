@@ -0,0 +1,43 @@
+package buildinfo
+
+import (
+	"runtime/debug"
+)
+
+// Set at build time, e.g.
+//
+//	go build -ldflags "-X durable-links-generator/buildinfo.Version=v1.2.0 \
+//	  -X durable-links-generator/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X durable-links-generator/buildinfo.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// Info returns the build metadata, falling back to the VCS stamp embedded by the Go toolchain
+// when the ldflags were not set.
+func Info() (version, commit, buildDate string) {
+	version, commit, buildDate = Version, Commit, BuildDate
+	if commit != "" && buildDate != "" {
+		return version, commit, buildDate
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return version, commit, buildDate
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if commit == "" {
+				commit = setting.Value
+			}
+		case "vcs.time":
+			if buildDate == "" {
+				buildDate = setting.Value
+			}
+		}
+	}
+	return version, commit, buildDate
+}
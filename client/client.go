@@ -0,0 +1,212 @@
+// Package client is a Go client of the durable links API.
+//
+//	c := client.New(client.Options{BaseURL: "https://links.example.com", APIKey: key})
+//	link, err := c.CreateLink(ctx, models.CreateDurableLinkRequest{...})
+//	if errors.Is(err, apperrors.ErrPathTaken) {
+//		...
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"durable-links-generator/api/models"
+)
+
+// Defaults of Options.
+const (
+	defaultTimeout    = 30 * time.Second
+	defaultMaxRetries = 3
+	defaultBackoff    = 200 * time.Millisecond
+	maxBackoff        = 10 * time.Second
+)
+
+type Options struct {
+	// BaseURL is the URL the API is served at, e.g. "https://links.example.com".
+	BaseURL string
+	// APIKey is sent as X-API-Key. CreateLink needs an editor's key unless the server sets
+	// PUBLIC_LINK_CREATION, and ListLinks a viewer's.
+	APIKey string
+	// HTTPClient sends the requests, one with a 30s timeout by default.
+	HTTPClient *http.Client
+	// MaxRetries is how many times a request is sent again after a retryable failure, 3 by
+	// default. A negative value disables retries.
+	MaxRetries int
+	// Backoff is the delay before the first retry, doubled for each retry after it unless the
+	// response has a Retry-After. Defaults to 200ms.
+	Backoff time.Duration
+}
+
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	maxRetries int
+	backoff    time.Duration
+}
+
+func New(opts Options) *Client {
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: defaultTimeout}
+	}
+	switch {
+	case opts.MaxRetries == 0:
+		opts.MaxRetries = defaultMaxRetries
+	case opts.MaxRetries < 0:
+		opts.MaxRetries = 0
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = defaultBackoff
+	}
+	return &Client{
+		baseURL:    strings.TrimSuffix(opts.BaseURL, "/"),
+		apiKey:     opts.APIKey,
+		httpClient: opts.HTTPClient,
+		maxRetries: opts.MaxRetries,
+		backoff:    opts.Backoff,
+	}
+}
+
+// CreateLink creates a short link with POST /shortLinks. It is only retried when the server
+// rejected the request without creating anything, so that a retry can't leave a second link.
+func (c *Client) CreateLink(ctx context.Context, req models.CreateDurableLinkRequest) (*models.ShortLinkResponse, error) {
+	var resp models.ShortLinkResponse
+	if err := c.do(ctx, http.MethodPost, "/shortLinks", req, false, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ExchangeShortLink resolves a short link to its long link with POST /exchangeShortLink.
+func (c *Client) ExchangeShortLink(ctx context.Context, req models.ExchangeShortLinkRequest) (*models.LongLinkResponse, error) {
+	var resp models.LongLinkResponse
+	if err := c.do(ctx, http.MethodPost, "/exchangeShortLink", req, true, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListLinks reads a page of a host's links with GET /shortLinks. The NextPageToken of the
+// response, passed as PageToken, reads the next page.
+func (c *Client) ListLinks(ctx context.Context, req models.ListLinksRequest) (*models.ListLinksResponse, error) {
+	query := url.Values{}
+	params := map[string]string{
+		"host":              req.Host,
+		"sort":              req.Sort,
+		"pageToken":         req.PageToken,
+		"suffix":            req.Suffix,
+		"destinationDomain": req.DestinationDomain,
+	}
+	for name, value := range params {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	if req.PageSize > 0 {
+		query.Set("pageSize", strconv.Itoa(req.PageSize))
+	}
+	if !req.CreatedAfter.IsZero() {
+		query.Set("createdAfter", req.CreatedAfter.Format(time.RFC3339))
+	}
+	if !req.CreatedBefore.IsZero() {
+		query.Set("createdBefore", req.CreatedBefore.Format(time.RFC3339))
+	}
+
+	var resp models.ListLinksResponse
+	if err := c.do(ctx, http.MethodGet, "/shortLinks?"+query.Encode(), nil, true, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// do sends a request with body encoded as JSON, decoding the response into out. Requests failing
+// with a retryable status are sent again, and so are idempotent ones failing before a response.
+func (c *Client) do(ctx context.Context, method, path string, body any, idempotent bool, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := c.send(ctx, method, path, payload, out)
+		if err == nil {
+			return nil
+		}
+		if attempt >= c.maxRetries || ctx.Err() != nil {
+			return err
+		}
+		retryable := idempotent
+		var apiErr *Error
+		if errors.As(err, &apiErr) {
+			retryable = apiErr.Retryable
+		}
+		if !retryable {
+			return err
+		}
+
+		delay := retryAfter
+		if delay <= 0 {
+			delay = min(c.backoff<<attempt, maxBackoff)
+			// Jitter keeps clients failing together from retrying together.
+			delay = delay/2 + rand.N(delay/2+1)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// send sends one attempt of a request, returning the Retry-After delay of a failed response.
+func (c *Client) send(ctx context.Context, method, path string, payload []byte, out any) (time.Duration, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		retryAfter := time.Duration(0)
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return retryAfter, newError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return 0, fmt.Errorf("failed to decode %s %s response: %w", method, path, err)
+	}
+	return 0, nil
+}
@@ -0,0 +1,194 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return New(Options{BaseURL: server.URL + "/", APIKey: "secret", Backoff: time.Millisecond})
+}
+
+func writeError(w http.ResponseWriter, code int, message string, status models.ErrorStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(models.ErrorResponse{Error: models.ErrorDetails{
+		Code:      code,
+		Message:   message,
+		Status:    status,
+		Retryable: status.Retryable(),
+	}})
+}
+
+func TestCreateLink(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/shortLinks", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("X-API-Key"))
+		var req models.CreateDurableLinkRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		assert.Equal(t, "https://example.com", req.DurableLinkInfo.Link)
+		json.NewEncoder(w).Encode(models.ShortLinkResponse{ShortLink: "https://acme.link/abc"})
+	})
+
+	resp, err := c.CreateLink(context.Background(), models.CreateDurableLinkRequest{
+		DurableLinkInfo: models.DurableLinkInfo{Host: "acme.link", Link: "https://example.com"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://acme.link/abc", resp.ShortLink)
+}
+
+func TestListLinks(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "acme.link", r.URL.Query().Get("host"))
+		assert.Equal(t, "2", r.URL.Query().Get("pageSize"))
+		assert.Equal(t, "2024-01-01T00:00:00Z", r.URL.Query().Get("createdAfter"))
+		assert.False(t, r.URL.Query().Has("sort"))
+		json.NewEncoder(w).Encode(models.ListLinksResponse{
+			Links:         []models.LinkSummary{{Path: "a"}, {Path: "b"}},
+			NextPageToken: "next",
+		})
+	})
+
+	resp, err := c.ListLinks(context.Background(), models.ListLinksRequest{
+		Host:         "acme.link",
+		PageSize:     2,
+		CreatedAfter: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Links, 2)
+	assert.Equal(t, "next", resp.NextPageToken)
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    error
+		status  models.ErrorStatus
+	}{
+		{
+			name: "reason",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(models.ErrorResponse{Error: models.ErrorDetails{
+					Code:    http.StatusNotFound,
+					Message: "Domain not found",
+					Status:  models.StatusNotFound,
+					Details: []models.ErrorInfo{{Type: models.ErrorInfoType, Reason: "DOMAIN_NOT_FOUND"}},
+				}})
+			},
+			want:   apperrors.ErrDomainNotFound,
+			status: models.StatusNotFound,
+		},
+		{
+			name: "message",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeError(w, http.StatusBadRequest, "sort must be one of: createdAt, clickCount, lastClickedAt, expiresAt, optionally prefixed with '-', got \"x\"", models.StatusInvalidArgument)
+			},
+			want:   apperrors.ErrInvalidListSort,
+			status: models.StatusInvalidArgument,
+		},
+		{
+			name: "status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeError(w, http.StatusNotFound, "Link not found", models.StatusNotFound)
+			},
+			want:   apperrors.ErrLinkNotFound,
+			status: models.StatusNotFound,
+		},
+		{
+			name: "deleted",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeError(w, http.StatusGone, "Link was deleted", models.StatusNotFound)
+			},
+			want:   apperrors.ErrLinkDeleted,
+			status: models.StatusNotFound,
+		},
+		{
+			name: "not an error response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "short and stout", http.StatusTeapot)
+			},
+			status: models.StatusInvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, tt.handler)
+			_, err := c.ExchangeShortLink(context.Background(), models.ExchangeShortLinkRequest{RequestedLink: "https://acme.link/abc"})
+			var apiErr *Error
+			if !assert.ErrorAs(t, err, &apiErr) {
+				return
+			}
+			assert.Equal(t, tt.status, apiErr.Status)
+			if tt.want != nil {
+				assert.ErrorIs(t, err, tt.want)
+			} else {
+				assert.Nil(t, apiErr.Unwrap())
+			}
+		})
+	}
+}
+
+func TestRetries(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			writeError(w, http.StatusServiceUnavailable, "Server is overloaded", models.StatusUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(models.ShortLinkResponse{ShortLink: "https://acme.link/abc"})
+	})
+	_, err := c.CreateLink(context.Background(), models.CreateDurableLinkRequest{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, calls.Load())
+
+	calls.Store(0)
+	c = newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		writeError(w, http.StatusInternalServerError, "Failed to create link", models.StatusInternal)
+	})
+	_, err = c.CreateLink(context.Background(), models.CreateDurableLinkRequest{})
+	assert.Error(t, err)
+	assert.EqualValues(t, 1, calls.Load(), "INTERNAL is not retried")
+
+	// Reading is retried after a failure without a response, creation isn't.
+	calls.Store(0)
+	failing := roundTripFunc(func(*http.Request) (*http.Response, error) {
+		calls.Add(1)
+		return nil, errors.New("connection reset")
+	})
+	c = New(Options{BaseURL: "http://acme.test", HTTPClient: &http.Client{Transport: failing}, MaxRetries: 2, Backoff: time.Millisecond})
+	_, err = c.ListLinks(context.Background(), models.ListLinksRequest{Host: "acme.link"})
+	assert.Error(t, err)
+	assert.EqualValues(t, 3, calls.Load())
+	calls.Store(0)
+	_, err = c.CreateLink(context.Background(), models.CreateDurableLinkRequest{})
+	assert.Error(t, err)
+	assert.EqualValues(t, 1, calls.Load())
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/models"
+)
+
+// Error is an error response of the API. It wraps the apperrors error the server failed with
+// when the response tells which, so that callers can test it with errors.Is.
+type Error struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	models.ErrorDetails
+	err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (%d %s)", e.Message, e.StatusCode, e.Status)
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// messageErrors are the errors servers without error reasons report with their own message,
+// possibly followed by details.
+var messageErrors = []error{
+	apperrors.ErrInvalidURLFormat,
+	apperrors.ErrHostInvalid,
+	apperrors.ErrInvalidAppStoreID,
+	apperrors.ErrInvalidPathFormat,
+	apperrors.ErrInvalidGate,
+	apperrors.ErrInvalidDeepLink,
+	apperrors.ErrInvalidPlatform,
+	apperrors.ErrSMSBudgetExceeded,
+	apperrors.ErrInvalidPlatformLink,
+	apperrors.ErrInvalidFormat,
+	apperrors.ErrMissingHost,
+	apperrors.ErrMissingLink,
+	apperrors.ErrMissingQuery,
+	apperrors.ErrInvalidSort,
+	apperrors.ErrInvalidListSort,
+	apperrors.ErrInvalidPageToken,
+	apperrors.ErrInvalidListSuffix,
+	apperrors.ErrInvalidDateRange,
+	apperrors.ErrInvalidDestinationDomain,
+	apperrors.ErrInvalidPath,
+	apperrors.ErrReservedPath,
+	apperrors.ErrInvalidSuffix,
+	apperrors.ErrPIIDetected,
+	apperrors.ErrPolicyDenied,
+}
+
+// statusErrors are the errors of the statuses that only ever report one, or one case of which
+// callers all handle the same, for servers without error reasons.
+var statusErrors = map[models.ErrorStatus]error{
+	models.StatusNotFound:          apperrors.ErrLinkNotFound,
+	models.StatusAlreadyExists:     apperrors.ErrPathTaken,
+	models.StatusResourceExhausted: apperrors.ErrRateLimited,
+	models.StatusPermissionDenied:  apperrors.ErrPolicyDenied,
+}
+
+// newError reads the error response resp.
+func newError(resp *http.Response) *Error {
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	var body models.ErrorResponse
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err := json.Unmarshal(raw, &body); err == nil && body.Error.Status != "" {
+		apiErr.ErrorDetails = body.Error
+	} else {
+		// Not one of ours, e.g. a proxy in front of the API failing.
+		status, ok := httpStatuses[resp.StatusCode]
+		if !ok {
+			status = httpStatuses[resp.StatusCode/100*100]
+		}
+		message := strings.TrimSpace(string(raw))
+		if message == "" {
+			message = http.StatusText(resp.StatusCode)
+		}
+		apiErr.ErrorDetails = models.ErrorDetails{
+			Code:      resp.StatusCode,
+			Message:   message,
+			Status:    status,
+			Retryable: status.Retryable(),
+		}
+	}
+	apiErr.err = appError(apiErr)
+	return apiErr
+}
+
+// httpStatuses maps the HTTP statuses of responses without error details to a status, other 4xx
+// and 5xx ones taking the status of 400 and 500.
+var httpStatuses = map[int]models.ErrorStatus{
+	http.StatusBadRequest:          models.StatusInvalidArgument,
+	http.StatusUnauthorized:        models.StatusUnauthenticated,
+	http.StatusForbidden:           models.StatusPermissionDenied,
+	http.StatusNotFound:            models.StatusNotFound,
+	http.StatusTooManyRequests:     models.StatusResourceExhausted,
+	http.StatusBadGateway:          models.StatusUnavailable,
+	http.StatusServiceUnavailable:  models.StatusUnavailable,
+	http.StatusGatewayTimeout:      models.StatusUnavailable,
+	http.StatusInternalServerError: models.StatusInternal,
+}
+
+// appError is the apperrors error e reports, nil when it isn't known. The reason in its details
+// tells, or else its message and status.
+func appError(e *Error) error {
+	for _, info := range e.Details {
+		if err := apperrors.FromCode(info.Reason); err != nil {
+			return err
+		}
+	}
+	if e.StatusCode == http.StatusGone {
+		return apperrors.ErrLinkDeleted
+	}
+	for _, err := range messageErrors {
+		if strings.HasPrefix(e.Message, err.Error()) {
+			return err
+		}
+	}
+	return statusErrors[e.Status]
+}
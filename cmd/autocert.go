@@ -0,0 +1,29 @@
+package main
+
+import (
+	"durable-links-generator/config"
+	"durable-links-generator/utils"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// newCertManager returns an ACME certificate manager for every configured domain and its preview
+// hosts, so links on preview hosts keep working under TLS.
+func newCertManager(cfg *config.Config) *autocert.Manager {
+	var hosts []string
+	for _, domain := range cfg.App.Domains {
+		hosts = append(hosts, utils.PreviewHostVariants(domain)...)
+	}
+
+	log.Info().
+		Strs("hosts", hosts).
+		Msg("Managing TLS certificates")
+
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      autocert.DirCache(cfg.Server.AutocertDir),
+		Email:      cfg.Server.AutocertEmail,
+		HostPolicy: autocert.HostWhitelist(hosts...),
+	}
+}
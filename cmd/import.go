@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"durable-links-generator/api/apperrors"
+	"durable-links-generator/api/repository"
+	"durable-links-generator/api/service"
+	"durable-links-generator/config"
+	"durable-links-generator/db"
+
+	"github.com/rs/zerolog/log"
+)
+
+// runImport runs the import subcommand: "import firebase [-host HOST] FILE" imports a Firebase
+// Dynamic Links CSV export like POST /import/firebase does, without its row limit.
+func runImport(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "firebase" {
+		return errors.New("usage: import firebase [-host HOST] FILE")
+	}
+	flags := flag.NewFlagSet("import firebase", flag.ContinueOnError)
+	host := flags.String("host", "", "domain to create the links on, the one of their Firebase short link by default")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: import firebase [-host HOST] FILE")
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	database, err := initDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	domainConfigs := service.NewDomainConfigs(repository.NewDomainRepositoryForDB(database), cfg.App.DomainConfigTTL)
+	// Imported links are recorded in the audit log as created by nobody, like those of background
+	// jobs.
+	linkRepository := repository.NewAuditedLinkRepository(repository.NewLinkRepositoryForDB(database), repository.NewAuditRepository(database.Write))
+	linkService, err := service.NewLinkService(linkRepository, cfg)
+	if err != nil {
+		return err
+	}
+	linkService.WithDomainConfigs(domainConfigs)
+	ctx := db.WithLane(context.Background(), db.LaneWrite)
+	results, err := service.NewFirebaseImportService(linkService, cfg).ImportFirebase(ctx, file, *host, 0)
+	if err != nil {
+		return err
+	}
+
+	var imported, skipped, failed int
+	for _, result := range results {
+		switch {
+		case result.Err == nil:
+			imported++
+		case errors.Is(result.Err, apperrors.ErrPathTaken):
+			skipped++
+			log.Warn().Int("row", result.Row).Str("source_link", result.SourceLink).Msg("Path already in use, skipped")
+		default:
+			failed++
+			log.Error().Err(result.Err).Int("row", result.Row).Str("source_link", result.SourceLink).Msg("Failed to import row")
+		}
+	}
+	log.Info().
+		Int("imported", imported).
+		Int("skipped", skipped).
+		Int("failed", failed).
+		Msg("Firebase export imported")
+	if failed > 0 {
+		return fmt.Errorf("%d rows failed", failed)
+	}
+	return nil
+}
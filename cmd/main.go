@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,18 +11,30 @@ import (
 	"time"
 
 	"durable-links-generator/api"
+	"durable-links-generator/api/cache"
+	"durable-links-generator/api/clicks"
+	"durable-links-generator/api/geoip"
+	"durable-links-generator/api/notify"
+	"durable-links-generator/api/repository"
+	"durable-links-generator/api/service"
+	"durable-links-generator/api/webhooks"
+	"durable-links-generator/buildinfo"
 	"durable-links-generator/config"
 	"durable-links-generator/db"
 
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
 )
 
 func initLogger(cfg *config.Config) {
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 	consoleWriter := zerolog.ConsoleWriter{Out: os.Stdout}
 	log.Logger = zerolog.New(consoleWriter).With().Timestamp().Logger()
+	// Code logging with log.Ctx outside of a request logs without a request ID.
+	zerolog.DefaultContextLogger = &log.Logger
 
 	level, err := zerolog.ParseLevel(cfg.Server.LogLevel)
 	if err != nil {
@@ -50,6 +63,107 @@ func initDatabase(cfg *config.Config) (*db.DB, error) {
 	return nil, fmt.Errorf("failed to connect to database after %d attempts: %w", maxRetries, err)
 }
 
+// checkDomainVerification registers the configured domains by checking their DNS verification
+// records, and logs and alerts with the record to publish for every domain that isn't verified
+// yet.
+func checkDomainVerification(ctx context.Context, cfg *config.Config, notifier notify.Notifier) error {
+	domains, err := service.NewDomainService(cfg, nil)
+	if err != nil {
+		return err
+	}
+	for _, result := range domains.VerifyAllDomains(ctx) {
+		if result.Verified {
+			log.Info().Str("domain", result.Domain).Msg("Domain verified")
+			continue
+		}
+		log.Warn().
+			Str("domain", result.Domain).
+			Str("txt_record", result.Record).
+			Msg("Domain not verified, publish the TXT record to verify it")
+		notifier.Notify(ctx, notify.Event{
+			Kind:     notify.KindDomainUnverified,
+			Project:  result.Domain,
+			Severity: notify.SeverityWarning,
+			Title:    "Domain not verified",
+			Message:  "Publish the TXT record to verify the domain.",
+			Fields:   map[string]string{"txt_record": result.Record},
+		})
+	}
+	return nil
+}
+
+// archiveStaleLinks runs the stale link archive policy every STALE_ARCHIVE_INTERVAL until ctx is
+// done. It returns at once when STALE_ARCHIVE_AFTER_DAYS isn't set.
+func archiveStaleLinks(ctx context.Context, cfg *config.Config, database *db.DB, linkCache repository.LinkCache, notifier notify.Notifier, publisher webhooks.Publisher) {
+	if cfg.App.StaleArchiveAfterDays <= 0 {
+		return
+	}
+
+	repo := repository.NewCachedLinkRepository(repository.NewLinkRepositoryForDB(database), linkCache)
+	staleLinks := service.NewStaleLinkService(repo, cfg, notifier).WithWebhooks(publisher)
+	ticker := time.NewTicker(cfg.App.StaleArchiveInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := staleLinks.ArchiveStaleLinks(db.WithLane(ctx, db.LaneWrite)); err != nil {
+			log.Error().Err(err).Msg("Failed to archive stale links")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeIdempotencyKeys deletes the responses of Idempotency-Keys older than IDEMPOTENCY_KEY_TTL
+// every hour until ctx is done.
+func purgeIdempotencyKeys(ctx context.Context, cfg *config.Config, database *db.DB) {
+	repo := repository.NewIdempotencyRepository(database.Write)
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		deleted, err := repo.DeleteExpiredIdempotencyKeys(ctx, time.Now().Add(-cfg.Server.IdempotencyKeyTTL))
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to purge idempotency keys")
+		} else if deleted > 0 {
+			log.Info().Int64("deleted", deleted).Msg("Purged expired idempotency keys")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// seedFixtures creates the deterministic preview environment links when FIXTURE_SEED is set.
+func seedFixtures(ctx context.Context, cfg *config.Config, database *db.DB, linkCache repository.LinkCache) {
+	if cfg.App.FixtureSeed == "" {
+		return
+	}
+
+	repo := repository.NewCachedLinkRepository(repository.NewLinkRepositoryForDB(database), linkCache)
+	if _, err := service.NewFixtureService(repo, cfg).Seed(db.WithLane(ctx, db.LaneWrite)); err != nil {
+		log.Error().Err(err).Msg("Failed to seed link fixtures")
+	}
+}
+
+// stopGRPCServer lets the gRPC calls in flight finish, cancelling those still running when ctx is
+// done.
+func stopGRPCServer(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		log.Error().Msg("gRPC server forced to shutdown")
+		server.Stop()
+	}
+}
+
 func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Warn().Msg("No .env file found, using environment variables")
@@ -58,6 +172,26 @@ func main() {
 	cfg := config.New()
 	initLogger(cfg)
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			log.Fatal().Err(err).Msg("Failed to migrate database")
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := runImport(cfg, os.Args[2:]); err != nil {
+			log.Fatal().Err(err).Msg("Failed to import links")
+		}
+		return
+	}
+
+	version, commit, buildDate := buildinfo.Info()
+	log.Info().
+		Str("version", version).
+		Str("commit", commit).
+		Str("build_date", buildDate).
+		Msg("Starting durable links generator")
+
 	database, err := initDatabase(cfg)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to connect to database")
@@ -67,7 +201,89 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	router := api.NewRouter(database.DB, cfg)
+	if cfg.Server.DBAutoMigrate {
+		if err := migrateDatabase(ctx, database); err != nil {
+			log.Fatal().Err(err).Msg("Failed to migrate database")
+		}
+	}
+
+	notifier, err := notify.New(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up notifications")
+	}
+	if dispatcher, ok := notifier.(*notify.Dispatcher); ok {
+		defer dispatcher.Close()
+	}
+
+	var redisClient *redis.Client
+	if cfg.Server.RedisURL != "" {
+		redisClient, err = cache.Dial(cfg.Server.RedisURL)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to connect to Redis")
+		}
+		defer redisClient.Close()
+	}
+
+	// Without REDIS_URL or LINK_CACHE_SIZE, link lookups always go to the database.
+	var linkCache repository.LinkCache
+	switch {
+	case redisClient != nil:
+		linkCache = cache.NewRedis(redisClient, cfg.Server.LinkCacheTTL)
+	case cfg.Server.LinkCacheSize > 0:
+		linkCache = cache.NewLRU(cfg.Server.LinkCacheSize, cfg.Server.LinkCacheTTL)
+	}
+
+	// Closed after the click aggregator, sending the click threshold events of its last flush.
+	webhookRepository := repository.NewWebhookRepositoryForDB(database)
+	webhookDispatcher := webhooks.NewDispatcher(webhookRepository, webhooks.Options{
+		MaxAttempts: cfg.App.WebhookMaxAttempts,
+		Backoff:     cfg.App.WebhookRetryBackoff,
+		QueueSize:   cfg.App.WebhookQueueSize,
+	})
+	defer webhookDispatcher.Close()
+
+	if err := checkDomainVerification(ctx, cfg, notifier); err != nil {
+		log.Fatal().Err(err).Msg("Invalid domain verification config")
+	}
+	seedFixtures(ctx, cfg, database, linkCache)
+	go archiveStaleLinks(ctx, cfg, database, linkCache, notifier, webhookDispatcher)
+	go purgeIdempotencyKeys(ctx, cfg, database)
+
+	// Closed before the database, writing the clicks still queued.
+	clickRepository := repository.NewLinkRepositoryForDB(database)
+	clickAggregator := clicks.NewAggregator(
+		webhooks.NewClickThresholds(clickRepository, clickRepository, webhookRepository, webhookDispatcher, cfg.App.URLScheme),
+		cfg.App.ClickFlushInterval,
+		cfg.App.ClickQueueSize,
+	)
+	defer clickAggregator.Close()
+
+	locator, err := geoip.Open(cfg.App.GeoIPDatabase)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load GeoIP database")
+	}
+	visitorHasher, err := clicks.NewVisitorHasher(cfg.App.VisitorHashSecret, cfg.App.VisitorSaltRotation)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up visitor hashing")
+	}
+	clickWriter := clicks.NewWriter(
+		repository.NewLinkRepositoryForDB(database),
+		locator,
+		visitorHasher,
+		cfg.App.ClickFlushInterval,
+		cfg.App.ClickQueueSize,
+		cfg.App.ClickEventBatchSize,
+	)
+	defer clickWriter.Close()
+
+	services, err := api.NewServices(database, cfg, notifier, clickAggregator, clickWriter, linkCache, webhookDispatcher, locator)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up services")
+	}
+	router, err := api.NewRouter(database, cfg, notifier, services, redisClient)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up routes")
+	}
 
 	server := &http.Server{
 		Addr:         fmt.Sprintf("0.0.0.0:%s", cfg.Server.Port),
@@ -77,13 +293,57 @@ func main() {
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
+	var challengeServer *http.Server
+	if cfg.Server.AutocertEnabled {
+		certManager := newCertManager(cfg)
+		server.Addr = fmt.Sprintf("0.0.0.0:%s", cfg.Server.TLSPort)
+		server.TLSConfig = certManager.TLSConfig()
+
+		challengeServer = &http.Server{
+			Addr:         fmt.Sprintf("0.0.0.0:%s", cfg.Server.Port),
+			Handler:      certManager.HTTPHandler(nil),
+			ReadTimeout:  cfg.Server.ReadTimeout,
+			WriteTimeout: cfg.Server.WriteTimeout,
+			IdleTimeout:  cfg.Server.IdleTimeout,
+		}
+
+		go func() {
+			log.Info().Msgf("ACME challenge server starting on port %s", cfg.Server.Port)
+			if err := challengeServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatal().Err(err).Msg("ACME challenge server failed to start")
+			}
+		}()
+	}
+
 	go func() {
-		log.Info().Msgf("Server starting on port %s", cfg.Server.Port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if cfg.Server.AutocertEnabled {
+			log.Info().Msgf("TLS server starting on port %s", cfg.Server.TLSPort)
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			log.Info().Msgf("Server starting on port %s", cfg.Server.Port)
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Fatal().Err(err).Msg("Server failed to start")
 		}
 	}()
 
+	var grpcServer *grpc.Server
+	if cfg.Server.GRPCPort != "" {
+		grpcServer = api.NewGRPCServer(services, cfg)
+		listener, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%s", cfg.Server.GRPCPort))
+		if err != nil {
+			log.Fatal().Err(err).Msg("gRPC server failed to start")
+		}
+		go func() {
+			log.Info().Msgf("gRPC server starting on port %s", cfg.Server.GRPCPort)
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatal().Err(err).Msg("gRPC server failed")
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
@@ -92,6 +352,16 @@ func main() {
 	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, cfg.Server.ShutdownTimeout)
 	defer shutdownCancel()
 
+	if challengeServer != nil {
+		if err := challengeServer.Shutdown(shutdownCtx); err != nil {
+			log.Error().Err(err).Msg("ACME challenge server forced to shutdown")
+		}
+	}
+
+	if grpcServer != nil {
+		stopGRPCServer(shutdownCtx, grpcServer)
+	}
+
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		log.Fatal().Err(err).Msg("Server forced to shutdown")
 	}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"durable-links-generator/config"
+	"durable-links-generator/db"
+
+	"github.com/rs/zerolog/log"
+)
+
+// migrateDatabase applies the migrations the database hasn't run yet.
+func migrateDatabase(ctx context.Context, database *db.DB) error {
+	applied, err := db.Migrate(ctx, database)
+	for _, version := range applied {
+		log.Info().Int("version", version).Msg("Applied migration")
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		log.Info().Msg("Database schema is up to date")
+	}
+	return nil
+}
+
+// runMigrate runs the migrate subcommand: "migrate" or "migrate up" applies the pending
+// migrations, "migrate status" reports the schema version of the database.
+func runMigrate(cfg *config.Config, args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+	if command != "up" && command != "status" {
+		return fmt.Errorf("unknown migrate command %q, want up or status", command)
+	}
+
+	database, err := initDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	ctx := context.Background()
+	if command == "up" {
+		return migrateDatabase(ctx, database)
+	}
+
+	version, err := db.MigrationVersion(ctx, database)
+	if err != nil {
+		return err
+	}
+	log.Info().
+		Int("version", version).
+		Int("latest", db.LatestMigrationVersion()).
+		Str("driver", database.Dialect.String()).
+		Msg("Database schema version")
+	return nil
+}
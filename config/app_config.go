@@ -1,21 +1,202 @@
 package config
 
+import (
+	"strings"
+	"time"
+)
+
 type AppConfig struct {
-	ShortPathLength           int
-	UnguessablePathLength     int
-	DefaultAndroidPackageName *string
-	DefaultIosStoreId         *string
-	URLScheme                 string
-	AllowedDomains            []string
+	ShortPathLength            int
+	UnguessablePathLength      int
+	PathCollisionRetries       int    // new paths tried after a generated path turned out to be taken
+	PathCollisionEscalateAfter int    // collisions after which retried paths get a character longer
+	PathGenerator              string // "random", "sequence", "hash", "hmac" or a registered custom strategy
+	PathGeneratorSecret        string
+	DefaultAndroidPackageName  *string
+	DefaultIosStoreId          *string
+	DomainConfigTTL            time.Duration // how long settings read from the domains table are cached
+	URLScheme                  string
+	AllowedDomains             []string
+	FallbackAllowedDomains     []string // hosts afl, ifl, ipfl, ofl and web agl, msl and masl may point to, AllowedDomains if empty, "*" for any
+	ImageAllowedDomains        []string // the same for si
+	Domains                    []string // short link hosts served by this instance
+	DomainVerificationSecret   string
+	PIIScanPolicy              string   // "off", "warn" or "block"
+	WarningsAsErrors           []string // link creation warning codes that reject the request instead
+	URLReputationProvider      string   // "none", "safebrowsing" or "http", see api/reputation
+	URLReputationAPIKey        string
+	URLReputationURL           string
+	URLReputationPolicy        string // "warn" or "block" links to flagged URLs
+	URLReputationTimeout       time.Duration
+	URLReputationFailOpen      bool          // create links when the reputation service can't be reached
+	DestinationCheckTimeout    time.Duration // of the request sent to destinations by validateDestination=true
+	DestinationCheckRedirects  int           // redirects followed by that request
+	ConsentRequired            bool
+	ConsentParam               string
+	ConsentCookie              string
+	InterstitialGates          map[string]string // host -> "age" or "terms"
+	InterstitialMinAge         int
+	InterstitialTermsURL       string
+	LinkInfoDomains            []string          // hosts serving the "/{path}+" link info page
+	ReservedLinkBehavior       string            // "page", "notfound" or a URL to redirect reserved links to
+	PrelaunchLinkBehavior      string            // the same for links whose activation window hasn't opened
+	ExpiredLinkBehavior        string            // the same for expired links without an expiredLink
+	PreviewPageTemplates       map[string]string // host -> HTML template file for its preview page
+	ReservedPaths              []string          // paths CUSTOM links and aliases may not use, on top of the built-in ones
+	IOSAppIDs                  map[string]string // host -> space separated "TEAMID.bundle.id" app IDs
+	PolicyContact              string
+	ScannerFriendlyDomains     []string
+	HeadCountsAsClick          bool
+	SDKSigningKey              string
+	SignedLinkKeys             map[string]string // key ID -> HMAC secret of signed links
+	SignedLinkKeyID            string            // the key new signed links are signed with
+	PublicAPIBase              string            // API base URL of the web SDK config, instead of the request's
+	ClickRetentionDays         int
+	TenantCreateQPS            int
+	TenantCreateBurst          int
+	TenantCreateConcurrency    int
+	TenantCreateQPSOverrides   map[string]string // host -> QPS
+	FixtureSeed                string
+	FixtureHost                string
+	FixtureCount               int
+	FixtureDestination         string
+	PolicyHooks                []string
+	PolicyUTMCampaignPattern   string
+	PolicyTakedownLinks        []string // "host/path"
+	PolicyTakedownDestinations []string
+	PolicyCELFile              string
+	NotificationsFile          string
+	NotificationsDedupWindow   time.Duration
+	ClickFlushInterval         time.Duration
+	ClickQueueSize             int
+	ClickEventBatchSize        int
+	GeoIPDatabase              string // CSV of IP ranges and country codes, see geoip.Parse
+	VisitorHashSecret          string // derives the visitor hash salts, shared by instances counting the same clicks
+	VisitorSaltRotation        time.Duration
+	StaleLinkDays              int
+	StaleArchiveAfterDays      int
+	StaleArchiveInterval       time.Duration
+	AttributionTTL             time.Duration // how long clicks can be claimed by installs, 0 turns attribution off
+	AttributionMaxIPs          int           // client addresses whose clicks are kept for attribution, 0 for no limit
+	DeepLinkBaseURL            string
+	DeepLinkBaseURLs           map[string]string // host -> base URL
+	DevicePolicies             map[string]string // "tv", "console" or "watch" -> "redirect", "ofl" or "qr"
+	NumericCodeLength          int
+	SMSLinkMaxLength           int
+	BatchMaxLinks              int // links one POST /shortLinks:batch call may create
+	ImportMaxRows              int // rows one POST /import/firebase call may import
+	WebhookMaxAttempts         int // attempts to deliver an event to a webhook before giving up
+	WebhookRetryBackoff        time.Duration
+	WebhookQueueSize           int // events waiting to be sent before new ones are dropped
+	CardBackgroundColor        string
+	CardTextColor              string
+	CardAccentColor            string
+	CardBrandName              string
+	QRLogo                     string // PNG or JPEG drawn in the middle of QR codes
+	QRErrorCorrection          string // default QR code error correction level, L, M, Q or H
+	PublicLinkCreation         bool   // lets POST /shortLinks and /shortLinks:batch through without an editor's key, as Firebase did
+	AnonymousEnabled           bool
+	AnonymousHost              string
+	AnonymousAllowedDomains    []string
+	AnonymousRatePerMinute     int
+	AnonymousBurst             int
 }
 
 func NewAppConfig() *AppConfig {
 	return &AppConfig{
-		ShortPathLength:           getEnvAsInt("SHORT_PATH_LENGTH", 6),
-		UnguessablePathLength:     getEnvAsInt("UNGUESSABLE_PATH_LENGTH", 10),
-		DefaultAndroidPackageName: getEnvAsOptionalString("DEFAULT_ANDROID_PACKAGE_NAME"),
-		DefaultIosStoreId:         getEnvAsOptionalString("DEFAULT_IOS_STORE_ID"),
-		URLScheme:                 getEnv("URL_SCHEME", "https"),
-		AllowedDomains:            getEnvAsSlice("ALLOWED_DOMAINS", []string{}),
+		ShortPathLength:            getEnvAsInt("SHORT_PATH_LENGTH", 6),
+		UnguessablePathLength:      getEnvAsInt("UNGUESSABLE_PATH_LENGTH", 10),
+		PathCollisionRetries:       getEnvAsInt("PATH_COLLISION_RETRIES", 5),
+		PathCollisionEscalateAfter: getEnvAsInt("PATH_COLLISION_ESCALATE_AFTER", 2),
+		PathGenerator:              getEnv("PATH_GENERATOR", "random"),
+		PathGeneratorSecret:        getEnv("PATH_GENERATOR_SECRET", ""),
+		DefaultAndroidPackageName:  getEnvAsOptionalString("DEFAULT_ANDROID_PACKAGE_NAME"),
+		DefaultIosStoreId:          getEnvAsOptionalString("DEFAULT_IOS_STORE_ID"),
+		DomainConfigTTL:            getEnvAsDuration("DOMAIN_CONFIG_TTL", time.Minute),
+		URLScheme:                  getEnv("URL_SCHEME", "https"),
+		AllowedDomains:             getEnvAsSlice("ALLOWED_DOMAINS", []string{}),
+		FallbackAllowedDomains:     getEnvAsSlice("FALLBACK_ALLOWED_DOMAINS", []string{}),
+		ImageAllowedDomains:        getEnvAsSlice("IMAGE_ALLOWED_DOMAINS", []string{}),
+		Domains:                    getEnvAsSlice("DOMAINS", []string{}),
+		DomainVerificationSecret:   getEnv("DOMAIN_VERIFICATION_SECRET", ""),
+		PIIScanPolicy:              getEnv("PII_SCAN_POLICY", "off"),
+		WarningsAsErrors:           getEnvAsSlice("WARNINGS_AS_ERRORS", []string{}),
+		URLReputationProvider:      getEnv("URL_REPUTATION_PROVIDER", "none"),
+		URLReputationAPIKey:        getEnv("URL_REPUTATION_API_KEY", ""),
+		URLReputationURL:           getEnv("URL_REPUTATION_URL", ""),
+		URLReputationPolicy:        getEnv("URL_REPUTATION_POLICY", "block"),
+		URLReputationTimeout:       getEnvAsDuration("URL_REPUTATION_TIMEOUT", 2*time.Second),
+		URLReputationFailOpen:      getEnvAsBool("URL_REPUTATION_FAIL_OPEN", true),
+		DestinationCheckTimeout:    getEnvAsDuration("DESTINATION_CHECK_TIMEOUT", 3*time.Second),
+		DestinationCheckRedirects:  getEnvAsInt("DESTINATION_CHECK_MAX_REDIRECTS", 5),
+		ConsentRequired:            getEnvAsBool("CONSENT_REQUIRED", false),
+		ConsentParam:               getEnv("CONSENT_PARAM", "consent"),
+		ConsentCookie:              getEnv("CONSENT_COOKIE", ""),
+		InterstitialGates:          getEnvAsMap("INTERSTITIAL_GATES"),
+		InterstitialMinAge:         getEnvAsInt("INTERSTITIAL_MIN_AGE", 18),
+		InterstitialTermsURL:       getEnv("INTERSTITIAL_TERMS_URL", ""),
+		LinkInfoDomains:            getEnvAsSlice("LINK_INFO_DOMAINS", []string{}),
+		ReservedLinkBehavior:       getEnv("RESERVED_LINK_BEHAVIOR", "page"),
+		PrelaunchLinkBehavior:      getEnv("PRELAUNCH_LINK_BEHAVIOR", "page"),
+		ExpiredLinkBehavior:        getEnv("EXPIRED_LINK_BEHAVIOR", "page"),
+		PreviewPageTemplates:       getEnvAsMap("PREVIEW_PAGE_TEMPLATES"),
+		ReservedPaths:              getEnvAsSlice("RESERVED_PATHS", []string{}),
+		IOSAppIDs:                  getEnvAsMap("IOS_APP_IDS"),
+		PolicyContact:              getEnv("POLICY_CONTACT", ""),
+		ScannerFriendlyDomains:     getEnvAsSlice("SCANNER_FRIENDLY_DOMAINS", []string{}),
+		HeadCountsAsClick:          getEnvAsBool("HEAD_COUNTS_AS_CLICK", false),
+		SDKSigningKey:              getEnv("SDK_SIGNING_KEY", ""),
+		SignedLinkKeys:             getEnvAsMap("SIGNED_LINK_KEYS"),
+		SignedLinkKeyID:            strings.ToLower(getEnv("SIGNED_LINK_KEY_ID", "")),
+		PublicAPIBase:              getEnv("PUBLIC_API_BASE", ""),
+		ClickRetentionDays:         getEnvAsInt("CLICK_RETENTION_DAYS", 0),
+		TenantCreateQPS:            getEnvAsInt("TENANT_CREATE_QPS", 0),
+		TenantCreateBurst:          getEnvAsInt("TENANT_CREATE_BURST", 0),
+		TenantCreateConcurrency:    getEnvAsInt("TENANT_CREATE_CONCURRENCY", 0),
+		TenantCreateQPSOverrides:   getEnvAsMap("TENANT_CREATE_QPS_OVERRIDES"),
+		FixtureSeed:                getEnv("FIXTURE_SEED", ""),
+		FixtureHost:                getEnv("FIXTURE_HOST", ""),
+		FixtureCount:               getEnvAsInt("FIXTURE_COUNT", 10),
+		FixtureDestination:         getEnv("FIXTURE_DESTINATION", "https://example.com/fixtures"),
+		PolicyHooks:                getEnvAsSlice("POLICY_HOOKS", []string{}),
+		PolicyUTMCampaignPattern:   getEnv("POLICY_UTM_CAMPAIGN_PATTERN", ""),
+		PolicyTakedownLinks:        getEnvAsSlice("POLICY_TAKEDOWN_LINKS", []string{}),
+		PolicyTakedownDestinations: getEnvAsSlice("POLICY_TAKEDOWN_DESTINATIONS", []string{}),
+		PolicyCELFile:              getEnv("POLICY_CEL_FILE", ""),
+		NotificationsFile:          getEnv("NOTIFICATIONS_FILE", ""),
+		NotificationsDedupWindow:   getEnvAsDuration("NOTIFICATIONS_DEDUP_WINDOW", 15*time.Minute),
+		ClickFlushInterval:         getEnvAsDuration("CLICK_FLUSH_INTERVAL", 5*time.Second),
+		ClickQueueSize:             getEnvAsInt("CLICK_QUEUE_SIZE", 10000),
+		ClickEventBatchSize:        getEnvAsInt("CLICK_EVENT_BATCH_SIZE", 500),
+		GeoIPDatabase:              getEnv("GEOIP_DATABASE", ""),
+		VisitorHashSecret:          getEnv("VISITOR_HASH_SECRET", ""),
+		VisitorSaltRotation:        getEnvAsDuration("VISITOR_SALT_ROTATION", 24*time.Hour),
+		StaleLinkDays:              getEnvAsInt("STALE_LINK_DAYS", 90),
+		StaleArchiveAfterDays:      getEnvAsInt("STALE_ARCHIVE_AFTER_DAYS", 0),
+		StaleArchiveInterval:       getEnvAsDuration("STALE_ARCHIVE_INTERVAL", time.Hour),
+		AttributionTTL:             getEnvAsDuration("ATTRIBUTION_TTL", time.Hour),
+		AttributionMaxIPs:          getEnvAsInt("ATTRIBUTION_MAX_IPS", 100000),
+		DeepLinkBaseURL:            getEnv("DEEP_LINK_BASE_URL", ""),
+		DeepLinkBaseURLs:           getEnvAsMap("DEEP_LINK_BASE_URLS"),
+		DevicePolicies:             getEnvAsMap("DEVICE_POLICIES"),
+		NumericCodeLength:          getEnvAsInt("NUMERIC_CODE_LENGTH", 6),
+		SMSLinkMaxLength:           getEnvAsInt("SMS_LINK_MAX_LENGTH", 40),
+		BatchMaxLinks:              getEnvAsInt("BATCH_MAX_LINKS", 100),
+		ImportMaxRows:              getEnvAsInt("IMPORT_MAX_ROWS", 10000),
+		WebhookMaxAttempts:         getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 5),
+		WebhookRetryBackoff:        getEnvAsDuration("WEBHOOK_RETRY_BACKOFF", 2*time.Second),
+		WebhookQueueSize:           getEnvAsInt("WEBHOOK_QUEUE_SIZE", 1024),
+		CardBackgroundColor:        getEnv("CARD_BACKGROUND_COLOR", "#1a1a2e"),
+		CardTextColor:              getEnv("CARD_TEXT_COLOR", "#ffffff"),
+		CardAccentColor:            getEnv("CARD_ACCENT_COLOR", "#e94560"),
+		CardBrandName:              getEnv("CARD_BRAND_NAME", ""),
+		QRLogo:                     getEnv("QR_LOGO", ""),
+		QRErrorCorrection:          getEnv("QR_ERROR_CORRECTION", "M"),
+		PublicLinkCreation:         getEnvAsBool("PUBLIC_LINK_CREATION", false),
+		AnonymousEnabled:           getEnvAsBool("ANONYMOUS_SHORTENER_ENABLED", false),
+		AnonymousHost:              getEnv("ANONYMOUS_HOST", ""),
+		AnonymousAllowedDomains:    getEnvAsSlice("ANONYMOUS_ALLOWED_DOMAINS", []string{}),
+		AnonymousRatePerMinute:     getEnvAsInt("ANONYMOUS_RATE_PER_MINUTE", 5),
+		AnonymousBurst:             getEnvAsInt("ANONYMOUS_BURST", 5),
 	}
 }
@@ -35,6 +35,23 @@ func getEnvAsSlice(key string, defaultVal []string) []string {
 	return defaultVal
 }
 
+// getEnvAsMap parses a comma separated list of key:value pairs, e.g. "a.link:age,b.link:terms".
+func getEnvAsMap(key string) map[string]string {
+	result := map[string]string{}
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return result
+	}
+	for _, pair := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(pair, ":")
+		if !ok {
+			continue
+		}
+		result[strings.ToLower(strings.TrimSpace(k))] = strings.TrimSpace(v)
+	}
+	return result
+}
+
 func getEnvAsInt(name string, defaultVal int) int {
 	if valStr, ok := os.LookupEnv(name); ok {
 		if val, err := strconv.Atoi(valStr); err == nil {
@@ -44,6 +61,15 @@ func getEnvAsInt(name string, defaultVal int) int {
 	return defaultVal
 }
 
+func getEnvAsBool(name string, defaultVal bool) bool {
+	if valStr, ok := os.LookupEnv(name); ok {
+		if val, err := strconv.ParseBool(valStr); err == nil {
+			return val
+		}
+	}
+	return defaultVal
+}
+
 func getEnvAsDuration(name string, defaultVal time.Duration) time.Duration {
 	if valStr, ok := os.LookupEnv(name); ok {
 		if val, err := time.ParseDuration(valStr); err == nil {
@@ -5,25 +5,152 @@ import (
 )
 
 type ServerConfig struct {
-	Port            string
-	LogLevel        string
-	ReadTimeout     time.Duration
-	WriteTimeout    time.Duration
-	IdleTimeout     time.Duration
-	ShutdownTimeout time.Duration
-	DBDriver        string
-	DBConnectionStr string
+	Port              string
+	LogLevel          string
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration
+	DBDriver          string // "postgres", "mysql", or "sqlite" in cgo builds
+	DBConnectionStr   string
+	DBAutoMigrate     bool // apply db/migrations on startup, see db.Migrate
+	AutocertEnabled   bool
+	AutocertEmail     string
+	AutocertDir       string
+	TLSPort           string
+	GRPCPort          string // serve the gRPC API on this port when set
+	AdminAPIKeys      []string
+	TrustedProxies    []string // addresses or CIDRs of the proxies whose X-Forwarded-For and X-Real-IP are believed
+	PublicCORSOrigins []string
+	AdminCORSOrigins  []string
+	SwaggerUIEnabled  bool // serve Swagger UI of /openapi.json at /v1/docs
+	WebUIEnabled      bool // serve the web UI at /ui, see api/webui
+
+	ConcurrencyLimitEnabled  bool
+	ConcurrencyInitialLimit  int
+	ConcurrencyMinLimit      int
+	ConcurrencyMaxLimit      int
+	ConcurrencyLatencyTarget time.Duration
+
+	DBReadMaxConns   int
+	DBWriteMaxConns  int
+	ReadLaneLimit    int
+	ReadLaneQueue    int
+	WriteLaneLimit   int
+	WriteLaneQueue   int
+	LaneQueueTimeout time.Duration
+
+	OPAURL        string
+	OPAPolicyPath string
+	OPATimeout    time.Duration
+	OPAFailOpen   bool
+
+	// OIDCIssuer lets callers of the admin API authenticate with the JWTs of this OpenID Connect
+	// issuer as well as with API keys, see api/oidc. Tokens must be issued for OIDCAudience,
+	// which is required with an issuer. The JWKS is discovered from the issuer unless
+	// OIDCJWKSURL is set.
+	OIDCIssuer       string
+	OIDCAudience     string
+	OIDCJWKSURL      string
+	OIDCJWKSCacheTTL time.Duration
+	// OIDCRoleClaim names the claim holding a token's roles, dotted for nested claims, e.g.
+	// "realm_access.roles". OIDCRoleMap maps claim values to roles; without it values are taken
+	// as they are, with it values it doesn't map are ignored. Roles set through /v1/subjectRoles
+	// take precedence over the claim.
+	OIDCRoleClaim string
+	OIDCRoleMap   map[string]string
+	// OIDCProjectClaim, when set, names the claim listing the hosts a token may work on, "*" for
+	// all of them. Tokens without it can't work on any.
+	OIDCProjectClaim string
+
+	CaptchaProvider   string
+	CaptchaSecret     string
+	CaptchaEndpoints  []string
+	CaptchaTimeout    time.Duration
+	CaptchaDifficulty int
+	CaptchaTTL        time.Duration
+
+	// RedisURL enables the Redis cache of link lookups, see cache.NewRedis. Without it,
+	// LinkCacheSize enables an in-process cache of that many lookups instead, see cache.LRU.
+	RedisURL      string
+	LinkCacheSize int
+	LinkCacheTTL  time.Duration
+
+	// Rate limits of POST /shortLinks and POST /exchangeShortLink per API key, or per client IP
+	// without one. They are kept in Redis with REDIS_URL, in memory otherwise.
+	RateLimitPerMinute int
+	RateLimitBurst     int
+	RateLimitOverrides map[string]string // API key fingerprint -> per minute, 0 for unlimited
+
+	// How long the response of a POST /shortLinks sent with an Idempotency-Key is replayed.
+	IdempotencyKeyTTL time.Duration
 }
 
 func NewServerConfig() *ServerConfig {
 	return &ServerConfig{
-		Port:            getEnv("PORT", "9010"),
-		LogLevel:        getEnv("LOG_LEVEL", "info"),
-		DBDriver:        getEnv("DB_DRIVER", "postgres"),
-		DBConnectionStr: getEnv("DATABASE_URL", ""),
-		ReadTimeout:     getEnvAsDuration("SERVER_READ_TIMEOUT", 15*time.Second),
-		WriteTimeout:    getEnvAsDuration("SERVER_WRITE_TIMEOUT", 15*time.Second),
-		IdleTimeout:     getEnvAsDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
-		ShutdownTimeout: getEnvAsDuration("SERVER_SHUTDOWN_TIMEOUT", 10*time.Second),
+		Port:              getEnv("PORT", "9010"),
+		LogLevel:          getEnv("LOG_LEVEL", "info"),
+		DBDriver:          getEnv("DB_DRIVER", "postgres"),
+		DBConnectionStr:   getEnv("DATABASE_URL", ""),
+		DBAutoMigrate:     getEnvAsBool("DB_AUTO_MIGRATE", false),
+		ReadTimeout:       getEnvAsDuration("SERVER_READ_TIMEOUT", 15*time.Second),
+		WriteTimeout:      getEnvAsDuration("SERVER_WRITE_TIMEOUT", 15*time.Second),
+		IdleTimeout:       getEnvAsDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
+		ShutdownTimeout:   getEnvAsDuration("SERVER_SHUTDOWN_TIMEOUT", 10*time.Second),
+		AutocertEnabled:   getEnvAsBool("AUTOCERT_ENABLED", false),
+		AutocertEmail:     getEnv("AUTOCERT_EMAIL", ""),
+		AutocertDir:       getEnv("AUTOCERT_DIR", "certs"),
+		TLSPort:           getEnv("TLS_PORT", "443"),
+		GRPCPort:          getEnv("GRPC_PORT", ""),
+		AdminAPIKeys:      getEnvAsSlice("ADMIN_API_KEYS", []string{}),
+		TrustedProxies:    getEnvAsSlice("TRUSTED_PROXIES", []string{}),
+		PublicCORSOrigins: getEnvAsSlice("PUBLIC_CORS_ORIGINS", []string{"*"}),
+		AdminCORSOrigins:  getEnvAsSlice("ADMIN_CORS_ORIGINS", []string{}),
+		SwaggerUIEnabled:  getEnvAsBool("SWAGGER_UI_ENABLED", false),
+		WebUIEnabled:      getEnvAsBool("WEB_UI_ENABLED", false),
+
+		ConcurrencyLimitEnabled:  getEnvAsBool("CONCURRENCY_LIMIT_ENABLED", false),
+		ConcurrencyInitialLimit:  getEnvAsInt("CONCURRENCY_INITIAL_LIMIT", 50),
+		ConcurrencyMinLimit:      getEnvAsInt("CONCURRENCY_MIN_LIMIT", 10),
+		ConcurrencyMaxLimit:      getEnvAsInt("CONCURRENCY_MAX_LIMIT", 500),
+		ConcurrencyLatencyTarget: getEnvAsDuration("CONCURRENCY_LATENCY_TARGET", 200*time.Millisecond),
+
+		DBReadMaxConns:   getEnvAsInt("DB_READ_MAX_CONNS", 0),
+		DBWriteMaxConns:  getEnvAsInt("DB_WRITE_MAX_CONNS", 10),
+		ReadLaneLimit:    getEnvAsInt("READ_LANE_LIMIT", 0),
+		ReadLaneQueue:    getEnvAsInt("READ_LANE_QUEUE", 0),
+		WriteLaneLimit:   getEnvAsInt("WRITE_LANE_LIMIT", 10),
+		WriteLaneQueue:   getEnvAsInt("WRITE_LANE_QUEUE", 100),
+		LaneQueueTimeout: getEnvAsDuration("LANE_QUEUE_TIMEOUT", 5*time.Second),
+
+		OPAURL:        getEnv("OPA_URL", ""),
+		OPAPolicyPath: getEnv("OPA_POLICY_PATH", "durablelinks/allow"),
+		OPATimeout:    getEnvAsDuration("OPA_TIMEOUT", 500*time.Millisecond),
+		OPAFailOpen:   getEnvAsBool("OPA_FAIL_OPEN", false),
+
+		OIDCIssuer:       getEnv("OIDC_ISSUER", ""),
+		OIDCAudience:     getEnv("OIDC_AUDIENCE", ""),
+		OIDCJWKSURL:      getEnv("OIDC_JWKS_URL", ""),
+		OIDCJWKSCacheTTL: getEnvAsDuration("OIDC_JWKS_CACHE_TTL", time.Hour),
+		OIDCRoleClaim:    getEnv("OIDC_ROLE_CLAIM", "role"),
+		OIDCRoleMap:      getEnvAsMap("OIDC_ROLE_MAP"),
+		OIDCProjectClaim: getEnv("OIDC_PROJECT_CLAIM", ""),
+
+		CaptchaProvider:   getEnv("CAPTCHA_PROVIDER", "none"),
+		CaptchaSecret:     getEnv("CAPTCHA_SECRET", ""),
+		CaptchaEndpoints:  getEnvAsSlice("CAPTCHA_ENDPOINTS", []string{"/v1/anonymous/shortLinks"}),
+		CaptchaTimeout:    getEnvAsDuration("CAPTCHA_TIMEOUT", 2*time.Second),
+		CaptchaDifficulty: getEnvAsInt("CAPTCHA_POW_DIFFICULTY", 20),
+		CaptchaTTL:        getEnvAsDuration("CAPTCHA_POW_TTL", 5*time.Minute),
+
+		RedisURL:      getEnv("REDIS_URL", ""),
+		LinkCacheSize: getEnvAsInt("LINK_CACHE_SIZE", 0),
+		LinkCacheTTL:  getEnvAsDuration("LINK_CACHE_TTL", 10*time.Minute),
+
+		RateLimitPerMinute: getEnvAsInt("RATE_LIMIT_PER_MINUTE", 0),
+		RateLimitBurst:     getEnvAsInt("RATE_LIMIT_BURST", 0),
+		RateLimitOverrides: getEnvAsMap("RATE_LIMIT_OVERRIDES"),
+
+		IdempotencyKeyTTL: getEnvAsDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
 	}
 }
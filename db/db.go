@@ -2,27 +2,107 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"durable-links-generator/config"
+	"durable-links-generator/db/mysql"
+	"durable-links-generator/db/sqlite"
 
 	"github.com/rs/zerolog/log"
 )
 
+// DB holds two connection pools to the same database. The embedded pool serves the read lane
+// (exchange and redirect traffic), Write serves link creation and the admin API, so a bulk job
+// can never take all connections away from redirects.
 type DB struct {
 	*sql.DB
+	Write   *sql.DB
+	Dialect Dialect
 }
 
 func New(cfg *config.Config) (*DB, error) {
-	db, err := sql.Open(cfg.Server.DBDriver, cfg.Server.DBConnectionStr)
+	dialect, err := DialectOf(cfg.Server.DBDriver)
+	if err != nil {
+		return nil, err
+	}
+	if dialect == SQLite {
+		return newSQLite(cfg)
+	}
+
+	readDB, err := open(cfg, dialect, cfg.Server.DBReadMaxConns)
+	if err != nil {
+		return nil, err
+	}
+
+	writeDB, err := open(cfg, dialect, cfg.Server.DBWriteMaxConns)
+	if err != nil {
+		readDB.Close()
+		return nil, err
+	}
+
+	database := &DB{DB: readDB, Write: writeDB, Dialect: dialect}
+	if dialect == MySQL {
+		if err := createMySQLSchema(writeDB); err != nil {
+			database.Close()
+			return nil, err
+		}
+	}
+
+	log.Info().Msg("Successfully connected to database")
+	return database, nil
+}
+
+// createMySQLSchema creates the tables of a MySQL database that doesn't have them yet, as
+// db/migrations does for Postgres.
+func createMySQLSchema(db *sql.DB) error {
+	for _, stmt := range mysql.SchemaStatements() {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to create schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// newSQLite opens a SQLite database and creates its schema. SQLite allows a single writer, so
+// both lanes share one connection; DATABASE_URL=:memory: gives a database that lasts as long
+// as the process.
+func newSQLite(cfg *config.Config) (*DB, error) {
+	db, err := sql.Open(sqlite.DriverName, cfg.Server.DBConnectionStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqlite.Schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	log.Info().Str("database", cfg.Server.DBConnectionStr).Msg("Opened SQLite database")
+	return &DB{DB: db, Write: db, Dialect: SQLite}, nil
+}
+
+func open(cfg *config.Config, dialect Dialect, maxConns int) (*sql.DB, error) {
+	var db *sql.DB
+	var err error
+	if dialect == MySQL {
+		db, err = mysql.Open(cfg.Server.DBConnectionStr)
+	} else {
+		db, err = sql.Open(cfg.Server.DBDriver, cfg.Server.DBConnectionStr)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	db.SetMaxOpenConns(maxConns)
 
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	return db, nil
+}
 
-	log.Info().Msg("Successfully connected to database")
-	return &DB{DB: db}, nil
+func (d *DB) Close() error {
+	return errors.Join(d.DB.Close(), d.Write.Close())
 }
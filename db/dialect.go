@@ -0,0 +1,35 @@
+package db
+
+import "fmt"
+
+// Dialect is the SQL flavour of the database DB_DRIVER selects.
+type Dialect int
+
+const (
+	Postgres Dialect = iota
+	SQLite
+	MySQL
+)
+
+// DialectOf returns the dialect of the database/sql driver named driver.
+func DialectOf(driver string) (Dialect, error) {
+	switch driver {
+	case "postgres":
+		return Postgres, nil
+	case "sqlite", "sqlite3":
+		return SQLite, nil
+	case "mysql":
+		return MySQL, nil
+	}
+	return 0, fmt.Errorf("unsupported DB_DRIVER %q", driver)
+}
+
+func (d Dialect) String() string {
+	switch d {
+	case SQLite:
+		return "sqlite"
+	case MySQL:
+		return "mysql"
+	}
+	return "postgres"
+}
@@ -0,0 +1,30 @@
+package db
+
+import "context"
+
+// Lane is the traffic class a request belongs to, deciding which connection pool serves it.
+type Lane int
+
+const (
+	LaneRead Lane = iota
+	LaneWrite
+)
+
+func (l Lane) String() string {
+	if l == LaneWrite {
+		return "write"
+	}
+	return "read"
+}
+
+type laneKey struct{}
+
+func WithLane(ctx context.Context, lane Lane) context.Context {
+	return context.WithValue(ctx, laneKey{}, lane)
+}
+
+// LaneFromContext returns the lane set on ctx, defaulting to the read lane.
+func LaneFromContext(ctx context.Context) Lane {
+	lane, _ := ctx.Value(laneKey{}).(Lane)
+	return lane
+}
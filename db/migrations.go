@@ -0,0 +1,171 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+//go:embed migrations/*.sql
+var Migrations embed.FS
+
+// Migration is one of the SQL files of Migrations.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// LoadMigrations returns the migrations shipped with this build, oldest first.
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(Migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	for _, entry := range entries {
+		prefix, _, _ := strings.Cut(entry.Name(), "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			continue
+		}
+		content, err := fs.ReadFile(Migrations, path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    strings.TrimSuffix(entry.Name(), ".sql"),
+			SQL:     string(content),
+		})
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return a.Version - b.Version })
+	return migrations, nil
+}
+
+// LatestMigrationVersion returns the number of the newest migration shipped with this build.
+func LatestMigrationVersion() int {
+	migrations, err := LoadMigrations()
+	if err != nil || len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// Arbitrary key of the Postgres advisory lock held while migrating, so instances starting
+// together run each migration once.
+const migrationLockKey = 7_165_931_820
+
+const createMigrationsTable = `
+    CREATE TABLE IF NOT EXISTS schema_migrations (
+        version    INTEGER     PRIMARY KEY,
+        applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    )`
+
+// Migrate applies the migrations the database hasn't run yet, oldest first, each in its own
+// transaction, and returns their versions. Applied versions are recorded in schema_migrations.
+// Databases migrated by hand before have no record of it, and run every migration once more,
+// which they take as all of them only create what's missing.
+//
+// SQLite and MySQL databases get the schema of the latest migration when opened, recorded in
+// their schema_migrations, so there is nothing to apply to them.
+func Migrate(ctx context.Context, database *DB) ([]int, error) {
+	if database.Dialect != Postgres {
+		return nil, nil
+	}
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	conn, err := database.Write.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return nil, fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+
+	if _, err := conn.ExecContext(ctx, createMigrationsTable); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	done, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []int
+	for _, migration := range migrations {
+		if done[migration.Version] {
+			continue
+		}
+		if err := applyMigration(ctx, conn, migration); err != nil {
+			return applied, fmt.Errorf("migration %s failed: %w", migration.Name, err)
+		}
+		applied = append(applied, migration.Version)
+	}
+	return applied, nil
+}
+
+func appliedMigrations(ctx context.Context, conn *sql.Conn) (map[int]bool, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	done := map[int]bool{}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		done[version] = true
+	}
+	return done, rows.Err()
+}
+
+func applyMigration(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.SQL); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, migration.Version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MigrationVersion returns the newest migration applied to the database, 0 when none is. SQLite
+// and MySQL databases record the migration their schema is as of when it is created.
+func MigrationVersion(ctx context.Context, database *DB) (int, error) {
+	var version int
+	err := database.Write.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "42P01" {
+		// Never migrated: schema_migrations doesn't exist yet.
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	return version, nil
+}
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS durable_links (
+    id                  BIGSERIAL PRIMARY KEY,
+    host                TEXT        NOT NULL,
+    path                TEXT        NOT NULL,
+    query_params        TEXT        NOT NULL,
+    is_unguessable_path BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (host, path)
+);
+
+-- Installs created before this schema was tracked may lack the timestamp.
+ALTER TABLE durable_links ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS durable_links_host_query_params_idx
+    ON durable_links (host, query_params)
+    WHERE is_unguessable_path = FALSE;
@@ -0,0 +1,12 @@
+-- Previous versions of a link's query params, written whenever a link is changed in place.
+CREATE TABLE IF NOT EXISTS link_revisions (
+    id           BIGSERIAL PRIMARY KEY,
+    host         TEXT        NOT NULL,
+    path         TEXT        NOT NULL,
+    query_params TEXT        NOT NULL,
+    reason       TEXT        NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS link_revisions_host_path_idx
+    ON link_revisions (host, path, created_at DESC);
@@ -0,0 +1,11 @@
+-- Additional paths resolving to an existing link.
+CREATE TABLE IF NOT EXISTS link_aliases (
+    host       TEXT        NOT NULL,
+    alias_path TEXT        NOT NULL,
+    path       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (host, alias_path),
+    FOREIGN KEY (host, path) REFERENCES durable_links (host, path) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS link_aliases_host_path_idx ON link_aliases (host, path);
@@ -0,0 +1,4 @@
+-- A superseded link points at the link that replaced it and, if redirect_to_successor is set,
+-- resolves to the successor's parameters.
+ALTER TABLE durable_links ADD COLUMN IF NOT EXISTS superseded_by TEXT;
+ALTER TABLE durable_links ADD COLUMN IF NOT EXISTS redirect_to_successor BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+-- Counter behind the "sequence" path generator.
+CREATE SEQUENCE IF NOT EXISTS durable_link_path_seq;
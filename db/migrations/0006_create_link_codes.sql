@@ -0,0 +1,11 @@
+-- Numeric companion codes resolving to a link through /c/{code}.
+CREATE TABLE IF NOT EXISTS link_codes (
+    host       TEXT        NOT NULL,
+    code       TEXT        NOT NULL,
+    path       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (host, code),
+    FOREIGN KEY (host, path) REFERENCES durable_links (host, path) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS link_codes_host_path_idx ON link_codes (host, path);
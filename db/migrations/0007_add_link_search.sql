@@ -0,0 +1,16 @@
+-- Decoded destination, social title and description, deep link route and path of each link,
+-- written by the application and searched by GET /v1/search.
+ALTER TABLE durable_links ADD COLUMN IF NOT EXISTS search_text TEXT;
+
+-- Links created earlier get their raw query params until they are next changed, which still
+-- matches most words.
+UPDATE durable_links
+   SET search_text = path || ' ' || replace(query_params, '+', ' ')
+ WHERE search_text IS NULL;
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS durable_links_search_tsv_idx
+    ON durable_links USING GIN (to_tsvector('simple', search_text));
+CREATE INDEX IF NOT EXISTS durable_links_search_trgm_idx
+    ON durable_links USING GIN (search_text gin_trgm_ops);
@@ -0,0 +1,10 @@
+-- Named search filters, owned by the fingerprint of the API key that saved them.
+CREATE TABLE IF NOT EXISTS saved_searches (
+    owner      TEXT        NOT NULL,
+    name       TEXT        NOT NULL,
+    query      TEXT        NOT NULL,
+    host       TEXT        NOT NULL DEFAULT '',
+    sort       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (owner, name)
+);
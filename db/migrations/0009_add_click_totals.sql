@@ -0,0 +1,7 @@
+-- Click totals maintained by the click aggregator, so stale and popular links can be found
+-- without scanning click events.
+ALTER TABLE durable_links ADD COLUMN IF NOT EXISTS total_clicks BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE durable_links ADD COLUMN IF NOT EXISTS last_clicked_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS durable_links_host_last_clicked_idx
+    ON durable_links (host, last_clicked_at);
@@ -0,0 +1,6 @@
+-- Archived links no longer resolve and are not handed out again for the same parameters.
+ALTER TABLE durable_links ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS durable_links_host_last_activity_idx
+    ON durable_links (host, (COALESCE(last_clicked_at, created_at)))
+    WHERE archived_at IS NULL;
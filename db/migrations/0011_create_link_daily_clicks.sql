@@ -0,0 +1,12 @@
+-- Clicks per link and UTC day, written by the click aggregator next to the totals on
+-- durable_links. Backs the click windows of the dashboard summary.
+CREATE TABLE IF NOT EXISTS link_daily_clicks (
+    host   TEXT   NOT NULL,
+    path   TEXT   NOT NULL,
+    day    DATE   NOT NULL,
+    clicks BIGINT NOT NULL,
+    PRIMARY KEY (host, path, day),
+    FOREIGN KEY (host, path) REFERENCES durable_links (host, path) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS link_daily_clicks_host_day_idx ON link_daily_clicks (host, day);
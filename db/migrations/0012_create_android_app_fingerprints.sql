@@ -0,0 +1,9 @@
+-- Signing certificate fingerprints of the Android apps that may open a host's links, served as
+-- the host's /.well-known/assetlinks.json.
+CREATE TABLE IF NOT EXISTS android_app_fingerprints (
+    host               TEXT        NOT NULL,
+    package_name       TEXT        NOT NULL,
+    sha256_fingerprint TEXT        NOT NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (host, package_name, sha256_fingerprint)
+);
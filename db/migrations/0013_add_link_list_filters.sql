@@ -0,0 +1,16 @@
+-- Host of a link's destination, its "link" param, which GET /shortLinks can filter on. Params are
+-- stored form encoded, so the scheme separator reads "%3A%2F%2F" and a port starts at "%3A".
+CREATE OR REPLACE FUNCTION link_destination_host(query_params TEXT) RETURNS TEXT
+    LANGUAGE SQL IMMUTABLE PARALLEL SAFE
+    AS $$
+        SELECT lower(substring(query_params FROM '(?:^|&)link=[A-Za-z][A-Za-z0-9+.-]*%3A%2F%2F([^%&/?#]+)'))
+    $$;
+
+CREATE INDEX IF NOT EXISTS durable_links_host_destination_idx
+    ON durable_links (host, link_destination_host(query_params))
+    WHERE archived_at IS NULL;
+
+-- Backs the default newest first order of GET /shortLinks and its creation date range.
+CREATE INDEX IF NOT EXISTS durable_links_host_created_idx
+    ON durable_links (host, created_at, id)
+    WHERE archived_at IS NULL;
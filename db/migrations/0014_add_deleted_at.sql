@@ -0,0 +1,3 @@
+-- Deleted links answer 410 Gone until they are restored or purged. They keep their path, so it
+-- isn't handed out to another link in the meantime.
+ALTER TABLE durable_links ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
@@ -0,0 +1,10 @@
+-- Link settings of the short link domains managed through /v1/domains. Hosts without a row use
+-- ALLOWED_DOMAINS, DEFAULT_ANDROID_PACKAGE_NAME and DEFAULT_IOS_STORE_ID.
+CREATE TABLE IF NOT EXISTS domains (
+    host                         TEXT        PRIMARY KEY,
+    allowed_domains              TEXT[]      NOT NULL DEFAULT '{}',
+    default_android_package_name TEXT        NOT NULL DEFAULT '',
+    default_ios_store_id         TEXT        NOT NULL DEFAULT '',
+    created_at                   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at                   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
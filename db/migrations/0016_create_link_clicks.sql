@@ -0,0 +1,16 @@
+-- Every resolution of a short link, written in batches by clicks.Writer. Path is as requested,
+-- before following aliases and successors. IPs are only used for the country and never stored.
+CREATE TABLE IF NOT EXISTS link_clicks (
+    id         BIGSERIAL   PRIMARY KEY,
+    host       TEXT        NOT NULL,
+    path       TEXT        NOT NULL,
+    clicked_at TIMESTAMPTZ NOT NULL,
+    source     TEXT        NOT NULL,
+    platform   TEXT        NOT NULL DEFAULT '',
+    country    TEXT        NOT NULL DEFAULT '',
+    referrer   TEXT        NOT NULL DEFAULT '',
+    user_agent TEXT        NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS link_clicks_host_path_clicked_idx ON link_clicks (host, path, clicked_at);
+CREATE INDEX IF NOT EXISTS link_clicks_host_clicked_idx ON link_clicks (host, clicked_at);
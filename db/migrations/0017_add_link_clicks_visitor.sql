@@ -0,0 +1,3 @@
+-- Salted daily hash of the visitor's IP and user agent, for unique click counts. Empty for clicks
+-- without consent.
+ALTER TABLE link_clicks ADD COLUMN IF NOT EXISTS visitor TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,30 @@
+-- Endpoints notified of the link events of a short link domain, managed through
+-- /v1/domains/{domain}/webhooks. An empty events list subscribes to every link event;
+-- click_thresholds are the total click counts that raise link.click_threshold.
+CREATE TABLE IF NOT EXISTS webhooks (
+    id               BIGSERIAL   PRIMARY KEY,
+    host             TEXT        NOT NULL,
+    url              TEXT        NOT NULL,
+    secret           TEXT        NOT NULL,
+    events           TEXT[]      NOT NULL DEFAULT '{}',
+    click_thresholds BIGINT[]    NOT NULL DEFAULT '{}',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_host_idx ON webhooks (host);
+
+-- Every attempt to deliver an event to a webhook. status_code is 0 when no response came back.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id           BIGSERIAL   PRIMARY KEY,
+    webhook_id   BIGINT      NOT NULL,
+    event_id     TEXT        NOT NULL,
+    event_type   TEXT        NOT NULL,
+    payload      TEXT        NOT NULL,
+    attempt      INTEGER     NOT NULL,
+    status_code  INTEGER     NOT NULL DEFAULT 0,
+    error        TEXT        NOT NULL DEFAULT '',
+    duration_ms  BIGINT      NOT NULL DEFAULT 0,
+    delivered_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_idx ON webhook_deliveries (webhook_id, id);
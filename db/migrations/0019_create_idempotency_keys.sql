@@ -0,0 +1,14 @@
+-- Responses of POST /shortLinks requests sent with an Idempotency-Key, replayed to the caller
+-- (API key fingerprint or client IP) sending the key again until IDEMPOTENCY_KEY_TTL has passed.
+-- status_code is 0 while the first request is still running.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    caller          TEXT        NOT NULL,
+    idempotency_key TEXT        NOT NULL,
+    request_hash    TEXT        NOT NULL,
+    status_code     INTEGER     NOT NULL DEFAULT 0,
+    body            TEXT        NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (caller, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_created_idx ON idempotency_keys (created_at);
@@ -0,0 +1,10 @@
+-- Labels for organizing links, set on creation and through PATCH /shortLinks/{path}. They don't
+-- change where a link leads, so they live outside query_params and its reuse of existing links.
+ALTER TABLE durable_links ADD COLUMN IF NOT EXISTS name  TEXT   NOT NULL DEFAULT '';
+ALTER TABLE durable_links ADD COLUMN IF NOT EXISTS notes TEXT   NOT NULL DEFAULT '';
+ALTER TABLE durable_links ADD COLUMN IF NOT EXISTS tags  TEXT[] NOT NULL DEFAULT '{}';
+
+-- Backs the tag filter of GET /shortLinks.
+CREATE INDEX IF NOT EXISTS durable_links_tags_idx
+    ON durable_links USING GIN (tags)
+    WHERE archived_at IS NULL;
@@ -0,0 +1,21 @@
+-- Campaigns group the short links of a domain so their clicks are reported together, managed
+-- through /v1/domains/{domain}/campaigns. A link belongs to at most one campaign.
+CREATE TABLE IF NOT EXISTS campaigns (
+    host       TEXT        NOT NULL,
+    id         TEXT        NOT NULL,
+    name       TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (host, id)
+);
+
+CREATE TABLE IF NOT EXISTS campaign_links (
+    host        TEXT        NOT NULL,
+    path        TEXT        NOT NULL,
+    campaign_id TEXT        NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (host, path),
+    FOREIGN KEY (host, path) REFERENCES durable_links (host, path) ON DELETE CASCADE,
+    FOREIGN KEY (host, campaign_id) REFERENCES campaigns (host, id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS campaign_links_campaign_idx ON campaign_links (host, campaign_id);
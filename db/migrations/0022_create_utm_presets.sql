@@ -0,0 +1,13 @@
+-- Named UTM params of a short link domain, managed through /v1/domains/{domain}/utmPresets and
+-- applied to the links created with analyticsInfo.presetName.
+CREATE TABLE IF NOT EXISTS utm_presets (
+    host         TEXT        NOT NULL,
+    name         TEXT        NOT NULL,
+    utm_source   TEXT        NOT NULL DEFAULT '',
+    utm_medium   TEXT        NOT NULL DEFAULT '',
+    utm_campaign TEXT        NOT NULL DEFAULT '',
+    utm_term     TEXT        NOT NULL DEFAULT '',
+    utm_content  TEXT        NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (host, name)
+);
@@ -0,0 +1,11 @@
+-- Per-country destinations of a link, replacing its link param on redirects of visitors GeoIP
+-- places in the country.
+CREATE TABLE IF NOT EXISTS link_geo_destinations (
+    host       TEXT        NOT NULL,
+    path       TEXT        NOT NULL,
+    country    TEXT        NOT NULL,
+    link       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (host, path, country),
+    FOREIGN KEY (host, path) REFERENCES durable_links (host, path) ON DELETE CASCADE
+);
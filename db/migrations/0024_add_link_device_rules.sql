@@ -0,0 +1,3 @@
+-- Per-link targeting rules by OS and OS version, evaluated on redirects from the user agent. A
+-- JSON array of rules, the first matching one wins; NULL for links without rules.
+ALTER TABLE durable_links ADD COLUMN IF NOT EXISTS device_rules JSONB;
@@ -0,0 +1,14 @@
+-- Weighted destinations of a link split between visitors for A/B tests, replacing its link param
+-- on redirects, and the variant each click was sent to.
+CREATE TABLE IF NOT EXISTS link_variants (
+    host       TEXT        NOT NULL,
+    path       TEXT        NOT NULL,
+    name       TEXT        NOT NULL,
+    link       TEXT        NOT NULL,
+    weight     INTEGER     NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (host, path, name),
+    FOREIGN KEY (host, path) REFERENCES durable_links (host, path) ON DELETE CASCADE
+);
+
+ALTER TABLE link_clicks ADD COLUMN IF NOT EXISTS variant TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- Clicks counted against the maxClicks limit of a link, incremented one at a time on redirects
+-- so concurrent visitors can't go past the limit; total_clicks is only flushed in batches.
+ALTER TABLE durable_links ADD COLUMN IF NOT EXISTS use_count BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,17 @@
+-- API keys created through /v1/apiKeys, stored as the SHA-256 of the key, which is only shown
+-- once. id is the key's fingerprint, the same one rate limits and OPA see.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id         TEXT        PRIMARY KEY,
+    key_hash   TEXT        NOT NULL UNIQUE,
+    name       TEXT        NOT NULL,
+    role       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Roles of the subjects of OIDC tokens, managed through /v1/subjectRoles. They take precedence
+-- over the role claim of the tokens.
+CREATE TABLE IF NOT EXISTS subject_roles (
+    subject    TEXT        PRIMARY KEY,
+    role       TEXT        NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
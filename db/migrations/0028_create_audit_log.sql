@@ -0,0 +1,30 @@
+-- Who changed which link or domain config when, with JSON snapshots from before and after the
+-- change, listed by GET /auditlog. Rows can't be updated or deleted.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id           BIGSERIAL   PRIMARY KEY,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    actor        TEXT        NOT NULL DEFAULT '',
+    subject      TEXT        NOT NULL DEFAULT '',
+    role         TEXT        NOT NULL DEFAULT '',
+    client_ip    TEXT        NOT NULL DEFAULT '',
+    request_id   TEXT        NOT NULL DEFAULT '',
+    action       TEXT        NOT NULL,
+    host         TEXT        NOT NULL,
+    resource     TEXT        NOT NULL,
+    reason       TEXT        NOT NULL DEFAULT '',
+    before_state TEXT,
+    after_state  TEXT
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_host_idx ON audit_log (host, resource, id);
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
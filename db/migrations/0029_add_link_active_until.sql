@@ -0,0 +1,12 @@
+-- End of the activation window of a link, its "au" param, which GET /shortLinks can sort on as
+-- expiresAt. NULL for links that never expire. Params are stored form encoded, so the colons of
+-- the RFC 3339 time read "%3A".
+ALTER TABLE durable_links ADD COLUMN IF NOT EXISTS active_until TIMESTAMPTZ;
+
+UPDATE durable_links
+   SET active_until = replace(substring(query_params FROM '(?:^|&)au=([^&]*)'), '%3A', ':')::timestamptz
+ WHERE active_until IS NULL
+   AND query_params ~ '(^|&)au=[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}%3A[0-9]{2}%3A[0-9]{2}Z(&|$)';
+
+CREATE INDEX IF NOT EXISTS durable_links_host_active_until_idx
+    ON durable_links (host, active_until);
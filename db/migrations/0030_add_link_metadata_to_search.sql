@@ -0,0 +1,5 @@
+-- SearchLinks matches links by their name, notes and tags too, which search_text holds after the
+-- destination fields since links are written with them.
+UPDATE durable_links
+   SET search_text = concat_ws(' ', search_text, NULLIF(name, ''), NULLIF(notes, ''), NULLIF(array_to_string(tags, ' '), ''))
+ WHERE name <> '' OR notes <> '' OR cardinality(tags) > 0;
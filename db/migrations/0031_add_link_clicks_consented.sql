@@ -0,0 +1,9 @@
+-- Whether the visitor consented to analytics, see CONSENT_REQUIRED. Clicks without consent keep
+-- no referrer, user agent, visitor or country. Earlier clicks didn't record it, but those stored
+-- without consent are the ones missing all of referrer, user agent and visitor.
+ALTER TABLE link_clicks ADD COLUMN IF NOT EXISTS consented BOOLEAN NOT NULL DEFAULT TRUE;
+
+UPDATE link_clicks
+   SET consented = FALSE
+ WHERE consented
+   AND visitor = '' AND referrer = '' AND user_agent = '';
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"durable-links-generator/db/mysql"
+	"durable-links-generator/db/sqlite"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatestMigrationVersion(t *testing.T) {
+	assert.GreaterOrEqual(t, LatestMigrationVersion(), 4)
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := LoadMigrations()
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, migrations) {
+		assert.Equal(t, 1, migrations[0].Version)
+		assert.Equal(t, "0001_create_durable_links", migrations[0].Name)
+		assert.Equal(t, LatestMigrationVersion(), migrations[len(migrations)-1].Version)
+	}
+	for i := 1; i < len(migrations); i++ {
+		assert.Less(t, migrations[i-1].Version, migrations[i].Version)
+	}
+}
+
+func TestSchemaVersion(t *testing.T) {
+	latest := LatestMigrationVersion()
+	for name, schema := range map[string]string{"sqlite": sqlite.Schema, "mysql": mysql.Schema} {
+		assert.Contains(t, schema, fmt.Sprintf("as of %04d", latest), name)
+		assert.Contains(t, schema, fmt.Sprintf("INTO schema_migrations (version) VALUES (%d);", latest), name)
+	}
+}
+
+func TestMigrationVersion(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer conn.Close()
+
+	mock.ExpectQuery(`SELECT COALESCE\(MAX\(version\), 0\) FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(26))
+
+	version, err := MigrationVersion(context.Background(), &DB{DB: conn, Write: conn, Dialect: MySQL})
+	assert.NoError(t, err)
+	assert.Equal(t, 26, version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrate(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer conn.Close()
+
+	latest := LatestMigrationVersion()
+	applied := sqlmock.NewRows([]string{"version"})
+	for version := 1; version < latest; version++ {
+		applied.AddRow(version)
+	}
+	mock.ExpectExec(`SELECT pg_advisory_lock\(\$1\)`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version FROM schema_migrations`).WillReturnRows(applied)
+	mock.ExpectBegin()
+	mock.ExpectExec(`ALTER TABLE link_clicks ADD COLUMN IF NOT EXISTS consented`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO schema_migrations \(version\) VALUES \(\$1\)`).
+		WithArgs(latest).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	versions, err := Migrate(context.Background(), &DB{DB: conn, Write: conn, Dialect: Postgres})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{latest}, versions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrate_Failure(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer conn.Close()
+
+	mock.ExpectExec(`SELECT pg_advisory_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version FROM schema_migrations`).WillReturnRows(sqlmock.NewRows([]string{"version"}))
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS durable_links`).WillReturnError(errors.New("permission denied"))
+	mock.ExpectRollback()
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	versions, err := Migrate(context.Background(), &DB{DB: conn, Write: conn, Dialect: Postgres})
+	assert.ErrorContains(t, err, "migration 0001_create_durable_links failed: permission denied")
+	assert.Empty(t, versions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,183 @@
+// Package mysql opens MySQL and MariaDB databases for DB_DRIVER=mysql. Its connections take the
+// $N placeholders the queries are written with, work in UTC and report the rows an UPDATE
+// matched rather than changed, so RowsAffected means what it does on Postgres.
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	_ "embed"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Schema creates the tables the repositories use, if they don't exist yet.
+//
+//go:embed schema.sql
+var Schema string
+
+// SchemaStatements splits Schema into its statements, as the driver runs one per Exec.
+func SchemaStatements() []string {
+	var stmts []string
+	for _, stmt := range strings.Split(Schema, ";\n") {
+		if stmt = strings.TrimSpace(stmt); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts
+}
+
+// Open returns a pool of connections to the database at dsn, a go-sql-driver/mysql DSN such as
+// user:password@tcp(localhost:3306)/links.
+func Open(dsn string) (*sql.DB, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ParseTime = true
+	cfg.Loc = time.UTC
+	cfg.ClientFoundRows = true
+	if cfg.Params == nil {
+		cfg.Params = map[string]string{}
+	}
+	cfg.Params["time_zone"] = "'+00:00'"
+
+	c, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(&connector{Connector: c}), nil
+}
+
+// IsUniqueViolation reports whether err is a MySQL duplicate key error.
+func IsUniqueViolation(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
+
+var placeholderPattern = regexp.MustCompile(`\$(\d+)`)
+
+// Rebind turns the $N placeholders of Postgres into MySQL's ?, which are bound in order. It
+// returns, for each ?, the index of the arg it takes, and the number of args the query takes.
+func Rebind(query string) (string, []int, int) {
+	var order []int
+	numInput := 0
+	rebound := placeholderPattern.ReplaceAllStringFunc(query, func(placeholder string) string {
+		n, _ := strconv.Atoi(placeholder[1:])
+		order = append(order, n-1)
+		numInput = max(numInput, n)
+		return "?"
+	})
+	return rebound, order, numInput
+}
+
+// reorder lists args in the order of the placeholders they are bound to.
+func reorder(args []driver.NamedValue, order []int) ([]driver.NamedValue, error) {
+	bound := make([]driver.NamedValue, len(order))
+	for i, n := range order {
+		if n < 0 || n >= len(args) {
+			return nil, fmt.Errorf("missing arg $%d", n+1)
+		}
+		bound[i] = driver.NamedValue{Ordinal: i + 1, Value: args[n].Value}
+	}
+	return bound, nil
+}
+
+type connector struct {
+	driver.Connector
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dc, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mc, ok := dc.(mysqlConn)
+	if !ok {
+		dc.Close()
+		return nil, fmt.Errorf("unexpected mysql connection %T", dc)
+	}
+	return &conn{mysqlConn: mc}, nil
+}
+
+// mysqlConn is what database/sql uses of a go-sql-driver/mysql connection.
+type mysqlConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+	driver.NamedValueChecker
+}
+
+// conn rebinds the placeholders of every query.
+type conn struct {
+	mysqlConn
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	rebound, order, numInput := Rebind(query)
+	s, err := c.mysqlConn.PrepareContext(ctx, rebound)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{Stmt: s, order: order, numInput: numInput}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	rebound, order, _ := Rebind(query)
+	bound, err := reorder(args, order)
+	if err != nil {
+		return nil, err
+	}
+	return c.mysqlConn.ExecContext(ctx, rebound, bound)
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rebound, order, _ := Rebind(query)
+	bound, err := reorder(args, order)
+	if err != nil {
+		return nil, err
+	}
+	return c.mysqlConn.QueryContext(ctx, rebound, bound)
+}
+
+type stmt struct {
+	driver.Stmt
+	order    []int
+	numInput int
+}
+
+func (s *stmt) NumInput() int {
+	return s.numInput
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	bound, err := reorder(args, s.order)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.(driver.StmtExecContext).ExecContext(ctx, bound)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	bound, err := reorder(args, s.order)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.(driver.StmtQueryContext).QueryContext(ctx, bound)
+}
@@ -0,0 +1,44 @@
+package mysql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebind(t *testing.T) {
+	q, order, numInput := Rebind("SELECT $2 WHERE host = $1 AND (path = $2 OR alias = $2) LIMIT $3")
+	assert.Equal(t, "SELECT ? WHERE host = ? AND (path = ? OR alias = ?) LIMIT ?", q)
+	assert.Equal(t, []int{1, 0, 1, 1, 2}, order)
+	assert.Equal(t, 3, numInput)
+}
+
+func TestReorder(t *testing.T) {
+	args := []driver.NamedValue{{Ordinal: 1, Value: "a"}, {Ordinal: 2, Value: "b"}}
+	bound, err := reorder(args, []int{1, 0, 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []driver.NamedValue{{Ordinal: 1, Value: "b"}, {Ordinal: 2, Value: "a"}, {Ordinal: 3, Value: "b"}}, bound)
+
+	_, err = reorder(args, []int{2})
+	assert.EqualError(t, err, "missing arg $3")
+}
+
+func TestSchemaStatements(t *testing.T) {
+	stmts := SchemaStatements()
+	assert.NotEmpty(t, stmts)
+	for _, stmt := range stmts {
+		assert.NotContains(t, stmt, ";\n")
+		assert.False(t, strings.HasSuffix(stmt, ";"), stmt)
+	}
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	assert.True(t, IsUniqueViolation(fmt.Errorf("insert: %w", &mysql.MySQLError{Number: 1062})))
+	assert.False(t, IsUniqueViolation(&mysql.MySQLError{Number: 1452}))
+	assert.False(t, IsUniqueViolation(errors.New("duplicate")))
+}
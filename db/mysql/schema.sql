@@ -0,0 +1,260 @@
+-- The schema of db/migrations for MySQL 8 and MariaDB 10.6, as of 0031. Text compares as
+-- binary, as in Postgres, so paths and codes are case sensitive; search_text alone uses a
+-- case insensitive collation for LIKE and FULLTEXT search. Times are stored in UTC, the time
+-- zone of every connection.
+CREATE TABLE IF NOT EXISTS durable_links (
+    id                    BIGINT       NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    host                  VARCHAR(255) NOT NULL,
+    path                  VARCHAR(255) NOT NULL,
+    query_params          TEXT         NOT NULL,
+    is_unguessable_path   BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at            DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    superseded_by         VARCHAR(255),
+    redirect_to_successor BOOLEAN      NOT NULL DEFAULT FALSE,
+    search_text           TEXT         COLLATE utf8mb4_unicode_ci,
+    total_clicks          BIGINT       NOT NULL DEFAULT 0,
+    last_clicked_at       DATETIME(6),
+    archived_at           DATETIME(6),
+    deleted_at            DATETIME(6),
+    name                  VARCHAR(255) NOT NULL DEFAULT '',
+    notes                 TEXT         NOT NULL DEFAULT (''),
+    -- tags holds a Postgres array literal, as written and read by pq.Array.
+    tags                  TEXT         NOT NULL DEFAULT ('{}'),
+    device_rules          JSON,
+    use_count             BIGINT       NOT NULL DEFAULT 0,
+    active_until          DATETIME(6),
+    UNIQUE KEY durable_links_host_path_key (host, path),
+    KEY durable_links_host_query_params_idx (host, query_params(255)),
+    KEY durable_links_host_last_clicked_idx (host, last_clicked_at),
+    KEY durable_links_host_active_until_idx (host, active_until),
+    KEY durable_links_host_created_idx (host, created_at, id),
+    FULLTEXT KEY durable_links_search_idx (search_text)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+CREATE TABLE IF NOT EXISTS link_revisions (
+    id           BIGINT       NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    host         VARCHAR(255) NOT NULL,
+    path         VARCHAR(255) NOT NULL,
+    query_params TEXT         NOT NULL,
+    reason       VARCHAR(255) NOT NULL,
+    created_at   DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    KEY link_revisions_host_path_idx (host, path, created_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+CREATE TABLE IF NOT EXISTS link_aliases (
+    host       VARCHAR(255) NOT NULL,
+    alias_path VARCHAR(255) NOT NULL,
+    path       VARCHAR(255) NOT NULL,
+    created_at DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (host, alias_path),
+    KEY link_aliases_host_path_idx (host, path),
+    FOREIGN KEY (host, path) REFERENCES durable_links (host, path) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+-- Stands in for the durable_link_path_seq sequence: a single row holding the last value.
+CREATE TABLE IF NOT EXISTS durable_link_path_seq (
+    id    TINYINT NOT NULL PRIMARY KEY,
+    value BIGINT  NOT NULL
+) ENGINE = InnoDB;
+
+INSERT IGNORE INTO durable_link_path_seq (id, value) VALUES (1, 0);
+
+CREATE TABLE IF NOT EXISTS link_codes (
+    host       VARCHAR(255) NOT NULL,
+    code       VARCHAR(255) NOT NULL,
+    path       VARCHAR(255) NOT NULL,
+    created_at DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (host, code),
+    KEY link_codes_host_path_idx (host, path),
+    FOREIGN KEY (host, path) REFERENCES durable_links (host, path) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+CREATE TABLE IF NOT EXISTS saved_searches (
+    owner      VARCHAR(255) NOT NULL,
+    name       VARCHAR(255) NOT NULL,
+    query      TEXT         NOT NULL,
+    host       VARCHAR(255) NOT NULL DEFAULT '',
+    sort       VARCHAR(32)  NOT NULL,
+    created_at DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (owner, name)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+CREATE TABLE IF NOT EXISTS link_daily_clicks (
+    host   VARCHAR(255) NOT NULL,
+    path   VARCHAR(255) NOT NULL,
+    day    DATE         NOT NULL,
+    clicks BIGINT       NOT NULL,
+    PRIMARY KEY (host, path, day),
+    KEY link_daily_clicks_host_day_idx (host, day),
+    FOREIGN KEY (host, path) REFERENCES durable_links (host, path) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+CREATE TABLE IF NOT EXISTS android_app_fingerprints (
+    host               VARCHAR(255) NOT NULL,
+    package_name       VARCHAR(255) NOT NULL,
+    sha256_fingerprint VARCHAR(255) NOT NULL,
+    created_at         DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (host, package_name, sha256_fingerprint)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+-- allowed_domains holds a Postgres array literal, as written and read by pq.Array.
+CREATE TABLE IF NOT EXISTS domains (
+    host                         VARCHAR(255) NOT NULL PRIMARY KEY,
+    allowed_domains              TEXT         NOT NULL,
+    default_android_package_name VARCHAR(255) NOT NULL DEFAULT '',
+    default_ios_store_id         VARCHAR(255) NOT NULL DEFAULT '',
+    created_at                   DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at                   DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+CREATE TABLE IF NOT EXISTS link_clicks (
+    id         BIGINT       NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    host       VARCHAR(255) NOT NULL,
+    path       VARCHAR(255) NOT NULL,
+    clicked_at DATETIME(6)  NOT NULL,
+    source     VARCHAR(32)  NOT NULL,
+    platform   VARCHAR(32)  NOT NULL DEFAULT '',
+    country    VARCHAR(8)   NOT NULL DEFAULT '',
+    referrer   TEXT         NOT NULL,
+    user_agent TEXT         NOT NULL,
+    visitor    VARCHAR(255) NOT NULL DEFAULT '',
+    variant    VARCHAR(64)  NOT NULL DEFAULT '',
+    consented  BOOLEAN      NOT NULL DEFAULT TRUE,
+    KEY link_clicks_host_path_clicked_idx (host, path, clicked_at),
+    KEY link_clicks_host_clicked_idx (host, clicked_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+-- events and click_thresholds hold Postgres array literals, as written and read by pq.Array.
+CREATE TABLE IF NOT EXISTS webhooks (
+    id               BIGINT       NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    host             VARCHAR(255) NOT NULL,
+    url              TEXT         NOT NULL,
+    secret           VARCHAR(255) NOT NULL,
+    events           TEXT         NOT NULL,
+    click_thresholds TEXT         NOT NULL,
+    created_at       DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    KEY webhooks_host_idx (host)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id           BIGINT       NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    webhook_id   BIGINT       NOT NULL,
+    event_id     VARCHAR(64)  NOT NULL,
+    event_type   VARCHAR(64)  NOT NULL,
+    payload      TEXT         NOT NULL,
+    attempt      INT          NOT NULL,
+    status_code  INT          NOT NULL DEFAULT 0,
+    error        TEXT         NOT NULL,
+    duration_ms  BIGINT       NOT NULL DEFAULT 0,
+    delivered_at DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    KEY webhook_deliveries_webhook_idx (webhook_id, id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    caller          VARCHAR(255) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash    VARCHAR(64)  NOT NULL,
+    status_code     INT          NOT NULL DEFAULT 0,
+    body            MEDIUMTEXT   NOT NULL,
+    created_at      DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (caller, idempotency_key),
+    KEY idempotency_keys_created_idx (created_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+CREATE TABLE IF NOT EXISTS campaigns (
+    host       VARCHAR(255) NOT NULL,
+    id         VARCHAR(64)  NOT NULL,
+    name       VARCHAR(255) NOT NULL DEFAULT '',
+    created_at DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (host, id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+CREATE TABLE IF NOT EXISTS campaign_links (
+    host        VARCHAR(255) NOT NULL,
+    path        VARCHAR(255) NOT NULL,
+    campaign_id VARCHAR(64)  NOT NULL,
+    created_at  DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (host, path),
+    KEY campaign_links_campaign_idx (host, campaign_id),
+    FOREIGN KEY (host, path) REFERENCES durable_links (host, path) ON DELETE CASCADE,
+    FOREIGN KEY (host, campaign_id) REFERENCES campaigns (host, id) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+CREATE TABLE IF NOT EXISTS utm_presets (
+    host         VARCHAR(255) NOT NULL,
+    name         VARCHAR(64)  NOT NULL,
+    utm_source   VARCHAR(255) NOT NULL DEFAULT '',
+    utm_medium   VARCHAR(255) NOT NULL DEFAULT '',
+    utm_campaign VARCHAR(255) NOT NULL DEFAULT '',
+    utm_term     VARCHAR(255) NOT NULL DEFAULT '',
+    utm_content  VARCHAR(255) NOT NULL DEFAULT '',
+    created_at   DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (host, name)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+CREATE TABLE IF NOT EXISTS link_geo_destinations (
+    host       VARCHAR(255) NOT NULL,
+    path       VARCHAR(255) NOT NULL,
+    country    CHAR(2)      NOT NULL,
+    link       TEXT         NOT NULL,
+    created_at DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (host, path, country),
+    FOREIGN KEY (host, path) REFERENCES durable_links (host, path) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+CREATE TABLE IF NOT EXISTS link_variants (
+    host       VARCHAR(255) NOT NULL,
+    path       VARCHAR(255) NOT NULL,
+    name       VARCHAR(64)  NOT NULL,
+    link       TEXT         NOT NULL,
+    weight     INT          NOT NULL,
+    created_at DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    PRIMARY KEY (host, path, name),
+    FOREIGN KEY (host, path) REFERENCES durable_links (host, path) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id         VARCHAR(16)  NOT NULL PRIMARY KEY,
+    key_hash   CHAR(64)     NOT NULL UNIQUE,
+    name       VARCHAR(255) NOT NULL,
+    role       VARCHAR(16)  NOT NULL,
+    created_at DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+CREATE TABLE IF NOT EXISTS subject_roles (
+    subject    VARCHAR(255) NOT NULL PRIMARY KEY,
+    role       VARCHAR(16)  NOT NULL,
+    updated_at DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id           BIGINT       NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    created_at   DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    actor        VARCHAR(64)  NOT NULL DEFAULT '',
+    subject      VARCHAR(255) NOT NULL DEFAULT '',
+    role         VARCHAR(16)  NOT NULL DEFAULT '',
+    client_ip    VARCHAR(64)  NOT NULL DEFAULT '',
+    request_id   VARCHAR(128) NOT NULL DEFAULT '',
+    action       VARCHAR(64)  NOT NULL,
+    host         VARCHAR(255) NOT NULL,
+    resource     VARCHAR(255) NOT NULL,
+    reason       VARCHAR(64)  NOT NULL DEFAULT '',
+    before_state MEDIUMTEXT,
+    after_state  MEDIUMTEXT,
+    KEY audit_log_host_idx (host, resource, id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_bin;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_log is append-only';
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_log is append-only';
+
+-- The migration this schema is as of, which MigrationVersion reports. It has to move along with
+-- the "as of" above.
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    INT         NOT NULL PRIMARY KEY,
+    applied_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+) ENGINE = InnoDB;
+
+INSERT IGNORE INTO schema_migrations (version) VALUES (31);
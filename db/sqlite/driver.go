@@ -0,0 +1,90 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+func init() {
+	sql.Register(DriverName, &sqliteDriver{SQLiteDriver: &sqlite3.SQLiteDriver{ConnectHook: setUpConn}})
+}
+
+// setUpConn enables foreign keys, needed for ON DELETE CASCADE, and adds the SQL functions the
+// shared queries call.
+func setUpConn(c *sqlite3.SQLiteConn) error {
+	if _, err := c.Exec("PRAGMA foreign_keys = ON; PRAGMA busy_timeout = 5000", nil); err != nil {
+		return err
+	}
+	if err := c.RegisterFunc("now", now, false); err != nil {
+		return err
+	}
+	if err := c.RegisterFunc("link_destination_host", linkDestinationHost, true); err != nil {
+		return err
+	}
+	return c.RegisterFunc("url_host", urlHost, true)
+}
+
+type sqliteDriver struct {
+	*sqlite3.SQLiteDriver
+}
+
+func (d *sqliteDriver) Open(dsn string) (driver.Conn, error) {
+	c, err := d.SQLiteDriver.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{SQLiteConn: c.(*sqlite3.SQLiteConn)}, nil
+}
+
+// conn rebinds the placeholders of every query and stores times in TimeFormat.
+type conn struct {
+	*sqlite3.SQLiteConn
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.SQLiteConn.Prepare(Rebind(query))
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.SQLiteConn.PrepareContext(ctx, Rebind(query))
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.SQLiteConn.ExecContext(ctx, Rebind(query), args)
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.SQLiteConn.QueryContext(ctx, Rebind(query), args)
+}
+
+// CheckNamedValue formats times, also those of valuers like sql.NullTime, and leaves other
+// values to the default conversion.
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	value := nv.Value
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return err
+		}
+		value = v
+	}
+	if t, ok := value.(time.Time); ok {
+		nv.Value = FormatTime(t)
+		return nil
+	}
+	return driver.ErrSkip
+}
+
+// IsUniqueViolation reports whether err is a SQLite unique or primary key constraint violation.
+func IsUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey)
+}
@@ -0,0 +1,11 @@
+//go:build !cgo
+
+package sqlite
+
+// Without cgo the driver isn't registered, so opening DB_DRIVER=sqlite fails with an unknown
+// driver error.
+
+// IsUniqueViolation reports whether err is a SQLite unique or primary key constraint violation.
+func IsUniqueViolation(err error) bool {
+	return false
+}
@@ -0,0 +1,38 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDriver(t *testing.T) {
+	db, err := sql.Open(DriverName, ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %s", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(Schema); err != nil {
+		t.Fatalf("failed to create schema: %s", err)
+	}
+
+	at := time.Date(2024, 5, 13, 9, 30, 0, 123456789, time.FixedZone("CEST", 2*60*60))
+	_, err = db.Exec(`INSERT INTO durable_links (host, path, query_params, last_clicked_at) VALUES ($1, $2, $3, $4)`,
+		"acme.link", "abc", "link=x", sql.NullTime{Time: at, Valid: true})
+	assert.NoError(t, err)
+
+	var stored string
+	var lastClickedAt time.Time
+	assert.NoError(t, db.QueryRow(`SELECT CAST(last_clicked_at AS TEXT), last_clicked_at FROM durable_links WHERE path = $2 AND host = $1`, "acme.link", "abc").Scan(&stored, &lastClickedAt))
+	assert.Equal(t, "2024-05-13 07:30:00.123", stored)
+	assert.True(t, at.Truncate(time.Millisecond).Equal(lastClickedAt))
+
+	_, err = db.Exec(`INSERT INTO durable_links (host, path, query_params) VALUES ($1, $2, $3)`, "acme.link", "abc", "link=y")
+	assert.True(t, IsUniqueViolation(err))
+	assert.False(t, IsUniqueViolation(sql.ErrNoRows))
+}
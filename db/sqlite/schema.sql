@@ -0,0 +1,279 @@
+-- The schema of db/migrations for SQLite, as of 0031. Timestamps are UTC text in the layout of
+-- TimeFormat so they compare and sort as text; TIMESTAMP columns are read back as times.
+CREATE TABLE IF NOT EXISTS durable_links (
+    id                    INTEGER   PRIMARY KEY AUTOINCREMENT,
+    host                  TEXT      NOT NULL,
+    path                  TEXT      NOT NULL,
+    query_params          TEXT      NOT NULL,
+    is_unguessable_path   BOOLEAN   NOT NULL DEFAULT FALSE,
+    created_at            TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    superseded_by         TEXT,
+    redirect_to_successor BOOLEAN   NOT NULL DEFAULT FALSE,
+    search_text           TEXT,
+    total_clicks          INTEGER   NOT NULL DEFAULT 0,
+    last_clicked_at       TIMESTAMP,
+    archived_at           TIMESTAMP,
+    deleted_at            TIMESTAMP,
+    name                  TEXT      NOT NULL DEFAULT '',
+    notes                 TEXT      NOT NULL DEFAULT '',
+    -- tags holds a Postgres array literal, as written and read by pq.Array.
+    tags                  TEXT      NOT NULL DEFAULT '{}',
+    device_rules          TEXT,
+    use_count             INTEGER   NOT NULL DEFAULT 0,
+    active_until          TIMESTAMP,
+    UNIQUE (host, path)
+);
+
+CREATE INDEX IF NOT EXISTS durable_links_host_query_params_idx
+    ON durable_links (host, query_params)
+    WHERE is_unguessable_path = FALSE;
+CREATE INDEX IF NOT EXISTS durable_links_host_last_clicked_idx
+    ON durable_links (host, last_clicked_at);
+CREATE INDEX IF NOT EXISTS durable_links_host_active_until_idx
+    ON durable_links (host, active_until);
+CREATE INDEX IF NOT EXISTS durable_links_host_created_idx
+    ON durable_links (host, created_at, id)
+    WHERE archived_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS link_revisions (
+    id           INTEGER   PRIMARY KEY AUTOINCREMENT,
+    host         TEXT      NOT NULL,
+    path         TEXT      NOT NULL,
+    query_params TEXT      NOT NULL,
+    reason       TEXT      NOT NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS link_revisions_host_path_idx
+    ON link_revisions (host, path, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS link_aliases (
+    host       TEXT      NOT NULL,
+    alias_path TEXT      NOT NULL,
+    path       TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (host, alias_path),
+    FOREIGN KEY (host, path) REFERENCES durable_links (host, path) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS link_aliases_host_path_idx ON link_aliases (host, path);
+
+-- Stands in for the durable_link_path_seq sequence: a single row holding the last value.
+CREATE TABLE IF NOT EXISTS durable_link_path_seq (
+    id    INTEGER PRIMARY KEY CHECK (id = 1),
+    value INTEGER NOT NULL
+);
+
+INSERT OR IGNORE INTO durable_link_path_seq (id, value) VALUES (1, 0);
+
+CREATE TABLE IF NOT EXISTS link_codes (
+    host       TEXT      NOT NULL,
+    code       TEXT      NOT NULL,
+    path       TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (host, code),
+    FOREIGN KEY (host, path) REFERENCES durable_links (host, path) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS link_codes_host_path_idx ON link_codes (host, path);
+
+CREATE TABLE IF NOT EXISTS saved_searches (
+    owner      TEXT      NOT NULL,
+    name       TEXT      NOT NULL,
+    query      TEXT      NOT NULL,
+    host       TEXT      NOT NULL DEFAULT '',
+    sort       TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (owner, name)
+);
+
+CREATE TABLE IF NOT EXISTS link_daily_clicks (
+    host   TEXT    NOT NULL,
+    path   TEXT    NOT NULL,
+    day    DATE    NOT NULL,
+    clicks INTEGER NOT NULL,
+    PRIMARY KEY (host, path, day),
+    FOREIGN KEY (host, path) REFERENCES durable_links (host, path) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS link_daily_clicks_host_day_idx ON link_daily_clicks (host, day);
+
+CREATE TABLE IF NOT EXISTS android_app_fingerprints (
+    host               TEXT      NOT NULL,
+    package_name       TEXT      NOT NULL,
+    sha256_fingerprint TEXT      NOT NULL,
+    created_at         TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (host, package_name, sha256_fingerprint)
+);
+
+-- allowed_domains holds a Postgres array literal, as written and read by pq.Array.
+CREATE TABLE IF NOT EXISTS domains (
+    host                         TEXT      PRIMARY KEY,
+    allowed_domains              TEXT      NOT NULL DEFAULT '{}',
+    default_android_package_name TEXT      NOT NULL DEFAULT '',
+    default_ios_store_id         TEXT      NOT NULL DEFAULT '',
+    created_at                   TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at                   TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS link_clicks (
+    id         INTEGER   PRIMARY KEY AUTOINCREMENT,
+    host       TEXT      NOT NULL,
+    path       TEXT      NOT NULL,
+    clicked_at TIMESTAMP NOT NULL,
+    source     TEXT      NOT NULL,
+    platform   TEXT      NOT NULL DEFAULT '',
+    country    TEXT      NOT NULL DEFAULT '',
+    referrer   TEXT      NOT NULL DEFAULT '',
+    user_agent TEXT      NOT NULL DEFAULT '',
+    visitor    TEXT      NOT NULL DEFAULT '',
+    variant    TEXT      NOT NULL DEFAULT '',
+    consented  BOOLEAN   NOT NULL DEFAULT TRUE
+);
+
+CREATE INDEX IF NOT EXISTS link_clicks_host_path_clicked_idx ON link_clicks (host, path, clicked_at);
+CREATE INDEX IF NOT EXISTS link_clicks_host_clicked_idx ON link_clicks (host, clicked_at);
+
+-- events and click_thresholds hold Postgres array literals, as written and read by pq.Array.
+CREATE TABLE IF NOT EXISTS webhooks (
+    id               INTEGER   PRIMARY KEY AUTOINCREMENT,
+    host             TEXT      NOT NULL,
+    url              TEXT      NOT NULL,
+    secret           TEXT      NOT NULL,
+    events           TEXT      NOT NULL DEFAULT '{}',
+    click_thresholds TEXT      NOT NULL DEFAULT '{}',
+    created_at       TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_host_idx ON webhooks (host);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id           INTEGER   PRIMARY KEY AUTOINCREMENT,
+    webhook_id   INTEGER   NOT NULL,
+    event_id     TEXT      NOT NULL,
+    event_type   TEXT      NOT NULL,
+    payload      TEXT      NOT NULL,
+    attempt      INTEGER   NOT NULL,
+    status_code  INTEGER   NOT NULL DEFAULT 0,
+    error        TEXT      NOT NULL DEFAULT '',
+    duration_ms  INTEGER   NOT NULL DEFAULT 0,
+    delivered_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_idx ON webhook_deliveries (webhook_id, id);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    caller          TEXT      NOT NULL,
+    idempotency_key TEXT      NOT NULL,
+    request_hash    TEXT      NOT NULL,
+    status_code     INTEGER   NOT NULL DEFAULT 0,
+    body            TEXT      NOT NULL DEFAULT '',
+    created_at      TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (caller, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_created_idx ON idempotency_keys (created_at);
+
+CREATE TABLE IF NOT EXISTS campaigns (
+    host       TEXT      NOT NULL,
+    id         TEXT      NOT NULL,
+    name       TEXT      NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (host, id)
+);
+
+CREATE TABLE IF NOT EXISTS campaign_links (
+    host        TEXT      NOT NULL,
+    path        TEXT      NOT NULL,
+    campaign_id TEXT      NOT NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (host, path),
+    FOREIGN KEY (host, path) REFERENCES durable_links (host, path) ON DELETE CASCADE,
+    FOREIGN KEY (host, campaign_id) REFERENCES campaigns (host, id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS campaign_links_campaign_idx ON campaign_links (host, campaign_id);
+
+CREATE TABLE IF NOT EXISTS utm_presets (
+    host         TEXT      NOT NULL,
+    name         TEXT      NOT NULL,
+    utm_source   TEXT      NOT NULL DEFAULT '',
+    utm_medium   TEXT      NOT NULL DEFAULT '',
+    utm_campaign TEXT      NOT NULL DEFAULT '',
+    utm_term     TEXT      NOT NULL DEFAULT '',
+    utm_content  TEXT      NOT NULL DEFAULT '',
+    created_at   TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (host, name)
+);
+
+CREATE TABLE IF NOT EXISTS link_geo_destinations (
+    host       TEXT      NOT NULL,
+    path       TEXT      NOT NULL,
+    country    TEXT      NOT NULL,
+    link       TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (host, path, country),
+    FOREIGN KEY (host, path) REFERENCES durable_links (host, path) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS link_variants (
+    host       TEXT      NOT NULL,
+    path       TEXT      NOT NULL,
+    name       TEXT      NOT NULL,
+    link       TEXT      NOT NULL,
+    weight     INTEGER   NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (host, path, name),
+    FOREIGN KEY (host, path) REFERENCES durable_links (host, path) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id         TEXT      PRIMARY KEY,
+    key_hash   TEXT      NOT NULL UNIQUE,
+    name       TEXT      NOT NULL,
+    role       TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS subject_roles (
+    subject    TEXT      PRIMARY KEY,
+    role       TEXT      NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id           INTEGER   PRIMARY KEY AUTOINCREMENT,
+    created_at   TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    actor        TEXT      NOT NULL DEFAULT '',
+    subject      TEXT      NOT NULL DEFAULT '',
+    role         TEXT      NOT NULL DEFAULT '',
+    client_ip    TEXT      NOT NULL DEFAULT '',
+    request_id   TEXT      NOT NULL DEFAULT '',
+    action       TEXT      NOT NULL,
+    host         TEXT      NOT NULL,
+    resource     TEXT      NOT NULL,
+    reason       TEXT      NOT NULL DEFAULT '',
+    before_state TEXT,
+    after_state  TEXT
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_host_idx ON audit_log (host, resource, id);
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+-- The migration this schema is as of, which MigrationVersion reports. It has to move along with
+-- the "as of" above.
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    INTEGER   PRIMARY KEY,
+    applied_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+INSERT OR IGNORE INTO schema_migrations (version) VALUES (31);
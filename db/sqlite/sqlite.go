@@ -0,0 +1,62 @@
+// Package sqlite registers the "sqlite" database/sql driver the db package opens for
+// DB_DRIVER=sqlite. It wraps github.com/mattn/go-sqlite3, which needs cgo, so that queries
+// written for Postgres mostly run unchanged: $N placeholders are accepted, times are stored as
+// comparable UTC text, and now(), link_destination_host() and url_host() are provided.
+package sqlite
+
+import (
+	_ "embed"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DriverName is the database/sql driver name the package registers.
+const DriverName = "sqlite"
+
+// TimeFormat is how times are stored: UTC with milliseconds, like strftime('%Y-%m-%d %H:%M:%f').
+const TimeFormat = "2006-01-02 15:04:05.000"
+
+// Schema creates the tables the repositories use, if they don't exist yet.
+//
+//go:embed schema.sql
+var Schema string
+
+var placeholderPattern = regexp.MustCompile(`\$(\d+)`)
+
+// Rebind turns the $N placeholders of Postgres into SQLite's ?N.
+func Rebind(query string) string {
+	return placeholderPattern.ReplaceAllString(query, "?$1")
+}
+
+// FormatTime returns t as stored in SQLite.
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(TimeFormat)
+}
+
+func now() string {
+	return FormatTime(time.Now())
+}
+
+var (
+	destinationHostPattern = regexp.MustCompile(`(?:^|&)link=[A-Za-z][A-Za-z0-9+.-]*%3A%2F%2F([^%&/?#]+)`)
+	urlHostPattern         = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*://([^/:?#]+)`)
+)
+
+// linkDestinationHost is the link_destination_host() SQL function of migration 0013.
+func linkDestinationHost(queryParams string) any {
+	return lowerGroup(destinationHostPattern, queryParams)
+}
+
+// urlHost returns the lowercased host of an absolute URL, or NULL.
+func urlHost(rawURL string) any {
+	return lowerGroup(urlHostPattern, rawURL)
+}
+
+func lowerGroup(pattern *regexp.Regexp, s string) any {
+	match := pattern.FindStringSubmatch(s)
+	if match == nil {
+		return nil
+	}
+	return strings.ToLower(match[1])
+}
@@ -0,0 +1,18 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebind(t *testing.T) {
+	assert.Equal(t, "SELECT ?2 WHERE host = ?1 AND path = ?12", Rebind("SELECT $2 WHERE host = $1 AND path = $12"))
+}
+
+func TestLinkDestinationHost(t *testing.T) {
+	assert.Equal(t, "shop.example.com", linkDestinationHost("apn=a&link=https%3A%2F%2FShop.Example.com%3A8443%2Fx"))
+	assert.Nil(t, linkDestinationHost("apn=a&deeplink=https%3A%2F%2Fexample.com"))
+	assert.Equal(t, "news.example.com", urlHost("https://News.example.com:443/a?b"))
+	assert.Nil(t, urlHost("android-app://"))
+}
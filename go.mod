@@ -14,6 +14,49 @@ require (
 
 require github.com/lib/pq v1.10.9
 
+require github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
+
+require golang.org/x/image v0.24.0
+
+require github.com/mattn/go-sqlite3 v1.14.22
+
+require (
+	google.golang.org/grpc v1.71.1
+	google.golang.org/protobuf v1.36.4
+)
+
+require (
+	filippo.io/edwards25519 v1.1.0 // indirect
+	github.com/go-sql-driver/mysql v1.9.3
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/redis/go-redis/v9 v9.7.3
+)
+
+require (
+	github.com/alicebob/miniredis/v2 v2.37.0
+	github.com/yuin/gopher-lua v1.1.1 // indirect
+)
+
+require (
+	cel.dev/expr v0.19.1 // indirect
+	github.com/antlr4-go/antlr/v4 v4.13.0 // indirect
+	github.com/google/cel-go v0.23.2
+	github.com/stoewer/go-strcase v1.2.0 // indirect
+	golang.org/x/exp v0.0.0-20230515195305-f3d0a9c9a5cc // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20250106144421-5f5ef82da422 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250115164207-1a7da9e5054f // indirect
+)
+
+require (
+	golang.org/x/crypto v0.36.0
+	golang.org/x/net v0.34.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
+)
+
 require (
 	github.com/DATA-DOG/go-sqlmock v1.5.2
 	github.com/davecgh/go-spew v1.1.1 // indirect
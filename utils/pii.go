@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ().\-]{5,}[0-9]$`)
+)
+
+// Query parameter names that usually carry a phone number.
+var phoneParamNames = []string{"phone", "tel", "mobile", "msisdn", "cell"}
+
+// FindPII returns the names of the parts of rawURL that look like they carry personal data:
+// email addresses anywhere in the path or query, and phone numbers in query params.
+func FindPII(rawURL string) []string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+
+	var found []string
+	if emailPattern.MatchString(u.Path) {
+		found = append(found, "path")
+	}
+
+	for key, values := range u.Query() {
+		for _, v := range values {
+			if emailPattern.MatchString(v) || looksLikePhoneNumber(key, v) {
+				found = append(found, key)
+				break
+			}
+		}
+	}
+	slices.Sort(found)
+	return found
+}
+
+// A value is treated as a phone number when it has 7 to 15 digits in a phone-like format and
+// either starts with an international prefix or sits in a phone-like param.
+func looksLikePhoneNumber(key, value string) bool {
+	value = strings.TrimSpace(value)
+	if !phonePattern.MatchString(value) {
+		return false
+	}
+
+	digits := 0
+	for _, c := range value {
+		if c >= '0' && c <= '9' {
+			digits++
+		}
+	}
+	if digits < 7 || digits > 15 {
+		return false
+	}
+
+	if strings.HasPrefix(value, "+") {
+		return true
+	}
+	key = strings.ToLower(key)
+	for _, name := range phoneParamNames {
+		if strings.Contains(key, name) {
+			return true
+		}
+	}
+	return false
+}
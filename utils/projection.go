@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ParseFields splits a comma separated fields parameter, dropping blanks.
+func ParseFields(raw string) []string {
+	var fields []string
+	for _, field := range strings.Split(raw, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// ProjectFields keeps only the given fields of a JSON object. With listKey set, the fields select
+// the keys of the objects in that list instead, e.g. the items of {"results": [...]}, and the
+// rest of the envelope is kept. Unknown fields are ignored; no fields leaves data as it is.
+func ProjectFields(data []byte, listKey string, fields []string) ([]byte, error) {
+	if len(fields) == 0 {
+		return data, nil
+	}
+	keep := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		keep[field] = true
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc map[string]any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("projection needs a JSON object: %w", err)
+	}
+
+	if listKey == "" {
+		return json.Marshal(pick(doc, keep))
+	}
+
+	items, _ := doc[listKey].([]any)
+	for i, item := range items {
+		if object, ok := item.(map[string]any); ok {
+			items[i] = pick(object, keep)
+		}
+	}
+	return json.Marshal(doc)
+}
+
+func pick(object map[string]any, keep map[string]bool) map[string]any {
+	picked := make(map[string]any, len(keep))
+	for key, value := range object {
+		if keep[key] {
+			picked[key] = value
+		}
+	}
+	return picked
+}
@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
 
 	"github.com/rs/zerolog/log"
 )
@@ -26,3 +29,35 @@ func GenerateRandomAlphanumericString(length int) string {
 
 	return id
 }
+
+// GenerateRandomNumericString returns length random digits.
+func GenerateRandomNumericString(length int) string {
+	b := make([]byte, length)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			log.Panic().Err(err).Msg("Failed to generate random number")
+		}
+		b[i] = byte('0' + n.Int64())
+	}
+	return string(b)
+}
+
+// DeterministicAlphanumericString derives an alphanumeric string from key and message, so the
+// same inputs always give the same string while different keys give unrelated ones.
+func DeterministicAlphanumericString(key, message string, length int) string {
+	const alphanumeric = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	b := make([]byte, 0, length)
+	for counter := byte(0); len(b) < length; counter++ {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write([]byte(message))
+		mac.Write([]byte{counter})
+		b = append(b, mac.Sum(nil)...)
+	}
+	b = b[:length]
+
+	for i := range b {
+		b[i] = alphanumeric[b[i]%byte(len(alphanumeric))]
+	}
+	return string(b)
+}
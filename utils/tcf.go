@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// Bit offset of the PurposesConsent field in a TCF v2 core segment.
+const tcfPurposesConsentOffset = 152
+
+// TCFPurposesConsented reports whether a IAB TCF v2 consent string grants consent for every
+// given purpose. Strings that can't be decoded are treated as no consent.
+func TCFPurposesConsented(tcString string, purposes ...int) bool {
+	core, _, _ := strings.Cut(strings.TrimSpace(tcString), ".")
+	if core == "" {
+		return false
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(core, "="))
+	if err != nil {
+		return false
+	}
+
+	bit := func(i int) bool {
+		if i/8 >= len(b) {
+			return false
+		}
+		return b[i/8]&(0x80>>(i%8)) != 0
+	}
+
+	version := 0
+	for i := range 6 {
+		version <<= 1
+		if bit(i) {
+			version |= 1
+		}
+	}
+	if version != 2 {
+		return false
+	}
+
+	for _, p := range purposes {
+		if p < 1 || p > 24 || !bit(tcfPurposesConsentOffset+p-1) {
+			return false
+		}
+	}
+	return true
+}
@@ -49,3 +49,18 @@ func CleanHost(raw string) (string, error) {
 
 	return host, nil
 }
+
+// PreviewHostVariants returns the domain together with the preview hosts that resolve to it:
+// preview.<domain> and, for domains with a subdomain, <app>-preview.<rest>.
+func PreviewHostVariants(domain string) []string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if domain == "" {
+		return nil
+	}
+
+	hosts := []string{domain, "preview." + domain}
+	if parts := strings.SplitN(domain, ".", 2); len(parts) == 2 && strings.Contains(parts[1], ".") {
+		hosts = append(hosts, parts[0]+"-preview."+parts[1])
+	}
+	return hosts
+}
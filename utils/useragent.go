@@ -0,0 +1,57 @@
+package utils
+
+import "strings"
+
+// User agent fragments of mail security gateways, URL scanners and HTTP libraries that fetch
+// links without a person behind them.
+var scannerUserAgents = []string{
+	"safelinks", "mimecast", "proofpoint", "urldefense", "barracuda", "trendmicro", "symantec",
+	"forcepoint", "zscaler", "cisco", "bingpreview", "google-safety", "microsoft office", "ms-office",
+	"python-requests", "python-urllib", "curl/", "wget/", "go-http-client", "java/", "okhttp",
+}
+
+// User agent fragments of the crawlers social networks and chat apps send to build link previews.
+var socialCrawlerUserAgents = []string{
+	"facebookexternalhit", "facebot", "twitterbot", "slackbot", "linkedinbot", "discordbot",
+	"telegrambot", "whatsapp", "pinterest", "redditbot", "embedly", "skypeuripreview", "vkshare",
+}
+
+// Rendering engines present in the user agent of every mainstream browser.
+var browserEngines = []string{"applewebkit/", "gecko/", "chrome/", "safari/", "firefox/", "edg/"}
+
+// IsLinkScanner reports whether ua belongs to a known URL scanner or HTTP library.
+func IsLinkScanner(ua string) bool {
+	ua = strings.ToLower(ua)
+	for _, s := range scannerUserAgents {
+		if strings.Contains(ua, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSocialCrawler reports whether ua belongs to a crawler fetching a link preview for a social
+// network or chat app.
+func IsSocialCrawler(ua string) bool {
+	ua = strings.ToLower(ua)
+	for _, c := range socialCrawlerUserAgents {
+		if strings.Contains(ua, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBrowserUserAgent reports whether ua looks like a mainstream browser.
+func IsBrowserUserAgent(ua string) bool {
+	ua = strings.ToLower(ua)
+	if !strings.HasPrefix(ua, "mozilla/") {
+		return false
+	}
+	for _, e := range browserEngines {
+		if strings.Contains(ua, e) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"encoding/base64"
 	"os"
+	"strings"
 	"testing"
 	"unicode"
 
@@ -190,6 +192,15 @@ func TestIsNumericString(t *testing.T) {
 	}
 }
 
+func TestIsHostname(t *testing.T) {
+	for _, host := range []string{"acme.link", "go.acme.link", "localhost", "x_y.example", "192.0.2.1", "::1"} {
+		assert.True(t, IsHostname(host), host)
+	}
+	for _, host := range []string{"", "Acme.link", "acme..link", "-acme.link", "acme.link.", "ac me.link", "é.link", strings.Repeat("a", 64) + ".link"} {
+		assert.False(t, IsHostname(host), host)
+	}
+}
+
 func TestIsDomainAllowed(t *testing.T) {
 	allowList := []string{
 		"example.com",
@@ -411,3 +422,262 @@ func TestCleanHost(t *testing.T) {
 		})
 	}
 }
+
+func TestFindPII(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want []string
+	}{
+		{
+			name: "no personal data",
+			url:  "https://example.com/product?id=42&utm_source=mail",
+			want: nil,
+		},
+		{
+			name: "email in query",
+			url:  "https://example.com/welcome?user=jane.doe%40example.org",
+			want: []string{"user"},
+		},
+		{
+			name: "email in path",
+			url:  "https://example.com/users/jane@example.org",
+			want: []string{"path"},
+		},
+		{
+			name: "international phone number",
+			url:  "https://example.com/?contact=%2B44%2020%207946%200958",
+			want: []string{"contact"},
+		},
+		{
+			name: "phone number in phone-like param",
+			url:  "https://example.com/?phone=555-123-4567",
+			want: []string{"phone"},
+		},
+		{
+			name: "numeric id is not a phone number",
+			url:  "https://example.com/?order=5551234567",
+			want: nil,
+		},
+		{
+			name: "invalid URL",
+			url:  "://bad",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FindPII(tt.url))
+		})
+	}
+}
+
+// buildTCString encodes a minimal TCF core segment with the given version and consented purposes.
+func buildTCString(version int, purposes ...int) string {
+	bits := make([]byte, 30)
+	set := func(i int) { bits[i/8] |= 0x80 >> (i % 8) }
+	for i := range 6 {
+		if version&(1<<(5-i)) != 0 {
+			set(i)
+		}
+	}
+	for _, p := range purposes {
+		set(152 + p - 1)
+	}
+	return base64.RawURLEncoding.EncodeToString(bits)
+}
+
+func TestTCFPurposesConsented(t *testing.T) {
+	tests := []struct {
+		name     string
+		tcString string
+		purposes []int
+		want     bool
+	}{
+		{
+			name:     "all purposes granted",
+			tcString: buildTCString(2, 1, 7),
+			purposes: []int{1, 7},
+			want:     true,
+		},
+		{
+			name:     "purpose missing",
+			tcString: buildTCString(2, 1),
+			purposes: []int{1, 7},
+			want:     false,
+		},
+		{
+			name:     "with publisher segment",
+			tcString: buildTCString(2, 1, 7) + ".YAAAAAAAAAAA",
+			purposes: []int{1, 7},
+			want:     true,
+		},
+		{
+			name:     "wrong version",
+			tcString: buildTCString(1, 1, 7),
+			purposes: []int{1, 7},
+			want:     false,
+		},
+		{
+			name:     "not base64",
+			tcString: "!!!",
+			purposes: []int{1},
+			want:     false,
+		},
+		{
+			name:     "empty",
+			tcString: "",
+			purposes: []int{1},
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, TCFPurposesConsented(tt.tcString, tt.purposes...))
+		})
+	}
+}
+
+func TestPreviewHostVariants(t *testing.T) {
+	tests := []struct {
+		name   string
+		domain string
+		want   []string
+	}{
+		{
+			name:   "apex domain",
+			domain: "acme.link",
+			want:   []string{"acme.link", "preview.acme.link"},
+		},
+		{
+			name:   "subdomain",
+			domain: "Acme.Short.Link",
+			want:   []string{"acme.short.link", "preview.acme.short.link", "acme-preview.short.link"},
+		},
+		{
+			name:   "empty",
+			domain: " ",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, PreviewHostVariants(tt.domain))
+		})
+	}
+}
+
+func TestUserAgentClassification(t *testing.T) {
+	tests := []struct {
+		name        string
+		ua          string
+		wantScanner bool
+		wantBrowser bool
+		wantCrawler bool
+	}{
+		{
+			name:        "chrome",
+			ua:          "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0 Safari/537.36",
+			wantBrowser: true,
+		},
+		{
+			name:        "firefox",
+			ua:          "Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+			wantBrowser: true,
+		},
+		{
+			name:        "curl",
+			ua:          "curl/8.5.0",
+			wantScanner: true,
+		},
+		{
+			name:        "mail gateway",
+			ua:          "Mozilla/5.0 (compatible; Mimecast URL Protect)",
+			wantScanner: true,
+		},
+		{
+			name:        "facebook crawler",
+			ua:          "facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)",
+			wantCrawler: true,
+		},
+		{
+			name:        "slack unfurler",
+			ua:          "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)",
+			wantCrawler: true,
+		},
+		{
+			name: "empty",
+			ua:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantScanner, IsLinkScanner(tt.ua))
+			assert.Equal(t, tt.wantBrowser, IsBrowserUserAgent(tt.ua))
+			assert.Equal(t, tt.wantCrawler, IsSocialCrawler(tt.ua))
+		})
+	}
+}
+
+func TestGenerateRandomNumericString(t *testing.T) {
+	assert.Regexp(t, `^[0-9]{6}$`, GenerateRandomNumericString(6))
+	assert.Empty(t, GenerateRandomNumericString(0))
+}
+
+func TestDeterministicAlphanumericString(t *testing.T) {
+	a := DeterministicAlphanumericString("seed", "fixture-01", 40)
+	assert.Len(t, a, 40)
+	assert.Regexp(t, `^[A-Za-z0-9]+$`, a)
+	assert.Equal(t, a, DeterministicAlphanumericString("seed", "fixture-01", 40))
+	assert.Equal(t, a[:6], DeterministicAlphanumericString("seed", "fixture-01", 6))
+	assert.NotEqual(t, a, DeterministicAlphanumericString("seed", "fixture-02", 40))
+	assert.NotEqual(t, a, DeterministicAlphanumericString("other", "fixture-01", 40))
+}
+
+func TestProjectFields(t *testing.T) {
+	list := []byte(`{"results":[{"path":"a","link":"https://a.com","createdAt":"2024-01-01T00:00:00Z","clicks":12345678901234567890},{"path":"b"}],"nextPageToken":"x"}`)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		listKey string
+		fields  string
+		want    string
+	}{
+		{
+			name:    "list items",
+			data:    list,
+			listKey: "results",
+			fields:  "path, clicks,unknown",
+			want:    `{"nextPageToken":"x","results":[{"clicks":12345678901234567890,"path":"a"},{"path":"b"}]}`,
+		},
+		{
+			name:   "single object",
+			data:   []byte(`{"path":"a","link":"https://a.com","aliases":["x"]}`),
+			fields: "aliases",
+			want:   `{"aliases":["x"]}`,
+		},
+		{
+			name:    "no fields",
+			data:    list,
+			listKey: "results",
+			fields:  " , ",
+			want:    string(list),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProjectFields(tt.data, tt.listKey, ParseFields(tt.fields))
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+
+	_, err := ProjectFields([]byte(`["a"]`), "", []string{"a"})
+	assert.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"net"
 	"net/url"
 	"strings"
 
@@ -21,6 +22,28 @@ func IsNumericString(s string) bool {
 	return true
 }
 
+// IsHostname reports whether host is a lowercase DNS name or an IP address, as CleanHost returns
+// for valid hosts.
+func IsHostname(host string) bool {
+	if net.ParseIP(host) != nil {
+		return true
+	}
+	if host == "" || len(host) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' && c != '_' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // IsDomainAllowed checks if a domain is in the allowlist. Note that we do not allow subdomains
 func IsDomainAllowed(allowList []string, rawLink string) bool {
 	u, err := url.Parse(rawLink)